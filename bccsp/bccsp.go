@@ -114,6 +114,13 @@ type BCCSP interface {
 	// the Subject Key Identifier ski.
 	GetKey(ski []byte) (k Key, err error)
 
+	// RotateKey generates a new, non-ephemeral, version of the private
+	// or secret key k using opts and retires k. If opts is nil, the new
+	// key is of the same type as k. A retired key is still returned by
+	// GetKey, so that what it signed or encrypted can still be verified
+	// or decrypted. RotateKey returns the SKIs of k and of the new key.
+	RotateKey(k Key, opts KeyGenOpts) (oldSKI, newSKI []byte, err error)
+
 	// Hash hashes messages msg using options opts.
 	// If opts is nil, the default hash function will be used.
 	Hash(msg []byte, opts HashOpts) (hash []byte, err error)
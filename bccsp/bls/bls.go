@@ -0,0 +1,237 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package bls implements BLS signatures over the BLS12-381 curve, with
+// public keys in G1 and signatures in G2, following the proof of
+// possession scheme of draft-irtf-cfrg-bls-signature. Signatures over
+// the same message can be aggregated, and verified against the
+// aggregation of the public keys of their signers.
+package bls
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	bls12381 "github.com/kilic/bls12-381"
+)
+
+const (
+	// PrivateKeySize is the size, in bytes, of private keys
+	PrivateKeySize = 32
+	// PublicKeySize is the size, in bytes, of compressed public keys
+	PublicKeySize = 48
+	// SignatureSize is the size, in bytes, of compressed signatures
+	SignatureSize = 96
+)
+
+// ErrInvalidSignature is returned when a well-formed
+// signature does not verify
+var ErrInvalidSignature = errors.New("Signature verification failed")
+
+var (
+	signatureDomain  = []byte("BLS_SIG_BLS12381G2_XMD:SHA-256_SSWU_RO_POP_")
+	possessionDomain = []byte("BLS_POP_BLS12381G2_XMD:SHA-256_SSWU_RO_POP_")
+
+	// order is the order of the groups
+	order = bls12381.NewG1().Q()
+)
+
+// GenerateKey returns a new private key, and its public key,
+// generated with the randomness read from rand
+func GenerateKey(rand io.Reader) (priv, pub []byte, err error) {
+	for {
+		x, err := randomScalar(rand)
+		if err != nil {
+			return nil, nil, err
+		}
+		if x.isZero() == 0 {
+			priv = x.bytes()
+			pub, err = PublicKey(priv)
+			return priv, pub, err
+		}
+	}
+}
+
+// PublicKey returns the public key of priv
+func PublicKey(priv []byte) ([]byte, error) {
+	x, err := scalarFromBytes(priv)
+	if err != nil {
+		return nil, err
+	}
+
+	g1 := bls12381.NewG1()
+	return g1.ToCompressed(mulG1(g1.One(), x)), nil
+}
+
+// VerifyPublicKey checks that pub is a valid public key
+func VerifyPublicKey(pub []byte) error {
+	_, err := publicKeyFromBytes(pub)
+	return err
+}
+
+// Sign signs msg with priv
+func Sign(priv, msg []byte) ([]byte, error) {
+	return sign(priv, msg, signatureDomain)
+}
+
+// Verify checks that sig is a valid signature of msg under pub
+func Verify(pub, msg, sig []byte) error {
+	return verify(pub, msg, sig, signatureDomain)
+}
+
+// ProvePossession returns a proof of possession of priv. Public keys
+// must be accompanied by a valid proof of possession before their
+// signatures are aggregated, to prevent rogue key attacks.
+func ProvePossession(priv []byte) ([]byte, error) {
+	pub, err := PublicKey(priv)
+	if err != nil {
+		return nil, err
+	}
+	return sign(priv, pub, possessionDomain)
+}
+
+// VerifyPossession checks that proof is a valid
+// proof of possession of the private key of pub
+func VerifyPossession(pub, proof []byte) error {
+	return verify(pub, pub, proof, possessionDomain)
+}
+
+// AggregateSignatures aggregates sigs into a single signature
+func AggregateSignatures(sigs [][]byte) ([]byte, error) {
+	if len(sigs) == 0 {
+		return nil, errors.New("Invalid signatures. At least one signature is required.")
+	}
+
+	g2 := bls12381.NewG2()
+	aggregate := g2.Zero()
+	for i, sig := range sigs {
+		s, err := signatureFromBytes(sig)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid signature at index [%d] [%s]", i, err)
+		}
+		g2.Add(aggregate, aggregate, s)
+	}
+	return g2.ToCompressed(aggregate), nil
+}
+
+// AggregatePublicKeys aggregates pubs into a single public key
+func AggregatePublicKeys(pubs [][]byte) ([]byte, error) {
+	if len(pubs) == 0 {
+		return nil, errors.New("Invalid public keys. At least one public key is required.")
+	}
+
+	g1 := bls12381.NewG1()
+	aggregate := g1.Zero()
+	for i, pub := range pubs {
+		p, err := publicKeyFromBytes(pub)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid public key at index [%d] [%s]", i, err)
+		}
+		g1.Add(aggregate, aggregate, p)
+	}
+	if g1.IsZero(aggregate) {
+		return nil, errors.New("Invalid public keys. Their aggregation is the identity.")
+	}
+	return g1.ToCompressed(aggregate), nil
+}
+
+// VerifyAggregate checks that sig is the aggregation of
+// valid signatures of msg under each of pubs
+func VerifyAggregate(pubs [][]byte, msg, sig []byte) error {
+	pub, err := AggregatePublicKeys(pubs)
+	if err != nil {
+		return err
+	}
+	return Verify(pub, msg, sig)
+}
+
+func sign(priv, msg, domain []byte) ([]byte, error) {
+	x, err := scalarFromBytes(priv)
+	if err != nil {
+		return nil, err
+	}
+
+	g2 := bls12381.NewG2()
+	h, err := g2.HashToCurve(msg, domain)
+	if err != nil {
+		return nil, fmt.Errorf("Failed hashing to curve [%s]", err)
+	}
+	return g2.ToCompressed(mulG2(h, x)), nil
+}
+
+func verify(pub, msg, sig, domain []byte) error {
+	p, err := publicKeyFromBytes(pub)
+	if err != nil {
+		return fmt.Errorf("Invalid public key [%s]", err)
+	}
+	s, err := signatureFromBytes(sig)
+	if err != nil {
+		return fmt.Errorf("Invalid signature [%s]", err)
+	}
+
+	h, err := bls12381.NewG2().HashToCurve(msg, domain)
+	if err != nil {
+		return fmt.Errorf("Failed hashing to curve [%s]", err)
+	}
+
+	// e(pub, H(msg)) == e(g1, sig)
+	engine := bls12381.NewEngine()
+	engine.AddPair(p, h)
+	engine.AddPairInv(engine.G1.One(), s)
+	if !engine.Check() {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func publicKeyFromBytes(pub []byte) (*bls12381.PointG1, error) {
+	g1 := bls12381.NewG1()
+	p, err := g1.FromCompressed(pub)
+	if err != nil {
+		return nil, err
+	}
+	if g1.IsZero(p) {
+		return nil, errors.New("The public key is the identity")
+	}
+	return p, nil
+}
+
+func signatureFromBytes(sig []byte) (*bls12381.PointG2, error) {
+	g2 := bls12381.NewG2()
+	s, err := g2.FromCompressed(sig)
+	if err != nil {
+		return nil, err
+	}
+	if g2.IsZero(s) {
+		return nil, errors.New("The signature is the identity")
+	}
+	return s, nil
+}
+
+func scalarFromBytes(priv []byte) (*scalar, error) {
+	if len(priv) != PrivateKeySize {
+		return nil, fmt.Errorf("Invalid private key length [%d]. It must be [%d].", len(priv), PrivateKeySize)
+	}
+	var buf [PrivateKeySize]byte
+	copy(buf[:], priv)
+	x := new(scalar)
+	x.setBytes(&buf)
+	if x.isReduced()&^x.isZero() == 0 {
+		return nil, errors.New("Invalid private key. It must be in [1, q-1].")
+	}
+	return x, nil
+}
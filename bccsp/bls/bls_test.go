@@ -0,0 +1,191 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bls
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+)
+
+func TestSignVerify(t *testing.T) {
+	priv, pub, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed generating key [%s]", err)
+	}
+	if len(priv) != PrivateKeySize || len(pub) != PublicKeySize {
+		t.Fatalf("Unexpected key sizes [%d] [%d]", len(priv), len(pub))
+	}
+
+	msg := []byte("Hello World")
+	sig, err := Sign(priv, msg)
+	if err != nil {
+		t.Fatalf("Failed signing [%s]", err)
+	}
+	if len(sig) != SignatureSize {
+		t.Fatalf("Unexpected signature size [%d]", len(sig))
+	}
+	if err := Verify(pub, msg, sig); err != nil {
+		t.Fatalf("Failed verifying signature [%s]", err)
+	}
+
+	// BLS signatures are deterministic
+	sig2, _ := Sign(priv, msg)
+	if !bytes.Equal(sig, sig2) {
+		t.Fatal("Signatures of the same message should be equal")
+	}
+
+	if err := Verify(pub, []byte("Bye World"), sig); err == nil {
+		t.Fatal("Verifying the signature of another message should fail")
+	}
+	_, otherPub, _ := GenerateKey(rand.Reader)
+	if err := Verify(otherPub, msg, sig); err == nil {
+		t.Fatal("Verifying a signature under another key should fail")
+	}
+	if err := Verify(pub, msg, sig[1:]); err == nil {
+		t.Fatal("Verifying a truncated signature should fail")
+	}
+
+	// Proofs of possession are not valid signatures, and vice versa
+	proof, err := ProvePossession(priv)
+	if err != nil {
+		t.Fatalf("Failed proving possession [%s]", err)
+	}
+	if err := VerifyPossession(pub, proof); err != nil {
+		t.Fatalf("Failed verifying proof of possession [%s]", err)
+	}
+	if err := Verify(pub, pub, proof); err == nil {
+		t.Fatal("A proof of possession should not be a valid signature")
+	}
+	if err := VerifyPossession(otherPub, proof); err == nil {
+		t.Fatal("Verifying a proof of possession under another key should fail")
+	}
+
+	if _, err := Sign(make([]byte, PrivateKeySize), msg); err == nil {
+		t.Fatal("Signing with a zero key should fail")
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	msg := []byte("Hello World")
+
+	var pubs, sigs [][]byte
+	for i := 0; i < 4; i++ {
+		priv, pub, err := GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatalf("Failed generating key [%s]", err)
+		}
+		sig, err := Sign(priv, msg)
+		if err != nil {
+			t.Fatalf("Failed signing [%s]", err)
+		}
+		pubs = append(pubs, pub)
+		sigs = append(sigs, sig)
+	}
+
+	aggregate, err := AggregateSignatures(sigs)
+	if err != nil {
+		t.Fatalf("Failed aggregating signatures [%s]", err)
+	}
+	if len(aggregate) != SignatureSize {
+		t.Fatalf("Unexpected aggregate signature size [%d]", len(aggregate))
+	}
+	if err := VerifyAggregate(pubs, msg, aggregate); err != nil {
+		t.Fatalf("Failed verifying aggregate signature [%s]", err)
+	}
+
+	if err := VerifyAggregate(pubs[:3], msg, aggregate); err == nil {
+		t.Fatal("Verifying against a subset of the signers should fail")
+	}
+	if err := VerifyAggregate(pubs, []byte("Bye World"), aggregate); err == nil {
+		t.Fatal("Verifying the aggregate of another message should fail")
+	}
+
+	if _, err := AggregateSignatures(nil); err == nil {
+		t.Fatal("Aggregating no signatures should fail")
+	}
+	if _, err := AggregatePublicKeys([][]byte{pubs[0], []byte("invalid")}); err == nil {
+		t.Fatal("Aggregating invalid public keys should fail")
+	}
+}
+
+func TestThreshold(t *testing.T) {
+	priv, pub, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed generating key [%s]", err)
+	}
+
+	shares, err := SplitKey(priv, 3, 5, rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed splitting key [%s]", err)
+	}
+	if len(shares) != 5 {
+		t.Fatalf("Expected 5 shares, got [%d]", len(shares))
+	}
+
+	msg := []byte("Hello World")
+	var partials []*PartialSignature
+	for _, share := range shares {
+		sig, err := Sign(share.PrivateKey, msg)
+		if err != nil {
+			t.Fatalf("Failed signing with share [%d] [%s]", share.Index, err)
+		}
+		sharePub, _ := PublicKey(share.PrivateKey)
+		if err := Verify(sharePub, msg, sig); err != nil {
+			t.Fatalf("Failed verifying partial signature [%s]", err)
+		}
+		partials = append(partials, &PartialSignature{Index: share.Index, Signature: sig})
+	}
+
+	// Any 3 shares produce the signature of the key
+	expected, _ := Sign(priv, msg)
+	for _, subset := range [][]int{{0, 1, 2}, {4, 2, 0}, {1, 3, 4}} {
+		var selected []*PartialSignature
+		for _, i := range subset {
+			selected = append(selected, partials[i])
+		}
+		sig, err := CombineSignatures(3, selected)
+		if err != nil {
+			t.Fatalf("Failed combining partial signatures [%s]", err)
+		}
+		if !bytes.Equal(sig, expected) {
+			t.Fatalf("Combined signature of shares %v differs from the signature of the key", subset)
+		}
+		if err := Verify(pub, msg, sig); err != nil {
+			t.Fatalf("Failed verifying combined signature [%s]", err)
+		}
+	}
+
+	// 2 shares are not enough
+	if _, err := CombineSignatures(3, partials[:2]); err == nil {
+		t.Fatal("Combining fewer partial signatures than the threshold should fail")
+	}
+	sig, err := CombineSignatures(2, partials[:2])
+	if err != nil {
+		t.Fatalf("Failed combining partial signatures [%s]", err)
+	}
+	if err := Verify(pub, msg, sig); err == nil {
+		t.Fatal("Fewer shares than the threshold should not produce a valid signature")
+	}
+
+	if _, err := CombineSignatures(3, []*PartialSignature{partials[0], partials[0], partials[1]}); err == nil {
+		t.Fatal("Combining duplicate partial signatures should fail")
+	}
+	if _, err := SplitKey(priv, 6, 5, rand.Reader); err == nil {
+		t.Fatal("A threshold larger than the number of shares should be rejected")
+	}
+}
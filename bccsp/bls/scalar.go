@@ -0,0 +1,290 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bls
+
+import (
+	"encoding/binary"
+	"math/big"
+	"math/bits"
+
+	bls12381 "github.com/kilic/bls12-381"
+)
+
+// scalar is an integer modulo the group order q, in little endian
+// 64 bits limbs. Unlike big.Int, and the scalar multiplications of
+// bls12381, the arithmetic on scalars takes a time independent of
+// their values, since private keys and their shares are scalars.
+type scalar [4]uint64
+
+var (
+	// q is the order of the groups
+	q scalar
+	// qInv is -q^-1 mod 2^64, for Montgomery reduction
+	qInv uint64
+	// r2 is 2^512 mod q, for converting to Montgomery form
+	r2 scalar
+	// q2 and q3 are 2q and 3q, as 5 limbs
+	q2, q3 [5]uint64
+)
+
+func init() {
+	q = scalarFromBig(order)
+
+	// Newton iteration, each step doubling the correct low bits of q^-1
+	inv := uint64(1)
+	for i := 0; i < 6; i++ {
+		inv *= 2 - q[0]*inv
+	}
+	qInv = -inv
+
+	r2 = scalarFromBig(new(big.Int).Mod(new(big.Int).Lsh(big.NewInt(1), 512), order))
+
+	q2 = wideFromBig(new(big.Int).Mul(order, big.NewInt(2)))
+	q3 = wideFromBig(new(big.Int).Mul(order, big.NewInt(3)))
+}
+
+// wideFromBig converts a public integer in [0, 2^320)
+func wideFromBig(x *big.Int) [5]uint64 {
+	var w [5]uint64
+	for i := range w {
+		w[i] = new(big.Int).Rsh(x, uint(64*i)).Uint64()
+	}
+	return w
+}
+
+// scalarFromBig converts a public integer in [0, 2^256)
+func scalarFromBig(x *big.Int) scalar {
+	w := wideFromBig(x)
+	return scalar{w[0], w[1], w[2], w[3]}
+}
+
+// setBytes sets s to the big endian integer buf, which may not be reduced
+func (s *scalar) setBytes(buf *[32]byte) {
+	for i := range s {
+		s[i] = binary.BigEndian.Uint64(buf[24-8*i:])
+	}
+}
+
+// bytes returns the big endian encoding of s
+func (s *scalar) bytes() []byte {
+	buf := make([]byte, PrivateKeySize)
+	for i := range s {
+		binary.BigEndian.PutUint64(buf[24-8*i:], s[i])
+	}
+	return buf
+}
+
+// sub sets s to a - b, and returns the borrow
+func (s *scalar) sub(a, b *scalar) uint64 {
+	var borrow uint64
+	for i := range s {
+		s[i], borrow = bits.Sub64(a[i], b[i], borrow)
+	}
+	return borrow
+}
+
+// reduce subtracts q from s if s is at least q, given carry,
+// the bit of s above its limbs
+func (s *scalar) reduce(carry uint64) {
+	var d scalar
+	borrow := d.sub(s, &q)
+	// keep s only if s < q, that is if the subtraction borrowed with no carry to pay for it
+	s.cmov(&d, 1^(borrow&^carry))
+}
+
+// cmov sets s to a if cond is 1, and leaves it unchanged if cond is 0
+func (s *scalar) cmov(a *scalar, cond uint64) {
+	mask := -cond
+	for i := range s {
+		s[i] ^= mask & (s[i] ^ a[i])
+	}
+}
+
+// isZero returns 1 if s is zero, and 0 otherwise
+func (s *scalar) isZero() uint64 {
+	v := s[0] | s[1] | s[2] | s[3]
+	return 1 ^ (v|-v)>>63
+}
+
+// isReduced returns 1 if s is less than q, and 0 otherwise
+func (s *scalar) isReduced() uint64 {
+	var d scalar
+	return d.sub(s, &q)
+}
+
+// add sets s to a + b mod q, with a and b reduced
+func (s *scalar) add(a, b *scalar) *scalar {
+	var carry uint64
+	for i := range s {
+		s[i], carry = bits.Add64(a[i], b[i], carry)
+	}
+	s.reduce(carry)
+	return s
+}
+
+// montMul sets s to a * b / 2^256 mod q, with a and b reduced
+func (s *scalar) montMul(a, b *scalar) *scalar {
+	var t [6]uint64
+	for i := 0; i < 4; i++ {
+		var c uint64
+		for j := 0; j < 4; j++ {
+			hi, lo := bits.Mul64(a[j], b[i])
+			var cc uint64
+			lo, cc = bits.Add64(lo, t[j], 0)
+			hi += cc
+			lo, cc = bits.Add64(lo, c, 0)
+			hi += cc
+			t[j], c = lo, hi
+		}
+		t[4], t[5] = bits.Add64(t[4], c, 0)
+
+		m := t[0] * qInv
+		hi, lo := bits.Mul64(m, q[0])
+		_, cc := bits.Add64(lo, t[0], 0)
+		c = hi + cc
+		for j := 1; j < 4; j++ {
+			hi, lo = bits.Mul64(m, q[j])
+			lo, cc = bits.Add64(lo, t[j], 0)
+			hi += cc
+			lo, cc = bits.Add64(lo, c, 0)
+			hi += cc
+			t[j-1], c = lo, hi
+		}
+		t[3], cc = bits.Add64(t[4], c, 0)
+		t[4] = t[5] + cc
+		t[5] = 0
+	}
+	copy(s[:], t[:4])
+	s.reduce(t[4])
+	return s
+}
+
+// mul sets s to a * b mod q, with a and b reduced
+func (s *scalar) mul(a, b *scalar) *scalar {
+	s.montMul(a, b)
+	return s.montMul(s, &r2)
+}
+
+// setWide sets s to the big endian integer buf modulo q
+func (s *scalar) setWide(buf *[48]byte) *scalar {
+	var lo, hi scalar
+	var b [32]byte
+	copy(b[:], buf[16:])
+	lo.setBytes(&b)
+	b = [32]byte{}
+	copy(b[16:], buf[:16])
+	hi.setBytes(&b)
+
+	// lo < 2^256 < 3q
+	lo.reduce(0)
+	lo.reduce(0)
+	// hi * 2^256 = hi * 2^512 / 2^256 mod q, with hi < 2^128 < q
+	hi.montMul(&hi, &r2)
+	return s.add(&lo, &hi)
+}
+
+// ladderScalar returns k + 2q or k + 3q, whichever has its bit 256
+// set, as 5 little endian limbs, for a scalar k. Both are multiples
+// of the same points as k, and, since 2q < 2^256 < 3q, one of them
+// is in [2^256, 2^257).
+func ladderScalar(k *scalar) [5]uint64 {
+	var k2, k3 [5]uint64
+	var c2, c3 uint64
+	for i := 0; i < 5; i++ {
+		var ki uint64
+		if i < 4 {
+			ki = k[i]
+		}
+		k2[i], c2 = bits.Add64(ki, q2[i], c2)
+		k3[i], c3 = bits.Add64(ki, q3[i], c3)
+	}
+
+	mask := k2[4] - 1
+	for i := range k2 {
+		k2[i] ^= mask & (k2[i] ^ k3[i])
+	}
+	return k2
+}
+
+// The ladders below compute k * p with the same sequence of additions
+// and doublings for every scalar k, swapping their operands in constant
+// time. Starting from a point with a projective z coordinate, the
+// operands of the additions are neither affine nor the identity, nor
+// equal or opposite, but with a negligible probability, so that Add
+// takes the same path at each step.
+
+// mulG1 returns k * p, for p in G1
+func mulG1(p *bls12381.PointG1, k *scalar) *bls12381.PointG1 {
+	g1 := bls12381.NewG1()
+	r0 := g1.Add(g1.New(), g1.Double(g1.New(), p), g1.Neg(g1.New(), p))
+	r1 := g1.Double(g1.New(), r0)
+	t := g1.New()
+
+	e := ladderScalar(k)
+	for i := 255; i >= 0; i-- {
+		bit := e[i/64] >> uint(i%64) & 1
+		cswapG1(r0, r1, bit)
+		r1.Set(g1.Add(t, r0, r1))
+		g1.Double(r0, r0)
+		cswapG1(r0, r1, bit)
+	}
+	return r0
+}
+
+// mulG2 returns k * p, for p in G2
+func mulG2(p *bls12381.PointG2, k *scalar) *bls12381.PointG2 {
+	g2 := bls12381.NewG2()
+	r0 := g2.Add(g2.New(), g2.Double(g2.New(), p), g2.Neg(g2.New(), p))
+	r1 := g2.Double(g2.New(), r0)
+	t := g2.New()
+
+	e := ladderScalar(k)
+	for i := 255; i >= 0; i-- {
+		bit := e[i/64] >> uint(i%64) & 1
+		cswapG2(r0, r1, bit)
+		r1.Set(g2.Add(t, r0, r1))
+		g2.Double(r0, r0)
+		cswapG2(r0, r1, bit)
+	}
+	return r0
+}
+
+// cswapG1 swaps a and b if bit is 1, and leaves them unchanged if bit is 0
+func cswapG1(a, b *bls12381.PointG1, bit uint64) {
+	mask := -bit
+	for i := range a {
+		for j := range a[i] {
+			t := mask & (a[i][j] ^ b[i][j])
+			a[i][j] ^= t
+			b[i][j] ^= t
+		}
+	}
+}
+
+// cswapG2 swaps a and b if bit is 1, and leaves them unchanged if bit is 0
+func cswapG2(a, b *bls12381.PointG2, bit uint64) {
+	mask := -bit
+	for i := range a {
+		for j := range a[i] {
+			for l := range a[i][j] {
+				t := mask & (a[i][j][l] ^ b[i][j][l])
+				a[i][j][l] ^= t
+				b[i][j][l] ^= t
+			}
+		}
+	}
+}
@@ -0,0 +1,117 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bls
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	bls12381 "github.com/kilic/bls12-381"
+)
+
+func scalarToBig(s *scalar) *big.Int {
+	return new(big.Int).SetBytes(s.bytes())
+}
+
+// testScalars returns edge case and random scalars
+func testScalars(t *testing.T) []*scalar {
+	one := big.NewInt(1)
+	values := []*big.Int{big.NewInt(0), one, big.NewInt(2), new(big.Int).Sub(order, one), new(big.Int).Rsh(order, 1)}
+	for i := 0; i < 20; i++ {
+		x, err := rand.Int(rand.Reader, order)
+		if err != nil {
+			t.Fatalf("Failed generating scalar [%s]", err)
+		}
+		values = append(values, x)
+	}
+
+	var scalars []*scalar
+	for _, x := range values {
+		s := scalarFromBig(x)
+		scalars = append(scalars, &s)
+	}
+	return scalars
+}
+
+func TestScalarArithmetic(t *testing.T) {
+	scalars := testScalars(t)
+	for _, a := range scalars {
+		for _, b := range scalars {
+			x, y := scalarToBig(a), scalarToBig(b)
+
+			sum := scalarToBig(new(scalar).add(a, b))
+			if expected := new(big.Int).Mod(new(big.Int).Add(x, y), order); sum.Cmp(expected) != 0 {
+				t.Fatalf("%x + %x = %x, expected %x", x, y, sum, expected)
+			}
+			product := scalarToBig(new(scalar).mul(a, b))
+			if expected := new(big.Int).Mod(new(big.Int).Mul(x, y), order); product.Cmp(expected) != 0 {
+				t.Fatalf("%x * %x = %x, expected %x", x, y, product, expected)
+			}
+		}
+	}
+
+	for i := 0; i < 20; i++ {
+		var buf [PrivateKeySize + 16]byte
+		if i > 0 {
+			rand.Read(buf[:])
+		}
+		if i == 1 {
+			for j := range buf {
+				buf[j] = 0xff
+			}
+		}
+		x := scalarToBig(new(scalar).setWide(&buf))
+		if expected := new(big.Int).Mod(new(big.Int).SetBytes(buf[:]), order); x.Cmp(expected) != 0 {
+			t.Fatalf("%x mod q = %x, expected %x", buf, x, expected)
+		}
+	}
+}
+
+func TestScalarFromBytes(t *testing.T) {
+	one := big.NewInt(1)
+	for _, x := range []*big.Int{one, new(big.Int).Sub(order, one)} {
+		s := scalarFromBig(x)
+		if _, err := scalarFromBytes(s.bytes()); err != nil {
+			t.Fatalf("Scalar %x should be a valid private key [%s]", x, err)
+		}
+	}
+	for _, x := range []*big.Int{big.NewInt(0), order, new(big.Int).Add(order, one), new(big.Int).Sub(new(big.Int).Lsh(one, 256), one)} {
+		s := scalarFromBig(x)
+		if _, err := scalarFromBytes(s.bytes()); err == nil {
+			t.Fatalf("Scalar %x should not be a valid private key", x)
+		}
+	}
+}
+
+func TestLadder(t *testing.T) {
+	g1, g2 := bls12381.NewG1(), bls12381.NewG2()
+	h, err := g2.HashToCurve([]byte("Hello World"), signatureDomain)
+	if err != nil {
+		t.Fatalf("Failed hashing to curve [%s]", err)
+	}
+
+	for _, k := range testScalars(t)[1:] {
+		x := scalarToBig(k)
+		if !g1.Equal(mulG1(g1.One(), k), g1.MulScalarBig(g1.New(), g1.One(), x)) {
+			t.Fatalf("Ladder in G1 disagrees with MulScalarBig for %x", x)
+		}
+		if !g2.Equal(mulG2(h, k), g2.MulScalarBig(g2.New(), h, x)) {
+			t.Fatalf("Ladder in G2 disagrees with MulScalarBig for %x", x)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bls
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	bls12381 "github.com/kilic/bls12-381"
+)
+
+// Share is a share of a private key split by SplitKey
+type Share struct {
+	// Index identifies the share, starting at 1
+	Index int
+	// PrivateKey is the private key share, which signs
+	// partial signatures and has its own public key
+	PrivateKey []byte
+}
+
+// PartialSignature is a signature by a private key share
+type PartialSignature struct {
+	// Index is the index of the share that signed
+	Index int
+	// Signature is the signature by the share
+	Signature []byte
+}
+
+// SplitKey splits priv into n shares, any t of which are required to
+// sign on behalf of priv. The shares are computed by Shamir's secret
+// sharing, with the coefficients read from rand.
+func SplitKey(priv []byte, t, n int, rand io.Reader) ([]*Share, error) {
+	if t < 1 || t > n {
+		return nil, fmt.Errorf("Invalid threshold [%d]. It must be in [1, %d].", t, n)
+	}
+	secret, err := scalarFromBytes(priv)
+	if err != nil {
+		return nil, err
+	}
+
+	// f(x) = secret + a_1 x + ... + a_(t-1) x^(t-1)
+	coefficients := []*scalar{secret}
+	for i := 1; i < t; i++ {
+		a, err := randomScalar(rand)
+		if err != nil {
+			return nil, err
+		}
+		coefficients = append(coefficients, a)
+	}
+
+	shares := make([]*Share, n)
+	for i := range shares {
+		x := scalar{uint64(i + 1)}
+		var y scalar
+		for j := len(coefficients) - 1; j >= 0; j-- {
+			y.mul(&y, &x)
+			y.add(&y, coefficients[j])
+		}
+		if y.isZero() == 1 {
+			return nil, errors.New("Failed splitting key. A share is zero.")
+		}
+		shares[i] = &Share{Index: i + 1, PrivateKey: y.bytes()}
+	}
+	return shares, nil
+}
+
+// CombineSignatures combines t partial signatures of the same message,
+// by distinct shares, into the signature of the private key the shares
+// were split from. Partial signatures beyond the first t are ignored.
+// Partial signatures should be verified against the public keys of
+// their shares beforehand, since an invalid one yields an invalid
+// signature.
+func CombineSignatures(t int, partials []*PartialSignature) ([]byte, error) {
+	if t < 1 || len(partials) < t {
+		return nil, fmt.Errorf("Not enough partial signatures [%d]. At least [%d] are required.", len(partials), t)
+	}
+	partials = partials[:t]
+
+	indices := make(map[int]bool)
+	for _, p := range partials {
+		if p == nil || p.Index < 1 {
+			return nil, errors.New("Invalid partial signature. Its index must be positive.")
+		}
+		if indices[p.Index] {
+			return nil, fmt.Errorf("Duplicate partial signature of share [%d]", p.Index)
+		}
+		indices[p.Index] = true
+	}
+
+	// sig = sum of lambda_i * sig_i, where lambda_i is the Lagrange
+	// coefficient of share i at zero. Unlike private keys, the Lagrange
+	// coefficients and the partial signatures are public, so they are
+	// multiplied with the faster, variable time, MulScalarBig.
+	g2 := bls12381.NewG2()
+	sig := g2.Zero()
+	for _, p := range partials {
+		s, err := signatureFromBytes(p.Signature)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid partial signature of share [%d] [%s]", p.Index, err)
+		}
+
+		num, den := big.NewInt(1), big.NewInt(1)
+		for _, q := range partials {
+			if q.Index == p.Index {
+				continue
+			}
+			num.Mul(num, big.NewInt(int64(q.Index)))
+			num.Mod(num, order)
+			den.Mul(den, big.NewInt(int64(q.Index-p.Index)))
+			den.Mod(den, order)
+		}
+		lambda := num.Mul(num, den.ModInverse(den, order))
+		lambda.Mod(lambda, order)
+
+		g2.Add(sig, sig, g2.MulScalarBig(g2.New(), s, lambda))
+	}
+	return g2.ToCompressed(sig), nil
+}
+
+func randomScalar(rand io.Reader) (*scalar, error) {
+	var buf [PrivateKeySize + 16]byte
+	if _, err := io.ReadFull(rand, buf[:]); err != nil {
+		return nil, fmt.Errorf("Failed reading randomness [%s]", err)
+	}
+	return new(scalar).setWide(&buf), nil
+}
@@ -0,0 +1,67 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bccsp
+
+// BLS12381KeyGenOpts contains options for BLS12-381 key generation.
+type BLS12381KeyGenOpts struct {
+	Temporary bool
+}
+
+// Algorithm returns the key generation algorithm identifier (to be used).
+func (opts *BLS12381KeyGenOpts) Algorithm() string {
+	return BLS12381
+}
+
+// Ephemeral returns true if the key to generate has to be ephemeral,
+// false otherwise.
+func (opts *BLS12381KeyGenOpts) Ephemeral() bool {
+	return opts.Temporary
+}
+
+// BLS12381PrivateKeyImportOpts contains options for BLS12-381
+// secret key importation from its 32 bytes big-endian encoding
+type BLS12381PrivateKeyImportOpts struct {
+	Temporary bool
+}
+
+// Algorithm returns the key importation algorithm identifier (to be used).
+func (opts *BLS12381PrivateKeyImportOpts) Algorithm() string {
+	return BLS12381
+}
+
+// Ephemeral returns true if the key to generate has to be ephemeral,
+// false otherwise.
+func (opts *BLS12381PrivateKeyImportOpts) Ephemeral() bool {
+	return opts.Temporary
+}
+
+// BLS12381PublicKeyImportOpts contains options for BLS12-381
+// public key importation from its 48 bytes compressed encoding
+type BLS12381PublicKeyImportOpts struct {
+	Temporary bool
+}
+
+// Algorithm returns the key importation algorithm identifier (to be used).
+func (opts *BLS12381PublicKeyImportOpts) Algorithm() string {
+	return BLS12381
+}
+
+// Ephemeral returns true if the key to generate has to be ephemeral,
+// false otherwise.
+func (opts *BLS12381PublicKeyImportOpts) Ephemeral() bool {
+	return opts.Temporary
+}
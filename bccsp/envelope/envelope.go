@@ -0,0 +1,227 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package envelope
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+
+	"github.com/hyperledger/fabric/bccsp"
+)
+
+const (
+	// envelopeVersion is the version of the format of envelopes
+	envelopeVersion = 1
+
+	// wrapLabel prefixes the context the key
+	// encryption keys of recipients are bound to
+	wrapLabel = "fabric-envelope-wrap"
+)
+
+// ErrNotRecipient is returned by DecryptWithKey when
+// the envelope is not addressed to the given key
+var ErrNotRecipient = errors.New("The key is not a recipient of the envelope")
+
+type envelope struct {
+	Version    int
+	Recipients []recipient
+	// Ciphertext is the AES-256-GCM encryption of the
+	// plaintext under the data encryption key
+	Ciphertext []byte
+}
+
+type recipient struct {
+	// SKI is the subject key identifier of the recipient's key
+	SKI []byte
+	// EphemeralKey is the ephemeral public key, in PKIX
+	// format, the key encryption key is agreed with
+	EphemeralKey []byte
+	// WrappedKey is the AES-256-GCM encryption of the data
+	// encryption key under the key encryption key
+	WrappedKey []byte
+}
+
+// EncryptForIdentities encrypts plaintext so that only the holders of
+// the private keys of certs can decrypt it, with DecryptWithKey. Like
+// ECIES, plaintext is encrypted with a fresh AES-256 key, which is then
+// encrypted for each recipient with a key agreed, via ECDH, between an
+// ephemeral key and the ECDSA key of its certificate. The ciphertext
+// authenticates additionalData, which is not encrypted and must be
+// passed to DecryptWithKey unchanged.
+func EncryptForIdentities(csp bccsp.BCCSP, plaintext, additionalData []byte, certs []*x509.Certificate) ([]byte, error) {
+	if csp == nil {
+		return nil, errors.New("Invalid BCCSP. It must not be nil.")
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("Invalid recipients. At least one certificate is required.")
+	}
+
+	dek := make([]byte, 32)
+	if _, err := rand.Read(dek); err != nil {
+		return nil, fmt.Errorf("Failed generating data encryption key [%s]", err)
+	}
+	dekKey, err := csp.KeyImport(dek, &bccsp.AES256ImportKeyOpts{Temporary: true})
+	if err != nil {
+		return nil, fmt.Errorf("Failed importing data encryption key [%s]", err)
+	}
+	ciphertext, err := csp.Encrypt(dekKey, plaintext, &bccsp.AESGCMModeOpts{AdditionalData: additionalData})
+	if err != nil {
+		return nil, fmt.Errorf("Failed encrypting plaintext [%s]", err)
+	}
+
+	env := &envelope{Version: envelopeVersion, Ciphertext: ciphertext}
+	for _, cert := range certs {
+		r, err := wrapKey(csp, dek, cert)
+		if err != nil {
+			return nil, err
+		}
+		env.Recipients = append(env.Recipients, *r)
+	}
+
+	return asn1.Marshal(*env)
+}
+
+// DecryptWithKey decrypts envelope, as returned by EncryptForIdentities,
+// with the private key k of one of its recipients. It returns
+// ErrNotRecipient if envelope is not addressed to k, and fails if
+// envelope or additionalData have been tampered with.
+func DecryptWithKey(csp bccsp.BCCSP, envelopeBytes, additionalData []byte, k bccsp.Key) ([]byte, error) {
+	if csp == nil {
+		return nil, errors.New("Invalid BCCSP. It must not be nil.")
+	}
+	if k == nil || !k.Private() {
+		return nil, errors.New("Invalid Key. It must be a private key.")
+	}
+
+	env := &envelope{}
+	rest, err := asn1.Unmarshal(envelopeBytes, env)
+	if err != nil {
+		return nil, fmt.Errorf("Failed unmarshalling envelope [%s]", err)
+	}
+	if len(rest) != 0 {
+		return nil, errors.New("Invalid envelope. Trailing data.")
+	}
+	if env.Version != envelopeVersion {
+		return nil, fmt.Errorf("Invalid envelope. Version [%d] not supported.", env.Version)
+	}
+
+	ski := k.SKI()
+	for _, r := range env.Recipients {
+		if !bytes.Equal(r.SKI, ski) {
+			continue
+		}
+
+		dek, err := unwrapKey(csp, &r, k)
+		if err != nil {
+			return nil, err
+		}
+		dekKey, err := csp.KeyImport(dek, &bccsp.AES256ImportKeyOpts{Temporary: true})
+		if err != nil {
+			return nil, fmt.Errorf("Failed importing data encryption key [%s]", err)
+		}
+		plaintext, err := csp.Decrypt(dekKey, env.Ciphertext, &bccsp.AESGCMModeOpts{AdditionalData: additionalData})
+		if err != nil {
+			return nil, fmt.Errorf("Failed decrypting envelope [%s]", err)
+		}
+		return plaintext, nil
+	}
+
+	return nil, ErrNotRecipient
+}
+
+// wrapKey encrypts dek for the holder of the private key of cert
+func wrapKey(csp bccsp.BCCSP, dek []byte, cert *x509.Certificate) (*recipient, error) {
+	pub, ok := cert.PublicKey.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("Invalid recipient [%s]. Only ECDSA keys are supported.", cert.Subject.CommonName)
+	}
+	var keyGenOpts bccsp.KeyGenOpts
+	switch pub.Curve {
+	case elliptic.P256():
+		keyGenOpts = &bccsp.ECDSAP256KeyGenOpts{Temporary: true}
+	case elliptic.P384():
+		keyGenOpts = &bccsp.ECDSAP384KeyGenOpts{Temporary: true}
+	default:
+		return nil, fmt.Errorf("Invalid recipient [%s]. Curve [%s] not supported.", cert.Subject.CommonName, pub.Curve.Params().Name)
+	}
+
+	recipientKey, err := csp.KeyImport(cert, &bccsp.X509PublicKeyImportOpts{Temporary: true})
+	if err != nil {
+		return nil, fmt.Errorf("Failed importing key of recipient [%s] [%s]", cert.Subject.CommonName, err)
+	}
+	ephemeralKey, err := csp.KeyGen(keyGenOpts)
+	if err != nil {
+		return nil, fmt.Errorf("Failed generating ephemeral key [%s]", err)
+	}
+	ephemeralPub, err := ephemeralKey.PublicKey()
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting ephemeral public key [%s]", err)
+	}
+	ephemeralRaw, err := ephemeralPub.Bytes()
+	if err != nil {
+		return nil, fmt.Errorf("Failed marshalling ephemeral public key [%s]", err)
+	}
+
+	r := &recipient{SKI: recipientKey.SKI(), EphemeralKey: ephemeralRaw}
+	kek, err := csp.KeyDeriv(ephemeralKey, &bccsp.ECDHKeyDerivOpts{
+		Temporary: true,
+		PublicKey: recipientKey,
+		Info:      r.context(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed agreeing on key encryption key [%s]", err)
+	}
+	r.WrappedKey, err = csp.Encrypt(kek, dek, &bccsp.AESGCMModeOpts{AdditionalData: r.context()})
+	if err != nil {
+		return nil, fmt.Errorf("Failed wrapping data encryption key [%s]", err)
+	}
+	return r, nil
+}
+
+// unwrapKey decrypts the data encryption key of r with k
+func unwrapKey(csp bccsp.BCCSP, r *recipient, k bccsp.Key) ([]byte, error) {
+	ephemeralPub, err := csp.KeyImport(r.EphemeralKey, &bccsp.ECDSAPKIXPublicKeyImportOpts{Temporary: true})
+	if err != nil {
+		return nil, fmt.Errorf("Failed importing ephemeral public key [%s]", err)
+	}
+	kek, err := csp.KeyDeriv(k, &bccsp.ECDHKeyDerivOpts{
+		Temporary: true,
+		PublicKey: ephemeralPub,
+		Info:      r.context(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed agreeing on key encryption key [%s]", err)
+	}
+	dek, err := csp.Decrypt(kek, r.WrappedKey, &bccsp.AESGCMModeOpts{AdditionalData: r.context()})
+	if err != nil {
+		return nil, fmt.Errorf("Failed unwrapping data encryption key [%s]", err)
+	}
+	return dek, nil
+}
+
+// context returns what the key encryption key of r is bound to:
+// the key of the recipient and the ephemeral key
+func (r *recipient) context() []byte {
+	context := append([]byte(wrapLabel), r.SKI...)
+	return append(context, r.EphemeralKey...)
+}
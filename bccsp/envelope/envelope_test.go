@@ -0,0 +1,153 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package envelope
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric/bccsp/sw"
+)
+
+func newTestBCCSP(t *testing.T) bccsp.BCCSP {
+	csp, err := sw.New(256, "SHA2", sw.NewDummyKeyStore())
+	if err != nil {
+		t.Fatalf("Failed initializing BCCSP [%s]", err)
+	}
+	return csp
+}
+
+// newRecipient returns a self-signed certificate of a key of
+// curve, and that key imported in csp
+func newRecipient(t *testing.T, csp bccsp.BCCSP, curve elliptic.Curve) (*x509.Certificate, bccsp.Key) {
+	priv, err := ecdsa.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed generating key [%s]", err)
+	}
+	cert := newCertificate(t, &priv.PublicKey, priv)
+
+	der, err := x509.MarshalECPrivateKey(priv)
+	if err != nil {
+		t.Fatalf("Failed marshalling key [%s]", err)
+	}
+	k, err := csp.KeyImport(der, &bccsp.ECDSAPrivateKeyImportOpts{Temporary: true})
+	if err != nil {
+		t.Fatalf("Failed importing key [%s]", err)
+	}
+	return cert, k
+}
+
+func newCertificate(t *testing.T, pub interface{}, priv crypto.Signer) *x509.Certificate {
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "peer0"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, pub, priv)
+	if err != nil {
+		t.Fatalf("Failed creating certificate [%s]", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("Failed parsing certificate [%s]", err)
+	}
+	return cert
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	csp := newTestBCCSP(t)
+	plaintext := []byte("private data")
+	aad := []byte("aad")
+
+	cert1, key1 := newRecipient(t, csp, elliptic.P256())
+	cert2, key2 := newRecipient(t, csp, elliptic.P384())
+	_, other := newRecipient(t, csp, elliptic.P256())
+
+	env, err := EncryptForIdentities(csp, plaintext, aad, []*x509.Certificate{cert1, cert2})
+	if err != nil {
+		t.Fatalf("Failed encrypting [%s]", err)
+	}
+	if bytes.Contains(env, plaintext) {
+		t.Fatal("The envelope should not hold the plaintext")
+	}
+
+	// Each recipient decrypts
+	for _, k := range []bccsp.Key{key1, key2} {
+		decrypted, err := DecryptWithKey(csp, env, aad, k)
+		if err != nil {
+			t.Fatalf("Failed decrypting [%s]", err)
+		}
+		if !bytes.Equal(decrypted, plaintext) {
+			t.Fatalf("Wrong plaintext. Expected [%s], got [%s]", plaintext, decrypted)
+		}
+	}
+
+	// Others do not
+	if _, err := DecryptWithKey(csp, env, aad, other); err != ErrNotRecipient {
+		t.Fatalf("Expected ErrNotRecipient, got [%v]", err)
+	}
+
+	// Tampering is detected
+	if _, err := DecryptWithKey(csp, env, []byte("other aad"), key1); err == nil {
+		t.Fatal("Decrypting with other additional data should fail")
+	}
+	tampered := append([]byte(nil), env...)
+	tampered[len(tampered)-1] ^= 1
+	if _, err := DecryptWithKey(csp, tampered, aad, key1); err == nil {
+		t.Fatal("Decrypting a tampered envelope should fail")
+	}
+	if _, err := DecryptWithKey(csp, []byte("envelope"), aad, key1); err == nil {
+		t.Fatal("Decrypting a malformed envelope should fail")
+	}
+
+	// Public keys cannot decrypt
+	pub, _ := key1.PublicKey()
+	if _, err := DecryptWithKey(csp, env, aad, pub); err == nil {
+		t.Fatal("Decrypting with a public key should fail")
+	}
+}
+
+func TestInvalidRecipients(t *testing.T) {
+	csp := newTestBCCSP(t)
+
+	if _, err := EncryptForIdentities(csp, []byte("data"), nil, nil); err == nil {
+		t.Fatal("Encrypting for no recipient should fail")
+	}
+	if _, err := EncryptForIdentities(nil, []byte("data"), nil, nil); err == nil {
+		t.Fatal("Encrypting with a nil BCCSP should fail")
+	}
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed generating key [%s]", err)
+	}
+	rsaCert := newCertificate(t, &rsaKey.PublicKey, rsaKey)
+	if _, err := EncryptForIdentities(csp, []byte("data"), nil, []*x509.Certificate{rsaCert}); err == nil {
+		t.Fatal("Encrypting for an RSA recipient should fail")
+	}
+}
@@ -117,6 +117,15 @@ func InitFactories(config *FactoryOpts) error {
 			}
 		}
 
+		// KMS-Based BCCSP
+		if config.KmsOpts != nil {
+			f := &KMSFactory{}
+			err := initBCCSP(f, config)
+			if err != nil {
+				factoriesInitError = fmt.Errorf("%s\n[%s]", factoriesInitError, err)
+			}
+		}
+
 		var ok bool
 		defaultBCCSP, ok = bccspMap[config.ProviderName]
 		if !ok {
@@ -0,0 +1,100 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package factory
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric/bccsp/kms"
+	"github.com/hyperledger/fabric/bccsp/sw"
+)
+
+const (
+	// KMSBasedFactoryName is the name of the factory of the KMS-based BCCSP implementation
+	KMSBasedFactoryName = "KMS"
+)
+
+// KMSFactory is the factory of the BCCSP whose private keys
+// are kept, and used to sign, in an external KMS.
+type KMSFactory struct{}
+
+// Name returns the name of this factory
+func (f *KMSFactory) Name() string {
+	return KMSBasedFactoryName
+}
+
+// Get returns an instance of BCCSP using Opts.
+func (f *KMSFactory) Get(config *FactoryOpts) (bccsp.BCCSP, error) {
+	// Validate arguments
+	if config == nil || config.KmsOpts == nil {
+		return nil, errors.New("Invalid config. It must not be nil.")
+	}
+
+	kmsOpts := config.KmsOpts
+
+	var client kms.Client
+	switch {
+	case kmsOpts.Vault != nil:
+		token := kmsOpts.Vault.Token
+		if token == "" {
+			token = os.Getenv("VAULT_TOKEN")
+		}
+
+		var err error
+		client, err = kms.NewVaultTransitClient(kmsOpts.Vault.Address, token, kmsOpts.Vault.Mount, kmsOpts.Vault.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("Failed initializing Vault client [%s]", err)
+		}
+	default:
+		return nil, errors.New("Invalid config. No KMS configured.")
+	}
+
+	// Ephemeral keys and public keys are handled in software,
+	// and never stored, so that no key touches the filesystem
+	csp, err := sw.New(kmsOpts.SecLevel, kmsOpts.HashFamily, sw.NewDummyKeyStore())
+	if err != nil {
+		return nil, fmt.Errorf("Failed initializing software BCCSP [%s]", err)
+	}
+
+	return kms.New(client, csp)
+}
+
+// KMSOpts contains options for the KMSFactory
+type KMSOpts struct {
+	// Algorithms of the operations performed in software
+	SecLevel   int    `mapstructure:"security" json:"security"`
+	HashFamily string `mapstructure:"hash" json:"hash"`
+
+	// Vault configures the transit secrets engine of HashiCorp Vault
+	Vault *VaultOpts `mapstructure:"vault,omitempty" json:"vault,omitempty"`
+}
+
+// VaultOpts contains options for the transit secrets engine of HashiCorp Vault
+type VaultOpts struct {
+	Address string `mapstructure:"address" json:"address"`
+	// Token authenticates the requests to Vault.
+	// It defaults to the VAULT_TOKEN environment variable.
+	Token string `mapstructure:"token,omitempty" json:"token,omitempty"`
+	// Mount is the path the transit secrets engine is
+	// mounted at. It defaults to transit.
+	Mount   string        `mapstructure:"mount,omitempty" json:"mount,omitempty"`
+	Timeout time.Duration `mapstructure:"timeout,omitempty" json:"timeout,omitempty"`
+}
@@ -20,6 +20,7 @@ type FactoryOpts struct {
 	ProviderName string      `mapstructure:"default" json:"default"`
 	SwOpts       *SwOpts     `mapstructure:"SW,omitempty" json:"SW,omitempty"`
 	Pkcs11Opts   *PKCS11Opts `mapstructure:"PKCS11,omitempty" json:"PKCS11,omitempty"`
+	KmsOpts      *KMSOpts    `mapstructure:"KMS,omitempty" json:"KMS,omitempty"`
 }
 
 var DefaultOpts = FactoryOpts{
@@ -60,7 +60,7 @@ func (f *PKCS11Factory) Get(config *FactoryOpts) (bccsp.BCCSP, error) {
 		// Default to DummyKeystore
 		ks = sw.NewDummyKeyStore()
 	}
-	err := pkcs11.InitPKCS11(p11Opts.Library, p11Opts.Pin, p11Opts.Label)
+	err := pkcs11.InitPKCS11WithMaxSessions(p11Opts.Library, p11Opts.Pin, p11Opts.Label, p11Opts.MaxSessions)
 	if err != nil {
 		return nil, fmt.Errorf("Failed initializing PKCS11 library %s %s [%s]",
 			p11Opts.Library, p11Opts.Label, err)
@@ -85,4 +85,8 @@ type PKCS11Opts struct {
 	Pin        string `mapstructure:"pin" json:"pin"`
 	Sensitive  bool   `mapstructure:"sensitivekeys,omitempty" json:"sensitivekeys,omitempty"`
 	SoftVerify bool   `mapstructure:"softwareverify,omitempty" json:"softwareverify,omitempty"`
+
+	// MaxSessions is the maximum number of sessions open
+	// with the token at once. Zero sets the default.
+	MaxSessions int `mapstructure:"maxsessions,omitempty" json:"maxsessions,omitempty"`
 }
@@ -59,7 +59,7 @@ func (f *SWFactory) Get(config *FactoryOpts) (bccsp.BCCSP, error) {
 		ks = sw.NewDummyKeyStore()
 	}
 
-	return sw.New(swOpts.SecLevel, swOpts.HashFamily, ks)
+	return sw.NewWithMinRSAKeySize(swOpts.SecLevel, swOpts.HashFamily, ks, swOpts.MinRSAKeySize)
 }
 
 // SwOpts contains options for the SWFactory
@@ -68,6 +68,10 @@ type SwOpts struct {
 	SecLevel   int    `mapstructure:"security" json:"security"`
 	HashFamily string `mapstructure:"hash" json:"hash"`
 
+	// MinRSAKeySize is the minimum length, in bits, of the RSA keys
+	// the BCCSP generates or imports. Zero sets no minimum.
+	MinRSAKeySize int `mapstructure:"minrsakeysize,omitempty" json:"minrsakeysize,omitempty"`
+
 	// Keystore Options
 	Ephemeral     bool               `mapstructure:"tempkeys,omitempty" json:"tempkeys,omitempty"`
 	FileKeystore  *FileKeystoreOpts  `mapstructure:"filekeystore,omitempty" json:"filekeystore,omitempty"`
@@ -0,0 +1,70 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kms
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"errors"
+
+	"github.com/hyperledger/fabric/bccsp"
+)
+
+// kmsPrivateKey is a private key held by a KMS
+type kmsPrivateKey struct {
+	// name is the name of the key in the KMS
+	name string
+	// ecdsaPub is the public key of the key
+	ecdsaPub *ecdsa.PublicKey
+	// pub is ecdsaPub, imported in the delegate BCCSP
+	pub bccsp.Key
+}
+
+// Bytes converts this key to its byte representation,
+// if this operation is allowed.
+func (k *kmsPrivateKey) Bytes() (raw []byte, err error) {
+	return nil, errors.New("Not supported.")
+}
+
+// SKI returns the subject key identifier of this key,
+// which is the same as the software BCCSP's.
+func (k *kmsPrivateKey) SKI() (ski []byte) {
+	raw := elliptic.Marshal(k.ecdsaPub.Curve, k.ecdsaPub.X, k.ecdsaPub.Y)
+
+	hash := sha256.New()
+	hash.Write(raw)
+	return hash.Sum(nil)
+}
+
+// Symmetric returns true if this key is a symmetric key,
+// false if this key is asymmetric
+func (k *kmsPrivateKey) Symmetric() bool {
+	return false
+}
+
+// Private returns true if this key is a private key,
+// false otherwise.
+func (k *kmsPrivateKey) Private() bool {
+	return true
+}
+
+// PublicKey returns the corresponding public key part of an asymmetric public/private key pair.
+// This method returns an error in symmetric key schemes.
+func (k *kmsPrivateKey) PublicKey() (bccsp.Key, error) {
+	return k.pub, nil
+}
@@ -0,0 +1,155 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kms
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/hyperledger/fabric/bccsp"
+	"github.com/op/go-logging"
+)
+
+var logger = logging.MustGetLogger("KMS_BCCSP")
+
+// keyStore is a read only bccsp.KeyStore over the keys of a KMS
+type keyStore struct {
+	client Client
+	// csp imports the public keys of the KMS keys
+	csp bccsp.BCCSP
+
+	lock sync.Mutex
+	// keys maps hex encoded SKIs to the keys of the KMS
+	keys map[string]*kmsPrivateKey
+	// names is the set of the names of the keys in keys
+	names map[string]struct{}
+}
+
+// NewKeyStore returns a read only KeyStore exposing the private keys of
+// the KMS of client, whose public keys are imported, ephemerally, in csp.
+// Keys are created in the KMS by the BCCSP returned by New.
+func NewKeyStore(client Client, csp bccsp.BCCSP) (bccsp.KeyStore, error) {
+	if client == nil {
+		return nil, errors.New("Invalid KMS client. It must not be nil.")
+	}
+	if csp == nil {
+		return nil, errors.New("Invalid BCCSP. It must not be nil.")
+	}
+	return newKeyStore(client, csp), nil
+}
+
+func newKeyStore(client Client, csp bccsp.BCCSP) *keyStore {
+	return &keyStore{
+		client: client,
+		csp:    csp,
+		keys:   make(map[string]*kmsPrivateKey),
+		names:  make(map[string]struct{}),
+	}
+}
+
+// ReadOnly returns true, as keys are only created in the KMS
+func (ks *keyStore) ReadOnly() bool {
+	return true
+}
+
+// GetKey returns the key of the KMS whose SKI is ski.
+// The KMS is listed again if no such key is known yet.
+func (ks *keyStore) GetKey(ski []byte) (bccsp.Key, error) {
+	if len(ski) == 0 {
+		return nil, errors.New("Invalid SKI. Cannot be of zero length.")
+	}
+
+	ks.lock.Lock()
+	defer ks.lock.Unlock()
+
+	if k, ok := ks.keys[hex.EncodeToString(ski)]; ok {
+		return k, nil
+	}
+
+	names, err := ks.client.ListKeys()
+	if err != nil {
+		return nil, fmt.Errorf("Failed listing KMS keys [%s]", err)
+	}
+	for _, name := range names {
+		if _, ok := ks.names[name]; ok {
+			continue
+		}
+
+		der, err := ks.client.PublicKey(name)
+		if err != nil {
+			return nil, fmt.Errorf("Failed getting public key of KMS key [%s] [%s]", name, err)
+		}
+		k, err := ks.newKey(name, der)
+		if err != nil {
+			// Keys of other types may live in the KMS
+			logger.Debugf("Skipping KMS key [%s] [%s]", name, err)
+			ks.names[name] = struct{}{}
+			continue
+		}
+		ks.add(k)
+	}
+
+	if k, ok := ks.keys[hex.EncodeToString(ski)]; ok {
+		return k, nil
+	}
+	return nil, fmt.Errorf("Key [%x] not found in the KMS", ski)
+}
+
+// StoreKey fails, as keys are only created in the KMS
+func (ks *keyStore) StoreKey(k bccsp.Key) error {
+	return errors.New("Read only KeyStore. Keys are created in the KMS.")
+}
+
+// createKey creates a new key in the KMS
+func (ks *keyStore) createKey() (*kmsPrivateKey, error) {
+	name, der, err := ks.client.CreateKey()
+	if err != nil {
+		return nil, fmt.Errorf("Failed creating KMS key [%s]", err)
+	}
+
+	k, err := ks.newKey(name, der)
+	if err != nil {
+		return nil, err
+	}
+
+	ks.lock.Lock()
+	defer ks.lock.Unlock()
+	ks.add(k)
+
+	return k, nil
+}
+
+func (ks *keyStore) newKey(name string, der []byte) (*kmsPrivateKey, error) {
+	ecdsaPub, err := parsePublicKey(der)
+	if err != nil {
+		return nil, err
+	}
+
+	pub, err := ks.csp.KeyImport(ecdsaPub, &bccsp.ECDSAGoPublicKeyImportOpts{Temporary: true})
+	if err != nil {
+		return nil, fmt.Errorf("Failed importing public key of KMS key [%s] [%s]", name, err)
+	}
+
+	return &kmsPrivateKey{name: name, ecdsaPub: ecdsaPub, pub: pub}, nil
+}
+
+func (ks *keyStore) add(k *kmsPrivateKey) {
+	ks.keys[hex.EncodeToString(k.SKI())] = k
+	ks.names[k.name] = struct{}{}
+}
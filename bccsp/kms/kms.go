@@ -0,0 +1,180 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kms
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric/bccsp/utils"
+)
+
+// Client is the interface to an external key management service (KMS)
+// holding ECDSA private keys, which never leave it.
+type Client interface {
+	// CreateKey creates an ECDSA P-256 key pair in the KMS and
+	// returns its name and its public key, in PKIX DER format
+	CreateKey() (name string, publicKey []byte, err error)
+
+	// ListKeys returns the names of the keys of the KMS
+	ListKeys() ([]string, error)
+
+	// PublicKey returns the public key, in PKIX DER format,
+	// of the key named name
+	PublicKey(name string) ([]byte, error)
+
+	// Sign signs digest with the private key named name,
+	// and returns the ASN.1 DER encoded ECDSA signature
+	Sign(name string, digest []byte) ([]byte, error)
+}
+
+// New returns a BCCSP whose non-ephemeral ECDSA keys are generated and
+// kept in the KMS of client, and which delegates signing with them to
+// that KMS. Ephemeral keys and all other operations are delegated to csp,
+// which also holds the public keys of the KMS keys.
+func New(client Client, csp bccsp.BCCSP) (bccsp.BCCSP, error) {
+	if client == nil {
+		return nil, errors.New("Invalid KMS client. It must not be nil.")
+	}
+	if csp == nil {
+		return nil, errors.New("Invalid BCCSP. It must not be nil.")
+	}
+
+	return &impl{BCCSP: csp, ks: newKeyStore(client, csp)}, nil
+}
+
+type impl struct {
+	bccsp.BCCSP
+	ks *keyStore
+}
+
+// KeyGen generates a key using opts. Non-ephemeral ECDSA
+// P-256 keys are generated in the KMS.
+func (csp *impl) KeyGen(opts bccsp.KeyGenOpts) (bccsp.Key, error) {
+	if opts == nil {
+		return nil, errors.New("Invalid Opts parameter. It must not be nil.")
+	}
+
+	switch opts.(type) {
+	case *bccsp.ECDSAKeyGenOpts, *bccsp.ECDSAP256KeyGenOpts:
+		if !opts.Ephemeral() {
+			return csp.ks.createKey()
+		}
+	}
+	return csp.BCCSP.KeyGen(opts)
+}
+
+// GetKey returns the key this CSP associates to
+// the Subject Key Identifier ski.
+func (csp *impl) GetKey(ski []byte) (bccsp.Key, error) {
+	k, err := csp.ks.GetKey(ski)
+	if err == nil {
+		return k, nil
+	}
+
+	k, err2 := csp.BCCSP.GetKey(ski)
+	if err2 != nil {
+		return nil, fmt.Errorf("Key [%x] not found in the KMS [%s] nor locally [%s]", ski, err, err2)
+	}
+	return k, nil
+}
+
+// RotateKey generates a new KMS key and returns the SKIs of k and of
+// the new key. Retiring k is left to the administrators of the KMS.
+func (csp *impl) RotateKey(k bccsp.Key, opts bccsp.KeyGenOpts) (oldSKI, newSKI []byte, err error) {
+	if _, ok := k.(*kmsPrivateKey); !ok {
+		return csp.BCCSP.RotateKey(k, opts)
+	}
+
+	newKey, err := csp.ks.createKey()
+	if err != nil {
+		return nil, nil, err
+	}
+	return k.SKI(), newKey.SKI(), nil
+}
+
+// Sign signs digest using key k. Signatures
+// of KMS keys are produced by the KMS.
+func (csp *impl) Sign(k bccsp.Key, digest []byte, opts bccsp.SignerOpts) ([]byte, error) {
+	kk, ok := k.(*kmsPrivateKey)
+	if !ok {
+		return csp.BCCSP.Sign(k, digest, opts)
+	}
+	if len(digest) == 0 {
+		return nil, errors.New("Invalid digest. Cannot be empty.")
+	}
+
+	start := time.Now()
+	signature, err := csp.ks.client.Sign(kk.name, digest)
+	if err != nil {
+		return nil, fmt.Errorf("Failed signing with KMS key [%s] [%s]", kk.name, err)
+	}
+	logger.Debugf("KMS key [%s] signed in [%s]", kk.name, time.Since(start))
+
+	// Fabric only accepts low-S signatures, which KMSes do not enforce
+	return toLowS(kk.ecdsaPub, signature)
+}
+
+// Verify verifies signature against key k and digest
+func (csp *impl) Verify(k bccsp.Key, signature, digest []byte, opts bccsp.SignerOpts) (bool, error) {
+	if kk, ok := k.(*kmsPrivateKey); ok {
+		k = kk.pub
+	}
+	return csp.BCCSP.Verify(k, signature, digest, opts)
+}
+
+type ecdsaSignature struct {
+	R, S *big.Int
+}
+
+// toLowS returns signature with its S value in the lower half of the
+// order of the curve of k, as the software BCCSP requires
+func toLowS(k *ecdsa.PublicKey, signature []byte) ([]byte, error) {
+	sig := &ecdsaSignature{}
+	if _, err := asn1.Unmarshal(signature, sig); err != nil {
+		return nil, fmt.Errorf("Failed unmarshalling KMS signature [%s]", err)
+	}
+	if sig.R == nil || sig.S == nil {
+		return nil, errors.New("Invalid KMS signature. R and S must be different from nil.")
+	}
+
+	halfOrder := new(big.Int).Rsh(k.Curve.Params().N, 1)
+	if sig.S.Cmp(halfOrder) == 1 {
+		sig.S.Sub(k.Curve.Params().N, sig.S)
+	}
+	return asn1.Marshal(*sig)
+}
+
+// parsePublicKey parses a P-256 public key in PKIX DER format
+func parsePublicKey(der []byte) (*ecdsa.PublicKey, error) {
+	pub, err := utils.DERToPublicKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing KMS public key [%s]", err)
+	}
+
+	ecdsaPub, ok := pub.(*ecdsa.PublicKey)
+	if !ok || ecdsaPub.Curve != elliptic.P256() {
+		return nil, errors.New("Invalid KMS public key. It must be an ECDSA P-256 key.")
+	}
+	return ecdsaPub, nil
+}
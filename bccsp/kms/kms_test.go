@@ -0,0 +1,241 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kms
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/asn1"
+	"fmt"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric/bccsp/sw"
+)
+
+// mockClient is an in-memory KMS which
+// only produces high-S signatures
+type mockClient struct {
+	lock sync.Mutex
+	keys map[string]*ecdsa.PrivateKey
+}
+
+func newMockClient() *mockClient {
+	return &mockClient{keys: make(map[string]*ecdsa.PrivateKey)}
+}
+
+func (c *mockClient) CreateKey() (string, []byte, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return "", nil, err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	name := fmt.Sprintf("key%d", len(c.keys))
+	c.keys[name] = key
+
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	return name, der, err
+}
+
+func (c *mockClient) ListKeys() ([]string, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	var names []string
+	for name := range c.keys {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func (c *mockClient) PublicKey(name string) ([]byte, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	key, ok := c.keys[name]
+	if !ok {
+		return nil, fmt.Errorf("No key [%s]", name)
+	}
+	return x509.MarshalPKIXPublicKey(&key.PublicKey)
+}
+
+func (c *mockClient) Sign(name string, digest []byte) ([]byte, error) {
+	c.lock.Lock()
+	key, ok := c.keys[name]
+	c.lock.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("No key [%s]", name)
+	}
+
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest)
+	if err != nil {
+		return nil, err
+	}
+	if s.Cmp(new(big.Int).Rsh(key.Params().N, 1)) <= 0 {
+		s.Sub(key.Params().N, s)
+	}
+	return asn1.Marshal(ecdsaSignature{r, s})
+}
+
+func newTestCSP(t *testing.T, client Client) bccsp.BCCSP {
+	swCSP, err := sw.New(256, "SHA2", sw.NewDummyKeyStore())
+	if err != nil {
+		t.Fatalf("Failed initializing software BCCSP [%s]", err)
+	}
+	csp, err := New(client, swCSP)
+	if err != nil {
+		t.Fatalf("Failed initializing KMS BCCSP [%s]", err)
+	}
+	return csp
+}
+
+func TestKMSKeyGenSignVerify(t *testing.T) {
+	client := newMockClient()
+	csp := newTestCSP(t, client)
+
+	k, err := csp.KeyGen(&bccsp.ECDSAP256KeyGenOpts{Temporary: false})
+	if err != nil {
+		t.Fatalf("Failed generating KMS key [%s]", err)
+	}
+	if _, ok := k.(*kmsPrivateKey); !ok {
+		t.Fatalf("Non-ephemeral keys should be generated in the KMS, got [%T]", k)
+	}
+	if len(client.keys) != 1 {
+		t.Fatalf("Expected 1 key in the KMS, got [%d]", len(client.keys))
+	}
+	if _, err := k.Bytes(); err == nil {
+		t.Fatal("KMS private keys should not be exported")
+	}
+
+	digest, err := csp.Hash([]byte("Hello World"), &bccsp.SHAOpts{})
+	if err != nil {
+		t.Fatalf("Failed hashing [%s]", err)
+	}
+
+	// The KMS produces high-S signatures, which are normalized
+	signature, err := csp.Sign(k, digest, nil)
+	if err != nil {
+		t.Fatalf("Failed signing [%s]", err)
+	}
+
+	valid, err := csp.Verify(k, signature, digest, nil)
+	if err != nil || !valid {
+		t.Fatalf("Failed verifying signature [%v]", err)
+	}
+
+	pk, err := k.PublicKey()
+	if err != nil {
+		t.Fatalf("Failed getting public key [%s]", err)
+	}
+	if string(pk.SKI()) != string(k.SKI()) {
+		t.Fatalf("SKIs are different [%x]!=[%x]", pk.SKI(), k.SKI())
+	}
+	valid, err = csp.Verify(pk, signature, digest, nil)
+	if err != nil || !valid {
+		t.Fatalf("Failed verifying signature with public key [%v]", err)
+	}
+
+	// Ephemeral keys are generated in software
+	ek, err := csp.KeyGen(&bccsp.ECDSAP256KeyGenOpts{Temporary: true})
+	if err != nil {
+		t.Fatalf("Failed generating ephemeral key [%s]", err)
+	}
+	if _, ok := ek.(*kmsPrivateKey); ok {
+		t.Fatal("Ephemeral keys should not be generated in the KMS")
+	}
+	if len(client.keys) != 1 {
+		t.Fatalf("Expected 1 key in the KMS, got [%d]", len(client.keys))
+	}
+}
+
+func TestKMSGetKey(t *testing.T) {
+	client := newMockClient()
+	k, err := newTestCSP(t, client).KeyGen(&bccsp.ECDSAKeyGenOpts{})
+	if err != nil {
+		t.Fatalf("Failed generating KMS key [%s]", err)
+	}
+
+	// Another instance finds the key in the KMS
+	csp := newTestCSP(t, client)
+	k2, err := csp.GetKey(k.SKI())
+	if err != nil {
+		t.Fatalf("Failed getting KMS key [%s]", err)
+	}
+	if k2.(*kmsPrivateKey).name != k.(*kmsPrivateKey).name {
+		t.Fatalf("Expected key [%s], got [%s]", k.(*kmsPrivateKey).name, k2.(*kmsPrivateKey).name)
+	}
+
+	if _, err := csp.GetKey([]byte{1, 2, 3}); err == nil {
+		t.Fatal("Getting an unknown key should fail")
+	}
+}
+
+func TestKMSRotateKey(t *testing.T) {
+	client := newMockClient()
+	csp := newTestCSP(t, client)
+
+	k, err := csp.KeyGen(&bccsp.ECDSAKeyGenOpts{})
+	if err != nil {
+		t.Fatalf("Failed generating KMS key [%s]", err)
+	}
+
+	oldSKI, newSKI, err := csp.RotateKey(k, nil)
+	if err != nil {
+		t.Fatalf("Failed rotating KMS key [%s]", err)
+	}
+	if string(oldSKI) != string(k.SKI()) || string(oldSKI) == string(newSKI) {
+		t.Fatalf("Unexpected SKIs [%x] [%x]", oldSKI, newSKI)
+	}
+	if _, err := csp.GetKey(newSKI); err != nil {
+		t.Fatalf("Failed getting the new KMS key [%s]", err)
+	}
+}
+
+func TestKMSKeyStore(t *testing.T) {
+	client := newMockClient()
+	csp := newTestCSP(t, client)
+
+	ks, err := NewKeyStore(client, csp)
+	if err != nil {
+		t.Fatalf("Failed initializing KMS key store [%s]", err)
+	}
+	if !ks.ReadOnly() {
+		t.Fatal("The KMS key store should be read only")
+	}
+
+	k, err := csp.KeyGen(&bccsp.ECDSAKeyGenOpts{})
+	if err != nil {
+		t.Fatalf("Failed generating KMS key [%s]", err)
+	}
+	if err := ks.StoreKey(k); err == nil {
+		t.Fatal("Storing keys in the KMS key store should fail")
+	}
+	if _, err := ks.GetKey(k.SKI()); err != nil {
+		t.Fatalf("Failed getting KMS key [%s]", err)
+	}
+
+	if _, err := NewKeyStore(nil, csp); err == nil {
+		t.Fatal("A nil client should be rejected")
+	}
+	if _, err := New(client, nil); err == nil {
+		t.Fatal("A nil BCCSP should be rejected")
+	}
+}
@@ -0,0 +1,216 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kms
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// vaultKeyPrefix prefixes the names of the keys created in Vault
+const vaultKeyPrefix = "fabric-"
+
+// NewVaultTransitClient returns a Client of the transit secrets engine
+// of the HashiCorp Vault at address, mounted at mount, which is "transit"
+// if empty. Requests are authenticated with token and time out after
+// timeout, or 30 seconds if timeout is zero.
+func NewVaultTransitClient(address, token, mount string, timeout time.Duration) (Client, error) {
+	if address == "" {
+		return nil, errors.New("Invalid Vault address. It must not be empty.")
+	}
+	if token == "" {
+		return nil, errors.New("Invalid Vault token. It must not be empty.")
+	}
+	if mount == "" {
+		mount = "transit"
+	}
+	if timeout == 0 {
+		timeout = 30 * time.Second
+	}
+
+	return &vaultTransitClient{
+		url:    strings.TrimSuffix(address, "/") + "/v1/" + strings.Trim(mount, "/"),
+		token:  token,
+		client: &http.Client{Timeout: timeout},
+	}, nil
+}
+
+type vaultTransitClient struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+// CreateKey creates an ecdsa-p256 key in Vault
+func (c *vaultTransitClient) CreateKey() (string, []byte, error) {
+	suffix := make([]byte, 16)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", nil, err
+	}
+	name := vaultKeyPrefix + hex.EncodeToString(suffix)
+
+	if err := c.do("POST", "/keys/"+name, map[string]interface{}{"type": "ecdsa-p256"}, nil); err != nil {
+		return "", nil, err
+	}
+
+	der, err := c.PublicKey(name)
+	if err != nil {
+		return "", nil, err
+	}
+	return name, der, nil
+}
+
+// ListKeys returns the names of the keys created by CreateKey
+func (c *vaultTransitClient) ListKeys() ([]string, error) {
+	resp := &struct {
+		Data struct {
+			Keys []string `json:"keys"`
+		} `json:"data"`
+	}{}
+	if err := c.do("LIST", "/keys", nil, resp); err != nil {
+		if err == errVaultNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var names []string
+	for _, name := range resp.Data.Keys {
+		if strings.HasPrefix(name, vaultKeyPrefix) {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// PublicKey returns the public key of the
+// latest version of the key named name
+func (c *vaultTransitClient) PublicKey(name string) ([]byte, error) {
+	resp := &struct {
+		Data struct {
+			LatestVersion int `json:"latest_version"`
+			Keys          map[string]struct {
+				PublicKey string `json:"public_key"`
+			} `json:"keys"`
+		} `json:"data"`
+	}{}
+	if err := c.do("GET", "/keys/"+name, nil, resp); err != nil {
+		return nil, err
+	}
+
+	version, ok := resp.Data.Keys[strconv.Itoa(resp.Data.LatestVersion)]
+	if !ok {
+		return nil, fmt.Errorf("No public key for version [%d] of key [%s]", resp.Data.LatestVersion, name)
+	}
+	block, _ := pem.Decode([]byte(version.PublicKey))
+	if block == nil {
+		return nil, fmt.Errorf("Failed decoding public key of key [%s]", name)
+	}
+	return block.Bytes, nil
+}
+
+// Sign signs the prehashed digest with the key named name
+func (c *vaultTransitClient) Sign(name string, digest []byte) ([]byte, error) {
+	var hashAlgorithm string
+	switch len(digest) {
+	case sha256.Size:
+		hashAlgorithm = "sha2-256"
+	case sha512.Size384:
+		hashAlgorithm = "sha2-384"
+	default:
+		return nil, fmt.Errorf("Invalid digest length [%d]", len(digest))
+	}
+
+	req := map[string]interface{}{
+		"input":                base64.StdEncoding.EncodeToString(digest),
+		"prehashed":            true,
+		"marshaling_algorithm": "asn1",
+	}
+	resp := &struct {
+		Data struct {
+			Signature string `json:"signature"`
+		} `json:"data"`
+	}{}
+	if err := c.do("POST", "/sign/"+name+"/"+hashAlgorithm, req, resp); err != nil {
+		return nil, err
+	}
+
+	// Signatures are of the form vault:v<version>:<base64 signature>
+	parts := strings.SplitN(resp.Data.Signature, ":", 3)
+	if len(parts) != 3 || parts[0] != "vault" {
+		return nil, fmt.Errorf("Invalid signature format [%s]", resp.Data.Signature)
+	}
+	return base64.StdEncoding.DecodeString(parts[2])
+}
+
+var errVaultNotFound = errors.New("Not found")
+
+func (c *vaultTransitClient) do(method, path string, reqBody, respBody interface{}) error {
+	var body bytes.Buffer
+	if reqBody != nil {
+		if err := json.NewEncoder(&body).Encode(reqBody); err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequest(method, c.url+path, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Vault-Token", c.token)
+	if reqBody != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("Vault request [%s %s] failed [%s]", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	raw, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("Failed reading Vault response [%s]", err)
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return errVaultNotFound
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("Vault request [%s %s] failed with status [%d] [%s]", method, path, resp.StatusCode, raw)
+	}
+
+	if respBody == nil || len(raw) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(raw, respBody); err != nil {
+		return fmt.Errorf("Failed unmarshalling Vault response [%s]", err)
+	}
+	return nil
+}
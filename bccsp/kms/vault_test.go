@@ -0,0 +1,160 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kms
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// newMockVault returns a server emulating the
+// transit secrets engine of Vault, mounted at transit
+func newMockVault(t *testing.T, token string) *httptest.Server {
+	var lock sync.Mutex
+	keys := map[string]*ecdsa.PrivateKey{"other": nil}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		if r.Header.Get("X-Vault-Token") != token {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		path := strings.Split(strings.TrimPrefix(r.URL.Path, "/v1/transit/"), "/")
+		reply := func(data interface{}) {
+			json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+		}
+
+		switch {
+		case r.Method == "LIST" && path[0] == "keys":
+			var names []string
+			for name := range keys {
+				names = append(names, name)
+			}
+			reply(map[string]interface{}{"keys": names})
+
+		case r.Method == "POST" && path[0] == "keys":
+			key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+			keys[path[1]] = key
+			w.WriteHeader(http.StatusNoContent)
+
+		case r.Method == "GET" && path[0] == "keys":
+			key, ok := keys[path[1]]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			der, _ := x509.MarshalPKIXPublicKey(&key.PublicKey)
+			reply(map[string]interface{}{
+				"latest_version": 1,
+				"keys": map[string]interface{}{
+					"1": map[string]string{"public_key": string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))},
+				},
+			})
+
+		case r.Method == "POST" && path[0] == "sign" && path[2] == "sha2-256":
+			req := map[string]interface{}{}
+			json.NewDecoder(r.Body).Decode(&req)
+			if req["prehashed"] != true || req["marshaling_algorithm"] != "asn1" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			digest, _ := base64.StdEncoding.DecodeString(req["input"].(string))
+			signature, _ := keys[path[1]].Sign(rand.Reader, digest, nil)
+			reply(map[string]string{"signature": "vault:v1:" + base64.StdEncoding.EncodeToString(signature)})
+
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+}
+
+func TestVaultTransitClient(t *testing.T) {
+	server := newMockVault(t, "token")
+	defer server.Close()
+
+	client, err := NewVaultTransitClient(server.URL, "token", "", 0)
+	if err != nil {
+		t.Fatalf("Failed initializing Vault client [%s]", err)
+	}
+
+	name, der, err := client.CreateKey()
+	if err != nil {
+		t.Fatalf("Failed creating key [%s]", err)
+	}
+	if !strings.HasPrefix(name, vaultKeyPrefix) {
+		t.Fatalf("Key name [%s] should start with [%s]", name, vaultKeyPrefix)
+	}
+	pub, err := parsePublicKey(der)
+	if err != nil {
+		t.Fatalf("Failed parsing public key [%s]", err)
+	}
+
+	// Keys not created by Fabric are not listed
+	names, err := client.ListKeys()
+	if err != nil {
+		t.Fatalf("Failed listing keys [%s]", err)
+	}
+	if len(names) != 1 || names[0] != name {
+		t.Fatalf("Expected keys [%s], got %v", name, names)
+	}
+
+	digest := sha256.Sum256([]byte("Hello World"))
+	signature, err := client.Sign(name, digest[:])
+	if err != nil {
+		t.Fatalf("Failed signing [%s]", err)
+	}
+	if !ecdsa.VerifyASN1(pub, digest[:], signature) {
+		t.Fatal("Invalid signature")
+	}
+
+	if _, err := client.Sign(name, []byte{1, 2, 3}); err == nil {
+		t.Fatal("Signing a digest of invalid length should fail")
+	}
+	if _, err := client.PublicKey("missing"); err == nil {
+		t.Fatal("Getting a missing key should fail")
+	}
+
+	// Unauthenticated requests
+	client, err = NewVaultTransitClient(server.URL, "bad token", "", 0)
+	if err != nil {
+		t.Fatalf("Failed initializing Vault client [%s]", err)
+	}
+	if _, _, err := client.CreateKey(); err == nil {
+		t.Fatal("Requests with an invalid token should fail")
+	}
+
+	if _, err := NewVaultTransitClient("", "token", "", 0); err == nil {
+		t.Fatal("An empty address should be rejected")
+	}
+	if _, err := NewVaultTransitClient(server.URL, "", "", 0); err == nil {
+		t.Fatal("An empty token should be rejected")
+	}
+}
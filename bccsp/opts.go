@@ -16,6 +16,8 @@ limitations under the License.
 
 package bccsp
 
+import "crypto"
+
 const (
 	// ECDSA Elliptic Curve Digital Signature Algorithm (key gen, import, sign, verify),
 	// at default security level.
@@ -32,6 +34,20 @@ const (
 	// ECDSAReRand ECDSA key re-randomization
 	ECDSAReRand = "ECDSA_RERAND"
 
+	// ECDH Elliptic Curve Diffie-Hellman key agreement between
+	// an ECDSA private key and a public key of the same curve
+	ECDH = "ECDH"
+
+	// ED25519 Edwards-curve Digital Signature Algorithm over Curve25519
+	// (key gen, import, sign, verify). Messages are signed as they are,
+	// without being hashed first.
+	ED25519 = "ED25519"
+
+	// BLS12381 BLS signature scheme over the BLS12-381 curve (key gen,
+	// import, sign, verify). Messages are signed as they are, without
+	// being hashed first, and signatures can be aggregated.
+	BLS12381 = "BLS12381"
+
 	// RSA at the default security level.
 	// Each BCCSP may or may not support default security level. If not supported than
 	// an error will be returned.
@@ -166,6 +182,45 @@ func (opts *ECDSAReRandKeyOpts) ExpansionValue() []byte {
 	return opts.Expansion
 }
 
+// ECDHKeyDerivOpts contains options for deriving, from an ECDSA private
+// key, the AES-256 key it agrees on with PublicKey via ECDH. The key is
+// expanded from the shared secret with HKDF-SHA256, using Info.
+type ECDHKeyDerivOpts struct {
+	Temporary bool
+	// PublicKey is the ECDSA public key of the other party
+	PublicKey Key
+	// Info binds the derived key to its context
+	Info []byte
+}
+
+// Algorithm returns the key derivation algorithm identifier (to be used).
+func (opts *ECDHKeyDerivOpts) Algorithm() string {
+	return ECDH
+}
+
+// Ephemeral returns true if the key to generate has to be ephemeral,
+// false otherwise.
+func (opts *ECDHKeyDerivOpts) Ephemeral() bool {
+	return opts.Temporary
+}
+
+// ECDSADeterministicSignerOpts contains options for ECDSA signing
+// with deterministic nonces, as specified by RFC 6979.
+type ECDSADeterministicSignerOpts struct {
+	// Hash is the hash function the digest has been computed with,
+	// and the nonces are derived with. If zero, the SHA-2 function
+	// with the size of the digest is used, SHA-256 by default.
+	Hash crypto.Hash
+}
+
+// HashFunc returns Hash, or SHA-256 if zero.
+func (opts *ECDSADeterministicSignerOpts) HashFunc() crypto.Hash {
+	if opts.Hash == 0 {
+		return crypto.SHA256
+	}
+	return opts.Hash
+}
+
 // AESKeyGenOpts contains options for AES key generation at default security level
 type AESKeyGenOpts struct {
 	Temporary bool
@@ -186,6 +241,14 @@ func (opts *AESKeyGenOpts) Ephemeral() bool {
 // with PKCS7 padding.
 type AESCBCPKCS7ModeOpts struct{}
 
+// AESGCMModeOpts contains options for AES encryption in GCM mode,
+// which authenticates both the ciphertext and AdditionalData.
+// AdditionalData is not encrypted, and the same AdditionalData
+// must be passed to decrypt the ciphertext.
+type AESGCMModeOpts struct {
+	AdditionalData []byte
+}
+
 // HMACTruncated256AESDeriveKeyOpts contains options for HMAC truncated
 // at 256 bits key derivation.
 type HMACTruncated256AESDeriveKeyOpts struct {
@@ -304,6 +367,22 @@ func (opts *RSAGoPublicKeyImportOpts) Ephemeral() bool {
 	return opts.Temporary
 }
 
+// RSAPrivateKeyImportOpts contains options for RSA secret key importation in PKCS#1 or PKCS#8 format
+type RSAPrivateKeyImportOpts struct {
+	Temporary bool
+}
+
+// Algorithm returns the key importation algorithm identifier (to be used).
+func (opts *RSAPrivateKeyImportOpts) Algorithm() string {
+	return RSA
+}
+
+// Ephemeral returns true if the key to generate has to be ephemeral,
+// false otherwise.
+func (opts *RSAPrivateKeyImportOpts) Ephemeral() bool {
+	return opts.Temporary
+}
+
 // X509PublicKeyImportOpts contains options for importing public keys from an x509 certificate
 type X509PublicKeyImportOpts struct {
 	Temporary bool
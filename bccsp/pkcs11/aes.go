@@ -135,3 +135,49 @@ func AESCBCPKCS7Decrypt(key, src []byte) ([]byte, error) {
 
 	return original, nil
 }
+
+// AESGCMEncrypt encrypts src in GCM mode, authenticating both src and
+// additionalData. The random nonce is prepended to the ciphertext.
+func AESGCMEncrypt(key, src, additionalData []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize(), gcm.NonceSize()+len(src)+gcm.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	return gcm.Seal(nonce, nonce, src, additionalData), nil
+}
+
+// AESGCMDecrypt decrypts src, as returned by AESGCMEncrypt, and checks
+// that neither src nor additionalData have been tampered with
+func AESGCMDecrypt(key, src, additionalData []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(src) < gcm.NonceSize()+gcm.Overhead() {
+		return nil, errors.New("Invalid ciphertext. It is too short")
+	}
+
+	plaintext, err := gcm.Open(nil, src[:gcm.NonceSize()], src[gcm.NonceSize():], additionalData)
+	if err != nil {
+		return nil, fmt.Errorf("Failed decrypting ciphertext [%s]", err)
+	}
+
+	return plaintext, nil
+}
@@ -605,6 +605,78 @@ func (csp *impl) GetKey(ski []byte) (k bccsp.Key, err error) {
 	return csp.ks.GetKey(ski)
 }
 
+// RotateKey generates a new, non-ephemeral, version of the private
+// or secret key k using opts and retires k. If opts is nil, the new
+// key is of the same type as k. An EC key pair stored in the HSM is
+// re-labeled as retired, keeping its CKA_ID, hence it is still returned
+// by GetKey. If re-labeling fails, the SKIs are returned along with
+// the error, as the new key exists.
+func (csp *impl) RotateKey(k bccsp.Key, opts bccsp.KeyGenOpts) (oldSKI, newSKI []byte, err error) {
+	// Validate arguments
+	if k == nil {
+		return nil, nil, errors.New("Invalid Key. It must not be nil.")
+	}
+	if !k.Private() {
+		return nil, nil, errors.New("Invalid Key. Only private and secret keys can be rotated.")
+	}
+	if opts == nil {
+		if opts, err = keyGenOptsFor(k); err != nil {
+			return nil, nil, err
+		}
+	}
+	if opts.Ephemeral() {
+		return nil, nil, errors.New("Invalid Opts parameter. Rotated keys must not be ephemeral.")
+	}
+
+	newKey, err := csp.KeyGen(opts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed generating new version of key [%x]: [%s]", k.SKI(), err)
+	}
+
+	if _, isHSMKey := k.(*ecdsaPrivateKey); isHSMKey {
+		if err := retireECKey(k.SKI()); err != nil {
+			return k.SKI(), newKey.SKI(), fmt.Errorf("Failed re-labeling retired key [%x]: [%s]", k.SKI(), err)
+		}
+	}
+
+	return k.SKI(), newKey.SKI(), nil
+}
+
+// keyGenOptsFor returns the options generating
+// a non-ephemeral key of the same type as k
+func keyGenOptsFor(k bccsp.Key) (bccsp.KeyGenOpts, error) {
+	switch key := k.(type) {
+	case *ecdsaPrivateKey:
+		switch key.pub.pub.Curve {
+		case elliptic.P256():
+			return &bccsp.ECDSAP256KeyGenOpts{}, nil
+		case elliptic.P384():
+			return &bccsp.ECDSAP384KeyGenOpts{}, nil
+		}
+	case *rsaPrivateKey:
+		switch key.privKey.N.BitLen() {
+		case 1024:
+			return &bccsp.RSA1024KeyGenOpts{}, nil
+		case 2048:
+			return &bccsp.RSA2048KeyGenOpts{}, nil
+		case 3072:
+			return &bccsp.RSA3072KeyGenOpts{}, nil
+		case 4096:
+			return &bccsp.RSA4096KeyGenOpts{}, nil
+		}
+	case *aesPrivateKey:
+		switch len(key.privKey) {
+		case 16:
+			return &bccsp.AES128KeyGenOpts{}, nil
+		case 24:
+			return &bccsp.AES192KeyGenOpts{}, nil
+		case 32:
+			return &bccsp.AES256KeyGenOpts{}, nil
+		}
+	}
+	return nil, fmt.Errorf("Cannot infer key generation options for key type [%T]", k)
+}
+
 // Hash hashes messages msg using options opts.
 func (csp *impl) Hash(msg []byte, opts bccsp.HashOpts) (digest []byte, err error) {
 	var h hash.Hash
@@ -752,6 +824,9 @@ func (csp *impl) Encrypt(k bccsp.Key, plaintext []byte, opts bccsp.EncrypterOpts
 		case *bccsp.AESCBCPKCS7ModeOpts, bccsp.AESCBCPKCS7ModeOpts:
 			// AES in CBC mode with PKCS7 padding
 			return AESCBCPKCS7Encrypt(k.(*aesPrivateKey).privKey, plaintext)
+		case *bccsp.AESGCMModeOpts:
+			// AES in GCM mode
+			return AESGCMEncrypt(k.(*aesPrivateKey).privKey, plaintext, opts.(*bccsp.AESGCMModeOpts).AdditionalData)
 		default:
 			return nil, fmt.Errorf("Mode not recognized [%s]", opts)
 		}
@@ -776,6 +851,9 @@ func (csp *impl) Decrypt(k bccsp.Key, ciphertext []byte, opts bccsp.DecrypterOpt
 		case *bccsp.AESCBCPKCS7ModeOpts, bccsp.AESCBCPKCS7ModeOpts:
 			// AES in CBC mode with PKCS7 padding
 			return AESCBCPKCS7Decrypt(k.(*aesPrivateKey).privKey, ciphertext)
+		case *bccsp.AESGCMModeOpts:
+			// AES in GCM mode
+			return AESGCMDecrypt(k.(*aesPrivateKey).privKey, ciphertext, opts.(*bccsp.AESGCMModeOpts).AdditionalData)
 		default:
 			return nil, fmt.Errorf("Mode not recognized [%s]", opts)
 		}
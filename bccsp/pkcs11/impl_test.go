@@ -1960,3 +1960,38 @@ func findPKCS11Lib() (lib, pin, label string) {
 	}
 	return lib, pin, label
 }
+
+func TestRotateKey(t *testing.T) {
+	for _, opts := range []bccsp.KeyGenOpts{
+		&bccsp.ECDSAP256KeyGenOpts{},
+		&bccsp.AES256KeyGenOpts{},
+	} {
+		k, err := currentBCCSP.KeyGen(opts)
+		if err != nil {
+			t.Fatalf("Failed generating key [%s]", err)
+		}
+
+		oldSKI, newSKI, err := currentBCCSP.RotateKey(k, nil)
+		if err != nil {
+			t.Fatalf("Failed rotating key [%s]", err)
+		}
+		if !bytes.Equal(oldSKI, k.SKI()) {
+			t.Fatal("Old SKI must be the SKI of the rotated key")
+		}
+		if bytes.Equal(oldSKI, newSKI) {
+			t.Fatal("New SKI must be different from the old one")
+		}
+
+		// Both the retired and the new key are available
+		if _, err := currentBCCSP.GetKey(oldSKI); err != nil {
+			t.Fatalf("Failed getting old key [%s]", err)
+		}
+		if _, err := currentBCCSP.GetKey(newSKI); err != nil {
+			t.Fatalf("Failed getting new key [%s]", err)
+		}
+	}
+
+	if _, _, err := currentBCCSP.RotateKey(nil, nil); err == nil {
+		t.Fatal("Rotating a nil key must fail")
+	}
+}
@@ -25,6 +25,7 @@ import (
 	"math/big"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/miekg/pkcs11"
 	"github.com/op/go-logging"
@@ -32,23 +33,32 @@ import (
 
 var (
 	ctx             *pkcs11.Ctx
-	sessions        = make(chan pkcs11.SessionHandle, 2000)
-	slot            uint
+	pool            *sessionPool
 	probablySoftHSM = false //Only needed for KeyImport
 )
 
 func InitPKCS11(lib, pin, label string) error {
+	return InitPKCS11WithMaxSessions(lib, pin, label, 0)
+}
+
+// InitPKCS11WithMaxSessions initializes the PKCS#11 library as InitPKCS11
+// does, keeping at most maxSessions sessions with the token open at once.
+// Zero or a negative value sets the default maximum.
+func InitPKCS11WithMaxSessions(lib, pin, label string, maxSessions int) error {
 	if strings.Contains(lib, "softhsm") {
 		probablySoftHSM = true
 	}
-	return loadLib(lib, pin, label)
+	return loadLib(lib, pin, label, maxSessions)
 }
 
-func loadLib(lib, pin, label string) error {
+func loadLib(lib, pin, label string, maxSessions int) error {
 	logger.Debugf("Loading pkcs11 library [%s]\n", lib)
 	if lib == "" {
 		return fmt.Errorf("No PKCS11 library default")
 	}
+	if pin == "" {
+		return fmt.Errorf("No PIN set\n")
+	}
 
 	ctx = pkcs11.New(lib)
 	if ctx == nil {
@@ -56,74 +66,69 @@ func loadLib(lib, pin, label string) error {
 	}
 
 	ctx.Initialize()
-	slots, err := ctx.GetSlotList(true)
+	slot, err := findSlot(label)
 	if err != nil {
 		return err
 	}
-	found := false
-	for _, s := range slots {
-		info, err := ctx.GetTokenInfo(s)
-		if err != nil {
-			continue
-		}
-		if label == info.Label {
-			found = true
-			slot = s
-			break
+
+	// Reinitializing the module invalidates all the sessions, and is only
+	// attempted when no session can be opened, after a loss of connectivity
+	reinit := func() (uint, error) {
+		logger.Warningf("Reinitializing pkcs11 library [%s]\n", lib)
+		ctx.Finalize()
+		if err := ctx.Initialize(); err != nil {
+			return 0, err
 		}
+		return findSlot(label)
 	}
-	if !found {
-		return fmt.Errorf("Could not find token with label %s", label)
-	}
-	session := getSession()
-	defer returnSession(session)
+	pool = newSessionPool(ctx, slot, pin, maxSessions, reinit)
 
-	if pin == "" {
-		return fmt.Errorf("No PIN set\n")
-	}
-	err = ctx.Login(session, pkcs11.CKU_USER, pin)
+	session, err := getSession()
 	if err != nil {
-		return fmt.Errorf("Login failed [%s]\n", err)
+		return err
 	}
+	returnSession(session)
 
 	return nil
 }
 
-func getSession() (session pkcs11.SessionHandle) {
-	select {
-	case session = <-sessions:
-		logger.Debugf("Reusing existing pkcs11 session %x on slot %d\n", session, slot)
-
-	default:
-		// create one
-		var s pkcs11.SessionHandle
-		var err error = nil
-		for i := 0; i < 10; i++ {
-			s, err = ctx.OpenSession(slot, pkcs11.CKF_SERIAL_SESSION|pkcs11.CKF_RW_SESSION)
-			if err != nil {
-				logger.Warningf("OpenSession failed, retrying [%s]\n", err)
-			} else {
-				break
-			}
-		}
+// findSlot returns the slot of the token with label label
+func findSlot(label string) (uint, error) {
+	slots, err := ctx.GetSlotList(true)
+	if err != nil {
+		return 0, err
+	}
+	for _, s := range slots {
+		info, err := ctx.GetTokenInfo(s)
 		if err != nil {
-			logger.Fatalf("OpenSession [%s]\n", err)
+			continue
 		}
-		logger.Debugf("Created new pkcs11 session %x on slot %d\n", session, slot)
-		session = s
+		if label == info.Label {
+			return s, nil
+		}
+	}
+	return 0, fmt.Errorf("Could not find token with label %s", label)
+}
+
+func getSession() (pkcs11.SessionHandle, error) {
+	if pool == nil {
+		return 0, fmt.Errorf("PKCS11 library not initialized")
 	}
-	return session
+	return pool.get()
 }
 
 func returnSession(session pkcs11.SessionHandle) {
-	sessions <- session
+	pool.put(session)
 }
 
 // Look for an EC key by SKI, stored in CKA_ID
 // This function can probably be addapted for both EC and RSA keys.
 func getECKey(ski []byte) (pubKey *ecdsa.PublicKey, isPriv bool, err error) {
 	p11lib := ctx
-	session := getSession()
+	session, err := getSession()
+	if err != nil {
+		return nil, false, err
+	}
 	defer returnSession(session)
 	isPriv = true
 	_, err = findKeyPairFromSKI(p11lib, session, ski, isPrivateKey)
@@ -214,7 +219,10 @@ func oidFromNamedCurve(curve elliptic.Curve) (asn1.ObjectIdentifier, bool) {
 
 func generateECKey(curve asn1.ObjectIdentifier, ephemeral bool) (ski []byte, pubKey *ecdsa.PublicKey, err error) {
 	p11lib := ctx
-	session := getSession()
+	session, err := getSession()
+	if err != nil {
+		return nil, nil, err
+	}
 	defer returnSession(session)
 
 	id := nextIDCtr()
@@ -300,7 +308,10 @@ func generateECKey(curve asn1.ObjectIdentifier, ephemeral bool) (ski []byte, pub
 
 func signECDSA(ski []byte, msg []byte) (R, S *big.Int, err error) {
 	p11lib := ctx
-	session := getSession()
+	session, err := getSession()
+	if err != nil {
+		return nil, nil, err
+	}
 	defer returnSession(session)
 
 	privateKey, err := findKeyPairFromSKI(p11lib, session, ski, isPrivateKey)
@@ -330,7 +341,10 @@ func signECDSA(ski []byte, msg []byte) (R, S *big.Int, err error) {
 
 func verifyECDSA(ski []byte, msg []byte, R, S *big.Int, byteSize int) (valid bool, err error) {
 	p11lib := ctx
-	session := getSession()
+	session, err := getSession()
+	if err != nil {
+		return false, err
+	}
 	defer returnSession(session)
 
 	logger.Debugf("Verify ECDSA\n")
@@ -366,7 +380,10 @@ func verifyECDSA(ski []byte, msg []byte, R, S *big.Int, byteSize int) (valid boo
 
 func importECKey(curve asn1.ObjectIdentifier, privKey, ecPt []byte, ephemeral bool, isPrivate bool) (ski []byte, err error) {
 	p11lib := ctx
-	session := getSession()
+	session, err := getSession()
+	if err != nil {
+		return nil, err
+	}
 	defer returnSession(session)
 
 	id := nextIDCtr()
@@ -472,6 +489,35 @@ func findKeyPairFromSKI(mod *pkcs11.Ctx, session pkcs11.SessionHandle, ski []byt
 	return &objs[0], nil
 }
 
+// Re-label the EC key pair of SKI ski as retired. CKA_ID is kept,
+// so that the key pair can still be found by SKI
+func retireECKey(ski []byte) error {
+	p11lib := ctx
+	session, err := getSession()
+	if err != nil {
+		return err
+	}
+	defer returnSession(session)
+
+	label := fmt.Sprintf("BCRETIRED%s", time.Now().UTC().Format("20060102150405"))
+	setlabel_t := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, label),
+	}
+
+	for _, isPrivate := range []bool{isPublicKey, isPrivateKey} {
+		key, err := findKeyPairFromSKI(p11lib, session, ski, isPrivate)
+		if err != nil {
+			return fmt.Errorf("Key not found [%s]", err)
+		}
+		if err := p11lib.SetAttributeValue(session, *key, setlabel_t); err != nil {
+			return fmt.Errorf("P11: set-label failed [%s]", err)
+		}
+	}
+
+	logger.Infof("Retired P11 key, SKI %x, label %s\n", ski, label)
+	return nil
+}
+
 // Fairly straightforward EC-point query, other than opencryptoki
 // mis-reporting length, including the 04 Tag of the field following
 // the SPKI in EP11-returned MACed publickeys:
@@ -583,10 +629,18 @@ func listAttrs(p11lib *pkcs11.Ctx, session pkcs11.SessionHandle, obj pkcs11.Obje
 
 func getSecretValue(ski []byte) []byte {
 	p11lib := ctx
-	session := getSession()
+	session, err := getSession()
+	if err != nil {
+		logger.Warningf("P11: get session [%s]\n", err)
+		return nil
+	}
 	defer returnSession(session)
 
 	keyHandle, err := findKeyPairFromSKI(p11lib, session, ski, isPrivateKey)
+	if err != nil {
+		logger.Warningf("P11: find key [%s]\n", err)
+		return nil
+	}
 
 	var privKey []byte
 	template := []*pkcs11.Attribute{
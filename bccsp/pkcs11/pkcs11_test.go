@@ -31,7 +31,10 @@ func TestPKCS11GetSession(t *testing.T) {
 		t.SkipNow()
 	}
 
-	session := getSession()
+	session, err := getSession()
+	if err != nil {
+		t.Fatalf("Failed getting session [%s]", err)
+	}
 	defer returnSession(session)
 }
 
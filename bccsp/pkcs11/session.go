@@ -0,0 +1,202 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkcs11
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/miekg/pkcs11"
+)
+
+const (
+	// defaultMaxSessions is the default maximum number of sessions open at once
+	defaultMaxSessions = 2000
+
+	// maxOpenAttempts is the number of attempts at opening a session
+	maxOpenAttempts = 10
+
+	// Session states, see CKS_* in the PKCS#11 specification
+	cksROPublicSession = 0
+	cksRWPublicSession = 2
+)
+
+// openRetryDelay is the delay before the second attempt at opening a
+// session, and grows linearly with the number of attempts
+var openRetryDelay = 100 * time.Millisecond
+
+// sessionModule is the subset of the PKCS#11 API a sessionPool uses
+type sessionModule interface {
+	OpenSession(slotID uint, flags uint) (pkcs11.SessionHandle, error)
+	CloseSession(sh pkcs11.SessionHandle) error
+	GetSessionInfo(sh pkcs11.SessionHandle) (pkcs11.SessionInfo, error)
+	Login(sh pkcs11.SessionHandle, userType uint, pin string) error
+}
+
+// sessionPool pools the sessions with a PKCS#11 token. Sessions are checked
+// before being reused: dead sessions are discarded, and sessions the token
+// logged out are logged in again. Once sessions cannot be opened anymore,
+// the module is reinitialized. Hence, the pool recovers from a loss of
+// connectivity with the HSM without restarting the peer.
+type sessionPool struct {
+	module sessionModule
+	pin    string
+
+	// reinit reinitializes the module and returns the slot of the token
+	reinit func() (uint, error)
+
+	lock sync.Mutex
+	slot uint
+
+	// idle holds the sessions not in use
+	idle chan pkcs11.SessionHandle
+	// open holds a token for each open session
+	open chan struct{}
+}
+
+func newSessionPool(module sessionModule, slot uint, pin string, maxSessions int, reinit func() (uint, error)) *sessionPool {
+	if maxSessions <= 0 {
+		maxSessions = defaultMaxSessions
+	}
+
+	return &sessionPool{
+		module: module,
+		pin:    pin,
+		reinit: reinit,
+		slot:   slot,
+		idle:   make(chan pkcs11.SessionHandle, maxSessions),
+		open:   make(chan struct{}, maxSessions),
+	}
+}
+
+// get returns a healthy session, logged in as the user. It blocks
+// while the maximum number of sessions are open and in use.
+func (p *sessionPool) get() (pkcs11.SessionHandle, error) {
+	for {
+		var session pkcs11.SessionHandle
+		select {
+		case session = <-p.idle:
+		default:
+			select {
+			case session = <-p.idle:
+			case p.open <- struct{}{}:
+				s, err := p.openSession()
+				if err != nil {
+					<-p.open
+					return 0, err
+				}
+				logger.Debugf("Created new pkcs11 session %x on slot %d\n", s, p.getSlot())
+				return s, nil
+			}
+		}
+
+		if err := p.check(session); err != nil {
+			logger.Warningf("Discarding pkcs11 session %x [%s]\n", session, err)
+			p.discard(session)
+			continue
+		}
+		logger.Debugf("Reusing existing pkcs11 session %x on slot %d\n", session, p.getSlot())
+		return session, nil
+	}
+}
+
+// put returns session to the pool
+func (p *sessionPool) put(session pkcs11.SessionHandle) {
+	p.idle <- session
+}
+
+// discard closes session, which is not returned to the pool
+func (p *sessionPool) discard(session pkcs11.SessionHandle) {
+	p.module.CloseSession(session)
+	<-p.open
+}
+
+// check returns an error if session is dead,
+// and logs it in again if the token logged it out
+func (p *sessionPool) check(session pkcs11.SessionHandle) error {
+	info, err := p.module.GetSessionInfo(session)
+	if err != nil {
+		return err
+	}
+
+	if info.State == cksROPublicSession || info.State == cksRWPublicSession {
+		logger.Infof("pkcs11 session %x is logged out, logging in again\n", session)
+		return p.login(session)
+	}
+	return nil
+}
+
+func (p *sessionPool) login(session pkcs11.SessionHandle) error {
+	err := p.module.Login(session, pkcs11.CKU_USER, p.pin)
+	if err != nil && err != pkcs11.Error(pkcs11.CKR_USER_ALREADY_LOGGED_IN) {
+		return fmt.Errorf("Login failed [%s]", err)
+	}
+	return nil
+}
+
+func (p *sessionPool) openSession() (pkcs11.SessionHandle, error) {
+	var err error
+	for i := 0; i < maxOpenAttempts; i++ {
+		if i > 0 {
+			time.Sleep(time.Duration(i) * openRetryDelay)
+
+			// The connection with the HSM may have been lost
+			if err = p.reinitialize(); err != nil {
+				logger.Warningf("Reinitializing pkcs11 module failed, retrying [%s]\n", err)
+				continue
+			}
+		}
+
+		var session pkcs11.SessionHandle
+		session, err = p.module.OpenSession(p.getSlot(), pkcs11.CKF_SERIAL_SESSION|pkcs11.CKF_RW_SESSION)
+		if err != nil {
+			logger.Warningf("OpenSession failed, retrying [%s]\n", err)
+			continue
+		}
+
+		if err = p.login(session); err != nil {
+			logger.Warningf("%s, retrying\n", err)
+			p.module.CloseSession(session)
+			continue
+		}
+		return session, nil
+	}
+	return 0, fmt.Errorf("OpenSession failed [%s]", err)
+}
+
+func (p *sessionPool) reinitialize() error {
+	if p.reinit == nil {
+		return nil
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	slot, err := p.reinit()
+	if err != nil {
+		return err
+	}
+	p.slot = slot
+	return nil
+}
+
+func (p *sessionPool) getSlot() uint {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.slot
+}
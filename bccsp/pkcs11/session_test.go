@@ -0,0 +1,217 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkcs11
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/miekg/pkcs11"
+)
+
+// mockModule emulates a token whose sessions can be
+// lost, and which can become unreachable
+type mockModule struct {
+	lock        sync.Mutex
+	next        pkcs11.SessionHandle
+	sessions    map[pkcs11.SessionHandle]uint
+	unreachable bool
+	loggedIn    bool
+	logins      int
+}
+
+func newMockModule() *mockModule {
+	return &mockModule{sessions: make(map[pkcs11.SessionHandle]uint)}
+}
+
+func (m *mockModule) OpenSession(slotID uint, flags uint) (pkcs11.SessionHandle, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.unreachable {
+		return 0, pkcs11.Error(pkcs11.CKR_DEVICE_ERROR)
+	}
+	m.next++
+	m.sessions[m.next] = cksRWPublicSession
+	if m.loggedIn {
+		m.sessions[m.next] = cksRWPublicSession + 1
+	}
+	return m.next, nil
+}
+
+func (m *mockModule) CloseSession(sh pkcs11.SessionHandle) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	delete(m.sessions, sh)
+	return nil
+}
+
+func (m *mockModule) GetSessionInfo(sh pkcs11.SessionHandle) (pkcs11.SessionInfo, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	state, ok := m.sessions[sh]
+	if !ok {
+		return pkcs11.SessionInfo{}, pkcs11.Error(pkcs11.CKR_SESSION_HANDLE_INVALID)
+	}
+	return pkcs11.SessionInfo{State: state}, nil
+}
+
+func (m *mockModule) Login(sh pkcs11.SessionHandle, userType uint, pin string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if pin != "1234" {
+		return pkcs11.Error(pkcs11.CKR_PIN_INCORRECT)
+	}
+	if m.loggedIn {
+		return pkcs11.Error(pkcs11.CKR_USER_ALREADY_LOGGED_IN)
+	}
+	// Logging in a session logs in all the sessions
+	m.loggedIn = true
+	m.logins++
+	for s := range m.sessions {
+		m.sessions[s] = cksRWPublicSession + 1
+	}
+	return nil
+}
+
+// drop emulates the loss of all the sessions
+func (m *mockModule) drop() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.sessions = make(map[pkcs11.SessionHandle]uint)
+	m.loggedIn = false
+}
+
+func TestSessionPool(t *testing.T) {
+	m := newMockModule()
+	p := newSessionPool(m, 0, "1234", 2, nil)
+
+	s1, err := p.get()
+	if err != nil {
+		t.Fatalf("Failed getting session [%s]", err)
+	}
+	if m.logins != 1 {
+		t.Fatalf("New sessions should be logged in, got [%d] logins", m.logins)
+	}
+	p.put(s1)
+
+	// Healthy sessions are reused
+	s2, err := p.get()
+	if err != nil {
+		t.Fatalf("Failed getting session [%s]", err)
+	}
+	if s2 != s1 {
+		t.Fatalf("Expected session [%d] to be reused, got [%d]", s1, s2)
+	}
+
+	// At most 2 sessions are open at once
+	s3, err := p.get()
+	if err != nil {
+		t.Fatalf("Failed getting session [%s]", err)
+	}
+	got := make(chan pkcs11.SessionHandle)
+	go func() {
+		s, _ := p.get()
+		got <- s
+	}()
+	select {
+	case <-got:
+		t.Fatal("Getting more sessions than the maximum should block")
+	case <-time.After(50 * time.Millisecond):
+	}
+	p.put(s3)
+	if s := <-got; s != s3 {
+		t.Fatalf("Expected session [%d], got [%d]", s3, s)
+	}
+	p.put(s2)
+	p.put(s3)
+
+	// Lost sessions are discarded, and new ones logged in
+	m.drop()
+	s4, err := p.get()
+	if err != nil {
+		t.Fatalf("Failed getting session [%s]", err)
+	}
+	if s4 == s2 || s4 == s3 {
+		t.Fatal("Lost sessions should not be reused")
+	}
+	if m.logins != 2 {
+		t.Fatalf("New sessions should be logged in again, got [%d] logins", m.logins)
+	}
+	if len(p.open) != 1 {
+		t.Fatalf("Expected 1 open session, got [%d]", len(p.open))
+	}
+	p.put(s4)
+
+	// Sessions logged out are logged in again
+	m.lock.Lock()
+	m.loggedIn = false
+	m.sessions[s4] = cksRWPublicSession
+	m.lock.Unlock()
+	s5, err := p.get()
+	if err != nil {
+		t.Fatalf("Failed getting session [%s]", err)
+	}
+	if s5 != s4 || m.logins != 3 {
+		t.Fatalf("Expected session [%d] to be logged in again, got [%d] after [%d] logins", s4, s5, m.logins)
+	}
+}
+
+func TestSessionPoolReinit(t *testing.T) {
+	defer func(delay time.Duration) { openRetryDelay = delay }(openRetryDelay)
+	openRetryDelay = time.Millisecond
+
+	m := newMockModule()
+	m.unreachable = true
+
+	// The module is reinitialized once sessions cannot be opened
+	reinits := 0
+	p := newSessionPool(m, 0, "1234", 0, func() (uint, error) {
+		reinits++
+		if reinits < 3 {
+			return 0, errors.New("unreachable")
+		}
+		m.unreachable = false
+		return 7, nil
+	})
+
+	if _, err := p.get(); err != nil {
+		t.Fatalf("Failed getting session [%s]", err)
+	}
+	if reinits != 3 || p.getSlot() != 7 {
+		t.Fatalf("Expected 3 reinitializations and slot 7, got [%d] and [%d]", reinits, p.getSlot())
+	}
+
+	// Unreachable tokens fail after a bounded number of attempts
+	m.drop()
+	m.unreachable = true
+	p = newSessionPool(m, 0, "1234", 1, nil)
+	if _, err := p.get(); err == nil {
+		t.Fatal("Getting a session from an unreachable token should fail")
+	}
+	if len(p.open) != 0 {
+		t.Fatalf("Failed sessions should not be counted as open, got [%d]", len(p.open))
+	}
+
+	// Wrong PINs fail
+	m.unreachable = false
+	p = newSessionPool(m, 0, "0000", 1, nil)
+	if _, err := p.get(); err == nil {
+		t.Fatal("Getting a session with a wrong PIN should fail")
+	}
+}
@@ -476,3 +476,50 @@ func TestVariousAESKeyEncoding(t *testing.T) {
 		t.Fatalf("Failed converting encrypted PEM to AES key. Keys are different [%x][%x]", key, keyFromPEM)
 	}
 }
+
+// TestGCMEncryptGCMDecrypt encrypts using AESGCMEncrypt and decrypts using AESGCMDecrypt.
+func TestGCMEncryptGCMDecrypt(t *testing.T) {
+	key := make([]byte, 32)
+	rand.Reader.Read(key)
+
+	ptext := []byte("a message with arbitrary length (42 bytes)")
+	aad := []byte("additional data")
+
+	encrypted, err := AESGCMEncrypt(key, ptext, aad)
+	if err != nil {
+		t.Fatalf("Error encrypting '%s': %s", ptext, err)
+	}
+
+	decrypted, err := AESGCMDecrypt(key, encrypted, aad)
+	if err != nil {
+		t.Fatalf("Error decrypting the encrypted '%s': %v", ptext, err)
+	}
+	if !bytes.Equal(ptext, decrypted) {
+		t.Fatal("Decrypt( Encrypt( ptext ) ) != ptext: Ciphertext decryption with the same key must result in the original plaintext!")
+	}
+
+	// Nonces are random
+	encrypted2, err := AESGCMEncrypt(key, ptext, aad)
+	if err != nil {
+		t.Fatalf("Error encrypting '%s': %s", ptext, err)
+	}
+	if bytes.Equal(encrypted, encrypted2) {
+		t.Fatal("Encrypting the same plaintext twice must give different ciphertexts")
+	}
+
+	// Tampered additional data
+	if _, err := AESGCMDecrypt(key, encrypted, []byte("other data")); err == nil {
+		t.Fatal("Decrypting with different additional data must fail")
+	}
+
+	// Tampered ciphertext
+	encrypted[len(encrypted)-1] ^= 1
+	if _, err := AESGCMDecrypt(key, encrypted, aad); err == nil {
+		t.Fatal("Decrypting a tampered ciphertext must fail")
+	}
+
+	// Truncated ciphertext
+	if _, err := AESGCMDecrypt(key, encrypted[:10], aad); err == nil {
+		t.Fatal("Decrypting a truncated ciphertext must fail")
+	}
+}
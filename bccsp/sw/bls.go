@@ -0,0 +1,39 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sw
+
+import (
+	"github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric/bccsp/bls"
+)
+
+// signBLS signs msg, which is not hashed as
+// BLS hashes the message it signs to the curve
+func (csp *impl) signBLS(k []byte, msg []byte, opts bccsp.SignerOpts) (signature []byte, err error) {
+	return bls.Sign(k, msg)
+}
+
+func (csp *impl) verifyBLS(k []byte, signature, msg []byte, opts bccsp.SignerOpts) (valid bool, err error) {
+	switch err := bls.Verify(k, msg, signature); err {
+	case nil:
+		return true, nil
+	case bls.ErrInvalidSignature:
+		return false, nil
+	default:
+		return false, err
+	}
+}
@@ -0,0 +1,105 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sw
+
+import (
+	"crypto/sha256"
+	"errors"
+
+	"github.com/hyperledger/fabric/bccsp"
+)
+
+type blsPrivateKey struct {
+	privKey []byte
+	pubKey  []byte
+}
+
+// Bytes converts this key to its byte representation,
+// if this operation is allowed.
+func (k *blsPrivateKey) Bytes() (raw []byte, err error) {
+	return nil, errors.New("Not supported.")
+}
+
+// SKI returns the subject key identifier of this key.
+func (k *blsPrivateKey) SKI() (ski []byte) {
+	if k.pubKey == nil {
+		return nil
+	}
+
+	// Hash the public key
+	hash := sha256.New()
+	hash.Write(k.pubKey)
+	return hash.Sum(nil)
+}
+
+// Symmetric returns true if this key is a symmetric key,
+// false if this key is asymmetric
+func (k *blsPrivateKey) Symmetric() bool {
+	return false
+}
+
+// Private returns true if this key is a private key,
+// false otherwise.
+func (k *blsPrivateKey) Private() bool {
+	return true
+}
+
+// PublicKey returns the corresponding public key part of an asymmetric public/private key pair.
+// This method returns an error in symmetric key schemes.
+func (k *blsPrivateKey) PublicKey() (bccsp.Key, error) {
+	return &blsPublicKey{k.pubKey}, nil
+}
+
+type blsPublicKey struct {
+	pubKey []byte
+}
+
+// Bytes converts this key to its byte representation,
+// if this operation is allowed.
+func (k *blsPublicKey) Bytes() (raw []byte, err error) {
+	return k.pubKey, nil
+}
+
+// SKI returns the subject key identifier of this key.
+func (k *blsPublicKey) SKI() (ski []byte) {
+	if k.pubKey == nil {
+		return nil
+	}
+
+	// Hash the public key
+	hash := sha256.New()
+	hash.Write(k.pubKey)
+	return hash.Sum(nil)
+}
+
+// Symmetric returns true if this key is a symmetric key,
+// false if this key is asymmetric
+func (k *blsPublicKey) Symmetric() bool {
+	return false
+}
+
+// Private returns true if this key is a private key,
+// false otherwise.
+func (k *blsPublicKey) Private() bool {
+	return false
+}
+
+// PublicKey returns the corresponding public key part of an asymmetric public/private key pair.
+// This method returns an error in symmetric key schemes.
+func (k *blsPublicKey) PublicKey() (bccsp.Key, error) {
+	return k, nil
+}
@@ -34,6 +34,9 @@ type config struct {
 	hashFunction  func() hash.Hash
 	aesBitLength  int
 	rsaBitLength  int
+
+	// minRSAKeySize is the minimum length, in bits, of RSA keys
+	minRSAKeySize int
 }
 
 func (conf *config) setSecurityLevel(securityLevel int, hashFamily string) (err error) {
@@ -0,0 +1,59 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sw
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/hyperledger/fabric/bccsp"
+	"golang.org/x/crypto/hkdf"
+)
+
+// deriveECDH returns the AES-256 key k agrees on, via ECDH, with
+// the public key of opts, expanded with HKDF-SHA256 from the
+// x-coordinate of the shared point
+func deriveECDH(k *ecdsa.PrivateKey, opts *bccsp.ECDHKeyDerivOpts) ([]byte, error) {
+	var pub *ecdsa.PublicKey
+	switch pk := opts.PublicKey.(type) {
+	case *ecdsaPublicKey:
+		pub = pk.pubKey
+	case *ecdsaPrivateKey:
+		pub = &pk.privKey.PublicKey
+	default:
+		return nil, fmt.Errorf("Invalid public key. It must be an ECDSA key, got [%T]", opts.PublicKey)
+	}
+	if pub.Curve != k.Curve {
+		return nil, errors.New("Invalid public key. It must be on the curve of the private key.")
+	}
+	if !pub.Curve.IsOnCurve(pub.X, pub.Y) {
+		return nil, errors.New("Invalid public key. It must be a point of the curve.")
+	}
+
+	x, _ := k.Curve.ScalarMult(pub.X, pub.Y, k.D.Bytes())
+	secret := make([]byte, (k.Curve.Params().BitSize+7)/8)
+	x.FillBytes(secret)
+
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, nil, opts.Info), key); err != nil {
+		return nil, fmt.Errorf("Failed expanding shared secret [%s]", err)
+	}
+	return key, nil
+}
@@ -75,7 +75,12 @@ func unmarshalECDSASignature(raw []byte) (*big.Int, *big.Int, error) {
 }
 
 func (csp *impl) signECDSA(k *ecdsa.PrivateKey, digest []byte, opts bccsp.SignerOpts) (signature []byte, err error) {
-	r, s, err := ecdsa.Sign(rand.Reader, k, digest)
+	var r, s *big.Int
+	if detOpts, ok := opts.(*bccsp.ECDSADeterministicSignerOpts); ok {
+		r, s, err = signECDSADeterministic(k, digest, deterministicHash(detOpts, digest))
+	} else {
+		r, s, err = ecdsa.Sign(rand.Reader, k, digest)
+	}
 	if err != nil {
 		return nil, err
 	}
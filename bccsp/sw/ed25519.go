@@ -0,0 +1,45 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sw
+
+import (
+	"crypto/ed25519"
+	"errors"
+
+	"github.com/hyperledger/fabric/bccsp"
+)
+
+// signED25519 signs msg, which is not hashed as
+// Ed25519 hashes the message it signs itself
+func (csp *impl) signED25519(k ed25519.PrivateKey, msg []byte, opts bccsp.SignerOpts) (signature []byte, err error) {
+	if len(k) != ed25519.PrivateKeySize {
+		return nil, errors.New("Invalid Ed25519 private key.")
+	}
+
+	return ed25519.Sign(k, msg), nil
+}
+
+func (csp *impl) verifyED25519(k ed25519.PublicKey, signature, msg []byte, opts bccsp.SignerOpts) (valid bool, err error) {
+	if len(k) != ed25519.PublicKeySize {
+		return false, errors.New("Invalid Ed25519 public key.")
+	}
+	if len(signature) != ed25519.SignatureSize {
+		return false, errors.New("Invalid signature. Wrong length.")
+	}
+
+	return ed25519.Verify(k, msg, signature), nil
+}
@@ -17,6 +17,7 @@ package sw
 
 import (
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/rand"
 	"errors"
 	"fmt"
@@ -35,6 +36,7 @@ import (
 	"crypto/sha512"
 
 	"github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric/bccsp/bls"
 	"github.com/hyperledger/fabric/bccsp/utils"
 	"github.com/op/go-logging"
 	"golang.org/x/crypto/sha3"
@@ -79,6 +81,23 @@ func New(securityLevel int, hashFamily string, keyStore bccsp.KeyStore) (bccsp.B
 	return &impl{conf, keyStore}, nil
 }
 
+// NewWithMinRSAKeySize returns a new instance of the software-based BCCSP
+// as New does, which refuses to generate or import RSA keys shorter than
+// minRSAKeySize bits. A minRSAKeySize of zero sets no minimum.
+func NewWithMinRSAKeySize(securityLevel int, hashFamily string, keyStore bccsp.KeyStore, minRSAKeySize int) (bccsp.BCCSP, error) {
+	if minRSAKeySize < 0 {
+		return nil, fmt.Errorf("Invalid minimum RSA key size [%d]. It must not be negative.", minRSAKeySize)
+	}
+
+	csp, err := New(securityLevel, hashFamily, keyStore)
+	if err != nil {
+		return nil, err
+	}
+	csp.(*impl).conf.minRSAKeySize = minRSAKeySize
+
+	return csp, nil
+}
+
 // SoftwareBasedBCCSP is the software-based implementation of the BCCSP.
 type impl struct {
 	conf *config
@@ -118,6 +137,22 @@ func (csp *impl) KeyGen(opts bccsp.KeyGenOpts) (k bccsp.Key, err error) {
 
 		k = &ecdsaPrivateKey{lowLevelKey}
 
+	case *bccsp.ED25519KeyGenOpts:
+		_, lowLevelKey, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("Failed generating Ed25519 key [%s]", err)
+		}
+
+		k = &ed25519PrivateKey{lowLevelKey}
+
+	case *bccsp.BLS12381KeyGenOpts:
+		privKey, pubKey, err := bls.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("Failed generating BLS12-381 key [%s]", err)
+		}
+
+		k = &blsPrivateKey{privKey, pubKey}
+
 	case *bccsp.AESKeyGenOpts:
 		lowLevelKey, err := GetRandomBytes(csp.conf.aesBitLength)
 
@@ -203,6 +238,12 @@ func (csp *impl) KeyGen(opts bccsp.KeyGenOpts) (k bccsp.Key, err error) {
 		return nil, fmt.Errorf("Unrecognized KeyGenOpts provided [%s]", opts.Algorithm())
 	}
 
+	if rsaK, ok := k.(*rsaPrivateKey); ok {
+		if err := csp.checkRSAKeySize(&rsaK.privKey.PublicKey); err != nil {
+			return nil, err
+		}
+	}
+
 	// If the key is not Ephemeral, store it.
 	if !opts.Ephemeral() {
 		// Store the key
@@ -338,6 +379,25 @@ func (csp *impl) KeyDeriv(k bccsp.Key, opts bccsp.KeyDerivOpts) (dk bccsp.Key, e
 
 			return reRandomizedKey, nil
 
+		// Agree on an AES key with another ECDSA key
+		case *bccsp.ECDHKeyDerivOpts:
+			raw, err := deriveECDH(ecdsaK.privKey, opts.(*bccsp.ECDHKeyDerivOpts))
+			if err != nil {
+				return nil, err
+			}
+			agreedKey := &aesPrivateKey{raw, false}
+
+			// If the key is not Ephemeral, store it.
+			if !opts.Ephemeral() {
+				// Store the key
+				err = csp.ks.StoreKey(agreedKey)
+				if err != nil {
+					return nil, fmt.Errorf("Failed storing AES key [%s]", err)
+				}
+			}
+
+			return agreedKey, nil
+
 		default:
 			return nil, fmt.Errorf("Unrecognized KeyDerivOpts provided [%s]", opts.Algorithm())
 
@@ -543,12 +603,141 @@ func (csp *impl) KeyImport(raw interface{}, opts bccsp.KeyImportOpts) (k bccsp.K
 
 		return k, nil
 
+	case *bccsp.ED25519PKIXPublicKeyImportOpts:
+		der, ok := raw.([]byte)
+		if !ok {
+			return nil, errors.New("[ED25519PKIXPublicKeyImportOpts] Invalid raw material. Expected byte array.")
+		}
+
+		if len(der) == 0 {
+			return nil, errors.New("[ED25519PKIXPublicKeyImportOpts] Invalid raw. It must not be nil.")
+		}
+
+		lowLevelKey, err := utils.DERToPublicKey(der)
+		if err != nil {
+			return nil, fmt.Errorf("Failed converting PKIX to Ed25519 public key [%s]", err)
+		}
+
+		ed25519PK, ok := lowLevelKey.(ed25519.PublicKey)
+		if !ok {
+			return nil, errors.New("Failed casting to Ed25519 public key. Invalid raw material.")
+		}
+
+		return csp.KeyImport(ed25519PK, &bccsp.ED25519GoPublicKeyImportOpts{Temporary: opts.Ephemeral()})
+
+	case *bccsp.ED25519PrivateKeyImportOpts:
+		der, ok := raw.([]byte)
+		if !ok {
+			return nil, errors.New("[ED25519PrivateKeyImportOpts] Invalid raw material. Expected byte array.")
+		}
+
+		if len(der) == 0 {
+			return nil, errors.New("[ED25519PrivateKeyImportOpts] Invalid raw. It must not be nil.")
+		}
+
+		lowLevelKey, err := utils.DERToPrivateKey(der)
+		if err != nil {
+			return nil, fmt.Errorf("Failed converting PKCS#8 to Ed25519 private key [%s]", err)
+		}
+
+		ed25519SK, ok := lowLevelKey.(ed25519.PrivateKey)
+		if !ok {
+			return nil, errors.New("Failed casting to Ed25519 private key. Invalid raw material.")
+		}
+
+		k = &ed25519PrivateKey{ed25519SK}
+
+		// If the key is not Ephemeral, store it.
+		if !opts.Ephemeral() {
+			// Store the key
+			err = csp.ks.StoreKey(k)
+			if err != nil {
+				return nil, fmt.Errorf("Failed storing Ed25519 key [%s]", err)
+			}
+		}
+
+		return k, nil
+
+	case *bccsp.BLS12381PrivateKeyImportOpts:
+		der, ok := raw.([]byte)
+		if !ok {
+			return nil, errors.New("[BLS12381PrivateKeyImportOpts] Invalid raw material. Expected byte array.")
+		}
+
+		pubKey, err := bls.PublicKey(der)
+		if err != nil {
+			return nil, fmt.Errorf("Failed converting to BLS12-381 private key [%s]", err)
+		}
+
+		k = &blsPrivateKey{utils.Clone(der), pubKey}
+
+		// If the key is not Ephemeral, store it.
+		if !opts.Ephemeral() {
+			// Store the key
+			err = csp.ks.StoreKey(k)
+			if err != nil {
+				return nil, fmt.Errorf("Failed storing BLS12-381 key [%s]", err)
+			}
+		}
+
+		return k, nil
+
+	case *bccsp.BLS12381PublicKeyImportOpts:
+		der, ok := raw.([]byte)
+		if !ok {
+			return nil, errors.New("[BLS12381PublicKeyImportOpts] Invalid raw material. Expected byte array.")
+		}
+
+		if err := bls.VerifyPublicKey(der); err != nil {
+			return nil, fmt.Errorf("Failed converting to BLS12-381 public key [%s]", err)
+		}
+
+		k = &blsPublicKey{utils.Clone(der)}
+
+		// If the key is not Ephemeral, store it.
+		if !opts.Ephemeral() {
+			// Store the key
+			err = csp.ks.StoreKey(k)
+			if err != nil {
+				return nil, fmt.Errorf("Failed storing BLS12-381 key [%s]", err)
+			}
+		}
+
+		return k, nil
+
+	case *bccsp.ED25519GoPublicKeyImportOpts:
+		lowLevelKey, ok := raw.(ed25519.PublicKey)
+		if !ok {
+			return nil, errors.New("[ED25519GoPublicKeyImportOpts] Invalid raw material. Expected ed25519.PublicKey.")
+		}
+
+		if len(lowLevelKey) != ed25519.PublicKeySize {
+			return nil, errors.New("[ED25519GoPublicKeyImportOpts] Invalid raw material. Wrong key length.")
+		}
+
+		k = &ed25519PublicKey{lowLevelKey}
+
+		// If the key is not Ephemeral, store it.
+		if !opts.Ephemeral() {
+			// Store the key
+			err = csp.ks.StoreKey(k)
+			if err != nil {
+				return nil, fmt.Errorf("Failed storing Ed25519 key [%s]", err)
+			}
+		}
+
+		return k, nil
+
 	case *bccsp.RSAGoPublicKeyImportOpts:
 		lowLevelKey, ok := raw.(*rsa.PublicKey)
 		if !ok {
 			return nil, errors.New("[RSAGoPublicKeyImportOpts] Invalid raw material. Expected *rsa.PublicKey.")
 		}
 
+		if err := csp.checkRSAKeySize(lowLevelKey); err != nil {
+			return nil, err
+		}
+
 		k = &rsaPublicKey{lowLevelKey}
 
 		// If the key is not Ephemeral, store it.
@@ -562,6 +751,43 @@ func (csp *impl) KeyImport(raw interface{}, opts bccsp.KeyImportOpts) (k bccsp.K
 
 		return k, nil
 
+	case *bccsp.RSAPrivateKeyImportOpts:
+		der, ok := raw.([]byte)
+		if !ok {
+			return nil, errors.New("[RSAPrivateKeyImportOpts] Invalid raw material. Expected byte array.")
+		}
+
+		if len(der) == 0 {
+			return nil, errors.New("[RSAPrivateKeyImportOpts] Invalid raw. It must not be nil.")
+		}
+
+		lowLevelKey, err := utils.DERToPrivateKey(der)
+		if err != nil {
+			return nil, fmt.Errorf("Failed converting DER to RSA private key [%s]", err)
+		}
+
+		rsaSK, ok := lowLevelKey.(*rsa.PrivateKey)
+		if !ok {
+			return nil, errors.New("Failed casting to RSA private key. Invalid raw material.")
+		}
+
+		if err := csp.checkRSAKeySize(&rsaSK.PublicKey); err != nil {
+			return nil, err
+		}
+
+		k = &rsaPrivateKey{rsaSK}
+
+		// If the key is not Ephemeral, store it.
+		if !opts.Ephemeral() {
+			// Store the key
+			err = csp.ks.StoreKey(k)
+			if err != nil {
+				return nil, fmt.Errorf("Failed storing RSA key [%s]", err)
+			}
+		}
+
+		return k, nil
+
 	case *bccsp.X509PublicKeyImportOpts:
 		x509Cert, ok := raw.(*x509.Certificate)
 		if !ok {
@@ -575,8 +801,10 @@ func (csp *impl) KeyImport(raw interface{}, opts bccsp.KeyImportOpts) (k bccsp.K
 			return csp.KeyImport(pk, &bccsp.ECDSAGoPublicKeyImportOpts{Temporary: opts.Ephemeral()})
 		case *rsa.PublicKey:
 			return csp.KeyImport(pk, &bccsp.RSAGoPublicKeyImportOpts{Temporary: opts.Ephemeral()})
+		case ed25519.PublicKey:
+			return csp.KeyImport(pk, &bccsp.ED25519GoPublicKeyImportOpts{Temporary: opts.Ephemeral()})
 		default:
-			return nil, errors.New("Certificate public key type not recognized. Supported keys: [ECDSA, RSA]")
+			return nil, errors.New("Certificate public key type not recognized. Supported keys: [ECDSA, RSA, Ed25519]")
 		}
 
 	default:
@@ -590,6 +818,74 @@ func (csp *impl) GetKey(ski []byte) (k bccsp.Key, err error) {
 	return csp.ks.GetKey(ski)
 }
 
+// RotateKey generates a new, non-ephemeral, version of the private
+// or secret key k using opts and retires k. If opts is nil, the new
+// key is of the same type as k. The software keystore keeps no labels,
+// hence k is left untouched and is still returned by GetKey.
+func (csp *impl) RotateKey(k bccsp.Key, opts bccsp.KeyGenOpts) (oldSKI, newSKI []byte, err error) {
+	// Validate arguments
+	if k == nil {
+		return nil, nil, errors.New("Invalid Key. It must not be nil.")
+	}
+	if !k.Private() {
+		return nil, nil, errors.New("Invalid Key. Only private and secret keys can be rotated.")
+	}
+	if opts == nil {
+		if opts, err = keyGenOptsFor(k); err != nil {
+			return nil, nil, err
+		}
+	}
+	if opts.Ephemeral() {
+		return nil, nil, errors.New("Invalid Opts parameter. Rotated keys must not be ephemeral.")
+	}
+
+	newKey, err := csp.KeyGen(opts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed generating new version of key [%x]: [%s]", k.SKI(), err)
+	}
+
+	return k.SKI(), newKey.SKI(), nil
+}
+
+// keyGenOptsFor returns the options generating
+// a non-ephemeral key of the same type as k
+func keyGenOptsFor(k bccsp.Key) (bccsp.KeyGenOpts, error) {
+	switch key := k.(type) {
+	case *ecdsaPrivateKey:
+		switch key.privKey.Curve {
+		case elliptic.P256():
+			return &bccsp.ECDSAP256KeyGenOpts{}, nil
+		case elliptic.P384():
+			return &bccsp.ECDSAP384KeyGenOpts{}, nil
+		}
+	case *ed25519PrivateKey:
+		return &bccsp.ED25519KeyGenOpts{}, nil
+	case *blsPrivateKey:
+		return &bccsp.BLS12381KeyGenOpts{}, nil
+	case *rsaPrivateKey:
+		switch key.privKey.N.BitLen() {
+		case 1024:
+			return &bccsp.RSA1024KeyGenOpts{}, nil
+		case 2048:
+			return &bccsp.RSA2048KeyGenOpts{}, nil
+		case 3072:
+			return &bccsp.RSA3072KeyGenOpts{}, nil
+		case 4096:
+			return &bccsp.RSA4096KeyGenOpts{}, nil
+		}
+	case *aesPrivateKey:
+		switch len(key.privKey) {
+		case 16:
+			return &bccsp.AES128KeyGenOpts{}, nil
+		case 24:
+			return &bccsp.AES192KeyGenOpts{}, nil
+		case 32:
+			return &bccsp.AES256KeyGenOpts{}, nil
+		}
+	}
+	return nil, fmt.Errorf("Cannot infer key generation options for key type [%T]", k)
+}
+
 // Hash hashes messages msg using options opts.
 func (csp *impl) Hash(msg []byte, opts bccsp.HashOpts) (digest []byte, err error) {
 	var h hash.Hash
@@ -644,7 +940,10 @@ func (csp *impl) GetHash(opts bccsp.HashOpts) (h hash.Hash, err error) {
 //
 // Note that when a signature of a hash of a larger message is needed,
 // the caller is responsible for hashing the larger message and passing
-// the hash (as digest).
+// the hash (as digest). Ed25519 and BLS12-381 keys are the exception:
+// they sign the message itself, passed as digest.
+// ECDSA signatures use RFC 6979 deterministic nonces when opts is
+// a *bccsp.ECDSADeterministicSignerOpts.
 func (csp *impl) Sign(k bccsp.Key, digest []byte, opts bccsp.SignerOpts) (signature []byte, err error) {
 	// Validate arguments
 	if k == nil {
@@ -658,6 +957,10 @@ func (csp *impl) Sign(k bccsp.Key, digest []byte, opts bccsp.SignerOpts) (signat
 	switch k.(type) {
 	case *ecdsaPrivateKey:
 		return csp.signECDSA(k.(*ecdsaPrivateKey).privKey, digest, opts)
+	case *ed25519PrivateKey:
+		return csp.signED25519(k.(*ed25519PrivateKey).privKey, digest, opts)
+	case *blsPrivateKey:
+		return csp.signBLS(k.(*blsPrivateKey).privKey, digest, opts)
 	case *rsaPrivateKey:
 		if opts == nil {
 			return nil, errors.New("Invalid options. Nil.")
@@ -688,6 +991,14 @@ func (csp *impl) Verify(k bccsp.Key, signature, digest []byte, opts bccsp.Signer
 		return csp.verifyECDSA(&(k.(*ecdsaPrivateKey).privKey.PublicKey), signature, digest, opts)
 	case *ecdsaPublicKey:
 		return csp.verifyECDSA(k.(*ecdsaPublicKey).pubKey, signature, digest, opts)
+	case *ed25519PrivateKey:
+		return csp.verifyED25519(k.(*ed25519PrivateKey).privKey.Public().(ed25519.PublicKey), signature, digest, opts)
+	case *ed25519PublicKey:
+		return csp.verifyED25519(k.(*ed25519PublicKey).pubKey, signature, digest, opts)
+	case *blsPrivateKey:
+		return csp.verifyBLS(k.(*blsPrivateKey).pubKey, signature, digest, opts)
+	case *blsPublicKey:
+		return csp.verifyBLS(k.(*blsPublicKey).pubKey, signature, digest, opts)
 	case *rsaPrivateKey:
 		if opts == nil {
 			return false, errors.New("Invalid options. It must not be nil.")
@@ -737,6 +1048,9 @@ func (csp *impl) Encrypt(k bccsp.Key, plaintext []byte, opts bccsp.EncrypterOpts
 		case *bccsp.AESCBCPKCS7ModeOpts, bccsp.AESCBCPKCS7ModeOpts:
 			// AES in CBC mode with PKCS7 padding
 			return AESCBCPKCS7Encrypt(k.(*aesPrivateKey).privKey, plaintext)
+		case *bccsp.AESGCMModeOpts:
+			// AES in GCM mode
+			return AESGCMEncrypt(k.(*aesPrivateKey).privKey, plaintext, opts.(*bccsp.AESGCMModeOpts).AdditionalData)
 		default:
 			return nil, fmt.Errorf("Mode not recognized [%s]", opts)
 		}
@@ -761,6 +1075,9 @@ func (csp *impl) Decrypt(k bccsp.Key, ciphertext []byte, opts bccsp.DecrypterOpt
 		case *bccsp.AESCBCPKCS7ModeOpts, bccsp.AESCBCPKCS7ModeOpts:
 			// AES in CBC mode with PKCS7 padding
 			return AESCBCPKCS7Decrypt(k.(*aesPrivateKey).privKey, ciphertext)
+		case *bccsp.AESGCMModeOpts:
+			// AES in GCM mode
+			return AESGCMDecrypt(k.(*aesPrivateKey).privKey, ciphertext, opts.(*bccsp.AESGCMModeOpts).AdditionalData)
 		default:
 			return nil, fmt.Errorf("Mode not recognized [%s]", opts)
 		}
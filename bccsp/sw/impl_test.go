@@ -32,6 +32,7 @@ import (
 	"time"
 
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/elliptic"
 	"crypto/sha256"
 
@@ -41,6 +42,7 @@ import (
 	"hash"
 
 	"github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric/bccsp/bls"
 	"github.com/hyperledger/fabric/bccsp/signer"
 	"github.com/hyperledger/fabric/bccsp/utils"
 	"golang.org/x/crypto/sha3"
@@ -1206,6 +1208,35 @@ func TestAESDecrypt(t *testing.T) {
 	}
 }
 
+func TestAESGCMEncryptDecrypt(t *testing.T) {
+
+	k, err := currentBCCSP.KeyGen(&bccsp.AESKeyGenOpts{Temporary: true})
+	if err != nil {
+		t.Fatalf("Failed generating AES_256 key [%s]", err)
+	}
+
+	msg := []byte("Hello World")
+	opts := &bccsp.AESGCMModeOpts{AdditionalData: []byte("header")}
+
+	ct, err := currentBCCSP.Encrypt(k, msg, opts)
+	if err != nil {
+		t.Fatalf("Failed encrypting [%s]", err)
+	}
+
+	pt, err := currentBCCSP.Decrypt(k, ct, opts)
+	if err != nil {
+		t.Fatalf("Failed decrypting [%s]", err)
+	}
+	if !bytes.Equal(msg, pt) {
+		t.Fatalf("Failed decrypting. Decrypted plaintext is different from the original. [%x][%x]", msg, pt)
+	}
+
+	_, err = currentBCCSP.Decrypt(k, ct, &bccsp.AESGCMModeOpts{AdditionalData: []byte("other header")})
+	if err == nil {
+		t.Fatal("Decrypting with different additional data should fail")
+	}
+}
+
 func TestHMACTruncated256KeyDerivOverAES256Key(t *testing.T) {
 
 	k, err := currentBCCSP.KeyGen(&bccsp.AESKeyGenOpts{Temporary: false})
@@ -1720,6 +1751,87 @@ func TestRSAKeyImportFromRSAPublicKey(t *testing.T) {
 	}
 }
 
+func TestRSAKeyImportFromRSAPrivateKey(t *testing.T) {
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed generating RSA key [%s]", err)
+	}
+
+	for _, der := range [][]byte{x509.MarshalPKCS1PrivateKey(key), mustMarshalPKCS8(t, key)} {
+		k, err := currentBCCSP.KeyImport(der, &bccsp.RSAPrivateKeyImportOpts{Temporary: false})
+		if err != nil {
+			t.Fatalf("Failed importing RSA private key [%s]", err)
+		}
+		if !k.Private() {
+			t.Fatal("Failed importing RSA private key. Key should be private")
+		}
+
+		msg := []byte("Hello World")
+		digest, err := currentBCCSP.Hash(msg, &bccsp.SHAOpts{})
+		if err != nil {
+			t.Fatalf("Failed computing HASH [%s]", err)
+		}
+
+		opts := &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: getCryptoHashIndex(t)}
+		signature, err := currentBCCSP.Sign(k, digest, opts)
+		if err != nil {
+			t.Fatalf("Failed generating RSA signature [%s]", err)
+		}
+		if err := rsa.VerifyPSS(&key.PublicKey, getCryptoHashIndex(t), digest, signature, opts); err != nil {
+			t.Fatalf("Failed verifying RSA signature [%s]", err)
+		}
+	}
+
+	_, err = currentBCCSP.KeyImport([]byte{0, 1, 2}, &bccsp.RSAPrivateKeyImportOpts{Temporary: true})
+	if err == nil {
+		t.Fatal("Importing an invalid RSA private key should fail")
+	}
+}
+
+func mustMarshalPKCS8(t *testing.T, key interface{}) []byte {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed marshalling private key [%s]", err)
+	}
+	return der
+}
+
+func TestMinRSAKeySize(t *testing.T) {
+
+	csp, err := NewWithMinRSAKeySize(256, "SHA2", NewDummyKeyStore(), 2048)
+	if err != nil {
+		t.Fatalf("Failed initializing BCCSP [%s]", err)
+	}
+
+	_, err = csp.KeyGen(&bccsp.RSA1024KeyGenOpts{Temporary: true})
+	if err == nil {
+		t.Fatal("Generating an RSA key shorter than the minimum should fail")
+	}
+	_, err = csp.KeyGen(&bccsp.RSA2048KeyGenOpts{Temporary: true})
+	if err != nil {
+		t.Fatalf("Failed generating RSA key [%s]", err)
+	}
+
+	short, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("Failed generating RSA key [%s]", err)
+	}
+	_, err = csp.KeyImport(&short.PublicKey, &bccsp.RSAGoPublicKeyImportOpts{Temporary: true})
+	if err == nil {
+		t.Fatal("Importing an RSA public key shorter than the minimum should fail")
+	}
+	_, err = csp.KeyImport(x509.MarshalPKCS1PrivateKey(short), &bccsp.RSAPrivateKeyImportOpts{Temporary: true})
+	if err == nil {
+		t.Fatal("Importing an RSA private key shorter than the minimum should fail")
+	}
+
+	_, err = NewWithMinRSAKeySize(256, "SHA2", NewDummyKeyStore(), -1)
+	if err == nil {
+		t.Fatal("A negative minimum RSA key size should be rejected")
+	}
+}
+
 func TestKeyImportFromX509RSAPublicKey(t *testing.T) {
 
 	// Generate an RSA key
@@ -1920,3 +2032,474 @@ func getCryptoHashIndex(t *testing.T) crypto.Hash {
 
 	return crypto.SHA3_256
 }
+
+func TestRotateKey(t *testing.T) {
+	for _, opts := range []bccsp.KeyGenOpts{
+		&bccsp.ECDSAP256KeyGenOpts{},
+		&bccsp.ECDSAP384KeyGenOpts{},
+		&bccsp.AES128KeyGenOpts{},
+		&bccsp.AES256KeyGenOpts{},
+		&bccsp.RSA1024KeyGenOpts{},
+	} {
+		k, err := currentBCCSP.KeyGen(opts)
+		if err != nil {
+			t.Fatalf("Failed generating key [%s]", err)
+		}
+
+		oldSKI, newSKI, err := currentBCCSP.RotateKey(k, nil)
+		if err != nil {
+			t.Fatalf("Failed rotating key [%s]", err)
+		}
+		if !bytes.Equal(oldSKI, k.SKI()) {
+			t.Fatal("Old SKI must be the SKI of the rotated key")
+		}
+		if bytes.Equal(oldSKI, newSKI) {
+			t.Fatal("New SKI must be different from the old one")
+		}
+
+		newKey, err := currentBCCSP.GetKey(newSKI)
+		if err != nil {
+			t.Fatalf("Failed getting new key [%s]", err)
+		}
+		expected, _ := keyGenOptsFor(k)
+		actual, _ := keyGenOptsFor(newKey)
+		if expected.Algorithm() != actual.Algorithm() {
+			t.Fatalf("New key must be of the same type as the old one, [%s] != [%s]", actual.Algorithm(), expected.Algorithm())
+		}
+
+		// The old key is still available
+		if _, err := currentBCCSP.GetKey(oldSKI); err != nil {
+			t.Fatalf("Failed getting old key [%s]", err)
+		}
+	}
+
+	// With explicit opts
+	k, err := currentBCCSP.KeyGen(&bccsp.ECDSAP256KeyGenOpts{})
+	if err != nil {
+		t.Fatalf("Failed generating key [%s]", err)
+	}
+	_, newSKI, err := currentBCCSP.RotateKey(k, &bccsp.ECDSAP384KeyGenOpts{})
+	if err != nil {
+		t.Fatalf("Failed rotating key [%s]", err)
+	}
+	newKey, err := currentBCCSP.GetKey(newSKI)
+	if err != nil {
+		t.Fatalf("Failed getting new key [%s]", err)
+	}
+	if newKey.(*ecdsaPrivateKey).privKey.Curve != elliptic.P384() {
+		t.Fatal("New key must be generated with the given opts")
+	}
+
+	// Invalid arguments
+	if _, _, err := currentBCCSP.RotateKey(nil, nil); err == nil {
+		t.Fatal("Rotating a nil key must fail")
+	}
+	pk, _ := k.PublicKey()
+	if _, _, err := currentBCCSP.RotateKey(pk, nil); err == nil {
+		t.Fatal("Rotating a public key must fail")
+	}
+	if _, _, err := currentBCCSP.RotateKey(k, &bccsp.ECDSAP256KeyGenOpts{Temporary: true}); err == nil {
+		t.Fatal("Rotating to an ephemeral key must fail")
+	}
+}
+
+func TestED25519KeyGenNonEphemeral(t *testing.T) {
+
+	k, err := currentBCCSP.KeyGen(&bccsp.ED25519KeyGenOpts{Temporary: false})
+	if err != nil {
+		t.Fatalf("Failed generating Ed25519 key [%s]", err)
+	}
+	if k == nil {
+		t.Fatal("Failed generating Ed25519 key. Key must be different from nil")
+	}
+	if !k.Private() {
+		t.Fatal("Failed generating Ed25519 key. Key should be private")
+	}
+	if k.Symmetric() {
+		t.Fatal("Failed generating Ed25519 key. Key should be asymmetric")
+	}
+
+	// Get the key back from the keystore
+	k2, err := currentBCCSP.GetKey(k.SKI())
+	if err != nil {
+		t.Fatalf("Failed getting Ed25519 key [%s]", err)
+	}
+	if !bytes.Equal(k.SKI(), k2.SKI()) {
+		t.Fatalf("SKIs are different [%x]!=[%x]", k.SKI(), k2.SKI())
+	}
+
+	// The public key is stored too
+	pk, err := k.PublicKey()
+	if err != nil {
+		t.Fatalf("Failed getting public key from private Ed25519 key [%s]", err)
+	}
+	if !bytes.Equal(k.SKI(), pk.SKI()) {
+		t.Fatalf("SKIs are different [%x]!=[%x]", k.SKI(), pk.SKI())
+	}
+	if err := currentKS.StoreKey(pk); err != nil {
+		t.Fatalf("Failed storing Ed25519 public key [%s]", err)
+	}
+}
+
+func TestED25519SignAndVerify(t *testing.T) {
+
+	k, err := currentBCCSP.KeyGen(&bccsp.ED25519KeyGenOpts{Temporary: true})
+	if err != nil {
+		t.Fatalf("Failed generating Ed25519 key [%s]", err)
+	}
+
+	// Ed25519 signs messages, not digests
+	msg := []byte("Hello World")
+	signature, err := currentBCCSP.Sign(k, msg, nil)
+	if err != nil {
+		t.Fatalf("Failed generating Ed25519 signature [%s]", err)
+	}
+	if len(signature) != ed25519.SignatureSize {
+		t.Fatalf("Failed generating Ed25519 signature. Wrong length [%d]", len(signature))
+	}
+
+	valid, err := currentBCCSP.Verify(k, signature, msg, nil)
+	if err != nil {
+		t.Fatalf("Failed verifying Ed25519 signature [%s]", err)
+	}
+	if !valid {
+		t.Fatal("Failed verifying Ed25519 signature. Signature not valid.")
+	}
+
+	pk, err := k.PublicKey()
+	if err != nil {
+		t.Fatalf("Failed getting public key from private Ed25519 key [%s]", err)
+	}
+	valid, err = currentBCCSP.Verify(pk, signature, msg, nil)
+	if err != nil {
+		t.Fatalf("Failed verifying Ed25519 signature [%s]", err)
+	}
+	if !valid {
+		t.Fatal("Failed verifying Ed25519 signature. Signature not valid.")
+	}
+
+	// A signature over another message
+	valid, err = currentBCCSP.Verify(pk, signature, []byte("Hello World!"), nil)
+	if err != nil {
+		t.Fatalf("Failed verifying Ed25519 signature [%s]", err)
+	}
+	if valid {
+		t.Fatal("Ed25519 signature should not be valid for another message.")
+	}
+
+	// A malformed signature
+	_, err = currentBCCSP.Verify(pk, signature[:10], msg, nil)
+	if err == nil {
+		t.Fatal("Verifying a truncated Ed25519 signature should fail.")
+	}
+}
+
+func TestED25519KeyImportFromExportedKey(t *testing.T) {
+
+	k, err := currentBCCSP.KeyGen(&bccsp.ED25519KeyGenOpts{Temporary: true})
+	if err != nil {
+		t.Fatalf("Failed generating Ed25519 key [%s]", err)
+	}
+
+	pk, err := k.PublicKey()
+	if err != nil {
+		t.Fatalf("Failed getting public key from private Ed25519 key [%s]", err)
+	}
+	pkRaw, err := pk.Bytes()
+	if err != nil {
+		t.Fatalf("Failed getting Ed25519 raw public key [%s]", err)
+	}
+
+	pk2, err := currentBCCSP.KeyImport(pkRaw, &bccsp.ED25519PKIXPublicKeyImportOpts{Temporary: false})
+	if err != nil {
+		t.Fatalf("Failed importing Ed25519 public key [%s]", err)
+	}
+	if !bytes.Equal(pk.SKI(), pk2.SKI()) {
+		t.Fatalf("SKIs are different [%x]!=[%x]", pk.SKI(), pk2.SKI())
+	}
+
+	// Import a PKCS#8 private key
+	_, sk, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed generating Ed25519 key [%s]", err)
+	}
+	skRaw, err := x509.MarshalPKCS8PrivateKey(sk)
+	if err != nil {
+		t.Fatalf("Failed marshalling Ed25519 private key [%s]", err)
+	}
+	k2, err := currentBCCSP.KeyImport(skRaw, &bccsp.ED25519PrivateKeyImportOpts{Temporary: true})
+	if err != nil {
+		t.Fatalf("Failed importing Ed25519 private key [%s]", err)
+	}
+	msg := []byte("Hello World")
+	signature, err := currentBCCSP.Sign(k2, msg, nil)
+	if err != nil {
+		t.Fatalf("Failed generating Ed25519 signature [%s]", err)
+	}
+	if !ed25519.Verify(sk.Public().(ed25519.PublicKey), msg, signature) {
+		t.Fatal("Failed verifying Ed25519 signature. Signature not valid.")
+	}
+
+	// Bad paths
+	_, err = currentBCCSP.KeyImport([]byte{0, 1, 2}, &bccsp.ED25519PKIXPublicKeyImportOpts{Temporary: true})
+	if err == nil {
+		t.Fatal("Importing an invalid Ed25519 public key should fail")
+	}
+	_, err = currentBCCSP.KeyImport(ed25519.PublicKey{0, 1, 2}, &bccsp.ED25519GoPublicKeyImportOpts{Temporary: true})
+	if err == nil {
+		t.Fatal("Importing a truncated Ed25519 public key should fail")
+	}
+}
+
+func TestKeyImportFromX509ED25519PublicKey(t *testing.T) {
+
+	k, err := currentBCCSP.KeyGen(&bccsp.ED25519KeyGenOpts{Temporary: true})
+	if err != nil {
+		t.Fatalf("Failed generating Ed25519 key [%s]", err)
+	}
+
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject: pkix.Name{
+			CommonName: "test.example.com",
+		},
+		NotBefore: time.Now().Add(-1 * time.Hour),
+		NotAfter:  time.Now().Add(1 * time.Hour),
+
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	cryptoSigner := &signer.CryptoSigner{}
+	err = cryptoSigner.Init(currentBCCSP, k)
+	if err != nil {
+		t.Fatalf("Failed initializing CyrptoSigner [%s]", err)
+	}
+
+	certRaw, err := x509.CreateCertificate(rand.Reader, &template, &template, cryptoSigner.Public(), cryptoSigner)
+	if err != nil {
+		t.Fatalf("Failed generating self-signed certificate [%s]", err)
+	}
+
+	cert, err := utils.DERToX509Certificate(certRaw)
+	if err != nil {
+		t.Fatalf("Failed generating X509 certificate object from raw [%s]", err)
+	}
+	if err := cert.CheckSignatureFrom(cert); err != nil {
+		t.Fatalf("Failed verifying self-signed certificate [%s]", err)
+	}
+
+	// Import the certificate's public key
+	pk, err := currentBCCSP.KeyImport(cert, &bccsp.X509PublicKeyImportOpts{Temporary: true})
+	if err != nil {
+		t.Fatalf("Failed importing Ed25519 public key [%s]", err)
+	}
+	if !bytes.Equal(k.SKI(), pk.SKI()) {
+		t.Fatalf("SKIs are different [%x]!=[%x]", k.SKI(), pk.SKI())
+	}
+
+	msg := []byte("Hello World")
+	signature, err := currentBCCSP.Sign(k, msg, nil)
+	if err != nil {
+		t.Fatalf("Failed generating Ed25519 signature [%s]", err)
+	}
+
+	valid, err := currentBCCSP.Verify(pk, signature, msg, nil)
+	if err != nil {
+		t.Fatalf("Failed verifying Ed25519 signature [%s]", err)
+	}
+	if !valid {
+		t.Fatal("Failed verifying Ed25519 signature. Signature not valid.")
+	}
+}
+
+func TestECDSADeterministicSign(t *testing.T) {
+	k, err := currentBCCSP.KeyGen(&bccsp.ECDSAKeyGenOpts{Temporary: true})
+	if err != nil {
+		t.Fatalf("Failed generating ECDSA key [%s]", err)
+	}
+
+	digest, err := currentBCCSP.Hash([]byte("Hello World"), &bccsp.SHAOpts{})
+	if err != nil {
+		t.Fatalf("Failed computing HASH [%s]", err)
+	}
+
+	opts := &bccsp.ECDSADeterministicSignerOpts{}
+	signature, err := currentBCCSP.Sign(k, digest, opts)
+	if err != nil {
+		t.Fatalf("Failed generating deterministic ECDSA signature [%s]", err)
+	}
+	signature2, err := currentBCCSP.Sign(k, digest, opts)
+	if err != nil {
+		t.Fatalf("Failed generating deterministic ECDSA signature [%s]", err)
+	}
+	if !bytes.Equal(signature, signature2) {
+		t.Fatal("Deterministic ECDSA signatures should be equal")
+	}
+
+	valid, err := currentBCCSP.Verify(k, signature, digest, nil)
+	if err != nil {
+		t.Fatalf("Failed verifying ECDSA signature [%s]", err)
+	}
+	if !valid {
+		t.Fatal("Failed verifying ECDSA signature. Signature not valid.")
+	}
+
+	// Randomized signatures differ
+	signature3, err := currentBCCSP.Sign(k, digest, nil)
+	if err != nil {
+		t.Fatalf("Failed generating ECDSA signature [%s]", err)
+	}
+	if bytes.Equal(signature, signature3) {
+		t.Fatal("Randomized ECDSA signatures should differ from deterministic ones")
+	}
+}
+
+func TestECDHKeyDeriv(t *testing.T) {
+	k1, err := currentBCCSP.KeyGen(&bccsp.ECDSAKeyGenOpts{Temporary: true})
+	if err != nil {
+		t.Fatalf("Failed generating ECDSA key [%s]", err)
+	}
+	k2, err := currentBCCSP.KeyGen(&bccsp.ECDSAKeyGenOpts{Temporary: true})
+	if err != nil {
+		t.Fatalf("Failed generating ECDSA key [%s]", err)
+	}
+	pk1, _ := k1.PublicKey()
+	pk2, _ := k2.PublicKey()
+
+	// Both parties agree on the same key
+	dk1, err := currentBCCSP.KeyDeriv(k1, &bccsp.ECDHKeyDerivOpts{Temporary: true, PublicKey: pk2, Info: []byte("info")})
+	if err != nil {
+		t.Fatalf("Failed deriving key [%s]", err)
+	}
+	dk2, err := currentBCCSP.KeyDeriv(k2, &bccsp.ECDHKeyDerivOpts{Temporary: true, PublicKey: pk1, Info: []byte("info")})
+	if err != nil {
+		t.Fatalf("Failed deriving key [%s]", err)
+	}
+	if !bytes.Equal(dk1.SKI(), dk2.SKI()) {
+		t.Fatal("Agreed keys should be equal")
+	}
+	ciphertext, err := currentBCCSP.Encrypt(dk1, []byte("Hello World"), &bccsp.AESGCMModeOpts{})
+	if err != nil {
+		t.Fatalf("Failed encrypting [%s]", err)
+	}
+	if _, err := currentBCCSP.Decrypt(dk2, ciphertext, &bccsp.AESGCMModeOpts{}); err != nil {
+		t.Fatalf("Failed decrypting [%s]", err)
+	}
+
+	// Keys are bound to their context
+	dk3, err := currentBCCSP.KeyDeriv(k1, &bccsp.ECDHKeyDerivOpts{Temporary: true, PublicKey: pk2, Info: []byte("other")})
+	if err != nil {
+		t.Fatalf("Failed deriving key [%s]", err)
+	}
+	if bytes.Equal(dk1.SKI(), dk3.SKI()) {
+		t.Fatal("Keys agreed for different contexts should differ")
+	}
+
+	// The other key must be an ECDSA key of the same curve
+	aesKey, err := currentBCCSP.KeyGen(&bccsp.AESKeyGenOpts{Temporary: true})
+	if err != nil {
+		t.Fatalf("Failed generating AES key [%s]", err)
+	}
+	if _, err := currentBCCSP.KeyDeriv(k1, &bccsp.ECDHKeyDerivOpts{Temporary: true, PublicKey: aesKey}); err == nil {
+		t.Fatal("Agreeing with an AES key should fail")
+	}
+	other, err := currentBCCSP.KeyGen(&bccsp.ECDSAP384KeyGenOpts{Temporary: true})
+	if err != nil {
+		t.Fatalf("Failed generating ECDSA key [%s]", err)
+	}
+	if other.(*ecdsaPrivateKey).privKey.Curve != k1.(*ecdsaPrivateKey).privKey.Curve {
+		if _, err := currentBCCSP.KeyDeriv(k1, &bccsp.ECDHKeyDerivOpts{Temporary: true, PublicKey: other}); err == nil {
+			t.Fatal("Agreeing with a key of another curve should fail")
+		}
+	}
+}
+
+func TestBLS12381SignVerify(t *testing.T) {
+	k, err := currentBCCSP.KeyGen(&bccsp.BLS12381KeyGenOpts{Temporary: true})
+	if err != nil {
+		t.Fatalf("Failed generating BLS12-381 key [%s]", err)
+	}
+	if !k.Private() || k.Symmetric() {
+		t.Fatal("Failed generating BLS12-381 key. Key should be private and asymmetric")
+	}
+	if _, err := k.Bytes(); err == nil {
+		t.Fatal("BLS12-381 private keys should not be exported")
+	}
+
+	pk, err := k.PublicKey()
+	if err != nil {
+		t.Fatalf("Failed getting corresponding public key [%s]", err)
+	}
+	if !bytes.Equal(k.SKI(), pk.SKI()) {
+		t.Fatal("SKIs of the private and public key should be equal")
+	}
+
+	msg := []byte("Hello World")
+	signature, err := currentBCCSP.Sign(k, msg, nil)
+	if err != nil {
+		t.Fatalf("Failed generating BLS12-381 signature [%s]", err)
+	}
+
+	valid, err := currentBCCSP.Verify(pk, signature, msg, nil)
+	if err != nil {
+		t.Fatalf("Failed verifying BLS12-381 signature [%s]", err)
+	}
+	if !valid {
+		t.Fatal("Failed verifying BLS12-381 signature. Signature not valid.")
+	}
+
+	valid, err = currentBCCSP.Verify(k, signature, []byte("Bye World"), nil)
+	if err != nil {
+		t.Fatalf("Failed verifying BLS12-381 signature [%s]", err)
+	}
+	if valid {
+		t.Fatal("The signature of another message should not be valid")
+	}
+
+	if _, err := currentBCCSP.Verify(pk, signature[1:], msg, nil); err == nil {
+		t.Fatal("Verifying a malformed signature should fail")
+	}
+
+	// Import the public key from its bytes
+	raw, err := pk.Bytes()
+	if err != nil {
+		t.Fatalf("Failed marshalling BLS12-381 public key [%s]", err)
+	}
+	pk2, err := currentBCCSP.KeyImport(raw, &bccsp.BLS12381PublicKeyImportOpts{Temporary: true})
+	if err != nil {
+		t.Fatalf("Failed importing BLS12-381 public key [%s]", err)
+	}
+	if !bytes.Equal(pk.SKI(), pk2.SKI()) {
+		t.Fatal("SKIs of the imported public key should be equal")
+	}
+	if _, err := currentBCCSP.KeyImport(raw[1:], &bccsp.BLS12381PublicKeyImportOpts{Temporary: true}); err == nil {
+		t.Fatal("Importing a malformed BLS12-381 public key should fail")
+	}
+}
+
+func TestBLS12381KeyImport(t *testing.T) {
+	priv, pub, err := bls.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed generating BLS12-381 key [%s]", err)
+	}
+
+	k, err := currentBCCSP.KeyImport(priv, &bccsp.BLS12381PrivateKeyImportOpts{Temporary: true})
+	if err != nil {
+		t.Fatalf("Failed importing BLS12-381 private key [%s]", err)
+	}
+
+	msg := []byte("Hello World")
+	signature, err := currentBCCSP.Sign(k, msg, nil)
+	if err != nil {
+		t.Fatalf("Failed generating BLS12-381 signature [%s]", err)
+	}
+	if err := bls.Verify(pub, msg, signature); err != nil {
+		t.Fatalf("Failed verifying BLS12-381 signature [%s]", err)
+	}
+
+	if _, err := currentBCCSP.KeyImport(priv[1:], &bccsp.BLS12381PrivateKeyImportOpts{Temporary: true}); err == nil {
+		t.Fatal("Importing a malformed BLS12-381 private key should fail")
+	}
+}
@@ -0,0 +1,59 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sw
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+
+	"github.com/hyperledger/fabric/bccsp"
+)
+
+// deterministicHash returns the hash function of opts, or, if
+// it is zero, the SHA-2 function with the size of digest
+func deterministicHash(opts *bccsp.ECDSADeterministicSignerOpts, digest []byte) crypto.Hash {
+	if opts.Hash != 0 {
+		return opts.Hash
+	}
+	switch len(digest) {
+	case crypto.SHA384.Size():
+		return crypto.SHA384
+	case crypto.SHA512.Size():
+		return crypto.SHA512
+	default:
+		return crypto.SHA256
+	}
+}
+
+// signECDSADeterministic signs digest, computed with h, with k, deriving
+// the nonce from k and digest as specified by RFC 6979. The signature is
+// computed by crypto/ecdsa, whose arithmetic on the nonce and on the
+// private key takes a time independent of their values.
+func signECDSADeterministic(k *ecdsa.PrivateKey, digest []byte, h crypto.Hash) (r, s *big.Int, err error) {
+	if !h.Available() {
+		return nil, nil, fmt.Errorf("Hash function not available [%d]", h)
+	}
+
+	// A nil source of randomness selects the deterministic nonce of RFC 6979
+	raw, err := k.Sign(nil, digest, h)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed signing [%s]", err)
+	}
+	return unmarshalECDSASignature(raw)
+}
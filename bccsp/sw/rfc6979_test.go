@@ -0,0 +1,85 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sw
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"crypto/sha512"
+	"math/big"
+	"testing"
+)
+
+func hexToInt(t *testing.T, s string) *big.Int {
+	v, ok := new(big.Int).SetString(s, 16)
+	if !ok {
+		t.Fatalf("Invalid hex [%s]", s)
+	}
+	return v
+}
+
+// TestSignECDSADeterministic checks the test vectors
+// of RFC 6979, section A.2.5 (P-256) and A.2.6 (P-384)
+func TestSignECDSADeterministic(t *testing.T) {
+	sha384 := sha512.Sum384([]byte("sample"))
+	sha256 := sha256.Sum256([]byte("sample"))
+
+	vectors := []struct {
+		curve  elliptic.Curve
+		d      string
+		hash   crypto.Hash
+		digest []byte
+		r, s   string
+	}{
+		{
+			curve:  elliptic.P256(),
+			d:      "C9AFA9D845BA75166B5C215767B1D6934E50C3DB36E89B127B8A622B120F6721",
+			hash:   crypto.SHA256,
+			digest: sha256[:],
+			r:      "EFD48B2AACB6A8FD1140DD9CD45E81D69D2C877B56AAF991C34D0EA84EAF3716",
+			s:      "F7CB1C942D657C41D436C7A1B6E29F65F3E900DBB9AFF4064DC4AB2F843ACDA8",
+		},
+		{
+			curve:  elliptic.P384(),
+			d:      "6B9D3DAD2E1B8C1C05B19875B6659F4DE23C3B667BF297BA9AA47740787137D896D5724E4C70A825F872C9EA60D2EDF5",
+			hash:   crypto.SHA384,
+			digest: sha384[:],
+			r:      "94EDBB92A5ECB8AAD4736E56C691916B3F88140666CE9FA73D64C4EA95AD133C81A648152E44ACF96E36DD1E80FABE46",
+			s:      "99EF4AEB15F178CEA1FE40DB2603138F130E740A19624526203B6351D0A3A94FA329C145786E679E7B82C71A38628AC8",
+		},
+	}
+
+	for _, v := range vectors {
+		k := &ecdsa.PrivateKey{D: hexToInt(t, v.d)}
+		k.Curve = v.curve
+		k.X, k.Y = v.curve.ScalarBaseMult(k.D.Bytes())
+
+		r, s, err := signECDSADeterministic(k, v.digest, v.hash)
+		if err != nil {
+			t.Fatalf("Failed signing [%s]", err)
+		}
+		if r.Cmp(hexToInt(t, v.r)) != 0 || s.Cmp(hexToInt(t, v.s)) != 0 {
+			t.Fatalf("Unexpected signature on [%s]: r [%X] s [%X]", v.curve.Params().Name, r, s)
+		}
+	}
+
+	if _, _, err := signECDSADeterministic(&ecdsa.PrivateKey{}, sha256[:], crypto.Hash(0)); err == nil {
+		t.Fatal("Signing with an unavailable hash function should fail")
+	}
+}
@@ -0,0 +1,35 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sw
+
+import (
+	"crypto/rsa"
+	"errors"
+	"fmt"
+)
+
+// checkRSAKeySize returns an error if k is shorter
+// than the minimum RSA key size of this BCCSP
+func (csp *impl) checkRSAKeySize(k *rsa.PublicKey) error {
+	if k == nil || k.N == nil {
+		return errors.New("Invalid RSA key. It must be different from nil.")
+	}
+	if bits := k.N.BitLen(); bits < csp.conf.minRSAKeySize {
+		return fmt.Errorf("Invalid RSA key. It has [%d] bits, at least [%d] are required.", bits, csp.conf.minRSAKeySize)
+	}
+	return nil
+}
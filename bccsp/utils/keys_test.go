@@ -1,7 +1,9 @@
 package utils
 
 import (
+	"bytes"
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/elliptic"
 	"crypto/rand"
 	"testing"
@@ -184,3 +186,72 @@ func TestECDSAKeys(t *testing.T) {
 		t.Fatal("PEMtoPublicKey should fail on nil PEM and wrong password")
 	}
 }
+
+func TestED25519Keys(t *testing.T) {
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed generating Ed25519 key [%s]", err)
+	}
+
+	// Private Key PEM format
+	pem, err := PrivateKeyToPEM(key, nil)
+	if err != nil {
+		t.Fatalf("Failed converting private key to PEM [%s]", err)
+	}
+	keyFromPEM, err := PEMtoPrivateKey(pem, nil)
+	if err != nil {
+		t.Fatalf("Failed converting PEM to private key [%s]", err)
+	}
+	if !bytes.Equal(key, keyFromPEM.(ed25519.PrivateKey)) {
+		t.Fatal("Failed converting PEM to private key. Keys do not match.")
+	}
+
+	// Private Key Encrypted PEM format
+	encPEM, err := PrivateKeyToPEM(key, []byte("passwd"))
+	if err != nil {
+		t.Fatalf("Failed converting private key to encrypted PEM [%s]", err)
+	}
+	keyFromPEM, err = PEMtoPrivateKey(encPEM, []byte("passwd"))
+	if err != nil {
+		t.Fatalf("Failed converting encrypted PEM to private key [%s]", err)
+	}
+	if !bytes.Equal(key, keyFromPEM.(ed25519.PrivateKey)) {
+		t.Fatal("Failed converting encrypted PEM to private key. Keys do not match.")
+	}
+
+	// Public Key PEM format
+	pem, err = PublicKeyToPEM(pub, nil)
+	if err != nil {
+		t.Fatalf("Failed converting public key to PEM [%s]", err)
+	}
+	pubFromPEM, err := PEMtoPublicKey(pem, nil)
+	if err != nil {
+		t.Fatalf("Failed converting PEM to public key [%s]", err)
+	}
+	if !bytes.Equal(pub, pubFromPEM.(ed25519.PublicKey)) {
+		t.Fatal("Failed converting PEM to public key. Keys do not match.")
+	}
+
+	// Public Key Encrypted PEM format
+	encPEM, err = PublicKeyToPEM(pub, []byte("passwd"))
+	if err != nil {
+		t.Fatalf("Failed converting public key to encrypted PEM [%s]", err)
+	}
+	pubFromPEM, err = PEMtoPublicKey(encPEM, []byte("passwd"))
+	if err != nil {
+		t.Fatalf("Failed converting encrypted PEM to public key [%s]", err)
+	}
+	if !bytes.Equal(pub, pubFromPEM.(ed25519.PublicKey)) {
+		t.Fatal("Failed converting encrypted PEM to public key. Keys do not match.")
+	}
+
+	// Truncated keys
+	_, err = PrivateKeyToPEM(key[:10], nil)
+	if err == nil {
+		t.Fatal("PrivateKeyToPEM should fail on truncated key")
+	}
+	_, err = PublicKeyToPEM(pub[:10], nil)
+	if err == nil {
+		t.Fatal("PublicKeyToPEM should fail on truncated key")
+	}
+}
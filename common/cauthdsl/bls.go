@@ -0,0 +1,115 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cauthdsl
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/hyperledger/fabric/bccsp/bls"
+	"github.com/hyperledger/fabric/common/policies"
+	cb "github.com/hyperledger/fabric/protos/common"
+
+	"github.com/golang/protobuf/proto"
+)
+
+type blsAggregateProvider struct{}
+
+// NewBLSAggregatePolicyProvider provides a policy generator for BLS_AGGREGATE type policies
+func NewBLSAggregatePolicyProvider() policies.Provider {
+	return &blsAggregateProvider{}
+}
+
+// NewPolicy creates a new policy based on the policy bytes
+func (pr *blsAggregateProvider) NewPolicy(data []byte) (policies.Policy, error) {
+	blsPolicy := &cb.BLSAggregatePolicy{}
+	if err := proto.Unmarshal(data, blsPolicy); err != nil {
+		return nil, fmt.Errorf("Error unmarshaling to BLSAggregatePolicy: %s", err)
+	}
+
+	return NewBLSAggregatePolicy(int(blsPolicy.N), blsPolicy.PublicKeys)
+}
+
+type blsAggregatePolicy struct {
+	n          int
+	publicKeys map[string]bool
+}
+
+// NewBLSAggregatePolicy returns a policy satisfied by a BLS12-381 signature
+// aggregated from the signatures, over the same data, of at least n of the
+// principals identified by publicKeys. The signature set carries one
+// SignedData per signer, see BLSAggregateSignatureSet. The public keys must
+// have been checked against proofs of possession of their private keys, see
+// bls.VerifyPossession, for the aggregation to be secure.
+func NewBLSAggregatePolicy(n int, publicKeys [][]byte) (policies.Policy, error) {
+	if n < 1 || n > len(publicKeys) {
+		return nil, fmt.Errorf("Invalid threshold [%d]. It must be in [1, %d].", n, len(publicKeys))
+	}
+
+	p := &blsAggregatePolicy{n: n, publicKeys: make(map[string]bool)}
+	for i, publicKey := range publicKeys {
+		if err := bls.VerifyPublicKey(publicKey); err != nil {
+			return nil, fmt.Errorf("Invalid public key at index [%d] [%s]", i, err)
+		}
+		p.publicKeys[string(publicKey)] = true
+	}
+	return p, nil
+}
+
+// BLSAggregateSignatureSet returns the signature set carrying
+// aggregate, the aggregated signature of data by the
+// principals identified by publicKeys
+func BLSAggregateSignatureSet(data, aggregate []byte, publicKeys [][]byte) []*cb.SignedData {
+	signatureSet := make([]*cb.SignedData, len(publicKeys))
+	for i, publicKey := range publicKeys {
+		signatureSet[i] = &cb.SignedData{Data: data, Identity: publicKey, Signature: aggregate}
+	}
+	return signatureSet
+}
+
+// Evaluate takes a set of SignedData and evaluates whether this set of signatures satisfies the policy
+func (p *blsAggregatePolicy) Evaluate(signatureSet []*cb.SignedData) error {
+	if len(signatureSet) == 0 {
+		return errors.New("Failed to authenticate policy. No signature.")
+	}
+
+	data, aggregate := signatureSet[0].Data, signatureSet[0].Signature
+	signers := make(map[string]bool)
+	var publicKeys [][]byte
+	for _, sd := range signatureSet {
+		if !bytes.Equal(sd.Data, data) || !bytes.Equal(sd.Signature, aggregate) {
+			return errors.New("Failed to authenticate policy. The signature set carries more than one aggregated signature.")
+		}
+		if !p.publicKeys[string(sd.Identity)] {
+			return fmt.Errorf("Failed to authenticate policy. Unknown signer [% x].", sd.Identity)
+		}
+		if signers[string(sd.Identity)] {
+			continue
+		}
+		signers[string(sd.Identity)] = true
+		publicKeys = append(publicKeys, sd.Identity)
+	}
+
+	if len(publicKeys) < p.n {
+		return fmt.Errorf("Failed to authenticate policy. [%d] signers, [%d] required.", len(publicKeys), p.n)
+	}
+	if err := bls.VerifyAggregate(publicKeys, data, aggregate); err != nil {
+		return fmt.Errorf("Failed to authenticate policy [%s]", err)
+	}
+	return nil
+}
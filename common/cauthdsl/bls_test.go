@@ -0,0 +1,87 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cauthdsl
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/hyperledger/fabric/bccsp/bls"
+)
+
+func TestBLSAggregatePolicy(t *testing.T) {
+	data := []byte("block header")
+
+	var privs, pubs, sigs [][]byte
+	for i := 0; i < 4; i++ {
+		priv, pub, err := bls.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatalf("Failed generating key [%s]", err)
+		}
+		sig, err := bls.Sign(priv, data)
+		if err != nil {
+			t.Fatalf("Failed signing [%s]", err)
+		}
+		privs, pubs, sigs = append(privs, priv), append(pubs, pub), append(sigs, sig)
+	}
+
+	policy, err := NewBLSAggregatePolicy(3, pubs)
+	if err != nil {
+		t.Fatalf("Failed creating policy [%s]", err)
+	}
+
+	// 3 out of 4 signers
+	aggregate, err := bls.AggregateSignatures(sigs[1:])
+	if err != nil {
+		t.Fatalf("Failed aggregating signatures [%s]", err)
+	}
+	if err := policy.Evaluate(BLSAggregateSignatureSet(data, aggregate, pubs[1:])); err != nil {
+		t.Fatalf("3 out of 4 signers should satisfy the policy [%s]", err)
+	}
+	if err := policy.Evaluate(BLSAggregateSignatureSet([]byte("other"), aggregate, pubs[1:])); err == nil {
+		t.Fatal("An aggregated signature of other data should not satisfy the policy")
+	}
+	if err := policy.Evaluate(BLSAggregateSignatureSet(data, aggregate, pubs[:3])); err == nil {
+		t.Fatal("An aggregated signature by other signers should not satisfy the policy")
+	}
+
+	// 2 out of 4 signers, even if repeated
+	aggregate, _ = bls.AggregateSignatures(sigs[2:])
+	if err := policy.Evaluate(BLSAggregateSignatureSet(data, aggregate, pubs[2:])); err == nil {
+		t.Fatal("2 out of 4 signers should not satisfy the policy")
+	}
+	if err := policy.Evaluate(BLSAggregateSignatureSet(data, aggregate, [][]byte{pubs[2], pubs[3], pubs[3]})); err == nil {
+		t.Fatal("Repeated signers should be counted once")
+	}
+
+	// Signers outside of the principal set
+	_, outsider, _ := bls.GenerateKey(rand.Reader)
+	if err := policy.Evaluate(BLSAggregateSignatureSet(data, aggregate, [][]byte{pubs[2], pubs[3], outsider})); err == nil {
+		t.Fatal("Signers outside of the principal set should not satisfy the policy")
+	}
+
+	if err := policy.Evaluate(nil); err == nil {
+		t.Fatal("An empty signature set should not satisfy the policy")
+	}
+
+	if _, err := NewBLSAggregatePolicy(5, pubs); err == nil {
+		t.Fatal("A threshold larger than the principal set should be rejected")
+	}
+	if _, err := NewBLSAggregatePolicy(1, [][]byte{privs[0]}); err == nil {
+		t.Fatal("Invalid public keys should be rejected")
+	}
+}
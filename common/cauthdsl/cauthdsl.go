@@ -20,7 +20,10 @@ import (
 	"fmt"
 
 	"bytes"
+	"strings"
 
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/policies"
 	"github.com/hyperledger/fabric/msp"
 	cb "github.com/hyperledger/fabric/protos/common"
 	"github.com/op/go-logging"
@@ -31,24 +34,44 @@ var cauthdslLogger = logging.MustGetLogger("cauthdsl")
 
 // compile recursively builds a go evaluatable function corresponding to the policy specified
 func compile(policy *cb.SignaturePolicy, identities []*cb.MSPPrincipal, deserializer msp.IdentityDeserializer) (func([]*cb.SignedData, []bool) bool, error) {
+	evaluator, err := compileEvaluator(policy, identities, deserializer)
+	if err != nil {
+		return nil, err
+	}
+	return func(signedData []*cb.SignedData, used []bool) bool {
+		return evaluator(signedData, used, nil)
+	}, nil
+}
+
+// evaluator evaluates a compiled policy; if trace is
+// not nil, it is filled with the account of the evaluation
+type evaluator func(signedData []*cb.SignedData, used []bool, trace *policies.Trace) bool
+
+// compileEvaluator builds the evaluator of the policy specified
+func compileEvaluator(policy *cb.SignaturePolicy, identities []*cb.MSPPrincipal, deserializer msp.IdentityDeserializer) (evaluator, error) {
 	switch t := policy.Type.(type) {
 	case *cb.SignaturePolicy_NOutOf_:
-		policies := make([]func([]*cb.SignedData, []bool) bool, len(t.NOutOf.Policies))
+		compiledPolicies := make([]evaluator, len(t.NOutOf.Policies))
 		for i, policy := range t.NOutOf.Policies {
-			compiledPolicy, err := compile(policy, identities, deserializer)
+			compiledPolicy, err := compileEvaluator(policy, identities, deserializer)
 			if err != nil {
 				return nil, err
 			}
-			policies[i] = compiledPolicy
+			compiledPolicies[i] = compiledPolicy
 
 		}
-		return func(signedData []*cb.SignedData, used []bool) bool {
+		return func(signedData []*cb.SignedData, used []bool, trace *policies.Trace) bool {
 			cauthdslLogger.Debugf("Gate evaluation starts: (%s)", t)
 			verified := int32(0)
 			_used := make([]bool, len(used))
-			for _, policy := range policies {
+			for _, policy := range compiledPolicies {
 				copy(_used, used)
-				if policy(signedData, _used) {
+				var subTrace *policies.Trace
+				if trace != nil {
+					subTrace = &policies.Trace{}
+					trace.SubTraces = append(trace.SubTraces, subTrace)
+				}
+				if policy(signedData, _used, subTrace) {
 					verified++
 					copy(used, _used)
 				}
@@ -60,39 +83,142 @@ func compile(policy *cb.SignaturePolicy, identities []*cb.MSPPrincipal, deserial
 				cauthdslLogger.Debugf("Gate evaluation fails: (%s)", t)
 			}
 
+			if trace != nil {
+				trace.Rule = fmt.Sprintf("%d out of %d", t.NOutOf.N, len(compiledPolicies))
+				trace.Satisfied = verified >= t.NOutOf.N
+				if !trace.Satisfied {
+					trace.Reason = fmt.Sprintf("%d sub-policies satisfied", verified)
+				}
+			}
+
 			return verified >= t.NOutOf.N
 		}, nil
 	case *cb.SignaturePolicy_SignedBy:
 		if t.SignedBy < 0 || t.SignedBy >= int32(len(identities)) {
 			return nil, fmt.Errorf("Identity index out of range, requested %d, but identies length is %d", t.SignedBy, len(identities))
 		}
-		signedByID := identities[t.SignedBy]
-		return func(signedData []*cb.SignedData, used []bool) bool {
-			cauthdslLogger.Debugf("Principal evaluation starts: (%s) (used %s)", t, used)
-			for i, sd := range signedData {
-				if used[i] {
-					continue
-				}
-				// FIXME: what should I do with the error below?
-				identity, _ := deserializer.DeserializeIdentity(sd.Identity)
-				err := identity.SatisfiesPrincipal(signedByID)
-				if err == nil {
-					err := identity.Verify(sd.Data, sd.Signature)
-					if err == nil {
-						cauthdslLogger.Debugf("Principal evaluation succeeds: (%s)", t, used)
-						used[i] = true
-						return true
-					}
-				}
+		return compileSignedBy(t, identities[t.SignedBy], nil, deserializer), nil
+	case *cb.SignaturePolicy_SignedByExcept_:
+		if t.SignedByExcept.SignedBy < 0 || t.SignedByExcept.SignedBy >= int32(len(identities)) {
+			return nil, fmt.Errorf("Identity index out of range, requested %d, but identies length is %d", t.SignedByExcept.SignedBy, len(identities))
+		}
+		if len(t.SignedByExcept.Except) == 0 {
+			return nil, fmt.Errorf("No principal excluded from identity %d", t.SignedByExcept.SignedBy)
+		}
+		excluded := make([]*cb.MSPPrincipal, len(t.SignedByExcept.Except))
+		for i, index := range t.SignedByExcept.Except {
+			if index < 0 || index >= int32(len(identities)) {
+				return nil, fmt.Errorf("Identity index out of range, requested %d, but identies length is %d", index, len(identities))
 			}
-			cauthdslLogger.Debugf("Principal evaluation fails: (%s)", t, used)
-			return false
-		}, nil
+			excluded[i] = identities[index]
+		}
+		return compileSignedBy(t, identities[t.SignedByExcept.SignedBy], excluded, deserializer), nil
 	default:
 		return nil, fmt.Errorf("Unknown type: %T:%v", t, t)
 	}
 }
 
+// compileSignedBy builds the evaluator requiring a signature from an identity
+// satisfying signedByID but none of the excluded principals; t is the policy,
+// for logging
+func compileSignedBy(t interface{}, signedByID *cb.MSPPrincipal, excluded []*cb.MSPPrincipal, deserializer msp.IdentityDeserializer) evaluator {
+	return func(signedData []*cb.SignedData, used []bool, trace *policies.Trace) bool {
+		cauthdslLogger.Debugf("Principal evaluation starts: (%s) (used %s)", t, used)
+		if trace != nil {
+			trace.Rule = "Signed by " + principalString(signedByID)
+			for i, principal := range excluded {
+				if i == 0 {
+					trace.Rule += " except "
+				} else {
+					trace.Rule += ", "
+				}
+				trace.Rule += principalString(principal)
+			}
+		}
+		for i, sd := range signedData {
+			var sigTrace *policies.SignatureTrace
+			if trace != nil {
+				sigTrace = &policies.SignatureTrace{Index: i}
+				trace.Signatures = append(trace.Signatures, sigTrace)
+			}
+			if used[i] {
+				unmatched(sigTrace, "Already counted for another principal")
+				continue
+			}
+			identity, err := deserializer.DeserializeIdentity(sd.Identity)
+			if err != nil {
+				cauthdslLogger.Errorf("Principal deserialization failed: (%s) for identity %v", err, sd.Identity)
+				unmatched(sigTrace, fmt.Sprintf("Identity cannot be deserialized: %s", err))
+				continue
+			}
+			if sigTrace != nil {
+				sigTrace.Signer = identity.GetMSPIdentifier()
+			}
+			err = identity.SatisfiesPrincipal(signedByID)
+			if err != nil {
+				unmatched(sigTrace, fmt.Sprintf("Identity does not satisfy the principal: %s", err))
+				continue
+			}
+			if principal := excludedPrincipal(identity, excluded); principal != nil {
+				unmatched(sigTrace, fmt.Sprintf("Identity satisfies the excluded principal %s", principalString(principal)))
+				continue
+			}
+			err = identity.Verify(sd.Data, sd.Signature)
+			if err != nil {
+				unmatched(sigTrace, fmt.Sprintf("Invalid signature: %s", err))
+				continue
+			}
+			cauthdslLogger.Debugf("Principal evaluation succeeds: (%s)", t, used)
+			used[i] = true
+			if trace != nil {
+				sigTrace.Matched = true
+				trace.Satisfied = true
+			}
+			return true
+		}
+		cauthdslLogger.Debugf("Principal evaluation fails: (%s)", t, used)
+		if trace != nil {
+			trace.Reason = "No signature matched"
+		}
+		return false
+	}
+}
+
+// excludedPrincipal returns the first of the excluded
+// principals identity satisfies, if any
+func excludedPrincipal(identity msp.Identity, excluded []*cb.MSPPrincipal) *cb.MSPPrincipal {
+	for _, principal := range excluded {
+		if identity.SatisfiesPrincipal(principal) == nil {
+			return principal
+		}
+	}
+	return nil
+}
+
+// unmatched records in sigTrace, if not nil, why the signature did not match
+func unmatched(sigTrace *policies.SignatureTrace, reason string) {
+	if sigTrace != nil {
+		sigTrace.Reason = reason
+	}
+}
+
+// principalString describes principal in the syntax of the policy parser when possible
+func principalString(principal *cb.MSPPrincipal) string {
+	if principal.PrincipalClassification == cb.MSPPrincipal_ROLE {
+		role := &cb.MSPRole{}
+		if err := proto.Unmarshal(principal.Principal, role); err == nil {
+			return fmt.Sprintf("'%s.%s'", role.MspIdentifier, strings.ToLower(role.Role.String()))
+		}
+	}
+	if principal.PrincipalClassification == cb.MSPPrincipal_ORGANIZATION_UNIT {
+		ou := &cb.OrganizationUnit{}
+		if err := proto.Unmarshal(principal.Principal, ou); err == nil {
+			return fmt.Sprintf("'%s.ou.%s'", ou.MspIdentifier, ou.OrganizationalUnitIdentifier)
+		}
+	}
+	return fmt.Sprintf("%s principal", principal.PrincipalClassification)
+}
+
 // FIXME: remove the code below as soon as we can use MSP from the policy manager code
 var invalidSignature = []byte("badsigned")
 
@@ -120,8 +246,12 @@ func (id *mockIdentity) Validate() error {
 	return nil
 }
 
-func (id *mockIdentity) GetOrganizationalUnits() []string {
-	return []string{"dunno"}
+func (id *mockIdentity) GetOrganizationalUnits() []*msp.OUIdentifier {
+	return []*msp.OUIdentifier{{OrganizationalUnitIdentifier: "dunno", CertifiersIdentifier: []byte("dunno")}}
+}
+
+func (id *mockIdentity) Anonymous() bool {
+	return false
 }
 
 func (id *mockIdentity) Verify(msg []byte, sig []byte) error {
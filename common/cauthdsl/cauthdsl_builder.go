@@ -74,6 +74,19 @@ func SignedBy(index int32) *cb.SignaturePolicy {
 	}
 }
 
+// SignedByExcept creates a SignaturePolicy requiring the signature of a given
+// signer which is none of the excluded signers
+func SignedByExcept(index int32, except ...int32) *cb.SignaturePolicy {
+	return &cb.SignaturePolicy{
+		Type: &cb.SignaturePolicy_SignedByExcept_{
+			SignedByExcept: &cb.SignaturePolicy_SignedByExcept{
+				SignedBy: index,
+				Except:   except,
+			},
+		},
+	}
+}
+
 // SignedByMspMember creates a SignaturePolicyEnvelope
 // requiring 1 signature from any member of the specified MSP
 func SignedByMspMember(mspId string) *cb.SignaturePolicyEnvelope {
@@ -92,6 +105,48 @@ func SignedByMspMember(mspId string) *cb.SignaturePolicyEnvelope {
 	return p
 }
 
+// SignedByMspAttribute creates a SignaturePolicyEnvelope requiring
+// 1 signature from any member of the specified MSP holding the
+// Fabric CA attribute name with the given value, or with any
+// value if value is empty
+func SignedByMspAttribute(mspId, name, value string) *cb.SignaturePolicyEnvelope {
+	// specify the principal: it's a member of the msp holding the attribute
+	principal := &cb.MSPPrincipal{
+		PrincipalClassification: cb.MSPPrincipal_ATTRIBUTE,
+		Principal:               utils.MarshalOrPanic(&cb.MSPAttribute{MspIdentifier: mspId, Name: name, Value: value})}
+
+	// create the policy: it requires exactly 1 signature from the first (and only) principal
+	p := &cb.SignaturePolicyEnvelope{
+		Version:    0,
+		Policy:     NOutOf(1, []*cb.SignaturePolicy{SignedBy(0)}),
+		Identities: []*cb.MSPPrincipal{principal},
+	}
+
+	return p
+}
+
+// SignedByMspMemberExceptOU creates a SignaturePolicyEnvelope requiring
+// 1 signature from any member of the specified MSP which is not a member
+// of the organizational unit ou
+func SignedByMspMemberExceptOU(mspId, ou string) *cb.SignaturePolicyEnvelope {
+	// specify the principals: a member of the msp, and a member of the excluded OU
+	principal := &cb.MSPPrincipal{
+		PrincipalClassification: cb.MSPPrincipal_ROLE,
+		Principal:               utils.MarshalOrPanic(&cb.MSPRole{Role: cb.MSPRole_MEMBER, MspIdentifier: mspId})}
+	excluded := &cb.MSPPrincipal{
+		PrincipalClassification: cb.MSPPrincipal_ORGANIZATION_UNIT,
+		Principal:               utils.MarshalOrPanic(&cb.OrganizationUnit{MspIdentifier: mspId, OrganizationalUnitIdentifier: ou})}
+
+	// create the policy: it requires exactly 1 signature from the first principal but not the second
+	p := &cb.SignaturePolicyEnvelope{
+		Version:    0,
+		Policy:     NOutOf(1, []*cb.SignaturePolicy{SignedByExcept(0, 1)}),
+		Identities: []*cb.MSPPrincipal{principal, excluded},
+	}
+
+	return p
+}
+
 // SignedByMspAdmin creates a SignaturePolicyEnvelope
 // requiring 1 signature from any admin of the specified MSP
 func SignedByMspAdmin(mspId string) *cb.SignaturePolicyEnvelope {
@@ -17,9 +17,11 @@ limitations under the License.
 package cauthdsl
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/policies"
 	cb "github.com/hyperledger/fabric/protos/common"
 )
 
@@ -102,3 +104,76 @@ func TestNegatively(t *testing.T) {
 		t.Fatal("Should have errored compiling because the Type field was nil")
 	}
 }
+
+func TestTrace(t *testing.T) {
+	policy := Envelope(And(SignedBy(0), SignedBy(1)), signers)
+
+	spe, err := compileEvaluator(policy.Policy, policy.Identities, &mockDeserializer{})
+	if err != nil {
+		t.Fatalf("Could not create a new SignaturePolicyEvaluator using the given policy, crypto-helper: %s", err)
+	}
+
+	signedData, used := toSignedData(msgs, signers, [][]byte{validSignature, invalidSignature})
+	trace := &policies.Trace{}
+	if spe(signedData, used, trace) {
+		t.Fatalf("Expected authentication to fail given one of two invalid signatures")
+	}
+	if trace.Satisfied || trace.Rule != "2 out of 2" || len(trace.SubTraces) != 2 {
+		t.Fatalf("Unexpected trace of the gate: %s", trace)
+	}
+
+	signer0 := trace.SubTraces[0]
+	if !signer0.Satisfied || len(signer0.Signatures) != 1 || !signer0.Signatures[0].Matched {
+		t.Fatalf("Expected the signature of signer0 to match: %s", trace)
+	}
+
+	signer1 := trace.SubTraces[1]
+	if signer1.Satisfied || len(signer1.Signatures) != 2 {
+		t.Fatalf("Expected no signature to match signer1: %s", trace)
+	}
+	if signer1.Signatures[0].Matched || !strings.Contains(signer1.Signatures[0].Reason, "Already counted") {
+		t.Fatalf("Expected the signature of signer0 to be already counted: %s", trace)
+	}
+	if signer1.Signatures[1].Matched || !strings.Contains(signer1.Signatures[1].Reason, "Invalid signature") {
+		t.Fatalf("Expected the signature of signer1 to be invalid: %s", trace)
+	}
+	if signer1.Signatures[1].Signer != "Mock" {
+		t.Fatalf("Expected the signer to be reported: %s", trace)
+	}
+}
+
+func TestSignedByExcept(t *testing.T) {
+	policy := Envelope(SignedByExcept(0, 1), [][]byte{signers[0], signers[1]})
+
+	spe, err := compile(policy.Policy, policy.Identities, &mockDeserializer{})
+	if err != nil {
+		t.Fatalf("Could not create a new SignaturePolicyEvaluator using the given policy, crypto-helper: %s", err)
+	}
+	if !spe(toSignedData([][]byte{nil}, [][]byte{signers[0]}, [][]byte{validSignature})) {
+		t.Errorf("Expected authentication to succeed with the valid signature of signer0")
+	}
+	if spe(toSignedData([][]byte{nil}, [][]byte{signers[1]}, [][]byte{validSignature})) {
+		t.Errorf("Expected authentication to fail because signers[1] is not authorized in the policy")
+	}
+
+	// The excluded principal also identifies signer0
+	policy = Envelope(SignedByExcept(0, 1), [][]byte{signers[0], signers[0]})
+	evaluator, err := compileEvaluator(policy.Policy, policy.Identities, &mockDeserializer{})
+	if err != nil {
+		t.Fatalf("Could not create a new SignaturePolicyEvaluator using the given policy, crypto-helper: %s", err)
+	}
+	signedData, used := toSignedData([][]byte{nil}, [][]byte{signers[0]}, [][]byte{validSignature})
+	trace := &policies.Trace{}
+	if evaluator(signedData, used, trace) {
+		t.Errorf("Expected authentication to fail because signers[0] satisfies the excluded principal")
+	}
+	if !strings.Contains(trace.Signatures[0].Reason, "excluded principal") {
+		t.Errorf("Expected the exclusion to be traced: %s", trace)
+	}
+
+	for _, policy := range []*cb.SignaturePolicy{SignedByExcept(0), SignedByExcept(0, 2), SignedByExcept(2, 0)} {
+		if _, err := compile(policy, Envelope(nil, signers).Identities, &mockDeserializer{}); err == nil {
+			t.Errorf("Should have errored compiling %v", policy)
+		}
+	}
+}
@@ -49,19 +49,56 @@ func (pr *provider) NewPolicy(data []byte) (policies.Policy, error) {
 		return nil, fmt.Errorf("This evaluator only understands messages of version 0, but version was %d", sigPolicy.Version)
 	}
 
-	compiled, err := compile(sigPolicy.Policy, sigPolicy.Identities, pr.deserializer)
+	compiled, err := compileEvaluator(sigPolicy.Policy, sigPolicy.Identities, pr.deserializer)
 	if err != nil {
 		return nil, err
 	}
 
 	return &policy{
 		evaluator: compiled,
+		principal: singlePrincipal(sigPolicy),
 	}, nil
 
 }
 
+// singlePrincipal returns the principal of sigPolicy if sigPolicy
+// requires exactly one signature by that principal, nil otherwise
+func singlePrincipal(sigPolicy *cb.SignaturePolicyEnvelope) *cb.MSPPrincipal {
+	signedBy, ok := sigPolicy.Policy.Type.(*cb.SignaturePolicy_SignedBy)
+	if !ok {
+		// A 1-out-of-1 gate is equivalent to its only sub-policy
+		nOutOf, ok := sigPolicy.Policy.Type.(*cb.SignaturePolicy_NOutOf_)
+		if !ok || nOutOf.NOutOf.N != 1 || len(nOutOf.NOutOf.Policies) != 1 {
+			return nil
+		}
+		signedBy, ok = nOutOf.NOutOf.Policies[0].Type.(*cb.SignaturePolicy_SignedBy)
+		if !ok {
+			return nil
+		}
+	}
+
+	if signedBy.SignedBy < 0 || signedBy.SignedBy >= int32(len(sigPolicy.Identities)) {
+		return nil
+	}
+
+	return sigPolicy.Identities[signedBy.SignedBy]
+}
+
 type policy struct {
-	evaluator func([]*cb.SignedData, []bool) bool
+	evaluator evaluator
+
+	// principal is the only principal this policy
+	// requires a signature of, if any
+	principal *cb.MSPPrincipal
+}
+
+// SinglePrincipal returns the principal of this policy and true if this policy
+// requires exactly one signature of that principal, or false otherwise
+func (p *policy) SinglePrincipal() (*cb.MSPPrincipal, bool) {
+	if p == nil || p.principal == nil {
+		return nil, false
+	}
+	return p.principal, true
 }
 
 // Evaluate takes a set of SignedData and evaluates whether this set of signatures satisfies the policy
@@ -70,9 +107,25 @@ func (p *policy) Evaluate(signatureSet []*cb.SignedData) error {
 		return fmt.Errorf("No such policy")
 	}
 
-	ok := p.evaluator(signatureSet, make([]bool, len(signatureSet)))
+	ok := p.evaluator(signatureSet, make([]bool, len(signatureSet)), nil)
 	if !ok {
 		return errors.New("Failed to authenticate policy")
 	}
 	return nil
 }
+
+// EvaluateWithTrace evaluates the signature set as Evaluate does, and
+// returns along which signatures matched which principals, and why
+func (p *policy) EvaluateWithTrace(signatureSet []*cb.SignedData) (*policies.Trace, error) {
+	if p == nil {
+		err := fmt.Errorf("No such policy")
+		return &policies.Trace{Rule: "Unknown policy", Reason: err.Error()}, err
+	}
+
+	trace := &policies.Trace{}
+	ok := p.evaluator(signatureSet, make([]bool, len(signatureSet)), trace)
+	if !ok {
+		return trace, errors.New("Failed to authenticate policy")
+	}
+	return trace, nil
+}
@@ -112,3 +112,67 @@ func TestRejectOnUnknown(t *testing.T) {
 		t.Fatal("Should have errored evaluating the default policy")
 	}
 }
+
+func TestSinglePrincipal(t *testing.T) {
+	provider := NewPolicyProvider(&mockDeserializer{})
+
+	for _, envelope := range []*cb.SignaturePolicyEnvelope{
+		SignedByMspMember("SampleOrg"),
+		Envelope(SignedBy(0), [][]byte{[]byte("SampleIdentity")}),
+	} {
+		p, err := provider.NewPolicy(marshalOrPanic(envelope))
+		if err != nil {
+			t.Fatalf("Should not have errored creating policy: %s", err)
+		}
+		principal, ok := p.(policies.SinglePrincipalPolicy).SinglePrincipal()
+		if !ok {
+			t.Fatalf("Policy %v should have reduced to a single principal", envelope)
+		}
+		if !proto.Equal(principal, envelope.Identities[0]) {
+			t.Fatalf("Expected principal %v, got %v", envelope.Identities[0], principal)
+		}
+	}
+
+	for _, envelope := range []*cb.SignaturePolicyEnvelope{
+		AcceptAllPolicy,
+		RejectAllPolicy,
+		Envelope(And(SignedBy(0), SignedBy(1)), [][]byte{[]byte("A"), []byte("B")}),
+		Envelope(Or(SignedBy(0), SignedBy(1)), [][]byte{[]byte("A"), []byte("B")}),
+	} {
+		p, err := provider.NewPolicy(marshalOrPanic(envelope))
+		if err != nil {
+			t.Fatalf("Should not have errored creating policy: %s", err)
+		}
+		if _, ok := p.(policies.SinglePrincipalPolicy).SinglePrincipal(); ok {
+			t.Fatalf("Policy %v should not have reduced to a single principal", envelope)
+		}
+	}
+}
+
+func TestEvaluateWithTrace(t *testing.T) {
+	provider := NewPolicyProvider(&mockDeserializer{})
+	p, err := provider.NewPolicy(marshalOrPanic(SignedByMspMember("SampleOrg")))
+	if err != nil {
+		t.Fatalf("Should not have errored creating policy: %s", err)
+	}
+
+	trace, err := p.(policies.TracingPolicy).EvaluateWithTrace([]*cb.SignedData{{Identity: []byte("SampleIdentity")}})
+	if err == nil {
+		t.Fatal("Should have errored evaluating the policy with a signature of another principal")
+	}
+	if trace.Satisfied || trace.Rule != "1 out of 1" || len(trace.SubTraces) != 1 {
+		t.Fatalf("Unexpected trace: %s", trace)
+	}
+	signedBy := trace.SubTraces[0]
+	if signedBy.Satisfied || signedBy.Rule != "Signed by 'SampleOrg.member'" || len(signedBy.Signatures) != 1 {
+		t.Fatalf("Unexpected trace: %s", trace)
+	}
+	if signedBy.Signatures[0].Matched || signedBy.Signatures[0].Reason == "" {
+		t.Fatalf("Expected the signature not to match, with a reason: %s", trace)
+	}
+
+	trace, err = p.(*policy).EvaluateWithTrace(nil)
+	if err == nil || trace.Satisfied || trace.SubTraces[0].Reason != "No signature matched" {
+		t.Fatalf("Unexpected trace of an empty signature set: %s", trace)
+	}
+}
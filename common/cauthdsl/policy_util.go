@@ -32,3 +32,16 @@ func TemplatePolicy(key string, sigPolicyEnv *cb.SignaturePolicyEnvelope) *cb.Co
 	}
 	return configGroup
 }
+
+// TemplateBLSAggregatePolicy creates a headerless configuration item representing a BLS_AGGREGATE
+// policy for a given key, satisfied by the aggregated signature of n of the holders of publicKeys
+func TemplateBLSAggregatePolicy(key string, n int32, publicKeys [][]byte) *cb.ConfigGroup {
+	configGroup := cb.NewConfigGroup()
+	configGroup.Policies[key] = &cb.ConfigPolicy{
+		Policy: &cb.Policy{
+			Type:   int32(cb.Policy_BLS_AGGREGATE),
+			Policy: utils.MarshalOrPanic(&cb.BLSAggregatePolicy{N: n, PublicKeys: publicKeys}),
+		},
+	}
+	return configGroup
+}
@@ -21,13 +21,23 @@ import (
 	"reflect"
 	"regexp"
 	"strconv"
+	"strings"
 
 	"github.com/Knetic/govaluate"
 	"github.com/hyperledger/fabric/protos/common"
 	"github.com/hyperledger/fabric/protos/utils"
 )
 
-var regex *regexp.Regexp = regexp.MustCompile("^([[:alnum:]]+)([.])(member|admin)$")
+var regex *regexp.Regexp = regexp.MustCompile("^([[:alnum:]]+)([.])(member|admin|client|peer|orderer)$")
+
+var attrRegex *regexp.Regexp = regexp.MustCompile("^([[:alnum:]]+)[.]attr[.]([^=']+)=([^']*)$")
+
+var ouRegex *regexp.Regexp = regexp.MustCompile("^([[:alnum:]]+)[.]ou[.]([^']+)$")
+
+// isPrincipal returns whether s is a principal, by role, by attribute or by OU
+func isPrincipal(s string) bool {
+	return regex.MatchString(s) || attrRegex.MatchString(s) || ouRegex.MatchString(s)
+}
 
 func and(args ...interface{}) (interface{}, error) {
 	toret := "outof(" + strconv.Itoa(len(args))
@@ -35,7 +45,7 @@ func and(args ...interface{}) (interface{}, error) {
 		toret += ", "
 		switch t := arg.(type) {
 		case string:
-			if regex.MatchString(t) {
+			if isPrincipal(t) {
 				toret += "'" + t + "'"
 			} else {
 				toret += t
@@ -54,7 +64,7 @@ func or(args ...interface{}) (interface{}, error) {
 		toret += ", "
 		switch t := arg.(type) {
 		case string:
-			if regex.MatchString(t) {
+			if isPrincipal(t) {
 				toret += "'" + t + "'"
 			} else {
 				toret += t
@@ -67,13 +77,32 @@ func or(args ...interface{}) (interface{}, error) {
 	return toret + ")", nil
 }
 
+func except(args ...interface{}) (interface{}, error) {
+	if len(args) < 2 {
+		return nil, fmt.Errorf("At least 2 arguments expected, got %d", len(args))
+	}
+	toret := "except("
+	for i, arg := range args {
+		if i > 0 {
+			toret += ", "
+		}
+		t, ok := arg.(string)
+		if !ok || !isPrincipal(t) {
+			return nil, fmt.Errorf("Unexpected argument %v, expected a principal", arg)
+		}
+		toret += "'" + t + "'"
+	}
+
+	return toret + ")", nil
+}
+
 func firstPass(args ...interface{}) (interface{}, error) {
 	toret := "outof(ID"
 	for _, arg := range args {
 		toret += ", "
 		switch t := arg.(type) {
 		case string:
-			if regex.MatchString(t) {
+			if isPrincipal(t) {
 				toret += "'" + t + "'"
 			} else {
 				toret += t
@@ -89,6 +118,14 @@ func firstPass(args ...interface{}) (interface{}, error) {
 	return toret + ")", nil
 }
 
+func firstPassExcept(args ...interface{}) (interface{}, error) {
+	res, err := firstPass(args...)
+	if err != nil {
+		return nil, err
+	}
+	return "except" + strings.TrimPrefix(res.(string), "outof"), nil
+}
+
 func secondPass(args ...interface{}) (interface{}, error) {
 	/* general sanity check, we expect at least 3 args */
 	if len(args) < 3 {
@@ -127,28 +164,12 @@ func secondPass(args ...interface{}) (interface{}, error) {
 	/* handle the rest of the arguments */
 	for _, principal := range args[2:] {
 		switch t := principal.(type) {
-		/* if it's a string, we expect it to be formed as
-		   <MSP_ID> . <ROLE>, where MSP_ID is the MSP identifier
-		   and ROLE is either a member of an admin*/
+		/* if it's a string, we expect it to be a principal */
 		case string:
-			/* split the string */
-			subm := regex.FindAllStringSubmatch(t, -1)
-			if subm == nil || len(subm) != 1 || len(subm[0]) != 4 {
-				return nil, fmt.Errorf("Error parsing principal %s", t)
-			}
-
-			/* get the right role */
-			var r common.MSPRole_MSPRoleType
-			if subm[0][3] == "member" {
-				r = common.MSPRole_MEMBER
-			} else {
-				r = common.MSPRole_ADMIN
+			p, err := parsePrincipal(t)
+			if err != nil {
+				return nil, err
 			}
-
-			/* build the principal we've been told */
-			p := &common.MSPPrincipal{
-				PrincipalClassification: common.MSPPrincipal_ROLE,
-				Principal:               utils.MarshalOrPanic(&common.MSPRole{MspIdentifier: subm[0][1], Role: r})}
 			ctx.principals = append(ctx.principals, p)
 
 			/* create a SignaturePolicy that requires a signature from
@@ -175,6 +196,80 @@ func secondPass(args ...interface{}) (interface{}, error) {
 	return NOutOf(int32(t), policies), nil
 }
 
+func secondPassExcept(args ...interface{}) (interface{}, error) {
+	/* we expect the context, the principal and at least an excluded principal */
+	if len(args) < 3 {
+		return nil, fmt.Errorf("At least 3 arguments expected, got %d", len(args))
+	}
+
+	ctx, ok := args[0].(*context)
+	if !ok {
+		return nil, fmt.Errorf("Unrecognized type, expected the context, got %s", reflect.TypeOf(args[0]))
+	}
+
+	/* the first principal is the one required, the others the ones excluded */
+	indices := make([]int32, len(args)-1)
+	for i, principal := range args[1:] {
+		t, ok := principal.(string)
+		if !ok {
+			return nil, fmt.Errorf("Unrecognized type, expected a principal, got %s", reflect.TypeOf(principal))
+		}
+		p, err := parsePrincipal(t)
+		if err != nil {
+			return nil, err
+		}
+		ctx.principals = append(ctx.principals, p)
+		indices[i] = int32(ctx.IDNum)
+		ctx.IDNum++
+	}
+
+	return SignedByExcept(indices[0], indices[1:]...), nil
+}
+
+// parsePrincipal parses a principal, formed as <MSP_ID> . <ROLE>, where
+// MSP_ID is the MSP identifier and ROLE is either a member, an admin, a
+// client, a peer or an orderer, as <MSP_ID> . attr . <NAME> = <VALUE>, or
+// as <MSP_ID> . ou . <OU>
+func parsePrincipal(t string) (*common.MSPPrincipal, error) {
+	if attrSubm := attrRegex.FindStringSubmatch(t); attrSubm != nil {
+		return &common.MSPPrincipal{
+			PrincipalClassification: common.MSPPrincipal_ATTRIBUTE,
+			Principal:               utils.MarshalOrPanic(&common.MSPAttribute{MspIdentifier: attrSubm[1], Name: attrSubm[2], Value: attrSubm[3]})}, nil
+	}
+
+	if ouSubm := ouRegex.FindStringSubmatch(t); ouSubm != nil {
+		return &common.MSPPrincipal{
+			PrincipalClassification: common.MSPPrincipal_ORGANIZATION_UNIT,
+			Principal:               utils.MarshalOrPanic(&common.OrganizationUnit{MspIdentifier: ouSubm[1], OrganizationalUnitIdentifier: ouSubm[2]})}, nil
+	}
+
+	/* split the string */
+	subm := regex.FindAllStringSubmatch(t, -1)
+	if subm == nil || len(subm) != 1 || len(subm[0]) != 4 {
+		return nil, fmt.Errorf("Error parsing principal %s", t)
+	}
+
+	/* get the right role */
+	var r common.MSPRole_MSPRoleType
+	switch subm[0][3] {
+	case "member":
+		r = common.MSPRole_MEMBER
+	case "admin":
+		r = common.MSPRole_ADMIN
+	case "client":
+		r = common.MSPRole_CLIENT
+	case "peer":
+		r = common.MSPRole_PEER
+	case "orderer":
+		r = common.MSPRole_ORDERER
+	}
+
+	/* build the principal we've been told */
+	return &common.MSPPrincipal{
+		PrincipalClassification: common.MSPPrincipal_ROLE,
+		Principal:               utils.MarshalOrPanic(&common.MSPRole{MspIdentifier: subm[0][1], Role: r})}, nil
+}
+
 type context struct {
 	IDNum      int
 	principals []*common.MSPPrincipal
@@ -192,7 +287,10 @@ func newContext() *context {
 //
 // where
 //	- GATE is either "and" or "or"
-//	- P is either a principal or another nested call to GATE
+//	- P is either a principal, another nested call to GATE or
+//	  EXCEPT(PRINCIPAL, PRINCIPAL[, PRINCIPAL]), which requires a
+//	  signature from the first principal by an identity which
+//	  satisfies none of the others
 //
 // a principal is defined as
 //
@@ -200,10 +298,26 @@ func newContext() *context {
 //
 // where
 //	- ORG is a string (representing the MSP identifier)
-//	- ROLE is either the string "member" or the string "admin" representing the required role
+//	- ROLE is either the string "member", "admin", "client", "peer" or
+//	  "orderer" representing the required role
+//
+// or as
+//
+// ORG.attr.NAME=VALUE
+//
+// where
+//	- NAME is the name of the Fabric CA attribute the identity must hold
+//	- VALUE is the value the attribute must have, any value if it is empty
+//
+// or as
+//
+// ORG.ou.OU
+//
+// where
+//	- OU is the organizational unit the identity must be a member of
 func FromString(policy string) (*common.SignaturePolicyEnvelope, error) {
 	// first we translate the and/or business into outof gates
-	intermediate, err := govaluate.NewEvaluableExpressionWithFunctions(policy, map[string]govaluate.ExpressionFunction{"AND": and, "and": and, "OR": or, "or": or})
+	intermediate, err := govaluate.NewEvaluableExpressionWithFunctions(policy, map[string]govaluate.ExpressionFunction{"AND": and, "and": and, "OR": or, "or": or, "EXCEPT": except, "except": except})
 	if err != nil {
 		return nil, err
 	}
@@ -219,7 +333,7 @@ func FromString(policy string) (*common.SignaturePolicyEnvelope, error) {
 	// to user-implemented functions other than via arguments.
 	// We need this argument because we need a global place where
 	// we put the identities that the policy requires
-	exp, err := govaluate.NewEvaluableExpressionWithFunctions(intermediateRes.(string), map[string]govaluate.ExpressionFunction{"outof": firstPass})
+	exp, err := govaluate.NewEvaluableExpressionWithFunctions(intermediateRes.(string), map[string]govaluate.ExpressionFunction{"outof": firstPass, "except": firstPassExcept})
 	if err != nil {
 		return nil, err
 	}
@@ -233,7 +347,7 @@ func FromString(policy string) (*common.SignaturePolicyEnvelope, error) {
 	parameters := make(map[string]interface{}, 1)
 	parameters["ID"] = ctx
 
-	exp, err = govaluate.NewEvaluableExpressionWithFunctions(res.(string), map[string]govaluate.ExpressionFunction{"outof": secondPass})
+	exp, err = govaluate.NewEvaluableExpressionWithFunctions(res.(string), map[string]govaluate.ExpressionFunction{"outof": secondPass, "except": secondPassExcept})
 	if err != nil {
 		return nil, err
 	}
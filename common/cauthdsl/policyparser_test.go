@@ -128,3 +128,105 @@ func TestComplex2(t *testing.T) {
 
 	assert.True(t, reflect.DeepEqual(p1, p2))
 }
+
+func TestNodeOURoles(t *testing.T) {
+	p1, err := FromString("OR('A.client', 'B.peer', 'C.orderer')")
+	assert.NoError(t, err)
+
+	principals := make([]*common.MSPPrincipal, 0)
+
+	principals = append(principals, &common.MSPPrincipal{
+		PrincipalClassification: common.MSPPrincipal_ROLE,
+		Principal:               utils.MarshalOrPanic(&common.MSPRole{Role: common.MSPRole_CLIENT, MspIdentifier: "A"})})
+
+	principals = append(principals, &common.MSPPrincipal{
+		PrincipalClassification: common.MSPPrincipal_ROLE,
+		Principal:               utils.MarshalOrPanic(&common.MSPRole{Role: common.MSPRole_PEER, MspIdentifier: "B"})})
+
+	principals = append(principals, &common.MSPPrincipal{
+		PrincipalClassification: common.MSPPrincipal_ROLE,
+		Principal:               utils.MarshalOrPanic(&common.MSPRole{Role: common.MSPRole_ORDERER, MspIdentifier: "C"})})
+
+	p2 := &common.SignaturePolicyEnvelope{
+		Version:    0,
+		Policy:     NOutOf(1, []*common.SignaturePolicy{SignedBy(0), SignedBy(1), SignedBy(2)}),
+		Identities: principals,
+	}
+
+	assert.True(t, reflect.DeepEqual(p1, p2))
+}
+
+func TestAttributes(t *testing.T) {
+	p1, err := FromString("AND('A.attr.department=treasury', OR('B.member', 'B.attr.hf.Type='))")
+	assert.NoError(t, err)
+
+	principals := make([]*common.MSPPrincipal, 0)
+
+	principals = append(principals, &common.MSPPrincipal{
+		PrincipalClassification: common.MSPPrincipal_ROLE,
+		Principal:               utils.MarshalOrPanic(&common.MSPRole{Role: common.MSPRole_MEMBER, MspIdentifier: "B"})})
+
+	principals = append(principals, &common.MSPPrincipal{
+		PrincipalClassification: common.MSPPrincipal_ATTRIBUTE,
+		Principal:               utils.MarshalOrPanic(&common.MSPAttribute{MspIdentifier: "B", Name: "hf.Type"})})
+
+	principals = append(principals, &common.MSPPrincipal{
+		PrincipalClassification: common.MSPPrincipal_ATTRIBUTE,
+		Principal:               utils.MarshalOrPanic(&common.MSPAttribute{MspIdentifier: "A", Name: "department", Value: "treasury"})})
+
+	p2 := &common.SignaturePolicyEnvelope{
+		Version:    0,
+		Policy:     And(SignedBy(2), Or(SignedBy(0), SignedBy(1))),
+		Identities: principals,
+	}
+
+	assert.True(t, reflect.DeepEqual(p1, p2))
+
+	assert.True(t, reflect.DeepEqual(SignedByMspAttribute("A", "department", "treasury"), &common.SignaturePolicyEnvelope{
+		Version:    0,
+		Policy:     NOutOf(1, []*common.SignaturePolicy{SignedBy(0)}),
+		Identities: principals[2:],
+	}))
+}
+
+func TestExcept(t *testing.T) {
+	p1, err := FromString("AND('B.peer', EXCEPT('A.member', 'A.ou.contractors', 'A.admin'))")
+	assert.NoError(t, err)
+
+	principals := make([]*common.MSPPrincipal, 0)
+
+	principals = append(principals, &common.MSPPrincipal{
+		PrincipalClassification: common.MSPPrincipal_ROLE,
+		Principal:               utils.MarshalOrPanic(&common.MSPRole{Role: common.MSPRole_MEMBER, MspIdentifier: "A"})})
+
+	principals = append(principals, &common.MSPPrincipal{
+		PrincipalClassification: common.MSPPrincipal_ORGANIZATION_UNIT,
+		Principal:               utils.MarshalOrPanic(&common.OrganizationUnit{MspIdentifier: "A", OrganizationalUnitIdentifier: "contractors"})})
+
+	principals = append(principals, &common.MSPPrincipal{
+		PrincipalClassification: common.MSPPrincipal_ROLE,
+		Principal:               utils.MarshalOrPanic(&common.MSPRole{Role: common.MSPRole_ADMIN, MspIdentifier: "A"})})
+
+	principals = append(principals, &common.MSPPrincipal{
+		PrincipalClassification: common.MSPPrincipal_ROLE,
+		Principal:               utils.MarshalOrPanic(&common.MSPRole{Role: common.MSPRole_PEER, MspIdentifier: "B"})})
+
+	p2 := &common.SignaturePolicyEnvelope{
+		Version:    0,
+		Policy:     And(SignedBy(3), SignedByExcept(0, 1, 2)),
+		Identities: principals,
+	}
+
+	assert.True(t, reflect.DeepEqual(p1, p2))
+
+	assert.True(t, reflect.DeepEqual(SignedByMspMemberExceptOU("A", "contractors"), &common.SignaturePolicyEnvelope{
+		Version:    0,
+		Policy:     NOutOf(1, []*common.SignaturePolicy{SignedByExcept(0, 1)}),
+		Identities: principals[:2],
+	}))
+
+	_, err = FromString("EXCEPT('A.member')")
+	assert.Error(t, err)
+	_, err = FromString("EXCEPT('A.member', OR('B.member', 'C.member'))")
+	assert.Error(t, err)
+}
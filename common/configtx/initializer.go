@@ -74,9 +74,19 @@ func newResources() *resources {
 			policyProviderMap[pType] = cauthdsl.NewPolicyProvider(mspConfigHandler)
 		case cb.Policy_MSP:
 			// Add hook for MSP Handler here
+		case cb.Policy_BLS_AGGREGATE:
+			policyProviderMap[pType] = cauthdsl.NewBLSAggregatePolicyProvider()
 		}
 	}
 
+	pluginProviders, err := policies.PluginProviders(mspConfigHandler)
+	if err != nil {
+		logger.Errorf("Policy types of plugins will not be available: %s", err)
+	}
+	for pType, provider := range pluginProviders {
+		policyProviderMap[pType] = provider
+	}
+
 	return &resources{
 		policyManager:    policies.NewManagerImpl(RootGroupKey, policyProviderMap),
 		configRoot:       configvaluesroot.NewRoot(mspConfigHandler),
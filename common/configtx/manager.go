@@ -186,6 +186,42 @@ func (cm *configManager) ProposeConfigUpdate(configtx *cb.Envelope) (*cb.ConfigE
 	}, nil
 }
 
+// UpdatedConfig returns the config resulting from applying configtx, an Envelope of type
+// CONFIG_UPDATE, to the config of configEnv. The modification policies of the updated config
+// are not evaluated, so that the impact of an update may be reviewed before it is signed.
+func UpdatedConfig(configEnv *cb.ConfigEnvelope, initializer api.Initializer, configtx *cb.Envelope) (*cb.ConfigEnvelope, error) {
+	m, err := NewManagerImpl(configEnv, initializer, nil)
+	if err != nil {
+		return nil, err
+	}
+	cm := m.(*configManager)
+
+	configUpdateEnv, err := envelopeToConfigUpdate(configtx)
+	if err != nil {
+		return nil, err
+	}
+
+	configMap, err := cm.verifyUpdate(configUpdateEnv, false)
+	if err != nil {
+		return nil, err
+	}
+
+	channelGroup, err := configMapToConfig(configMap)
+	if err != nil {
+		return nil, fmt.Errorf("Could not turn configMap back to channelGroup: %s", err)
+	}
+
+	return &cb.ConfigEnvelope{
+		Config: &cb.Config{
+			Header: &cb.ChannelHeader{
+				ChannelId: cm.chainID,
+			},
+			Channel: channelGroup,
+		},
+		LastUpdate: configtx,
+	}, nil
+}
+
 func (cm *configManager) prepareApply(configEnv *cb.ConfigEnvelope) (map[string]comparable, *configResult, error) {
 	if configEnv == nil {
 		return nil, nil, fmt.Errorf("Attempted to apply config with nil envelope")
@@ -409,3 +409,26 @@ func TestMissingChainID(t *testing.T) {
 		t.Error("Should have errored creating the config manager because of the missing header")
 	}
 }
+
+// TestUpdatedConfig checks that the config resulting from an update is computed
+// even though the update does not satisfy the modification policies
+func TestUpdatedConfig(t *testing.T) {
+	initializer := defaultInitializer()
+	initializer.Resources.PolicyManagerVal.Policy.Err = fmt.Errorf("err")
+	configEnv := makeConfigEnvelope(defaultChain, makeConfigPair("foo", "foo", 0, []byte("foo")))
+
+	newConfig := makeConfigUpdateEnvelope(defaultChain, makeConfigPair("foo", "foo", 1, []byte("bar")))
+
+	updated, err := UpdatedConfig(configEnv, initializer, newConfig)
+	if err != nil {
+		t.Fatalf("Should not have errored computing the updated config: %s", err)
+	}
+	if string(updated.Config.Channel.Values["foo"].Value) != "bar" {
+		t.Errorf("Expected updated value bar, got %s", updated.Config.Channel.Values["foo"].Value)
+	}
+
+	newConfig = makeConfigUpdateEnvelope(defaultChain, makeConfigPair("foo", "foo", 2, []byte("bar")))
+	if _, err = UpdatedConfig(configEnv, initializer, newConfig); err == nil {
+		t.Error("Should have errored computing the updated config because of the sequence jump")
+	}
+}
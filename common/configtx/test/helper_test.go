@@ -17,10 +17,16 @@ limitations under the License.
 package test
 
 import (
+	"crypto/rand"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/hyperledger/fabric/bccsp/bls"
+	"github.com/hyperledger/fabric/common/cauthdsl"
+	"github.com/hyperledger/fabric/common/configtx"
+	"github.com/hyperledger/fabric/common/genesis"
+
 	logging "github.com/op/go-logging"
 )
 
@@ -55,3 +61,49 @@ func TestApplicationOrgTemplate(t *testing.T) {
 func TestCompositeTemplate(t *testing.T) {
 	_ = CompositeTemplate()
 }
+
+func TestBLSAggregatePolicyConfig(t *testing.T) {
+	data := []byte("block header")
+	var pubs, sigs [][]byte
+	for i := 0; i < 3; i++ {
+		priv, pub, err := bls.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatalf("Failed generating key [%s]", err)
+		}
+		sig, err := bls.Sign(priv, data)
+		if err != nil {
+			t.Fatalf("Failed signing [%s]", err)
+		}
+		pubs, sigs = append(pubs, pub), append(sigs, sig)
+	}
+
+	template := configtx.NewCompositeTemplate(CompositeTemplate(),
+		configtx.NewSimpleTemplate(cauthdsl.TemplateBLSAggregatePolicy("TwoOfThree", 2, pubs)))
+	block, err := genesis.NewFactoryImpl(template).Block("foo")
+	if err != nil {
+		t.Fatalf("Error making genesis block: %s", err)
+	}
+	configEnv, err := configtx.ConfigEnvelopeFromBlock(block)
+	if err != nil {
+		t.Fatalf("Error extracting config: %s", err)
+	}
+	cm, err := configtx.NewManagerImpl(configEnv, configtx.NewInitializer(), nil)
+	if err != nil {
+		t.Fatalf("Error loading config: %s", err)
+	}
+
+	policy, ok := cm.PolicyManager().GetPolicy("TwoOfThree")
+	if !ok {
+		t.Fatal("The BLS aggregate policy should have been configured")
+	}
+	aggregate, err := bls.AggregateSignatures(sigs[:2])
+	if err != nil {
+		t.Fatalf("Failed aggregating signatures [%s]", err)
+	}
+	if err := policy.Evaluate(cauthdsl.BLSAggregateSignatureSet(data, aggregate, pubs[:2])); err != nil {
+		t.Fatalf("2 out of 3 signers should satisfy the policy [%s]", err)
+	}
+	if err := policy.Evaluate(cauthdsl.BLSAggregateSignatureSet(data, sigs[2], pubs[2:])); err == nil {
+		t.Fatal("1 out of 3 signers should not satisfy the policy")
+	}
+}
@@ -27,6 +27,12 @@ import (
 // authorizeUpdate validates that all modified config has the corresponding modification policies satisfied by the signature set
 // it returns a map of the modified config
 func (cm *configManager) authorizeUpdate(configUpdateEnv *cb.ConfigUpdateEnvelope) (map[string]comparable, error) {
+	return cm.verifyUpdate(configUpdateEnv, true)
+}
+
+// verifyUpdate validates the config update as authorizeUpdate does, but only evaluates
+// the modification policies of the modified config if checkPolicies is set
+func (cm *configManager) verifyUpdate(configUpdateEnv *cb.ConfigUpdateEnvelope, checkPolicies bool) (map[string]comparable, error) {
 	if configUpdateEnv == nil {
 		return nil, fmt.Errorf("Cannot process nil ConfigUpdateEnvelope")
 	}
@@ -94,7 +100,7 @@ func (cm *configManager) authorizeUpdate(configUpdateEnv *cb.ConfigUpdateEnvelop
 
 			// Get the modification policy for this config item if one was previously specified
 			// or accept it if it is new, as the group policy will be evaluated for its inclusion
-			if ok {
+			if ok && checkPolicies {
 				policy, ok := cm.policyForItem(oldValue)
 				if !ok {
 					return nil, fmt.Errorf("Unexpected missing policy %s for item %s", oldValue.modPolicy(), key)
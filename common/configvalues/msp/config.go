@@ -80,6 +80,11 @@ func (bh *MSPConfigHandler) ProposeMSP(mspConfig *mspprotos.MSPConfig) (msp.MSP,
 
 	// set it up
 	err = mspInst.Setup(mspConfig)
+	if chainErr, ok := err.(*msp.CAChainError); ok {
+		// surface the typed error so that callers can tell
+		// which certificate of the config has been rejected
+		return nil, chainErr
+	}
 	if err != nil {
 		return nil, fmt.Errorf("Setting up the MSP manager failed, err %s", err)
 	}
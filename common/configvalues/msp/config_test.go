@@ -17,8 +17,17 @@ limitations under the License.
 package msp
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
 	"testing"
+	"time"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/msp"
 	mspprotos "github.com/hyperledger/fabric/protos/msp"
 	"github.com/stretchr/testify/assert"
@@ -53,3 +62,37 @@ func TestMSPConfigManager(t *testing.T) {
 	_, err = mspCH.ProposeMSP(&mspprotos.MSPConfig{Config: []byte("BARF!")})
 	assert.Error(t, err)
 }
+
+func TestProposeMSPCAChainError(t *testing.T) {
+	conf, err := msp.GetLocalMspConfig("../../../msp/sampleconfig/", nil, "DEFAULT")
+	assert.NoError(t, err)
+
+	// the root CA becomes an intermediate CA that
+	// does not chain up to the new root CA
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "root"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		SubjectKeyId:          []byte{1, 2, 3, 4},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+
+	fmspconf := &mspprotos.FabricMSPConfig{}
+	assert.NoError(t, proto.Unmarshal(conf.Config, fmspconf))
+	fmspconf.IntermediateCerts = fmspconf.RootCerts
+	fmspconf.RootCerts = [][]byte{pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+	conf.Config, err = proto.Marshal(fmspconf)
+	assert.NoError(t, err)
+
+	mspCH := &MSPConfigHandler{}
+	mspCH.BeginConfig()
+	_, err = mspCH.ProposeMSP(conf)
+	assert.IsType(t, &msp.CAChainError{}, err)
+}
@@ -53,6 +53,11 @@ func (m *Manager) PolicyNames() []string {
 	panic("Unimplimented")
 }
 
+// Snapshot panics
+func (m *Manager) Snapshot() policies.Snapshot {
+	panic("Unimplimented")
+}
+
 // BasePath returns BasePathVal
 func (m *Manager) BasePath() string {
 	return m.BasePathVal
@@ -95,3 +100,7 @@ func (m *PolicyManagerMgmt) BasePath() string {
 func (m *PolicyManagerMgmt) PolicyNames() []string {
 	panic("implement me")
 }
+
+func (m *PolicyManagerMgmt) Snapshot() policies.Snapshot {
+	panic("implement me")
+}
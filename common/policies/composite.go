@@ -0,0 +1,174 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policies
+
+import (
+	"fmt"
+
+	cb "github.com/hyperledger/fabric/protos/common"
+
+	"github.com/golang/protobuf/proto"
+)
+
+type compositePolicy struct {
+	conf    *cb.CompositePolicy
+	manager *ManagerImpl
+}
+
+// newCompositePolicy creates a new composite policy based on the policy
+// bytes, whose sub-policies are resolved relative to manager
+func newCompositePolicy(data []byte, manager *ManagerImpl) (*compositePolicy, error) {
+	cp := &cb.CompositePolicy{}
+	if err := proto.Unmarshal(data, cp); err != nil {
+		return nil, fmt.Errorf("Error unmarshaling to CompositePolicy: %s", err)
+	}
+	if err := validateCompositeRule(cp); err != nil {
+		return nil, err
+	}
+
+	return &compositePolicy{
+		conf:    cp,
+		manager: manager,
+	}, nil
+}
+
+// validateCompositeRule checks that rule, and the rules nested in it, are well formed
+func validateCompositeRule(rule *cb.CompositePolicy) error {
+	switch t := rule.Type.(type) {
+	case *cb.CompositePolicy_SubPolicy:
+		if t.SubPolicy == "" {
+			return fmt.Errorf("Composite policy references a sub-policy with no name")
+		}
+	case *cb.CompositePolicy_NOutOf_:
+		if t.NOutOf == nil {
+			return fmt.Errorf("Composite policy has a nil NOutOf rule")
+		}
+		if t.NOutOf.N < 0 || int(t.NOutOf.N) > len(t.NOutOf.Policies) {
+			return fmt.Errorf("Composite policy requires %d out of %d sub-policies", t.NOutOf.N, len(t.NOutOf.Policies))
+		}
+		for _, subRule := range t.NOutOf.Policies {
+			if err := validateCompositeRule(subRule); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("Composite policy has an unknown rule type: %T", t)
+	}
+	return nil
+}
+
+// Evaluate takes a set of SignedData and evaluates whether this set of signatures satisfies the policy
+func (cp *compositePolicy) Evaluate(signatureSet []*cb.SignedData) error {
+	return cp.evaluate(signatureSet, nil)
+}
+
+// evaluate evaluates this policy as a sub-policy of the composite
+// policies of path, which is used to detect reference cycles
+func (cp *compositePolicy) evaluate(signatureSet []*cb.SignedData, path []*compositePolicy) error {
+	for _, policy := range path {
+		if policy == cp {
+			return fmt.Errorf("Composite policy references itself")
+		}
+	}
+	return cp.evaluateRule(cp.conf, signatureSet, append(path, cp))
+}
+
+func (cp *compositePolicy) evaluateRule(rule *cb.CompositePolicy, signatureSet []*cb.SignedData, path []*compositePolicy) error {
+	switch t := rule.Type.(type) {
+	case *cb.CompositePolicy_SubPolicy:
+		// Sub-policies are resolved at evaluation time, as absolute
+		// paths may reference groups committed after this policy
+		policy, ok := cp.manager.GetPolicy(t.SubPolicy)
+		if !ok {
+			return fmt.Errorf("Composite policy references unknown sub-policy %s", t.SubPolicy)
+		}
+		if subPolicy, ok := policy.(*compositePolicy); ok {
+			return subPolicy.evaluate(signatureSet, path)
+		}
+		return policy.Evaluate(signatureSet)
+	case *cb.CompositePolicy_NOutOf_:
+		remaining := t.NOutOf.N
+		for _, subRule := range t.NOutOf.Policies {
+			if remaining == 0 {
+				break
+			}
+			if cp.evaluateRule(subRule, signatureSet, path) == nil {
+				remaining--
+			}
+		}
+		if remaining > 0 {
+			return fmt.Errorf("Failed to reach composite threshold of %d sub-policies, required %d remaining", t.NOutOf.N, remaining)
+		}
+		return nil
+	}
+	return fmt.Errorf("Composite policy has an unknown rule type: %T", rule.Type)
+}
+
+// EvaluateWithTrace evaluates the signature set as Evaluate does, but
+// evaluates all the sub-policies so that the trace accounts for each
+func (cp *compositePolicy) EvaluateWithTrace(signatureSet []*cb.SignedData) (*Trace, error) {
+	return cp.evaluateWithTrace(signatureSet, nil)
+}
+
+func (cp *compositePolicy) evaluateWithTrace(signatureSet []*cb.SignedData, path []*compositePolicy) (*Trace, error) {
+	for _, policy := range path {
+		if policy == cp {
+			err := fmt.Errorf("Composite policy references itself")
+			return &Trace{Rule: "Composite policy", Reason: err.Error()}, err
+		}
+	}
+	return cp.traceRule(cp.conf, signatureSet, append(path, cp))
+}
+
+func (cp *compositePolicy) traceRule(rule *cb.CompositePolicy, signatureSet []*cb.SignedData, path []*compositePolicy) (*Trace, error) {
+	switch t := rule.Type.(type) {
+	case *cb.CompositePolicy_SubPolicy:
+		policy, ok := cp.manager.GetPolicy(t.SubPolicy)
+		if !ok {
+			err := fmt.Errorf("Composite policy references unknown sub-policy %s", t.SubPolicy)
+			return &Trace{Name: t.SubPolicy, Rule: "Unknown policy", Reason: err.Error()}, err
+		}
+		var trace *Trace
+		var err error
+		if subPolicy, ok := policy.(*compositePolicy); ok {
+			trace, err = subPolicy.evaluateWithTrace(signatureSet, path)
+		} else {
+			trace, err = EvaluateWithTrace(policy, signatureSet)
+		}
+		trace.Name = t.SubPolicy
+		return trace, err
+	case *cb.CompositePolicy_NOutOf_:
+		trace := &Trace{Rule: fmt.Sprintf("%d out of %d", t.NOutOf.N, len(t.NOutOf.Policies))}
+		satisfied := int32(0)
+		for _, subRule := range t.NOutOf.Policies {
+			subTrace, err := cp.traceRule(subRule, signatureSet, path)
+			if err == nil {
+				satisfied++
+			}
+			trace.SubTraces = append(trace.SubTraces, subTrace)
+		}
+		if satisfied < t.NOutOf.N {
+			err := fmt.Errorf("Failed to reach composite threshold of %d sub-policies, required %d remaining", t.NOutOf.N, t.NOutOf.N-satisfied)
+			trace.Reason = err.Error()
+			return trace, err
+		}
+		trace.Satisfied = true
+		return trace, nil
+	}
+	err := fmt.Errorf("Composite policy has an unknown rule type: %T", rule.Type)
+	return &Trace{Rule: "Composite policy", Reason: err.Error()}, err
+}
@@ -0,0 +1,121 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policies
+
+import (
+	"testing"
+
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// outcomeProvider creates policies accepting all signature
+// sets if their bytes are "accept", and rejecting them otherwise
+type outcomeProvider struct{}
+
+func (op outcomeProvider) NewPolicy(data []byte) (Policy, error) {
+	if string(data) == "accept" {
+		return acceptPolicy{}, nil
+	}
+	return rejectPolicy(data), nil
+}
+
+func outcomePolicy(outcome string) *cb.ConfigPolicy {
+	return &cb.ConfigPolicy{Policy: &cb.Policy{Type: mockType, Policy: []byte(outcome)}}
+}
+
+func compositePolicyConfig(rule *cb.CompositePolicy) *cb.ConfigPolicy {
+	return &cb.ConfigPolicy{Policy: &cb.Policy{Type: int32(cb.Policy_COMPOSITE), Policy: utils.MarshalOrPanic(rule)}}
+}
+
+func TestCompositePolicy(t *testing.T) {
+	m := NewManagerImpl(ChannelPrefix, map[int32]Provider{mockType: outcomeProvider{}})
+	groups, err := m.BeginPolicyProposals([]string{ApplicationPrefix, OrdererPrefix})
+	assert.NoError(t, err)
+	application, orderer := groups[0], groups[1]
+	orgs, err := application.BeginPolicyProposals([]string{"OrgA", "OrgB"})
+	assert.NoError(t, err)
+	for _, org := range orgs {
+		_, err = org.BeginPolicyProposals([]string{})
+		assert.NoError(t, err)
+	}
+	_, err = orderer.BeginPolicyProposals([]string{})
+	assert.NoError(t, err)
+
+	assert.NoError(t, orgs[0].ProposePolicy("Admins", outcomePolicy("accept")))
+	assert.NoError(t, orgs[1].ProposePolicy("Peers", outcomePolicy("reject")))
+	assert.NoError(t, orderer.ProposePolicy("Admins", outcomePolicy("accept")))
+
+	threeSubPolicies := []*cb.CompositePolicy{
+		CompositeSubPolicy("OrgA/Admins"),
+		CompositeSubPolicy("OrgB/Peers"),
+		CompositeSubPolicy("/Channel/Orderer/Admins"),
+	}
+	proposals := map[string]*cb.CompositePolicy{
+		"TwoOfThree": CompositeNOutOf(2, threeSubPolicies...),
+		"AllOfThree": CompositeNOutOf(3, threeSubPolicies...),
+		"Nested": CompositeNOutOf(2,
+			CompositeSubPolicy("OrgB/Peers"),
+			CompositeNOutOf(1, CompositeSubPolicy("OrgB/Peers"), CompositeSubPolicy("OrgA/Admins")),
+			CompositeSubPolicy("TwoOfThree")),
+		"Unknown": CompositeSubPolicy("OrgC/Admins"),
+		"CycleA":  CompositeNOutOf(1, CompositeSubPolicy("CycleB")),
+		"CycleB":  CompositeNOutOf(1, CompositeSubPolicy("/Channel/Application/CycleA")),
+	}
+	for name, rule := range proposals {
+		assert.NoError(t, application.ProposePolicy(name, compositePolicyConfig(rule)))
+	}
+	assert.NoError(t, m.ProposePolicy("Relative", compositePolicyConfig(CompositeSubPolicy("Application/OrgA/Admins"))))
+
+	// Malformed composite policies
+	for _, rule := range []*cb.CompositePolicy{
+		CompositeNOutOf(3, CompositeSubPolicy("OrgA/Admins")),
+		CompositeNOutOf(-1),
+		CompositeNOutOf(1, CompositeSubPolicy("")),
+		{},
+	} {
+		assert.Error(t, application.ProposePolicy("Malformed", compositePolicyConfig(rule)))
+	}
+	assert.Error(t, application.ProposePolicy("Malformed", &cb.ConfigPolicy{Policy: &cb.Policy{Type: int32(cb.Policy_COMPOSITE), Policy: []byte("GARBAGE")}}))
+
+	for _, org := range orgs {
+		org.CommitProposals()
+	}
+	application.CommitProposals()
+	orderer.CommitProposals()
+	m.CommitProposals()
+
+	evaluate := func(name string) error {
+		policy, ok := m.GetPolicy(name)
+		assert.True(t, ok, "Should have found policy %s", name)
+		return policy.Evaluate(nil)
+	}
+	assert.NoError(t, evaluate("/Channel/Application/TwoOfThree"))
+	assert.Error(t, evaluate("/Channel/Application/AllOfThree"))
+	assert.NoError(t, evaluate("/Channel/Application/Nested"))
+	assert.Error(t, evaluate("/Channel/Application/Unknown"))
+	assert.Error(t, evaluate("/Channel/Application/CycleA"))
+	assert.NoError(t, evaluate("/Channel/Relative"))
+}
+
+func TestCompositePolicyProvider(t *testing.T) {
+	assert.Panics(t, func() {
+		NewManagerImpl(ChannelPrefix, map[int32]Provider{int32(cb.Policy_COMPOSITE): outcomeProvider{}})
+	})
+}
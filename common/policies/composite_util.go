@@ -0,0 +1,59 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policies
+
+import (
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+)
+
+// CompositeSubPolicy returns a composite policy rule satisfied by the policy at path
+func CompositeSubPolicy(path string) *cb.CompositePolicy {
+	return &cb.CompositePolicy{
+		Type: &cb.CompositePolicy_SubPolicy{SubPolicy: path},
+	}
+}
+
+// CompositeNOutOf returns a composite policy rule requiring n of rules to be satisfied
+func CompositeNOutOf(n int32, rules ...*cb.CompositePolicy) *cb.CompositePolicy {
+	return &cb.CompositePolicy{
+		Type: &cb.CompositePolicy_NOutOf_{
+			NOutOf: &cb.CompositePolicy_NOutOf{
+				N:        n,
+				Policies: rules,
+			},
+		},
+	}
+}
+
+// TemplateCompositePolicy creates a composite policy at the specified path with the given policyName and rule
+func TemplateCompositePolicy(path []string, policyName string, rule *cb.CompositePolicy) *cb.ConfigGroup {
+	root := cb.NewConfigGroup()
+	group := root
+	for _, element := range path {
+		group.Groups[element] = cb.NewConfigGroup()
+		group = group.Groups[element]
+	}
+
+	group.Policies[policyName] = &cb.ConfigPolicy{
+		Policy: &cb.Policy{
+			Type:   int32(cb.Policy_COMPOSITE),
+			Policy: utils.MarshalOrPanic(rule),
+		},
+	}
+	return root
+}
@@ -28,6 +28,9 @@ type implicitMetaPolicy struct {
 	conf        *cb.ImplicitMetaPolicy
 	threshold   int
 	subPolicies []Policy
+
+	// subPolicyNames are the names of subPolicies, relative to the group of this policy
+	subPolicyNames []string
 }
 
 // NewPolicy creates a new policy based on the policy bytes
@@ -44,9 +47,11 @@ func newImplicitMetaPolicy(data []byte) (*implicitMetaPolicy, error) {
 
 func (imp *implicitMetaPolicy) initialize(config *policyConfig) {
 	imp.subPolicies = make([]Policy, len(config.managers))
+	imp.subPolicyNames = make([]string, len(config.managers))
 	i := 0
-	for _, manager := range config.managers {
+	for name, manager := range config.managers {
 		imp.subPolicies[i], _ = manager.GetPolicy(imp.conf.SubPolicy)
+		imp.subPolicyNames[i] = name + PathSeparator + imp.conf.SubPolicy
 		i++
 	}
 
@@ -81,3 +86,43 @@ func (imp *implicitMetaPolicy) Evaluate(signatureSet []*cb.SignedData) error {
 	}
 	return fmt.Errorf("Failed to reach implicit threshold of %d sub-policies, required %d remaining", imp.threshold, remaining)
 }
+
+// SinglePrincipal returns the principal of the only sub-policy, if the
+// threshold of this policy is one and that sub-policy reduces to a single principal
+func (imp *implicitMetaPolicy) SinglePrincipal() (*cb.MSPPrincipal, bool) {
+	if imp.threshold != 1 || len(imp.subPolicies) != 1 {
+		return nil, false
+	}
+
+	subPolicy, ok := imp.subPolicies[0].(SinglePrincipalPolicy)
+	if !ok {
+		return nil, false
+	}
+
+	return subPolicy.SinglePrincipal()
+}
+
+// EvaluateWithTrace evaluates the signature set as Evaluate does, but
+// evaluates all the sub-policies so that the trace accounts for each
+func (imp *implicitMetaPolicy) EvaluateWithTrace(signatureSet []*cb.SignedData) (*Trace, error) {
+	trace := &Trace{
+		Rule: fmt.Sprintf("%s of the %s sub-policies, %d out of %d", imp.conf.Rule, imp.conf.SubPolicy, imp.threshold, len(imp.subPolicies)),
+	}
+	satisfied := 0
+	for i, policy := range imp.subPolicies {
+		subTrace, err := EvaluateWithTrace(policy, signatureSet)
+		if err == nil {
+			satisfied++
+		}
+		subTrace.Name = imp.subPolicyNames[i]
+		trace.SubTraces = append(trace.SubTraces, subTrace)
+	}
+
+	if satisfied < imp.threshold {
+		err := fmt.Errorf("Failed to reach implicit threshold of %d sub-policies, required %d remaining", imp.threshold, imp.threshold-satisfied)
+		trace.Reason = err.Error()
+		return trace, err
+	}
+	trace.Satisfied = true
+	return trace, nil
+}
@@ -99,3 +99,44 @@ func TestImplicitMetaMajority(t *testing.T) {
 	assert.Error(t, runPolicyTest(cb.ImplicitMetaPolicy_MAJORITY, 10, 0))
 	assert.NoError(t, runPolicyTest(cb.ImplicitMetaPolicy_MAJORITY, 0, 0))
 }
+
+type singlePrincipalPolicy struct {
+	acceptPolicy
+}
+
+func (sp singlePrincipalPolicy) SinglePrincipal() (*cb.MSPPrincipal, bool) {
+	return &cb.MSPPrincipal{Principal: []byte("SampleOrg")}, true
+}
+
+func TestImplicitMetaSinglePrincipal(t *testing.T) {
+	newPolicy := func(rule cb.ImplicitMetaPolicy_Rule, subPolicies ...Policy) *implicitMetaPolicy {
+		managers := make(map[string]*ManagerImpl)
+		for i, subPolicy := range subPolicies {
+			managers[fmt.Sprintf("%d", i)] = &ManagerImpl{
+				config: &policyConfig{
+					policies: map[string]Policy{TestPolicyName: subPolicy},
+				},
+			}
+		}
+		imp, err := newImplicitMetaPolicy(utils.MarshalOrPanic(&cb.ImplicitMetaPolicy{
+			Rule:      rule,
+			SubPolicy: TestPolicyName,
+		}))
+		assert.NoError(t, err)
+		imp.initialize(&policyConfig{managers: managers})
+		return imp
+	}
+
+	principal, ok := newPolicy(cb.ImplicitMetaPolicy_ANY, singlePrincipalPolicy{}).SinglePrincipal()
+	assert.True(t, ok)
+	assert.Equal(t, []byte("SampleOrg"), principal.Principal)
+	_, ok = newPolicy(cb.ImplicitMetaPolicy_MAJORITY, singlePrincipalPolicy{}).SinglePrincipal()
+	assert.True(t, ok)
+
+	_, ok = newPolicy(cb.ImplicitMetaPolicy_ANY, singlePrincipalPolicy{}, singlePrincipalPolicy{}).SinglePrincipal()
+	assert.False(t, ok)
+	_, ok = newPolicy(cb.ImplicitMetaPolicy_ANY, acceptPolicy{}).SinglePrincipal()
+	assert.False(t, ok)
+	_, ok = newPolicy(cb.ImplicitMetaPolicy_ANY).SinglePrincipal()
+	assert.False(t, ok)
+}
@@ -0,0 +1,119 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policies
+
+import (
+	"fmt"
+	"plugin"
+	"sync"
+
+	"github.com/hyperledger/fabric/msp"
+)
+
+// PluginFactorySymbol is the name of the function a policy provider
+// plugin must export. Its signature must be that of PluginFactory:
+//
+//	func NewPolicyProvider(deserializer msp.IdentityDeserializer) (policies.Provider, error)
+const PluginFactorySymbol = "NewPolicyProvider"
+
+// MinPluginPolicyType is the lowest policy type plugins may provide,
+// the types below it being reserved for the policy types of Fabric
+const MinPluginPolicyType = 1000
+
+// PluginFactory creates the Provider of a policy type, given the
+// deserializer of the identities of the MSPs of the channel
+type PluginFactory func(deserializer msp.IdentityDeserializer) (Provider, error)
+
+var pluginFactories = struct {
+	sync.RWMutex
+	factories map[int32]PluginFactory
+}{factories: make(map[int32]PluginFactory)}
+
+// RegisterPluginFactory registers factory as the creator of the Provider
+// of policyType for the channels created afterwards. policyType must not
+// be lower than MinPluginPolicyType nor already registered.
+func RegisterPluginFactory(policyType int32, factory PluginFactory) error {
+	if policyType < MinPluginPolicyType {
+		return fmt.Errorf("Policy type %d is reserved, plugins must provide types from %d", policyType, MinPluginPolicyType)
+	}
+	if factory == nil {
+		return fmt.Errorf("No factory provided for policy type %d", policyType)
+	}
+
+	pluginFactories.Lock()
+	defer pluginFactories.Unlock()
+	if _, ok := pluginFactories.factories[policyType]; ok {
+		return fmt.Errorf("Policy type %d is already provided", policyType)
+	}
+	pluginFactories.factories[policyType] = factory
+	return nil
+}
+
+// LoadPlugin loads the Go plugin at path and registers the function it
+// exports as PluginFactorySymbol as the factory of the Provider of
+// policyType. This allows adding policy types, such as threshold
+// signature policies, without forking the policies package. The plugin
+// must be built against the same sources as the peer or orderer.
+func LoadPlugin(policyType int32, path string) error {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return fmt.Errorf("Failed opening policy provider plugin %s: %s", path, err)
+	}
+
+	sym, err := p.Lookup(PluginFactorySymbol)
+	if err != nil {
+		return fmt.Errorf("Failed looking up %s in policy provider plugin %s: %s", PluginFactorySymbol, path, err)
+	}
+
+	factory, err := getPluginFactory(sym)
+	if err != nil {
+		return fmt.Errorf("Invalid policy provider plugin %s: %s", path, err)
+	}
+
+	return RegisterPluginFactory(policyType, factory)
+}
+
+// PluginProviders creates the Providers of the registered policy types,
+// given the deserializer of the identities of the MSPs of a channel
+func PluginProviders(deserializer msp.IdentityDeserializer) (map[int32]Provider, error) {
+	pluginFactories.RLock()
+	defer pluginFactories.RUnlock()
+
+	providers := make(map[int32]Provider)
+	for policyType, factory := range pluginFactories.factories {
+		provider, err := factory(deserializer)
+		if err != nil {
+			return nil, fmt.Errorf("Failed creating the provider of policy type %d: %s", policyType, err)
+		}
+		if provider == nil {
+			return nil, fmt.Errorf("No provider created for policy type %d", policyType)
+		}
+		providers[policyType] = provider
+	}
+	return providers, nil
+}
+
+// getPluginFactory returns the PluginFactory sym is
+func getPluginFactory(sym plugin.Symbol) (PluginFactory, error) {
+	switch factory := sym.(type) {
+	case func(msp.IdentityDeserializer) (Provider, error):
+		return factory, nil
+	case *PluginFactory:
+		return *factory, nil
+	}
+	return nil, fmt.Errorf("%s is of type %T, expected %T", PluginFactorySymbol, sym, PluginFactory(nil))
+}
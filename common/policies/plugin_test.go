@@ -0,0 +1,86 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policies
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hyperledger/fabric/msp"
+	cb "github.com/hyperledger/fabric/protos/common"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newPluginProvider(deserializer msp.IdentityDeserializer) (Provider, error) {
+	return outcomeProvider{}, nil
+}
+
+func TestRegisterPluginFactory(t *testing.T) {
+	assert.Error(t, RegisterPluginFactory(int32(0), newPluginProvider))
+	assert.Error(t, RegisterPluginFactory(MinPluginPolicyType-1, newPluginProvider))
+	assert.Error(t, RegisterPluginFactory(MinPluginPolicyType, nil))
+
+	assert.NoError(t, RegisterPluginFactory(MinPluginPolicyType+1, newPluginProvider))
+	assert.Error(t, RegisterPluginFactory(MinPluginPolicyType+1, newPluginProvider))
+
+	providers, err := PluginProviders(nil)
+	assert.NoError(t, err)
+	assert.IsType(t, outcomeProvider{}, providers[MinPluginPolicyType+1])
+
+	// Plugin policy types can be proposed
+	m := NewManagerImpl(ChannelPrefix, providers)
+	_, err = m.BeginPolicyProposals([]string{})
+	assert.NoError(t, err)
+	assert.NoError(t, m.ProposePolicy("Plugin", &cb.ConfigPolicy{Policy: &cb.Policy{Type: MinPluginPolicyType + 1, Policy: []byte("accept")}}))
+	m.CommitProposals()
+	policy, ok := m.GetPolicy("Plugin")
+	assert.True(t, ok)
+	assert.NoError(t, policy.Evaluate(nil))
+
+	// Failing factories
+	assert.NoError(t, RegisterPluginFactory(MinPluginPolicyType+2, func(msp.IdentityDeserializer) (Provider, error) {
+		return nil, errors.New("failure")
+	}))
+	_, err = PluginProviders(nil)
+	assert.Error(t, err)
+	pluginFactories.Lock()
+	delete(pluginFactories.factories, MinPluginPolicyType+2)
+	pluginFactories.Unlock()
+}
+
+func TestLoadPlugin(t *testing.T) {
+	assert.Error(t, LoadPlugin(MinPluginPolicyType, "/nonexistent/policy.so"))
+
+	// Exported functions and function variables
+	factory, err := getPluginFactory(newPluginProvider)
+	assert.NoError(t, err)
+	provider, err := factory(nil)
+	assert.NoError(t, err)
+	assert.NotNil(t, provider)
+
+	variable := PluginFactory(newPluginProvider)
+	factory, err = getPluginFactory(&variable)
+	assert.NoError(t, err)
+	assert.NotNil(t, factory)
+
+	// Symbols of other types
+	_, err = getPluginFactory(func() Provider { return nil })
+	assert.Error(t, err)
+	_, err = getPluginFactory(outcomeProvider{})
+	assert.Error(t, err)
+}
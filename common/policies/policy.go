@@ -47,6 +47,10 @@ const (
 	// ChannelApplicationAdmins is the label for the channel's application admin policy
 	ChannelApplicationAdmins = PathSeparator + ChannelPrefix + PathSeparator + ApplicationPrefix + PathSeparator + "Admins"
 
+	// ChannelApplicationGossipVerification is the label for the channel's policy
+	// gossip messages are verified against, in place of the application readers policy
+	ChannelApplicationGossipVerification = PathSeparator + ChannelPrefix + PathSeparator + ApplicationPrefix + PathSeparator + "GossipVerification"
+
 	// BlockValidation is the label for the policy which should validate the block signatures for the channel
 	BlockValidation = PathSeparator + ChannelPrefix + PathSeparator + OrdererPrefix + PathSeparator + "BlockValidation"
 )
@@ -59,6 +63,17 @@ type Policy interface {
 	Evaluate(signatureSet []*cb.SignedData) error
 }
 
+// SinglePrincipalPolicy is implemented by policies which can tell
+// whether they reduce to one signature of a single principal
+type SinglePrincipalPolicy interface {
+	Policy
+
+	// SinglePrincipal returns a principal and true if this policy is satisfied
+	// if and only if the signature set contains a valid signature of an identity
+	// satisfying that principal. Otherwise, it returns false.
+	SinglePrincipal() (*cb.MSPPrincipal, bool)
+}
+
 // Manager is a read only subset of the policy ManagerImpl
 type Manager interface {
 	// GetPolicy returns a policy and true if it was the policy requested, or false if it is the default policy
@@ -72,6 +87,9 @@ type Manager interface {
 
 	// Policies returns all policy names defined in the manager
 	PolicyNames() []string
+
+	// Snapshot returns the policies of the manager and of its sub-managers
+	Snapshot() Snapshot
 }
 
 // Proposer is the interface used by the configtx manager for policy management
@@ -102,6 +120,9 @@ type policyConfig struct {
 	policies map[string]Policy
 	managers map[string]*ManagerImpl
 	imps     []*implicitMetaPolicy
+
+	// sources are the config of the policies proposed to this manager
+	sources map[string]*cb.ConfigPolicy
 }
 
 // ManagerImpl is an implementation of Manager and configtx.ConfigHandler
@@ -121,6 +142,10 @@ func NewManagerImpl(basePath string, providers map[int32]Provider) *ManagerImpl
 	if ok {
 		logger.Panicf("ImplicitMetaPolicy type must be provider by the policy manager")
 	}
+	_, ok = providers[int32(cb.Policy_COMPOSITE)]
+	if ok {
+		logger.Panicf("CompositePolicy type must be provider by the policy manager")
+	}
 
 	return &ManagerImpl{
 		basePath:  basePath,
@@ -129,6 +154,7 @@ func NewManagerImpl(basePath string, providers map[int32]Provider) *ManagerImpl
 		config: &policyConfig{
 			policies: make(map[string]Policy),
 			managers: make(map[string]*ManagerImpl),
+			sources:  make(map[string]*cb.ConfigPolicy),
 		},
 	}
 }
@@ -213,6 +239,7 @@ func (pm *ManagerImpl) BeginPolicyProposals(groups []string) ([]Proposer, error)
 	pm.pendingConfig = &policyConfig{
 		policies: make(map[string]Policy),
 		managers: make(map[string]*ManagerImpl),
+		sources:  make(map[string]*cb.ConfigPolicy),
 	}
 
 	managers := make([]Proposer, len(groups))
@@ -294,14 +321,21 @@ func (pm *ManagerImpl) ProposePolicy(key string, configPolicy *cb.ConfigPolicy)
 
 	var cPolicy Policy
 
-	if policy.Type == int32(cb.Policy_IMPLICIT_META) {
+	switch policy.Type {
+	case int32(cb.Policy_IMPLICIT_META):
 		imp, err := newImplicitMetaPolicy(policy.Policy)
 		if err != nil {
 			return err
 		}
 		pm.pendingConfig.imps = append(pm.pendingConfig.imps, imp)
 		cPolicy = imp
-	} else {
+	case int32(cb.Policy_COMPOSITE):
+		cp, err := newCompositePolicy(policy.Policy, pm)
+		if err != nil {
+			return err
+		}
+		cPolicy = cp
+	default:
 		provider, ok := pm.providers[int32(policy.Type)]
 		if !ok {
 			return fmt.Errorf("Unknown policy type: %v", policy.Type)
@@ -315,6 +349,7 @@ func (pm *ManagerImpl) ProposePolicy(key string, configPolicy *cb.ConfigPolicy)
 	}
 
 	pm.pendingConfig.policies[key] = cPolicy
+	pm.pendingConfig.sources[key] = configPolicy
 
 	logger.Debugf("Proposed new policy %s for %s", key, pm.basePath)
 	return nil
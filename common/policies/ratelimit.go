@@ -0,0 +1,133 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policies
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	cb "github.com/hyperledger/fabric/protos/common"
+)
+
+// RateLimiter tracks the successful evaluations of the policies it
+// limits, for each identity. A RateLimiter may be shared by several
+// policies, such as the successive versions of a policy across config
+// updates, so that the limit applies to all of them together.
+type RateLimiter struct {
+	limit    int
+	interval time.Duration
+	now      func() time.Time
+
+	lock sync.Mutex
+	// authorizations maps identities to the times of their
+	// successful evaluations within the last interval, oldest first
+	authorizations map[string][]time.Time
+	lastSweep      time.Time
+}
+
+// NewRateLimiter returns a RateLimiter allowing each identity at most
+// limit successful evaluations of the limited policies per interval
+func NewRateLimiter(limit int, interval time.Duration) *RateLimiter {
+	return &RateLimiter{
+		limit:          limit,
+		interval:       interval,
+		now:            time.Now,
+		authorizations: make(map[string][]time.Time),
+	}
+}
+
+// NewRateLimitedPolicy returns a policy which evaluates signature sets as
+// policy does, but rejects, without evaluating policy, the signature sets
+// of identities which exhausted their limit in limiter
+func NewRateLimitedPolicy(policy Policy, limiter *RateLimiter) Policy {
+	return &rateLimitedPolicy{policy: policy, limiter: limiter}
+}
+
+type rateLimitedPolicy struct {
+	policy  Policy
+	limiter *RateLimiter
+}
+
+// Evaluate takes a set of SignedData and evaluates whether this set of signatures satisfies the policy
+func (p *rateLimitedPolicy) Evaluate(signatureSet []*cb.SignedData) error {
+	if err := p.limiter.check(signatureSet); err != nil {
+		return err
+	}
+	if err := p.policy.Evaluate(signatureSet); err != nil {
+		return err
+	}
+	p.limiter.record(signatureSet)
+	return nil
+}
+
+// check returns an error if an identity of signatureSet exhausted its limit
+func (rl *RateLimiter) check(signatureSet []*cb.SignedData) error {
+	rl.lock.Lock()
+	defer rl.lock.Unlock()
+
+	now := rl.now()
+	rl.sweep(now)
+	for _, sd := range signatureSet {
+		if len(rl.recent(string(sd.Identity), now)) >= rl.limit {
+			return fmt.Errorf("Identity exceeded the limit of %d authorizations per %s", rl.limit, rl.interval)
+		}
+	}
+	return nil
+}
+
+// record records a successful evaluation for each identity of signatureSet
+func (rl *RateLimiter) record(signatureSet []*cb.SignedData) {
+	rl.lock.Lock()
+	defer rl.lock.Unlock()
+
+	now := rl.now()
+	recorded := make(map[string]bool)
+	for _, sd := range signatureSet {
+		identity := string(sd.Identity)
+		if recorded[identity] {
+			continue
+		}
+		recorded[identity] = true
+		rl.authorizations[identity] = append(rl.recent(identity, now), now)
+	}
+}
+
+// recent returns the evaluations of identity within the interval preceding now
+func (rl *RateLimiter) recent(identity string, now time.Time) []time.Time {
+	times := rl.authorizations[identity]
+	i := 0
+	for i < len(times) && !times[i].After(now.Add(-rl.interval)) {
+		i++
+	}
+	return times[i:]
+}
+
+// sweep forgets the identities without evaluations within the
+// last interval, at most once per interval
+func (rl *RateLimiter) sweep(now time.Time) {
+	if now.Sub(rl.lastSweep) < rl.interval {
+		return
+	}
+	rl.lastSweep = now
+
+	for identity := range rl.authorizations {
+		if len(rl.recent(identity, now)) == 0 {
+			delete(rl.authorizations, identity)
+		}
+	}
+}
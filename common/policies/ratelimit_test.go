@@ -0,0 +1,94 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policies
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	cb "github.com/hyperledger/fabric/protos/common"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type countingPolicy struct {
+	err         error
+	evaluations int
+}
+
+func (p *countingPolicy) Evaluate(signatureSet []*cb.SignedData) error {
+	p.evaluations++
+	return p.err
+}
+
+func signedBy(identities ...string) []*cb.SignedData {
+	signatureSet := make([]*cb.SignedData, len(identities))
+	for i, identity := range identities {
+		signatureSet[i] = &cb.SignedData{Identity: []byte(identity)}
+	}
+	return signatureSet
+}
+
+func TestRateLimitedPolicy(t *testing.T) {
+	now := time.Unix(0, 0)
+	limiter := NewRateLimiter(2, time.Minute)
+	limiter.now = func() time.Time { return now }
+
+	policy := &countingPolicy{}
+	limited := NewRateLimitedPolicy(policy, limiter)
+
+	assert.NoError(t, limited.Evaluate(signedBy("alice")))
+	now = now.Add(30 * time.Second)
+	assert.NoError(t, limited.Evaluate(signedBy("alice")))
+	assert.Error(t, limited.Evaluate(signedBy("alice")), "Should have exceeded the limit")
+	assert.Equal(t, 2, policy.evaluations, "Should not have evaluated the policy past the limit")
+
+	// Other identities have their own limit
+	assert.NoError(t, limited.Evaluate(signedBy("bob")))
+	assert.Error(t, limited.Evaluate(signedBy("bob", "alice")))
+
+	// The limit is shared by the policies of the limiter
+	assert.Error(t, NewRateLimitedPolicy(&countingPolicy{}, limiter).Evaluate(signedBy("alice")))
+
+	// The first evaluation leaves the interval
+	now = now.Add(31 * time.Second)
+	assert.NoError(t, limited.Evaluate(signedBy("alice")))
+	assert.Error(t, limited.Evaluate(signedBy("alice")))
+
+	// Failed evaluations do not count
+	policy.err = fmt.Errorf("err")
+	assert.Error(t, limited.Evaluate(signedBy("carol")))
+	assert.Error(t, limited.Evaluate(signedBy("carol")))
+	policy.err = nil
+	assert.NoError(t, limited.Evaluate(signedBy("carol")))
+}
+
+func TestRateLimiterSweep(t *testing.T) {
+	now := time.Unix(0, 0)
+	limiter := NewRateLimiter(1, time.Minute)
+	limiter.now = func() time.Time { return now }
+	limited := NewRateLimitedPolicy(&countingPolicy{}, limiter)
+
+	assert.NoError(t, limited.Evaluate(signedBy("alice", "bob", "alice")))
+	assert.Len(t, limiter.authorizations, 2)
+	assert.Len(t, limiter.authorizations["alice"], 1)
+
+	now = now.Add(2 * time.Minute)
+	assert.NoError(t, limited.Evaluate(signedBy("carol")))
+	assert.Len(t, limiter.authorizations, 1, "Should have forgotten the identities without recent evaluations")
+}
@@ -0,0 +1,97 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policies
+
+import (
+	"sort"
+	"strings"
+
+	cb "github.com/hyperledger/fabric/protos/common"
+
+	"github.com/golang/protobuf/proto"
+)
+
+// Snapshot is the effective policy tree of a channel. It maps the fully
+// qualified path of each policy, such as /Channel/Application/Admins,
+// to the config of the policy.
+type Snapshot map[string]*cb.ConfigPolicy
+
+// PolicyChange is a difference between two snapshots
+type PolicyChange struct {
+	// Path is the fully qualified path of the policy
+	Path string
+	// Original is the policy before the change, or nil if it was added
+	Original *cb.ConfigPolicy
+	// Updated is the policy after the change, or nil if it was removed
+	Updated *cb.ConfigPolicy
+}
+
+// Paths returns the paths of the policies of the snapshot in sorted order
+func (s Snapshot) Paths() []string {
+	paths := make([]string, 0, len(s))
+	for path := range s {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Diff returns the policies added, removed or modified by updated with
+// respect to s, sorted by path. Only changes to the policy itself or to
+// its mod policy are reported, not changes of version alone.
+func (s Snapshot) Diff(updated Snapshot) []*PolicyChange {
+	paths := s.Paths()
+	for path := range updated {
+		if _, ok := s[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	var changes []*PolicyChange
+	for _, path := range paths {
+		original, updatedPolicy := s[path], updated[path]
+		if original != nil && updatedPolicy != nil &&
+			original.ModPolicy == updatedPolicy.ModPolicy && proto.Equal(original.Policy, updatedPolicy.Policy) {
+			continue
+		}
+		changes = append(changes, &PolicyChange{Path: path, Original: original, Updated: updatedPolicy})
+	}
+	return changes
+}
+
+// Snapshot returns the policies of the manager and of its sub-managers,
+// keyed by their fully qualified path
+func (pm *ManagerImpl) Snapshot() Snapshot {
+	var path []string
+	for m := pm; m != nil; m = m.parent {
+		path = append([]string{m.basePath}, path...)
+	}
+
+	snapshot := make(Snapshot)
+	pm.snapshot(PathSeparator+strings.Join(path, PathSeparator), snapshot)
+	return snapshot
+}
+
+func (pm *ManagerImpl) snapshot(path string, snapshot Snapshot) {
+	for key, configPolicy := range pm.config.sources {
+		snapshot[path+PathSeparator+key] = configPolicy
+	}
+	for group, m := range pm.config.managers {
+		m.snapshot(path+PathSeparator+group, snapshot)
+	}
+}
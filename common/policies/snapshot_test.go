@@ -0,0 +1,81 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policies
+
+import (
+	"testing"
+
+	cb "github.com/hyperledger/fabric/protos/common"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// makeSnapshotManager returns a manager with a policy named policy and a sub-group
+// group holding a policy named policy, both of value value
+func makeSnapshotManager(t *testing.T, group string, value string) *ManagerImpl {
+	m := NewManagerImpl("test", defaultProviders())
+	nested, err := m.BeginPolicyProposals([]string{group})
+	assert.NoError(t, err)
+	_, err = nested[0].BeginPolicyProposals([]string{})
+	assert.NoError(t, err)
+
+	configPolicy := &cb.ConfigPolicy{Policy: &cb.Policy{Type: mockType, Policy: []byte(value)}, ModPolicy: "Admins"}
+	assert.NoError(t, m.ProposePolicy("policy", configPolicy))
+	assert.NoError(t, nested[0].ProposePolicy("policy", configPolicy))
+
+	nested[0].CommitProposals()
+	m.CommitProposals()
+	return m
+}
+
+func TestSnapshot(t *testing.T) {
+	m := makeSnapshotManager(t, "nest", "value")
+
+	snapshot := m.Snapshot()
+	assert.Equal(t, []string{"/test/nest/policy", "/test/policy"}, snapshot.Paths())
+	assert.Equal(t, []byte("value"), snapshot["/test/nest/policy"].Policy.Policy)
+
+	n, ok := m.Manager([]string{"nest"})
+	assert.True(t, ok)
+	assert.Equal(t, []string{"/test/nest/policy"}, n.Snapshot().Paths())
+}
+
+func TestSnapshotDiff(t *testing.T) {
+	original := makeSnapshotManager(t, "nest", "value").Snapshot()
+
+	assert.Empty(t, original.Diff(makeSnapshotManager(t, "nest", "value").Snapshot()))
+
+	changes := original.Diff(makeSnapshotManager(t, "other", "value").Snapshot())
+	assert.Len(t, changes, 2)
+	assert.Equal(t, "/test/nest/policy", changes[0].Path)
+	assert.Nil(t, changes[0].Updated, "Policy should have been removed")
+	assert.Equal(t, "/test/other/policy", changes[1].Path)
+	assert.Nil(t, changes[1].Original, "Policy should have been added")
+
+	changes = original.Diff(makeSnapshotManager(t, "nest", "updated").Snapshot())
+	assert.Len(t, changes, 2)
+	for _, change := range changes {
+		assert.Equal(t, []byte("value"), change.Original.Policy.Policy)
+		assert.Equal(t, []byte("updated"), change.Updated.Policy.Policy)
+	}
+
+	updated := makeSnapshotManager(t, "nest", "value").Snapshot()
+	updated["/test/policy"] = &cb.ConfigPolicy{Policy: updated["/test/policy"].Policy, ModPolicy: "Writers"}
+	changes = original.Diff(updated)
+	assert.Len(t, changes, 1)
+	assert.Equal(t, "/test/policy", changes[0].Path)
+}
@@ -0,0 +1,126 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policies
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	cb "github.com/hyperledger/fabric/protos/common"
+)
+
+// Trace is the account of the evaluation of a policy,
+// or of one of the rules the policy is made of
+type Trace struct {
+	// Name is the name of the policy, if known
+	Name string
+	// Rule describes the policy or the rule
+	Rule string
+	// Satisfied tells whether the signature set satisfied the rule
+	Satisfied bool
+	// Reason is why the rule was not satisfied, if it was not
+	Reason string
+	// Signatures reports, for a rule requiring the signature of a
+	// principal, why each signature did or did not match it
+	Signatures []*SignatureTrace
+	// SubTraces are the traces of the sub-policies or sub-rules
+	SubTraces []*Trace
+}
+
+// SignatureTrace is the outcome of matching a signature against a principal
+type SignatureTrace struct {
+	// Index is the index of the signature in the signature set
+	Index int
+	// Signer is the MSP identifier of the signer, if known
+	Signer string
+	// Matched tells whether the signature matched the principal
+	Matched bool
+	// Reason is why the signature did not match, if it did not
+	Reason string
+}
+
+// TracingPolicy is implemented by policies which can
+// explain why a signature set does or does not satisfy them
+type TracingPolicy interface {
+	Policy
+
+	// EvaluateWithTrace evaluates the signature set as Evaluate does,
+	// and returns along the trace of the evaluation
+	EvaluateWithTrace(signatureSet []*cb.SignedData) (*Trace, error)
+}
+
+// EvaluateWithTrace evaluates signatureSet against policy and returns the
+// trace of the evaluation. The trace of a policy not implementing
+// TracingPolicy only reports the outcome of the evaluation.
+func EvaluateWithTrace(policy Policy, signatureSet []*cb.SignedData) (*Trace, error) {
+	if tp, ok := policy.(TracingPolicy); ok {
+		return tp.EvaluateWithTrace(signatureSet)
+	}
+
+	err := policy.Evaluate(signatureSet)
+	trace := &Trace{Rule: fmt.Sprintf("Policy of type %T", policy), Satisfied: err == nil}
+	if err != nil {
+		trace.Reason = err.Error()
+	}
+	return trace, err
+}
+
+// EvaluateWithTrace returns the trace of the rejection of signatureSet
+func (rp rejectPolicy) EvaluateWithTrace(signatureSet []*cb.SignedData) (*Trace, error) {
+	err := rp.Evaluate(signatureSet)
+	return &Trace{Name: string(rp), Rule: "Reject all", Reason: err.Error()}, err
+}
+
+// String returns the trace as an indented tree, one rule per line
+func (t *Trace) String() string {
+	var buf bytes.Buffer
+	t.write(&buf, 0)
+	return buf.String()
+}
+
+func (t *Trace) write(buf *bytes.Buffer, depth int) {
+	indent := strings.Repeat("  ", depth)
+	outcome := "FAILED"
+	if t.Satisfied {
+		outcome = "OK"
+	}
+	fmt.Fprintf(buf, "%s[%s] ", indent, outcome)
+	if t.Name != "" {
+		fmt.Fprintf(buf, "%s: ", t.Name)
+	}
+	buf.WriteString(t.Rule)
+	if t.Reason != "" {
+		fmt.Fprintf(buf, " (%s)", t.Reason)
+	}
+	buf.WriteString("\n")
+
+	for _, st := range t.Signatures {
+		fmt.Fprintf(buf, "%s  - signature %d", indent, st.Index)
+		if st.Signer != "" {
+			fmt.Fprintf(buf, " of %s", st.Signer)
+		}
+		if st.Matched {
+			buf.WriteString(": matched\n")
+		} else {
+			fmt.Fprintf(buf, ": not matched (%s)\n", st.Reason)
+		}
+	}
+	for _, sub := range t.SubTraces {
+		sub.write(buf, depth+1)
+	}
+}
@@ -0,0 +1,110 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policies
+
+import (
+	"testing"
+
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImplicitMetaTrace(t *testing.T) {
+	imp, err := newImplicitMetaPolicy(utils.MarshalOrPanic(&cb.ImplicitMetaPolicy{
+		Rule:      cb.ImplicitMetaPolicy_MAJORITY,
+		SubPolicy: TestPolicyName,
+	}))
+	assert.NoError(t, err)
+	imp.initialize(&policyConfig{managers: makeManagers(3, 1)})
+
+	trace, err := imp.EvaluateWithTrace(nil)
+	assert.Error(t, err)
+	assert.False(t, trace.Satisfied)
+	assert.Equal(t, "MAJORITY of the TestPolicyName sub-policies, 2 out of 3", trace.Rule)
+	assert.Len(t, trace.SubTraces, 3)
+	satisfied := 0
+	for _, subTrace := range trace.SubTraces {
+		assert.Contains(t, subTrace.Name, PathSeparator+TestPolicyName)
+		if subTrace.Satisfied {
+			satisfied++
+		}
+	}
+	assert.Equal(t, 1, satisfied)
+
+	imp.initialize(&policyConfig{managers: makeManagers(3, 2)})
+	trace, err = imp.EvaluateWithTrace(nil)
+	assert.NoError(t, err)
+	assert.True(t, trace.Satisfied)
+}
+
+func TestCompositePolicyTrace(t *testing.T) {
+	m := NewManagerImpl(ChannelPrefix, map[int32]Provider{mockType: outcomeProvider{}})
+	_, err := m.BeginPolicyProposals([]string{})
+	assert.NoError(t, err)
+	assert.NoError(t, m.ProposePolicy("Accept", outcomePolicy("accept")))
+	assert.NoError(t, m.ProposePolicy("Reject", outcomePolicy("reject")))
+	assert.NoError(t, m.ProposePolicy("Composite", compositePolicyConfig(CompositeNOutOf(2,
+		CompositeSubPolicy("Accept"),
+		CompositeSubPolicy("Reject"),
+		CompositeSubPolicy("Missing")))))
+	assert.NoError(t, m.ProposePolicy("Cycle", compositePolicyConfig(CompositeSubPolicy("Cycle"))))
+	m.CommitProposals()
+
+	policy, _ := m.GetPolicy("Composite")
+	trace, err := EvaluateWithTrace(policy, nil)
+	assert.Equal(t, policy.Evaluate(nil), err)
+	assert.False(t, trace.Satisfied)
+	assert.Equal(t, "2 out of 3", trace.Rule)
+	assert.Len(t, trace.SubTraces, 3)
+	assert.Equal(t, "Accept", trace.SubTraces[0].Name)
+	assert.True(t, trace.SubTraces[0].Satisfied)
+	assert.Equal(t, "Reject", trace.SubTraces[1].Name)
+	assert.False(t, trace.SubTraces[1].Satisfied)
+	assert.Equal(t, "Missing", trace.SubTraces[2].Name)
+	assert.False(t, trace.SubTraces[2].Satisfied)
+
+	policy, _ = m.GetPolicy("Cycle")
+	trace, err = EvaluateWithTrace(policy, nil)
+	assert.Error(t, err)
+	assert.False(t, trace.Satisfied)
+}
+
+func TestTraceString(t *testing.T) {
+	trace := &Trace{
+		Rule:   "1 out of 2",
+		Reason: "0 sub-policies satisfied",
+		SubTraces: []*Trace{
+			{
+				Name: "Org1",
+				Rule: "Signed by 'Org1MSP.member'",
+				Signatures: []*SignatureTrace{
+					{Index: 0, Signer: "Org2MSP", Reason: "Identity does not satisfy the principal"},
+				},
+			},
+			{Rule: "Signed by 'Org2MSP.admin'", Satisfied: true, Signatures: []*SignatureTrace{{Index: 0, Signer: "Org2MSP", Matched: true}}},
+		},
+	}
+
+	assert.Equal(t, `[FAILED] 1 out of 2 (0 sub-policies satisfied)
+  [FAILED] Org1: Signed by 'Org1MSP.member'
+    - signature 0 of Org2MSP: not matched (Identity does not satisfy the principal)
+  [OK] Signed by 'Org2MSP.admin'
+    - signature 0 of Org2MSP: matched
+`, trace.String())
+}
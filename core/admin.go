@@ -17,6 +17,7 @@ limitations under the License.
 package core
 
 import (
+	"fmt"
 	"os"
 	"runtime"
 
@@ -25,7 +26,13 @@ import (
 	"golang.org/x/net/context"
 
 	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/hyperledger/fabric/bccsp/factory"
 	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/gossip/discovery"
+	"github.com/hyperledger/fabric/gossip/election"
+	"github.com/hyperledger/fabric/gossip/service"
+	mspmgmt "github.com/hyperledger/fabric/msp/mgmt"
 	pb "github.com/hyperledger/fabric/protos/peer"
 )
 
@@ -95,3 +102,141 @@ func (*ServerAdmin) SetModuleLogLevel(ctx context.Context, request *pb.LogLevelR
 
 	return logResponse, err
 }
+
+// ReloadLocalMSP reloads the local MSP from the directory set by
+// peer.mspConfigPath and, if its signing identity is valid, swaps it
+// in for the current one, so that admin certificates can be rotated
+// and CRLs added without restarting the peer
+func (*ServerAdmin) ReloadLocalMSP(context.Context, *empty.Empty) (*pb.LocalMSPResponse, error) {
+	var bccspConfig *factory.FactoryOpts
+	if err := viper.UnmarshalKey("peer.BCCSP", &bccspConfig); err != nil {
+		return nil, fmt.Errorf("Could not parse YAML config [%s]", err)
+	}
+
+	mspID := viper.GetString("peer.localMspId")
+	if err := mspmgmt.ReloadAndValidateLocalMsp(viper.GetString("peer.mspConfigPath"), bccspConfig, mspID); err != nil {
+		log.Errorf("Failed reloading the local MSP: %s", err)
+		return nil, fmt.Errorf("Failed reloading the local MSP: %s", err)
+	}
+
+	signer, err := mspmgmt.GetLocalMSP().GetDefaultSigningIdentity()
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting the signing identity of the local MSP: %s", err)
+	}
+	signerBytes, err := signer.Serialize()
+	if err != nil {
+		return nil, fmt.Errorf("Failed serializing the signing identity of the local MSP: %s", err)
+	}
+
+	log.Infof("Reloaded the local MSP [%s]", mspID)
+	return &pb.LocalMSPResponse{MspId: mspID, SigningIdentity: signerBytes}, nil
+}
+
+// GetMSPAuditLog returns the validation decisions recorded in the
+// audit log enabled by peer.mspAudit, restricted to the MSPs of the
+// requested channel, if any
+func (*ServerAdmin) GetMSPAuditLog(ctx context.Context, request *pb.MSPAuditLogRequest) (*pb.MSPAuditLogResponse, error) {
+	auditLog := mspmgmt.GetAuditLog()
+	if auditLog == nil {
+		return nil, fmt.Errorf("The MSP audit log is not enabled")
+	}
+
+	response := &pb.MSPAuditLogResponse{}
+	for _, record := range auditLog.Records() {
+		if request.Channel != "" && record.Channel != request.Channel {
+			continue
+		}
+		response.Records = append(response.Records, &pb.MSPAuditRecord{
+			Timestamp:    &timestamp.Timestamp{Seconds: record.Time.Unix(), Nanos: int32(record.Time.Nanosecond())},
+			MspId:        record.MSPID,
+			Channel:      record.Channel,
+			IdentityHash: record.IdentityHash,
+			Operation:    record.Operation,
+			Principal:    record.Principal,
+			Allowed:      record.Allowed,
+			Reason:       record.Reason,
+		})
+	}
+	return response, nil
+}
+
+// GetLeadershipState returns the state of the leader election of the
+// requested channel, if the leader of the channel is elected
+func (*ServerAdmin) GetLeadershipState(ctx context.Context, request *pb.LeadershipRequest) (*pb.LeadershipState, error) {
+	state, err := service.GetGossipService().LeadershipState(request.Channel)
+	if err != nil {
+		return nil, err
+	}
+	return leadershipStateToProto(request.Channel, state), nil
+}
+
+// OverrideLeadership overrides the leader election of the requested channel,
+// so that operators can move the leadership away from the peer delivering
+// blocks of the organization before maintaining it
+func (*ServerAdmin) OverrideLeadership(ctx context.Context, request *pb.LeadershipOverrideRequest) (*pb.LeadershipState, error) {
+	var override election.Override
+	switch request.Override {
+	case pb.LeadershipState_NONE:
+		override = election.NoOverride
+	case pb.LeadershipState_RELINQUISHED:
+		override = election.Relinquished
+	case pb.LeadershipState_ACQUIRED:
+		override = election.Acquired
+	default:
+		return nil, fmt.Errorf("Unknown leadership override %s", request.Override)
+	}
+
+	state, err := service.GetGossipService().OverrideLeadership(request.Channel, override)
+	if err != nil {
+		return nil, err
+	}
+	log.Infof("Overrode the leader election of channel [%s] with [%s]", request.Channel, override)
+	return leadershipStateToProto(request.Channel, state), nil
+}
+
+func leadershipStateToProto(channel string, state election.LeadershipState) *pb.LeadershipState {
+	response := &pb.LeadershipState{
+		Channel:  channel,
+		IsLeader: state.IsLeader,
+		Leader:   []byte(state.Leader),
+		Term:     state.Term,
+	}
+	switch state.Override {
+	case election.Relinquished:
+		response.Override = pb.LeadershipState_RELINQUISHED
+	case election.Acquired:
+		response.Override = pb.LeadershipState_ACQUIRED
+	}
+	return response
+}
+
+// GetGossipMembership returns the alive and dead peers in the gossip
+// membership view of the peer, along with the times they were last seen
+func (*ServerAdmin) GetGossipMembership(context.Context, *empty.Empty) (*pb.GossipMembership, error) {
+	self, members := service.GetGossipService().MembershipSnapshot()
+
+	response := &pb.GossipMembership{
+		Self: &pb.GossipMember{
+			PkiId:            self.PKIid,
+			Endpoint:         self.Endpoint,
+			InternalEndpoint: self.InternalEndpoint,
+			Metadata:         self.Metadata,
+			Alive:            true,
+		},
+	}
+	for _, member := range members {
+		response.Members = append(response.Members, gossipMemberToProto(member))
+	}
+	return response, nil
+}
+
+func gossipMemberToProto(member discovery.MemberState) *pb.GossipMember {
+	return &pb.GossipMember{
+		PkiId:            member.PKIid,
+		Endpoint:         member.Endpoint,
+		InternalEndpoint: member.InternalEndpoint,
+		Metadata:         member.Metadata,
+		Alive:            member.Alive,
+		LastSeen:         &timestamp.Timestamp{Seconds: member.LastSeen.Unix(), Nanos: int32(member.LastSeen.Nanosecond())},
+	}
+}
@@ -0,0 +1,110 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shim
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+
+	"github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric/bccsp/envelope"
+	"github.com/hyperledger/fabric/bccsp/factory"
+)
+
+// Encrypt encrypts value with the 32 bytes AES key key, in GCM mode.
+// The ciphertext authenticates both value and additionalData, which
+// is not encrypted and must be passed to Decrypt unchanged. Keys are
+// best passed to chaincodes via the transient map of the proposal,
+// see GetTransient, as the transient map is not recorded on the ledger.
+func Encrypt(key, value, additionalData []byte) ([]byte, error) {
+	csp := factory.GetDefault()
+	k, err := csp.KeyImport(key, &bccsp.AES256ImportKeyOpts{Temporary: true})
+	if err != nil {
+		return nil, fmt.Errorf("Failed importing encryption key [%s]", err)
+	}
+
+	return csp.Encrypt(k, value, &bccsp.AESGCMModeOpts{AdditionalData: additionalData})
+}
+
+// Decrypt decrypts ciphertext, as returned by Encrypt, with key. It
+// fails if ciphertext or additionalData have been tampered with.
+func Decrypt(key, ciphertext, additionalData []byte) ([]byte, error) {
+	csp := factory.GetDefault()
+	k, err := csp.KeyImport(key, &bccsp.AES256ImportKeyOpts{Temporary: true})
+	if err != nil {
+		return nil, fmt.Errorf("Failed importing encryption key [%s]", err)
+	}
+
+	return csp.Decrypt(k, ciphertext, &bccsp.AESGCMModeOpts{AdditionalData: additionalData})
+}
+
+// PutEncryptedState encrypts value with key, see Encrypt, and puts it
+// in the ledger under stateKey. The ciphertext is bound to stateKey,
+// hence it cannot be moved to another key of the ledger undetected.
+func PutEncryptedState(stub ChaincodeStubInterface, stateKey string, value, key []byte) error {
+	ciphertext, err := Encrypt(key, value, []byte(stateKey))
+	if err != nil {
+		return fmt.Errorf("Failed encrypting state [%s]: [%s]", stateKey, err)
+	}
+
+	return stub.PutState(stateKey, ciphertext)
+}
+
+// GetEncryptedState returns the value put in the ledger under
+// stateKey by PutEncryptedState, decrypted with key. It returns
+// nil if stateKey does not exist.
+func GetEncryptedState(stub ChaincodeStubInterface, stateKey string, key []byte) ([]byte, error) {
+	ciphertext, err := stub.GetState(stateKey)
+	if err != nil || ciphertext == nil {
+		return nil, err
+	}
+
+	value, err := Decrypt(key, ciphertext, []byte(stateKey))
+	if err != nil {
+		return nil, fmt.Errorf("Failed decrypting state [%s]: [%s]", stateKey, err)
+	}
+	return value, nil
+}
+
+// PutStateForIdentities encrypts value for the holders of the PEM
+// encoded certificates certs, see envelope.EncryptForIdentities, and
+// puts it in the ledger under stateKey. Unlike PutEncryptedState, no
+// key is shared with the chaincode: each holder decrypts the value,
+// off the ledger, with envelope.DecryptWithKey, its private key and
+// stateKey as additional data.
+func PutStateForIdentities(stub ChaincodeStubInterface, stateKey string, value []byte, certs [][]byte) error {
+	recipients := make([]*x509.Certificate, len(certs))
+	for i, certPEM := range certs {
+		block, _ := pem.Decode(certPEM)
+		if block == nil {
+			return fmt.Errorf("Failed decoding PEM of certificate [%d]", i)
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return fmt.Errorf("Failed parsing certificate [%d]: [%s]", i, err)
+		}
+		recipients[i] = cert
+	}
+
+	ciphertext, err := envelope.EncryptForIdentities(factory.GetDefault(), value, []byte(stateKey), recipients)
+	if err != nil {
+		return fmt.Errorf("Failed encrypting state [%s]: [%s]", stateKey, err)
+	}
+
+	return stub.PutState(stateKey, ciphertext)
+}
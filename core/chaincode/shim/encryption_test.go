@@ -0,0 +1,146 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shim
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric/bccsp/envelope"
+	"github.com/hyperledger/fabric/bccsp/factory"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	key := make([]byte, 32)
+	rand.Read(key)
+	value := []byte("value")
+
+	ciphertext, err := Encrypt(key, value, []byte("aad"))
+	if err != nil {
+		t.Fatalf("Failed encrypting [%s]", err)
+	}
+
+	plaintext, err := Decrypt(key, ciphertext, []byte("aad"))
+	if err != nil {
+		t.Fatalf("Failed decrypting [%s]", err)
+	}
+	if !bytes.Equal(value, plaintext) {
+		t.Fatalf("Decrypted value [%s] is different from [%s]", plaintext, value)
+	}
+
+	if _, err := Decrypt(key, ciphertext, []byte("other aad")); err == nil {
+		t.Fatal("Decrypting with other additional data should fail")
+	}
+	if _, err := Encrypt(key[:16], value, nil); err == nil {
+		t.Fatal("Encrypting with a key of the wrong size should fail")
+	}
+}
+
+func TestEncryptedState(t *testing.T) {
+	key := make([]byte, 32)
+	rand.Read(key)
+
+	stub := NewMockStub("encryptedState", nil)
+	stub.MockTransactionStart("init")
+	if err := PutEncryptedState(stub, "a", []byte("secret"), key); err != nil {
+		t.Fatalf("Failed putting encrypted state [%s]", err)
+	}
+	ciphertext, _ := stub.GetState("a")
+	stub.PutState("b", ciphertext)
+	stub.MockTransactionEnd("init")
+
+	// The ledger only holds the ciphertext
+	if bytes.Contains(ciphertext, []byte("secret")) {
+		t.Fatal("The ledger should not hold the plaintext")
+	}
+
+	value, err := GetEncryptedState(stub, "a", key)
+	if err != nil {
+		t.Fatalf("Failed getting encrypted state [%s]", err)
+	}
+	if string(value) != "secret" {
+		t.Fatalf("Expected [secret], got [%s]", value)
+	}
+
+	// Ciphertexts moved to other keys do not decrypt
+	if _, err := GetEncryptedState(stub, "b", key); err == nil {
+		t.Fatal("Getting a ciphertext moved to another key should fail")
+	}
+
+	// Missing keys
+	value, err = GetEncryptedState(stub, "c", key)
+	if err != nil || value != nil {
+		t.Fatalf("Expected no value and no error, got [%s] and [%v]", value, err)
+	}
+}
+
+func TestStateForIdentities(t *testing.T) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed generating key [%s]", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &priv.PublicKey, priv)
+	if err != nil {
+		t.Fatalf("Failed creating certificate [%s]", err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+
+	stub := NewMockStub("stateForIdentities", nil)
+	stub.MockTransactionStart("init")
+	if err := PutStateForIdentities(stub, "a", []byte("secret"), [][]byte{certPEM}); err != nil {
+		t.Fatalf("Failed putting state [%s]", err)
+	}
+	if err := PutStateForIdentities(stub, "b", []byte("secret"), [][]byte{[]byte("cert")}); err == nil {
+		t.Fatal("Putting state for a malformed certificate should fail")
+	}
+	stub.MockTransactionEnd("init")
+
+	ciphertext, _ := stub.GetState("a")
+	if bytes.Contains(ciphertext, []byte("secret")) {
+		t.Fatal("The ledger should not hold the plaintext")
+	}
+
+	// The holder of the certificate decrypts off the ledger
+	csp := factory.GetDefault()
+	keyDER, _ := x509.MarshalECPrivateKey(priv)
+	k, err := csp.KeyImport(keyDER, &bccsp.ECDSAPrivateKeyImportOpts{Temporary: true})
+	if err != nil {
+		t.Fatalf("Failed importing key [%s]", err)
+	}
+	value, err := envelope.DecryptWithKey(csp, ciphertext, []byte("a"), k)
+	if err != nil {
+		t.Fatalf("Failed decrypting state [%s]", err)
+	}
+	if string(value) != "secret" {
+		t.Fatalf("Expected [secret], got [%s]", value)
+	}
+}
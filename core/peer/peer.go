@@ -180,10 +180,15 @@ func createChain(cid string, ledger ledger.PeerLedger, cb *common.Block) error {
 		})
 	}
 
+	// the MSPs of the channel are replaced on each config update
+	mspCallback := func(cm configtxapi.Manager) {
+		mspmgmt.ConfigureChannelMSPs(cm.ChainID(), cm.MSPManager())
+	}
+
 	configtxManager, err := configtx.NewManagerImpl(
 		configEnvelope,
 		configtxInitializer,
-		[]func(cm configtxapi.Manager){gossipCallbackWrapper},
+		[]func(cm configtxapi.Manager){gossipCallbackWrapper, mspCallback},
 	)
 	if err != nil {
 		return err
@@ -400,3 +405,7 @@ func (c *policyManagerMgmt) BasePath() string {
 func (c *policyManagerMgmt) PolicyNames() []string {
 	panic("implement me")
 }
+
+func (c *policyManagerMgmt) Snapshot() policies.Snapshot {
+	panic("implement me")
+}
@@ -31,6 +31,20 @@ type SecurityAdvisor interface {
 	OrgByPeerIdentity(PeerIdentityType) OrgIdentityType
 }
 
+// OUAwareSecurityAdvisor is a SecurityAdvisor that also provides
+// the organizational units of peer identities, allowing gossip to
+// scope messages to subdivisions of organizations
+type OUAwareSecurityAdvisor interface {
+	SecurityAdvisor
+
+	// OrgUnitsByPeerIdentity returns the organizational
+	// units of a given peer identity, sorted.
+	// If any error occurs, nil is returned.
+	// This method does not validate peerIdentity.
+	// This validation is supposed to be done appropriately during the execution flow.
+	OrgUnitsByPeerIdentity(PeerIdentityType) []string
+}
+
 // ChannelNotifier is implemented by the gossip component and is used for the peer
 // layer to notify the gossip component of a JoinChannel event
 type ChannelNotifier interface {
@@ -16,7 +16,11 @@ limitations under the License.
 
 package api
 
-import "github.com/hyperledger/fabric/gossip/common"
+import (
+	"time"
+
+	"github.com/hyperledger/fabric/gossip/common"
+)
 
 // MessageCryptoService is the contract between the gossip component and the
 // peer's cryptographic layer and is used by the gossip component to verify,
@@ -55,6 +59,74 @@ type MessageCryptoService interface {
 	ValidateIdentity(peerIdentity PeerIdentityType) error
 }
 
+// ExpirationAwareMessageCryptoService is a MessageCryptoService
+// that also knows when the identities of peers expire
+type ExpirationAwareMessageCryptoService interface {
+	MessageCryptoService
+
+	// Expiration returns the time the identity of a remote peer expires at.
+	// If the expiration of the identity is unknown, the zero time is returned.
+	// If the identity is invalid, it returns an error.
+	Expiration(peerIdentity PeerIdentityType) (time.Time, error)
+}
+
+// BlacklistError is implemented by the errors a MessageCryptoService
+// returns for the identities that only misbehaving peers send
+type BlacklistError interface {
+	error
+
+	// Blacklist returns whether gossip should stop
+	// communicating with the peer that sent the identity
+	Blacklist() bool
+}
+
+// MACSessionService is implemented by the MessageCryptoServices able to
+// authenticate the messages exchanged over a connection with MACs, under
+// a key established when the connection is, rather than with signatures
+type MACSessionService interface {
+
+	// NewMACHandshake starts the handshake establishing the MAC session
+	// of a new connection, or returns nil if MAC sessions are disabled
+	NewMACHandshake() (MACHandshake, error)
+}
+
+// MACHandshake establishes the MAC session of a connection.
+// Both peers send the hello of their handshake, then the signature
+// Sign returns given the hello of the other peer, and finally
+// establish the session given the signature of the other peer.
+type MACHandshake interface {
+
+	// Hello returns the ephemeral public key and the nonce to send to the remote peer
+	Hello() (ephemeralKey, nonce []byte)
+
+	// Sign returns the signature to send to the remote peer of remoteIdentity,
+	// given the ephemeral key and the nonce of its hello. The signature covers
+	// the PKI-IDs, ephemeral keys and nonces of both peers.
+	// If no MAC session may be established with that peer, Sign returns an error.
+	Sign(remoteIdentity PeerIdentityType, ephemeralKey, nonce []byte) ([]byte, error)
+
+	// Establish verifies the signature sent by the remote peer,
+	// and returns the MAC session of the connection
+	Establish(signature []byte) (MACSession, error)
+}
+
+// MACSession authenticates the messages exchanged over a connection.
+// Each MAC is bound to the position of its message in the connection,
+// so messages must be authenticated and verified in the order they
+// are sent in.
+type MACSession interface {
+
+	// MAC returns the MAC of the next message sent
+	MAC(message []byte) []byte
+
+	// VerifyMAC checks that tag is the MAC of the next message received
+	VerifyMAC(tag, message []byte) error
+
+	// Expired returns whether the session expired,
+	// and the connection must be established again
+	Expired() bool
+}
+
 // PeerIdentityType is the peer's certificate
 type PeerIdentityType []byte
 
@@ -52,6 +52,11 @@ const (
 
 var errSendOverflow = errors.New(sendOverflowErr)
 
+// maxRecvMsgSize is the maximum size, in bytes, of the envelopes received
+// from peers, and of their payloads once decompressed. The vendored gRPC
+// doesn't bound the size of the messages it receives, so streams enforce it
+var maxRecvMsgSize = 100 * 1024 * 1024
+
 func init() {
 	rand.Seed(42)
 }
@@ -80,6 +85,15 @@ func NewCommInstanceWithServer(port int, idMapper identity.Mapper, peerIdentity
 		dialOpts = []grpc.DialOption{grpc.WithTimeout(util.GetDurationOrDefault("peer.gossip.dialTimeout", defDialTimeout))}
 	}
 
+	pins, err := getCertPins()
+	if err != nil {
+		return nil, err
+	}
+	tunnel, err := getTunnelConfig()
+	if err != nil {
+		return nil, err
+	}
+
 	if port > 0 {
 		s, ll, secOpt, certHash = createGRPCLayer(port)
 		dialOpts = append(dialOpts, secOpt)
@@ -102,6 +116,13 @@ func NewCommInstanceWithServer(port int, idMapper identity.Mapper, peerIdentity
 		exitChan:          make(chan struct{}, 1),
 		subscriptions:     make([]chan proto.ReceivedMessage, 0),
 		blackListedPKIIDs: make([]common.PKIidType, 0),
+		tunnel:            tunnel,
+		compression:       getCompressionConfig(),
+		pins:              pins,
+		transports:        make(map[string]transport),
+	}
+	if macSessions, isMACSessionService := idMapper.(api.MACSessionService); isMACSessionService {
+		commInst.macSessions = macSessions
 	}
 	commInst.connStore = newConnStore(commInst, commInst.logger)
 	commInst.idMapper.Put(idMapper.GetPKIidOfCert(peerIdentity), peerIdentity)
@@ -113,6 +134,10 @@ func NewCommInstanceWithServer(port int, idMapper identity.Mapper, peerIdentity
 			s.Serve(ll)
 		}()
 		proto.RegisterGossipServer(s, commInst)
+		if err := commInst.startTunnel(s); err != nil {
+			commInst.Stop()
+			return nil, err
+		}
 	}
 
 	return commInst, nil
@@ -135,6 +160,10 @@ func NewCommInstance(s *grpc.Server, cert *tls.Certificate, idStore identity.Map
 	}
 
 	proto.RegisterGossipServer(s, commInst.(*commImpl))
+	if err := commInst.(*commImpl).startTunnel(s); err != nil {
+		commInst.Stop()
+		return nil, err
+	}
 
 	return commInst, nil
 }
@@ -158,11 +187,72 @@ type commImpl struct {
 	stopWG            sync.WaitGroup
 	subscriptions     []chan proto.ReceivedMessage
 	blackListedPKIIDs []common.PKIidType
+	tunnel            tunnelConfig
+	tunnelSrv         *tunnelServer
+	transportLock     sync.Mutex
+	transports        map[string]transport // the transport last used to reach each endpoint
+	compression       compressionConfig
+	pins              certPins
+	macSessions       api.MACSessionService // nil if the messages of connections aren't authenticated with MACs
 }
 
-func (c *commImpl) createConnection(endpoint string, expectedPKIID common.PKIidType) (*connection, error) {
+// startTunnel accepts tunneled connections, served by s, if configured to
+func (c *commImpl) startTunnel(s *grpc.Server) error {
+	if c.tunnel.listenAddress == "" {
+		return nil
+	}
+	ts, err := serveTunnel(s, c.tunnel)
+	if err != nil {
+		return fmt.Errorf("Failed accepting tunneled connections on %s: %v", c.tunnel.listenAddress, err)
+	}
+	c.tunnelSrv = ts
+	c.logger.Info("Accepting tunneled connections on", ts.Addr())
+	return nil
+}
+
+// dial connects to the peer of endpoint via raw gRPC and, if it cannot be
+// reached and fallback is enabled, via the tunnel. The transport a peer was
+// last reached with is tried first on the following connections.
+func (c *commImpl) dial(endpoint string) (*grpc.ClientConn, proto.GossipClient, error) {
+	transports := []transport{grpcTransport}
+	if c.tunnel.fallback {
+		c.transportLock.Lock()
+		if c.transports[endpoint] == tunnelTransport {
+			transports = []transport{tunnelTransport, grpcTransport}
+		} else {
+			transports = append(transports, tunnelTransport)
+		}
+		c.transportLock.Unlock()
+	}
+
 	var err error
-	var cc *grpc.ClientConn
+	for _, t := range transports {
+		opts := append(append([]grpc.DialOption{}, c.opts...), grpc.WithBlock())
+		if t == tunnelTransport {
+			opts = append(opts, c.tunnel.tunnelDialOption())
+		}
+		var cc *grpc.ClientConn
+		cc, err = grpc.Dial(endpoint, opts...)
+		if err != nil {
+			c.logger.Debug("Failed dialing", endpoint, "via", t, ":", err)
+			continue
+		}
+		cl := proto.NewGossipClient(cc)
+		if _, err = cl.Ping(context.Background(), &proto.Empty{}); err != nil {
+			cc.Close()
+			continue
+		}
+		if c.tunnel.fallback {
+			c.transportLock.Lock()
+			c.transports[endpoint] = t
+			c.transportLock.Unlock()
+		}
+		return cc, cl, nil
+	}
+	return nil, nil, err
+}
+
+func (c *commImpl) createConnection(endpoint string, expectedPKIID common.PKIidType) (*connection, error) {
 	var stream proto.Gossip_GossipStreamClient
 	var pkiID common.PKIidType
 
@@ -172,28 +262,31 @@ func (c *commImpl) createConnection(endpoint string, expectedPKIID common.PKIidT
 	if c.isStopping() {
 		return nil, errors.New("Stopping")
 	}
-	cc, err = grpc.Dial(endpoint, append(c.opts, grpc.WithBlock())...)
+	cc, cl, err := c.dial(endpoint)
 	if err != nil {
 		return nil, err
 	}
 
-	cl := proto.NewGossipClient(cc)
-
-	if _, err = cl.Ping(context.Background(), &proto.Empty{}); err != nil {
-		cc.Close()
-		return nil, err
-	}
-
 	if stream, err = cl.GossipStream(context.Background()); err == nil {
-		pkiID, err = c.authenticateRemotePeer(stream)
+		var connMsg *proto.ConnEstablish
+		var mac *connMAC
+		connMsg, mac, err = c.authenticateRemotePeer(stream)
 		if err == nil {
+			pkiID = connMsg.PkiID
 			if expectedPKIID != nil && !bytes.Equal(pkiID, expectedPKIID) {
 				// PKIID is nil when we don't know the remote PKI id's
 				c.logger.Warning("Remote endpoint claims to be a different peer, expected", expectedPKIID, "but got", pkiID)
 				return nil, errors.New("Authentication failure")
 			}
+			if err = c.pins.verify(stream.Context(), endpoint, c.selfCertHash != nil); err != nil {
+				c.logger.Warning("Rejecting", endpoint, ":", err)
+				cc.Close()
+				return nil, err
+			}
 			conn := newConnection(cl, cc, stream, nil)
 			conn.pkiID = pkiID
+			conn.compression = c.compression.negotiate(connMsg.Compression)
+			conn.mac = mac
 			conn.logger = c.logger
 
 			h := func(m *proto.SignedGossipMessage) {
@@ -280,16 +373,13 @@ func (c *commImpl) Probe(remotePeer *RemotePeer) error {
 		return errors.New("Stopping")
 	}
 	c.logger.Debug("Entering, endpoint:", endpoint, "PKIID:", pkiID)
-	cc, err := grpc.Dial(remotePeer.Endpoint, append(c.opts, grpc.WithBlock())...)
+	cc, _, err := c.dial(remotePeer.Endpoint)
+	c.logger.Debug("Returning", err)
 	if err != nil {
-		c.logger.Debug("Returning", err)
 		return err
 	}
-	defer cc.Close()
-	cl := proto.NewGossipClient(cc)
-	_, err = cl.Ping(context.Background(), &proto.Empty{})
-	c.logger.Debug("Returning", err)
-	return err
+	cc.Close()
+	return nil
 }
 
 func (c *commImpl) Accept(acceptor common.MessageAcceptor) <-chan proto.ReceivedMessage {
@@ -359,6 +449,9 @@ func (c *commImpl) Stop() {
 	if c.lsnr != nil {
 		c.lsnr.Close()
 	}
+	if c.tunnelSrv != nil {
+		c.tunnelSrv.Close()
+	}
 	c.connStore.shutdown()
 	c.logger.Debug("Shut down connection store, connection count:", c.connStore.connNum())
 	c.exitChan <- struct{}{}
@@ -384,7 +477,7 @@ func extractRemoteAddress(stream stream) string {
 	return remoteAddress
 }
 
-func (c *commImpl) authenticateRemotePeer(stream stream) (common.PKIidType, error) {
+func (c *commImpl) authenticateRemotePeer(stream stream) (*proto.ConnEstablish, *connMAC, error) {
 	ctx := stream.Context()
 	remoteAddress := extractRemoteAddress(stream)
 	remoteCertHash := extractCertificateHashFromContext(ctx)
@@ -405,7 +498,8 @@ func (c *commImpl) authenticateRemotePeer(stream stream) (common.PKIidType, erro
 		}
 	}
 
-	cMsg = c.createConnectionMsg(c.PKIID, c.selfCertHash, c.peerIdentity, signer)
+	handshake := c.newMACHandshake()
+	cMsg = c.createConnectionMsg(c.PKIID, c.selfCertHash, c.peerIdentity, handshake, signer)
 
 	c.logger.Debug("Sending", cMsg, "to", remoteAddress)
 	stream.Send(cMsg.Envelope)
@@ -413,34 +507,37 @@ func (c *commImpl) authenticateRemotePeer(stream stream) (common.PKIidType, erro
 	if err != nil {
 		err := fmt.Errorf("Failed reading messge from %s, reason: %v", remoteAddress, err)
 		c.logger.Warning(err)
-		return nil, err
+		return nil, nil, err
 	}
 	receivedMsg := m.GetConn()
 	if receivedMsg == nil {
 		c.logger.Warning("Expected connection message but got", receivedMsg)
-		return nil, errors.New("Wrong type")
+		return nil, nil, errors.New("Wrong type")
 	}
 
 	if receivedMsg.PkiID == nil {
 		c.logger.Warning("%s didn't send a pkiID")
-		return nil, fmt.Errorf("%s didn't send a pkiID", remoteAddress)
+		return nil, nil, fmt.Errorf("%s didn't send a pkiID", remoteAddress)
 	}
 
 	if c.isPKIblackListed(receivedMsg.PkiID) {
 		c.logger.Warning("Connection attempt from", remoteAddress, "but it is black-listed")
-		return nil, errors.New("Black-listed")
+		return nil, nil, errors.New("Black-listed")
 	}
 	c.logger.Debug("Received", receivedMsg, "from", remoteAddress)
 	err = c.idMapper.Put(receivedMsg.PkiID, receivedMsg.Cert)
 	if err != nil {
 		c.logger.Warning("Identity store rejected", remoteAddress, ":", err)
-		return nil, err
+		if blErr, isBlacklistError := err.(api.BlacklistError); isBlacklistError && blErr.Blacklist() {
+			c.BlackListPKIid(receivedMsg.PkiID)
+		}
+		return nil, nil, err
 	}
 
 	// if TLS is detected, verify remote peer
 	if remoteCertHash != nil && c.selfCertHash != nil {
 		if !bytes.Equal(remoteCertHash, receivedMsg.Hash) {
-			return nil, fmt.Errorf("Expected %v in remote hash, but got %v", remoteCertHash, receivedMsg.Hash)
+			return nil, nil, fmt.Errorf("Expected %v in remote hash, but got %v", remoteCertHash, receivedMsg.Hash)
 		}
 		verifier := func(peerIdentity []byte, signature, message []byte) error {
 			pkiID := c.idMapper.GetPKIidOfCert(api.PeerIdentityType(peerIdentity))
@@ -449,26 +546,32 @@ func (c *commImpl) authenticateRemotePeer(stream stream) (common.PKIidType, erro
 		err = m.Verify(receivedMsg.Cert, verifier)
 		if err != nil {
 			c.logger.Error("Failed verifying signature from", remoteAddress, ":", err)
-			return nil, err
+			return nil, nil, err
 		}
 	}
 
+	mac, err := c.establishMACSession(stream, handshake, receivedMsg, remoteAddress)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	c.logger.Debug("Authenticated", remoteAddress)
-	return receivedMsg.PkiID, nil
+	return receivedMsg, mac, nil
 }
 
 func (c *commImpl) GossipStream(stream proto.Gossip_GossipStreamServer) error {
 	if c.isStopping() {
 		return errors.New("Shutting down")
 	}
-	PKIID, err := c.authenticateRemotePeer(stream)
+	connMsg, mac, err := c.authenticateRemotePeer(stream)
 	if err != nil {
 		c.logger.Error("Authentication failed")
 		return err
 	}
+	PKIID := common.PKIidType(connMsg.PkiID)
 	c.logger.Debug("Servicing", extractRemoteAddress(stream))
 
-	conn := c.connStore.onConnected(stream, PKIID)
+	conn := c.connStore.onConnected(stream, PKIID, c.compression.negotiate(connMsg.Compression), mac)
 
 	// if connStore denied the connection, it means we already have a connection to that peer
 	// so close this stream
@@ -543,15 +646,22 @@ func readWithTimeout(stream interface{}, timeout time.Duration, address string)
 	}
 }
 
-func (c *commImpl) createConnectionMsg(pkiID common.PKIidType, hash []byte, cert api.PeerIdentityType, signer proto.Signer) *proto.SignedGossipMessage {
+func (c *commImpl) createConnectionMsg(pkiID common.PKIidType, hash []byte, cert api.PeerIdentityType, handshake api.MACHandshake, signer proto.Signer) *proto.SignedGossipMessage {
+	var hello *proto.MACHandshake
+	if handshake != nil {
+		ephemeralKey, nonce := handshake.Hello()
+		hello = &proto.MACHandshake{EphemeralKey: ephemeralKey, Nonce: nonce}
+	}
 	m := &proto.GossipMessage{
 		Tag:   proto.GossipMessage_EMPTY,
 		Nonce: 0,
 		Content: &proto.GossipMessage_Conn{
 			Conn: &proto.ConnEstablish{
-				Hash:  hash,
-				Cert:  cert,
-				PkiID: pkiID,
+				Hash:        hash,
+				Cert:        cert,
+				PkiID:       pkiID,
+				Compression: c.compression.algorithms,
+				Mac:         hello,
 			},
 		},
 	}
@@ -125,7 +125,7 @@ func handshaker(endpoint string, comm Comm, t *testing.T, sigMutator func([]byte
 		pkiID = common.PKIidType(pkiIDmutator([]byte(endpoint)))
 	}
 	assert.NoError(t, err, "%v", err)
-	msg := c.createConnectionMsg(pkiID, clientCertHash, []byte(endpoint), func(msg []byte) ([]byte, error) {
+	msg := c.createConnectionMsg(pkiID, clientCertHash, []byte(endpoint), nil, func(msg []byte) ([]byte, error) {
 		return msg, nil
 	})
 
@@ -140,7 +140,7 @@ func handshaker(endpoint string, comm Comm, t *testing.T, sigMutator func([]byte
 	assert.NoError(t, err, "%v", err)
 	if sigMutator == nil {
 		hash := extractCertificateHashFromContext(stream.Context())
-		expectedMsg := c.createConnectionMsg(common.PKIidType("localhost:9611"), hash, []byte("localhost:9611"), func(msg []byte) ([]byte, error) {
+		expectedMsg := c.createConnectionMsg(common.PKIidType("localhost:9611"), hash, []byte("localhost:9611"), nil, func(msg []byte) ([]byte, error) {
 			return msg, nil
 		})
 		assert.Equal(t, expectedMsg.Envelope.Signature, msg.Envelope.Signature)
@@ -299,6 +299,48 @@ func TestBlackListPKIid(t *testing.T) {
 	waitForMessages(t, out4, 4, "comm1 should have received 4 messages")
 }
 
+type blacklistErr struct{}
+
+func (blacklistErr) Error() string {
+	return "identity too large"
+}
+
+func (blacklistErr) Blacklist() bool {
+	return true
+}
+
+// offenderSecProvider rejects the identity of
+// offender with an api.BlacklistError
+type offenderSecProvider struct {
+	naiveSecProvider
+	offender string
+}
+
+func (s *offenderSecProvider) ValidateIdentity(peerIdentity api.PeerIdentityType) error {
+	if string(peerIdentity) == s.offender {
+		return blacklistErr{}
+	}
+	return nil
+}
+
+func TestBlackListOnIdentityRejection(t *testing.T) {
+	t.Parallel()
+	comm1, _ := newCommInstance(1621, &offenderSecProvider{offender: "localhost:1622"})
+	comm2, _ := newCommInstance(1622, naiveSec)
+	defer comm1.Stop()
+	defer comm2.Stop()
+
+	comm2.Send(createGossipMsg(), remotePeer(1621))
+
+	blacklisted := func() bool {
+		return comm1.(*commImpl).isPKIblackListed(common.PKIidType("localhost:1622"))
+	}
+	for i := 0; i < 50 && !blacklisted(); i++ {
+		time.Sleep(100 * time.Millisecond)
+	}
+	assert.True(t, blacklisted(), "comm1 should have black-listed comm2")
+}
+
 func TestParallelSend(t *testing.T) {
 	t.Parallel()
 	comm1, _ := newCommInstance(5411, naiveSec)
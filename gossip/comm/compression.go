@@ -0,0 +1,159 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package comm
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/hyperledger/fabric/gossip/util"
+	proto "github.com/hyperledger/fabric/protos/gossip"
+	"github.com/spf13/viper"
+)
+
+const defCompressionThreshold = 4096
+
+// compressor compresses and decompresses the payloads of envelopes
+type compressor interface {
+	compress(data []byte) ([]byte, error)
+	decompress(data []byte) ([]byte, error)
+}
+
+// compressors maps the names of the supported compression algorithms to their compressors
+var compressors = map[string]compressor{
+	"gzip": gzipCompressor{},
+}
+
+type gzipCompressor struct{}
+
+func (gzipCompressor) compress(data []byte) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	w := gzip.NewWriter(buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gzipCompressor) decompress(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	// Payloads are bounded by the size of the messages received, so
+	// that peers cannot send compression bombs
+	decompressed, err := ioutil.ReadAll(io.LimitReader(r, int64(maxRecvMsgSize)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(decompressed) > maxRecvMsgSize {
+		return nil, fmt.Errorf("Decompressed payload exceeds %d bytes", maxRecvMsgSize)
+	}
+	return decompressed, nil
+}
+
+// compressionConfig is the configuration of the compression of messages
+type compressionConfig struct {
+	// algorithms are the compression algorithms this
+	// peer accepts, in its order of preference
+	algorithms []string
+	// threshold is the size, in bytes, below which
+	// payloads are sent uncompressed
+	threshold int
+}
+
+func getCompressionConfig() compressionConfig {
+	conf := compressionConfig{
+		threshold: util.GetIntOrDefault("peer.gossip.compression.threshold", defCompressionThreshold),
+	}
+	for _, algorithm := range viper.GetStringSlice("peer.gossip.compression.algorithms") {
+		if _, supported := compressors[algorithm]; !supported {
+			util.GetLogger(util.LoggingCommModule, "").Warning("Unsupported compression algorithm", algorithm, "ignored")
+			continue
+		}
+		conf.algorithms = append(conf.algorithms, algorithm)
+	}
+	return conf
+}
+
+// negotiate returns the compression of the messages sent to a remote peer,
+// with the algorithm this peer prefers among those the remote peer accepts
+func (conf compressionConfig) negotiate(remoteAlgorithms []string) connCompression {
+	for _, algorithm := range conf.algorithms {
+		for _, remoteAlgorithm := range remoteAlgorithms {
+			if algorithm == remoteAlgorithm {
+				return connCompression{algorithm: algorithm, threshold: conf.threshold}
+			}
+		}
+	}
+	return connCompression{}
+}
+
+// connCompression is the compression of the messages
+// sent through a connection, negotiated in the handshake
+type connCompression struct {
+	// algorithm is empty if messages are sent uncompressed
+	algorithm string
+	threshold int
+}
+
+// compress returns a copy of envelope with its payload compressed, or
+// envelope itself if it is smaller than the threshold or incompressible
+func (cc connCompression) compress(envelope *proto.Envelope) (*proto.Envelope, error) {
+	if cc.algorithm == "" || len(envelope.Payload) < cc.threshold {
+		return envelope, nil
+	}
+	payload, err := compressors[cc.algorithm].compress(envelope.Payload)
+	if err != nil {
+		return nil, err
+	}
+	if len(payload) >= len(envelope.Payload) {
+		return envelope, nil
+	}
+	return &proto.Envelope{
+		Payload:        payload,
+		Signature:      envelope.Signature,
+		SecretEnvelope: envelope.SecretEnvelope,
+		Hops:           envelope.Hops,
+		Compression:    cc.algorithm,
+	}, nil
+}
+
+// decompressEnvelope decompresses the payload of envelope in place, if compressed
+func decompressEnvelope(envelope *proto.Envelope) error {
+	if envelope.Compression == "" {
+		return nil
+	}
+	c, supported := compressors[envelope.Compression]
+	if !supported {
+		return fmt.Errorf("Unsupported compression algorithm %s", envelope.Compression)
+	}
+	payload, err := c.decompress(envelope.Payload)
+	if err != nil {
+		return fmt.Errorf("Failed decompressing payload: %v", err)
+	}
+	envelope.Payload = payload
+	envelope.Compression = ""
+	return nil
+}
@@ -0,0 +1,149 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package comm
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	proto "github.com/hyperledger/fabric/protos/gossip"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompressionNegotiation(t *testing.T) {
+	conf := compressionConfig{algorithms: []string{"gzip"}, threshold: 1024}
+	assert.Equal(t, connCompression{algorithm: "gzip", threshold: 1024}, conf.negotiate([]string{"zstd", "gzip"}))
+	assert.Equal(t, connCompression{}, conf.negotiate([]string{"zstd"}))
+	assert.Equal(t, connCompression{}, conf.negotiate(nil))
+	assert.Equal(t, connCompression{}, compressionConfig{}.negotiate([]string{"gzip"}))
+}
+
+func TestCompressEnvelope(t *testing.T) {
+	cc := connCompression{algorithm: "gzip", threshold: 1024}
+
+	// Small payloads are not compressed
+	small := &proto.Envelope{Payload: bytes.Repeat([]byte{1}, 1023)}
+	envelope, err := cc.compress(small)
+	assert.NoError(t, err)
+	assert.True(t, small == envelope)
+
+	// Neither are incompressible payloads
+	random := &proto.Envelope{Payload: []byte(fmt.Sprintf("%x", time.Now().UnixNano()))}
+	envelope, err = connCompression{algorithm: "gzip"}.compress(random)
+	assert.NoError(t, err)
+	assert.True(t, random == envelope)
+
+	// Large payloads are compressed in a copy of the envelope
+	payload := bytes.Repeat([]byte{1}, 4096)
+	large := &proto.Envelope{Payload: payload, Signature: []byte{2}, Hops: 3}
+	envelope, err = cc.compress(large)
+	assert.NoError(t, err)
+	assert.Equal(t, "gzip", envelope.Compression)
+	assert.True(t, len(envelope.Payload) < len(payload))
+	assert.Equal(t, payload, large.Payload)
+	assert.Empty(t, large.Compression)
+
+	assert.NoError(t, decompressEnvelope(envelope))
+	assert.Equal(t, large, envelope)
+
+	// Uncompressed envelopes are left as is
+	assert.NoError(t, decompressEnvelope(small))
+	assert.Len(t, small.Payload, 1023)
+
+	// Unsupported algorithms and corrupted payloads are rejected
+	assert.Error(t, decompressEnvelope(&proto.Envelope{Payload: payload, Compression: "zstd"}))
+	assert.Error(t, decompressEnvelope(&proto.Envelope{Payload: payload, Compression: "gzip"}))
+}
+
+func TestDecompressionBomb(t *testing.T) {
+	defer func(size int) {
+		maxRecvMsgSize = size
+	}(maxRecvMsgSize)
+	maxRecvMsgSize = 1024 * 1024
+
+	bomb, err := gzipCompressor{}.compress(make([]byte, maxRecvMsgSize+1))
+	assert.NoError(t, err)
+	assert.True(t, len(bomb) < maxRecvMsgSize/100)
+	assert.Error(t, decompressEnvelope(&proto.Envelope{Payload: bomb, Compression: "gzip"}))
+
+	payload, err := gzipCompressor{}.compress(make([]byte, maxRecvMsgSize))
+	assert.NoError(t, err)
+	envelope := &proto.Envelope{Payload: payload, Compression: "gzip"}
+	assert.NoError(t, decompressEnvelope(envelope))
+	assert.Len(t, envelope.Payload, maxRecvMsgSize)
+}
+
+func TestCompressionBetweenPeers(t *testing.T) {
+	t.Parallel()
+	conf := compressionConfig{algorithms: []string{"gzip"}, threshold: 1024}
+
+	comm1, _ := newCommInstance(8631, naiveSec)
+	defer comm1.Stop()
+	comm1.(*commImpl).compression = conf
+	comm2, _ := newCommInstance(8632, naiveSec)
+	defer comm2.Stop()
+	comm2.(*commImpl).compression = conf
+	comm3, _ := newCommInstance(8633, naiveSec)
+	defer comm3.Stop()
+
+	largeMsg := func() *proto.SignedGossipMessage {
+		return (&proto.GossipMessage{
+			Tag: proto.GossipMessage_EMPTY,
+			Content: &proto.GossipMessage_DataMsg{
+				DataMsg: &proto.DataMessage{Payload: &proto.Payload{SeqNum: 1, Data: bytes.Repeat([]byte{1}, 8192)}},
+			},
+		}).NoopSign()
+	}
+	expectMsg := func(m <-chan proto.ReceivedMessage, sent *proto.SignedGossipMessage) {
+		select {
+		case msg := <-m:
+			assert.Equal(t, sent.Payload, msg.GetGossipMessage().Payload)
+			assert.Empty(t, msg.GetGossipMessage().Compression)
+			assert.Equal(t, sent.GetDataMsg().Payload.Data, msg.GetGossipMessage().GetDataMsg().Payload.Data)
+		case <-time.After(5 * time.Second):
+			assert.Fail(t, "Didn't receive a message")
+		}
+	}
+	compressionTo := func(c Comm, port int) string {
+		conn, err := c.(*commImpl).connStore.getConnection(remotePeer(port))
+		assert.NoError(t, err)
+		return conn.compression.algorithm
+	}
+
+	// Both peers accept gzip, hence compress the messages they send each other
+	m1 := comm1.Accept(acceptAll)
+	msg := largeMsg()
+	comm2.Send(msg, remotePeer(8631))
+	expectMsg(m1, msg)
+	assert.Equal(t, "gzip", compressionTo(comm2, 8631))
+	assert.Equal(t, "gzip", compressionTo(comm1, 8632))
+
+	m2 := comm2.Accept(acceptAll)
+	msg = largeMsg()
+	comm1.Send(msg, remotePeer(8632))
+	expectMsg(m2, msg)
+
+	// A peer accepting no compression is sent uncompressed messages
+	m3 := comm3.Accept(acceptAll)
+	msg = largeMsg()
+	comm1.Send(msg, remotePeer(8633))
+	expectMsg(m3, msg)
+	assert.Equal(t, "", compressionTo(comm1, 8633))
+	assert.Equal(t, "", compressionTo(comm3, 8631))
+}
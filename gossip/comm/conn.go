@@ -18,11 +18,11 @@ package comm
 
 import (
 	"errors"
+	"fmt"
 	"sync"
 	"sync/atomic"
 
 	"github.com/hyperledger/fabric/gossip/common"
-	"github.com/hyperledger/fabric/gossip/util"
 	proto "github.com/hyperledger/fabric/protos/gossip"
 	"github.com/op/go-logging"
 	"google.golang.org/grpc"
@@ -154,7 +154,7 @@ func (cs *connectionStore) shutdown() {
 	wg.Wait()
 }
 
-func (cs *connectionStore) onConnected(serverStream proto.Gossip_GossipStreamServer, pkiID common.PKIidType) *connection {
+func (cs *connectionStore) onConnected(serverStream proto.Gossip_GossipStreamServer, pkiID common.PKIidType, compression connCompression, mac *connMAC) *connection {
 	cs.Lock()
 	defer cs.Unlock()
 
@@ -162,12 +162,14 @@ func (cs *connectionStore) onConnected(serverStream proto.Gossip_GossipStreamSer
 		c.close()
 	}
 
-	return cs.registerConn(pkiID, serverStream)
+	return cs.registerConn(pkiID, serverStream, compression, mac)
 }
 
-func (cs *connectionStore) registerConn(pkiID common.PKIidType, serverStream proto.Gossip_GossipStreamServer) *connection {
+func (cs *connectionStore) registerConn(pkiID common.PKIidType, serverStream proto.Gossip_GossipStreamServer, compression connCompression, mac *connMAC) *connection {
 	conn := newConnection(nil, nil, nil, serverStream)
 	conn.pkiID = pkiID
+	conn.compression = compression
+	conn.mac = mac
 	conn.logger = cs.logger
 	cs.pki2Conn[string(pkiID)] = conn
 	return conn
@@ -184,7 +186,7 @@ func (cs *connectionStore) closeByPKIid(pkiID common.PKIidType) {
 
 func newConnection(cl proto.GossipClient, c *grpc.ClientConn, cs proto.Gossip_GossipStreamClient, ss proto.Gossip_GossipStreamServer) *connection {
 	connection := &connection{
-		outBuff:      make(chan *msgSending, util.GetIntOrDefault("peer.gossip.sendBuffSize", defSendBuffSize)),
+		lanes:        getLaneConfigs(),
 		cl:           cl,
 		conn:         c,
 		clientStream: cs,
@@ -192,14 +194,20 @@ func newConnection(cl proto.GossipClient, c *grpc.ClientConn, cs proto.Gossip_Go
 		stopFlag:     int32(0),
 		stopChan:     make(chan struct{}, 1),
 	}
+	for i := range connection.outBuffs {
+		connection.outBuffs[i] = make(chan *msgSending, connection.lanes[i].sendBuffSize)
+	}
 
 	return connection
 }
 
 type connection struct {
-	outBuff      chan *msgSending
+	lanes        [numLanes]laneConfig            // configuration of the buffers of each lane
+	outBuffs     [numLanes]chan *msgSending      // messages to send, by lane
 	logger       *logging.Logger                 // logger
 	pkiID        common.PKIidType                // pkiID of the remote endpoint
+	compression  connCompression                 // compression of the messages sent to the remote endpoint
+	mac          *connMAC                        // authenticates the messages of the connection with MACs, if not nil
 	handler      handler                         // function to invoke upon a message reception
 	conn         *grpc.ClientConn                // gRPC connection to remote endpoint
 	cl           proto.GossipClient              // gRPC stub of remote endpoint
@@ -240,37 +248,69 @@ func (conn *connection) toDie() bool {
 }
 
 func (conn *connection) send(msg *proto.SignedGossipMessage, onErr func(error)) {
+	lane := LaneOf(msg.GossipMessage)
+	envelope := msg.Envelope
+	// Only the messages carrying blocks are worth compressing
+	if lane == BlockLane {
+		var err error
+		if envelope, err = conn.compression.compress(envelope); err != nil {
+			conn.logger.Warning(conn.pkiID, "Failed compressing message, sending it uncompressed:", err)
+			envelope = msg.Envelope
+		}
+	}
+
 	conn.Lock()
 	defer conn.Unlock()
 
-	if len(conn.outBuff) == util.GetIntOrDefault("peer.gossip.sendBuffSize", defSendBuffSize) {
-		go onErr(errSendOverflow)
-		return
+	outBuff := conn.outBuffs[lane]
+	if len(outBuff) == cap(outBuff) {
+		switch conn.lanes[lane].dropPolicy {
+		case DropNone:
+			go onErr(errSendOverflow)
+			return
+		case DropNewest:
+			conn.logger.Debug(conn.pkiID, "Send buffer of lane", lane, "is full, dropping message")
+			return
+		case DropOldest:
+			conn.logger.Debug(conn.pkiID, "Send buffer of lane", lane, "is full, dropping oldest message")
+			select {
+			case <-outBuff:
+			default:
+			}
+		}
 	}
 
 	m := &msgSending{
-		envelope: msg.Envelope,
+		envelope: envelope,
 		onErr:    onErr,
 	}
 
-	conn.outBuff <- m
+	outBuff <- m
 }
 
 func (conn *connection) serviceConnection() error {
 	errChan := make(chan error, 1)
-	msgChan := make(chan *proto.SignedGossipMessage, util.GetIntOrDefault("peer.gossip.recvBuffSize", defRecvBuffSize))
-	defer close(msgChan)
+	var msgChans [numLanes]chan *proto.SignedGossipMessage
+	for i := range msgChans {
+		msgChans[i] = make(chan *proto.SignedGossipMessage, conn.lanes[i].recvBuffSize)
+		defer close(msgChans[i])
+	}
 
 	// Call stream.Recv() asynchronously in readFromStream(),
 	// and wait for either the Recv() call to end,
 	// or a signal to close the connection, which exits
 	// the method and makes the Recv() call to fail in the
 	// readFromStream() method
-	go conn.readFromStream(errChan, msgChan)
+	go conn.readFromStream(errChan, msgChans)
 
 	go conn.writeToStream()
 
 	for !conn.toDie() {
+		// Messages of higher priority lanes are handled first
+		if msg := pollReceived(msgChans); msg != nil {
+			conn.handler(msg)
+			continue
+		}
 		select {
 		case stop := <-conn.stopChan:
 			conn.logger.Debug("Closing reading from stream")
@@ -278,13 +318,30 @@ func (conn *connection) serviceConnection() error {
 			return nil
 		case err := <-errChan:
 			return err
-		case msg := <-msgChan:
+		case msg := <-msgChans[LeadershipLane]:
+			conn.handler(msg)
+		case msg := <-msgChans[BlockLane]:
+			conn.handler(msg)
+		case msg := <-msgChans[DefaultLane]:
 			conn.handler(msg)
 		}
 	}
 	return nil
 }
 
+// pollReceived returns the next received message of
+// the highest priority lane, or nil if there is none
+func pollReceived(msgChans [numLanes]chan *proto.SignedGossipMessage) *proto.SignedGossipMessage {
+	for _, msgChan := range msgChans {
+		select {
+		case msg := <-msgChan:
+			return msg
+		default:
+		}
+	}
+	return nil
+}
+
 func (conn *connection) writeToStream() {
 	for !conn.toDie() {
 		stream := conn.getStream()
@@ -292,26 +349,55 @@ func (conn *connection) writeToStream() {
 			conn.logger.Error(conn.pkiID, "Stream is nil, aborting!")
 			return
 		}
-		select {
-		case m := <-conn.outBuff:
-			err := stream.Send(m.envelope)
-			if err != nil {
-				go m.onErr(err)
+		m := conn.nextToSend()
+		if m == nil {
+			conn.logger.Debug("Closing writing to stream")
+			return
+		}
+		envelope := m.envelope
+		if conn.mac != nil {
+			if conn.mac.session.Expired() {
+				// The connection must be established again, with a fresh key
+				go m.onErr(errMACSessionExpired)
 				return
 			}
-			break
-		case stop := <-conn.stopChan:
-			conn.logger.Debug("Closing writing to stream")
-			conn.stopChan <- stop
+			envelope = conn.mac.authenticate(envelope)
+		}
+		err := stream.Send(envelope)
+		if err != nil {
+			go m.onErr(err)
 			return
 		}
 	}
 }
 
-func (conn *connection) readFromStream(errChan chan error, msgChan chan *proto.SignedGossipMessage) {
+// nextToSend waits for the next message to send, taken from the highest
+// priority lane that has messages, and returns nil if the connection stops
+func (conn *connection) nextToSend() *msgSending {
+	for _, outBuff := range conn.outBuffs {
+		select {
+		case m := <-outBuff:
+			return m
+		default:
+		}
+	}
+	select {
+	case m := <-conn.outBuffs[LeadershipLane]:
+		return m
+	case m := <-conn.outBuffs[BlockLane]:
+		return m
+	case m := <-conn.outBuffs[DefaultLane]:
+		return m
+	case stop := <-conn.stopChan:
+		conn.stopChan <- stop
+		return nil
+	}
+}
+
+func (conn *connection) readFromStream(errChan chan error, msgChans [numLanes]chan *proto.SignedGossipMessage) {
 	defer func() {
 		recover()
-	}() // msgChan might be closed
+	}() // msgChans might be closed
 	for !conn.toDie() {
 		stream := conn.getStream()
 		if stream == nil {
@@ -329,13 +415,60 @@ func (conn *connection) readFromStream(errChan chan error, msgChan chan *proto.S
 			conn.logger.Debug(conn.pkiID, "Got error, aborting:", err)
 			return
 		}
+		if size := len(envelope.Payload); size > maxRecvMsgSize {
+			err = fmt.Errorf("Payload of %d bytes exceeds %d bytes", size, maxRecvMsgSize)
+			errChan <- err
+			conn.logger.Warning(conn.pkiID, "Got error, aborting:", err)
+			return
+		}
+		if conn.mac != nil {
+			if conn.mac.session.Expired() {
+				errChan <- errMACSessionExpired
+				conn.logger.Debug(conn.pkiID, "MAC session expired, aborting")
+				return
+			}
+			if err = conn.mac.verify(envelope); err != nil {
+				errChan <- err
+				conn.logger.Warning(conn.pkiID, "Got error, aborting:", err)
+				return
+			}
+		}
+		if err = decompressEnvelope(envelope); err != nil {
+			errChan <- err
+			conn.logger.Warning(conn.pkiID, "Got error, aborting:", err)
+			return
+		}
 		msg, err := envelope.ToGossipMessage()
 		if err != nil {
 			errChan <- err
 			conn.logger.Warning(conn.pkiID, "Got error, aborting:", err)
+			return
+		}
+		if conn.mac != nil {
+			msg.SetAuthenticatedBy(conn.mac.identity)
+		}
+		conn.receive(msg, msgChans)
+	}
+}
+
+// receive buffers msg in its lane, applying the drop policy of the lane if its buffer is full
+func (conn *connection) receive(msg *proto.SignedGossipMessage, msgChans [numLanes]chan *proto.SignedGossipMessage) {
+	lane := LaneOf(msg.GossipMessage)
+	msgChan := msgChans[lane]
+	if len(msgChan) == cap(msgChan) {
+		switch conn.lanes[lane].dropPolicy {
+		case DropNewest:
+			conn.logger.Debug(conn.pkiID, "Receive buffer of lane", lane, "is full, dropping message")
+			return
+		case DropOldest:
+			conn.logger.Debug(conn.pkiID, "Receive buffer of lane", lane, "is full, dropping oldest message")
+			select {
+			case <-msgChan:
+			default:
+			}
 		}
-		msgChan <- msg
 	}
+	msgChan <- msg
 }
 
 func (conn *connection) getStream() stream {
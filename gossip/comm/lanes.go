@@ -0,0 +1,115 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package comm
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric/gossip/util"
+	proto "github.com/hyperledger/fabric/protos/gossip"
+	"github.com/spf13/viper"
+)
+
+// Lane is a priority class of gossip messages. Connections buffer the messages
+// of each lane separately, and send and process the messages of a lane before
+// those of lower priority lanes, so that high-volume traffic does not starve
+// block dissemination and leader election.
+type Lane int
+
+const (
+	// LeadershipLane carries leader election messages, it has the highest priority
+	LeadershipLane Lane = iota
+	// BlockLane carries the blocks disseminated and transferred by state sync
+	BlockLane
+	// DefaultLane carries all other messages, such as membership and pull messages
+	DefaultLane
+
+	numLanes = int(DefaultLane) + 1
+)
+
+var laneNames = []string{"leadership", "block", "default"}
+
+func (l Lane) String() string {
+	return laneNames[l]
+}
+
+// LaneOf returns the lane of msg
+func LaneOf(msg *proto.GossipMessage) Lane {
+	switch {
+	case msg.IsLeadershipMsg():
+		return LeadershipLane
+	case msg.IsDataMsg() || msg.IsRemoteStateMessage():
+		return BlockLane
+	}
+	return DefaultLane
+}
+
+// DropPolicy determines what happens to a message of a lane whose buffer is full
+type DropPolicy int
+
+const (
+	// DropNone drops no message: a full send buffer disconnects the
+	// peer as unresponsive, and a full receive buffer stalls reading
+	// from the peer until messages are processed
+	DropNone DropPolicy = iota
+	// DropNewest drops the message which does not fit in the buffer
+	DropNewest
+	// DropOldest drops the oldest message of the buffer to make room
+	DropOldest
+)
+
+var dropPolicyNames = []string{"none", "newest", "oldest"}
+
+func (p DropPolicy) String() string {
+	return dropPolicyNames[p]
+}
+
+// ParseDropPolicy returns the drop policy of the given name
+func ParseDropPolicy(name string) (DropPolicy, error) {
+	for i, policyName := range dropPolicyNames {
+		if name == policyName {
+			return DropPolicy(i), nil
+		}
+	}
+	return DropNone, fmt.Errorf("Unknown drop policy %s, expected one of %v", name, dropPolicyNames)
+}
+
+// laneConfig is the configuration of the buffers of a lane
+type laneConfig struct {
+	sendBuffSize int
+	recvBuffSize int
+	dropPolicy   DropPolicy
+}
+
+// getLaneConfigs returns the configuration of each lane. Buffer sizes not configured
+// for a lane default to the ones of connections, and the drop policy to DropNone.
+func getLaneConfigs() [numLanes]laneConfig {
+	var confs [numLanes]laneConfig
+	for i := range confs {
+		key := "peer.gossip.lanes." + Lane(i).String()
+		confs[i].sendBuffSize = util.GetIntOrDefault(key+".sendBuffSize", util.GetIntOrDefault("peer.gossip.sendBuffSize", defSendBuffSize))
+		confs[i].recvBuffSize = util.GetIntOrDefault(key+".recvBuffSize", util.GetIntOrDefault("peer.gossip.recvBuffSize", defRecvBuffSize))
+		if name := viper.GetString(key + ".dropPolicy"); name != "" {
+			policy, err := ParseDropPolicy(name)
+			if err != nil {
+				util.GetLogger(util.LoggingCommModule, "").Warning("Invalid drop policy of lane", Lane(i), ":", err)
+			}
+			confs[i].dropPolicy = policy
+		}
+	}
+	return confs
+}
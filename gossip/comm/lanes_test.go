@@ -0,0 +1,159 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package comm
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/gossip/util"
+	proto "github.com/hyperledger/fabric/protos/gossip"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func createLaneMsg(lane Lane, nonce uint64) *proto.SignedGossipMessage {
+	msg := &proto.GossipMessage{Nonce: nonce, Tag: proto.GossipMessage_EMPTY}
+	switch lane {
+	case LeadershipLane:
+		msg.Content = &proto.GossipMessage_LeadershipMsg{LeadershipMsg: &proto.LeadershipMessage{}}
+	case BlockLane:
+		msg.Content = &proto.GossipMessage_DataMsg{DataMsg: &proto.DataMessage{}}
+	default:
+		msg.Content = &proto.GossipMessage_AliveMsg{AliveMsg: &proto.AliveMessage{}}
+	}
+	return msg.NoopSign()
+}
+
+func TestLaneOf(t *testing.T) {
+	for _, lane := range []Lane{LeadershipLane, BlockLane, DefaultLane} {
+		assert.Equal(t, lane, LaneOf(createLaneMsg(lane, 0).GossipMessage))
+	}
+	stateResponse := &proto.GossipMessage{
+		Content: &proto.GossipMessage_StateResponse{StateResponse: &proto.RemoteStateResponse{}},
+	}
+	assert.Equal(t, BlockLane, LaneOf(stateResponse))
+}
+
+func TestParseDropPolicy(t *testing.T) {
+	for _, policy := range []DropPolicy{DropNone, DropNewest, DropOldest} {
+		parsed, err := ParseDropPolicy(policy.String())
+		assert.NoError(t, err)
+		assert.Equal(t, policy, parsed)
+	}
+	_, err := ParseDropPolicy("random")
+	assert.Error(t, err)
+}
+
+func TestLaneConfigs(t *testing.T) {
+	defer func() {
+		viper.Set("peer.gossip.lanes.block.sendBuffSize", 0)
+		viper.Set("peer.gossip.lanes.default.dropPolicy", "")
+		viper.Set("peer.gossip.lanes.leadership.dropPolicy", "")
+	}()
+	viper.Set("peer.gossip.lanes.block.sendBuffSize", 5)
+	viper.Set("peer.gossip.lanes.default.dropPolicy", "oldest")
+	viper.Set("peer.gossip.lanes.leadership.dropPolicy", "random")
+
+	confs := getLaneConfigs()
+	assert.Equal(t, 5, confs[BlockLane].sendBuffSize)
+	assert.Equal(t, util.GetIntOrDefault("peer.gossip.sendBuffSize", defSendBuffSize), confs[DefaultLane].sendBuffSize)
+	assert.Equal(t, util.GetIntOrDefault("peer.gossip.recvBuffSize", defRecvBuffSize), confs[BlockLane].recvBuffSize)
+	assert.Equal(t, DropOldest, confs[DefaultLane].dropPolicy)
+	assert.Equal(t, DropNone, confs[BlockLane].dropPolicy)
+	assert.Equal(t, DropNone, confs[LeadershipLane].dropPolicy)
+}
+
+func TestSendPriority(t *testing.T) {
+	conn := newConnection(nil, nil, nil, nil)
+	conn.logger = util.GetLogger(util.LoggingCommModule, "")
+	noop := func(error) {}
+
+	conn.send(createLaneMsg(DefaultLane, 1), noop)
+	conn.send(createLaneMsg(BlockLane, 2), noop)
+	conn.send(createLaneMsg(DefaultLane, 3), noop)
+	conn.send(createLaneMsg(LeadershipLane, 4), noop)
+
+	var nonces []uint64
+	for i := 0; i < 4; i++ {
+		msg, err := conn.nextToSend().envelope.ToGossipMessage()
+		assert.NoError(t, err)
+		nonces = append(nonces, msg.Nonce)
+	}
+	assert.Equal(t, []uint64{4, 2, 1, 3}, nonces)
+
+	// A stopping connection has nothing to send
+	conn.stopChan <- struct{}{}
+	assert.Nil(t, conn.nextToSend())
+}
+
+func TestSendDropPolicies(t *testing.T) {
+	conn := newConnection(nil, nil, nil, nil)
+	conn.logger = util.GetLogger(util.LoggingCommModule, "")
+	for i := range conn.outBuffs {
+		conn.outBuffs[i] = make(chan *msgSending, 2)
+	}
+	conn.lanes[LeadershipLane].dropPolicy = DropNone
+	conn.lanes[BlockLane].dropPolicy = DropNewest
+	conn.lanes[DefaultLane].dropPolicy = DropOldest
+
+	overflow := make(chan error, 1)
+	onErr := func(err error) {
+		overflow <- err
+	}
+	for nonce := uint64(1); nonce <= 3; nonce++ {
+		for _, lane := range []Lane{LeadershipLane, BlockLane, DefaultLane} {
+			conn.send(createLaneMsg(lane, nonce), onErr)
+		}
+	}
+
+	// Overflowing the leadership lane is reported
+	assert.Equal(t, errSendOverflow, <-overflow)
+
+	nonces := func(lane Lane) []uint64 {
+		var nonces []uint64
+		for len(conn.outBuffs[lane]) > 0 {
+			msg, _ := (<-conn.outBuffs[lane]).envelope.ToGossipMessage()
+			nonces = append(nonces, msg.Nonce)
+		}
+		return nonces
+	}
+	assert.Equal(t, []uint64{1, 2}, nonces(LeadershipLane))
+	assert.Equal(t, []uint64{1, 2}, nonces(BlockLane))
+	assert.Equal(t, []uint64{2, 3}, nonces(DefaultLane))
+}
+
+func TestReceiveDropPolicies(t *testing.T) {
+	conn := newConnection(nil, nil, nil, nil)
+	conn.logger = util.GetLogger(util.LoggingCommModule, "")
+	conn.lanes[BlockLane].dropPolicy = DropNewest
+	conn.lanes[DefaultLane].dropPolicy = DropOldest
+	var msgChans [numLanes]chan *proto.SignedGossipMessage
+	for i := range msgChans {
+		msgChans[i] = make(chan *proto.SignedGossipMessage, 1)
+	}
+
+	conn.receive(createLaneMsg(BlockLane, 1), msgChans)
+	conn.receive(createLaneMsg(BlockLane, 2), msgChans)
+	conn.receive(createLaneMsg(DefaultLane, 3), msgChans)
+	conn.receive(createLaneMsg(DefaultLane, 4), msgChans)
+	conn.receive(createLaneMsg(LeadershipLane, 5), msgChans)
+
+	assert.Equal(t, uint64(5), pollReceived(msgChans).Nonce)
+	assert.Equal(t, uint64(1), pollReceived(msgChans).Nonce)
+	assert.Equal(t, uint64(4), pollReceived(msgChans).Nonce)
+	assert.Nil(t, pollReceived(msgChans))
+}
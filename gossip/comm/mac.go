@@ -0,0 +1,140 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package comm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/hyperledger/fabric/gossip/api"
+	"github.com/hyperledger/fabric/gossip/util"
+	proto "github.com/hyperledger/fabric/protos/gossip"
+)
+
+var errMACSessionExpired = errors.New("MAC session expired")
+
+// connMAC authenticates the messages of a connection with MACs
+type connMAC struct {
+	session  api.MACSession
+	identity api.PeerIdentityType // identity of the remote peer
+}
+
+// authenticate returns a copy of envelope carrying its MAC
+func (m *connMAC) authenticate(envelope *proto.Envelope) *proto.Envelope {
+	authenticated := *envelope
+	authenticated.Mac = m.session.MAC(macContent(envelope))
+	return &authenticated
+}
+
+// verify checks the MAC of envelope
+func (m *connMAC) verify(envelope *proto.Envelope) error {
+	return m.session.VerifyMAC(envelope.Mac, macContent(envelope))
+}
+
+// macContent returns what the MAC of envelope covers: all its fields but the MAC
+func macContent(envelope *proto.Envelope) []byte {
+	buf := &bytes.Buffer{}
+	writeField := func(field []byte) {
+		binary.Write(buf, binary.BigEndian, uint32(len(field)))
+		buf.Write(field)
+	}
+	writeField(envelope.Payload)
+	writeField(envelope.Signature)
+	if secret := envelope.SecretEnvelope; secret != nil {
+		buf.WriteByte(1)
+		writeField(secret.Payload)
+		writeField(secret.Signature)
+	} else {
+		buf.WriteByte(0)
+	}
+	binary.Write(buf, binary.BigEndian, envelope.Hops)
+	writeField([]byte(envelope.Compression))
+	return buf.Bytes()
+}
+
+// newMACHandshake starts the MAC handshake of a new connection,
+// or returns nil if MAC sessions are disabled
+func (c *commImpl) newMACHandshake() api.MACHandshake {
+	if c.macSessions == nil {
+		return nil
+	}
+	handshake, err := c.macSessions.NewMACHandshake()
+	if err != nil {
+		c.logger.Warning("Failed starting MAC handshake:", err)
+		return nil
+	}
+	return handshake
+}
+
+// establishMACSession completes the MAC handshake of a connection, once the
+// connection messages carrying the hellos of both peers were exchanged. It
+// returns nil if either peer doesn't authenticate the messages of the
+// connection with MACs.
+func (c *commImpl) establishMACSession(stream stream, handshake api.MACHandshake, remote *proto.ConnEstablish, remoteAddress string) (*connMAC, error) {
+	hello := remote.GetMac()
+	if handshake == nil || hello == nil {
+		return nil, nil
+	}
+
+	// A peer declines the session by sending an empty signature
+	signature, err := handshake.Sign(remote.Cert, hello.EphemeralKey, hello.Nonce)
+	if err != nil {
+		c.logger.Debug("Not authenticating the messages of", remoteAddress, "with MACs:", err)
+		signature = nil
+	}
+	stream.Send(createMACSignatureMsg(signature).Envelope)
+	m, err := readWithTimeout(stream, util.GetDurationOrDefault("peer.gossip.connTimeout", defConnTimeout), remoteAddress)
+	if err != nil {
+		return nil, fmt.Errorf("Failed reading MAC handshake from %s, reason: %v", remoteAddress, err)
+	}
+	var remoteSignature []byte
+	if mac := m.GetConn().GetMac(); mac != nil {
+		remoteSignature = mac.Signature
+	}
+	if len(signature) == 0 || len(remoteSignature) == 0 {
+		return nil, nil
+	}
+
+	session, err := handshake.Establish(remoteSignature)
+	if err != nil {
+		c.logger.Warning("Failed establishing MAC session with", remoteAddress, ":", err)
+		return nil, err
+	}
+	return &connMAC{session: session, identity: remote.Cert}, nil
+}
+
+// createMACSignatureMsg creates the connection message
+// carrying the signature of a MAC handshake
+func createMACSignatureMsg(signature []byte) *proto.SignedGossipMessage {
+	m := &proto.SignedGossipMessage{
+		GossipMessage: &proto.GossipMessage{
+			Tag: proto.GossipMessage_EMPTY,
+			Content: &proto.GossipMessage_Conn{
+				Conn: &proto.ConnEstablish{
+					Mac: &proto.MACHandshake{Signature: signature},
+				},
+			},
+		},
+	}
+	// The signature of the handshake is what authenticates the message
+	m.Sign(func(msg []byte) ([]byte, error) {
+		return msg, nil
+	})
+	return m
+}
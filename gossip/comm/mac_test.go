@@ -0,0 +1,181 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package comm
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/gossip/api"
+	proto "github.com/hyperledger/fabric/protos/gossip"
+	"github.com/stretchr/testify/assert"
+)
+
+type macSecProvider struct {
+	naiveSecProvider
+	decline bool
+}
+
+func (p *macSecProvider) NewMACHandshake() (api.MACHandshake, error) {
+	return &mockMACHandshake{decline: p.decline}, nil
+}
+
+type mockMACHandshake struct {
+	decline bool
+}
+
+func (*mockMACHandshake) Hello() ([]byte, []byte) {
+	return []byte("key"), []byte("nonce")
+}
+
+func (h *mockMACHandshake) Sign(remoteIdentity api.PeerIdentityType, ephemeralKey, nonce []byte) ([]byte, error) {
+	if h.decline {
+		return nil, errors.New("declined")
+	}
+	return []byte("signature"), nil
+}
+
+func (*mockMACHandshake) Establish(signature []byte) (api.MACSession, error) {
+	if !bytes.Equal(signature, []byte("signature")) {
+		return nil, errors.New("invalid signature")
+	}
+	return &mockMACSession{}, nil
+}
+
+type mockMACSession struct {
+	lock     sync.Mutex
+	sent     uint64
+	received uint64
+	expired  int32
+}
+
+func (s *mockMACSession) MAC(message []byte) []byte {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.sent++
+	return mockMAC(s.sent, message)
+}
+
+func (s *mockMACSession) VerifyMAC(tag, message []byte) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if !bytes.Equal(tag, mockMAC(s.received+1, message)) {
+		return errors.New("invalid MAC")
+	}
+	s.received++
+	return nil
+}
+
+func (s *mockMACSession) Expired() bool {
+	return atomic.LoadInt32(&s.expired) == 1
+}
+
+func mockMAC(seq uint64, message []byte) []byte {
+	h := sha256.New()
+	binary.Write(h, binary.BigEndian, seq)
+	h.Write(message)
+	return h.Sum(nil)
+}
+
+func TestMACContent(t *testing.T) {
+	mac := &connMAC{session: &mockMACSession{}}
+	envelope := createGossipMsg().Envelope
+	authenticated := mac.authenticate(envelope)
+	assert.Nil(t, envelope.Mac)
+
+	// The MAC covers all the fields of the envelope
+	for _, mutate := range []func(e *proto.Envelope){
+		func(e *proto.Envelope) { e.Payload = append([]byte{0}, e.Payload...) },
+		func(e *proto.Envelope) { e.Signature = []byte("other signature") },
+		func(e *proto.Envelope) { e.SecretEnvelope = &proto.SecretEnvelope{} },
+		func(e *proto.Envelope) { e.Hops++ },
+		func(e *proto.Envelope) { e.Compression = "gzip" },
+	} {
+		tampered := *authenticated
+		mutate(&tampered)
+		assert.Error(t, (&connMAC{session: &mockMACSession{}}).verify(&tampered))
+	}
+	assert.NoError(t, (&connMAC{session: &mockMACSession{}}).verify(authenticated))
+}
+
+func TestMACSessions(t *testing.T) {
+	t.Parallel()
+	comm1, _ := newCommInstance(8637, &macSecProvider{})
+	defer comm1.Stop()
+	comm2, _ := newCommInstance(8638, &macSecProvider{})
+	defer comm2.Stop()
+	comm3, _ := newCommInstance(8639, &macSecProvider{decline: true})
+	defer comm3.Stop()
+
+	failingVerifier := func(peerIdentity []byte, signature, message []byte) error {
+		return errors.New("not verified")
+	}
+	receive := func(msgs <-chan proto.ReceivedMessage) *proto.SignedGossipMessage {
+		select {
+		case m := <-msgs:
+			return m.GetGossipMessage()
+		case <-time.After(5 * time.Second):
+			assert.Fail(t, "Didn't receive a message")
+			return nil
+		}
+	}
+
+	// Messages of MAC sessions are trusted as signed by the peer that sent them
+	m2 := comm2.Accept(acceptAll)
+	comm1.Send(signedGossipMsg(), remotePeer(8638))
+	msg := receive(m2)
+	assert.NoError(t, msg.Verify([]byte("localhost:8637"), failingVerifier))
+	assert.Error(t, msg.Verify([]byte("localhost:8639"), failingVerifier))
+
+	// Over both directions of the connection
+	m1 := comm1.Accept(acceptAll)
+	comm2.Send(signedGossipMsg(), remotePeer(8637))
+	msg = receive(m1)
+	assert.NoError(t, msg.Verify([]byte("localhost:8638"), failingVerifier))
+
+	// Without a MAC session, signatures are verified
+	m3 := comm3.Accept(acceptAll)
+	comm1.Send(signedGossipMsg(), remotePeer(8639))
+	msg = receive(m3)
+	assert.Error(t, msg.Verify([]byte("localhost:8637"), failingVerifier))
+
+	// Once the session expires, the connection is established again
+	conn, err := comm1.(*commImpl).connStore.getConnection(remotePeer(8638))
+	assert.NoError(t, err)
+	atomic.StoreInt32(&conn.mac.session.(*mockMACSession).expired, 1)
+	comm1.Send(signedGossipMsg(), remotePeer(8638))
+	comm1.Send(signedGossipMsg(), remotePeer(8638))
+	msg = receive(m2)
+	assert.NoError(t, msg.Verify([]byte("localhost:8637"), failingVerifier))
+	newConn, err := comm1.(*commImpl).connStore.getConnection(remotePeer(8638))
+	assert.NoError(t, err)
+	assert.NotEqual(t, conn, newConn)
+}
+
+func signedGossipMsg() *proto.SignedGossipMessage {
+	msg := createGossipMsg()
+	msg.Sign(func(msg []byte) ([]byte, error) {
+		return msg, nil
+	})
+	return msg
+}
@@ -0,0 +1,72 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package comm
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/viper"
+	"golang.org/x/net/context"
+)
+
+// certPins maps endpoints to the hashes of the TLS
+// certificates the peers of these endpoints may present
+type certPins map[string][][]byte
+
+// getCertPins returns the pins of peer.gossip.pinnedCerts,
+// whose entries are of the form <endpoint>=<hex SHA-256 hash>
+func getCertPins() (certPins, error) {
+	pins := certPins{}
+	for _, entry := range viper.GetStringSlice("peer.gossip.pinnedCerts") {
+		parts := strings.Split(entry, "=")
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("Invalid pinned certificate %s, expected <endpoint>=<hash>", entry)
+		}
+		hash, err := hex.DecodeString(parts[1])
+		if err != nil || len(hash) != sha256.Size {
+			return nil, fmt.Errorf("Invalid hash of pinned certificate %s, expected a hex SHA-256 hash", entry)
+		}
+		pins[parts[0]] = append(pins[parts[0]], hash)
+	}
+	return pins, nil
+}
+
+// verify checks that the peer of endpoint presented, in the TLS handshake
+// of the stream of ctx, a certificate pinned for endpoint. Endpoints
+// without pins are not checked. bound tells whether the handshake of gossip
+// bound the certificate to the identity of the peer, which is required
+// for the pin to authenticate the peer.
+func (pins certPins) verify(ctx context.Context, endpoint string, bound bool) error {
+	hashes, pinned := pins[endpoint]
+	if !pinned {
+		return nil
+	}
+	certHash := extractCertificateHashFromContext(ctx)
+	if certHash == nil || !bound {
+		return fmt.Errorf("%s is pinned but its TLS certificate is not bound to its identity", endpoint)
+	}
+	for _, hash := range hashes {
+		if bytes.Equal(hash, certHash) {
+			return nil
+		}
+	}
+	return fmt.Errorf("TLS certificate of %s, of hash %x, is not pinned", endpoint, certHash)
+}
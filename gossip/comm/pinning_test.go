@@ -0,0 +1,98 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package comm
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+)
+
+func TestGetCertPins(t *testing.T) {
+	defer viper.Set("peer.gossip.pinnedCerts", []string{})
+	hash1 := bytes.Repeat([]byte{1}, 32)
+	hash2 := bytes.Repeat([]byte{2}, 32)
+
+	viper.Set("peer.gossip.pinnedCerts", []string{
+		fmt.Sprintf("peer0:7051=%x", hash1),
+		fmt.Sprintf("peer0:7051=%x", hash2),
+		fmt.Sprintf("peer1:7051=%x", hash2),
+	})
+	pins, err := getCertPins()
+	assert.NoError(t, err)
+	assert.Equal(t, certPins{"peer0:7051": {hash1, hash2}, "peer1:7051": {hash2}}, pins)
+
+	for _, entry := range []string{"peer0:7051", fmt.Sprintf("=%x", hash1), "peer0:7051=zz", "peer0:7051=0102"} {
+		viper.Set("peer.gossip.pinnedCerts", []string{entry})
+		_, err = getCertPins()
+		assert.Error(t, err, entry)
+	}
+
+	// Endpoints without pins are not checked, pinned ones require TLS
+	pins = certPins{"peer0:7051": {hash1}}
+	assert.NoError(t, pins.verify(context.Background(), "peer1:7051", false))
+	assert.Error(t, pins.verify(context.Background(), "peer0:7051", true))
+}
+
+func TestCertPinning(t *testing.T) {
+	t.Parallel()
+	comm1, _ := newCommInstance(8634, naiveSec)
+	defer comm1.Stop()
+	comm2, _ := newCommInstance(8635, naiveSec)
+	defer comm2.Stop()
+	comm3, _ := newCommInstance(8636, naiveSec)
+	defer comm3.Stop()
+
+	comm1.(*commImpl).pins = certPins{
+		"localhost:8635": {comm2.(*commImpl).selfCertHash},
+		"localhost:8636": {comm2.(*commImpl).selfCertHash},
+	}
+
+	// comm2 presents its pinned certificate
+	m2 := comm2.Accept(acceptAll)
+	comm1.Send(createGossipMsg(), remotePeer(8635))
+	select {
+	case <-m2:
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "Didn't receive a message")
+	}
+
+	// comm3 presents a certificate that is not pinned
+	_, err := comm1.(*commImpl).createConnection("localhost:8636", nil)
+	assert.Error(t, err)
+	m3 := comm3.Accept(acceptAll)
+	comm1.Send(createGossipMsg(), remotePeer(8636))
+	select {
+	case <-m3:
+		assert.Fail(t, "Received a message over a connection to a peer that is not pinned")
+	case <-time.After(time.Second):
+	}
+
+	// Pins are not checked on connections opened by remote peers
+	m1 := comm1.Accept(acceptAll)
+	comm3.Send(createGossipMsg(), remotePeer(8634))
+	select {
+	case <-m1:
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "Didn't receive a message")
+	}
+}
@@ -0,0 +1,317 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package comm
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric/gossip/util"
+	"github.com/spf13/viper"
+	"golang.org/x/net/websocket"
+	"google.golang.org/grpc"
+)
+
+const (
+	defTunnelPort = 443
+	defTunnelPath = "/gossip"
+)
+
+// transport is the way connections to a remote peer are established
+type transport int
+
+const (
+	// grpcTransport dials the gossip endpoint of remote peers directly
+	grpcTransport transport = iota
+	// tunnelTransport tunnels gRPC in a WebSocket over HTTP(S),
+	// for peers that can only reach others through web egress
+	tunnelTransport
+)
+
+func (t transport) String() string {
+	if t == tunnelTransport {
+		return "tunnel"
+	}
+	return "grpc"
+}
+
+// tunnelConfig is the configuration of the WebSocket tunnel
+type tunnelConfig struct {
+	// listenAddress is the address tunneled connections are accepted on,
+	// empty if this peer does not accept them
+	listenAddress string
+	// fallback is whether connections are tunneled to remote
+	// peers that cannot be reached via raw gRPC
+	fallback bool
+	// port is the port the tunnel of remote peers is dialed on
+	port int
+	// path is the HTTP path of the tunnel
+	path string
+	// tls is whether the tunnel runs over HTTPS rather than HTTP
+	tls bool
+	// certFile and keyFile are the TLS certificate and key
+	// the tunnel is served with, when tls is set
+	certFile, keyFile string
+	// rootCAs are the roots the TLS certificates of the tunnels of
+	// remote peers are verified against, the system roots if nil
+	rootCAs *x509.CertPool
+	// insecureSkipVerify is whether the TLS certificates of the
+	// tunnels of remote peers are not verified
+	insecureSkipVerify bool
+	// allowedOrigins are the origins, besides the tunnel's
+	// own, tunneled connections are accepted from
+	allowedOrigins []string
+}
+
+func getTunnelConfig() (tunnelConfig, error) {
+	conf := tunnelConfig{
+		listenAddress:      viper.GetString("peer.gossip.tunnel.listenAddress"),
+		fallback:           viper.GetBool("peer.gossip.tunnel.fallback"),
+		port:               util.GetIntOrDefault("peer.gossip.tunnel.port", defTunnelPort),
+		path:               viper.GetString("peer.gossip.tunnel.path"),
+		tls:                viper.GetBool("peer.gossip.tunnel.tls"),
+		certFile:           viper.GetString("peer.tls.cert.file"),
+		keyFile:            viper.GetString("peer.tls.key.file"),
+		insecureSkipVerify: viper.GetBool("peer.gossip.tunnel.insecureSkipVerify"),
+		allowedOrigins:     viper.GetStringSlice("peer.gossip.tunnel.allowedOrigins"),
+	}
+	if conf.path == "" {
+		conf.path = defTunnelPath
+	}
+	if rootCertFile := viper.GetString("peer.tls.rootcert.file"); conf.tls && rootCertFile != "" {
+		rootCert, err := ioutil.ReadFile(rootCertFile)
+		if err != nil {
+			return conf, fmt.Errorf("Failed reading the TLS root certificate of the tunnel: %v", err)
+		}
+		conf.rootCAs = x509.NewCertPool()
+		if !conf.rootCAs.AppendCertsFromPEM(rootCert) {
+			return conf, fmt.Errorf("No certificate found in %s", rootCertFile)
+		}
+	}
+	return conf, nil
+}
+
+// tunnelURL returns the URL of the tunnel of the remote peer of endpoint
+func (conf tunnelConfig) tunnelURL(endpoint string) (string, error) {
+	host, _, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		return "", err
+	}
+	scheme := "ws"
+	if conf.tls {
+		scheme = "wss"
+	}
+	return fmt.Sprintf("%s://%s%s", scheme, net.JoinHostPort(host, strconv.Itoa(conf.port)), conf.path), nil
+}
+
+// tunnelDialOption returns the dial option gRPC connections are
+// tunneled to the remote peer with
+func (conf tunnelConfig) tunnelDialOption() grpc.DialOption {
+	return grpc.WithDialer(func(endpoint string, timeout time.Duration) (net.Conn, error) {
+		url, err := conf.tunnelURL(endpoint)
+		if err != nil {
+			return nil, err
+		}
+		return conf.dialTunnel(url, timeout)
+	})
+}
+
+// tunnelOrigin returns the origin of the tunnel at location,
+// which peers dial it with
+func tunnelOrigin(location *url.URL) string {
+	scheme := "http"
+	if location.Scheme == "wss" {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s", scheme, location.Host)
+}
+
+// dialTunnel opens a WebSocket to tunnelURL, over which gRPC is then spoken
+func (conf tunnelConfig) dialTunnel(tunnelURL string, timeout time.Duration) (net.Conn, error) {
+	location, err := url.Parse(tunnelURL)
+	if err != nil {
+		return nil, err
+	}
+	wsConf, err := websocket.NewConfig(tunnelURL, tunnelOrigin(location))
+	if err != nil {
+		return nil, err
+	}
+
+	var conn net.Conn
+	dialer := &net.Dialer{Timeout: timeout}
+	address := net.JoinHostPort(wsConf.Location.Hostname(), wsConf.Location.Port())
+	if wsConf.Location.Scheme == "wss" {
+		// Remote peers are authenticated by the gRPC handshake tunneled
+		// within, but the tunnel is verified as well, unless configured
+		// otherwise, so that it doesn't expose the handshake to interception
+		conn, err = tls.DialWithDialer(dialer, "tcp", address, &tls.Config{
+			ServerName:         wsConf.Location.Hostname(),
+			RootCAs:            conf.rootCAs,
+			InsecureSkipVerify: conf.insecureSkipVerify,
+		})
+	} else {
+		conn, err = dialer.Dial("tcp", address)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if timeout > 0 {
+		conn.SetDeadline(time.Now().Add(timeout))
+	}
+	ws, err := websocket.NewClient(wsConf, conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	conn.SetDeadline(time.Time{})
+	ws.PayloadType = websocket.BinaryFrame
+	return ws, nil
+}
+
+// tunnelServer accepts tunneled connections and hands them to a gRPC server
+type tunnelServer struct {
+	lsnr     net.Listener
+	conns    chan net.Conn
+	stopChan chan struct{}
+	stopOnce sync.Once
+}
+
+// serveTunnel accepts tunneled connections according to conf
+// and serves gRPC over them with s, until the server is closed
+func serveTunnel(s *grpc.Server, conf tunnelConfig) (*tunnelServer, error) {
+	lsnr, err := net.Listen("tcp", conf.listenAddress)
+	if err != nil {
+		return nil, err
+	}
+	if conf.tls {
+		cert, err := tls.LoadX509KeyPair(conf.certFile, conf.keyFile)
+		if err != nil {
+			lsnr.Close()
+			return nil, fmt.Errorf("Failed loading the TLS certificate of the tunnel: %v", err)
+		}
+		lsnr = tls.NewListener(lsnr, &tls.Config{Certificates: []tls.Certificate{cert}})
+	}
+
+	ts := &tunnelServer{
+		lsnr:     lsnr,
+		conns:    make(chan net.Conn),
+		stopChan: make(chan struct{}),
+	}
+	mux := http.NewServeMux()
+	mux.Handle(conf.path, websocket.Server{
+		Handshake: conf.checkOrigin,
+		Handler:   ts.handle,
+	})
+	go (&http.Server{Handler: mux}).Serve(lsnr)
+	go s.Serve(ts)
+	return ts, nil
+}
+
+// checkOrigin rejects the WebSockets opened from origins other than the
+// tunnel's own, which peers dial it with, and the allowed origins, so that
+// web pages can't open tunnels from the browsers of their visitors
+func (conf tunnelConfig) checkOrigin(wsConf *websocket.Config, req *http.Request) error {
+	origin := req.Header.Get("Origin")
+	for _, allowedOrigin := range conf.allowedOrigins {
+		if origin == allowedOrigin {
+			return nil
+		}
+	}
+	if u, err := url.Parse(origin); err == nil && u.Host != "" && u.Host == req.Host {
+		return nil
+	}
+	return fmt.Errorf("Origin %s not allowed", origin)
+}
+
+// handle hands a tunneled connection to the gRPC server, and holds
+// the WebSocket open until the gRPC server closes the connection
+func (ts *tunnelServer) handle(ws *websocket.Conn) {
+	ws.PayloadType = websocket.BinaryFrame
+	conn := &tunnelConn{Conn: ws, closed: make(chan struct{})}
+	if addr, err := net.ResolveTCPAddr("tcp", ws.Request().RemoteAddr); err == nil {
+		conn.remoteAddr = addr
+	}
+
+	select {
+	case ts.conns <- conn:
+	case <-ts.stopChan:
+		return
+	}
+	select {
+	case <-conn.closed:
+	case <-ts.stopChan:
+	}
+}
+
+// Accept implements net.Listener
+func (ts *tunnelServer) Accept() (net.Conn, error) {
+	select {
+	case conn := <-ts.conns:
+		return conn, nil
+	case <-ts.stopChan:
+		return nil, errors.New("Tunnel closed")
+	}
+}
+
+// Close implements net.Listener
+func (ts *tunnelServer) Close() error {
+	var err error
+	ts.stopOnce.Do(func() {
+		close(ts.stopChan)
+		err = ts.lsnr.Close()
+	})
+	return err
+}
+
+// Addr implements net.Listener
+func (ts *tunnelServer) Addr() net.Addr {
+	return ts.lsnr.Addr()
+}
+
+// tunnelConn is a tunneled connection, whose remote address
+// is the one of the HTTP client rather than its origin
+type tunnelConn struct {
+	*websocket.Conn
+	remoteAddr net.Addr
+	closeOnce  sync.Once
+	closed     chan struct{}
+}
+
+func (conn *tunnelConn) RemoteAddr() net.Addr {
+	if conn.remoteAddr != nil {
+		return conn.remoteAddr
+	}
+	return conn.Conn.RemoteAddr()
+}
+
+func (conn *tunnelConn) Close() error {
+	err := conn.Conn.Close()
+	conn.closeOnce.Do(func() {
+		close(conn.closed)
+	})
+	return err
+}
@@ -0,0 +1,178 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package comm
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/gossip/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTunnelURL(t *testing.T) {
+	conf := tunnelConfig{port: 443, path: "/gossip"}
+	url, err := conf.tunnelURL("peer0.org1.example.com:7051")
+	assert.NoError(t, err)
+	assert.Equal(t, "ws://peer0.org1.example.com:443/gossip", url)
+
+	conf.tls = true
+	url, err = conf.tunnelURL("[::1]:7051")
+	assert.NoError(t, err)
+	assert.Equal(t, "wss://[::1]:443/gossip", url)
+
+	_, err = conf.tunnelURL("peer0")
+	assert.Error(t, err)
+}
+
+func TestTunnelOrigin(t *testing.T) {
+	conf := tunnelConfig{allowedOrigins: []string{"https://proxy.example.com"}}
+	req := func(host, origin string) *http.Request {
+		r := &http.Request{Host: host, Header: http.Header{}}
+		if origin != "" {
+			r.Header.Set("Origin", origin)
+		}
+		return r
+	}
+
+	// Peers dial tunnels with their own origin
+	assert.NoError(t, conf.checkOrigin(nil, req("peer0:443", "https://peer0:443")))
+	assert.NoError(t, conf.checkOrigin(nil, req("peer0:443", "https://proxy.example.com")))
+	assert.Error(t, conf.checkOrigin(nil, req("peer0:443", "https://evil.example.com")))
+	assert.Error(t, conf.checkOrigin(nil, req("peer0:443", "")))
+	assert.Error(t, conf.checkOrigin(nil, req("peer0:443", "null")))
+}
+
+func TestTunnelFallback(t *testing.T) {
+	t.Parallel()
+	testTunnelFallback(t, 8611, 8612, 8613, 8619, false)
+}
+
+func TestTunnelFallbackTLS(t *testing.T) {
+	t.Parallel()
+	testTunnelFallback(t, 8621, 8622, 8623, 8629, true)
+}
+
+// testTunnelFallback has a peer serving a tunnel on tunnelPort reached
+// by another through it, as its raw gRPC endpoint is unreachable
+func testTunnelFallback(t *testing.T, port1, port2, tunnelPort, unreachablePort int, useTLS bool) {
+	conf := tunnelConfig{
+		listenAddress: fmt.Sprintf("localhost:%d", tunnelPort),
+		fallback:      true,
+		port:          tunnelPort,
+		path:          defTunnelPath,
+		tls:           useTLS,
+	}
+	if useTLS {
+		conf.keyFile = fmt.Sprintf("key.%d.pem", tunnelPort)
+		conf.certFile = fmt.Sprintf("cert.%d.pem", tunnelPort)
+		rootCert, err := generateTunnelCertificates(conf.keyFile, conf.certFile)
+		assert.NoError(t, err)
+		defer os.Remove(conf.keyFile)
+		defer os.Remove(conf.certFile)
+		conf.rootCAs = x509.NewCertPool()
+		conf.rootCAs.AddCert(rootCert)
+	}
+
+	comm1, _ := newCommInstance(port1, naiveSec)
+	defer comm1.Stop()
+	inst1 := comm1.(*commImpl)
+	inst1.tunnel = conf
+	assert.NoError(t, inst1.startTunnel(inst1.gSrv))
+
+	comm2, _ := newCommInstance(port2, naiveSec)
+	defer comm2.Stop()
+	inst2 := comm2.(*commImpl)
+	inst2.tunnel = conf
+	inst2.tunnel.listenAddress = ""
+
+	// The endpoint of comm1 is not reachable via raw gRPC, but its tunnel is
+	endpoint := fmt.Sprintf("localhost:%d", unreachablePort)
+	peer := &RemotePeer{Endpoint: endpoint, PKIID: common.PKIidType(fmt.Sprintf("localhost:%d", port1))}
+	assert.NoError(t, comm2.Probe(peer))
+
+	m := comm1.Accept(acceptAll)
+	comm2.Send(createGossipMsg(), peer)
+	select {
+	case msg := <-m:
+		assert.Equal(t, common.PKIidType(fmt.Sprintf("localhost:%d", port2)), msg.GetPKIID())
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "Didn't receive a message through the tunnel")
+	}
+	inst2.transportLock.Lock()
+	assert.Equal(t, tunnelTransport, inst2.transports[endpoint])
+	inst2.transportLock.Unlock()
+
+	// Peers reachable via raw gRPC are not tunneled to
+	assert.NoError(t, comm1.Probe(remotePeer(port2)))
+	assert.Equal(t, grpcTransport, inst1.transports[fmt.Sprintf("localhost:%d", port2)])
+
+	if useTLS {
+		// Tunnels whose certificate can't be verified are not dialed,
+		// unless configured otherwise
+		inst2.tunnel.rootCAs = nil
+		assert.Error(t, comm2.Probe(peer))
+		inst2.tunnel.insecureSkipVerify = true
+		assert.NoError(t, comm2.Probe(peer))
+	}
+
+	// Without fallback, the unreachable endpoint stays unreachable
+	inst2.tunnel.fallback = false
+	assert.Error(t, comm2.Probe(peer))
+}
+
+// generateTunnelCertificates generates a self-signed TLS certificate
+// for localhost, which it returns as the root to verify it against
+func generateTunnelCertificates(privKeyFile string, certKeyFile string) (*x509.Certificate, error) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	template := x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		DNSNames:              []string{"localhost"},
+	}
+	rawBytes, err := x509.CreateCertificate(rand.Reader, &template, &template, &privateKey.PublicKey, privateKey)
+	if err != nil {
+		return nil, err
+	}
+	if err = writeFile(certKeyFile, "CERTIFICATE", rawBytes); err != nil {
+		return nil, err
+	}
+	privBytes, err := x509.MarshalECPrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	if err = writeFile(privKeyFile, "EC PRIVATE KEY", privBytes); err != nil {
+		return nil, err
+	}
+	return x509.ParseCertificate(rawBytes)
+}
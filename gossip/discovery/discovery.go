@@ -17,6 +17,8 @@ limitations under the License.
 package discovery
 
 import (
+	"time"
+
 	"github.com/hyperledger/fabric/gossip/common"
 	proto "github.com/hyperledger/fabric/protos/gossip"
 )
@@ -70,6 +72,14 @@ func (nm NetworkMember) PreferredEndpoint() string {
 	return nm.Endpoint
 }
 
+// MemberState is the state of a member in the view of a discovery module
+type MemberState struct {
+	NetworkMember
+	Alive bool
+	// LastSeen is the last time the member was known to be alive
+	LastSeen time.Time
+}
+
 // Discovery is the interface that represents a discovery module
 type Discovery interface {
 
@@ -92,10 +102,17 @@ type Discovery interface {
 	// GetMembership returns the alive members in the view
 	GetMembership() []NetworkMember
 
+	// MembershipSnapshot returns the alive and dead members in the view
+	MembershipSnapshot() []MemberState
+
 	// InitiateSync makes the instance ask a given number of peers
 	// for their membership information
 	InitiateSync(peerNum int)
 
 	// Connect makes this instance to connect to a remote instance
 	Connect(NetworkMember)
+
+	// ConnectSRV makes this instance resolve the given DNS SRV records periodically,
+	// such as _gossip._tcp.org1.example.com, and connect to the peers they point to
+	ConnectSRV(records []string)
 }
@@ -19,6 +19,7 @@ package discovery
 import (
 	"bytes"
 	"fmt"
+	"net"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -35,6 +36,11 @@ import (
 
 const defaultHelloInterval = time.Duration(5) * time.Second
 
+const defaultSRVRefreshInterval = time.Minute
+
+// lookupSRV resolves DNS SRV records, it is replaced in tests
+var lookupSRV = net.LookupSRV
+
 var aliveExpirationCheckInterval time.Duration
 
 // SetAliveTimeInterval sets the alive time interval
@@ -58,6 +64,11 @@ func SetReconnectInterval(interval time.Duration) {
 	viper.Set("peer.gossip.reconnectInterval", interval)
 }
 
+// SetSRVRefreshInterval sets the interval DNS SRV records are resolved at
+func SetSRVRefreshInterval(interval time.Duration) {
+	viper.Set("peer.gossip.srvRefreshInterval", interval)
+}
+
 type timestamp struct {
 	incTime  time.Time
 	seqNum   uint64
@@ -193,6 +204,71 @@ func (d *gossipDiscoveryImpl) connect2BootstrapPeers(endpoints []string) {
 	}
 }
 
+// ConnectSRV makes this instance resolve the given DNS SRV records periodically,
+// and connect to the peers they point to which are not alive members
+func (d *gossipDiscoveryImpl) ConnectSRV(records []string) {
+	go d.periodicalResolveSRV(records)
+}
+
+func (d *gossipDiscoveryImpl) periodicalResolveSRV(records []string) {
+	defer d.logger.Debug("Stopped")
+
+	for !d.toDie() {
+		for _, endpoint := range d.resolveSRV(records) {
+			if endpoint == d.self.Endpoint || endpoint == d.self.InternalEndpoint || d.isAliveEndpoint(endpoint) {
+				continue
+			}
+			go func(endpoint string) {
+				peer := &NetworkMember{
+					Endpoint:         endpoint,
+					InternalEndpoint: endpoint,
+				}
+				if !d.comm.Ping(peer) {
+					d.logger.Warning("Peer", endpoint, "of the DNS SRV records is not responsive")
+					return
+				}
+				d.comm.SendToPeer(peer, d.createMembershipRequest().NoopSign())
+			}(endpoint)
+		}
+		time.Sleep(getSRVRefreshInterval())
+	}
+}
+
+// resolveSRV returns the endpoints the given DNS SRV records point to
+func (d *gossipDiscoveryImpl) resolveSRV(records []string) []string {
+	var endpoints []string
+	resolved := make(map[string]struct{})
+	for _, record := range records {
+		_, addrs, err := lookupSRV("", "", record)
+		if err != nil {
+			d.logger.Warning("Failed resolving DNS SRV record", record, ":", err)
+			continue
+		}
+		for _, addr := range addrs {
+			endpoint := fmt.Sprintf("%s:%d", strings.TrimSuffix(addr.Target, "."), addr.Port)
+			if _, exists := resolved[endpoint]; exists {
+				continue
+			}
+			resolved[endpoint] = struct{}{}
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	d.logger.Debug("DNS SRV records", records, "resolved to", endpoints)
+	return endpoints
+}
+
+func (d *gossipDiscoveryImpl) isAliveEndpoint(endpoint string) bool {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	for pkiID := range d.aliveLastTS {
+		member := d.id2Member[pkiID]
+		if member != nil && (member.Endpoint == endpoint || member.InternalEndpoint == endpoint) {
+			return true
+		}
+	}
+	return false
+}
+
 func (d *gossipDiscoveryImpl) somePeerIsKnown() bool {
 	d.lock.RLock()
 	defer d.lock.RUnlock()
@@ -818,6 +894,30 @@ func (d *gossipDiscoveryImpl) GetMembership() []NetworkMember {
 
 }
 
+func (d *gossipDiscoveryImpl) MembershipSnapshot() []MemberState {
+	if d.toDie() {
+		return []MemberState{}
+	}
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+
+	snapshot := []MemberState{}
+	for alive, lastSeenMap := range map[bool]map[string]*timestamp{true: d.aliveLastTS, false: d.deadLastTS} {
+		for pkiIDStr, ts := range lastSeenMap {
+			member, exists := d.id2Member[pkiIDStr]
+			if !exists {
+				continue
+			}
+			snapshot = append(snapshot, MemberState{
+				NetworkMember: *member,
+				Alive:         alive,
+				LastSeen:      ts.lastSeen,
+			})
+		}
+	}
+	return snapshot
+}
+
 func tsToTime(ts uint64) time.Time {
 	return time.Unix(int64(0), int64(ts))
 }
@@ -889,6 +989,10 @@ func getReconnectInterval() time.Duration {
 	return util.GetDurationOrDefault("peer.gossip.reconnectInterval", getAliveExpirationTimeout())
 }
 
+func getSRVRefreshInterval() time.Duration {
+	return util.GetDurationOrDefault("peer.gossip.srvRefreshInterval", defaultSRVRefreshInterval)
+}
+
 func filterOutLocalhost(endpoints []string, port int) []string {
 	var returnedEndpoints []string
 	for _, endpoint := range endpoints {
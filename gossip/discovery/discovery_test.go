@@ -27,6 +27,7 @@ import (
 	"time"
 
 	"github.com/hyperledger/fabric/gossip/common"
+	"github.com/hyperledger/fabric/gossip/util"
 	proto "github.com/hyperledger/fabric/protos/gossip"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
@@ -309,6 +310,53 @@ func TestConnect(t *testing.T) {
 	stopInstances(t, instances)
 }
 
+func TestConnectSRV(t *testing.T) {
+	SetSRVRefreshInterval(time.Millisecond * 100)
+	lookupSRV = func(service, proto, name string) (string, []*net.SRV, error) {
+		if name != "_gossip._tcp.org1.example.com" {
+			return "", nil, fmt.Errorf("no such host")
+		}
+		var addrs []*net.SRV
+		for i := 0; i < 4; i++ {
+			addrs = append(addrs, &net.SRV{Target: "localhost.", Port: uint16(12611 + i)})
+		}
+		return name, addrs, nil
+	}
+	defer func() { lookupSRV = net.LookupSRV }()
+
+	instances := []*gossipInstance{}
+	for i := 0; i < 4; i++ {
+		instances = append(instances, createDiscoveryInstance(12611+i, fmt.Sprintf("d%d", i), []string{}))
+	}
+	instances[0].ConnectSRV([]string{"_gossip._tcp.org2.example.com", "_gossip._tcp.org1.example.com"})
+
+	fullMembership := func() bool {
+		for _, inst := range instances {
+			if len(inst.GetMembership()) != 3 {
+				return false
+			}
+		}
+		return true
+	}
+	waitUntilOrFail(t, fullMembership)
+	stopInstances(t, instances)
+}
+
+func TestResolveSRV(t *testing.T) {
+	lookupSRV = func(service, proto, name string) (string, []*net.SRV, error) {
+		return name, []*net.SRV{
+			{Target: "peer0.org1.example.com.", Port: 7051},
+			{Target: "peer1.org1.example.com.", Port: 7051},
+			{Target: "peer0.org1.example.com.", Port: 7051},
+		}, nil
+	}
+	defer func() { lookupSRV = net.LookupSRV }()
+
+	d := &gossipDiscoveryImpl{logger: util.GetLogger(util.LoggingDiscoveryModule, "")}
+	endpoints := d.resolveSRV([]string{"_gossip._tcp.org1.example.com"})
+	assert.Equal(t, []string{"peer0.org1.example.com:7051", "peer1.org1.example.com:7051"}, endpoints)
+}
+
 func TestUpdate(t *testing.T) {
 	t.Parallel()
 	nodeNum := 5
@@ -426,6 +474,37 @@ func TestExpiration(t *testing.T) {
 	waitUntilOrFailBlocking(t, stopAction.Wait)
 }
 
+func TestMembershipSnapshot(t *testing.T) {
+	t.Parallel()
+	bootPeers := []string{bootPeer(6711)}
+	instances := []*gossipInstance{}
+	for i := 1; i <= 3; i++ {
+		instances = append(instances, createDiscoveryInstance(6710+i, fmt.Sprintf("d%d", i), bootPeers))
+	}
+	assertMembership(t, instances, 2)
+
+	start := time.Now()
+	waitUntilOrFailBlocking(t, instances[2].Stop)
+	assertMembership(t, instances[:2], 1)
+
+	snapshot := instances[0].MembershipSnapshot()
+	assert.Len(t, snapshot, 2)
+	for _, member := range snapshot {
+		switch member.Endpoint {
+		case "localhost:6712":
+			assert.True(t, member.Alive)
+			assert.True(t, member.LastSeen.After(start))
+		case "localhost:6713":
+			assert.False(t, member.Alive)
+			assert.True(t, member.LastSeen.Before(time.Now().Add(-getAliveExpirationTimeout())))
+		default:
+			t.Fatal("Unexpected member", member.Endpoint)
+		}
+	}
+
+	stopInstances(t, instances[:2])
+}
+
 func TestGetFullMembership(t *testing.T) {
 	t.Parallel()
 	nodeNum := 15
@@ -109,10 +109,53 @@ type LeaderElectionService interface {
 	// IsLeader returns whether this peer is a leader or not
 	IsLeader() bool
 
+	// State returns the state of the election, as seen by this peer
+	State() LeadershipState
+
+	// SetOverride manually overrides the election, or ends
+	// the override in effect if o is NoOverride
+	SetOverride(o Override)
+
 	// Stop stops the LeaderElectionService
 	Stop()
 }
 
+// Override is a manual override of the leader election, used
+// by operators during the maintenance of the leader peer
+type Override int
+
+const (
+	// NoOverride means that the peer takes part in the election
+	NoOverride Override = iota
+	// Relinquished means that the peer gives up the leadership,
+	// if it is the leader, and abstains from the election
+	Relinquished
+	// Acquired means that the peer becomes the leader, and does not
+	// step down for peers with lower IDs declaring themselves leaders
+	Acquired
+)
+
+var overrideNames = []string{"none", "relinquished", "acquired"}
+
+func (o Override) String() string {
+	if o < 0 || int(o) >= len(overrideNames) {
+		return fmt.Sprintf("Override(%d)", int(o))
+	}
+	return overrideNames[o]
+}
+
+// LeadershipState is the state of the leader election, as seen by a peer
+type LeadershipState struct {
+	// IsLeader is whether the peer is the leader
+	IsLeader bool
+	// Leader is the ID of the current leader, empty if no leader is known
+	Leader string
+	// Term is incremented every time the leader known to the peer changes
+	Term uint64
+	// Override is the manual override in effect
+	Override Override
+}
+
 // Peer describes a remote peer
 type Peer interface {
 	// ID returns the ID of the peer
@@ -170,6 +213,10 @@ type leaderElectionSvcImpl struct {
 	adapter       LeaderElectionAdapter
 	logger        *logging.Logger
 	callback      leadershipCallback
+	leaderID      string    // ID of the current leader, empty if unknown
+	leaderSeen    time.Time // last time the current leader declared itself
+	term          uint64
+	override      Override
 }
 
 func (le *leaderElectionSvcImpl) start() {
@@ -215,9 +262,12 @@ func (le *leaderElectionSvcImpl) handleMessage(msg Msg) {
 		if le.sleeping && len(le.interruptChan) == 0 {
 			le.interruptChan <- struct{}{}
 		}
-		if msg.SenderID() < le.id && le.IsLeader() {
+		if msg.SenderID() < le.id && le.IsLeader() && le.override != Acquired {
 			le.stopBeingLeader()
 		}
+		if !le.IsLeader() {
+			le.setLeader(msg.SenderID())
+		}
 	} else {
 		// We shouldn't get here
 		le.logger.Error("Got a message that's not a proposal and not a declaration")
@@ -269,6 +319,20 @@ func (le *leaderElectionSvcImpl) run() {
 func (le *leaderElectionSvcImpl) leaderElection() {
 	le.logger.Info(le.id, ": Entering")
 	defer le.logger.Info(le.id, ": Exiting")
+	switch le.getOverride() {
+	case Relinquished:
+		le.logger.Info(le.id, ": Abstaining from the election")
+		le.waitForInterrupt(leaderElectionDuration)
+		return
+	case Acquired:
+		le.Lock()
+		if !le.IsLeader() {
+			le.beLeader()
+		}
+		le.Unlock()
+		atomic.StoreInt32(&le.leaderExists, int32(1))
+		return
+	}
 	le.propose()
 	le.waitForInterrupt(leaderElectionDuration)
 	// If someone declared itself as a leader, give up
@@ -287,7 +351,11 @@ func (le *leaderElectionSvcImpl) leaderElection() {
 	}
 	// If we got here, there is no one that proposed being a leader
 	// that's a better candidate than us.
-	le.beLeader()
+	le.Lock()
+	if le.override == NoOverride {
+		le.beLeader()
+	}
+	le.Unlock()
 	atomic.StoreInt32(&le.leaderExists, int32(1))
 }
 
@@ -313,6 +381,9 @@ func (le *leaderElectionSvcImpl) follower() {
 }
 
 func (le *leaderElectionSvcImpl) leader() {
+	le.Lock()
+	le.setLeader(le.id)
+	le.Unlock()
 	leaderDeclaration := le.adapter.CreateMessage(true)
 	le.adapter.Gossip(leaderDeclaration)
 	le.waitForInterrupt(leadershipDeclarationInterval)
@@ -364,18 +435,76 @@ func (le *leaderElectionSvcImpl) IsLeader() bool {
 	return isLeader
 }
 
+// beLeader makes this peer the leader, the lock must be held
 func (le *leaderElectionSvcImpl) beLeader() {
 	le.logger.Info(le.id, ": Becoming a leader")
 	atomic.StoreInt32(&le.isLeader, int32(1))
+	le.setLeader(le.id)
 	le.callback(true)
 }
 
+// stopBeingLeader makes this peer a follower, the lock must be held
 func (le *leaderElectionSvcImpl) stopBeingLeader() {
 	le.logger.Info(le.id, "Stopped being a leader")
 	atomic.StoreInt32(&le.isLeader, int32(0))
+	if le.leaderID == le.id {
+		le.leaderID = ""
+	}
 	le.callback(false)
 }
 
+// setLeader records id as the current leader, the lock must be held
+func (le *leaderElectionSvcImpl) setLeader(id string) {
+	if id != le.leaderID {
+		le.leaderID = id
+		le.term++
+	}
+	le.leaderSeen = time.Now()
+}
+
+func (le *leaderElectionSvcImpl) getOverride() Override {
+	le.Lock()
+	defer le.Unlock()
+	return le.override
+}
+
+// State returns the state of the election, as seen by this peer
+func (le *leaderElectionSvcImpl) State() LeadershipState {
+	le.Lock()
+	defer le.Unlock()
+	state := LeadershipState{
+		IsLeader: le.IsLeader(),
+		Leader:   le.leaderID,
+		Term:     le.term,
+		Override: le.override,
+	}
+	// A leader that stopped declaring itself is no longer known
+	if !state.IsLeader && time.Since(le.leaderSeen) > leaderAliveThreshold {
+		state.Leader = ""
+	}
+	return state
+}
+
+// SetOverride manually overrides the election, or ends
+// the override in effect if o is NoOverride
+func (le *leaderElectionSvcImpl) SetOverride(o Override) {
+	le.logger.Info(le.id, ": Overriding the election with", o)
+	le.Lock()
+	defer le.Unlock()
+	le.override = o
+	switch o {
+	case Relinquished:
+		if le.IsLeader() {
+			le.stopBeingLeader()
+		}
+	case Acquired:
+		if !le.IsLeader() {
+			le.beLeader()
+		}
+		atomic.StoreInt32(&le.leaderExists, int32(1))
+	}
+}
+
 func (le *leaderElectionSvcImpl) shouldStop() bool {
 	return atomic.LoadInt32(&le.toDie) == int32(1)
 }
@@ -343,3 +343,75 @@ func TestPartition(t *testing.T) {
 	}
 
 }
+
+func TestLeadershipOverride(t *testing.T) {
+	t.Parallel()
+	// Scenario: p0 is elected, and is then maintained. It relinquishes
+	// the leadership, which p1 takes over. Then p2 acquires the
+	// leadership, and p1 relinquishes it as well.
+	// Once the overrides end, p2 stays the leader as the
+	// election is not re-run while a leader is known.
+	peers := createPeers(0, 2, 1, 0)
+	p2, p1, p0 := peers[0], peers[1], peers[2]
+	leaders := waitForLeaderElection(t, peers)
+	assert.Equal(t, []string{"p0"}, leaders)
+	waitForState(t, p1, func(s LeadershipState) bool { return s.Leader == "p0" })
+	term := p1.State().Term
+	assert.True(t, p0.State().IsLeader)
+	assert.Equal(t, "p0", p0.State().Leader)
+
+	p0.SetOverride(Relinquished)
+	assert.False(t, p0.IsLeader())
+	assert.False(t, p0.isLeaderFromCallback)
+	waitForLeaders(t, peers, "p1")
+	waitForState(t, p0, func(s LeadershipState) bool { return s.Leader == "p1" })
+	state := p2.State()
+	assert.Equal(t, "p1", state.Leader)
+	assert.Equal(t, term+1, state.Term)
+	assert.Equal(t, Relinquished, p0.State().Override)
+
+	p2.SetOverride(Acquired)
+	assert.True(t, p2.IsLeader())
+	assert.True(t, p2.isLeaderFromCallback)
+	p1.SetOverride(Relinquished)
+	waitForLeaders(t, peers, "p2")
+	time.Sleep(leaderAliveThreshold)
+	waitForLeaders(t, peers, "p2")
+	waitForState(t, p0, func(s LeadershipState) bool { return s.Leader == "p2" })
+
+	for _, p := range peers {
+		p.SetOverride(NoOverride)
+	}
+	time.Sleep(leadershipDeclarationInterval + leaderAliveThreshold*2)
+	waitForLeaders(t, peers, "p2")
+	assert.Equal(t, NoOverride, p2.State().Override)
+}
+
+func waitForLeaders(t *testing.T, peers []*peer, expected ...string) {
+	end := time.Now().Add(testTimeout)
+	var leaders []string
+	for time.Now().Before(end) {
+		leaders = nil
+		for _, p := range peers {
+			if p.IsLeader() {
+				leaders = append(leaders, p.id)
+			}
+		}
+		if assert.ObjectsAreEqual(expected, leaders) {
+			return
+		}
+		time.Sleep(testPollInterval)
+	}
+	t.Fatalf("Expected leaders %v, got %v", expected, leaders)
+}
+
+func waitForState(t *testing.T, p *peer, cond func(LeadershipState) bool) {
+	end := time.Now().Add(testTimeout)
+	for time.Now().Before(end) {
+		if cond(p.State()) {
+			return
+		}
+		time.Sleep(testPollInterval)
+	}
+	t.Fatalf("Unexpected state of %s: %+v", p.id, p.State())
+}
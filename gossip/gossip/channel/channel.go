@@ -43,6 +43,7 @@ type Config struct {
 	MaxBlockCountToStore     int
 	PullPeerNum              int
 	PullInterval             time.Duration
+	PullFilterFPRate         float64
 	RequestStateInfoInterval time.Duration
 }
 
@@ -248,6 +249,7 @@ func (gc *gossipChannel) createBlockPuller() pull.Mediator {
 		PeerCountToSelect: gc.GetConf().PullPeerNum,
 		PullInterval:      gc.GetConf().PullInterval,
 		Tag:               proto.GossipMessage_CHAN_AND_ORG,
+		FilterFPRate:      gc.GetConf().PullFilterFPRate,
 	}
 	seqNumFromMsg := func(msg *proto.SignedGossipMessage) string {
 		dataMsg := msg.GetDataMsg()
@@ -85,13 +85,19 @@ func (ga *gossipAdapterImpl) GetConf() channel.Config {
 		PublishStateInfoInterval: ga.conf.PublishStateInfoInterval,
 		PullInterval:             ga.conf.PullInterval,
 		PullPeerNum:              ga.conf.PullPeerNum,
+		PullFilterFPRate:         ga.conf.PullFilterFPRate,
 		RequestStateInfoInterval: ga.conf.RequestStateInfoInterval,
 	}
 }
 
+// GetMembership returns the alive members in the view which are not quarantined
+func (ga *gossipAdapterImpl) GetMembership() []discovery.NetworkMember {
+	return ga.gossipServiceImpl.membership()
+}
+
 // Gossip gossips a message
 func (ga *gossipAdapterImpl) Gossip(msg *proto.SignedGossipMessage) {
-	ga.gossipServiceImpl.emitter.Add(msg)
+	ga.gossipServiceImpl.forward(msg)
 }
 
 func (ga *gossipAdapterImpl) Send(msg *proto.SignedGossipMessage, peers ...*comm.RemotePeer) {
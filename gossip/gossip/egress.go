@@ -0,0 +1,176 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gossip
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+
+	"github.com/hyperledger/fabric/gossip/api"
+	"github.com/hyperledger/fabric/gossip/comm"
+	"github.com/hyperledger/fabric/gossip/common"
+	proto "github.com/hyperledger/fabric/protos/gossip"
+	"github.com/op/go-logging"
+)
+
+// EgressFilter decides whether messages may be sent to remote peers.
+// Egress filters are evaluated before any message leaves the peer,
+// including the responses to the requests of remote peers.
+type EgressFilter interface {
+	// Permit returns nil if msg may be sent to peer,
+	// or else the reason it may not
+	Permit(msg *proto.GossipMessage, peer EgressPeer) error
+}
+
+// EgressFilterFunc is an EgressFilter implemented by a function
+type EgressFilterFunc func(msg *proto.GossipMessage, peer EgressPeer) error
+
+// Permit returns f(msg, peer)
+func (f EgressFilterFunc) Permit(msg *proto.GossipMessage, peer EgressPeer) error {
+	return f(msg, peer)
+}
+
+// EgressPeer is a remote peer a message is about to be sent to
+type EgressPeer struct {
+	PKIID    common.PKIidType
+	Endpoint string
+	// Org is the organization of the peer, or nil if it is not known yet
+	Org api.OrgIdentityType
+}
+
+// OrgEgressFilter returns an EgressFilter which permits the messages
+// selector accepts to be sent only to the peers of orgs. The selector
+// is passed the *proto.GossipMessage about to be sent.
+func OrgEgressFilter(selector common.MessageAcceptor, orgs ...api.OrgIdentityType) EgressFilter {
+	return EgressFilterFunc(func(msg *proto.GossipMessage, peer EgressPeer) error {
+		if !selector(msg) {
+			return nil
+		}
+		for _, org := range orgs {
+			if bytes.Equal(org, peer.Org) {
+				return nil
+			}
+		}
+		return fmt.Errorf("Organization %s of peer %s is not permitted", string(peer.Org), peer.Endpoint)
+	})
+}
+
+// egressComm evaluates the egress filters of the gossip
+// instance before messages are sent through the Comm it wraps
+type egressComm struct {
+	comm.Comm
+	logger *logging.Logger
+	// orgOfPeer returns the organization of the peer of a PKI-ID, or nil
+	orgOfPeer func(common.PKIidType) api.OrgIdentityType
+
+	lock    sync.RWMutex
+	filters []EgressFilter
+
+	stopOnce sync.Once
+	stopChan chan struct{}
+}
+
+func newEgressComm(c comm.Comm, orgOfPeer func(common.PKIidType) api.OrgIdentityType, logger *logging.Logger) *egressComm {
+	return &egressComm{Comm: c, orgOfPeer: orgOfPeer, logger: logger, stopChan: make(chan struct{})}
+}
+
+func (c *egressComm) addFilter(filter EgressFilter) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.filters = append(c.filters, filter)
+}
+
+// permit returns whether msg may be sent to peer
+func (c *egressComm) permit(msg *proto.GossipMessage, peer *comm.RemotePeer) bool {
+	c.lock.RLock()
+	filters := c.filters
+	c.lock.RUnlock()
+	if len(filters) == 0 {
+		return true
+	}
+
+	egressPeer := EgressPeer{PKIID: peer.PKIID, Endpoint: peer.Endpoint, Org: c.orgOfPeer(peer.PKIID)}
+	for _, filter := range filters {
+		if err := filter.Permit(msg, egressPeer); err != nil {
+			c.logger.Debug("Not sending", msg, "to", peer, ":", err)
+			return false
+		}
+	}
+	return true
+}
+
+// Send sends msg to the peers the egress filters permit
+func (c *egressComm) Send(msg *proto.SignedGossipMessage, peers ...*comm.RemotePeer) {
+	var permitted []*comm.RemotePeer
+	for _, peer := range peers {
+		if c.permit(msg.GossipMessage, peer) {
+			permitted = append(permitted, peer)
+		}
+	}
+	if len(permitted) == 0 {
+		return
+	}
+	c.Comm.Send(msg, permitted...)
+}
+
+// Accept returns the messages of the Comm it wraps, whose
+// responses are sent only if the egress filters permit it
+func (c *egressComm) Accept(acceptor common.MessageAcceptor) <-chan proto.ReceivedMessage {
+	in := c.Comm.Accept(acceptor)
+	out := make(chan proto.ReceivedMessage, cap(in))
+	go func() {
+		defer close(out)
+		for {
+			select {
+			case msg, ok := <-in:
+				if !ok {
+					return
+				}
+				select {
+				case out <- &egressReceivedMessage{ReceivedMessage: msg, c: c}:
+				case <-c.stopChan:
+					return
+				}
+			case <-c.stopChan:
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// Stop stops the Comm it wraps
+func (c *egressComm) Stop() {
+	c.stopOnce.Do(func() {
+		close(c.stopChan)
+	})
+	c.Comm.Stop()
+}
+
+type egressReceivedMessage struct {
+	proto.ReceivedMessage
+	c *egressComm
+}
+
+// Respond sends msg to the sender of the message, if the egress filters permit it
+func (m *egressReceivedMessage) Respond(msg *proto.GossipMessage) {
+	sender := &comm.RemotePeer{PKIID: m.GetPKIID()}
+	if m.c.permit(msg, sender) {
+		m.ReceivedMessage.Respond(msg)
+	}
+}
@@ -0,0 +1,154 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gossip
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/gossip/api"
+	"github.com/hyperledger/fabric/gossip/comm"
+	"github.com/hyperledger/fabric/gossip/common"
+	"github.com/hyperledger/fabric/gossip/util"
+	proto "github.com/hyperledger/fabric/protos/gossip"
+	"github.com/stretchr/testify/assert"
+)
+
+type egressCommMock struct {
+	comm.Comm
+	sent     chan []*comm.RemotePeer
+	incoming chan proto.ReceivedMessage
+	stopped  bool
+}
+
+func (c *egressCommMock) Send(msg *proto.SignedGossipMessage, peers ...*comm.RemotePeer) {
+	c.sent <- peers
+}
+
+func (c *egressCommMock) Accept(common.MessageAcceptor) <-chan proto.ReceivedMessage {
+	return c.incoming
+}
+
+func (c *egressCommMock) Stop() {
+	c.stopped = true
+}
+
+type egressReceivedMessageMock struct {
+	proto.ReceivedMessage
+	sender    common.PKIidType
+	responses chan *proto.GossipMessage
+}
+
+func (m *egressReceivedMessageMock) GetPKIID() common.PKIidType {
+	return m.sender
+}
+
+func (m *egressReceivedMessageMock) Respond(msg *proto.GossipMessage) {
+	m.responses <- msg
+}
+
+func newEgressCommMock() (*egressComm, *egressCommMock) {
+	mock := &egressCommMock{
+		sent:     make(chan []*comm.RemotePeer, 1),
+		incoming: make(chan proto.ReceivedMessage, 1),
+	}
+	orgs := map[string]api.OrgIdentityType{"p1": api.OrgIdentityType("ORG1"), "p2": api.OrgIdentityType("ORG2")}
+	orgOfPeer := func(pkiID common.PKIidType) api.OrgIdentityType {
+		return orgs[string(pkiID)]
+	}
+	return newEgressComm(mock, orgOfPeer, util.GetLogger(util.LoggingGossipModule, "")), mock
+}
+
+func TestEgressFilters(t *testing.T) {
+	c, mock := newEgressCommMock()
+	p1 := &comm.RemotePeer{PKIID: common.PKIidType("p1"), Endpoint: "p1:7051"}
+	p2 := &comm.RemotePeer{PKIID: common.PKIidType("p2"), Endpoint: "p2:7051"}
+	p3 := &comm.RemotePeer{PKIID: common.PKIidType("p3"), Endpoint: "p3:7051"}
+	dataMsg := createDataMsg(1, []byte{}, "", common.ChainID("A")).NoopSign()
+	emptyMsg := createEmptyMsg().NoopSign()
+
+	// Without filters, messages are sent to all peers
+	c.Send(dataMsg, p1, p2, p3)
+	assert.Equal(t, []*comm.RemotePeer{p1, p2, p3}, <-mock.sent)
+
+	// Blocks are sent only to ORG1
+	c.addFilter(OrgEgressFilter(func(msg interface{}) bool {
+		return msg.(*proto.GossipMessage).IsDataMsg()
+	}, api.OrgIdentityType("ORG1")))
+	c.Send(dataMsg, p1, p2, p3)
+	assert.Equal(t, []*comm.RemotePeer{p1}, <-mock.sent)
+	c.Send(emptyMsg, p1, p2, p3)
+	assert.Equal(t, []*comm.RemotePeer{p1, p2, p3}, <-mock.sent)
+
+	// All filters must permit a message
+	c.addFilter(EgressFilterFunc(func(msg *proto.GossipMessage, peer EgressPeer) error {
+		if peer.Endpoint == "p1:7051" {
+			return errors.New("p1 is denied")
+		}
+		return nil
+	}))
+	c.Send(emptyMsg, p1, p2, p3)
+	assert.Equal(t, []*comm.RemotePeer{p2, p3}, <-mock.sent)
+
+	// Nothing is sent if no peer is permitted
+	c.Send(dataMsg, p1, p2, p3)
+	select {
+	case peers := <-mock.sent:
+		assert.Fail(t, "Message should not have been sent", peers)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestEgressFiltersOnResponses(t *testing.T) {
+	c, mock := newEgressCommMock()
+	c.addFilter(OrgEgressFilter(func(msg interface{}) bool {
+		return msg.(*proto.GossipMessage).IsDataMsg()
+	}, api.OrgIdentityType("ORG1")))
+	incoming := c.Accept(func(interface{}) bool { return true })
+
+	respond := func(sender string, msg *proto.GossipMessage) *proto.GossipMessage {
+		responses := make(chan *proto.GossipMessage, 1)
+		mock.incoming <- &egressReceivedMessageMock{sender: common.PKIidType(sender), responses: responses}
+		(<-incoming).Respond(msg)
+		select {
+		case response := <-responses:
+			return response
+		case <-time.After(100 * time.Millisecond):
+			return nil
+		}
+	}
+
+	dataMsg := createDataMsg(1, []byte{}, "", common.ChainID("A"))
+	assert.Equal(t, dataMsg, respond("p1", dataMsg))
+	assert.Nil(t, respond("p2", dataMsg))
+	emptyMsg := createEmptyMsg()
+	assert.Equal(t, emptyMsg, respond("p2", emptyMsg))
+
+	// Stopping closes the channel of incoming messages
+	c.Stop()
+	assert.True(t, mock.stopped)
+	_, open := <-incoming
+	assert.False(t, open)
+}
+
+func createEmptyMsg() *proto.GossipMessage {
+	return &proto.GossipMessage{
+		Tag:     proto.GossipMessage_EMPTY,
+		Content: &proto.GossipMessage_Empty{Empty: &proto.Empty{}},
+	}
+}
@@ -25,6 +25,7 @@ import (
 	"github.com/hyperledger/fabric/gossip/comm"
 	"github.com/hyperledger/fabric/gossip/common"
 	"github.com/hyperledger/fabric/gossip/discovery"
+	"github.com/hyperledger/fabric/gossip/reputation"
 	proto "github.com/hyperledger/fabric/protos/gossip"
 )
 
@@ -41,6 +42,10 @@ type Gossip interface {
 	// and also subscribed to the channel given
 	PeersOfChannel(common.ChainID) []discovery.NetworkMember
 
+	// MembershipSnapshot returns the membership of this peer, along
+	// with the alive and dead peers in its membership view
+	MembershipSnapshot() (discovery.NetworkMember, []discovery.MemberState)
+
 	// UpdateMetadata updates the self metadata of the discovery layer
 	// the peer publishes to other peers
 	UpdateMetadata(metadata []byte)
@@ -61,6 +66,10 @@ type Gossip interface {
 	// JoinChan makes the Gossip instance join a channel
 	JoinChan(joinMsg api.JoinChannelMessage, chainID common.ChainID)
 
+	// AddEgressFilter registers a filter evaluated before any message is sent to
+	// a remote peer. Messages are sent only to the peers all filters permit.
+	AddEgressFilter(filter EgressFilter)
+
 	// Stop stops the gossip component
 	Stop()
 }
@@ -70,6 +79,7 @@ type Config struct {
 	BindPort            int      // Port we bind to, used only for tests
 	ID                  string   // ID of this instance
 	BootstrapPeers      []string // Peers we connect to at startup
+	BootstrapSRV        []string // DNS SRV records of peers, resolved periodically to connect to them
 	PropagateIterations int      // Number of times a message is pushed to remote peers
 	PropagatePeerNum    int      // Number of peers selected to push messages to
 
@@ -82,6 +92,8 @@ type Config struct {
 	PullInterval time.Duration // Determines frequency of pull phases
 	PullPeerNum  int           // Number of peers to pull from
 
+	PullFilterFPRate float64 // False positive rate of the Bloom filters of pull hellos, 0 disables them
+
 	SkipBlockVerification bool // Should we skip verifying block messages or not
 
 	PublishCertPeriod        time.Duration    // Time from startup certificates are included in Alive messages
@@ -91,4 +103,8 @@ type Config struct {
 
 	InternalEndpoint string // Endpoint we publish to peers in our organization
 	ExternalEndpoint string // Peer publishes this endpoint instead of SelfEndpoint to foreign organizations
+
+	Reputation reputation.Config // Scoring of remote peers according to their misbehaviors
+
+	MaxHops HopLimits // Number of hops messages of each class travel from the peer that created them
 }
@@ -36,6 +36,7 @@ import (
 	"github.com/hyperledger/fabric/gossip/gossip/msgstore"
 	"github.com/hyperledger/fabric/gossip/gossip/pull"
 	"github.com/hyperledger/fabric/gossip/identity"
+	"github.com/hyperledger/fabric/gossip/reputation"
 	"github.com/hyperledger/fabric/gossip/util"
 	proto "github.com/hyperledger/fabric/protos/gossip"
 	"github.com/op/go-logging"
@@ -73,6 +74,8 @@ type gossipServiceImpl struct {
 	mcs               api.MessageCryptoService
 	aliveMsgStore     msgstore.MessageStore
 	stateInfoMsgStore msgstore.MessageStore
+	reputation        *reputation.Tracker
+	egress            *egressComm
 }
 
 // NewGossipService creates a gossip instance attached to a gRPC server
@@ -101,7 +104,6 @@ func NewGossipService(conf *Config, s *grpc.Server, secAdvisor api.SecurityAdvis
 		idMapper:              idMapper,
 		disc:                  nil,
 		mcs:                   mcs,
-		comm:                  c,
 		conf:                  conf,
 		ChannelDeMultiplexer:  comm.NewChannelDemultiplexer(),
 		logger:                lgr,
@@ -109,7 +111,10 @@ func NewGossipService(conf *Config, s *grpc.Server, secAdvisor api.SecurityAdvis
 		stopFlag:              int32(0),
 		stopSignal:            &sync.WaitGroup{},
 		includeIdentityPeriod: time.Now().Add(conf.PublishCertPeriod),
+		reputation:            reputation.NewTracker(conf.Reputation),
 	}
+	g.egress = newEgressComm(c, g.orgOfPeer, lgr)
+	g.comm = g.egress
 
 	g.aliveMsgStore = msgstore.NewMessageStore(proto.NewGossipMessageComparator(0), func(m interface{}) {})
 
@@ -122,6 +127,9 @@ func NewGossipService(conf *Config, s *grpc.Server, secAdvisor api.SecurityAdvis
 	g.disSecAdap = g.newDiscoverySecurityAdapter()
 
 	g.disc = discovery.NewDiscoveryService(conf.BootstrapPeers, g.selfNetworkMember(), g.discAdapter, g.disSecAdap)
+	if len(conf.BootstrapSRV) != 0 {
+		g.disc.ConnectSRV(conf.BootstrapSRV)
+	}
 	g.logger.Info("Creating gossip service with self membership of", g.selfNetworkMember())
 
 	g.certStore = newCertStore(g.createCertStorePuller(), idMapper, selfIdentity, mcs)
@@ -205,6 +213,7 @@ func (g *gossipServiceImpl) handlePresumedDead() {
 			g.toDieChan <- s
 			return
 		case deadEndpoint := <-g.comm.PresumedDead():
+			g.reputation.Report(deadEndpoint, reputation.Timeout)
 			g.presumedDead <- deadEndpoint
 			break
 		}
@@ -296,7 +305,7 @@ func (g *gossipServiceImpl) handleMessage(m proto.ReceivedMessage) {
 		if !added {
 			return
 		}
-		g.emitter.Add(msg)
+		g.forward(msg)
 	}
 
 	if msg.IsChannelRestricted() {
@@ -304,7 +313,7 @@ func (g *gossipServiceImpl) handleMessage(m proto.ReceivedMessage) {
 			// If we're not in the channel but we should forward to peers of our org
 			if g.isInMyorg(discovery.NetworkMember{PKIid: m.GetPKIID()}) && msg.IsStateInfoMsg() {
 				if g.stateInfoMsgStore.Add(msg) {
-					g.emitter.Add(msg)
+					g.forward(msg)
 				}
 			}
 			if !g.toDie() {
@@ -343,6 +352,7 @@ func (g *gossipServiceImpl) forwardDiscoveryMsg(msg proto.ReceivedMessage) {
 func (g *gossipServiceImpl) validateMsg(msg proto.ReceivedMessage) bool {
 	if err := msg.GetGossipMessage().IsTagLegal(); err != nil {
 		g.logger.Warning("Tag of", msg.GetGossipMessage(), "isn't legal:", err)
+		g.reputation.Report(msg.GetPKIID(), reputation.InvalidMessage)
 		return false
 	}
 
@@ -356,6 +366,7 @@ func (g *gossipServiceImpl) validateMsg(msg proto.ReceivedMessage) bool {
 		blockMsg := msg.GetGossipMessage().GetDataMsg()
 		if blockMsg.Payload == nil {
 			g.logger.Warning("Empty block! Discarding it")
+			g.reputation.Report(msg.GetPKIID(), reputation.InvalidMessage)
 			return false
 		}
 
@@ -366,6 +377,7 @@ func (g *gossipServiceImpl) validateMsg(msg proto.ReceivedMessage) bool {
 
 		if err := g.mcs.VerifyBlock(msg.GetGossipMessage().Channel, blockMsg); err != nil {
 			g.logger.Warning("Could not verify block", blockMsg.Payload.SeqNum, ":", err)
+			g.reputation.Report(msg.GetPKIID(), reputation.FailedVerification)
 			return false
 		}
 	}
@@ -450,13 +462,13 @@ func (g *gossipServiceImpl) gossipBatch(msgs []*proto.SignedGossipMessage) {
 
 	// Gossip messages restricted to our org
 	orgMsgs, msgs = partitionMessages(isOrgRestricted, msgs)
-	peers2Send := filter.SelectPeers(g.conf.PropagatePeerNum, g.disc.GetMembership(), g.isInMyorg)
+	peers2Send := g.selectPeers(g.conf.PropagatePeerNum, g.disc.GetMembership(), g.isInMyorg)
 	for _, msg := range orgMsgs {
 		g.comm.Send(msg, peers2Send...)
 	}
 
 	// Finally, gossip the remaining messages
-	peers2Send = filter.SelectPeers(g.conf.PropagatePeerNum, g.disc.GetMembership())
+	peers2Send = g.selectPeers(g.conf.PropagatePeerNum, g.disc.GetMembership())
 	for _, msg := range msgs {
 		g.comm.Send(msg, peers2Send...)
 	}
@@ -486,9 +498,9 @@ func (g *gossipServiceImpl) gossipInChan(messages []*proto.SignedGossipMessage,
 		}
 		// Select the peers to send the messages to
 		// For leadership messages we will select all peers that pass routing factory - e.g. all peers in channel and org
-		membership := g.disc.GetMembership()
+		membership := g.membership()
 		allPeersInCh := filter.SelectPeers(len(membership), membership, chanRoutingFactory(gc))
-		peers2Send := filter.SelectPeers(g.conf.PropagatePeerNum, membership, chanRoutingFactory(gc))
+		peers2Send := g.selectPeers(g.conf.PropagatePeerNum, membership, chanRoutingFactory(gc))
 		// Send the messages to the remote peers
 		for _, msg := range messagesOfChannel {
 			if msg.IsLeadershipMsg() {
@@ -529,7 +541,7 @@ func (g *gossipServiceImpl) Gossip(msg *proto.GossipMessage) {
 	if g.conf.PropagateIterations == 0 {
 		return
 	}
-	g.emitter.Add(sMsg)
+	g.forward(sMsg)
 }
 
 // Send sends a message to remote peers
@@ -537,16 +549,43 @@ func (g *gossipServiceImpl) Send(msg *proto.GossipMessage, peers ...*comm.Remote
 	g.comm.Send(msg.NoopSign(), peers...)
 }
 
+// AddEgressFilter registers a filter evaluated before any message is sent to a remote peer
+func (g *gossipServiceImpl) AddEgressFilter(filter EgressFilter) {
+	g.egress.addFilter(filter)
+}
+
 // GetPeers returns a mapping of endpoint --> []discovery.NetworkMember
 func (g *gossipServiceImpl) Peers() []discovery.NetworkMember {
 	s := []discovery.NetworkMember{}
-	for _, member := range g.disc.GetMembership() {
+	for _, member := range g.membership() {
 		s = append(s, member)
 	}
 	return s
 
 }
 
+// MembershipSnapshot returns the membership of this peer, along
+// with the alive and dead peers in its membership view
+func (g *gossipServiceImpl) MembershipSnapshot() (discovery.NetworkMember, []discovery.MemberState) {
+	return g.disc.Self(), g.disc.MembershipSnapshot()
+}
+
+// membership returns the alive members in the view which are not quarantined
+func (g *gossipServiceImpl) membership() []discovery.NetworkMember {
+	return g.reputation.Filter(g.disc.GetMembership())
+}
+
+// selectPeers returns k peers of peerPool that match filters, selecting deprioritized
+// peers only when not enough other peers match, and never quarantined peers
+func (g *gossipServiceImpl) selectPeers(k int, peerPool []discovery.NetworkMember, filters ...filter.RoutingFilter) []*comm.RemotePeer {
+	preferred, deprioritized := g.reputation.Partition(peerPool)
+	peers := filter.SelectPeers(k, preferred, filters...)
+	if len(peers) < k {
+		peers = append(peers, filter.SelectPeers(k-len(peers), deprioritized, filters...)...)
+	}
+	return peers
+}
+
 // PeersOfChannel returns the NetworkMembers considered alive
 // and also subscribed to the channel given
 func (g *gossipServiceImpl) PeersOfChannel(channel common.ChainID) []discovery.NetworkMember {
@@ -664,7 +703,7 @@ func (g *gossipServiceImpl) newDiscoveryAdapter() *discoveryAdapter {
 			if g.conf.PropagateIterations == 0 {
 				return
 			}
-			g.emitter.Add(msg)
+			g.forward(msg)
 		},
 		incChan:      make(chan *proto.SignedGossipMessage),
 		presumedDead: g.presumedDead,
@@ -829,6 +868,7 @@ func (g *gossipServiceImpl) createCertStorePuller() pull.Mediator {
 		PeerCountToSelect: g.conf.PullPeerNum,
 		PullInterval:      g.conf.PullInterval,
 		Tag:               proto.GossipMessage_EMPTY,
+		FilterFPRate:      g.conf.PullFilterFPRate,
 	}
 	pkiIDFromMsg := func(msg *proto.SignedGossipMessage) string {
 		identityMsg := msg.GetPeerIdentity()
@@ -900,6 +940,15 @@ func (g *gossipServiceImpl) getOrgOfPeer(PKIID common.PKIidType) api.OrgIdentity
 	return g.secAdvisor.OrgByPeerIdentity(cert)
 }
 
+// orgOfPeer returns the organization of the peer of PKIID, or nil if its identity is not known
+func (g *gossipServiceImpl) orgOfPeer(PKIID common.PKIidType) api.OrgIdentityType {
+	cert, err := g.idMapper.Get(PKIID)
+	if err != nil {
+		return nil
+	}
+	return g.secAdvisor.OrgByPeerIdentity(cert)
+}
+
 func (g *gossipServiceImpl) validateLeadershipMessage(msg *proto.SignedGossipMessage) error {
 	pkiID := msg.GetLeadershipMsg().PkiID
 	if len(pkiID) == 0 {
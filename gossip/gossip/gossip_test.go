@@ -32,6 +32,7 @@ import (
 	"github.com/hyperledger/fabric/gossip/discovery"
 	"github.com/hyperledger/fabric/gossip/gossip/algo"
 	"github.com/hyperledger/fabric/gossip/identity"
+	"github.com/hyperledger/fabric/gossip/reputation"
 	"github.com/hyperledger/fabric/gossip/util"
 	proto "github.com/hyperledger/fabric/protos/gossip"
 	"github.com/stretchr/testify/assert"
@@ -828,6 +829,38 @@ func TestDisseminateAll2All(t *testing.T) {
 	testWG.Done()
 }
 
+func TestSelectPeersByReputation(t *testing.T) {
+	t.Parallel()
+	g := &gossipServiceImpl{reputation: reputation.NewTracker(reputation.Config{})}
+	var members []discovery.NetworkMember
+	for i := 0; i < 4; i++ {
+		members = append(members, discovery.NetworkMember{PKIid: common.PKIidType(fmt.Sprintf("p%d", i)), Endpoint: fmt.Sprintf("localhost:%d", i)})
+	}
+	// p0 is quarantined and p1 is deprioritized
+	for i := 0; i < 4; i++ {
+		g.reputation.Report(members[0].PKIid, reputation.FailedVerification)
+	}
+	g.reputation.Report(members[1].PKIid, reputation.FailedVerification)
+	g.reputation.Report(members[1].PKIid, reputation.InvalidMessage)
+
+	endpoints := func(k int) []string {
+		var endpoints []string
+		for _, peer := range g.selectPeers(k, members) {
+			endpoints = append(endpoints, peer.Endpoint)
+		}
+		return endpoints
+	}
+	assert.Len(t, endpoints(1), 1)
+	assert.NotContains(t, endpoints(1), "localhost:0")
+	assert.NotContains(t, endpoints(1), "localhost:1")
+	assert.Len(t, endpoints(2), 2)
+	assert.NotContains(t, endpoints(2), "localhost:0")
+	assert.NotContains(t, endpoints(2), "localhost:1")
+	assert.Contains(t, endpoints(3), "localhost:1")
+	assert.Len(t, endpoints(4), 3)
+	assert.NotContains(t, endpoints(4), "localhost:0")
+}
+
 func TestEndedGoroutines(t *testing.T) {
 	t.Parallel()
 	testWG.Wait()
@@ -0,0 +1,77 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gossip
+
+import (
+	proto "github.com/hyperledger/fabric/protos/gossip"
+)
+
+// HopLimits bounds, per class of messages, the number of hops messages
+// travel from the peer that created them. Messages that reached a peer
+// after as many hops as their limit are not forwarded any further.
+// A limit of 0 means no limit.
+//
+// The limits are advisory: the hops of a message are set by each peer
+// that forwards it, outside of the signature of the message, so they
+// bound how far honest peers flood messages but a misbehaving peer can
+// reset them. The hop count is the only time-to-live of messages: they
+// carry no signed creation time to expire them by.
+type HopLimits struct {
+	Membership int // Alive messages
+	Block      int // Data messages
+	StateInfo  int // StateInfo messages
+	Leadership int // Leadership messages
+}
+
+// of returns the hop limit of the class of msg
+func (l HopLimits) of(msg *proto.GossipMessage) int {
+	switch {
+	case msg.IsAliveMsg():
+		return l.Membership
+	case msg.IsDataMsg():
+		return l.Block
+	case msg.IsStateInfoMsg():
+		return l.StateInfo
+	case msg.IsLeadershipMsg():
+		return l.Leadership
+	}
+	return 0
+}
+
+// forward adds msg to the messages pushed to remote peers, unless it
+// already traveled as many hops as the limit of its class permits.
+// The hops of the forwarded copy are incremented. As they are not
+// signed, the hops msg claims are trusted as received.
+func (g *gossipServiceImpl) forward(msg *proto.SignedGossipMessage) {
+	if msg.Envelope == nil {
+		g.emitter.Add(msg)
+		return
+	}
+	if limit := g.conf.MaxHops.of(msg.GossipMessage); limit > 0 && msg.Hops >= uint32(limit) {
+		g.logger.Debug("Not forwarding", msg, "after", msg.Hops, "hops")
+		return
+	}
+	g.emitter.Add(&proto.SignedGossipMessage{
+		GossipMessage: msg.GossipMessage,
+		Envelope: &proto.Envelope{
+			Payload:        msg.Payload,
+			Signature:      msg.Signature,
+			SecretEnvelope: msg.SecretEnvelope,
+			Hops:           msg.Hops + 1,
+		},
+	})
+}
@@ -0,0 +1,92 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gossip
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/gossip/util"
+	proto "github.com/hyperledger/fabric/protos/gossip"
+	"github.com/stretchr/testify/assert"
+)
+
+type emitterMock struct {
+	added []*proto.SignedGossipMessage
+}
+
+func (e *emitterMock) Add(msg interface{}) {
+	e.added = append(e.added, msg.(*proto.SignedGossipMessage))
+}
+
+func (e *emitterMock) Stop() {}
+
+func (e *emitterMock) Size() int {
+	return len(e.added)
+}
+
+func TestForwardHopLimits(t *testing.T) {
+	emitter := &emitterMock{}
+	g := &gossipServiceImpl{
+		conf:    &Config{MaxHops: HopLimits{Membership: 2, Leadership: 1}},
+		emitter: emitter,
+		logger:  util.GetLogger(util.LoggingGossipModule, "hops"),
+	}
+	aliveMsg := func(hops uint32) *proto.SignedGossipMessage {
+		msg := (&proto.GossipMessage{
+			Tag:     proto.GossipMessage_EMPTY,
+			Content: &proto.GossipMessage_AliveMsg{AliveMsg: &proto.AliveMessage{}},
+		}).NoopSign()
+		msg.Hops = hops
+		return msg
+	}
+
+	// Messages within the limit are forwarded with their hops incremented,
+	// without modifying the received message
+	received := aliveMsg(1)
+	g.forward(received)
+	assert.Len(t, emitter.added, 1)
+	assert.Equal(t, uint32(2), emitter.added[0].Hops)
+	assert.Equal(t, uint32(1), received.Hops)
+	assert.Equal(t, received.Payload, emitter.added[0].Payload)
+
+	// Messages that reached the limit are not forwarded
+	g.forward(aliveMsg(2))
+	g.forward(aliveMsg(3))
+	assert.Len(t, emitter.added, 1)
+
+	// Messages created by this peer are forwarded with a single hop
+	leadershipMsg := (&proto.GossipMessage{
+		Tag:     proto.GossipMessage_CHAN_AND_ORG,
+		Content: &proto.GossipMessage_LeadershipMsg{LeadershipMsg: &proto.LeadershipMessage{}},
+	}).NoopSign()
+	g.forward(leadershipMsg)
+	assert.Len(t, emitter.added, 2)
+	assert.Equal(t, uint32(1), emitter.added[1].Hops)
+	leadershipMsg.Hops = 1
+	g.forward(leadershipMsg)
+	assert.Len(t, emitter.added, 2)
+
+	// Classes without a limit are forwarded regardless of their hops
+	dataMsg := (&proto.GossipMessage{
+		Tag:     proto.GossipMessage_CHAN_AND_ORG,
+		Content: &proto.GossipMessage_DataMsg{DataMsg: &proto.DataMessage{}},
+	}).NoopSign()
+	dataMsg.Hops = 100
+	g.forward(dataMsg)
+	assert.Len(t, emitter.added, 3)
+	assert.Equal(t, uint32(101), emitter.added[2].Hops)
+}
@@ -0,0 +1,111 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pull
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"math"
+
+	proto "github.com/hyperledger/fabric/protos/gossip"
+)
+
+// maxBloomHashes bounds the number of hash functions of
+// filters received from remote peers
+const maxBloomHashes = 32
+
+// bloomFilter is a Bloom filter of item IDs. The hashes of the
+// items are seeded, so that false positives differ between
+// filters of different seeds
+type bloomFilter struct {
+	bits   []byte
+	hashes uint32
+	seed   uint64
+}
+
+// newBloomFilter returns a bloomFilter of the given items
+// with a false positive rate of about fpRate
+func newBloomFilter(items []string, fpRate float64, seed uint64) *bloomFilter {
+	n := float64(len(items))
+	if n == 0 {
+		n = 1
+	}
+	m := math.Ceil(-n * math.Log(fpRate) / (math.Ln2 * math.Ln2))
+	if m < 8 {
+		m = 8
+	}
+	k := math.Max(1, math.Min(maxBloomHashes, math.Floor(m/n*math.Ln2+0.5)))
+
+	f := &bloomFilter{
+		bits:   make([]byte, (int(m)+7)/8),
+		hashes: uint32(k),
+		seed:   seed,
+	}
+	for _, item := range items {
+		f.add(item)
+	}
+	return f
+}
+
+// bloomFilterFromProto returns the bloomFilter of the given
+// message, or nil if the message is nil or malformed
+func bloomFilterFromProto(filter *proto.BloomFilter, seed uint64) *bloomFilter {
+	if filter == nil || len(filter.Bits) == 0 || filter.Hashes == 0 || filter.Hashes > maxBloomHashes {
+		return nil
+	}
+	return &bloomFilter{bits: filter.Bits, hashes: filter.Hashes, seed: seed}
+}
+
+func (f *bloomFilter) toProto() *proto.BloomFilter {
+	return &proto.BloomFilter{Bits: f.bits, Hashes: f.hashes}
+}
+
+func (f *bloomFilter) add(item string) {
+	for _, i := range f.indices(item) {
+		f.bits[i/8] |= 1 << (i % 8)
+	}
+}
+
+// contains returns whether item may have been added to the filter.
+// It never returns false for items that have been added.
+func (f *bloomFilter) contains(item string) bool {
+	for _, i := range f.indices(item) {
+		if f.bits[i/8]&(1<<(i%8)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// indices returns the bits of item, derived from
+// two halves of a single hash by double hashing
+func (f *bloomFilter) indices(item string) []uint64 {
+	seed := make([]byte, 8)
+	binary.BigEndian.PutUint64(seed, f.seed)
+	h := fnv.New64a()
+	h.Write(seed)
+	h.Write([]byte(item))
+	sum := h.Sum64()
+	h1, h2 := sum&math.MaxUint32, sum>>32|1
+
+	m := uint64(len(f.bits)) * 8
+	indices := make([]uint64, f.hashes)
+	for i := range indices {
+		indices[i] = (h1 + uint64(i)*h2) % m
+	}
+	return indices
+}
@@ -63,6 +63,12 @@ type PullConfig struct {
 	Tag               proto.GossipMessage_Tag
 	Channel           common.ChainID
 	MsgType           proto.PullMsgType
+	// FilterFPRate, if positive, is the false positive rate of the Bloom
+	// filter of the items this peer has, sent in hello messages so that
+	// digests only carry the items this peer is missing. Items falsely
+	// matched by the filter are pulled in later rounds, as the filter
+	// of each round is seeded differently.
+	FilterFPRate float64
 }
 
 // Mediator is a component wrap a PullEngine and provides the methods
@@ -227,6 +233,7 @@ func (p *pullMediatorImpl) Hello(dest string, nonce uint64) {
 				Nonce:    nonce,
 				Metadata: nil,
 				MsgType:  p.config.MsgType,
+				Filter:   p.itemsFilter(nonce),
 			},
 		},
 	}
@@ -235,9 +242,36 @@ func (p *pullMediatorImpl) Hello(dest string, nonce uint64) {
 	p.Send(helloMsg.NoopSign(), p.peersWithEndpoints(dest)...)
 }
 
+// itemsFilter returns the Bloom filter of the items of
+// the mediator, or nil if filters are disabled
+func (p *pullMediatorImpl) itemsFilter(nonce uint64) *proto.BloomFilter {
+	if p.config.FilterFPRate <= 0 {
+		return nil
+	}
+	p.RLock()
+	items := make([]string, 0, len(p.itemID2Msg))
+	for itemID := range p.itemID2Msg {
+		items = append(items, itemID)
+	}
+	p.RUnlock()
+	return newBloomFilter(items, p.config.FilterFPRate, nonce).toProto()
+}
+
 // SendDigest sends a digest to a remote PullEngine.
 // The context parameter specifies the remote engine to send to.
+// If the hello of the remote engine carries a Bloom filter,
+// the items it contains are omitted from the digest.
 func (p *pullMediatorImpl) SendDigest(digest []string, nonce uint64, context interface{}) {
+	hello := context.(proto.ReceivedMessage).GetGossipMessage().GetHello()
+	if filter := bloomFilterFromProto(hello.GetFilter(), nonce); filter != nil {
+		missing := []string{}
+		for _, item := range digest {
+			if !filter.contains(item) {
+				missing = append(missing, item)
+			}
+		}
+		digest = missing
+	}
 	digMsg := &proto.GossipMessage{
 		Channel: p.config.Channel,
 		Tag:     p.config.Tag,
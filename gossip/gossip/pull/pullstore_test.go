@@ -18,6 +18,7 @@ package pull
 
 import (
 	"fmt"
+	"sort"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -264,6 +265,91 @@ func TestHandleMessage(t *testing.T) {
 	assert.True(t, inst1.items.Exists(uint64(2)))
 }
 
+func TestBloomFilter(t *testing.T) {
+	t.Parallel()
+	items := make([]string, 1000)
+	for i := range items {
+		items[i] = fmt.Sprintf("%d", i)
+	}
+	f := newBloomFilter(items, 0.01, 1)
+	for _, item := range items {
+		assert.True(t, f.contains(item))
+	}
+
+	// The false positive rate is about the one requested
+	falsePositives := 0
+	for i := 1000; i < 11000; i++ {
+		if f.contains(fmt.Sprintf("%d", i)) {
+			falsePositives++
+		}
+	}
+	assert.True(t, falsePositives < 200, "Too many false positives: %d", falsePositives)
+
+	// The filter survives a roundtrip with the seed
+	f2 := bloomFilterFromProto(f.toProto(), 1)
+	for _, item := range items {
+		assert.True(t, f2.contains(item))
+	}
+
+	// Malformed filters are ignored
+	assert.Nil(t, bloomFilterFromProto(nil, 1))
+	assert.Nil(t, bloomFilterFromProto(&proto.BloomFilter{Hashes: 1}, 1))
+	assert.Nil(t, bloomFilterFromProto(&proto.BloomFilter{Bits: []byte{1}, Hashes: maxBloomHashes + 1}, 1))
+}
+
+func TestFilteredDigest(t *testing.T) {
+	t.Parallel()
+	inst1 := createPullInstance("localhost:5611", make(map[string]*pullInstance))
+	inst2 := createPullInstance("localhost:5612", make(map[string]*pullInstance))
+	defer inst1.stop()
+	defer inst2.stop()
+
+	for i := 0; i < 100; i++ {
+		inst2.mediator.Add(dataMsg(i))
+	}
+
+	digests := make(chan []string, 1)
+	inst1.mediator.RegisterMsgHook(DigestMsgType, func(itemIds []string, _ []*proto.SignedGossipMessage, msg proto.ReceivedMessage) {
+		// Ignore the digests of the pulls inst1 initiates on its own
+		if nonce := msg.GetGossipMessage().GetDataDig().Nonce; nonce == 5 || nonce == 6 {
+			digests <- itemIds
+		}
+	})
+
+	// inst1 has all items but 100 and 101, and hellos inst2 with their filter
+	items := []string{}
+	for i := 2; i < 100; i++ {
+		items = append(items, fmt.Sprintf("%d", i))
+	}
+	hello := helloMsg()
+	hello.GetHello().Nonce = 5
+	hello.GetHello().Filter = newBloomFilter(items, 0.0001, 5).toProto()
+	inst2.mediator.HandleMessage(inst1.wrapPullMsg(hello.NoopSign()))
+
+	select {
+	case digest := <-digests:
+		assert.Equal(t, []string{"0", "1"}, sortedStrings(digest))
+	case <-time.After(timeoutInterval):
+		assert.Fail(t, "Didn't receive a digest")
+	}
+
+	// Hellos without filters get full digests
+	hello = helloMsg()
+	hello.GetHello().Nonce = 6
+	inst2.mediator.HandleMessage(inst1.wrapPullMsg(hello.NoopSign()))
+	select {
+	case digest := <-digests:
+		assert.Len(t, digest, 100)
+	case <-time.After(timeoutInterval):
+		assert.Fail(t, "Didn't receive a digest")
+	}
+}
+
+func sortedStrings(a []string) []string {
+	sort.Strings(a)
+	return a
+}
+
 func waitUntilOrFail(t *testing.T, pred func() bool) {
 	start := time.Now()
 	limit := start.UnixNano() + timeoutInterval.Nanoseconds()
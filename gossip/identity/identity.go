@@ -19,6 +19,7 @@ package identity
 import (
 	"bytes"
 	"sync"
+	"time"
 
 	"errors"
 
@@ -52,14 +53,20 @@ type Mapper interface {
 type identityMapperImpl struct {
 	mcs        api.MessageCryptoService
 	pkiID2Cert map[string]api.PeerIdentityType
+	// expirations holds the timers purging identities once they expire
+	expirations map[string]*time.Timer
 	sync.RWMutex
 }
 
-// NewIdentityMapper method, all we need is a reference to a MessageCryptoService
+// NewIdentityMapper method, all we need is a reference to a MessageCryptoService.
+// If the MessageCryptoService is an api.ExpirationAwareMessageCryptoService,
+// identities are purged once they expire. If it is an api.MACSessionService,
+// so is the Mapper.
 func NewIdentityMapper(mcs api.MessageCryptoService) Mapper {
 	return &identityMapperImpl{
-		mcs:        mcs,
-		pkiID2Cert: make(map[string]api.PeerIdentityType),
+		mcs:         mcs,
+		pkiID2Cert:  make(map[string]api.PeerIdentityType),
+		expirations: make(map[string]*time.Timer),
 	}
 }
 
@@ -82,12 +89,67 @@ func (is *identityMapperImpl) Put(pkiID common.PKIidType, identity api.PeerIdent
 		return errors.New("Identity doesn't match the computed pkiID")
 	}
 
+	expiresAt, err := is.expiration(identity)
+	if err != nil {
+		return err
+	}
+
 	is.Lock()
 	defer is.Unlock()
 	is.pkiID2Cert[string(id)] = identity
+	is.scheduleExpiration(string(id), identity, expiresAt)
 	return nil
 }
 
+// expiration returns the time identity expires at, or
+// the zero time if the expiration is unknown
+func (is *identityMapperImpl) expiration(identity api.PeerIdentityType) (time.Time, error) {
+	mcs, isExpirationAware := is.mcs.(api.ExpirationAwareMessageCryptoService)
+	if !isExpirationAware {
+		return time.Time{}, nil
+	}
+	return mcs.Expiration(identity)
+}
+
+// NewMACHandshake starts the handshake establishing the MAC session of a
+// new connection, or returns nil if the MessageCryptoService is not an
+// api.MACSessionService
+func (is *identityMapperImpl) NewMACHandshake() (api.MACHandshake, error) {
+	mcs, isMACSessionService := is.mcs.(api.MACSessionService)
+	if !isMACSessionService {
+		return nil, nil
+	}
+	return mcs.NewMACHandshake()
+}
+
+// scheduleExpiration schedules the purge of identity, mapped to pkiID,
+// at expiresAt, replacing any previously scheduled purge of pkiID.
+// Must be called with the lock held.
+func (is *identityMapperImpl) scheduleExpiration(pkiID string, identity api.PeerIdentityType, expiresAt time.Time) {
+	if timer, exists := is.expirations[pkiID]; exists {
+		timer.Stop()
+		delete(is.expirations, pkiID)
+	}
+	if expiresAt.IsZero() {
+		return
+	}
+
+	var timer *time.Timer
+	timer = time.AfterFunc(expiresAt.Sub(time.Now()), func() {
+		is.Lock()
+		defer is.Unlock()
+		// The identity may have been replaced meanwhile
+		if is.expirations[pkiID] != timer {
+			return
+		}
+		delete(is.expirations, pkiID)
+		if bytes.Equal(is.pkiID2Cert[pkiID], identity) {
+			delete(is.pkiID2Cert, pkiID)
+		}
+	})
+	is.expirations[pkiID] = timer
+}
+
 // get returns the identity of a given pkiID, or error if such an identity
 // isn't found
 func (is *identityMapperImpl) Get(pkiID common.PKIidType) (api.PeerIdentityType, error) {
@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/hyperledger/fabric/gossip/api"
 	"github.com/hyperledger/fabric/gossip/common"
@@ -109,3 +110,51 @@ func TestVerify(t *testing.T) {
 	assert.NoError(t, idStore.Verify(pkiID, signed, []byte("bla bla")))
 	assert.Error(t, idStore.Verify(pkiID2, signed, []byte("bla bla")))
 }
+
+type expiringCryptoService struct {
+	naiveCryptoService
+	expiresAt time.Time
+}
+
+// Expiration returns the time the identity of a remote peer expires at
+func (cs *expiringCryptoService) Expiration(peerIdentity api.PeerIdentityType) (time.Time, error) {
+	if string(peerIdentity) == "invalid" {
+		return time.Time{}, fmt.Errorf("Invalid identity")
+	}
+	return cs.expiresAt, nil
+}
+
+func TestExpiration(t *testing.T) {
+	cs := &expiringCryptoService{expiresAt: time.Now().Add(500 * time.Millisecond)}
+	idStore := NewIdentityMapper(cs)
+	identity := []byte("yacovm")
+	pkiID := cs.GetPKIidOfCert(api.PeerIdentityType(identity))
+	assert.NoError(t, idStore.Put(pkiID, identity))
+	_, err := idStore.Get(pkiID)
+	assert.NoError(t, err)
+
+	// The identity is purged once it expires
+	time.Sleep(time.Second)
+	_, err = idStore.Get(pkiID)
+	assert.Error(t, err)
+
+	// Identities with an unknown expiration are never purged
+	cs.expiresAt = time.Time{}
+	assert.NoError(t, idStore.Put(pkiID, identity))
+	time.Sleep(time.Second)
+	_, err = idStore.Get(pkiID)
+	assert.NoError(t, err)
+
+	// Putting an identity again replaces its expiration
+	cs.expiresAt = time.Now().Add(500 * time.Millisecond)
+	assert.NoError(t, idStore.Put(pkiID, identity))
+	cs.expiresAt = time.Time{}
+	assert.NoError(t, idStore.Put(pkiID, identity))
+	time.Sleep(time.Second)
+	_, err = idStore.Get(pkiID)
+	assert.NoError(t, err)
+
+	// Identities whose expiration cannot be determined are rejected
+	invalid := []byte("invalid")
+	assert.Error(t, idStore.Put(cs.GetPKIidOfCert(invalid), invalid))
+}
@@ -25,6 +25,7 @@ import (
 	"github.com/hyperledger/fabric/gossip/api"
 	"github.com/hyperledger/fabric/gossip/gossip"
 	"github.com/hyperledger/fabric/gossip/identity"
+	"github.com/hyperledger/fabric/gossip/reputation"
 	"github.com/hyperledger/fabric/gossip/util"
 	"github.com/spf13/viper"
 	"google.golang.org/grpc"
@@ -49,6 +50,7 @@ func newConfig(selfEndpoint string, externalEndpoint string, bootPeers ...string
 	return &gossip.Config{
 		BindPort:                   int(port),
 		BootstrapPeers:             bootPeers,
+		BootstrapSRV:               viper.GetStringSlice("peer.gossip.bootstrapSRV"),
 		ID:                         selfEndpoint,
 		MaxBlockCountToStore:       util.GetIntOrDefault("peer.gossip.maxBlockCountToStore", 100),
 		MaxPropagationBurstLatency: util.GetDurationOrDefault("peer.gossip.maxPropagationBurstLatency", 10*time.Millisecond),
@@ -57,6 +59,7 @@ func newConfig(selfEndpoint string, externalEndpoint string, bootPeers ...string
 		PropagatePeerNum:           util.GetIntOrDefault("peer.gossip.propagatePeerNum", 3),
 		PullInterval:               util.GetDurationOrDefault("peer.gossip.pullInterval", 4*time.Second),
 		PullPeerNum:                util.GetIntOrDefault("peer.gossip.pullPeerNum", 3),
+		PullFilterFPRate:           viper.GetFloat64("peer.gossip.pullFilterFPRate"),
 		InternalEndpoint:           selfEndpoint,
 		ExternalEndpoint:           externalEndpoint,
 		PublishCertPeriod:          util.GetDurationOrDefault("peer.gossip.publishCertPeriod", 10*time.Second),
@@ -64,6 +67,21 @@ func newConfig(selfEndpoint string, externalEndpoint string, bootPeers ...string
 		PublishStateInfoInterval:   util.GetDurationOrDefault("peer.gossip.publishStateInfoInterval", 4*time.Second),
 		SkipBlockVerification:      viper.GetBool("peer.gossip.skipBlockVerification"),
 		TLSServerCert:              cert,
+		Reputation: reputation.Config{
+			InvalidMessagePenalty:     viper.GetInt("peer.gossip.reputation.invalidMessagePenalty"),
+			FailedVerificationPenalty: viper.GetInt("peer.gossip.reputation.failedVerificationPenalty"),
+			TimeoutPenalty:            viper.GetInt("peer.gossip.reputation.timeoutPenalty"),
+			DeprioritizeThreshold:     viper.GetInt("peer.gossip.reputation.deprioritizeThreshold"),
+			QuarantineThreshold:       viper.GetInt("peer.gossip.reputation.quarantineThreshold"),
+			QuarantineDuration:        viper.GetDuration("peer.gossip.reputation.quarantineDuration"),
+			RecoveryHalfLife:          viper.GetDuration("peer.gossip.reputation.recoveryHalfLife"),
+		},
+		MaxHops: gossip.HopLimits{
+			Membership: viper.GetInt("peer.gossip.maxHops.membership"),
+			Block:      viper.GetInt("peer.gossip.maxHops.block"),
+			StateInfo:  viper.GetInt("peer.gossip.maxHops.stateInfo"),
+			Leadership: viper.GetInt("peer.gossip.maxHops.leadership"),
+		},
 	}
 }
 
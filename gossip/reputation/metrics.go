@@ -0,0 +1,127 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reputation
+
+import (
+	"encoding/hex"
+
+	"github.com/hyperledger/fabric/gossip/common"
+)
+
+// Gauge records a value that may go up and down
+type Gauge interface {
+	// With returns a Gauge whose values carry the labels
+	// given as alternating label names and values
+	With(labelValues ...string) Gauge
+
+	// Set sets the gauge to value
+	Set(value float64)
+}
+
+// Counter records a monotonically increasing value
+type Counter interface {
+	// With returns a Counter whose increments carry the labels
+	// given as alternating label names and values
+	With(labelValues ...string) Counter
+
+	// Add increases the counter by delta
+	Add(delta float64)
+}
+
+// MetricsProvider creates the metrics the Tracker reports
+type MetricsProvider interface {
+	// NewGauge returns the gauge named name
+	NewGauge(name string) Gauge
+
+	// NewCounter returns the counter named name
+	NewCounter(name string) Counter
+}
+
+const (
+	// PeerScore is the name of the gauge of the scores of peers
+	// as of their last misbehavior, labeled with "peer" being
+	// the hex encoding of their PKI-IDs
+	PeerScore = "gossip_peer_score"
+
+	// ReputationEvents is the name of the counter of the misbehaviors
+	// reported, labeled with "event" being "invalid_message",
+	// "failed_verification" or "timeout"
+	ReputationEvents = "gossip_reputation_events"
+
+	// Quarantines is the name of the counter of the quarantines of peers
+	Quarantines = "gossip_quarantines"
+
+	// QuarantinedPeers is the name of the gauge of the
+	// number of peers quarantined
+	QuarantinedPeers = "gossip_quarantined_peers"
+)
+
+type trackerMetrics struct {
+	peerScore        Gauge
+	reputationEvents Counter
+	quarantines      Counter
+	quarantinedPeers Gauge
+}
+
+// newTrackerMetrics returns the metrics created by provider, or nil if provider is nil
+func newTrackerMetrics(provider MetricsProvider) *trackerMetrics {
+	if provider == nil {
+		return nil
+	}
+	return &trackerMetrics{
+		peerScore:        provider.NewGauge(PeerScore),
+		reputationEvents: provider.NewCounter(ReputationEvents),
+		quarantines:      provider.NewCounter(Quarantines),
+		quarantinedPeers: provider.NewGauge(QuarantinedPeers),
+	}
+}
+
+// setScore records the score of the peer of PKI-ID pkiID
+func (m *trackerMetrics) setScore(pkiID common.PKIidType, score float64) {
+	if m == nil {
+		return
+	}
+
+	m.peerScore.With("peer", hex.EncodeToString(pkiID)).Set(score)
+}
+
+// countEvent records that event was reported
+func (m *trackerMetrics) countEvent(event Event) {
+	if m == nil {
+		return
+	}
+
+	m.reputationEvents.With("event", event.String()).Add(1)
+}
+
+// countQuarantine records that a peer was quarantined
+func (m *trackerMetrics) countQuarantine() {
+	if m == nil {
+		return
+	}
+
+	m.quarantines.Add(1)
+}
+
+// setQuarantined records the number of peers quarantined
+func (m *trackerMetrics) setQuarantined(count int) {
+	if m == nil {
+		return
+	}
+
+	m.quarantinedPeers.Set(float64(count))
+}
@@ -0,0 +1,272 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reputation
+
+import (
+	"encoding/hex"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric/gossip/common"
+	"github.com/hyperledger/fabric/gossip/discovery"
+	"github.com/hyperledger/fabric/gossip/util"
+	"github.com/op/go-logging"
+)
+
+// Event is a misbehavior of a remote peer which lowers its score
+type Event int
+
+const (
+	// InvalidMessage means that the peer sent a malformed message
+	InvalidMessage Event = iota
+	// FailedVerification means that the peer sent a message whose
+	// signature, or the block it carries, failed verification
+	FailedVerification
+	// Timeout means that the peer did not respond in time
+	Timeout
+)
+
+var eventNames = []string{"invalid_message", "failed_verification", "timeout"}
+
+func (e Event) String() string {
+	return eventNames[e]
+}
+
+const (
+	// MaxScore is the score of peers that did not misbehave
+	MaxScore = 100
+
+	defInvalidMessagePenalty     = 10
+	defFailedVerificationPenalty = 20
+	defTimeoutPenalty            = 5
+	defDeprioritizeThreshold     = 70
+	defQuarantineThreshold       = 40
+	defQuarantineDuration        = time.Minute
+	defRecoveryHalfLife          = 5 * time.Minute
+)
+
+// Config is the configuration of a Tracker, zero values stand for the defaults
+type Config struct {
+	InvalidMessagePenalty     int           // Score lost for an invalid message
+	FailedVerificationPenalty int           // Score lost for a failed verification
+	TimeoutPenalty            int           // Score lost for a timeout
+	DeprioritizeThreshold     int           // Score below which peers are selected last
+	QuarantineThreshold       int           // Score below which peers are quarantined
+	QuarantineDuration        time.Duration // Time peers are quarantined for
+	RecoveryHalfLife          time.Duration // Time after which half of the lost score is recovered
+	MetricsProvider           MetricsProvider
+}
+
+// Tracker scores remote peers according to their misbehaviors. Peers start
+// with MaxScore, lose score for each misbehavior reported, and recover it over
+// time. Peers whose score is below the deprioritize threshold are selected only
+// when no other peer is available, and peers whose score falls below the
+// quarantine threshold are excluded from the membership view for a while.
+type Tracker struct {
+	conf    Config
+	now     func() time.Time
+	logger  *logging.Logger
+	metrics *trackerMetrics
+
+	lock sync.Mutex
+	// peers maps PKI-IDs to the records of peers that misbehaved
+	peers     map[string]*peerRecord
+	lastSweep time.Time
+}
+
+type peerRecord struct {
+	// penalty is the score lost as of updated
+	penalty float64
+	updated time.Time
+	// quarantinedUntil is the end of the quarantine of the peer, if any
+	quarantinedUntil time.Time
+}
+
+// NewTracker returns a Tracker configured by conf
+func NewTracker(conf Config) *Tracker {
+	if conf.InvalidMessagePenalty == 0 {
+		conf.InvalidMessagePenalty = defInvalidMessagePenalty
+	}
+	if conf.FailedVerificationPenalty == 0 {
+		conf.FailedVerificationPenalty = defFailedVerificationPenalty
+	}
+	if conf.TimeoutPenalty == 0 {
+		conf.TimeoutPenalty = defTimeoutPenalty
+	}
+	if conf.DeprioritizeThreshold == 0 {
+		conf.DeprioritizeThreshold = defDeprioritizeThreshold
+	}
+	if conf.QuarantineThreshold == 0 {
+		conf.QuarantineThreshold = defQuarantineThreshold
+	}
+	if conf.QuarantineDuration == 0 {
+		conf.QuarantineDuration = defQuarantineDuration
+	}
+	if conf.RecoveryHalfLife == 0 {
+		conf.RecoveryHalfLife = defRecoveryHalfLife
+	}
+
+	return &Tracker{
+		conf:    conf,
+		now:     time.Now,
+		logger:  util.GetLogger(util.LoggingReputationModule, ""),
+		metrics: newTrackerMetrics(conf.MetricsProvider),
+		peers:   make(map[string]*peerRecord),
+	}
+}
+
+// Report lowers the score of the peer of PKI-ID pkiID for event, and
+// quarantines the peer if its score falls below the quarantine threshold
+func (t *Tracker) Report(pkiID common.PKIidType, event Event) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	now := t.now()
+	t.sweep(now)
+
+	record, exists := t.peers[string(pkiID)]
+	if !exists {
+		record = &peerRecord{updated: now}
+		t.peers[string(pkiID)] = record
+	}
+	record.penalty = t.decayedPenalty(record, now) + float64(t.penalty(event))
+	record.updated = now
+
+	score := MaxScore - record.penalty
+	if score < float64(t.conf.QuarantineThreshold) && !now.Before(record.quarantinedUntil) {
+		t.logger.Warning("Quarantining peer", pkiID, "with score", score, "for", t.conf.QuarantineDuration)
+		record.quarantinedUntil = now.Add(t.conf.QuarantineDuration)
+		// The score recovers from the quarantine threshold, so that
+		// the peer leaves the quarantine still deprioritized
+		record.penalty = MaxScore - float64(t.conf.QuarantineThreshold)
+		t.metrics.countQuarantine()
+	}
+
+	t.metrics.countEvent(event)
+	t.metrics.setScore(pkiID, MaxScore-record.penalty)
+	t.metrics.setQuarantined(t.quarantined(now))
+}
+
+// Score returns the current score of the peer of PKI-ID pkiID
+func (t *Tracker) Score(pkiID common.PKIidType) float64 {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	record, exists := t.peers[string(pkiID)]
+	if !exists {
+		return MaxScore
+	}
+	return MaxScore - t.decayedPenalty(record, t.now())
+}
+
+// Scores returns the current scores of the peers that misbehaved,
+// keyed by the hex encoding of their PKI-IDs
+func (t *Tracker) Scores() map[string]float64 {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	now := t.now()
+	scores := make(map[string]float64, len(t.peers))
+	for pkiID, record := range t.peers {
+		scores[hex.EncodeToString([]byte(pkiID))] = MaxScore - t.decayedPenalty(record, now)
+	}
+	return scores
+}
+
+// IsQuarantined returns whether the peer of PKI-ID pkiID is quarantined
+func (t *Tracker) IsQuarantined(pkiID common.PKIidType) bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	record, exists := t.peers[string(pkiID)]
+	return exists && t.now().Before(record.quarantinedUntil)
+}
+
+// Partition returns the members that are not quarantined, split into the
+// ones to select in priority and the deprioritized ones
+func (t *Tracker) Partition(members []discovery.NetworkMember) (preferred []discovery.NetworkMember, deprioritized []discovery.NetworkMember) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	now := t.now()
+	for _, member := range members {
+		record, exists := t.peers[string(member.PKIid)]
+		switch {
+		case !exists:
+			preferred = append(preferred, member)
+		case now.Before(record.quarantinedUntil):
+		case MaxScore-t.decayedPenalty(record, now) < float64(t.conf.DeprioritizeThreshold):
+			deprioritized = append(deprioritized, member)
+		default:
+			preferred = append(preferred, member)
+		}
+	}
+	return preferred, deprioritized
+}
+
+// Filter returns the members that are not quarantined
+func (t *Tracker) Filter(members []discovery.NetworkMember) []discovery.NetworkMember {
+	preferred, deprioritized := t.Partition(members)
+	return append(preferred, deprioritized...)
+}
+
+func (t *Tracker) penalty(event Event) int {
+	switch event {
+	case InvalidMessage:
+		return t.conf.InvalidMessagePenalty
+	case FailedVerification:
+		return t.conf.FailedVerificationPenalty
+	}
+	return t.conf.TimeoutPenalty
+}
+
+// decayedPenalty returns the penalty of record at time now, halved every recovery half-life
+func (t *Tracker) decayedPenalty(record *peerRecord, now time.Time) float64 {
+	halfLives := float64(now.Sub(record.updated)) / float64(t.conf.RecoveryHalfLife)
+	if halfLives <= 0 {
+		return record.penalty
+	}
+	return record.penalty / math.Pow(2, halfLives)
+}
+
+// quarantined returns the number of peers quarantined at time now
+func (t *Tracker) quarantined(now time.Time) int {
+	count := 0
+	for _, record := range t.peers {
+		if now.Before(record.quarantinedUntil) {
+			count++
+		}
+	}
+	return count
+}
+
+// sweep forgets the peers which recovered their score, at most once per recovery half-life
+func (t *Tracker) sweep(now time.Time) {
+	if now.Sub(t.lastSweep) < t.conf.RecoveryHalfLife {
+		return
+	}
+	t.lastSweep = now
+
+	for pkiID, record := range t.peers {
+		if now.Before(record.quarantinedUntil) || t.decayedPenalty(record, now) >= 1 {
+			continue
+		}
+		delete(t.peers, pkiID)
+		t.metrics.setScore(common.PKIidType(pkiID), MaxScore)
+	}
+}
@@ -0,0 +1,182 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reputation
+
+import (
+	"encoding/hex"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/gossip/common"
+	"github.com/hyperledger/fabric/gossip/discovery"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockClock struct {
+	now time.Time
+}
+
+func (c *mockClock) advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+type mockMetric struct {
+	lock   *sync.Mutex
+	values map[string]float64
+	labels string
+}
+
+func newMockMetric() *mockMetric {
+	return &mockMetric{lock: &sync.Mutex{}, values: make(map[string]float64)}
+}
+
+func (m *mockMetric) with(labelValues ...string) *mockMetric {
+	labels := m.labels
+	for _, labelValue := range labelValues {
+		labels += labelValue + ","
+	}
+	return &mockMetric{lock: m.lock, values: m.values, labels: labels}
+}
+
+func (m *mockMetric) With(labelValues ...string) Gauge {
+	return m.with(labelValues...)
+}
+
+func (m *mockMetric) Set(value float64) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.values[m.labels] = value
+}
+
+func (m *mockMetric) Add(delta float64) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.values[m.labels] += delta
+}
+
+type mockCounter struct {
+	*mockMetric
+}
+
+func (m *mockCounter) With(labelValues ...string) Counter {
+	return &mockCounter{m.with(labelValues...)}
+}
+
+type mockMetricsProvider struct {
+	metrics map[string]*mockMetric
+}
+
+func (p *mockMetricsProvider) NewGauge(name string) Gauge {
+	p.metrics[name] = newMockMetric()
+	return p.metrics[name]
+}
+
+func (p *mockMetricsProvider) NewCounter(name string) Counter {
+	p.metrics[name] = newMockMetric()
+	return &mockCounter{p.metrics[name]}
+}
+
+func newTestTracker(conf Config) (*Tracker, *mockClock) {
+	clock := &mockClock{now: time.Now()}
+	t := NewTracker(conf)
+	t.now = func() time.Time {
+		return clock.now
+	}
+	return t, clock
+}
+
+func TestScores(t *testing.T) {
+	tracker, clock := newTestTracker(Config{RecoveryHalfLife: time.Minute})
+	p1 := common.PKIidType("p1")
+
+	assert.Equal(t, float64(MaxScore), tracker.Score(p1))
+
+	tracker.Report(p1, InvalidMessage)
+	tracker.Report(p1, Timeout)
+	assert.Equal(t, float64(MaxScore-defInvalidMessagePenalty-defTimeoutPenalty), tracker.Score(p1))
+
+	// Half of the lost score is recovered after a half-life
+	clock.advance(time.Minute)
+	assert.InDelta(t, MaxScore-float64(defInvalidMessagePenalty+defTimeoutPenalty)/2, tracker.Score(p1), 0.001)
+	assert.Len(t, tracker.Scores(), 1)
+	assert.InDelta(t, tracker.Score(p1), tracker.Scores()[hex.EncodeToString(p1)], 0.001)
+
+	// Recovered peers are forgotten
+	clock.advance(time.Hour)
+	tracker.Report(common.PKIidType("p2"), Timeout)
+	assert.Len(t, tracker.Scores(), 1)
+	assert.Equal(t, float64(MaxScore), tracker.Score(p1))
+}
+
+func TestQuarantine(t *testing.T) {
+	tracker, clock := newTestTracker(Config{
+		FailedVerificationPenalty: 25,
+		QuarantineDuration:        time.Minute,
+		RecoveryHalfLife:          time.Hour,
+	})
+	good, bad, suspicious := common.PKIidType("good"), common.PKIidType("bad"), common.PKIidType("suspicious")
+	members := []discovery.NetworkMember{{PKIid: good}, {PKIid: bad}, {PKIid: suspicious}}
+
+	tracker.Report(suspicious, FailedVerification)
+	tracker.Report(suspicious, InvalidMessage)
+	for i := 0; i < 2; i++ {
+		tracker.Report(bad, FailedVerification)
+	}
+	assert.False(t, tracker.IsQuarantined(bad))
+
+	preferred, deprioritized := tracker.Partition(members)
+	assert.Equal(t, []discovery.NetworkMember{{PKIid: good}}, preferred)
+	assert.Equal(t, []discovery.NetworkMember{{PKIid: bad}, {PKIid: suspicious}}, deprioritized)
+
+	// Falling below the quarantine threshold
+	tracker.Report(bad, FailedVerification)
+	assert.True(t, tracker.IsQuarantined(bad))
+	assert.Equal(t, float64(defQuarantineThreshold), tracker.Score(bad))
+	preferred, deprioritized = tracker.Partition(members)
+	assert.Equal(t, []discovery.NetworkMember{{PKIid: good}}, preferred)
+	assert.Equal(t, []discovery.NetworkMember{{PKIid: suspicious}}, deprioritized)
+	assert.Equal(t, []discovery.NetworkMember{{PKIid: good}, {PKIid: suspicious}}, tracker.Filter(members))
+
+	// The peer leaves the quarantine deprioritized
+	clock.advance(time.Minute)
+	assert.False(t, tracker.IsQuarantined(bad))
+	_, deprioritized = tracker.Partition(members)
+	assert.Equal(t, []discovery.NetworkMember{{PKIid: bad}, {PKIid: suspicious}}, deprioritized)
+
+	// and is quarantined again upon its next misbehavior
+	tracker.Report(bad, Timeout)
+	assert.True(t, tracker.IsQuarantined(bad))
+}
+
+func TestMetrics(t *testing.T) {
+	provider := &mockMetricsProvider{metrics: make(map[string]*mockMetric)}
+	tracker, _ := newTestTracker(Config{MetricsProvider: provider, QuarantineThreshold: 85})
+	p1 := common.PKIidType("p1")
+
+	tracker.Report(p1, InvalidMessage)
+	tracker.Report(p1, Timeout)
+	assert.Equal(t, map[string]float64{"peer," + hex.EncodeToString(p1) + ",": 85}, provider.metrics[PeerScore].values)
+	assert.Equal(t, map[string]float64{"event,invalid_message,": 1, "event,timeout,": 1}, provider.metrics[ReputationEvents].values)
+	assert.Empty(t, provider.metrics[Quarantines].values)
+	assert.Equal(t, float64(0), provider.metrics[QuarantinedPeers].values[""])
+
+	tracker.Report(p1, Timeout)
+	assert.Equal(t, float64(1), provider.metrics[Quarantines].values[""])
+	assert.Equal(t, float64(1), provider.metrics[QuarantinedPeers].values[""])
+	assert.Equal(t, float64(85), provider.metrics[PeerScore].values["peer,"+hex.EncodeToString(p1)+","])
+}
@@ -51,6 +51,13 @@ type configEventReceiver interface {
 	configUpdated(config Config)
 }
 
+// configChangeReceiver is implemented by the configEventReceivers that
+// need to be notified of every configuration update, such as updates
+// of MSPs or CRLs, and not only of those changing anchor peers
+type configChangeReceiver interface {
+	configChanged(config Config)
+}
+
 type configEventer struct {
 	lastConfig *configStore
 	receiver   configEventReceiver
@@ -69,6 +76,10 @@ func newConfigEventer(receiver configEventReceiver) *configEventer {
 func (ce *configEventer) ProcessConfigUpdate(config Config) {
 	logger.Debugf("Processing new config for channel %s", config.ChainID())
 
+	if receiver, ok := ce.receiver.(configChangeReceiver); ok {
+		receiver.configChanged(config)
+	}
+
 	if ce.lastConfig != nil && reflect.DeepEqual(ce.lastConfig.orgMap, config.Organizations()) {
 		logger.Debugf("Ignoring new config for channel %s because it contained no anchor peer updates", config.ChainID())
 		return
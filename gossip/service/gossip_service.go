@@ -17,6 +17,8 @@ limitations under the License.
 package service
 
 import (
+	"bytes"
+	"fmt"
 	"sync"
 
 	peerComm "github.com/hyperledger/fabric/core/comm"
@@ -24,11 +26,14 @@ import (
 	"github.com/hyperledger/fabric/core/deliverservice"
 	"github.com/hyperledger/fabric/gossip/api"
 	gossipCommon "github.com/hyperledger/fabric/gossip/common"
+	"github.com/hyperledger/fabric/gossip/election"
 	"github.com/hyperledger/fabric/gossip/gossip"
 	"github.com/hyperledger/fabric/gossip/identity"
 	"github.com/hyperledger/fabric/gossip/integration"
 	"github.com/hyperledger/fabric/gossip/state"
 	"github.com/hyperledger/fabric/gossip/util"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/msp/mgmt"
 	"github.com/hyperledger/fabric/peer/gossip/sa"
 	"github.com/hyperledger/fabric/protos/common"
 	proto "github.com/hyperledger/fabric/protos/gossip"
@@ -55,6 +60,10 @@ type GossipService interface {
 	GetBlock(chainID string, index uint64) *common.Block
 	// AddPayload appends message payload to for given chain
 	AddPayload(chainID string, payload *proto.Payload) error
+	// LeadershipState returns the state of the leader election of given chain
+	LeadershipState(chainID string) (election.LeadershipState, error)
+	// OverrideLeadership manually overrides the leader election of given chain
+	OverrideLeadership(chainID string, o election.Override) (election.LeadershipState, error)
 }
 
 // DeliveryServiceFactory factory to create and initialize delivery service instance
@@ -74,14 +83,31 @@ func (*deliveryFactoryImpl) Service(g GossipService) (deliverclient.DeliverServi
 type gossipServiceImpl struct {
 	gossipSvc
 	chains          map[string]state.GossipStateProvider
+	leaderElection  map[string]election.LeaderElectionService
 	deliveryService deliverclient.DeliverService
 	deliveryFactory DeliveryServiceFactory
 	lock            sync.RWMutex
 	msgCrypto       identity.Mapper
+	mcs             api.MessageCryptoService
 	peerIdentity    []byte
 	secAdv          api.SecurityAdvisor
 }
 
+// channelConfigListener is implemented by the MessageCryptoServices
+// that need to be notified of the configuration updates of channels
+type channelConfigListener interface {
+	// ChannelConfigUpdated is invoked whenever the
+	// configuration of channel chainID is updated
+	ChannelConfigUpdated(chainID gossipCommon.ChainID)
+}
+
+// localMSPListener is implemented by the MessageCryptoServices
+// that need to be notified of the reloads of the local MSP
+type localMSPListener interface {
+	// LocalMSPUpdated is invoked whenever the local MSP is reloaded
+	LocalMSPUpdated()
+}
+
 // This is an implementation of api.JoinChannelMessage.
 type joinChannelMessage struct {
 	seqNum      uint64
@@ -134,14 +160,36 @@ func InitGossipServiceCustomDeliveryFactory(peerIdentity []byte, endpoint string
 		gossipServiceInstance = &gossipServiceImpl{
 			gossipSvc:       gossip,
 			chains:          make(map[string]state.GossipStateProvider),
+			leaderElection:  make(map[string]election.LeaderElectionService),
 			deliveryFactory: factory,
 			msgCrypto:       idMapper,
+			mcs:             mcs,
 			peerIdentity:    peerIdentity,
 			secAdv:          secAdv,
 		}
+		mgmt.SubscribeLocalMSPUpdates(gossipServiceInstance.localMSPUpdated)
 	})
 }
 
+// localMSPUpdated notifies the MessageCryptoService of a reload of the
+// local MSP. The identity of the peer advertised by gossip is fixed at
+// initialization, hence a new signing identity requires a restart.
+func (g *gossipServiceImpl) localMSPUpdated(lclMsp msp.MSP) {
+	if listener, ok := g.mcs.(localMSPListener); ok {
+		listener.LocalMSPUpdated()
+	}
+
+	signer, err := lclMsp.GetDefaultSigningIdentity()
+	if err != nil {
+		logger.Warning("Reloaded local MSP has no signing identity:", err)
+		return
+	}
+	peerIdentity, err := signer.Serialize()
+	if err != nil || !bytes.Equal(peerIdentity, g.peerIdentity) {
+		logger.Warning("The signing identity of the peer changed, the peer must be restarted for gossip to advertise it")
+	}
+}
+
 // GetGossipService returns an instance of gossip service
 func GetGossipService() GossipService {
 	return gossipServiceInstance
@@ -158,7 +206,7 @@ func (g *gossipServiceImpl) InitializeChannel(chainID string, committer committe
 	defer g.lock.Unlock()
 	// Initialize new state provider for given committer
 	logger.Debug("Creating state provider for chainID", chainID)
-	g.chains[chainID] = state.NewGossipStateProvider(chainID, g, committer)
+	g.chains[chainID] = state.NewGossipStateProvider(chainID, g, committer, g.mcs)
 	if g.deliveryService == nil {
 		var err error
 		g.deliveryService, err = g.deliveryFactory.Service(gossipServiceInstance)
@@ -176,6 +224,15 @@ func (g *gossipServiceImpl) InitializeChannel(chainID string, committer committe
 	}
 }
 
+// configChanged notifies the MessageCryptoService of every
+// configuration update, so that it drops what it cached under
+// the previous configuration
+func (g *gossipServiceImpl) configChanged(config Config) {
+	if listener, ok := g.mcs.(channelConfigListener); ok {
+		listener.ChannelConfigUpdated(gossipCommon.ChainID(config.ChainID()))
+	}
+}
+
 // configUpdated constructs a joinChannelMessage and sends it to the gossipSvc
 func (g *gossipServiceImpl) configUpdated(config Config) {
 	myOrg := string(g.secAdv.OrgByPeerIdentity(api.PeerIdentityType(g.peerIdentity)))
@@ -215,10 +272,45 @@ func (g *gossipServiceImpl) AddPayload(chainID string, payload *proto.Payload) e
 	return g.chains[chainID].AddPayload(payload)
 }
 
+// LeadershipState returns the state of the leader election of given chain
+func (g *gossipServiceImpl) LeadershipState(chainID string) (election.LeadershipState, error) {
+	le, err := g.getLeaderElection(chainID)
+	if err != nil {
+		return election.LeadershipState{}, err
+	}
+	return le.State(), nil
+}
+
+// OverrideLeadership manually overrides the leader election of given chain,
+// e.g. to have its leader relinquish the leadership during maintenance
+func (g *gossipServiceImpl) OverrideLeadership(chainID string, o election.Override) (election.LeadershipState, error) {
+	le, err := g.getLeaderElection(chainID)
+	if err != nil {
+		return election.LeadershipState{}, err
+	}
+	logger.Warning("Overriding the leader election of chain", chainID, "with", o)
+	le.SetOverride(o)
+	return le.State(), nil
+}
+
+func (g *gossipServiceImpl) getLeaderElection(chainID string) (election.LeaderElectionService, error) {
+	g.lock.RLock()
+	defer g.lock.RUnlock()
+	le, exists := g.leaderElection[chainID]
+	if !exists {
+		return nil, fmt.Errorf("Leader election is not enabled for chain %s", chainID)
+	}
+	return le, nil
+}
+
 // Stop stops the gossip component
 func (g *gossipServiceImpl) Stop() {
 	g.lock.Lock()
 	defer g.lock.Unlock()
+	for chainID, le := range g.leaderElection {
+		logger.Info("Stopping leader election for chain", chainID)
+		le.Stop()
+	}
 	for _, ch := range g.chains {
 		logger.Info("Stopping chain", ch)
 		ch.Stop()
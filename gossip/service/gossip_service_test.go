@@ -26,6 +26,7 @@ import (
 
 	mockpolicies "github.com/hyperledger/fabric/common/mocks/policies"
 	"github.com/hyperledger/fabric/gossip/api"
+	"github.com/hyperledger/fabric/gossip/election"
 	"github.com/hyperledger/fabric/msp/mgmt"
 	"github.com/hyperledger/fabric/msp/mgmt/testtools"
 	"github.com/hyperledger/fabric/peer/gossip/mcs"
@@ -72,3 +73,45 @@ func TestInitGossipService(t *testing.T) {
 func TestJCMInterface(t *testing.T) {
 	_ = api.JoinChannelMessage(&joinChannelMessage{})
 }
+
+type mockLeaderElection struct {
+	state election.LeadershipState
+}
+
+func (le *mockLeaderElection) IsLeader() bool {
+	return le.state.IsLeader
+}
+
+func (le *mockLeaderElection) State() election.LeadershipState {
+	return le.state
+}
+
+func (le *mockLeaderElection) SetOverride(o election.Override) {
+	le.state.Override = o
+	le.state.IsLeader = o == election.Acquired
+}
+
+func (le *mockLeaderElection) Stop() {
+}
+
+func TestLeaderElection(t *testing.T) {
+	g := &gossipServiceImpl{
+		leaderElection: make(map[string]election.LeaderElectionService),
+	}
+
+	// Leader election is not enabled for the chain
+	_, err := g.LeadershipState("A")
+	assert.Error(t, err)
+	_, err = g.OverrideLeadership("A", election.Acquired)
+	assert.Error(t, err)
+
+	g.leaderElection["A"] = &mockLeaderElection{state: election.LeadershipState{Leader: "p0", Term: 3}}
+	state, err := g.LeadershipState("A")
+	assert.NoError(t, err)
+	assert.Equal(t, election.LeadershipState{Leader: "p0", Term: 3}, state)
+
+	state, err = g.OverrideLeadership("A", election.Acquired)
+	assert.NoError(t, err)
+	assert.True(t, state.IsLeader)
+	assert.Equal(t, election.Acquired, state.Override)
+}
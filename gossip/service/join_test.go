@@ -25,6 +25,8 @@ import (
 	"github.com/hyperledger/fabric/gossip/comm"
 	"github.com/hyperledger/fabric/gossip/common"
 	"github.com/hyperledger/fabric/gossip/discovery"
+	gossip2 "github.com/hyperledger/fabric/gossip/gossip"
+	"github.com/hyperledger/fabric/msp/mgmt"
 	"github.com/hyperledger/fabric/protos/gossip"
 	"github.com/hyperledger/fabric/protos/peer"
 	"github.com/stretchr/testify/assert"
@@ -54,6 +56,10 @@ func (*gossipMock) PeersOfChannel(common.ChainID) []discovery.NetworkMember {
 	panic("implement me")
 }
 
+func (*gossipMock) MembershipSnapshot() (discovery.NetworkMember, []discovery.MemberState) {
+	panic("implement me")
+}
+
 func (*gossipMock) UpdateMetadata(metadata []byte) {
 	panic("implement me")
 }
@@ -74,6 +80,10 @@ func (g *gossipMock) JoinChan(joinMsg api.JoinChannelMessage, chainID common.Cha
 	g.Called()
 }
 
+func (*gossipMock) AddEgressFilter(filter gossip2.EgressFilter) {
+	panic("implement me")
+}
+
 func (*gossipMock) Stop() {
 	panic("implement me")
 }
@@ -142,3 +152,50 @@ func TestJoinChannelConfig(t *testing.T) {
 
 	}
 }
+
+type configListenerMCS struct {
+	api.MessageCryptoService
+	updated         []common.ChainID
+	localMSPUpdates int
+}
+
+func (m *configListenerMCS) ChannelConfigUpdated(chainID common.ChainID) {
+	m.updated = append(m.updated, chainID)
+}
+
+func TestConfigUpdateNotifiesMessageCryptoService(t *testing.T) {
+	svcMock := &gossipMock{}
+	svcMock.On("JoinChan", mock.Anything)
+	mcs := &configListenerMCS{}
+	g := &gossipServiceImpl{secAdv: &secAdvMock{}, peerIdentity: api.PeerIdentityType("Org0"), gossipSvc: svcMock, mcs: mcs}
+	ce := g.NewConfigEventer()
+	ce.ProcessConfigUpdate(&configMock{})
+	assert.Equal(t, []common.ChainID{common.ChainID("A")}, mcs.updated)
+	svcMock.AssertNumberOfCalls(t, "JoinChan", 1)
+
+	// Even if the update changes no anchor peer, such as an update of MSPs
+	ce.ProcessConfigUpdate(&configMock{})
+	assert.Len(t, mcs.updated, 2)
+	svcMock.AssertNumberOfCalls(t, "JoinChan", 1)
+
+	// Even if this peer's org is not in the channel
+	g.peerIdentity = api.PeerIdentityType("OrgMSP0")
+	g.NewConfigEventer().ProcessConfigUpdate(&configMock{})
+	assert.Len(t, mcs.updated, 3)
+	svcMock.AssertNumberOfCalls(t, "JoinChan", 1)
+}
+
+func (m *configListenerMCS) LocalMSPUpdated() {
+	m.localMSPUpdates++
+}
+
+func TestLocalMSPUpdateNotifiesMessageCryptoService(t *testing.T) {
+	mcs := &configListenerMCS{}
+	g := &gossipServiceImpl{secAdv: &secAdvMock{}, peerIdentity: api.PeerIdentityType("Org0"), gossipSvc: &gossipMock{}, mcs: mcs}
+	g.localMSPUpdated(mgmt.GetLocalMSP())
+	assert.Equal(t, 1, mcs.localMSPUpdates)
+
+	// MessageCryptoServices not listening are not notified
+	g.mcs = &secImpl{}
+	g.localMSPUpdated(mgmt.GetLocalMSP())
+}
@@ -25,6 +25,7 @@ import (
 
 	pb "github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/core/committer"
+	"github.com/hyperledger/fabric/gossip/api"
 	"github.com/hyperledger/fabric/gossip/comm"
 	common2 "github.com/hyperledger/fabric/gossip/common"
 	"github.com/hyperledger/fabric/gossip/gossip"
@@ -54,6 +55,10 @@ var remoteStateMsgFilter = func(message interface{}) bool {
 const (
 	defPollingPeriod       = 200 * time.Millisecond
 	defAntiEntropyInterval = 10 * time.Second
+	defStateWorkers        = 4
+	defStateBatchSize      = 10
+	defStateRequestTimeout = 5 * time.Second
+	defStateRequestRetries = 3
 )
 
 // GossipStateProviderImpl the implementation of the GossipStateProvider interface
@@ -81,13 +86,25 @@ type GossipStateProviderImpl struct {
 
 	committer committer.Committer
 
+	mcs api.MessageCryptoService
+
+	// Number of block ranges requested in parallel during state transfer
+	workers int
+
+	// Number of blocks requested at once
+	batchSize uint64
+
+	// Nonces of pending state requests, mapped to the
+	// channels their responses are signaled on
+	pendingRequests map[uint64]chan struct{}
+
 	logger *logging.Logger
 
 	done sync.WaitGroup
 }
 
 // NewGossipStateProvider creates initialized instance of gossip state provider
-func NewGossipStateProvider(chainID string, g gossip.Gossip, committer committer.Committer) GossipStateProvider {
+func NewGossipStateProvider(chainID string, g gossip.Gossip, committer committer.Committer, mcs api.MessageCryptoService) GossipStateProvider {
 	logger := util.GetLogger(util.LoggingStateModule, "")
 
 	gossipChan, _ := g.Accept(func(message interface{}) bool {
@@ -126,6 +143,14 @@ func NewGossipStateProvider(chainID string, g gossip.Gossip, committer committer
 
 		committer: committer,
 
+		mcs: mcs,
+
+		workers: util.GetIntOrDefault("peer.gossip.state.workers", defStateWorkers),
+
+		batchSize: uint64(util.GetIntOrDefault("peer.gossip.state.batchSize", defStateBatchSize)),
+
+		pendingRequests: make(map[uint64]chan struct{}),
+
 		logger: logger,
 	}
 
@@ -224,9 +249,10 @@ func (s *GossipStateProviderImpl) handleStateRequest(msg proto.ReceivedMessage)
 			Hash:   string(blocks[0].Header.Hash()),
 		})
 	}
-	// Sending back response with missing blocks
+	// Sending back response with missing blocks, with the nonce
+	// of the request to allow matching it with its response
 	msg.Respond(&proto.GossipMessage{
-		Nonce:   0,
+		Nonce:   msg.GetGossipMessage().Nonce,
 		Tag:     proto.GossipMessage_CHAN_OR_ORG,
 		Channel: []byte(s.chainID),
 		Content: &proto.GossipMessage_StateResponse{response},
@@ -237,11 +263,22 @@ func (s *GossipStateProviderImpl) handleStateResponse(msg proto.ReceivedMessage)
 	response := msg.GetGossipMessage().GetStateResponse()
 	for _, payload := range response.GetPayloads() {
 		s.logger.Debugf("Received payload with sequence number %d.", payload.SeqNum)
+		if err := s.mcs.VerifyBlock(common2.ChainID(s.chainID), payload); err != nil {
+			s.logger.Warningf("Could not verify block with sequence number %d, dropping it: %s", payload.SeqNum, err)
+			continue
+		}
 		err := s.payloads.Push(payload)
 		if err != nil {
 			s.logger.Warningf("Payload with sequence number %d was received earlier", payload.SeqNum)
 		}
 	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if responseChan, exists := s.pendingRequests[msg.GetGossipMessage().Nonce]; exists {
+		responseChan <- struct{}{}
+		delete(s.pendingRequests, msg.GetGossipMessage().Nonce)
+	}
 }
 
 // Internal function to check whenever we need to finish listening
@@ -334,46 +371,155 @@ func (s *GossipStateProviderImpl) antiEntropy() {
 	s.done.Done()
 }
 
-// GetBlocksInRange capable to acquire blocks with sequence
-// numbers in the range [start...end].
+// requestBlocksInRange acquires the blocks with sequence numbers in the range
+// [start...end], split into batches requested in parallel from the peers that
+// have them. The blocks are committed in order as soon as they arrive, while
+// the next batches are requested. At most workers batches are requested ahead
+// of the next block to commit.
 func (s *GossipStateProviderImpl) requestBlocksInRange(start uint64, end uint64) {
-	var peers []*comm.RemotePeer
-	// Filtering peers which might have relevant blocks
+	heights := make(map[*comm.RemotePeer]uint64)
 	for _, netMember := range s.gossip.PeersOfChannel(common2.ChainID(s.chainID)) {
 		nodeMetadata, err := FromBytes(netMember.Metadata)
 		if err == nil {
-			if nodeMetadata.LedgerHeight >= end {
-				peers = append(peers, &comm.RemotePeer{Endpoint: netMember.PreferredEndpoint(), PKIID: netMember.PKIid})
-			}
+			heights[&comm.RemotePeer{Endpoint: netMember.PreferredEndpoint(), PKIID: netMember.PKIid}] = nodeMetadata.LedgerHeight
 		} else {
 			s.logger.Errorf("Unable to de-serialize node meta state, error = %s", err)
 		}
 	}
 
+	s.logger.Infof("State transfer of blocks in range [%d, %d] with %d workers", start, end, s.workers)
+
+	var lock sync.Mutex
+	next := start
+	failed := false
+	// nextBatch returns the next batch to request, or false
+	// if no batch is left or a batch could not be acquired
+	nextBatch := func() (uint64, uint64, bool) {
+		lock.Lock()
+		defer lock.Unlock()
+		if failed || next > end {
+			return 0, 0, false
+		}
+		from := next
+		to := from + s.batchSize - 1
+		if to > end {
+			to = end
+		}
+		next = to + 1
+		return from, to, true
+	}
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < s.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for from, to, ok := nextBatch(); ok && !s.isDone(); from, to, ok = nextBatch() {
+				if !s.waitForCommits(from) || !s.requestBlocks(from, to, heights) {
+					lock.Lock()
+					failed = true
+					lock.Unlock()
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// waitForCommits waits until the block with sequence number from is within
+// the blocks that may be requested ahead of the next block to commit, and
+// returns false if the state provider stops or commits stall meanwhile
+func (s *GossipStateProviderImpl) waitForCommits(from uint64) bool {
+	window := uint64(s.workers) * s.batchSize
+	next := s.payloads.Next()
+	deadline := time.Now().Add(defStateRequestTimeout)
+	for from >= s.payloads.Next()+window {
+		if s.isDone() {
+			return false
+		}
+		if current := s.payloads.Next(); current != next {
+			next = current
+			deadline = time.Now().Add(defStateRequestTimeout)
+		} else if time.Now().After(deadline) {
+			s.logger.Warningf("No block committed since block %d, stopping state transfer", next)
+			return false
+		}
+		time.Sleep(defPollingPeriod)
+	}
+	return true
+}
+
+// requestBlocks requests the blocks with sequence numbers in the range
+// [from...to] from peers which have them, until a peer responds, and
+// returns false if none did
+func (s *GossipStateProviderImpl) requestBlocks(from uint64, to uint64, heights map[*comm.RemotePeer]uint64) bool {
+	var peers []*comm.RemotePeer
+	for peer, height := range heights {
+		if height >= to {
+			peers = append(peers, peer)
+		}
+	}
+
 	n := len(peers)
 	if n == 0 {
-		s.logger.Warningf("There is not peer nodes to ask for missing blocks in range [%d, %d)", start, end)
-		return
+		s.logger.Warningf("There is not peer nodes to ask for missing blocks in range [%d, %d]", from, to)
+		return false
 	}
-	// Select peers to ask for blocks
-	peer := peers[rand.Intn(n)]
-	s.logger.Infof("State transfer, with peer %s, the min available sequence number %d next block %d", peer.Endpoint, start, end)
 
 	request := &proto.RemoteStateRequest{
 		SeqNums: make([]uint64, 0),
 	}
 
-	for i := start; i <= end; i++ {
+	for i := from; i <= to; i++ {
 		request.SeqNums = append(request.SeqNums, uint64(i))
 	}
 
-	s.logger.Debug("Sending direct request to complete missing blocks,", request, "for chain", s.chainID)
-	s.gossip.Send(&proto.GossipMessage{
-		Nonce:   0,
-		Tag:     proto.GossipMessage_CHAN_OR_ORG,
-		Channel: []byte(s.chainID),
-		Content: &proto.GossipMessage_StateRequest{request},
-	}, peer)
+	for retry := 0; retry < defStateRequestRetries && !s.isDone(); retry++ {
+		// Select peers to ask for blocks
+		peer := peers[rand.Intn(n)]
+		s.logger.Debugf("State transfer, with peer %s, of blocks in range [%d, %d]", peer.Endpoint, from, to)
+
+		nonce := uint64(rand.Int63())
+		responseChan := make(chan struct{}, 1)
+		s.mutex.Lock()
+		s.pendingRequests[nonce] = responseChan
+		s.mutex.Unlock()
+
+		s.logger.Debug("Sending direct request to complete missing blocks,", request, "for chain", s.chainID)
+		s.gossip.Send(&proto.GossipMessage{
+			Nonce:   nonce,
+			Tag:     proto.GossipMessage_CHAN_OR_ORG,
+			Channel: []byte(s.chainID),
+			Content: &proto.GossipMessage_StateRequest{request},
+		}, peer)
+
+		if s.waitForResponse(responseChan) {
+			return true
+		}
+
+		s.mutex.Lock()
+		delete(s.pendingRequests, nonce)
+		s.mutex.Unlock()
+		s.logger.Warningf("Peer %s did not respond to the request of blocks in range [%d, %d]", peer.Endpoint, from, to)
+	}
+	return false
+}
+
+// waitForResponse waits for a signal on responseChan, and returns false
+// if the state provider stops or no signal arrives within the timeout
+func (s *GossipStateProviderImpl) waitForResponse(responseChan chan struct{}) bool {
+	timeout := time.After(defStateRequestTimeout)
+	for !s.isDone() {
+		select {
+		case <-responseChan:
+			return true
+		case <-timeout:
+			return false
+		case <-time.After(defPollingPeriod):
+		}
+	}
+	return false
 }
 
 // GetBlock return ledger block given its sequence number as a parameter
@@ -194,7 +194,7 @@ func newPeerNode(config *gossip.Config, committer committer.Committer) *peerNode
 	// basic parts
 	return &peerNode{
 		g: gossip,
-		s: NewGossipStateProvider(util.GetTestChainID(), gossip, committer),
+		s: NewGossipStateProvider(util.GetTestChainID(), gossip, committer, &naiveCryptoService{}),
 
 		commit: committer,
 	}
@@ -413,6 +413,98 @@ func TestGossipStateProvider_TestStateMessages(t *testing.T) {
 	}
 }
 
+func TestGossipStateProvider_ParallelStateTransfer(t *testing.T) {
+	viper.Set("peer.fileSystemPath", "/tmp/tests/ledger/node")
+	viper.Set("peer.gossip.state.workers", 3)
+	viper.Set("peer.gossip.state.batchSize", 4)
+	ledgermgmt.InitializeTestEnv()
+	defer func() {
+		viper.Set("peer.gossip.state.workers", 0)
+		viper.Set("peer.gossip.state.batchSize", 0)
+		ledgermgmt.CleanupTestEnv()
+	}()
+
+	bootPeer := newPeerNode(newGossipConfig(20, 100), newCommitter(20))
+	defer bootPeer.shutdown()
+
+	msgCount := 30
+	for i := 1; i <= msgCount; i++ {
+		rawblock := pcomm.NewBlock(uint64(i), []byte{})
+		bytes, err := pb.Marshal(rawblock)
+		assert.NoError(t, err)
+		bootPeer.s.AddPayload(&proto.Payload{SeqNum: uint64(i), Data: bytes})
+	}
+
+	peer := newPeerNode(newGossipConfig(21, 100, 20), newCommitter(21))
+	defer peer.shutdown()
+	assert.Equal(t, 3, peer.s.(*GossipStateProviderImpl).workers)
+	assert.Equal(t, uint64(4), peer.s.(*GossipStateProviderImpl).batchSize)
+
+	waitUntilTrueOrTimeout(t, func() bool {
+		height, err := peer.commit.LedgerHeight()
+		return err == nil && height == uint64(msgCount+1)
+	}, 2*defAntiEntropyInterval+10*time.Second)
+}
+
+type rejectingCryptoService struct {
+	naiveCryptoService
+	rejected uint64
+}
+
+func (cs *rejectingCryptoService) VerifyBlock(chainID common.ChainID, signedBlock api.SignedBlock) error {
+	if signedBlock.(*proto.Payload).SeqNum == cs.rejected {
+		return fmt.Errorf("Block %d is rejected", cs.rejected)
+	}
+	return nil
+}
+
+type receivedMessage struct {
+	msg *proto.SignedGossipMessage
+}
+
+func (m *receivedMessage) Respond(msg *proto.GossipMessage) {
+}
+
+func (m *receivedMessage) GetGossipMessage() *proto.SignedGossipMessage {
+	return m.msg
+}
+
+func (m *receivedMessage) GetSourceEnvelope() *proto.Envelope {
+	return m.msg.Envelope
+}
+
+func (m *receivedMessage) GetPKIID() common.PKIidType {
+	return nil
+}
+
+func TestGossipStateProvider_VerifyStateResponse(t *testing.T) {
+	s := &GossipStateProviderImpl{
+		chainID:         util.GetTestChainID(),
+		payloads:        NewPayloadsBuffer(1),
+		mcs:             &rejectingCryptoService{rejected: 2},
+		pendingRequests: make(map[uint64]chan struct{}),
+		logger:          logger,
+	}
+	responseChan := make(chan struct{}, 1)
+	s.pendingRequests[42] = responseChan
+
+	s.handleStateResponse(&receivedMessage{msg: (&proto.GossipMessage{
+		Nonce: 42,
+		Content: &proto.GossipMessage_StateResponse{StateResponse: &proto.RemoteStateResponse{
+			Payloads: []*proto.Payload{{SeqNum: 1}, {SeqNum: 2}, {SeqNum: 3}},
+		}},
+	}).NoopSign()})
+
+	// The rejected block is not buffered
+	assert.Equal(t, 2, s.payloads.Size())
+	assert.Equal(t, uint64(1), s.payloads.Pop().SeqNum)
+	assert.Nil(t, s.payloads.Pop())
+
+	// The pending request got its response
+	assert.Len(t, responseChan, 1)
+	assert.Empty(t, s.pendingRequests)
+}
+
 func waitUntilTrueOrTimeout(t *testing.T, predicate func() bool, timeout time.Duration) {
 	ch := make(chan struct{})
 	go func() {
@@ -28,15 +28,16 @@ import (
 
 // Module names for logger initialization.
 const (
-	LoggingChannelModule   = "gossip/channel"
-	LoggingCommModule      = "gossip/comm"
-	LoggingDiscoveryModule = "gossip/discovery"
-	LoggingElectionModule  = "gossip/election"
-	LoggingGossipModule    = "gossip/gossip"
-	LoggingMockModule      = "gossip/comm/mock"
-	LoggingPullModule      = "gossip/pull"
-	LoggingServiceModule   = "gossip/service"
-	LoggingStateModule     = "gossip/state"
+	LoggingChannelModule    = "gossip/channel"
+	LoggingCommModule       = "gossip/comm"
+	LoggingDiscoveryModule  = "gossip/discovery"
+	LoggingElectionModule   = "gossip/election"
+	LoggingGossipModule     = "gossip/gossip"
+	LoggingMockModule       = "gossip/comm/mock"
+	LoggingPullModule       = "gossip/pull"
+	LoggingReputationModule = "gossip/reputation"
+	LoggingServiceModule    = "gossip/service"
+	LoggingStateModule      = "gossip/state"
 )
 
 var loggersByModules = make(map[string]*logging.Logger)
@@ -0,0 +1,114 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package msp
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hyperledger/fabric/protos/common"
+)
+
+// attributesOID is the ASN.1 object identifier of the
+// x.509 extension Fabric CA stores identity attributes in
+var attributesOID = asn1.ObjectIdentifier{1, 2, 3, 4, 5, 6, 7, 8, 1}
+
+// satisfiesAttribute returns nil if id, a valid member of this MSP,
+// carries the x.509 extension or holds the Fabric CA attribute of attr
+func (msp *bccspmsp) satisfiesAttribute(id *identity, attr *common.MSPAttribute) error {
+	if attr.ExtensionOid != "" {
+		oid, err := parseOID(attr.ExtensionOid)
+		if err != nil {
+			return err
+		}
+
+		for _, ext := range id.cert.Extensions {
+			if !ext.Id.Equal(oid) {
+				continue
+			}
+			if len(attr.ExtensionValue) == 0 || bytes.Equal(ext.Value, attr.ExtensionValue) {
+				return nil
+			}
+			return fmt.Errorf("The identity carries extension %s with a different value", attr.ExtensionOid)
+		}
+		return fmt.Errorf("The identity does not carry extension %s", attr.ExtensionOid)
+	}
+
+	if attr.Name == "" {
+		return errors.New("Invalid attribute principal: neither an attribute name nor an extension")
+	}
+
+	attrs, err := getFabricCAAttributes(id.cert)
+	if err != nil {
+		return err
+	}
+	value, ok := attrs[attr.Name]
+	if !ok {
+		return fmt.Errorf("The identity does not hold attribute %s", attr.Name)
+	}
+	if attr.Value != "" && value != attr.Value {
+		return fmt.Errorf("The identity holds attribute %s with a different value", attr.Name)
+	}
+	return nil
+}
+
+// getFabricCAAttributes returns the attributes Fabric CA issued cert with
+func getFabricCAAttributes(cert *x509.Certificate) (map[string]string, error) {
+	attrs := make(map[string]string)
+	for _, ext := range cert.Extensions {
+		if !ext.Id.Equal(attributesOID) {
+			continue
+		}
+
+		caAttrs := &struct {
+			Attrs map[string]string `json:"attrs"`
+		}{}
+		err := json.Unmarshal(ext.Value, caAttrs)
+		if err != nil {
+			return nil, fmt.Errorf("Failed unmarshalling attributes, err %s", err)
+		}
+		for name, value := range caAttrs.Attrs {
+			attrs[name] = value
+		}
+	}
+
+	return attrs, nil
+}
+
+// parseOID parses an object identifier in dotted form
+func parseOID(s string) (asn1.ObjectIdentifier, error) {
+	parts := strings.Split(s, ".")
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("Invalid object identifier %s", s)
+	}
+
+	oid := make(asn1.ObjectIdentifier, len(parts))
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("Invalid object identifier %s", s)
+		}
+		oid[i] = n
+	}
+	return oid, nil
+}
@@ -0,0 +1,82 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package msp
+
+import (
+	"crypto/x509/pkix"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func attributePrincipal(t *testing.T, attr *common.MSPAttribute) *common.MSPPrincipal {
+	raw, err := proto.Marshal(attr)
+	assert.NoError(t, err)
+	return &common.MSPPrincipal{PrincipalClassification: common.MSPPrincipal_ATTRIBUTE, Principal: raw}
+}
+
+func TestAttributePrincipals(t *testing.T) {
+	thisMSP, serializedID := newCriticalExtensionsMSP(t, pkix.Extension{
+		Id:    attributesOID,
+		Value: []byte(`{"attrs":{"department":"treasury","hf.Type":"client"}}`),
+	})
+	id, err := thisMSP.DeserializeIdentity(serializedID)
+	assert.NoError(t, err)
+
+	for _, test := range []struct {
+		attr      *common.MSPAttribute
+		satisfied bool
+	}{
+		// Fabric CA attributes
+		{&common.MSPAttribute{MspIdentifier: "CRITEXT", Name: "department", Value: "treasury"}, true},
+		{&common.MSPAttribute{MspIdentifier: "CRITEXT", Name: "department"}, true},
+		{&common.MSPAttribute{MspIdentifier: "CRITEXT", Name: "department", Value: "sales"}, false},
+		{&common.MSPAttribute{MspIdentifier: "CRITEXT", Name: "role"}, false},
+		// x.509 extensions
+		{&common.MSPAttribute{MspIdentifier: "CRITEXT", ExtensionOid: "1.2.3.4.5.6.7.8.1"}, true},
+		{&common.MSPAttribute{MspIdentifier: "CRITEXT", ExtensionOid: "1.2.3.4.5.6.7.8.1", ExtensionValue: []byte("{}")}, false},
+		{&common.MSPAttribute{MspIdentifier: "CRITEXT", ExtensionOid: "1.2.3.4.5.99"}, false},
+		{&common.MSPAttribute{MspIdentifier: "CRITEXT", ExtensionOid: "1.x"}, false},
+		// Other MSPs
+		{&common.MSPAttribute{MspIdentifier: "OTHER", Name: "department", Value: "treasury"}, false},
+		// Neither attribute nor extension
+		{&common.MSPAttribute{MspIdentifier: "CRITEXT"}, false},
+	} {
+		err := thisMSP.SatisfiesPrincipal(id, attributePrincipal(t, test.attr))
+		if test.satisfied {
+			assert.NoError(t, err, "%v", test.attr)
+		} else {
+			assert.Error(t, err, "%v", test.attr)
+		}
+	}
+
+	// Extension values are matched as they are
+	thisMSP, serializedID = newCriticalExtensionsMSP(t, pkix.Extension{Id: unknownCriticalExtension, Value: []byte{5, 0}})
+	id, err = thisMSP.DeserializeIdentity(serializedID)
+	assert.NoError(t, err)
+	assert.NoError(t, thisMSP.SatisfiesPrincipal(id, attributePrincipal(t, &common.MSPAttribute{
+		MspIdentifier: "CRITEXT", ExtensionOid: "1.2.3.4.5.99", ExtensionValue: []byte{5, 0},
+	})))
+
+	// Malformed attributes
+	thisMSP, serializedID = newCriticalExtensionsMSP(t, pkix.Extension{Id: attributesOID, Value: []byte("attrs")})
+	id, err = thisMSP.DeserializeIdentity(serializedID)
+	assert.NoError(t, err)
+	assert.Error(t, thisMSP.SatisfiesPrincipal(id, attributePrincipal(t, &common.MSPAttribute{MspIdentifier: "CRITEXT", Name: "department"})))
+}
@@ -0,0 +1,155 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package msp
+
+import (
+	"crypto/sha256"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric/protos/common"
+)
+
+// The operations recorded in audit logs
+const (
+	AuditValidate           = "Validate"
+	AuditSatisfiesPrincipal = "SatisfiesPrincipal"
+)
+
+// AuditRecord is a decision taken by an MSP about an identity
+type AuditRecord struct {
+	// Time is when the decision was taken
+	Time time.Time
+	// MSPID is the identifier of the MSP that took the decision
+	MSPID string
+	// Channel is the channel of the MSP, or empty for the local MSP
+	Channel string
+	// IdentityHash is the SHA-256 hash of the serialized identity
+	IdentityHash []byte
+	// Operation is either AuditValidate or AuditSatisfiesPrincipal
+	Operation string
+	// Principal is the classification of the principal
+	// checked by AuditSatisfiesPrincipal decisions
+	Principal string
+	// Allowed tells whether the identity is valid
+	// or, respectively, satisfies the principal
+	Allowed bool
+	// Reason is why the identity was not allowed, if it was not
+	Reason string
+}
+
+// AuditLog keeps the most recent decisions of the MSPs it is set
+// on via SetAuditLog, up to the size it is created with. It is
+// safe for concurrent use.
+type AuditLog struct {
+	lock    sync.Mutex
+	records []AuditRecord
+	// next is where the next record goes in records
+	next int
+	full bool
+}
+
+// NewAuditLog returns an audit log keeping
+// the size most recent decisions, at least one
+func NewAuditLog(size int) *AuditLog {
+	if size < 1 {
+		size = 1
+	}
+	return &AuditLog{records: make([]AuditRecord, size)}
+}
+
+func (l *AuditLog) add(record AuditRecord) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.records[l.next] = record
+	l.next = (l.next + 1) % len(l.records)
+	if l.next == 0 {
+		l.full = true
+	}
+}
+
+// Records returns the decisions in the log, oldest first
+func (l *AuditLog) Records() []AuditRecord {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if !l.full {
+		return append([]AuditRecord(nil), l.records[:l.next]...)
+	}
+	return append(append([]AuditRecord(nil), l.records[l.next:]...), l.records[:l.next]...)
+}
+
+// auditor records the decisions of an MSP of a channel in a log
+type auditor struct {
+	log     *AuditLog
+	channel string
+}
+
+// SetAuditLog makes m record its decisions in log, as an MSP of
+// channel, or stop recording them if log is nil. It has no effect
+// on MSPs not supporting audit.
+func SetAuditLog(m MSP, log *AuditLog, channel string) {
+	bm, ok := m.(*bccspmsp)
+	if !ok {
+		return
+	}
+	if log == nil {
+		bm.auditor.Store((*auditor)(nil))
+		return
+	}
+	bm.auditor.Store(&auditor{log: log, channel: channel})
+}
+
+// SetManagerAuditLog calls SetAuditLog on each of the MSPs of mgr
+func SetManagerAuditLog(mgr MSPManager, log *AuditLog, channel string) error {
+	msps, err := mgr.GetMSPs()
+	if err != nil {
+		return err
+	}
+	for _, m := range msps {
+		SetAuditLog(m, log, channel)
+	}
+	return nil
+}
+
+// audit records the decision err of operation on id, if audit is enabled
+func (msp *bccspmsp) audit(id Identity, operation string, principal *common.MSPPrincipal, err error) {
+	a, _ := msp.auditor.Load().(*auditor)
+	if a == nil {
+		return
+	}
+
+	record := AuditRecord{
+		Time:      msp.now(),
+		MSPID:     msp.name,
+		Channel:   a.channel,
+		Operation: operation,
+		Allowed:   err == nil,
+	}
+	if idBytes, serr := id.Serialize(); serr == nil {
+		hash := sha256.Sum256(idBytes)
+		record.IdentityHash = hash[:]
+	}
+	if principal != nil {
+		record.Principal = principal.PrincipalClassification.String()
+	}
+	if err != nil {
+		record.Reason = err.Error()
+	}
+	a.log.add(record)
+}
@@ -0,0 +1,106 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package msp
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditLogWraparound(t *testing.T) {
+	log := NewAuditLog(3)
+	assert.Empty(t, log.Records())
+
+	for _, id := range []string{"a", "b"} {
+		log.add(AuditRecord{MSPID: id})
+	}
+	assert.Equal(t, []AuditRecord{{MSPID: "a"}, {MSPID: "b"}}, log.Records())
+
+	// The oldest records are overwritten
+	for _, id := range []string{"c", "d", "e"} {
+		log.add(AuditRecord{MSPID: id})
+	}
+	assert.Equal(t, []AuditRecord{{MSPID: "c"}, {MSPID: "d"}, {MSPID: "e"}}, log.Records())
+
+	// At least one record is kept
+	log = NewAuditLog(0)
+	log.add(AuditRecord{MSPID: "a"})
+	log.add(AuditRecord{MSPID: "b"})
+	assert.Equal(t, []AuditRecord{{MSPID: "b"}}, log.Records())
+}
+
+func TestAuditLog(t *testing.T) {
+	root := issueTestCert(t, "root", nil, pathLen(-1))
+	other := issueTestCert(t, "other", nil, pathLen(-1))
+	thisMSP, err := setupCAChainMSP(root)
+	assert.NoError(t, err)
+
+	valid, err := thisMSP.DeserializeIdentity(serializeTestCert(t, "CACHAIN", issueTestCert(t, "peer0", root, nil).cert))
+	assert.NoError(t, err)
+	invalid, err := thisMSP.DeserializeIdentity(serializeTestCert(t, "CACHAIN", issueTestCert(t, "peer1", other, nil).cert))
+	assert.NoError(t, err)
+
+	// Nothing is recorded until the log is set
+	log := NewAuditLog(10)
+	assert.NoError(t, valid.Validate())
+	SetAuditLog(thisMSP, log, "mychannel")
+
+	assert.NoError(t, valid.Validate())
+	assert.Error(t, invalid.Validate())
+	principal, err := proto.Marshal(&common.MSPRole{MspIdentifier: "CACHAIN", Role: common.MSPRole_MEMBER})
+	assert.NoError(t, err)
+	assert.NoError(t, thisMSP.SatisfiesPrincipal(valid, &common.MSPPrincipal{
+		PrincipalClassification: common.MSPPrincipal_ROLE,
+		Principal:               principal,
+	}))
+
+	records := log.Records()
+	assert.Len(t, records, 3)
+	validBytes, err := valid.Serialize()
+	assert.NoError(t, err)
+	hash := sha256.Sum256(validBytes)
+	for _, record := range records {
+		assert.Equal(t, "CACHAIN", record.MSPID)
+		assert.Equal(t, "mychannel", record.Channel)
+		assert.False(t, record.Time.IsZero())
+	}
+
+	assert.Equal(t, AuditValidate, records[0].Operation)
+	assert.Equal(t, hash[:], records[0].IdentityHash)
+	assert.True(t, records[0].Allowed)
+	assert.Empty(t, records[0].Reason)
+
+	assert.Equal(t, AuditValidate, records[1].Operation)
+	assert.NotEqual(t, hash[:], records[1].IdentityHash)
+	assert.False(t, records[1].Allowed)
+	assert.NotEmpty(t, records[1].Reason)
+
+	// Only the decision on the principal is recorded, not the
+	// validation of the identity it implies
+	assert.Equal(t, AuditSatisfiesPrincipal, records[2].Operation)
+	assert.Equal(t, "ROLE", records[2].Principal)
+	assert.True(t, records[2].Allowed)
+
+	// Recording stops once the log is unset
+	SetAuditLog(thisMSP, nil, "")
+	assert.NoError(t, valid.Validate())
+	assert.Len(t, log.Records(), 3)
+}
@@ -0,0 +1,131 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package msp
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/msp"
+)
+
+// ExportConfigBundle returns conf, the configuration of a FABRIC MSP,
+// as a bundle signed by signer, so that it can be distributed out-of-band.
+// The bundle carries the CA certificates, the revocation lists and the
+// organizational unit configuration of the MSP; the signing identity of
+// conf, if any, is left out.
+func ExportConfigBundle(conf *msp.MSPConfig, signer SigningIdentity) ([]byte, error) {
+	if conf == nil {
+		return nil, errors.New("Invalid MSP config. It must be different from nil")
+	}
+	if signer == nil {
+		return nil, errors.New("Invalid signer. It must be different from nil")
+	}
+	if conf.Type != int32(FABRIC) {
+		return nil, fmt.Errorf("Unsupported MSP type %d", conf.Type)
+	}
+
+	fabricConf := &msp.FabricMSPConfig{}
+	if err := proto.Unmarshal(conf.Config, fabricConf); err != nil {
+		return nil, fmt.Errorf("Failed unmarshalling fabric msp config, err %s", err)
+	}
+	fabricConf.SigningIdentity = nil
+	fabricConfBytes, err := proto.Marshal(fabricConf)
+	if err != nil {
+		return nil, fmt.Errorf("Failed marshalling fabric msp config, err %s", err)
+	}
+	confBytes, err := proto.Marshal(&msp.MSPConfig{Type: conf.Type, Config: fabricConfBytes})
+	if err != nil {
+		return nil, fmt.Errorf("Failed marshalling msp config, err %s", err)
+	}
+
+	signerBytes, err := signer.Serialize()
+	if err != nil {
+		return nil, fmt.Errorf("Failed serializing signer, err %s", err)
+	}
+	signature, err := signer.Sign(confBytes)
+	if err != nil {
+		return nil, fmt.Errorf("Failed signing msp config, err %s", err)
+	}
+
+	return proto.Marshal(&msp.MSPConfigBundle{Config: confBytes, Signer: signerBytes, Signature: signature})
+}
+
+// ImportConfigBundle verifies bundle, as returned by ExportConfigBundle,
+// and returns the MSP configuration it carries. The configuration must
+// set up a valid MSP. If deserializer is not nil, the signer of the bundle
+// must be an identity deserializer validates; otherwise, the bundle must
+// be signed by an admin of the MSP it carries.
+func ImportConfigBundle(bundle []byte, deserializer IdentityDeserializer) (*msp.MSPConfig, error) {
+	b := &msp.MSPConfigBundle{}
+	if err := proto.Unmarshal(bundle, b); err != nil {
+		return nil, fmt.Errorf("Failed unmarshalling msp config bundle, err %s", err)
+	}
+
+	conf := &msp.MSPConfig{}
+	if err := proto.Unmarshal(b.Config, conf); err != nil {
+		return nil, fmt.Errorf("Failed unmarshalling msp config, err %s", err)
+	}
+	if conf.Type != int32(FABRIC) {
+		return nil, fmt.Errorf("Unsupported MSP type %d", conf.Type)
+	}
+	fabricConf := &msp.FabricMSPConfig{}
+	if err := proto.Unmarshal(conf.Config, fabricConf); err != nil {
+		return nil, fmt.Errorf("Failed unmarshalling fabric msp config, err %s", err)
+	}
+	if fabricConf.SigningIdentity != nil {
+		return nil, errors.New("Invalid msp config bundle: it carries a signing identity")
+	}
+
+	bundled, err := NewBccspMsp()
+	if err != nil {
+		return nil, fmt.Errorf("Failed creating msp, err %s", err)
+	}
+	if err := bundled.Setup(conf); err != nil {
+		return nil, fmt.Errorf("Invalid msp config, err %s", err)
+	}
+
+	selfSigned := deserializer == nil
+	if selfSigned {
+		deserializer = bundled
+	}
+	signer, err := deserializer.DeserializeIdentity(b.Signer)
+	if err != nil {
+		return nil, fmt.Errorf("Failed deserializing signer, err %s", err)
+	}
+	if selfSigned {
+		err = signer.SatisfiesPrincipal(adminPrincipal(fabricConf.Name))
+	} else {
+		err = signer.Validate()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Invalid signer, err %s", err)
+	}
+	if err := signer.Verify(b.Config, b.Signature); err != nil {
+		return nil, fmt.Errorf("Invalid signature over msp config, err %s", err)
+	}
+
+	return conf, nil
+}
+
+// adminPrincipal returns the principal of the admins of MSP mspID
+func adminPrincipal(mspID string) *common.MSPPrincipal {
+	role, _ := proto.Marshal(&common.MSPRole{MspIdentifier: mspID, Role: common.MSPRole_ADMIN})
+	return &common.MSPPrincipal{PrincipalClassification: common.MSPPrincipal_ROLE, Principal: role}
+}
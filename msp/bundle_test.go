@@ -0,0 +1,122 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package msp
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/protos/msp"
+	"github.com/stretchr/testify/assert"
+)
+
+// setupBundleMSP returns the config of an MSP of CA root whose
+// signing identity is signer, admin of the MSP if admin is true,
+// and the MSP it sets up
+func setupBundleMSP(t *testing.T, root, signer *testCA, admin bool) (*msp.MSPConfig, MSP) {
+	encode := func(cert *x509.Certificate) []byte {
+		return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(signer.key)
+	assert.NoError(t, err)
+
+	fabricConf := &msp.FabricMSPConfig{
+		Name:           "BUNDLE",
+		RootCerts:      [][]byte{encode(root.cert)},
+		RevocationList: [][]byte{newTestCRL(t, root, 1, 0)},
+		SigningIdentity: &msp.SigningIdentityInfo{
+			PublicSigner: encode(signer.cert),
+			PrivateSigner: &msp.KeyInfo{
+				KeyIdentifier: "PEER",
+				KeyMaterial:   pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}),
+			},
+		},
+	}
+	if admin {
+		fabricConf.Admins = [][]byte{encode(signer.cert)}
+	}
+	raw, err := proto.Marshal(fabricConf)
+	assert.NoError(t, err)
+	conf := &msp.MSPConfig{Config: raw, Type: int32(FABRIC)}
+
+	thisMSP, err := NewBccspMsp()
+	assert.NoError(t, err)
+	assert.NoError(t, thisMSP.Setup(conf))
+	return conf, thisMSP
+}
+
+func TestConfigBundle(t *testing.T) {
+	root := issueTestCert(t, "root", nil, pathLen(-1))
+	conf, thisMSP := setupBundleMSP(t, root, issueTestCert(t, "admin", root, nil), true)
+	signer, err := thisMSP.GetDefaultSigningIdentity()
+	assert.NoError(t, err)
+
+	bundle, err := ExportConfigBundle(conf, signer)
+	assert.NoError(t, err)
+
+	// Signed by an admin of the bundled MSP
+	imported, err := ImportConfigBundle(bundle, nil)
+	assert.NoError(t, err)
+	importedConf := &msp.FabricMSPConfig{}
+	assert.NoError(t, proto.Unmarshal(imported.Config, importedConf))
+	originalConf := &msp.FabricMSPConfig{}
+	assert.NoError(t, proto.Unmarshal(conf.Config, originalConf))
+	assert.Nil(t, importedConf.SigningIdentity)
+	assert.Equal(t, originalConf.Name, importedConf.Name)
+	assert.Equal(t, originalConf.RootCerts, importedConf.RootCerts)
+	assert.Equal(t, originalConf.RevocationList, importedConf.RevocationList)
+
+	// Signed by an identity validated by the given deserializer
+	_, err = ImportConfigBundle(bundle, thisMSP)
+	assert.NoError(t, err)
+	otherMSP, err := setupCAChainMSP(issueTestCert(t, "other", nil, pathLen(-1)))
+	assert.NoError(t, err)
+	_, err = ImportConfigBundle(bundle, otherMSP)
+	assert.Error(t, err)
+
+	// Tampered bundles
+	b := &msp.MSPConfigBundle{}
+	assert.NoError(t, proto.Unmarshal(bundle, b))
+	b.Signature[len(b.Signature)-1] ^= 1
+	tampered, err := proto.Marshal(b)
+	assert.NoError(t, err)
+	_, err = ImportConfigBundle(tampered, nil)
+	assert.Error(t, err)
+	_, err = ImportConfigBundle([]byte("garbage"), nil)
+	assert.Error(t, err)
+
+	// Not signed by an admin of the bundled MSP
+	conf, thisMSP = setupBundleMSP(t, root, issueTestCert(t, "peer0", root, nil), false)
+	signer, err = thisMSP.GetDefaultSigningIdentity()
+	assert.NoError(t, err)
+	bundle, err = ExportConfigBundle(conf, signer)
+	assert.NoError(t, err)
+	_, err = ImportConfigBundle(bundle, nil)
+	assert.Error(t, err)
+	_, err = ImportConfigBundle(bundle, thisMSP)
+	assert.NoError(t, err)
+
+	// Invalid arguments
+	_, err = ExportConfigBundle(nil, signer)
+	assert.Error(t, err)
+	_, err = ExportConfigBundle(conf, nil)
+	assert.Error(t, err)
+	_, err = ExportConfigBundle(&msp.MSPConfig{Type: int32(OTHER)}, signer)
+	assert.Error(t, err)
+}
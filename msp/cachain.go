@@ -0,0 +1,150 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package msp
+
+import (
+	"bytes"
+	"crypto/x509"
+	"errors"
+	"fmt"
+
+	"github.com/hyperledger/fabric/bccsp"
+)
+
+// The reasons a certificate is rejected by an MSP, see CAChainError
+var (
+	// ErrPathLenExceeded means that the certificate has been issued
+	// in violation of the path length constraint of a CA above it
+	ErrPathLenExceeded = errors.New("Path length constraint exceeded")
+	// ErrUnknownIssuer means that the certificate has not been issued
+	// by any of the root or intermediate CAs of the MSP
+	ErrUnknownIssuer = errors.New("Issuer is not a CA of the MSP")
+)
+
+// CAChainError is returned when the chain of a certificate
+// up to the root CAs of an MSP does not hold
+type CAChainError struct {
+	// MSPID is the identifier of the MSP
+	MSPID string
+	// Cert is the certificate that was rejected
+	Cert *x509.Certificate
+	// Reason is either ErrPathLenExceeded or ErrUnknownIssuer
+	Reason error
+	// CA is, with ErrPathLenExceeded, the CA whose
+	// path length constraint is exceeded
+	CA *x509.Certificate
+	// Intermediates is, with ErrPathLenExceeded, the number
+	// of intermediate CAs that follow CA in the chain
+	Intermediates int
+}
+
+func (e *CAChainError) Error() string {
+	if e.Reason == ErrPathLenExceeded {
+		return fmt.Sprintf("MSP %s rejected certificate [%s]: %s: CA [%s] allows [%d] intermediate CAs below it, found [%d]",
+			e.MSPID, e.Cert.Subject.CommonName, e.Reason, e.CA.Subject.CommonName, e.CA.MaxPathLen, e.Intermediates)
+	}
+	return fmt.Sprintf("MSP %s rejected certificate [%s]: %s", e.MSPID, e.Cert.Subject.CommonName, e.Reason)
+}
+
+// validateCAChain checks that cert chains up to one of the root CAs
+// through the intermediate CAs of this MSP, however deep the chain is,
+// and that no CA of the chain has its path length constraint exceeded
+func (msp *bccspmsp) validateCAChain(cert *x509.Certificate) *CAChainError {
+	chain, err := msp.getCAChain(cert)
+	if err != nil {
+		return &CAChainError{MSPID: msp.name, Cert: cert, Reason: err}
+	}
+
+	// the certificates of the chain other than CAs,
+	// that is the leaf at most, are not counted
+	intermediates := 0
+	for i := 1; i < len(chain); i++ {
+		if chain[i-1].IsCA {
+			intermediates++
+		}
+
+		ca := chain[i]
+		if ca.BasicConstraintsValid && ca.MaxPathLen >= 0 && intermediates > ca.MaxPathLen {
+			return &CAChainError{MSPID: msp.name, Cert: cert, Reason: ErrPathLenExceeded, CA: ca, Intermediates: intermediates}
+		}
+	}
+
+	return nil
+}
+
+// getCAChain returns the chain from cert up to one of the root CAs
+func (msp *bccspmsp) getCAChain(cert *x509.Certificate) ([]*x509.Certificate, error) {
+	chain := []*x509.Certificate{cert}
+	for {
+		current := chain[len(chain)-1]
+		if root := findIssuer(current, msp.rootCerts, nil); root != nil {
+			if !bytes.Equal(root.Raw, current.Raw) {
+				chain = append(chain, root)
+			}
+			return chain, nil
+		}
+
+		// certificates already in the chain are skipped so that
+		// cross-signed intermediate CAs cannot make us loop
+		issuer := findIssuer(current, msp.intermediateCerts, chain)
+		if issuer == nil {
+			return nil, ErrUnknownIssuer
+		}
+		chain = append(chain, issuer)
+	}
+}
+
+// getCertificationChainIdentifier returns the hash of the
+// chain of CA certificates cert is issued by
+func (msp *bccspmsp) getCertificationChainIdentifier(cert *x509.Certificate) ([]byte, error) {
+	chain, err := msp.getCAChain(cert)
+	if err != nil {
+		return nil, err
+	}
+
+	hf, err := msp.bccsp.GetHash(&bccsp.SHA256Opts{})
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting hash function, err %s", err)
+	}
+	for _, ca := range chain[1:] {
+		hf.Write(ca.Raw)
+	}
+	return hf.Sum(nil), nil
+}
+
+// findIssuer returns the certificate among cas, other than
+// those in skip, that issued cert, or nil if there is none
+func findIssuer(cert *x509.Certificate, cas []Identity, skip []*x509.Certificate) *x509.Certificate {
+	for _, ca := range cas {
+		caCert := ca.(*identity).cert
+		if !bytes.Equal(caCert.RawSubject, cert.RawIssuer) || cert.CheckSignatureFrom(caCert) != nil {
+			continue
+		}
+
+		skipped := false
+		for _, c := range skip {
+			if bytes.Equal(c.Raw, caCert.Raw) {
+				skipped = true
+				break
+			}
+		}
+		if !skipped {
+			return caCert
+		}
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package msp
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/protos/msp"
+	"github.com/stretchr/testify/assert"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+// issueTestCert issues a certificate named cn with parent, or
+// a self-signed one if parent is nil. A CA certificate is issued
+// if maxPathLen is not nil, constrained to it if it is not negative,
+// belonging to the organizational units ous
+func issueTestCert(t *testing.T, cn string, parent *testCA, maxPathLen *int, ous ...string) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: cn, OrganizationalUnit: ous},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		BasicConstraintsValid: true,
+	}
+	if maxPathLen != nil {
+		template.IsCA = true
+		template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign
+		template.SubjectKeyId = serial.Bytes()
+		if *maxPathLen >= 0 {
+			template.MaxPathLen = *maxPathLen
+			template.MaxPathLenZero = *maxPathLen == 0
+		} else {
+			template.MaxPathLen = -1
+		}
+	}
+
+	issuer := &testCA{cert: template, key: key}
+	if parent != nil {
+		issuer = parent
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, issuer.cert, &key.PublicKey, issuer.key)
+	assert.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+
+	return &testCA{cert: cert, key: key}
+}
+
+func pathLen(n int) *int {
+	return &n
+}
+
+func setupCAChainMSP(root *testCA, intermediates ...*testCA) (MSP, error) {
+	conf := &msp.FabricMSPConfig{
+		Name:      "CACHAIN",
+		RootCerts: [][]byte{pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: root.cert.Raw})},
+	}
+	for _, ca := range intermediates {
+		conf.IntermediateCerts = append(conf.IntermediateCerts, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.cert.Raw}))
+	}
+	raw, err := proto.Marshal(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	thisMSP, err := NewBccspMsp()
+	if err != nil {
+		return nil, err
+	}
+	return thisMSP, thisMSP.Setup(&msp.MSPConfig{Config: raw, Type: int32(FABRIC)})
+}
+
+func TestDeepIntermediateCAChain(t *testing.T) {
+	root := issueTestCert(t, "root", nil, pathLen(-1))
+	ica1 := issueTestCert(t, "ica1", root, pathLen(2))
+	ica2 := issueTestCert(t, "ica2", ica1, pathLen(1))
+	ica3 := issueTestCert(t, "ica3", ica2, pathLen(0))
+	leaf := issueTestCert(t, "peer0", ica3, nil)
+
+	// intermediates in any order
+	thisMSP, err := setupCAChainMSP(root, ica3, ica1, ica2)
+	assert.NoError(t, err)
+
+	id, err := thisMSP.DeserializeIdentity(serializeTestCert(t, "CACHAIN", leaf.cert))
+	assert.NoError(t, err)
+	assert.NoError(t, thisMSP.Validate(id))
+}
+
+func TestIntermediateCAPathLenExceeded(t *testing.T) {
+	root := issueTestCert(t, "root", nil, pathLen(1))
+	ica1 := issueTestCert(t, "ica1", root, pathLen(-1))
+	ica2 := issueTestCert(t, "ica2", ica1, pathLen(-1))
+
+	// one intermediate below the root is allowed
+	_, err := setupCAChainMSP(root, ica1)
+	assert.NoError(t, err)
+
+	// two are not
+	_, err = setupCAChainMSP(root, ica1, ica2)
+	assert.IsType(t, &CAChainError{}, err)
+	chainErr := err.(*CAChainError)
+	assert.Equal(t, ErrPathLenExceeded, chainErr.Reason)
+	assert.Equal(t, "ica2", chainErr.Cert.Subject.CommonName)
+	assert.Equal(t, "root", chainErr.CA.Subject.CommonName)
+	assert.Equal(t, 2, chainErr.Intermediates)
+
+	// a CA constrained to issue end-entity certificates only
+	root = issueTestCert(t, "root", nil, pathLen(-1))
+	ica1 = issueTestCert(t, "ica1", root, pathLen(-1))
+	ica3 := issueTestCert(t, "ica3", ica1, pathLen(0))
+	ica4 := issueTestCert(t, "ica4", ica3, pathLen(-1))
+	_, err = setupCAChainMSP(root, ica1, ica3, ica4)
+	assert.IsType(t, &CAChainError{}, err)
+	chainErr = err.(*CAChainError)
+	assert.Equal(t, ErrPathLenExceeded, chainErr.Reason)
+	assert.Equal(t, "ica3", chainErr.CA.Subject.CommonName)
+}
+
+func TestIntermediateCAUnknownIssuer(t *testing.T) {
+	root := issueTestCert(t, "root", nil, pathLen(-1))
+	ica1 := issueTestCert(t, "ica1", root, pathLen(-1))
+	ica2 := issueTestCert(t, "ica2", ica1, pathLen(-1))
+
+	// ica1 is missing
+	_, err := setupCAChainMSP(root, ica2)
+	assert.IsType(t, &CAChainError{}, err)
+	assert.Equal(t, ErrUnknownIssuer, err.(*CAChainError).Reason)
+
+	// ica1 issued by another root
+	other := issueTestCert(t, "other", nil, pathLen(-1))
+	_, err = setupCAChainMSP(other, ica1)
+	assert.IsType(t, &CAChainError{}, err)
+	assert.Equal(t, ErrUnknownIssuer, err.(*CAChainError).Reason)
+}
+
+func TestIdentityPathLenExceeded(t *testing.T) {
+	root := issueTestCert(t, "root", nil, pathLen(0))
+	ica1 := issueTestCert(t, "ica1", root, pathLen(-1))
+	leaf := issueTestCert(t, "peer0", ica1, nil)
+
+	// identities issued in violation of the constraint are reported as such
+	thisMSP, err := setupCAChainMSP(root)
+	assert.NoError(t, err)
+	thisMSP.(*bccspmsp).intermediateCerts = []Identity{&identity{cert: ica1.cert}}
+	thisMSP.(*bccspmsp).opts.Intermediates.AddCert(ica1.cert)
+
+	id, err := thisMSP.DeserializeIdentity(serializeTestCert(t, "CACHAIN", leaf.cert))
+	assert.NoError(t, err)
+	err = thisMSP.Validate(id)
+	assert.IsType(t, &CAChainError{}, err)
+	assert.Equal(t, ErrPathLenExceeded, err.(*CAChainError).Reason)
+}
+
+func serializeTestCert(t *testing.T, mspID string, cert *x509.Certificate) []byte {
+	raw, err := proto.Marshal(&SerializedIdentity{
+		Mspid:   mspID,
+		IdBytes: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}),
+	})
+	assert.NoError(t, err)
+	return raw
+}
@@ -0,0 +1,81 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package msp
+
+import (
+	"crypto/x509"
+	"time"
+)
+
+// Clock tells MSPs the time to check the validity
+// of certificates at, see SetClock
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// SystemClock is the clock of MSPs by default: the wall-clock time
+var SystemClock Clock = systemClock{}
+
+// SetClock makes m check the validity of certificates, and
+// timestamp its audit records, at the time given by clock,
+// or by SystemClock if clock is nil. It has no effect on
+// MSPs not supporting it, and is meant to be called before
+// m is used.
+func SetClock(m MSP, clock Clock) {
+	if bm, ok := m.(*bccspmsp); ok {
+		bm.clock = clock
+	}
+}
+
+// SetClockSkew makes m accept certificates that are not yet valid,
+// or no longer valid, by up to skew, to tolerate differences between
+// the clocks of the issuers and of this MSP. It has no effect on MSPs
+// not supporting it, and is meant to be called before m is used.
+func SetClockSkew(m MSP, skew time.Duration) {
+	if bm, ok := m.(*bccspmsp); ok && skew >= 0 {
+		bm.clockSkew = skew
+	}
+}
+
+// now returns the current time according to the clock of this MSP
+func (msp *bccspmsp) now() time.Time {
+	if msp.clock == nil {
+		return SystemClock.Now()
+	}
+	return msp.clock.Now()
+}
+
+// verificationTime returns the time to verify cert at: the current
+// time or, if cert is only valid within the clock skew tolerance of
+// this MSP, the closest time it is valid at
+func (msp *bccspmsp) verificationTime(cert *x509.Certificate) time.Time {
+	now := msp.now()
+	switch {
+	case now.Before(cert.NotBefore) && cert.NotBefore.Sub(now) <= msp.clockSkew:
+		return cert.NotBefore
+	case now.After(cert.NotAfter) && now.Sub(cert.NotAfter) <= msp.clockSkew:
+		return cert.NotAfter
+	}
+	return now
+}
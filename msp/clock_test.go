@@ -0,0 +1,70 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package msp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time {
+	return time.Time(c)
+}
+
+func TestClock(t *testing.T) {
+	root := issueTestCert(t, "root", nil, pathLen(-1))
+	leaf := issueTestCert(t, "peer0", root, nil)
+	thisMSP, err := setupCAChainMSP(root)
+	assert.NoError(t, err)
+	id, err := thisMSP.DeserializeIdentity(serializeTestCert(t, "CACHAIN", leaf.cert))
+	assert.NoError(t, err)
+
+	// The wall-clock time by default
+	assert.NoError(t, id.Validate())
+
+	// Before and after the validity period of the certificate
+	SetClock(thisMSP, fixedClock(leaf.cert.NotBefore.Add(-time.Minute)))
+	assert.Error(t, id.Validate())
+	SetClock(thisMSP, fixedClock(leaf.cert.NotAfter.Add(time.Minute)))
+	assert.Error(t, id.Validate())
+
+	// Within the clock skew tolerance
+	SetClockSkew(thisMSP, 2*time.Minute)
+	assert.NoError(t, id.Validate())
+	SetClock(thisMSP, fixedClock(leaf.cert.NotBefore.Add(-time.Minute)))
+	assert.NoError(t, id.Validate())
+
+	// Beyond the clock skew tolerance
+	SetClock(thisMSP, fixedClock(leaf.cert.NotAfter.Add(3*time.Minute)))
+	assert.Error(t, id.Validate())
+
+	// Back to the wall-clock time
+	SetClock(thisMSP, nil)
+	assert.NoError(t, id.Validate())
+
+	// Audit records are timestamped by the clock
+	now := time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)
+	log := NewAuditLog(1)
+	SetAuditLog(thisMSP, log, "")
+	SetClock(thisMSP, fixedClock(now))
+	id.Validate()
+	assert.Equal(t, now, log.Records()[0].Time)
+}
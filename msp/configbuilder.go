@@ -19,6 +19,7 @@ package msp
 import (
 	"fmt"
 	"io/ioutil"
+	"os"
 
 	"github.com/golang/protobuf/proto"
 
@@ -27,8 +28,45 @@ import (
 
 	"github.com/hyperledger/fabric/bccsp/factory"
 	"github.com/hyperledger/fabric/protos/msp"
+	"gopkg.in/yaml.v2"
 )
 
+// OrganizationalUnitIdentifiersConfiguration is used to represent an OU
+// and an associated trusted certificate
+type OrganizationalUnitIdentifiersConfiguration struct {
+	// Certificate is the path, relative to the MSP directory, of the
+	// root or intermediate CA certificate the identities of the OU must
+	// chain up to; any CA of the MSP is accepted if it is left empty
+	Certificate string `yaml:"Certificate,omitempty"`
+	// OrganizationalUnitIdentifier is the organizational unit
+	OrganizationalUnitIdentifier string `yaml:"OrganizationalUnitIdentifier,omitempty"`
+}
+
+// NodeOUs defines how identities are classified into clients,
+// peers, admins and orderers given their organizational units
+type NodeOUs struct {
+	// Enable activates the classification
+	Enable bool `yaml:"Enable,omitempty"`
+	// ClientOUIdentifier identifies the clients
+	ClientOUIdentifier *OrganizationalUnitIdentifiersConfiguration `yaml:"ClientOUIdentifier,omitempty"`
+	// PeerOUIdentifier identifies the peers
+	PeerOUIdentifier *OrganizationalUnitIdentifiersConfiguration `yaml:"PeerOUIdentifier,omitempty"`
+	// AdminOUIdentifier identifies the admins, in addition to admincerts
+	AdminOUIdentifier *OrganizationalUnitIdentifiersConfiguration `yaml:"AdminOUIdentifier,omitempty"`
+	// OrdererOUIdentifier identifies the orderers
+	OrdererOUIdentifier *OrganizationalUnitIdentifiersConfiguration `yaml:"OrdererOUIdentifier,omitempty"`
+}
+
+// Configuration represents the accessory configuration an MSP
+// can be equipped with, read from the config.yaml file of the
+// MSP directory
+type Configuration struct {
+	// NodeOUs enables the classification of identities by
+	// organizational unit, in which case the admincerts
+	// directory is no longer required
+	NodeOUs *NodeOUs `yaml:"NodeOUs,omitempty"`
+}
+
 func readFile(file string) ([]byte, error) {
 	fileCont, err := ioutil.ReadFile(file)
 	if err != nil {
@@ -86,6 +124,7 @@ const (
 	signcerts         = "signcerts"
 	keystore          = "keystore"
 	intermediatecerts = "intermediatecerts"
+	configfilename    = "config.yaml"
 )
 
 func SetupBCCSPKeystoreConfig(bccspConfig *factory.FactoryOpts, keystoreDir string) {
@@ -153,8 +192,15 @@ func getMspConfig(dir string, bccspConfig *factory.FactoryOpts, ID string, sigid
 		return nil, fmt.Errorf("Could not load a valid signer certificate from directory %s, err %s", signcertDir, err)
 	}
 
+	nodeOUs, err := getNodeOUsFromConfig(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	// admins are identified by their organizational unit with
+	// NodeOUs enabled, in which case admincerts is not mandatory
 	admincert, err := getPemMaterialFromDir(admincertDir)
-	if err != nil || len(admincert) == 0 {
+	if (err != nil || len(admincert) == 0) && (nodeOUs == nil || !nodeOUs.Enable) {
 		return nil, fmt.Errorf("Could not load a valid admin certificate from directory %s, err %s", admincertDir, err)
 	}
 
@@ -166,6 +212,7 @@ func getMspConfig(dir string, bccspConfig *factory.FactoryOpts, ID string, sigid
 		RootCerts:         cacerts,
 		IntermediateCerts: intermediatecert,
 		SigningIdentity:   sigid,
+		FabricNodeOus:     nodeOUs,
 		Name:              ID}
 
 	fmpsjs, _ := proto.Marshal(fmspconf)
@@ -174,3 +221,58 @@ func getMspConfig(dir string, bccspConfig *factory.FactoryOpts, ID string, sigid
 
 	return mspconf, nil
 }
+
+// getNodeOUsFromConfig returns the NodeOUs of the config.yaml
+// file of the MSP directory dir, or nil if there is none
+func getNodeOUsFromConfig(dir string) (*msp.FabricNodeOUs, error) {
+	configFile := filepath.Join(dir, configfilename)
+	if _, err := os.Stat(configFile); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	raw, err := readFile(configFile)
+	if err != nil {
+		return nil, err
+	}
+
+	configuration := &Configuration{}
+	err = yaml.Unmarshal(raw, configuration)
+	if err != nil {
+		return nil, fmt.Errorf("Failed unmarshalling configuration file %s, err %s", configFile, err)
+	}
+	if configuration.NodeOUs == nil {
+		return nil, nil
+	}
+
+	nodeOUs := &msp.FabricNodeOUs{Enable: configuration.NodeOUs.Enable}
+	for _, ou := range []struct {
+		conf *OrganizationalUnitIdentifiersConfiguration
+		id   **msp.FabricOUIdentifier
+	}{
+		{configuration.NodeOUs.ClientOUIdentifier, &nodeOUs.ClientOuIdentifier},
+		{configuration.NodeOUs.PeerOUIdentifier, &nodeOUs.PeerOuIdentifier},
+		{configuration.NodeOUs.AdminOUIdentifier, &nodeOUs.AdminOuIdentifier},
+		{configuration.NodeOUs.OrdererOUIdentifier, &nodeOUs.OrdererOuIdentifier},
+	} {
+		if ou.conf == nil {
+			continue
+		}
+
+		// the certificate is carried as it is and
+		// matched against the CAs at MSP setup
+		var certifier []byte
+		if ou.conf.Certificate != "" {
+			certifier, err = readPemFile(filepath.Join(dir, ou.conf.Certificate))
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		*ou.id = &msp.FabricOUIdentifier{
+			CertifiersIdentifier:         certifier,
+			OrganizationalUnitIdentifier: ou.conf.OrganizationalUnitIdentifier,
+		}
+	}
+
+	return nodeOUs, nil
+}
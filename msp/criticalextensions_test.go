@@ -0,0 +1,118 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package msp
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/protos/msp"
+	"github.com/stretchr/testify/assert"
+)
+
+var unknownCriticalExtension = asn1.ObjectIdentifier{1, 2, 3, 4, 5, 99}
+
+// newCriticalExtensionsMSP returns an MSP whose root CA issued a
+// certificate carrying extension, and the serialized identity of
+// that certificate
+func newCriticalExtensionsMSP(t *testing.T, extension pkix.Extension) (MSP, []byte) {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		SubjectKeyId:          []byte{1, 2, 3, 4},
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	assert.NoError(t, err)
+	caCert, err := x509.ParseCertificate(caDER)
+	assert.NoError(t, err)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:    big.NewInt(2),
+		Subject:         pkix.Name{CommonName: "peer0"},
+		NotBefore:       time.Now().Add(-time.Hour),
+		NotAfter:        time.Now().Add(time.Hour),
+		ExtraExtensions: []pkix.Extension{extension},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, caCert, &key.PublicKey, caKey)
+	assert.NoError(t, err)
+
+	fmspconf := &msp.FabricMSPConfig{
+		RootCerts: [][]byte{pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER})},
+		Name:      "CRITEXT"}
+	fmpsjs, err := proto.Marshal(fmspconf)
+	assert.NoError(t, err)
+
+	thisMSP, err := NewBccspMsp()
+	assert.NoError(t, err)
+	err = thisMSP.Setup(&msp.MSPConfig{Config: fmpsjs, Type: int32(FABRIC)})
+	assert.NoError(t, err)
+
+	serializedID, err := NewSerializedIdentity("CRITEXT", pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+	assert.NoError(t, err)
+
+	return thisMSP, serializedID
+}
+
+func TestUnknownCriticalExtensions(t *testing.T) {
+	thisMSP, serializedID := newCriticalExtensionsMSP(t, pkix.Extension{Id: unknownCriticalExtension, Critical: true, Value: []byte{5, 0}})
+
+	id, err := thisMSP.DeserializeIdentity(serializedID)
+	assert.NoError(t, err)
+
+	// Strict by default
+	assert.Error(t, id.Validate())
+	assert.Error(t, id.(OptsValidator).ValidateOpts(&ValidationOpts{}))
+	assert.Error(t, id.(OptsValidator).ValidateOpts(&ValidationOpts{
+		AllowedCriticalExtensions: []asn1.ObjectIdentifier{{1, 2, 3, 4, 5, 100}},
+	}))
+
+	// Allowlisted
+	assert.NoError(t, id.(OptsValidator).ValidateOpts(&ValidationOpts{
+		AllowedCriticalExtensions: []asn1.ObjectIdentifier{unknownCriticalExtension},
+	}))
+
+	// Allowlisting does not change the certificate of the identity
+	assert.Error(t, id.Validate())
+}
+
+func TestNonCriticalUnknownExtensions(t *testing.T) {
+	thisMSP, serializedID := newCriticalExtensionsMSP(t, pkix.Extension{Id: unknownCriticalExtension, Value: []byte{5, 0}})
+
+	id, err := thisMSP.DeserializeIdentity(serializedID)
+	assert.NoError(t, err)
+
+	assert.NoError(t, id.Validate())
+	assert.NoError(t, id.(OptsValidator).ValidateOpts(&ValidationOpts{}))
+}
@@ -17,12 +17,15 @@ limitations under the License.
 package msp
 
 import (
+	"crypto"
 	"crypto/rand"
+	"crypto/rsa"
 	"crypto/x509"
 	"encoding/hex"
 	"encoding/pem"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/bccsp"
@@ -70,13 +73,43 @@ func (id *identity) Validate() error {
 	return id.msp.Validate(id)
 }
 
-// GetOrganizationalUnits returns the OU for this instance
-func (id *identity) GetOrganizationalUnits() []string {
+// ValidateOpts returns nil if this instance is a valid identity,
+// according to opts, or an error otherwise
+func (id *identity) ValidateOpts(opts *ValidationOpts) error {
+	return id.msp.validateIdentity(id, opts)
+}
+
+// ExpiresAt returns the time this identity expires at
+func (id *identity) ExpiresAt() time.Time {
+	return id.cert.NotAfter
+}
+
+// GetOrganizationalUnits returns the OUs for this instance, namespaced
+// by the identifier of the chain of trust of its certificate
+func (id *identity) GetOrganizationalUnits() []*OUIdentifier {
 	if id.cert == nil {
 		return nil
 	}
 
-	return id.cert.Subject.OrganizationalUnit
+	cid, err := id.msp.getCertificationChainIdentifier(id.cert)
+	if err != nil {
+		mspLogger.Errorf("Failed getting certification chain identifier for %s, err %s", id.id, err)
+		return nil
+	}
+
+	var res []*OUIdentifier
+	for _, unit := range id.cert.Subject.OrganizationalUnit {
+		res = append(res, &OUIdentifier{
+			OrganizationalUnitIdentifier: unit,
+			CertifiersIdentifier:         cid,
+		})
+	}
+	return res
+}
+
+// Anonymous returns false, as x.509 identities are nominal
+func (id *identity) Anonymous() bool {
+	return false
 }
 
 // NewSerializedIdentity returns a serialized identity
@@ -101,7 +134,7 @@ func (id *identity) Verify(msg []byte, sig []byte) error {
 	// mspLogger.Infof("Verifying signature")
 
 	// Compute Hash
-	digest, err := id.msp.bccsp.Hash(msg, &bccsp.SHAOpts{})
+	digest, err := id.digest(msg)
 	if err != nil {
 		return fmt.Errorf("Failed computing digest [%s]", err)
 	}
@@ -112,7 +145,7 @@ func (id *identity) Verify(msg []byte, sig []byte) error {
 		mspLogger.Debugf("Verify: sig = %s", hex.Dump(sig))
 	}
 
-	valid, err := id.msp.bccsp.Verify(id.pk, sig, digest, nil)
+	valid, err := id.msp.bccsp.Verify(id.pk, sig, digest, id.signerOpts(digest))
 	if err != nil {
 		return fmt.Errorf("Could not determine the validity of the signature, err %s", err)
 	} else if !valid {
@@ -122,6 +155,31 @@ func (id *identity) Verify(msg []byte, sig []byte) error {
 	return nil
 }
 
+// digest returns what the key of this identity signs in order to
+// sign msg: the hash of msg or, for Ed25519 keys, msg itself, as
+// Ed25519 hashes the messages it signs
+func (id *identity) digest(msg []byte) ([]byte, error) {
+	if id.cert.PublicKeyAlgorithm == x509.Ed25519 {
+		return msg, nil
+	}
+	return id.msp.bccsp.Hash(msg, &bccsp.SHAOpts{})
+}
+
+// signerOpts returns the options the signatures of this identity over
+// digest are produced and verified with: RSA keys sign with PSS, using
+// the SHA-2 function of the size of digest, other keys need no options
+func (id *identity) signerOpts(digest []byte) bccsp.SignerOpts {
+	if id.cert.PublicKeyAlgorithm != x509.RSA {
+		return nil
+	}
+
+	hash := crypto.SHA256
+	if len(digest) == crypto.SHA384.Size() {
+		hash = crypto.SHA384
+	}
+	return &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: hash}
+}
+
 func (id *identity) VerifyOpts(msg []byte, sig []byte, opts SignatureOpts) error {
 	// TODO
 	return nil
@@ -170,7 +228,7 @@ func (id *signingidentity) Sign(msg []byte) ([]byte, error) {
 	//mspLogger.Infof("Signing message")
 
 	// Compute Hash
-	digest, err := id.msp.bccsp.Hash(msg, &bccsp.SHAOpts{})
+	digest, err := id.digest(msg)
 	if err != nil {
 		return nil, fmt.Errorf("Failed computing digest [%s]", err)
 	}
@@ -185,7 +243,7 @@ func (id *signingidentity) Sign(msg []byte) ([]byte, error) {
 	mspLogger.Debugf("Sign: digest: %X \n", digest)
 
 	// Sign
-	return id.signer.Sign(rand.Reader, digest, nil)
+	return id.signer.Sign(rand.Reader, digest, id.signerOpts(digest))
 }
 
 func (id *signingidentity) SignOpts(msg []byte, opts SignatureOpts) ([]byte, error) {
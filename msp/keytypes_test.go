@@ -0,0 +1,143 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package msp
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/protos/msp"
+	"github.com/stretchr/testify/assert"
+)
+
+// newMSPWithKeys returns an MSP whose root CA and signing identity
+// have keys generated by genKey, and the serialized identity of a
+// certificate issued by that CA
+func newMSPWithKeys(t *testing.T, name string, genKey func() crypto.Signer) (MSP, []byte) {
+	caKey := genKey()
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		SubjectKeyId:          []byte{1, 2, 3, 4},
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, caKey.Public(), caKey)
+	assert.NoError(t, err)
+	caCert, err := x509.ParseCertificate(caDER)
+	assert.NoError(t, err)
+
+	newCert := func(serial int64, cn string) ([]byte, crypto.Signer) {
+		key := genKey()
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: cn},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, caCert, key.Public(), caKey)
+		assert.NoError(t, err)
+		return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), key
+	}
+
+	signcert, signkey := newCert(2, "peer0")
+	keyDER, err := x509.MarshalPKCS8PrivateKey(signkey)
+	assert.NoError(t, err)
+
+	fmspconf := &msp.FabricMSPConfig{
+		RootCerts: [][]byte{pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER})},
+		SigningIdentity: &msp.SigningIdentityInfo{
+			PublicSigner: signcert,
+			PrivateSigner: &msp.KeyInfo{
+				KeyIdentifier: "PEER",
+				KeyMaterial:   pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}),
+			},
+		},
+		Name: name}
+	fmpsjs, err := proto.Marshal(fmspconf)
+	assert.NoError(t, err)
+
+	thisMSP, err := NewBccspMsp()
+	assert.NoError(t, err)
+	err = thisMSP.Setup(&msp.MSPConfig{Config: fmpsjs, Type: int32(FABRIC)})
+	assert.NoError(t, err)
+
+	cert, _ := newCert(3, "peer1")
+	serializedID, err := NewSerializedIdentity(name, cert)
+	assert.NoError(t, err)
+
+	return thisMSP, serializedID
+}
+
+// testSignAndVerify checks that the signing identity of thisMSP signs
+// messages that verify, and that id is valid but did not sign them
+func testSignAndVerify(t *testing.T, thisMSP MSP, serializedID []byte) {
+	id, err := thisMSP.DeserializeIdentity(serializedID)
+	assert.NoError(t, err)
+	assert.NoError(t, id.Validate())
+
+	signer, err := thisMSP.GetDefaultSigningIdentity()
+	assert.NoError(t, err)
+	assert.NoError(t, signer.Validate())
+
+	msg := []byte("foo")
+	sig, err := signer.Sign(msg)
+	assert.NoError(t, err)
+	assert.NoError(t, signer.Verify(msg, sig))
+	assert.Error(t, signer.Verify([]byte("bar"), sig))
+
+	serializedSigner, err := signer.Serialize()
+	assert.NoError(t, err)
+	verifier, err := thisMSP.DeserializeIdentity(serializedSigner)
+	assert.NoError(t, err)
+	assert.NoError(t, verifier.Verify(msg, sig))
+
+	// Signatures of other identities do not verify
+	assert.Error(t, id.Verify(msg, sig))
+}
+
+func TestED25519Identities(t *testing.T) {
+	thisMSP, serializedID := newMSPWithKeys(t, "ED25519", func() crypto.Signer {
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		assert.NoError(t, err)
+		return key
+	})
+
+	testSignAndVerify(t, thisMSP, serializedID)
+}
+
+func TestRSAIdentities(t *testing.T) {
+	thisMSP, serializedID := newMSPWithKeys(t, "RSA", func() crypto.Signer {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		assert.NoError(t, err)
+		return key
+	})
+
+	testSignAndVerify(t, thisMSP, serializedID)
+}
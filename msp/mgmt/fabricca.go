@@ -0,0 +1,144 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mgmt
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/msp"
+)
+
+// fabricCAEnroller reenrolls against the REST API of a Fabric CA server
+type fabricCAEnroller struct {
+	url    string
+	caName string
+	client *http.Client
+}
+
+type fabricCAReenrollRequest struct {
+	CSR    string `json:"certificate_request"`
+	CAName string `json:"caname,omitempty"`
+}
+
+type fabricCAResponse struct {
+	Success bool `json:"success"`
+	Result  struct {
+		Cert string `json:"Cert"`
+	} `json:"result"`
+	Errors []struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// NewFabricCAEnroller returns an Enroller reenrolling against the Fabric
+// CA server at url, and its CA named caName, or its default CA if caName
+// is empty. tlsConfig, if not nil, configures the connections to https
+// URLs.
+func NewFabricCAEnroller(url, caName string, tlsConfig *tls.Config, timeout time.Duration) (Enroller, error) {
+	if url == "" {
+		return nil, errors.New("The URL of the Fabric CA server must be set")
+	}
+
+	return &fabricCAEnroller{
+		url:    strings.TrimSuffix(url, "/"),
+		caName: caName,
+		client: &http.Client{
+			Timeout:   timeout,
+			Transport: &http.Transport{TLSClientConfig: tlsConfig},
+		},
+	}, nil
+}
+
+// Reenroll sends csr to the reenroll endpoint of the Fabric CA server,
+// authenticated with a token signed by id
+func (e *fabricCAEnroller) Reenroll(csr []byte, id msp.SigningIdentity) ([]byte, error) {
+	body, err := json.Marshal(&fabricCAReenrollRequest{CSR: string(csr), CAName: e.caName})
+	if err != nil {
+		return nil, fmt.Errorf("Failed marshalling request [%s]", err)
+	}
+	token, err := authToken(body, id)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", e.url+"/api/v1/reenroll", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("Failed creating request [%s]", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", token)
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("Failed sending request to [%s] [%s]", e.url, err)
+	}
+	defer resp.Body.Close()
+	raw, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("Failed reading response [%s]", err)
+	}
+
+	res := &fabricCAResponse{}
+	if err := json.Unmarshal(raw, res); err != nil {
+		return nil, fmt.Errorf("Failed unmarshalling response with status [%s] [%s]", resp.Status, err)
+	}
+	if !res.Success {
+		var messages []string
+		for _, e := range res.Errors {
+			messages = append(messages, fmt.Sprintf("%d: %s", e.Code, e.Message))
+		}
+		return nil, fmt.Errorf("Reenrollment failed with status [%s]: [%s]", resp.Status, strings.Join(messages, "; "))
+	}
+
+	cert, err := base64.StdEncoding.DecodeString(res.Result.Cert)
+	if err != nil {
+		return nil, fmt.Errorf("Failed decoding certificate [%s]", err)
+	}
+	return cert, nil
+}
+
+// authToken returns the token authenticating body as sent by id,
+// in the format of Fabric CA: the base64 encoded certificate of id
+// and its signature over the base64 encoded body and certificate
+func authToken(body []byte, id msp.SigningIdentity) (string, error) {
+	serialized, err := id.Serialize()
+	if err != nil {
+		return "", fmt.Errorf("Failed serializing signing identity [%s]", err)
+	}
+	sID := &msp.SerializedIdentity{}
+	if err := proto.Unmarshal(serialized, sID); err != nil {
+		return "", fmt.Errorf("Failed unmarshalling signing identity [%s]", err)
+	}
+
+	b64Cert := base64.StdEncoding.EncodeToString(sID.IdBytes)
+	signature, err := id.Sign([]byte(base64.StdEncoding.EncodeToString(body) + "." + b64Cert))
+	if err != nil {
+		return "", fmt.Errorf("Failed signing token [%s]", err)
+	}
+	return b64Cert + "." + base64.StdEncoding.EncodeToString(signature), nil
+}
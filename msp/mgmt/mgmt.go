@@ -18,8 +18,10 @@ package mgmt
 
 import (
 	"sync"
+	"time"
 
 	"errors"
+	"fmt"
 
 	"github.com/hyperledger/fabric/bccsp/factory"
 	"github.com/hyperledger/fabric/msp"
@@ -40,6 +42,96 @@ func LoadLocalMsp(dir string, bccspConfig *factory.FactoryOpts, mspID string) er
 	return GetLocalMSP().Setup(conf)
 }
 
+// ReloadLocalMsp loads a new local MSP from the specified directory and,
+// if its setup succeeds, replaces the current local MSP with it and
+// notifies the subscribers registered via SubscribeLocalMSPUpdates.
+// If the setup fails, the current local MSP is left in place.
+func ReloadLocalMsp(dir string, bccspConfig *factory.FactoryOpts, mspID string) error {
+	return reloadLocalMsp(dir, bccspConfig, mspID, nil)
+}
+
+// ReloadAndValidateLocalMsp reloads the local MSP as ReloadLocalMsp does,
+// provided that the default signing identity of the new local MSP is valid,
+// so that a faulty directory does not leave the peer unable to sign
+func ReloadAndValidateLocalMsp(dir string, bccspConfig *factory.FactoryOpts, mspID string) error {
+	return reloadLocalMsp(dir, bccspConfig, mspID, validateSigningIdentity)
+}
+
+// validateSigningIdentity checks that the default
+// signing identity of lclMsp is valid
+func validateSigningIdentity(lclMsp msp.MSP) error {
+	id, err := lclMsp.GetDefaultSigningIdentity()
+	if err != nil {
+		return fmt.Errorf("Failed getting the signing identity of the local MSP [%s]", err)
+	}
+	if err := id.Validate(); err != nil {
+		return fmt.Errorf("Invalid signing identity of the local MSP [%s]", err)
+	}
+	return nil
+}
+
+// reloadLocalMsp reloads the local MSP, provided
+// that check, if not nil, accepts the new local MSP
+func reloadLocalMsp(dir string, bccspConfig *factory.FactoryOpts, mspID string, check func(msp.MSP) error) error {
+	if mspID == "" {
+		return errors.New("The local MSP must have an ID")
+	}
+
+	conf, err := msp.GetLocalMspConfig(dir, bccspConfig, mspID)
+	if err != nil {
+		return err
+	}
+
+	lclMsp, err := msp.NewBccspMsp()
+	if err != nil {
+		return err
+	}
+	if err := lclMsp.Setup(conf); err != nil {
+		return err
+	}
+	m.Lock()
+	configureMsp(lclMsp, "")
+	m.Unlock()
+	if check != nil {
+		if err := check(lclMsp); err != nil {
+			return err
+		}
+	}
+
+	m.Lock()
+	localMsp = lclMsp
+	subscribers := make([]func(msp.MSP), 0, len(localMspSubscribers))
+	for _, subscriber := range localMspSubscribers {
+		subscribers = append(subscribers, subscriber)
+	}
+	m.Unlock()
+
+	mspLogger.Infof("Reloaded local MSP [%s]", mspID)
+	for _, subscriber := range subscribers {
+		subscriber(lclMsp)
+	}
+
+	return nil
+}
+
+// SubscribeLocalMSPUpdates registers subscriber to be invoked with the
+// new local MSP whenever the local MSP is reloaded via ReloadLocalMsp.
+// It returns a function that cancels the subscription.
+func SubscribeLocalMSPUpdates(subscriber func(msp.MSP)) (unsubscribe func()) {
+	m.Lock()
+	defer m.Unlock()
+
+	localMspSubscriptions++
+	id := localMspSubscriptions
+	localMspSubscribers[id] = subscriber
+
+	return func() {
+		m.Lock()
+		defer m.Unlock()
+		delete(localMspSubscribers, id)
+	}
+}
+
 // FIXME: AS SOON AS THE CHAIN MANAGEMENT CODE IS COMPLETE,
 // THESE MAPS AND HELPSER FUNCTIONS SHOULD DISAPPEAR BECAUSE
 // OWNERSHIP OF PER-CHAIN MSP MANAGERS WILL BE HANDLED BY IT;
@@ -49,6 +141,72 @@ var m sync.Mutex
 var localMsp msp.MSP
 var mspMap map[string]msp.MSPManager = make(map[string]msp.MSPManager)
 var mspLogger = logging.MustGetLogger("msp")
+var localMspSubscribers = make(map[uint64]func(msp.MSP))
+var localMspSubscriptions uint64
+var auditLog *msp.AuditLog
+var clockSkew time.Duration
+
+// EnableAuditLog makes the local MSP, as well as the MSPs of the
+// channels passed to ConfigureChannelMSPs, record their validation
+// decisions in an audit log keeping the size most recent ones
+func EnableAuditLog(size int) *msp.AuditLog {
+	m.Lock()
+	defer m.Unlock()
+
+	auditLog = msp.NewAuditLog(size)
+	if localMsp != nil {
+		msp.SetAuditLog(localMsp, auditLog, "")
+	}
+	return auditLog
+}
+
+// GetAuditLog returns the audit log enabled
+// by EnableAuditLog, or nil if it is not enabled
+func GetAuditLog() *msp.AuditLog {
+	m.Lock()
+	defer m.Unlock()
+
+	return auditLog
+}
+
+// SetClockSkew sets the tolerance of the local MSP, as well as of
+// the MSPs of the channels passed to ConfigureChannelMSPs, on the
+// validity period of certificates, see msp.SetClockSkew
+func SetClockSkew(skew time.Duration) {
+	m.Lock()
+	defer m.Unlock()
+
+	clockSkew = skew
+	if localMsp != nil {
+		msp.SetClockSkew(localMsp, skew)
+	}
+}
+
+// ConfigureChannelMSPs applies the audit log and clock skew settings
+// to the MSPs of mgr, the MSP manager of channel chainID. It must be
+// called again whenever the MSPs of the channel change.
+func ConfigureChannelMSPs(chainID string, mgr msp.MSPManager) {
+	msps, err := mgr.GetMSPs()
+	if err != nil {
+		mspLogger.Warningf("Failed configuring the MSPs of channel %s, err %s", chainID, err)
+		return
+	}
+
+	m.Lock()
+	defer m.Unlock()
+	for _, mspInst := range msps {
+		configureMsp(mspInst, chainID)
+	}
+}
+
+// configureMsp applies the audit log and clock skew
+// settings to mspInst, an MSP of channel; m must be held
+func configureMsp(mspInst msp.MSP, channel string) {
+	if auditLog != nil {
+		msp.SetAuditLog(mspInst, auditLog, channel)
+	}
+	msp.SetClockSkew(mspInst, clockSkew)
+}
 
 // GetManagerForChain returns the msp manager for the supplied
 // chain; if no such manager exists, one is created
@@ -117,6 +275,7 @@ func GetLocalMSP() msp.MSP {
 			if err != nil {
 				mspLogger.Fatalf("Failed to initialize local MSP, received err %s", err)
 			}
+			configureMsp(lclMsp, "")
 			localMsp = lclMsp
 		}
 	}
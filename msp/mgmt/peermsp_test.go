@@ -17,9 +17,13 @@ limitations under the License.
 package mgmt
 
 import (
+	"errors"
 	"io/ioutil"
 	"os"
 	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/msp"
 )
 
 // getTestMSPConfigPath returns the path to sampleconfig for unit tests
@@ -44,3 +48,190 @@ func TestLocalMSP(t *testing.T) {
 		t.Fatalf("GetDefaultSigningIdentity failed, err %s", err)
 	}
 }
+
+func TestReloadLocalMSP(t *testing.T) {
+	testMSPConfigPath := getTestMSPConfigPath()
+	if err := LoadLocalMsp(testMSPConfigPath, nil, "DEFAULT"); err != nil {
+		t.Fatalf("LoadLocalMsp failed, err %s", err)
+	}
+	previous := GetLocalMSP()
+
+	var notified []msp.MSP
+	unsubscribe := SubscribeLocalMSPUpdates(func(lclMsp msp.MSP) {
+		notified = append(notified, lclMsp)
+	})
+
+	if err := ReloadLocalMsp(testMSPConfigPath, nil, "RELOADED"); err != nil {
+		t.Fatalf("ReloadLocalMsp failed, err %s", err)
+	}
+	reloaded := GetLocalMSP()
+	if reloaded == previous {
+		t.Fatalf("The local MSP was not replaced")
+	}
+	if id, _ := reloaded.GetIdentifier(); id != "RELOADED" {
+		t.Fatalf("Unexpected identifier of the reloaded MSP [%s]", id)
+	}
+	if len(notified) != 1 || notified[0] != reloaded {
+		t.Fatalf("Subscriber not notified of the reloaded MSP")
+	}
+
+	// A failed reload leaves the local MSP in place
+	if err := ReloadLocalMsp("/nonexistent", nil, "FAILED"); err == nil {
+		t.Fatalf("ReloadLocalMsp should have failed")
+	}
+	if err := ReloadLocalMsp(testMSPConfigPath, nil, ""); err == nil {
+		t.Fatalf("ReloadLocalMsp should have failed")
+	}
+	if GetLocalMSP() != reloaded || len(notified) != 1 {
+		t.Fatalf("A failed reload must not replace the local MSP")
+	}
+
+	// Unsubscribed subscribers are not notified
+	unsubscribe()
+	if err := ReloadLocalMsp(testMSPConfigPath, nil, "DEFAULT"); err != nil {
+		t.Fatalf("ReloadLocalMsp failed, err %s", err)
+	}
+	if len(notified) != 1 {
+		t.Fatalf("Unsubscribed subscriber notified")
+	}
+}
+
+func TestReloadAndValidateLocalMSP(t *testing.T) {
+	testMSPConfigPath := getTestMSPConfigPath()
+	if err := LoadLocalMsp(testMSPConfigPath, nil, "DEFAULT"); err != nil {
+		t.Fatalf("LoadLocalMsp failed, err %s", err)
+	}
+	previous := GetLocalMSP()
+
+	// A new local MSP rejected by the check is not swapped in
+	err := reloadLocalMsp(testMSPConfigPath, nil, "REJECTED", func(msp.MSP) error {
+		return errors.New("rejected")
+	})
+	if err == nil || err.Error() != "rejected" {
+		t.Fatalf("The reload should have been rejected, got %v", err)
+	}
+	if GetLocalMSP() != previous {
+		t.Fatalf("A rejected reload must not replace the local MSP")
+	}
+
+	// The signing identity must be valid
+	if err := validateSigningIdentity(msp.NewNoopMsp()); err != nil {
+		t.Fatalf("validateSigningIdentity failed, err %s", err)
+	}
+	conf, err := msp.GetVerifyingMspConfig(testMSPConfigPath, nil, "VERIFYING")
+	if err != nil {
+		t.Fatalf("GetVerifyingMspConfig failed, err %s", err)
+	}
+	verifyingMsp, err := msp.NewBccspMsp()
+	if err != nil {
+		t.Fatalf("NewBccspMsp failed, err %s", err)
+	}
+	if err := verifyingMsp.Setup(conf); err != nil {
+		t.Fatalf("Setup failed, err %s", err)
+	}
+	if err := validateSigningIdentity(verifyingMsp); err == nil {
+		t.Fatalf("An MSP without signing identity should have been rejected")
+	}
+}
+
+func TestAuditLog(t *testing.T) {
+	testMSPConfigPath := getTestMSPConfigPath()
+	if err := LoadLocalMsp(testMSPConfigPath, nil, "DEFAULT"); err != nil {
+		t.Fatalf("LoadLocalMsp failed, err %s", err)
+	}
+	defer func() {
+		m.Lock()
+		auditLog = nil
+		m.Unlock()
+		msp.SetAuditLog(GetLocalMSP(), nil, "")
+	}()
+
+	// Without audit log, nothing is recorded
+	ConfigureChannelMSPs("mychannel", msp.NewMSPManager())
+	if GetAuditLog() != nil {
+		t.Fatalf("The audit log should not be enabled")
+	}
+
+	log := EnableAuditLog(10)
+	if GetAuditLog() != log {
+		t.Fatalf("Unexpected audit log")
+	}
+
+	// The local MSP records its decisions, even once reloaded
+	signer, err := GetLocalMSP().GetDefaultSigningIdentity()
+	if err != nil {
+		t.Fatalf("GetDefaultSigningIdentity failed, err %s", err)
+	}
+	signer.Validate()
+	if err := ReloadLocalMsp(testMSPConfigPath, nil, "DEFAULT"); err != nil {
+		t.Fatalf("ReloadLocalMsp failed, err %s", err)
+	}
+	signer, err = GetLocalMSP().GetDefaultSigningIdentity()
+	if err != nil {
+		t.Fatalf("GetDefaultSigningIdentity failed, err %s", err)
+	}
+	signer.Validate()
+
+	// As do the MSPs of channels
+	verifyingMsp, err := msp.NewBccspMsp()
+	if err != nil {
+		t.Fatalf("NewBccspMsp failed, err %s", err)
+	}
+	conf, err := msp.GetVerifyingMspConfig(testMSPConfigPath, nil, "DEFAULT")
+	if err != nil {
+		t.Fatalf("GetVerifyingMspConfig failed, err %s", err)
+	}
+	if err := verifyingMsp.Setup(conf); err != nil {
+		t.Fatalf("Setup failed, err %s", err)
+	}
+	mgr := msp.NewMSPManager()
+	if err := mgr.Setup([]msp.MSP{verifyingMsp}); err != nil {
+		t.Fatalf("Setup failed, err %s", err)
+	}
+	ConfigureChannelMSPs("mychannel", mgr)
+	verifyingMsp.Validate(signer)
+
+	records := log.Records()
+	if len(records) != 3 {
+		t.Fatalf("Expected 3 audit records, got %d", len(records))
+	}
+	for i, channel := range []string{"", "", "mychannel"} {
+		if records[i].Channel != channel || records[i].Operation != msp.AuditValidate {
+			t.Fatalf("Unexpected audit record %d: %+v", i, records[i])
+		}
+	}
+}
+
+func TestClockSkew(t *testing.T) {
+	testMSPConfigPath := getTestMSPConfigPath()
+	if err := LoadLocalMsp(testMSPConfigPath, nil, "DEFAULT"); err != nil {
+		t.Fatalf("LoadLocalMsp failed, err %s", err)
+	}
+	defer SetClockSkew(0)
+
+	signer, err := GetLocalMSP().GetDefaultSigningIdentity()
+	if err != nil {
+		t.Fatalf("GetDefaultSigningIdentity failed, err %s", err)
+	}
+
+	// The certificate of the sample signing identity has expired
+	// since, by less than the skew the local MSP tolerates
+	notAfter := signer.(interface {
+		ExpiresAt() time.Time
+	}).ExpiresAt()
+	if time.Now().Before(notAfter) {
+		t.Skip("The sample signing identity has not expired")
+	}
+	if err := signer.Validate(); err == nil {
+		t.Fatalf("An expired identity should not be valid")
+	}
+	SetClockSkew(time.Since(notAfter) + time.Hour)
+	if err := signer.Validate(); err != nil {
+		t.Fatalf("The identity should be valid within the clock skew, err %s", err)
+	}
+
+	// The skew also applies to the reloaded local MSP
+	if err := ReloadAndValidateLocalMsp(testMSPConfigPath, nil, "DEFAULT"); err != nil {
+		t.Fatalf("ReloadAndValidateLocalMsp failed, err %s", err)
+	}
+}
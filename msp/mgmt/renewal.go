@@ -0,0 +1,289 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mgmt
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric/bccsp/factory"
+	"github.com/hyperledger/fabric/bccsp/signer"
+	"github.com/hyperledger/fabric/msp"
+)
+
+// Enroller obtains certificates from a certificate authority
+type Enroller interface {
+	// Reenroll returns the PEM encoded certificate the CA issues for
+	// the PEM encoded certificate signing request csr, authenticating
+	// the request as id, the current signing identity of the local MSP
+	Reenroll(csr []byte, id msp.SigningIdentity) ([]byte, error)
+}
+
+// RenewalConfig configures the renewal of the certificate
+// of the signing identity of the local MSP
+type RenewalConfig struct {
+	// Dir, BCCSPConfig and MSPID are those the local MSP is loaded with
+	Dir         string
+	BCCSPConfig *factory.FactoryOpts
+	MSPID       string
+
+	// Window is how long before its expiry the certificate is renewed
+	Window time.Duration
+	// CheckInterval is how often the expiry of the certificate is checked
+	CheckInterval time.Duration
+
+	// Enroller obtains the new certificates
+	Enroller Enroller
+}
+
+// CertRenewer renews the certificate of the signing identity of the
+// local MSP when it gets close to its expiry: the key of the identity
+// is rotated, the CA is asked to certify the new key, the certificate
+// replaces the previous one in the signcerts folder of the MSP, and
+// the local MSP is reloaded, see ReloadLocalMsp.
+type CertRenewer struct {
+	conf RenewalConfig
+
+	// csp rotates the keys, reload reloads the local MSP
+	csp    bccsp.BCCSP
+	reload func() error
+
+	lock     sync.Mutex
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// NewCertRenewer returns a CertRenewer configured by conf
+func NewCertRenewer(conf RenewalConfig) (*CertRenewer, error) {
+	if conf.Dir == "" || conf.MSPID == "" {
+		return nil, errors.New("The directory and the ID of the local MSP must be set")
+	}
+	if conf.Enroller == nil {
+		return nil, errors.New("Invalid enroller. It must not be nil.")
+	}
+	if conf.Window <= 0 || conf.CheckInterval <= 0 {
+		return nil, fmt.Errorf("Invalid renewal window [%s] or check interval [%s]. They must be positive.", conf.Window, conf.CheckInterval)
+	}
+
+	return &CertRenewer{
+		conf: conf,
+		csp:  factory.GetDefault(),
+		reload: func() error {
+			return ReloadLocalMsp(conf.Dir, conf.BCCSPConfig, conf.MSPID)
+		},
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}, nil
+}
+
+// Start checks, right away and then periodically, whether
+// the certificate must be renewed, until Stop is called
+func (r *CertRenewer) Start() {
+	go func() {
+		defer close(r.done)
+
+		ticker := time.NewTicker(r.conf.CheckInterval)
+		defer ticker.Stop()
+		for {
+			if _, err := r.RenewIfNeeded(); err != nil {
+				mspLogger.Errorf("Failed renewing the certificate of the local MSP, retrying in [%s]: [%s]", r.conf.CheckInterval, err)
+			}
+
+			select {
+			case <-ticker.C:
+			case <-r.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the periodic checks started by Start, and waits for
+// an ongoing renewal to complete
+func (r *CertRenewer) Stop() {
+	r.stopOnce.Do(func() {
+		close(r.stop)
+	})
+	<-r.done
+}
+
+// RenewIfNeeded renews the certificate if it expires within the
+// renewal window, and returns whether it did. If the local MSP
+// cannot be reloaded, the previous certificate is restored.
+func (r *CertRenewer) RenewIfNeeded() (bool, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	certFile, certPEM, cert, err := readSignCert(r.conf.Dir)
+	if err != nil {
+		return false, err
+	}
+	remaining := cert.NotAfter.Sub(time.Now())
+	if remaining > r.conf.Window {
+		mspLogger.Debugf("Certificate of the local MSP expires in [%s], not renewing yet", remaining)
+		return false, nil
+	}
+	mspLogger.Infof("Certificate of the local MSP expires in [%s], renewing it", remaining)
+
+	// Rotate the key of the signing identity
+	certPubKey, err := r.csp.KeyImport(cert, &bccsp.X509PublicKeyImportOpts{Temporary: true})
+	if err != nil {
+		return false, fmt.Errorf("Failed importing the public key of the certificate [%s]", err)
+	}
+	key, err := r.csp.GetKey(certPubKey.SKI())
+	if err != nil {
+		return false, fmt.Errorf("Failed getting the key of the certificate [%s]", err)
+	}
+	_, newSKI, err := r.csp.RotateKey(key, nil)
+	if err != nil {
+		return false, fmt.Errorf("Failed rotating the key of the certificate [%s]", err)
+	}
+	newKey, err := r.csp.GetKey(newSKI)
+	if err != nil {
+		return false, fmt.Errorf("Failed getting the rotated key [%s]", err)
+	}
+
+	// Have it certified
+	csr, err := newCSR(r.csp, newKey, cert)
+	if err != nil {
+		return false, err
+	}
+	id, err := GetLocalMSP().GetDefaultSigningIdentity()
+	if err != nil {
+		return false, fmt.Errorf("Failed getting the signing identity of the local MSP [%s]", err)
+	}
+	newCertPEM, err := r.conf.Enroller.Reenroll(csr, id)
+	if err != nil {
+		return false, fmt.Errorf("Failed reenrolling [%s]", err)
+	}
+	if err := r.checkCert(newCertPEM, newSKI); err != nil {
+		return false, err
+	}
+
+	// Swap the certificates, and reload the local MSP
+	if err := writeFileAtomically(certFile, newCertPEM); err != nil {
+		return false, fmt.Errorf("Failed writing the new certificate [%s]", err)
+	}
+	if err := r.reload(); err != nil {
+		if restoreErr := writeFileAtomically(certFile, certPEM); restoreErr != nil {
+			mspLogger.Errorf("Failed restoring the previous certificate [%s]", restoreErr)
+		}
+		return false, fmt.Errorf("Failed reloading the local MSP with the new certificate [%s]", err)
+	}
+
+	mspLogger.Infof("Renewed the certificate of the local MSP, new key [%x]", newSKI)
+	return true, nil
+}
+
+// checkCert checks that certPEM certifies the key of SKI ski
+func (r *CertRenewer) checkCert(certPEM []byte, ski []byte) error {
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return errors.New("Invalid new certificate. It must be PEM encoded.")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return fmt.Errorf("Failed parsing the new certificate [%s]", err)
+	}
+	pubKey, err := r.csp.KeyImport(cert, &bccsp.X509PublicKeyImportOpts{Temporary: true})
+	if err != nil {
+		return fmt.Errorf("Failed importing the public key of the new certificate [%s]", err)
+	}
+	if !bytes.Equal(pubKey.SKI(), ski) {
+		return errors.New("Invalid new certificate. It does not certify the rotated key.")
+	}
+	return nil
+}
+
+// newCSR returns a PEM encoded certificate signing request for k,
+// with the subject and the alternative names of cert
+func newCSR(csp bccsp.BCCSP, k bccsp.Key, cert *x509.Certificate) ([]byte, error) {
+	s := &signer.CryptoSigner{}
+	if err := s.Init(csp, k); err != nil {
+		return nil, fmt.Errorf("Failed initializing signer [%s]", err)
+	}
+
+	template := &x509.CertificateRequest{
+		Subject:        cert.Subject,
+		DNSNames:       cert.DNSNames,
+		EmailAddresses: cert.EmailAddresses,
+		IPAddresses:    cert.IPAddresses,
+	}
+	der, err := x509.CreateCertificateRequest(rand.Reader, template, s)
+	if err != nil {
+		return nil, fmt.Errorf("Failed creating certificate signing request [%s]", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der}), nil
+}
+
+// readSignCert returns the path, the content and the parsed
+// certificate of the single file of the signcerts folder of dir
+func readSignCert(dir string) (string, []byte, *x509.Certificate, error) {
+	signcertDir := filepath.Join(dir, "signcerts")
+	files, err := ioutil.ReadDir(signcertDir)
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("Failed reading directory %s [%s]", signcertDir, err)
+	}
+	var certFiles []string
+	for _, f := range files {
+		if !f.IsDir() {
+			certFiles = append(certFiles, filepath.Join(signcertDir, f.Name()))
+		}
+	}
+	if len(certFiles) != 1 {
+		return "", nil, nil, fmt.Errorf("Directory %s must hold exactly one certificate, found [%d]", signcertDir, len(certFiles))
+	}
+
+	certPEM, err := ioutil.ReadFile(certFiles[0])
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("Failed reading certificate [%s]", err)
+	}
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return "", nil, nil, fmt.Errorf("Failed decoding PEM of %s", certFiles[0])
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("Failed parsing certificate %s [%s]", certFiles[0], err)
+	}
+	return certFiles[0], certPEM, cert, nil
+}
+
+// writeFileAtomically replaces the content of path with data, so
+// that readers never see a partially written file
+func writeFileAtomically(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,317 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mgmt
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric/bccsp/sw"
+	"github.com/hyperledger/fabric/msp"
+)
+
+type mockCA struct {
+	cert     *x509.Certificate
+	key      *ecdsa.PrivateKey
+	validity time.Duration
+	err      error
+	// otherKey, if set, is certified instead of the key of the CSR
+	otherKey interface{}
+	ids      []msp.SigningIdentity
+}
+
+func newMockCA(t *testing.T) *mockCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed generating CA key [%s]", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour * 365),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed creating CA certificate [%s]", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return &mockCA{cert: cert, key: key, validity: 24 * time.Hour * 365}
+}
+
+func (ca *mockCA) issue(pub interface{}, subject pkix.Name, validity time.Duration) ([]byte, error) {
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      subject,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(validity),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, pub, ca.key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), nil
+}
+
+func (ca *mockCA) Reenroll(csrPEM []byte, id msp.SigningIdentity) ([]byte, error) {
+	ca.ids = append(ca.ids, id)
+	if ca.err != nil {
+		return nil, ca.err
+	}
+
+	block, _ := pem.Decode(csrPEM)
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	if err := csr.CheckSignature(); err != nil {
+		return nil, err
+	}
+	pub := csr.PublicKey
+	if ca.otherKey != nil {
+		pub = ca.otherKey
+	}
+	return ca.issue(pub, csr.Subject, ca.validity)
+}
+
+// newRenewalTestMSP returns a folder holding a signing certificate,
+// issued by ca and expiring after validity, whose key is in csp
+func newRenewalTestMSP(t *testing.T, csp bccsp.BCCSP, ca *mockCA, validity time.Duration) string {
+	dir, err := ioutil.TempDir("", "renewal")
+	if err != nil {
+		t.Fatalf("Failed creating temporary folder [%s]", err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "signcerts"), 0755); err != nil {
+		t.Fatalf("Failed creating signcerts folder [%s]", err)
+	}
+
+	k, err := csp.KeyGen(&bccsp.ECDSAP256KeyGenOpts{})
+	if err != nil {
+		t.Fatalf("Failed generating key [%s]", err)
+	}
+	pk, _ := k.PublicKey()
+	raw, _ := pk.Bytes()
+	pub, err := x509.ParsePKIXPublicKey(raw)
+	if err != nil {
+		t.Fatalf("Failed parsing public key [%s]", err)
+	}
+
+	certPEM, err := ca.issue(pub, pkix.Name{CommonName: "peer0"}, validity)
+	if err != nil {
+		t.Fatalf("Failed issuing certificate [%s]", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "signcerts", "peer.pem"), certPEM, 0644); err != nil {
+		t.Fatalf("Failed writing certificate [%s]", err)
+	}
+	return dir
+}
+
+func TestCertRenewal(t *testing.T) {
+	if err := LoadLocalMsp(getTestMSPConfigPath(), nil, "DEFAULT"); err != nil {
+		t.Fatalf("LoadLocalMsp failed, err %s", err)
+	}
+
+	ksDir, err := ioutil.TempDir("", "renewalks")
+	if err != nil {
+		t.Fatalf("Failed creating temporary folder [%s]", err)
+	}
+	defer os.RemoveAll(ksDir)
+	ks, err := sw.NewFileBasedKeyStore(nil, ksDir, false)
+	if err != nil {
+		t.Fatalf("Failed creating keystore [%s]", err)
+	}
+	csp, err := sw.New(256, "SHA2", ks)
+	if err != nil {
+		t.Fatalf("Failed creating BCCSP [%s]", err)
+	}
+
+	ca := newMockCA(t)
+	dir := newRenewalTestMSP(t, csp, ca, time.Hour)
+	defer os.RemoveAll(dir)
+
+	r, err := NewCertRenewer(RenewalConfig{
+		Dir:           dir,
+		MSPID:         "DEFAULT",
+		Window:        24 * time.Hour,
+		CheckInterval: time.Hour,
+		Enroller:      ca,
+	})
+	if err != nil {
+		t.Fatalf("NewCertRenewer failed, err %s", err)
+	}
+	reloads := 0
+	var reloadErr error
+	r.csp = csp
+	r.reload = func() error {
+		reloads++
+		return reloadErr
+	}
+
+	// The certificate expires within the window
+	_, oldPEM, oldCert, _ := readSignCert(dir)
+	renewed, err := r.RenewIfNeeded()
+	if err != nil || !renewed {
+		t.Fatalf("The certificate should have been renewed [%v]", err)
+	}
+	_, _, newCert, err := readSignCert(dir)
+	if err != nil {
+		t.Fatalf("Failed reading the new certificate [%s]", err)
+	}
+	if newCert.SerialNumber.Cmp(oldCert.SerialNumber) == 0 || newCert.Subject.CommonName != "peer0" {
+		t.Fatalf("The certificate was not replaced by a new certificate of the same subject")
+	}
+	newPub, _ := csp.KeyImport(newCert, &bccsp.X509PublicKeyImportOpts{Temporary: true})
+	if _, err := csp.GetKey(newPub.SKI()); err != nil {
+		t.Fatalf("The key of the new certificate should be in the keystore [%s]", err)
+	}
+	if reloads != 1 || len(ca.ids) != 1 || ca.ids[0] == nil {
+		t.Fatalf("The local MSP should have been reloaded after reenrolling as its signing identity")
+	}
+
+	// The new certificate does not expire within the window
+	renewed, err = r.RenewIfNeeded()
+	if err != nil || renewed {
+		t.Fatalf("The certificate should not have been renewed [%v]", err)
+	}
+
+	// Failures leave the certificate in place
+	if err := ioutil.WriteFile(filepath.Join(dir, "signcerts", "peer.pem"), oldPEM, 0644); err != nil {
+		t.Fatalf("Failed writing certificate [%s]", err)
+	}
+	ca.err = errors.New("CA unavailable")
+	if _, err := r.RenewIfNeeded(); err == nil {
+		t.Fatal("Renewal should fail when the CA fails")
+	}
+	ca.err = nil
+	ca.otherKey = &ca.key.PublicKey
+	if _, err := r.RenewIfNeeded(); err == nil {
+		t.Fatal("Renewal should fail when the CA certifies another key")
+	}
+	ca.otherKey = nil
+	reloadErr = errors.New("invalid MSP")
+	if _, err := r.RenewIfNeeded(); err == nil {
+		t.Fatal("Renewal should fail when the local MSP cannot be reloaded")
+	}
+	if _, certPEM, _, _ := readSignCert(dir); string(certPEM) != string(oldPEM) {
+		t.Fatal("The previous certificate should have been restored")
+	}
+}
+
+func TestCertRenewerStartStop(t *testing.T) {
+	if _, err := NewCertRenewer(RenewalConfig{Dir: "dir", MSPID: "DEFAULT", Window: time.Hour, CheckInterval: time.Hour}); err == nil {
+		t.Fatal("NewCertRenewer should fail without an enroller")
+	}
+	if _, err := NewCertRenewer(RenewalConfig{Dir: "dir", MSPID: "DEFAULT", Enroller: newMockCA(t)}); err == nil {
+		t.Fatal("NewCertRenewer should fail without a window")
+	}
+
+	// Failed checks are retried until stopped
+	r, err := NewCertRenewer(RenewalConfig{
+		Dir:           "/nonexistent",
+		MSPID:         "DEFAULT",
+		Window:        time.Hour,
+		CheckInterval: time.Millisecond,
+		Enroller:      newMockCA(t),
+	})
+	if err != nil {
+		t.Fatalf("NewCertRenewer failed, err %s", err)
+	}
+	r.Start()
+	time.Sleep(10 * time.Millisecond)
+	r.Stop()
+	r.Stop()
+}
+
+func TestFabricCAEnroller(t *testing.T) {
+	if err := LoadLocalMsp(getTestMSPConfigPath(), nil, "DEFAULT"); err != nil {
+		t.Fatalf("LoadLocalMsp failed, err %s", err)
+	}
+	id, err := GetLocalMSP().GetDefaultSigningIdentity()
+	if err != nil {
+		t.Fatalf("GetDefaultSigningIdentity failed, err %s", err)
+	}
+
+	fail := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/api/v1/reenroll" {
+			http.NotFound(w, req)
+			return
+		}
+		body, _ := ioutil.ReadAll(req.Body)
+		request := &fabricCAReenrollRequest{}
+		json.Unmarshal(body, request)
+
+		// The token is signed by the signing identity
+		parts := strings.Split(req.Header.Get("Authorization"), ".")
+		cert, _ := base64.StdEncoding.DecodeString(parts[0])
+		signature, _ := base64.StdEncoding.DecodeString(parts[1])
+		serialized, _ := id.Serialize()
+		sID := &msp.SerializedIdentity{}
+		proto.Unmarshal(serialized, sID)
+		msg := base64.StdEncoding.EncodeToString(body) + "." + parts[0]
+		if fail || string(cert) != string(sID.IdBytes) || id.Verify([]byte(msg), signature) != nil || request.CAName != "ca1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"success":false,"errors":[{"code":20,"message":"Authorization failure"}]}`))
+			return
+		}
+
+		w.Write([]byte(`{"success":true,"result":{"Cert":"` + base64.StdEncoding.EncodeToString([]byte(request.CSR)) + `"}}`))
+	}))
+	defer server.Close()
+
+	e, err := NewFabricCAEnroller(server.URL+"/", "ca1", nil, time.Second)
+	if err != nil {
+		t.Fatalf("NewFabricCAEnroller failed, err %s", err)
+	}
+	cert, err := e.Reenroll([]byte("csr"), id)
+	if err != nil {
+		t.Fatalf("Reenroll failed, err %s", err)
+	}
+	if string(cert) != "csr" {
+		t.Fatalf("Unexpected certificate [%s]", cert)
+	}
+
+	fail = true
+	if _, err := e.Reenroll([]byte("csr"), id); err == nil || !strings.Contains(err.Error(), "Authorization failure") {
+		t.Fatalf("Reenroll should fail with the error of the server, got [%v]", err)
+	}
+
+	if _, err := NewFabricCAEnroller("", "", nil, time.Second); err == nil {
+		t.Fatal("NewFabricCAEnroller should fail without URL")
+	}
+}
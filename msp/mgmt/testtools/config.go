@@ -19,12 +19,22 @@ package msptesttools
 import (
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/hyperledger/fabric/common/util"
 	"github.com/hyperledger/fabric/msp"
 	"github.com/hyperledger/fabric/msp/mgmt"
 )
 
+// SampleConfigClock tells a time the certificates of
+// sampleconfig, some of which expired, are valid at
+type SampleConfigClock struct{}
+
+// Now returns June 1st, 2017
+func (SampleConfigClock) Now() time.Time {
+	return time.Date(2017, 6, 1, 0, 0, 0, 0, time.UTC)
+}
+
 func getConfigPath(dir string) (string, error) {
 	// Try to read the dir
 	if _, err := os.Stat(dir); err != nil {
@@ -53,6 +63,7 @@ func LoadMSPSetupForTesting(dir string) error {
 		return err
 	}
 
+	msp.SetClock(mgmt.GetLocalMSP(), SampleConfigClock{})
 	err = mgmt.GetLocalMSP().Setup(conf)
 	if err != nil {
 		return err
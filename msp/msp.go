@@ -17,6 +17,8 @@ limitations under the License.
 package msp
 
 import (
+	"encoding/asn1"
+
 	"github.com/hyperledger/fabric/protos/common"
 	"github.com/hyperledger/fabric/protos/msp"
 )
@@ -128,11 +130,12 @@ type Identity interface {
 	// Examples:
 	//  - if the identity is an x.509 certificate, this function returns one
 	//    or more string which is encoded in the Subject's Distinguished Name
-	//    of the type OU
-	// TODO: For X.509 based identities, check if we need a dedicated type
-	//       for OU where the Certificate OU is properly namespaced by the
-	//       signer's identity
-	GetOrganizationalUnits() []string
+	//    of the type OU, namespaced by the identifier of the chain of
+	//    trust of the certificate
+	GetOrganizationalUnits() []*OUIdentifier
+
+	// Anonymous returns true if this is an anonymous identity, false otherwise
+	Anonymous() bool
 
 	// Verify a signature over some message using this identity as reference
 	Verify(msg []byte, sig []byte) error
@@ -198,6 +201,25 @@ type SignatureOpts struct {
 	Label  string
 }
 
+// ValidationOpts are identity validation options
+type ValidationOpts struct {
+	// AllowedCriticalExtensions lists the object identifiers of unknown
+	// critical x.509 extensions to be ignored when validating an identity.
+	// By default, an identity carrying an unknown critical extension is
+	// rejected, as mandated by RFC 5280. Ignoring such an extension means
+	// ignoring whatever constraint its issuer meant to impose on the use
+	// of the certificate, so an extension should be allowed only if its
+	// semantics are known to be irrelevant to Fabric.
+	AllowedCriticalExtensions []asn1.ObjectIdentifier
+}
+
+// OptsValidator is implemented by identities
+// that support validation options
+type OptsValidator interface {
+	// ValidateOpts validates this identity applying the given options
+	ValidateOpts(opts *ValidationOpts) error
+}
+
 // Attribute is an arbitrary name/value pair
 type Attribute interface {
 	Key() AttributeName
@@ -226,6 +248,17 @@ type AttributeProofSpec struct {
 // Structures defining the identifiers for identity providers and members
 // and members that belong to them.
 
+// OUIdentifier represents an organizational unit and
+// its related chain of trust identifier.
+type OUIdentifier struct {
+	// CertifiersIdentifier is the hash of certificates chain of trust
+	// related to this organizational unit
+	CertifiersIdentifier []byte
+	// OrganizationUnitIdentifier defines the organizational unit under the
+	// MSP identified with MSPIdentifier
+	OrganizationalUnitIdentifier string
+}
+
 // IdentityIdentifier is a holder for the identifier of a specific
 // identity, naturally namespaced, by its provider identifier.
 type IdentityIdentifier struct {
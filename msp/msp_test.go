@@ -20,6 +20,7 @@ import (
 	"os"
 	"reflect"
 	"testing"
+	"time"
 
 	"fmt"
 
@@ -257,7 +258,17 @@ func TestGetOU(t *testing.T) {
 		return
 	}
 
-	assert.Equal(t, "COP", id.GetOrganizationalUnits()[0])
+	assert.Equal(t, "COP", id.GetOrganizationalUnits()[0].OrganizationalUnitIdentifier)
+}
+
+func TestExpiresAt(t *testing.T) {
+	id, err := localMsp.GetDefaultSigningIdentity()
+	if err != nil {
+		t.Fatalf("GetSigningIdentity should have succeeded")
+		return
+	}
+
+	assert.Equal(t, time.Date(2017, 11, 11, 17, 7, 0, 0, time.UTC), id.(*signingidentity).ExpiresAt().UTC())
 }
 
 func TestOUPolicyPrincipal(t *testing.T) {
@@ -277,6 +288,62 @@ func TestOUPolicyPrincipal(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestOUPolicyPrincipalCertifiers(t *testing.T) {
+	root := issueTestCert(t, "root", nil, pathLen(-1))
+	intermediate := issueTestCert(t, "intermediate", root, pathLen(-1))
+	thisMSP, err := setupCAChainMSP(root, intermediate)
+	assert.NoError(t, err)
+
+	viaRoot, err := thisMSP.DeserializeIdentity(serializeTestCert(t, "CACHAIN", issueTestCert(t, "peer0", root, nil, "COP").cert))
+	assert.NoError(t, err)
+	viaIntermediate, err := thisMSP.DeserializeIdentity(serializeTestCert(t, "CACHAIN", issueTestCert(t, "peer1", intermediate, nil, "COP").cert))
+	assert.NoError(t, err)
+
+	// The OUs are namespaced by the chain of trust of the identities
+	ous := viaIntermediate.GetOrganizationalUnits()
+	assert.Len(t, ous, 1)
+	assert.Equal(t, "COP", ous[0].OrganizationalUnitIdentifier)
+	assert.NotEqual(t, viaRoot.GetOrganizationalUnits()[0].CertifiersIdentifier, ous[0].CertifiersIdentifier)
+
+	principal := func(certifiers []byte) *common.MSPPrincipal {
+		ou, err := proto.Marshal(&common.OrganizationUnit{
+			MspIdentifier:                "CACHAIN",
+			OrganizationalUnitIdentifier: "COP",
+			CertifiersIdentifier:         certifiers,
+		})
+		assert.NoError(t, err)
+		return &common.MSPPrincipal{PrincipalClassification: common.MSPPrincipal_ORGANIZATION_UNIT, Principal: ou}
+	}
+
+	// Any chain of trust is accepted if the principal names none
+	assert.NoError(t, viaRoot.SatisfiesPrincipal(principal(nil)))
+	assert.NoError(t, viaIntermediate.SatisfiesPrincipal(principal(nil)))
+
+	// Otherwise, only the OUs certified by that chain of trust
+	assert.Error(t, viaRoot.SatisfiesPrincipal(principal(ous[0].CertifiersIdentifier)))
+	assert.NoError(t, viaIntermediate.SatisfiesPrincipal(principal(ous[0].CertifiersIdentifier)))
+}
+
+func TestAnonymityPolicyPrincipal(t *testing.T) {
+	root := issueTestCert(t, "root", nil, pathLen(-1))
+	thisMSP, err := setupCAChainMSP(root)
+	assert.NoError(t, err)
+	id, err := thisMSP.DeserializeIdentity(serializeTestCert(t, "CACHAIN", issueTestCert(t, "peer0", root, nil).cert))
+	assert.NoError(t, err)
+	assert.False(t, id.Anonymous())
+
+	principal := func(anonymityType common.MSPIdentityAnonymity_MSPIdentityAnonymityType) *common.MSPPrincipal {
+		anonymity, err := proto.Marshal(&common.MSPIdentityAnonymity{AnonymityType: anonymityType})
+		assert.NoError(t, err)
+		return &common.MSPPrincipal{PrincipalClassification: common.MSPPrincipal_ANONYMITY, Principal: anonymity}
+	}
+
+	assert.NoError(t, id.SatisfiesPrincipal(principal(common.MSPIdentityAnonymity_NOMINAL)))
+	assert.Error(t, id.SatisfiesPrincipal(principal(common.MSPIdentityAnonymity_ANONYMOUS)))
+	assert.Error(t, id.SatisfiesPrincipal(principal(common.MSPIdentityAnonymity_MSPIdentityAnonymityType(2))))
+	assert.Error(t, id.SatisfiesPrincipal(&common.MSPPrincipal{PrincipalClassification: common.MSPPrincipal_ANONYMITY, Principal: []byte("garbage")}))
+}
+
 func TestAdminPolicyPrincipal(t *testing.T) {
 	id, err := localMsp.GetDefaultSigningIdentity()
 	assert.NoError(t, err)
@@ -314,6 +381,10 @@ var conf *msp.MSPConfig
 var localMsp MSP
 var mspMgr MSPManager
 
+// fixtureTime is a time the certificates of sampleconfig, and of the
+// MSP with intermediate CAs, are valid at, as some of them expired
+var fixtureTime = fixedClock(time.Date(2017, 6, 1, 0, 0, 0, 0, time.UTC))
+
 func TestMain(m *testing.M) {
 	var err error
 	conf, err = GetLocalMspConfig("./sampleconfig/", nil, "DEFAULT")
@@ -327,6 +398,7 @@ func TestMain(m *testing.M) {
 		fmt.Printf("Constructor for msp should have succeeded, got err %s instead", err)
 		os.Exit(-1)
 	}
+	SetClock(localMsp, fixtureTime)
 
 	err = localMsp.Setup(conf)
 	if err != nil {
@@ -27,6 +27,8 @@ import (
 	"fmt"
 	"math/big"
 	"reflect"
+	"sync/atomic"
+	"time"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/bccsp"
@@ -60,8 +62,22 @@ type bccspmsp struct {
 	// verification options for MSP members
 	opts *x509.VerifyOptions
 
-	// list of certificate revocation lists
-	CRL []*pkix.CertificateList
+	// index of the certificates revoked by the CRLs
+	revocations *revocationStore
+
+	// classification of identities by organizational unit, if enabled
+	nodeOUs *nodeOUs
+
+	// auditor holds the *auditor recording the decisions
+	// of this MSP, if audit is enabled, see SetAuditLog
+	auditor atomic.Value
+
+	// clock gives the time certificates are validated at, see SetClock
+	clock Clock
+
+	// clockSkew is the tolerance on the validity period
+	// of certificates, see SetClockSkew
+	clockSkew time.Duration
 }
 
 // NewBccspMsp returns an MSP instance backed up by a BCCSP
@@ -129,9 +145,18 @@ func (msp *bccspmsp) getSigningIdentityFromConf(sidInfo *m.SigningIdentityInfo)
 		}
 
 		pemKey, _ := pem.Decode(sidInfo.PrivateSigner.KeyMaterial)
-		privKey, err = msp.bccsp.KeyImport(pemKey.Bytes, &bccsp.ECDSAPrivateKeyImportOpts{Temporary: true})
+		var opts bccsp.KeyImportOpts
+		switch idPub.(*identity).cert.PublicKeyAlgorithm {
+		case x509.Ed25519:
+			opts = &bccsp.ED25519PrivateKeyImportOpts{Temporary: true}
+		case x509.RSA:
+			opts = &bccsp.RSAPrivateKeyImportOpts{Temporary: true}
+		default:
+			opts = &bccsp.ECDSAPrivateKeyImportOpts{Temporary: true}
+		}
+		privKey, err = msp.bccsp.KeyImport(pemKey.Bytes, opts)
 		if err != nil {
-			return nil, fmt.Errorf("getIdentityFromBytes error: Failed to import EC private key, err %s", err)
+			return nil, fmt.Errorf("getIdentityFromBytes error: Failed to import %s private key, err %s", opts.Algorithm(), err)
 		}
 	}
 
@@ -312,20 +337,24 @@ func (msp *bccspmsp) Setup(conf1 *m.MSPConfig) error {
 		msp.opts.Intermediates.AddCert(v.(*identity).cert)
 	}
 
-	// setup the CRL (if present)
-	msp.CRL = make([]*pkix.CertificateList, len(conf.RevocationList))
-	for i, crlbytes := range conf.RevocationList {
-		crl, err := x509.ParseCRL(crlbytes)
-		if err != nil {
-			return fmt.Errorf("Could not parse RevocationList, err %s", err)
+	// ensure that each intermediate CA chains up to a root CA
+	// without exceeding the path length constraints on the way
+	for _, v := range msp.intermediateCerts {
+		if err := msp.validateCAChain(v.(*identity).cert); err != nil {
+			return err
 		}
+	}
 
-		// TODO: pre-verify the signature on the CRL and create a map
-		//       of CA certs to respective CRLs so that later upon
-		//       validation we can already look up the CRL given the
-		//       chain of the certificate to be validated
+	// setup the classification of identities by organizational unit (if enabled)
+	err = msp.setupNodeOUs(conf.FabricNodeOus)
+	if err != nil {
+		return err
+	}
 
-		msp.CRL[i] = crl
+	// setup the CRL (if present)
+	msp.revocations, err = msp.newRevocationStore(conf.RevocationList)
+	if err != nil {
+		return err
 	}
 
 	return nil
@@ -366,6 +395,12 @@ func (msp *bccspmsp) GetSigningIdentity(identifier *IdentityIdentifier) (Signing
 // nil in case the identity is valid or an
 // error otherwise
 func (msp *bccspmsp) Validate(id Identity) error {
+	err := msp.validate(id)
+	msp.audit(id, AuditValidate, nil, err)
+	return err
+}
+
+func (msp *bccspmsp) validate(id Identity) error {
 	mspLogger.Infof("MSP %s validating identity", msp.name)
 
 	switch id := id.(type) {
@@ -373,96 +408,123 @@ func (msp *bccspmsp) Validate(id Identity) error {
 	// this is how I can validate it given the
 	// root of trust this MSP has
 	case *identity:
-		// we expect to have a valid VerifyOptions instance
-		if msp.opts == nil {
-			return errors.New("Invalid msp instance")
-		}
+		return msp.validateIdentity(id, nil)
+	default:
+		return fmt.Errorf("Identity type not recognized")
+	}
+}
 
-		// CAs cannot be directly used as identities..
-		if id.cert.IsCA {
-			return errors.New("A CA certificate cannot be used directly by this MSP")
+// validateIdentity validates id, a member of this MSP,
+// according to this MSP's roots of trust and revocation lists,
+// applying the supplied validation options, if any
+func (msp *bccspmsp) validateIdentity(id *identity, opts *ValidationOpts) error {
+	// we expect to have a valid VerifyOptions instance
+	if msp.opts == nil {
+		return errors.New("Invalid msp instance")
+	}
+
+	// CAs cannot be directly used as identities..
+	if id.cert.IsCA {
+		return errors.New("A CA certificate cannot be used directly by this MSP")
+	}
+
+	// at this point we might want to perform some
+	// more elaborate validation. We do not do this
+	// yet because we do not want to impose any
+	// constraints without knowing the exact requirements,
+	// but we at least list the kind of extra validation that we might perform:
+	// 1) we might only allow a single verification chain (e.g. we expect the
+	//    cert to be signed exactly only by the CA or only by the intermediate)
+	// 2) we might want to let golang find any path, and then have a blacklist
+	//    of paths (e.g. it can be signed by CA -> iCA1 -> iCA2 and it can be
+	//    signed by CA but not by CA -> iCA1)
+
+	// drop the unknown critical extensions the caller asked us to ignore
+	cert := id.cert
+	if opts != nil {
+		cert = opts.ignoreCriticalExtensions(cert)
+	}
+
+	// ask golang to validate the cert for us based on the options that we've built at setup time
+	verifyOpts := *(msp.opts)
+	verifyOpts.CurrentTime = msp.verificationTime(cert)
+	validationChain, err := cert.Verify(verifyOpts)
+	if err != nil {
+		// report path length violations as such rather than
+		// as the unknown authority golang ends up with
+		if chainErr := msp.validateCAChain(cert); chainErr != nil && chainErr.Reason == ErrPathLenExceeded {
+			return chainErr
 		}
+		return fmt.Errorf("The supplied identity is not valid, Verify() returned %s", err)
+	}
 
-		// at this point we might want to perform some
-		// more elaborate validation. We do not do this
-		// yet because we do not want to impose any
-		// constraints without knowing the exact requirements,
-		// but we at least list the kind of extra validation that we might perform:
-		// 1) we might only allow a single verification chain (e.g. we expect the
-		//    cert to be signed exactly only by the CA or only by the intermediate)
-		// 2) we might want to let golang find any path, and then have a blacklist
-		//    of paths (e.g. it can be signed by CA -> iCA1 -> iCA2 and it can be
-		//    signed by CA but not by CA -> iCA1)
-
-		// ask golang to validate the cert for us based on the options that we've built at setup time
-		validationChain, err := id.cert.Verify(*(msp.opts))
-		if err != nil {
-			return fmt.Errorf("The supplied identity is not valid, Verify() returned %s", err)
-		}
+	// we only support a single validation chain;
+	// if there's more than one then there might
+	// be unclarity about who owns the identity
+	if len(validationChain) != 1 {
+		return fmt.Errorf("This MSP only supports a single validation chain, got %d", len(validationChain))
+	}
 
-		// we only support a single validation chain;
-		// if there's more than one then there might
-		// be unclarity about who owns the identity
-		if len(validationChain) != 1 {
-			return fmt.Errorf("This MSP only supports a single validation chain, got %d", len(validationChain))
-		}
+	// we expect a chain of length at least 2
+	if len(validationChain[0]) < 2 {
+		return fmt.Errorf("Expected a chain of length at least 2, got %d", len(validationChain))
+	}
 
-		// we expect a chain of length at least 2
-		if len(validationChain[0]) < 2 {
-			return fmt.Errorf("Expected a chain of length at least 2, got %d", len(validationChain))
-		}
+	// with NodeOUs enabled, the identity has to belong to exactly one role
+	err = msp.validateNodeOUs(id)
+	if err != nil {
+		return err
+	}
 
-		// here we know that the identity is valid; now we have to check whether it has been revoked
+	// here we know that the identity is valid; now we have to check whether it has been revoked
 
-		// identify the SKI of the CA that signed this cert
-		SKI, err := getSubjectKeyIdentifierFromCert(validationChain[0][1])
-		if err != nil {
-			return fmt.Errorf("Could not obtain Subject Key Identifier for signer cert, err %s", err)
-		}
+	// identify the SKI of the CA that signed this cert
+	SKI, err := getSubjectKeyIdentifierFromCert(validationChain[0][1])
+	if err != nil {
+		return fmt.Errorf("Could not obtain Subject Key Identifier for signer cert, err %s", err)
+	}
 
-		// check whether one of the CRLs we have has this cert's
-		// SKI as its AuthorityKeyIdentifier
-		for _, crl := range msp.CRL {
-			aki, err := getAuthorityKeyIdentifierFromCrl(crl)
-			if err != nil {
-				return fmt.Errorf("Could not obtain Authority Key Identifier for crl, err %s", err)
-			}
+	// check whether one of the CRLs we have revokes this cert
+	if msp.revocations.isRevoked(SKI, id.cert.SerialNumber) {
+		// A CRL also includes a time of revocation so that
+		// the CA can say "this cert is to be revoked starting
+		// from this time"; however here we just assume that
+		// revocation applies instantaneously from the time
+		// the MSP config is committed and used so we will not
+		// make use of that field
+		return errors.New("The certificate has been revoked")
+	}
 
-			// check if the SKI of the cert that signed us matches the AKI of any of the CRLs
-			if bytes.Equal(aki, SKI) {
-				// we have a CRL, check whether the serial number is revoked
-				for _, rc := range crl.TBSCertList.RevokedCertificates {
-					if rc.SerialNumber.Cmp(id.cert.SerialNumber) == 0 {
-						// We have found a CRL whose AKI matches the SKI of
-						// the CA (root or intermediate) that signed the
-						// certificate that is under validation. As a
-						// precaution, we verify that said CA is also the
-						// signer of this CRL.
-						err = validationChain[0][1].CheckCRLSignature(crl)
-						if err != nil {
-							// the CA cert that signed the certificate
-							// that is under validation did not sign the
-							// candidate CRL - skip
-							mspLogger.Warningf("Invalid signature over the identified CRL, error %s", err)
-							continue
-						}
-
-						// A CRL also includes a time of revocation so that
-						// the CA can say "this cert is to be revoked starting
-						// from this time"; however here we just assume that
-						// revocation applies instantaneously from the time
-						// the MSP config is committed and used so we will not
-						// make use of that field
-						return errors.New("The certificate has been revoked")
-					}
-				}
+	return nil
+}
+
+// ignoreCriticalExtensions returns a copy of cert that no longer
+// reports as unhandled the critical extensions allowed by opts.
+// cert is returned as it is if there is nothing to ignore.
+func (opts *ValidationOpts) ignoreCriticalExtensions(cert *x509.Certificate) *x509.Certificate {
+	if len(cert.UnhandledCriticalExtensions) == 0 || len(opts.AllowedCriticalExtensions) == 0 {
+		return cert
+	}
+
+	unhandled := make([]asn1.ObjectIdentifier, 0, len(cert.UnhandledCriticalExtensions))
+	for _, oid := range cert.UnhandledCriticalExtensions {
+		allowed := false
+		for _, allowedOID := range opts.AllowedCriticalExtensions {
+			if oid.Equal(allowedOID) {
+				allowed = true
+				break
 			}
 		}
-
-		return nil
-	default:
-		return fmt.Errorf("Identity type not recognized")
+		if allowed {
+			mspLogger.Debugf("Ignoring unknown critical extension %s", oid)
+			continue
+		}
+		unhandled = append(unhandled, oid)
 	}
+
+	stripped := *cert
+	stripped.UnhandledCriticalExtensions = unhandled
+	return &stripped
 }
 
 // DeserializeIdentity returns an Identity given the byte-level
@@ -516,6 +578,12 @@ func (msp *bccspmsp) deserializeIdentityInternal(serializedIdentity []byte) (Ide
 
 // SatisfiesPrincipal returns null if the identity matches the principal or an error otherwise
 func (msp *bccspmsp) SatisfiesPrincipal(id Identity, principal *common.MSPPrincipal) error {
+	err := msp.satisfiesPrincipal(id, principal)
+	msp.audit(id, AuditSatisfiesPrincipal, principal, err)
+	return err
+}
+
+func (msp *bccspmsp) satisfiesPrincipal(id Identity, principal *common.MSPPrincipal) error {
 	switch principal.PrincipalClassification {
 	// in this case, we have to check whether the
 	// identity has a role in the msp - member or admin
@@ -538,7 +606,7 @@ func (msp *bccspmsp) SatisfiesPrincipal(id Identity, principal *common.MSPPrinci
 		case common.MSPRole_MEMBER:
 			// in the case of member, we simply check
 			// whether this identity is valid for the MSP
-			return msp.Validate(id)
+			return msp.validate(id)
 		case common.MSPRole_ADMIN:
 			// in the case of admin, we check that the
 			// id is exactly one of our admins
@@ -559,7 +627,28 @@ func (msp *bccspmsp) SatisfiesPrincipal(id Identity, principal *common.MSPPrinci
 				}
 			}
 
+			// with NodeOUs enabled, admins can also be
+			// identified by their organizational unit
+			if id, ok := id.(*identity); ok && msp.nodeOUs != nil && msp.nodeOUs.admin != nil {
+				err := msp.validate(id)
+				if err != nil {
+					return err
+				}
+
+				return msp.satisfiesNodeOURole(id, common.MSPRole_ADMIN)
+			}
+
 			return errors.New("This identity is not an admin")
+		case common.MSPRole_CLIENT, common.MSPRole_PEER, common.MSPRole_ORDERER:
+			// in the case of the roles identified by
+			// organizational unit, we check whether this
+			// identity is valid and has the unit of the role
+			err := msp.validate(id)
+			if err != nil {
+				return err
+			}
+
+			return msp.satisfiesNodeOURole(id.(*identity), mspRole.Role)
 		default:
 			return fmt.Errorf("Invalid MSP role type %d", int32(mspRole.Role))
 		}
@@ -593,20 +682,70 @@ func (msp *bccspmsp) SatisfiesPrincipal(id Identity, principal *common.MSPPrinci
 
 		// we then check if the identity is valid with this MSP
 		// and fail if it is not
-		err = msp.Validate(id)
+		err = msp.validate(id)
 		if err != nil {
 			return err
 		}
 
-		// now we check whether any of this identity's OUs match the requested one
+		// now we check whether any of this identity's OUs match the requested
+		// one; if the principal names the certifiers of the OU, the OU must
+		// also be certified by the same chain of trust
 		for _, ou := range id.GetOrganizationalUnits() {
-			if ou == OU.OrganizationalUnitIdentifier {
+			if ou.OrganizationalUnitIdentifier != OU.OrganizationalUnitIdentifier {
+				continue
+			}
+			if len(OU.CertifiersIdentifier) == 0 || bytes.Equal(ou.CertifiersIdentifier, OU.CertifiersIdentifier) {
 				return nil
 			}
 		}
 
 		// if we are here, no match was found, return an error
 		return errors.New("The identities do not match")
+	case common.MSPPrincipal_ATTRIBUTE:
+		// Principal contains the MSPAttribute
+		attr := &common.MSPAttribute{}
+		err := proto.Unmarshal(principal.Principal, attr)
+		if err != nil {
+			return fmt.Errorf("Could not unmarshal MSPAttribute from principal, err %s", err)
+		}
+
+		// at first, we check whether the MSP
+		// identifier is the same as that of the identity
+		if attr.MspIdentifier != msp.name {
+			return fmt.Errorf("The identity is a member of a different MSP (expected %s, got %s)", attr.MspIdentifier, id.GetMSPIdentifier())
+		}
+
+		// we then check if the identity is valid with this MSP
+		// and fail if it is not
+		err = msp.validate(id)
+		if err != nil {
+			return err
+		}
+
+		// now we check whether the identity holds the attribute
+		return msp.satisfiesAttribute(id.(*identity), attr)
+	case common.MSPPrincipal_ANONYMITY:
+		// Principal contains the MSPIdentityAnonymity
+		anonymity := &common.MSPIdentityAnonymity{}
+		err := proto.Unmarshal(principal.Principal, anonymity)
+		if err != nil {
+			return fmt.Errorf("Could not unmarshal MSPIdentityAnonymity from principal, err %s", err)
+		}
+
+		switch anonymity.AnonymityType {
+		case common.MSPIdentityAnonymity_ANONYMOUS:
+			if !id.Anonymous() {
+				return errors.New("Principal is anonymous, but the identity is not")
+			}
+			return nil
+		case common.MSPIdentityAnonymity_NOMINAL:
+			if id.Anonymous() {
+				return errors.New("Principal is nominal, but the identity is anonymous")
+			}
+			return nil
+		default:
+			return fmt.Errorf("Invalid MSP anonymity type %d", int32(anonymity.AnonymityType))
+		}
 	default:
 		return fmt.Errorf("Invalid principal type %d", int32(principal.PrincipalClassification))
 	}
@@ -102,6 +102,7 @@ func TestMSPWithIntermediateCAs(t *testing.T) {
 
 	thisMSP, err := NewBccspMsp()
 	assert.NoError(t, err)
+	SetClock(thisMSP, fixtureTime)
 
 	err = thisMSP.Setup(mspconf)
 	assert.NoError(t, err)
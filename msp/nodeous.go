@@ -0,0 +1,182 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package msp
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric/protos/common"
+	m "github.com/hyperledger/fabric/protos/msp"
+)
+
+// nodeOU is the organizational unit
+// identifying the identities of a role
+type nodeOU struct {
+	ou string
+
+	// certifier, if not nil, is the CA that must
+	// be in the chain of the identities of the role
+	certifier *x509.Certificate
+}
+
+// nodeOUs classifies the identities of an MSP
+// into roles given their organizational units;
+// roles whose organizational unit is nil are
+// not assigned to any identity
+type nodeOUs struct {
+	client  *nodeOU
+	peer    *nodeOU
+	admin   *nodeOU
+	orderer *nodeOU
+}
+
+// setupNodeOUs sets up the classification of identities
+// by organizational unit, if enabled by conf
+func (msp *bccspmsp) setupNodeOUs(conf *m.FabricNodeOUs) error {
+	msp.nodeOUs = nil
+	if conf == nil || !conf.Enable {
+		return nil
+	}
+
+	ous := &nodeOUs{}
+	for _, role := range []struct {
+		ou   **nodeOU
+		conf *m.FabricOUIdentifier
+	}{
+		{&ous.client, conf.ClientOuIdentifier},
+		{&ous.peer, conf.PeerOuIdentifier},
+		{&ous.admin, conf.AdminOuIdentifier},
+		{&ous.orderer, conf.OrdererOuIdentifier},
+	} {
+		if role.conf == nil {
+			continue
+		}
+		if role.conf.OrganizationalUnitIdentifier == "" {
+			return errors.New("Invalid NodeOUs: empty organizational unit identifier")
+		}
+
+		ou := &nodeOU{ou: role.conf.OrganizationalUnitIdentifier}
+		if len(role.conf.CertifiersIdentifier) != 0 {
+			certifier, err := msp.getCACertFromConf(role.conf.CertifiersIdentifier)
+			if err != nil {
+				return fmt.Errorf("Invalid NodeOUs certifier for organizational unit %s, err %s", ou.ou, err)
+			}
+			ou.certifier = certifier
+		}
+		*role.ou = ou
+	}
+
+	msp.nodeOUs = ous
+	return nil
+}
+
+// getCACertFromConf returns the root or intermediate CA
+// certificate of this MSP whose PEM encoding is certBytes
+func (msp *bccspmsp) getCACertFromConf(certBytes []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(certBytes)
+	if block == nil {
+		return nil, errors.New("could not decode pem bytes")
+	}
+
+	for _, ca := range append(append([]Identity{}, msp.rootCerts...), msp.intermediateCerts...) {
+		if caCert := ca.(*identity).cert; bytes.Equal(caCert.Raw, block.Bytes) {
+			return caCert, nil
+		}
+	}
+
+	return nil, errors.New("certificate is not a CA of this MSP")
+}
+
+// hasNodeOU returns whether id belongs to the role identified by ou
+func (msp *bccspmsp) hasNodeOU(id *identity, ou *nodeOU) bool {
+	if ou == nil {
+		return false
+	}
+
+	found := false
+	for _, unit := range id.cert.Subject.OrganizationalUnit {
+		if unit == ou.ou {
+			found = true
+			break
+		}
+	}
+	if !found || ou.certifier == nil {
+		return found
+	}
+
+	chain, err := msp.getCAChain(id.cert)
+	if err != nil {
+		return false
+	}
+	for _, ca := range chain[1:] {
+		if bytes.Equal(ca.Raw, ou.certifier.Raw) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateNodeOUs checks that id belongs to exactly one of the
+// roles of this MSP, when identities are classified into roles
+func (msp *bccspmsp) validateNodeOUs(id *identity) error {
+	if msp.nodeOUs == nil {
+		return nil
+	}
+
+	roles := 0
+	for _, ou := range []*nodeOU{msp.nodeOUs.client, msp.nodeOUs.peer, msp.nodeOUs.admin, msp.nodeOUs.orderer} {
+		if msp.hasNodeOU(id, ou) {
+			roles++
+		}
+	}
+
+	if roles != 1 {
+		return fmt.Errorf("The identity must belong to exactly one of the node organizational units of this MSP, it belongs to %d", roles)
+	}
+	return nil
+}
+
+// satisfiesNodeOURole returns nil if id, a valid member
+// of this MSP, belongs to role given its organizational units
+func (msp *bccspmsp) satisfiesNodeOURole(id *identity, role common.MSPRole_MSPRoleType) error {
+	name := strings.ToLower(role.String())
+	if msp.nodeOUs == nil {
+		return fmt.Errorf("NodeOUs are not enabled, cannot tell whether the identity is a %s", name)
+	}
+
+	var ou *nodeOU
+	switch role {
+	case common.MSPRole_CLIENT:
+		ou = msp.nodeOUs.client
+	case common.MSPRole_PEER:
+		ou = msp.nodeOUs.peer
+	case common.MSPRole_ADMIN:
+		ou = msp.nodeOUs.admin
+	case common.MSPRole_ORDERER:
+		ou = msp.nodeOUs.orderer
+	}
+
+	if !msp.hasNodeOU(id, ou) {
+		return fmt.Errorf("The identity is not a %s", name)
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package msp
+
+import (
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/stretchr/testify/assert"
+)
+
+const nodeOUsConfig = `
+NodeOUs:
+  Enable: true
+  ClientOUIdentifier:
+    OrganizationalUnitIdentifier: client
+  PeerOUIdentifier:
+    Certificate: intermediatecerts/ica.pem
+    OrganizationalUnitIdentifier: peer
+  AdminOUIdentifier:
+    Certificate: cacerts/root.pem
+    OrganizationalUnitIdentifier: admin
+  OrdererOUIdentifier:
+    OrganizationalUnitIdentifier: orderer
+`
+
+// writeNodeOUsMSPDir writes an MSP directory without
+// admincerts, with config as its config.yaml if not empty
+func writeNodeOUsMSPDir(t *testing.T, root, ica, signer *testCA, config string) string {
+	dir, err := ioutil.TempDir("", "nodeous")
+	assert.NoError(t, err)
+
+	for name, cert := range map[string]*testCA{
+		"cacerts/root.pem":          root,
+		"intermediatecerts/ica.pem": ica,
+		"signcerts/peer.pem":        signer,
+	} {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, ioutil.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.cert.Raw}), 0644))
+	}
+	if config != "" {
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, configfilename), []byte(config), 0644))
+	}
+
+	return dir
+}
+
+func rolePrincipal(t *testing.T, mspID string, role common.MSPRole_MSPRoleType) *common.MSPPrincipal {
+	raw, err := proto.Marshal(&common.MSPRole{MspIdentifier: mspID, Role: role})
+	assert.NoError(t, err)
+	return &common.MSPPrincipal{PrincipalClassification: common.MSPPrincipal_ROLE, Principal: raw}
+}
+
+func TestNodeOUs(t *testing.T) {
+	root := issueTestCert(t, "root", nil, pathLen(-1))
+	ica := issueTestCert(t, "ica", root, pathLen(-1))
+	peer := issueTestCert(t, "peer0", ica, nil, "peer")
+
+	dir := writeNodeOUsMSPDir(t, root, ica, peer, nodeOUsConfig)
+	defer os.RemoveAll(dir)
+
+	conf, err := GetVerifyingMspConfig(dir, nil, "NODEOUS")
+	assert.NoError(t, err)
+	thisMSP, err := NewBccspMsp()
+	assert.NoError(t, err)
+	assert.NoError(t, thisMSP.Setup(conf))
+
+	identity := func(ca *testCA) Identity {
+		id, err := thisMSP.DeserializeIdentity(serializeTestCert(t, "NODEOUS", ca.cert))
+		assert.NoError(t, err)
+		return id
+	}
+
+	roles := []common.MSPRole_MSPRoleType{common.MSPRole_CLIENT, common.MSPRole_PEER, common.MSPRole_ADMIN, common.MSPRole_ORDERER}
+	for _, test := range []struct {
+		id   Identity
+		role common.MSPRole_MSPRoleType
+	}{
+		{identity(issueTestCert(t, "user1", root, nil, "client")), common.MSPRole_CLIENT},
+		{identity(peer), common.MSPRole_PEER},
+		{identity(issueTestCert(t, "admin", ica, nil, "admin")), common.MSPRole_ADMIN},
+		{identity(issueTestCert(t, "orderer0", root, nil, "orderer")), common.MSPRole_ORDERER},
+	} {
+		assert.NoError(t, test.id.Validate())
+		assert.NoError(t, thisMSP.SatisfiesPrincipal(test.id, rolePrincipal(t, "NODEOUS", common.MSPRole_MEMBER)))
+		for _, role := range roles {
+			err := thisMSP.SatisfiesPrincipal(test.id, rolePrincipal(t, "NODEOUS", role))
+			if role == test.role {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		}
+	}
+
+	// identities that do not belong to exactly one role are not valid
+	assert.Error(t, identity(issueTestCert(t, "nobody", root, nil, "sales")).Validate())
+	assert.Error(t, identity(issueTestCert(t, "both", root, nil, "client", "orderer")).Validate())
+	// nor are peers not issued by the certifier of their organizational unit
+	assert.Error(t, identity(issueTestCert(t, "peer1", root, nil, "peer")).Validate())
+}
+
+func TestNodeOUsConfig(t *testing.T) {
+	root := issueTestCert(t, "root", nil, pathLen(-1))
+	ica := issueTestCert(t, "ica", root, pathLen(-1))
+	peer := issueTestCert(t, "peer0", ica, nil, "peer")
+
+	// admincerts is required without NodeOUs
+	dir := writeNodeOUsMSPDir(t, root, ica, peer, "")
+	defer os.RemoveAll(dir)
+	_, err := GetVerifyingMspConfig(dir, nil, "NODEOUS")
+	assert.Error(t, err)
+
+	// malformed configuration
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, configfilename), []byte("NodeOUs: ["), 0644))
+	_, err = GetVerifyingMspConfig(dir, nil, "NODEOUS")
+	assert.Error(t, err)
+
+	// certifiers that are not CAs of the MSP
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, configfilename), []byte(`
+NodeOUs:
+  Enable: true
+  PeerOUIdentifier:
+    Certificate: signcerts/peer.pem
+    OrganizationalUnitIdentifier: peer
+`), 0644))
+	conf, err := GetVerifyingMspConfig(dir, nil, "NODEOUS")
+	assert.NoError(t, err)
+	thisMSP, err := NewBccspMsp()
+	assert.NoError(t, err)
+	assert.Error(t, thisMSP.Setup(conf))
+
+	// roles identified by organizational unit require NodeOUs
+	thisMSP, err = setupCAChainMSP(root, ica)
+	assert.NoError(t, err)
+	id, err := thisMSP.DeserializeIdentity(serializeTestCert(t, "CACHAIN", peer.cert))
+	assert.NoError(t, err)
+	assert.NoError(t, id.Validate())
+	assert.Error(t, thisMSP.SatisfiesPrincipal(id, rolePrincipal(t, "CACHAIN", common.MSPRole_PEER)))
+}
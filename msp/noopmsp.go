@@ -92,8 +92,12 @@ func (id *noopidentity) Validate() error {
 	return nil
 }
 
-func (id *noopidentity) GetOrganizationalUnits() []string {
-	return []string{"dunno"}
+func (id *noopidentity) GetOrganizationalUnits() []*OUIdentifier {
+	return []*OUIdentifier{{OrganizationalUnitIdentifier: "dunno", CertifiersIdentifier: []byte("dunno")}}
+}
+
+func (id *noopidentity) Anonymous() bool {
+	return false
 }
 
 func (id *noopidentity) Verify(msg []byte, sig []byte) error {
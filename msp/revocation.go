@@ -0,0 +1,264 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package msp
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+)
+
+var (
+	// oidCRLNumber is the object identifier of the CRL number extension
+	oidCRLNumber = asn1.ObjectIdentifier{2, 5, 29, 20}
+	// oidDeltaCRLIndicator is the object identifier of the extension
+	// marking delta CRLs, carrying the number of their base CRL
+	oidDeltaCRLIndicator = asn1.ObjectIdentifier{2, 5, 29, 27}
+	// oidCRLReason is the object identifier of the reason code
+	// extension of revoked certificate entries
+	oidCRLReason = asn1.ObjectIdentifier{2, 5, 29, 21}
+)
+
+// crlReasonRemoveFromCRL is the reason code of the entries of delta
+// CRLs that lift the revocation of a certificate that was on hold
+const crlReasonRemoveFromCRL = 8
+
+// maxCachedCRLs is the number of parsed CRLs kept across MSP setups
+const maxCachedCRLs = 256
+
+// parsedCRL is a CRL reduced to what revocation checks need
+type parsedCRL struct {
+	// tbs is the CRL without its revoked certificates,
+	// kept to check the signature of the CRL
+	tbs *pkix.CertificateList
+	aki []byte
+	// number is the CRL number, or nil if there is none
+	number *big.Int
+	// baseNumber is the number of the base CRL of a delta CRL,
+	// or nil if the CRL is a complete one
+	baseNumber *big.Int
+	// revoked and removed are the serial numbers the CRL
+	// revokes and, for a delta CRL, reinstates
+	revoked map[string]struct{}
+	removed map[string]struct{}
+}
+
+// crlCache keeps the CRLs parsed by previous MSP setups, as channel
+// config updates set MSPs up again with the same CRLs most of the time
+var crlCache = struct {
+	sync.Mutex
+	crls map[[sha256.Size]byte]*parsedCRL
+}{crls: make(map[[sha256.Size]byte]*parsedCRL)}
+
+// parseCRL parses crlBytes, or returns the cached result of a previous parsing
+func parseCRL(crlBytes []byte) (*parsedCRL, error) {
+	key := sha256.Sum256(crlBytes)
+
+	crlCache.Lock()
+	parsed, ok := crlCache.crls[key]
+	crlCache.Unlock()
+	if ok {
+		return parsed, nil
+	}
+
+	crl, err := x509.ParseCRL(crlBytes)
+	if err != nil {
+		return nil, fmt.Errorf("Could not parse RevocationList, err %s", err)
+	}
+
+	parsed = &parsedCRL{
+		revoked: make(map[string]struct{}, len(crl.TBSCertList.RevokedCertificates)),
+		removed: make(map[string]struct{}),
+	}
+	if parsed.aki, err = getAuthorityKeyIdentifierFromCrl(crl); err != nil {
+		return nil, fmt.Errorf("Could not obtain Authority Key Identifier for crl, err %s", err)
+	}
+	for _, ext := range crl.TBSCertList.Extensions {
+		switch {
+		case ext.Id.Equal(oidCRLNumber):
+			parsed.number = new(big.Int)
+			if _, err := asn1.Unmarshal(ext.Value, &parsed.number); err != nil {
+				return nil, fmt.Errorf("Failed to unmarshal CRL number, err %s", err)
+			}
+		case ext.Id.Equal(oidDeltaCRLIndicator):
+			parsed.baseNumber = new(big.Int)
+			if _, err := asn1.Unmarshal(ext.Value, &parsed.baseNumber); err != nil {
+				return nil, fmt.Errorf("Failed to unmarshal delta CRL indicator, err %s", err)
+			}
+		}
+	}
+
+	for _, rc := range crl.TBSCertList.RevokedCertificates {
+		if parsed.baseNumber != nil && isRemoveFromCRL(rc) {
+			parsed.removed[serialKey(rc.SerialNumber)] = struct{}{}
+			continue
+		}
+		parsed.revoked[serialKey(rc.SerialNumber)] = struct{}{}
+	}
+
+	// only the signed part without the entries is kept
+	tbs := *crl
+	tbs.TBSCertList.RevokedCertificates = nil
+	parsed.tbs = &tbs
+
+	crlCache.Lock()
+	defer crlCache.Unlock()
+	if len(crlCache.crls) >= maxCachedCRLs {
+		for k := range crlCache.crls {
+			delete(crlCache.crls, k)
+			break
+		}
+	}
+	crlCache.crls[key] = parsed
+
+	return parsed, nil
+}
+
+// isRemoveFromCRL returns whether rc reinstates a certificate
+func isRemoveFromCRL(rc pkix.RevokedCertificate) bool {
+	for _, ext := range rc.Extensions {
+		if !ext.Id.Equal(oidCRLReason) {
+			continue
+		}
+		var reason asn1.Enumerated
+		if _, err := asn1.Unmarshal(ext.Value, &reason); err == nil && reason == crlReasonRemoveFromCRL {
+			return true
+		}
+	}
+	return false
+}
+
+func serialKey(serial *big.Int) string {
+	return serial.Text(62)
+}
+
+// revocationStore indexes the serial numbers of the certificates
+// revoked by each CA of an MSP, by subject key identifier of the CA,
+// so that checking whether a certificate is revoked does not depend
+// on the number of revocations
+type revocationStore struct {
+	revoked map[string]map[string]struct{}
+}
+
+// isRevoked returns whether the certificate with serial number
+// serial, issued by the CA with subject key identifier caSKI, is revoked
+func (s *revocationStore) isRevoked(caSKI []byte, serial *big.Int) bool {
+	if s == nil {
+		return false
+	}
+	_, revoked := s.revoked[string(caSKI)][serialKey(serial)]
+	return revoked
+}
+
+// newRevocationStore indexes the revocations of the complete and delta
+// CRLs crls. CRLs not signed by a CA of this MSP are ignored. The
+// revocations of all the complete CRLs of a CA are retained, and delta
+// CRLs are applied on top of them in order of CRL number; a delta
+// reinstates the certificates it removes from the CRL only if a complete
+// CRL at least as recent as its base CRL has been supplied.
+func (msp *bccspmsp) newRevocationStore(crls [][]byte) (*revocationStore, error) {
+	var complete, deltas []*parsedCRL
+	signers := make(map[*parsedCRL]string)
+	for _, crlBytes := range crls {
+		crl, err := parseCRL(crlBytes)
+		if err != nil {
+			return nil, err
+		}
+
+		ski, ok := msp.getCRLSigner(crl)
+		if !ok {
+			mspLogger.Warningf("Ignoring CRL not signed by any CA of MSP %s", msp.name)
+			continue
+		}
+		signers[crl] = ski
+
+		if crl.baseNumber == nil {
+			complete = append(complete, crl)
+		} else {
+			deltas = append(deltas, crl)
+		}
+	}
+
+	store := &revocationStore{revoked: make(map[string]map[string]struct{})}
+	latest := make(map[string]*big.Int)
+	for _, crl := range complete {
+		ski := signers[crl]
+		revoked, ok := store.revoked[ski]
+		if !ok {
+			revoked = make(map[string]struct{}, len(crl.revoked))
+			store.revoked[ski] = revoked
+		}
+		for serial := range crl.revoked {
+			revoked[serial] = struct{}{}
+		}
+		if crl.number != nil && (latest[ski] == nil || crl.number.Cmp(latest[ski]) > 0) {
+			latest[ski] = crl.number
+		}
+	}
+
+	sort.SliceStable(deltas, func(i, j int) bool {
+		if deltas[i].number == nil || deltas[j].number == nil {
+			return deltas[j].number != nil
+		}
+		return deltas[i].number.Cmp(deltas[j].number) < 0
+	})
+	for _, crl := range deltas {
+		ski := signers[crl]
+		revoked, ok := store.revoked[ski]
+		if !ok {
+			revoked = make(map[string]struct{}, len(crl.revoked))
+			store.revoked[ski] = revoked
+		}
+		for serial := range crl.revoked {
+			revoked[serial] = struct{}{}
+		}
+
+		if latest[ski] == nil || latest[ski].Cmp(crl.baseNumber) < 0 {
+			mspLogger.Warningf("Base CRL %s of delta CRL of MSP %s not found, not reinstating certificates", crl.baseNumber, msp.name)
+			continue
+		}
+		for serial := range crl.removed {
+			delete(revoked, serial)
+		}
+	}
+
+	return store, nil
+}
+
+// getCRLSigner returns the subject key identifier of the
+// CA of this MSP that signed crl, if any
+func (msp *bccspmsp) getCRLSigner(crl *parsedCRL) (string, bool) {
+	for _, ca := range append(append([]Identity{}, msp.rootCerts...), msp.intermediateCerts...) {
+		caCert := ca.(*identity).cert
+		ski, err := getSubjectKeyIdentifierFromCert(caCert)
+		if err != nil || !bytes.Equal(ski, crl.aki) {
+			continue
+		}
+		if err := caCert.CheckCRLSignature(crl.tbs); err != nil {
+			mspLogger.Warningf("Invalid signature over the identified CRL, error %s", err)
+			continue
+		}
+		return string(ski), true
+	}
+	return "", false
+}
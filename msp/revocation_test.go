@@ -17,7 +17,14 @@ limitations under the License.
 package msp
 
 import (
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"math/big"
 	"testing"
+	"time"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/protos/msp"
@@ -106,3 +113,101 @@ func TestRevocation(t *testing.T) {
 	err = id.Validate()
 	assert.Error(t, err)
 }
+
+// newTestCRL returns a CRL numbered number issued by ca, revoking the
+// entries, and marked as a delta of the CRL numbered base if not nil
+func newTestCRL(t *testing.T, ca *testCA, number, base int64, entries ...x509.RevocationListEntry) []byte {
+	template := &x509.RevocationList{
+		Number:                    big.NewInt(number),
+		ThisUpdate:                time.Now().Add(-time.Hour),
+		NextUpdate:                time.Now().Add(time.Hour),
+		RevokedCertificateEntries: entries,
+	}
+	if base > 0 {
+		value, err := asn1.Marshal(big.NewInt(base))
+		assert.NoError(t, err)
+		template.ExtraExtensions = []pkix.Extension{{Id: oidDeltaCRLIndicator, Critical: true, Value: value}}
+	}
+	der, err := x509.CreateRevocationList(rand.Reader, template, ca.cert, ca.key)
+	assert.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: der})
+}
+
+func revokedEntry(cert *x509.Certificate, reasonCode int) x509.RevocationListEntry {
+	return x509.RevocationListEntry{SerialNumber: cert.SerialNumber, RevocationTime: time.Now(), ReasonCode: reasonCode}
+}
+
+func setupCRLMSP(t *testing.T, root *testCA, crls ...[]byte) MSP {
+	conf, err := proto.Marshal(&msp.FabricMSPConfig{
+		Name:           "CRLMSP",
+		RootCerts:      [][]byte{pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: root.cert.Raw})},
+		RevocationList: crls,
+	})
+	assert.NoError(t, err)
+
+	thisMSP, err := NewBccspMsp()
+	assert.NoError(t, err)
+	assert.NoError(t, thisMSP.Setup(&msp.MSPConfig{Config: conf, Type: int32(FABRIC)}))
+	return thisMSP
+}
+
+func validateTestCert(t *testing.T, thisMSP MSP, cert *x509.Certificate) error {
+	id, err := thisMSP.DeserializeIdentity(serializeTestCert(t, "CRLMSP", cert))
+	assert.NoError(t, err)
+	return id.Validate()
+}
+
+func TestDeltaCRLs(t *testing.T) {
+	root := issueTestCert(t, "root", nil, pathLen(-1))
+	a := issueTestCert(t, "a", root, nil)
+	b := issueTestCert(t, "b", root, nil)
+	c := issueTestCert(t, "c", root, nil)
+	d := issueTestCert(t, "d", root, nil)
+
+	full := newTestCRL(t, root, 1, 0, revokedEntry(a.cert, 1), revokedEntry(b.cert, 6))
+	delta := newTestCRL(t, root, 2, 1, revokedEntry(b.cert, crlReasonRemoveFromCRL), revokedEntry(c.cert, 1))
+
+	// The complete CRL alone
+	thisMSP := setupCRLMSP(t, root, full)
+	assert.Error(t, validateTestCert(t, thisMSP, a.cert))
+	assert.Error(t, validateTestCert(t, thisMSP, b.cert))
+	assert.NoError(t, validateTestCert(t, thisMSP, c.cert))
+
+	// The delta reinstates b and revokes c, whatever the order of the CRLs
+	thisMSP = setupCRLMSP(t, root, delta, full)
+	assert.Error(t, validateTestCert(t, thisMSP, a.cert))
+	assert.NoError(t, validateTestCert(t, thisMSP, b.cert))
+	assert.Error(t, validateTestCert(t, thisMSP, c.cert))
+	assert.NoError(t, validateTestCert(t, thisMSP, d.cert))
+
+	// A later delta revokes b again
+	thisMSP = setupCRLMSP(t, root, full, newTestCRL(t, root, 3, 1, revokedEntry(b.cert, 6)), delta)
+	assert.Error(t, validateTestCert(t, thisMSP, b.cert))
+
+	// Without its base CRL, a delta revokes but does not reinstate
+	thisMSP = setupCRLMSP(t, root, newTestCRL(t, root, 1, 0, revokedEntry(b.cert, 6)), newTestCRL(t, root, 3, 2, revokedEntry(b.cert, crlReasonRemoveFromCRL), revokedEntry(c.cert, 1)))
+	assert.Error(t, validateTestCert(t, thisMSP, b.cert))
+	assert.Error(t, validateTestCert(t, thisMSP, c.cert))
+
+	// CRLs of other CAs are ignored
+	other := issueTestCert(t, "other", nil, pathLen(-1))
+	thisMSP = setupCRLMSP(t, root, newTestCRL(t, other, 1, 0, revokedEntry(a.cert, 1)))
+	assert.NoError(t, validateTestCert(t, thisMSP, a.cert))
+}
+
+func TestCRLCache(t *testing.T) {
+	root := issueTestCert(t, "root", nil, pathLen(-1))
+	leaf := issueTestCert(t, "leaf", root, nil)
+	crl := newTestCRL(t, root, 1, 0, revokedEntry(leaf.cert, 1))
+
+	parsed, err := parseCRL(crl)
+	assert.NoError(t, err)
+	cached, err := parseCRL(crl)
+	assert.NoError(t, err)
+	assert.True(t, parsed == cached)
+	assert.Nil(t, parsed.tbs.TBSCertList.RevokedCertificates)
+	assert.Len(t, parsed.revoked, 1)
+
+	_, err = parseCRL([]byte("not a CRL"))
+	assert.Error(t, err)
+}
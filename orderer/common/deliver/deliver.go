@@ -57,7 +57,8 @@ type Support interface {
 }
 
 type deliverServer struct {
-	sm SupportManager
+	sm      SupportManager
+	limiter *policies.RateLimiter
 }
 
 // NewHandlerImpl creates an implementation of the Handler interface
@@ -67,6 +68,32 @@ func NewHandlerImpl(sm SupportManager) Handler {
 	}
 }
 
+// NewRateLimitedHandlerImpl creates an implementation of the Handler interface which
+// rejects the deliver requests of clients which exhausted their limit in limiter
+func NewRateLimitedHandlerImpl(sm SupportManager, limiter *policies.RateLimiter) Handler {
+	return &deliverServer{
+		sm:      sm,
+		limiter: limiter,
+	}
+}
+
+// policyManager allows embedding a policies.Manager without
+// the field hiding the Manager method of the interface
+type policyManager interface {
+	policies.Manager
+}
+
+// rateLimitedManager limits the policies of a policy manager with a rate limiter
+type rateLimitedManager struct {
+	policyManager
+	limiter *policies.RateLimiter
+}
+
+func (rlm *rateLimitedManager) GetPolicy(id string) (policies.Policy, bool) {
+	policy, ok := rlm.policyManager.GetPolicy(id)
+	return policies.NewRateLimitedPolicy(policy, rlm.limiter), ok
+}
+
 func (ds *deliverServer) Handle(srv ab.AtomicBroadcast_DeliverServer) error {
 	logger.Debugf("Starting new deliver loop")
 	for {
@@ -99,7 +126,12 @@ func (ds *deliverServer) Handle(srv ab.AtomicBroadcast_DeliverServer) error {
 			return sendStatusReply(srv, cb.Status_NOT_FOUND)
 		}
 
-		sf := sigfilter.New(chain.SharedConfig().EgressPolicyNames, chain.PolicyManager())
+		policyManager := chain.PolicyManager()
+		if ds.limiter != nil {
+			policyManager = &rateLimitedManager{policyManager: policyManager, limiter: ds.limiter}
+		}
+
+		sf := sigfilter.New(chain.SharedConfig().EgressPolicyNames, policyManager)
 		result, _ := sf.Apply(envelope)
 		if result != filter.Forward {
 			return sendStatusReply(srv, cb.Status_FORBIDDEN)
@@ -384,3 +384,29 @@ func TestBlockingSeek(t *testing.T) {
 		t.Fatalf("Timed out waiting to get all blocks")
 	}
 }
+
+func TestRateLimitedSeek(t *testing.T) {
+	mm := newMockMultichainManager()
+
+	m := newMockD()
+	defer close(m.recvChan)
+	ds := NewRateLimitedHandlerImpl(mm, policies.NewRateLimiter(1, time.Minute))
+
+	go ds.Handle(m)
+
+	for _, expected := range []cb.Status{cb.Status_SUCCESS, cb.Status_FORBIDDEN} {
+		m.recvChan <- makeSeek(systemChainID, &ab.SeekInfo{Start: seekOldest, Stop: seekOldest, Behavior: ab.SeekInfo_BLOCK_UNTIL_READY})
+
+		select {
+		case deliverReply := <-m.sendChan:
+			if deliverReply.GetBlock() != nil {
+				deliverReply = <-m.sendChan
+			}
+			if deliverReply.GetStatus() != expected {
+				t.Fatalf("Expected status %v but got %v", expected, deliverReply.GetStatus())
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Timed out waiting for the reply")
+		}
+	}
+}
@@ -55,6 +55,20 @@ type General struct {
 	LocalMSPDir    string
 	LocalMSPID     string
 	BCCSP          *bccsp.FactoryOpts
+	PolicyPlugins  []PolicyPlugin
+	DeliverLimit   DeliverLimit
+}
+
+// DeliverLimit contains config for limiting the rate of deliver requests of each client
+type DeliverLimit struct {
+	Requests int
+	Interval time.Duration
+}
+
+// PolicyPlugin contains config for a Go plugin providing a policy type
+type PolicyPlugin struct {
+	Type int32
+	Path string
 }
 
 //TLS contains config used to configure TLS
@@ -159,6 +173,9 @@ var defaults = TopLevel{
 		LocalMSPDir: "../msp/sampleconfig/",
 		LocalMSPID:  "DEFAULT",
 		BCCSP:       &bccsp.DefaultOpts,
+		DeliverLimit: DeliverLimit{
+			Interval: time.Minute,
+		},
 	},
 	RAMLedger: RAMLedger{
 		HistorySize: 10000,
@@ -235,6 +252,9 @@ func (c *TopLevel) completeInitialization() {
 		case c.General.LocalMSPID == "":
 			logger.Infof("General.LocalMSPID unset, setting to %s", defaults.General.LocalMSPID)
 			c.General.LocalMSPID = defaults.General.LocalMSPID
+		case c.General.DeliverLimit.Requests > 0 && c.General.DeliverLimit.Interval == 0*time.Second:
+			logger.Infof("General.DeliverLimit.Interval unset, setting to %v", defaults.General.DeliverLimit.Interval)
+			c.General.DeliverLimit.Interval = defaults.General.DeliverLimit.Interval
 		case c.FileLedger.Prefix == "":
 			logger.Infof("FileLedger.Prefix unset, setting to %s", defaults.FileLedger.Prefix)
 			c.FileLedger.Prefix = defaults.FileLedger.Prefix
@@ -28,6 +28,7 @@ import (
 	genesisconfig "github.com/hyperledger/fabric/common/configtx/tool/localconfig"
 	"github.com/hyperledger/fabric/common/configtx/tool/provisional"
 	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/common/policies"
 	"github.com/hyperledger/fabric/core/comm"
 	"github.com/hyperledger/fabric/orderer/common/bootstrap/file"
 	"github.com/hyperledger/fabric/orderer/kafka"
@@ -90,6 +91,14 @@ func main() {
 		panic(fmt.Errorf("Failed initializing crypto [%s]", err))
 	}
 
+	// Load the policy provider plugins, before any chain is created
+	for _, policyPlugin := range conf.General.PolicyPlugins {
+		logger.Infof("Loading policy provider plugin %s for policy type %d", policyPlugin.Path, policyPlugin.Type)
+		if err = policies.LoadPlugin(policyPlugin.Type, policyPlugin.Path); err != nil {
+			panic(fmt.Errorf("Failed loading policy provider plugin [%s]", err))
+		}
+	}
+
 	var lf ordererledger.Factory
 	switch conf.General.LedgerType {
 	case "file":
@@ -155,9 +164,15 @@ func main() {
 
 	manager := multichain.NewManagerImpl(lf, consenters, signer)
 
+	var deliverLimiter *policies.RateLimiter
+	if conf.General.DeliverLimit.Requests > 0 {
+		deliverLimiter = policies.NewRateLimiter(conf.General.DeliverLimit.Requests, conf.General.DeliverLimit.Interval)
+	}
+
 	server := NewServer(
 		manager,
 		signer,
+		deliverLimiter,
 	)
 
 	ab.RegisterAtomicBroadcastServer(grpcServer.Server(), server)
@@ -18,6 +18,7 @@ package main
 
 import (
 	"github.com/hyperledger/fabric/common/crypto"
+	"github.com/hyperledger/fabric/common/policies"
 	"github.com/hyperledger/fabric/orderer/common/broadcast"
 	"github.com/hyperledger/fabric/orderer/common/deliver"
 	"github.com/hyperledger/fabric/orderer/configupdate"
@@ -55,12 +56,18 @@ type server struct {
 	dh deliver.Handler
 }
 
-// NewServer creates a ab.AtomicBroadcastServer based on the broadcast target and ledger Reader
-func NewServer(ml multichain.Manager, signer crypto.LocalSigner) ab.AtomicBroadcastServer {
+// NewServer creates a ab.AtomicBroadcastServer based on the broadcast target and ledger Reader.
+// If deliverLimiter is not nil, it limits the rate of the deliver requests of each client.
+func NewServer(ml multichain.Manager, signer crypto.LocalSigner, deliverLimiter *policies.RateLimiter) ab.AtomicBroadcastServer {
 	logger.Infof("Starting orderer")
 
+	dh := deliver.NewHandlerImpl(deliverSupport{Manager: ml})
+	if deliverLimiter != nil {
+		dh = deliver.NewRateLimitedHandlerImpl(deliverSupport{Manager: ml}, deliverLimiter)
+	}
+
 	s := &server{
-		dh: deliver.NewHandlerImpl(deliverSupport{Manager: ml}),
+		dh: dh,
 		bh: broadcast.NewHandlerImpl(broadcastSupport{
 			Manager:               ml,
 			ConfigUpdateProcessor: configupdate.New(ml.SystemChannelID(), configUpdateSupport{Manager: ml}, signer),
@@ -0,0 +1,149 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chaincode
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/cauthdsl"
+	"github.com/hyperledger/fabric/common/configtx"
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/peer/common"
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	explainTxFile      string
+	explainConfigBlock string
+)
+
+// explainPolicyCmd returns the cobra command for Chaincode ExplainPolicy
+func explainPolicyCmd(cf *ChaincodeCmdFactory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "explainpolicy",
+		Short: fmt.Sprintf("Explain why the endorsements of a transaction do or do not satisfy an endorsement policy."),
+		Long: fmt.Sprintf(`Explain why the endorsements of a transaction do or do not satisfy an endorsement policy.
+The transaction is read from the file of --txfile, and the MSPs of the channel from its configuration block in the file of --configblock.`),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return chaincodeExplainPolicy(cmd, args)
+		},
+	}
+	flags := cmd.Flags()
+	flags.StringVar(&explainTxFile, "txfile", common.UndefinedParamValue, "File containing the marshalled transaction envelope")
+	flags.StringVar(&explainConfigBlock, "configblock", common.UndefinedParamValue, "File containing the configuration block of the channel")
+
+	return cmd
+}
+
+// chaincodeExplainPolicy evaluates the endorsements of each action of the
+// transaction against the endorsement policy, as VSCC does, and prints the
+// trace of each evaluation
+func chaincodeExplainPolicy(cmd *cobra.Command, args []string) error {
+	if policy == common.UndefinedParamValue {
+		return fmt.Errorf("Must supply the endorsement policy")
+	}
+	if explainTxFile == common.UndefinedParamValue {
+		return fmt.Errorf("Must supply the transaction file")
+	}
+	if explainConfigBlock == common.UndefinedParamValue {
+		return fmt.Errorf("Must supply the configuration block file")
+	}
+
+	policyEnvelope, err := cauthdsl.FromString(policy)
+	if err != nil {
+		return fmt.Errorf("Invalid policy %s: %s", policy, err)
+	}
+
+	blockBytes, err := ioutil.ReadFile(explainConfigBlock)
+	if err != nil {
+		return fmt.Errorf("Error reading configuration block: %s", err)
+	}
+	block, err := utils.GetBlockFromBlockBytes(blockBytes)
+	if err != nil {
+		return fmt.Errorf("Error unmarshalling configuration block: %s", err)
+	}
+	configEnv, err := configtx.ConfigEnvelopeFromBlock(block)
+	if err != nil {
+		return fmt.Errorf("Error extracting configuration from block: %s", err)
+	}
+	configManager, err := configtx.NewManagerImpl(configEnv, configtx.NewInitializer(), nil)
+	if err != nil {
+		return fmt.Errorf("Error loading the channel configuration: %s", err)
+	}
+
+	endorsementPolicy, err := cauthdsl.NewPolicyProvider(configManager.MSPManager()).NewPolicy(utils.MarshalOrPanic(policyEnvelope))
+	if err != nil {
+		return fmt.Errorf("Error creating the endorsement policy: %s", err)
+	}
+
+	txBytes, err := ioutil.ReadFile(explainTxFile)
+	if err != nil {
+		return fmt.Errorf("Error reading transaction: %s", err)
+	}
+	env := &cb.Envelope{}
+	if err = proto.Unmarshal(txBytes, env); err != nil {
+		return fmt.Errorf("Error unmarshalling transaction: %s", err)
+	}
+	signatureSets, err := endorsementSignatureSets(env)
+	if err != nil {
+		return err
+	}
+
+	for i, signatureSet := range signatureSets {
+		trace, _ := policies.EvaluateWithTrace(endorsementPolicy, signatureSet)
+		fmt.Printf("Action %d:\n%s", i, trace)
+	}
+
+	return nil
+}
+
+// endorsementSignatureSets returns, for each action of the endorser
+// transaction of env, the signature set VSCC evaluates
+func endorsementSignatureSets(env *cb.Envelope) ([][]*cb.SignedData, error) {
+	payl, err := utils.GetPayload(env)
+	if err != nil {
+		return nil, fmt.Errorf("Error extracting transaction payload: %s", err)
+	}
+	tx, err := utils.GetTransaction(payl.Data)
+	if err != nil {
+		return nil, fmt.Errorf("Error extracting transaction: %s", err)
+	}
+
+	signatureSets := make([][]*cb.SignedData, len(tx.Actions))
+	for i, act := range tx.Actions {
+		cap, err := utils.GetChaincodeActionPayload(act.Payload)
+		if err != nil {
+			return nil, fmt.Errorf("Error extracting action %d: %s", i, err)
+		}
+
+		prespBytes := cap.Action.ProposalResponsePayload
+		signatureSets[i] = make([]*cb.SignedData, len(cap.Action.Endorsements))
+		for j, endorsement := range cap.Action.Endorsements {
+			signatureSets[i][j] = &cb.SignedData{
+				Data:      append(prespBytes, endorsement.Endorser...),
+				Identity:  endorsement.Endorser,
+				Signature: endorsement.Signature,
+			}
+		}
+	}
+
+	return signatureSets, nil
+}
@@ -0,0 +1,63 @@
+/*
+ Copyright IBM Corp. 2016-2017 All Rights Reserved.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package chaincode
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	cb "github.com/hyperledger/fabric/protos/common"
+)
+
+func TestExplainPolicyMissingArguments(t *testing.T) {
+	for _, args := range [][]string{
+		{"--txfile", "tx", "--configblock", "block"},
+		{"-P", "OR('Org1MSP.member')", "--configblock", "block"},
+		{"-P", "OR('Org1MSP.member')", "--txfile", "tx"},
+	} {
+		cmd := explainPolicyCmd(nil)
+		AddFlags(cmd)
+		cmd.SetArgs(args)
+		if err := cmd.Execute(); err == nil {
+			t.Fatalf("Expected error executing explainpolicy command with arguments %v", args)
+		}
+	}
+}
+
+func TestEndorsementSignatureSets(t *testing.T) {
+	if _, err := endorsementSignatureSets(&cb.Envelope{Payload: []byte("GARBAGE")}); err == nil {
+		t.Fatalf("Expected error extracting the signature sets of a malformed transaction")
+	}
+
+	txFile, err := ioutil.TempFile("", "explainpolicy")
+	if err != nil {
+		t.Fatalf("Could not create transaction file: %s", err)
+	}
+	defer os.Remove(txFile.Name())
+	txBytes, _ := proto.Marshal(&cb.Envelope{Payload: []byte("GARBAGE")})
+	txFile.Write(txBytes)
+	txFile.Close()
+
+	cmd := explainPolicyCmd(nil)
+	AddFlags(cmd)
+	cmd.SetArgs([]string{"-P", "OR('Org1MSP.member')", "--txfile", txFile.Name(), "--configblock", txFile.Name()})
+	if err := cmd.Execute(); err == nil {
+		t.Fatalf("Expected error executing explainpolicy command with a malformed configuration block")
+	}
+}
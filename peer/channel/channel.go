@@ -55,6 +55,7 @@ func Cmd(cf *ChannelCmdFactory) *cobra.Command {
 	channelCmd.AddCommand(joinCmd(cf))
 	channelCmd.AddCommand(createCmd(cf))
 	channelCmd.AddCommand(fetchCmd(cf))
+	channelCmd.AddCommand(policyDiffCmd(cf))
 
 	return channelCmd
 }
@@ -0,0 +1,139 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package channel
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/configtx"
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/peer/common"
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/spf13/cobra"
+)
+
+func policyDiffCmd(cf *ChannelCmdFactory) *cobra.Command {
+	policyDiffCmd := &cobra.Command{
+		Use:   "policydiff",
+		Short: "Show the policies changed by a configuration update.",
+		Long: `Show the policies a configuration update adds, removes or modifies, so that its impact can be reviewed before signing it.
+The current configuration is read from the configuration block of --blockpath, and the update from the configuration transaction of --file.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return policyDiff(cmd, args, cf)
+		},
+	}
+
+	return policyDiffCmd
+}
+
+func policyDiff(cmd *cobra.Command, args []string, cf *ChannelCmdFactory) error {
+	if genesisBlockPath == common.UndefinedParamValue {
+		return fmt.Errorf("Must supply the configuration block file")
+	}
+	if channelTxFile == "" {
+		return fmt.Errorf("Must supply the configuration transaction file")
+	}
+
+	blockBytes, err := ioutil.ReadFile(genesisBlockPath)
+	if err != nil {
+		return fmt.Errorf("Error reading configuration block: %s", err)
+	}
+	block, err := utils.GetBlockFromBlockBytes(blockBytes)
+	if err != nil {
+		return fmt.Errorf("Error unmarshalling configuration block: %s", err)
+	}
+	configEnv, err := configtx.ConfigEnvelopeFromBlock(block)
+	if err != nil {
+		return fmt.Errorf("Error extracting configuration from block: %s", err)
+	}
+
+	txBytes, err := ioutil.ReadFile(channelTxFile)
+	if err != nil {
+		return fmt.Errorf("Error reading configuration transaction: %s", err)
+	}
+	configtxEnv := &cb.Envelope{}
+	if err = proto.Unmarshal(txBytes, configtxEnv); err != nil {
+		return fmt.Errorf("Error unmarshalling configuration transaction: %s", err)
+	}
+
+	original, err := configtx.NewManagerImpl(configEnv, configtx.NewInitializer(), nil)
+	if err != nil {
+		return fmt.Errorf("Error loading the current configuration: %s", err)
+	}
+	updatedEnv, err := configtx.UpdatedConfig(configEnv, configtx.NewInitializer(), configtxEnv)
+	if err != nil {
+		return fmt.Errorf("Error applying the configuration transaction: %s", err)
+	}
+	updated, err := configtx.NewManagerImpl(updatedEnv, configtx.NewInitializer(), nil)
+	if err != nil {
+		return fmt.Errorf("Error loading the updated configuration: %s", err)
+	}
+
+	printPolicyChanges(os.Stdout, original.PolicyManager().Snapshot().Diff(updated.PolicyManager().Snapshot()))
+	return nil
+}
+
+// printPolicyChanges writes each change to w, in a human readable form
+func printPolicyChanges(w io.Writer, changes []*policies.PolicyChange) {
+	if len(changes) == 0 {
+		fmt.Fprintln(w, "No policies changed")
+		return
+	}
+
+	for _, change := range changes {
+		switch {
+		case change.Original == nil:
+			fmt.Fprintf(w, "Added %s:\n  + %s\n", change.Path, policyString(change.Updated))
+		case change.Updated == nil:
+			fmt.Fprintf(w, "Removed %s:\n  - %s\n", change.Path, policyString(change.Original))
+		default:
+			fmt.Fprintf(w, "Modified %s:\n  - %s\n  + %s\n", change.Path, policyString(change.Original), policyString(change.Updated))
+		}
+	}
+}
+
+// policyString returns the type, the decoded value and the mod policy of configPolicy
+func policyString(configPolicy *cb.ConfigPolicy) string {
+	policy := configPolicy.Policy
+	if policy == nil {
+		return fmt.Sprintf("<nil> (mod_policy: %s)", configPolicy.ModPolicy)
+	}
+
+	var msg proto.Message
+	switch cb.Policy_PolicyType(policy.Type) {
+	case cb.Policy_SIGNATURE:
+		msg = &cb.SignaturePolicyEnvelope{}
+	case cb.Policy_IMPLICIT_META:
+		msg = &cb.ImplicitMetaPolicy{}
+	case cb.Policy_COMPOSITE:
+		msg = &cb.CompositePolicy{}
+	case cb.Policy_BLS_AGGREGATE:
+		msg = &cb.BLSAggregatePolicy{}
+	}
+
+	value := fmt.Sprintf("%x", policy.Policy)
+	if msg != nil && proto.Unmarshal(policy.Policy, msg) == nil {
+		value = strings.TrimSpace(proto.CompactTextString(msg))
+	}
+	return fmt.Sprintf("%s %s (mod_policy: %s)", cb.Policy_PolicyType(policy.Type), value, configPolicy.ModPolicy)
+}
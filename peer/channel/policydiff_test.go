@@ -0,0 +1,83 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package channel
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/hyperledger/fabric/common/cauthdsl"
+	"github.com/hyperledger/fabric/common/policies"
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+)
+
+func TestPolicyDiffMissingArguments(t *testing.T) {
+	for _, args := range [][]string{
+		{"-f", "update.tx"},
+		{"-b", "mychannel.block"},
+	} {
+		cmd := policyDiffCmd(nil)
+		AddFlags(cmd)
+		cmd.SetArgs(args)
+		if err := cmd.Execute(); err == nil {
+			t.Fatalf("Expected error executing policydiff command with arguments %v", args)
+		}
+		genesisBlockPath, channelTxFile = "", ""
+	}
+}
+
+func TestPrintPolicyChanges(t *testing.T) {
+	buf := &bytes.Buffer{}
+	printPolicyChanges(buf, nil)
+	if buf.String() != "No policies changed\n" {
+		t.Fatalf("Unexpected output for no changes: %s", buf.String())
+	}
+
+	signature := &cb.ConfigPolicy{
+		Policy: &cb.Policy{
+			Type:   int32(cb.Policy_SIGNATURE),
+			Policy: utils.MarshalOrPanic(cauthdsl.AcceptAllPolicy),
+		},
+		ModPolicy: "Admins",
+	}
+	implicitMeta := &cb.ConfigPolicy{
+		Policy: &cb.Policy{
+			Type:   int32(cb.Policy_IMPLICIT_META),
+			Policy: utils.MarshalOrPanic(&cb.ImplicitMetaPolicy{SubPolicy: "Admins", Rule: cb.ImplicitMetaPolicy_MAJORITY}),
+		},
+		ModPolicy: "Admins",
+	}
+
+	buf.Reset()
+	printPolicyChanges(buf, []*policies.PolicyChange{
+		{Path: "/Channel/Admins", Original: signature, Updated: implicitMeta},
+		{Path: "/Channel/Readers", Updated: implicitMeta},
+		{Path: "/Channel/Writers", Original: signature},
+	})
+	expected := `Modified /Channel/Admins:
+  - SIGNATURE ` + policyString(signature)[len("SIGNATURE "):] + `
+  + IMPLICIT_META sub_policy:"Admins" rule:MAJORITY (mod_policy: Admins)
+Added /Channel/Readers:
+  + IMPLICIT_META sub_policy:"Admins" rule:MAJORITY (mod_policy: Admins)
+Removed /Channel/Writers:
+  - ` + policyString(signature) + `
+`
+	if buf.String() != expected {
+		t.Fatalf("Unexpected output:\n%s\nexpected:\n%s", buf.String(), expected)
+	}
+}
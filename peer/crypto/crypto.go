@@ -0,0 +1,41 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crypto
+
+import (
+	"fmt"
+
+	"github.com/op/go-logging"
+	"github.com/spf13/cobra"
+)
+
+const cryptoFuncName = "crypto"
+
+var logger = logging.MustGetLogger("cryptoCmd")
+
+// Cmd returns the cobra command for Crypto
+func Cmd() *cobra.Command {
+	cryptoCmd.AddCommand(selfTestCmd())
+
+	return cryptoCmd
+}
+
+var cryptoCmd = &cobra.Command{
+	Use:   cryptoFuncName,
+	Short: fmt.Sprintf("%s specific commands.", cryptoFuncName),
+	Long:  fmt.Sprintf("%s specific commands.", cryptoFuncName),
+}
@@ -0,0 +1,222 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crypto
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric/bccsp/factory"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	iterations int
+	fipsMode   bool
+)
+
+func selfTestCmd() *cobra.Command {
+	flags := selfTestCobraCmd.Flags()
+	flags.IntVarP(&iterations, "iterations", "n", 100, "Number of times each operation is benchmarked")
+	flags.BoolVar(&fipsMode, "fips", false, "Validate the BCCSP configuration against the FIPS-mode constraints")
+
+	return selfTestCobraCmd
+}
+
+var selfTestCobraCmd = &cobra.Command{
+	Use:   "selftest",
+	Short: "Tests and benchmarks the configured BCCSP.",
+	Long: `Exercises the BCCSP configured under peer.BCCSP (hash, sign, verify, encrypt, decrypt),
+including the PKCS#11 paths if it is the default provider, and reports the throughput and
+latency of each operation. With --fips, the configuration is also validated against the
+FIPS-mode constraints.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return selfTest(os.Stdout)
+	},
+}
+
+func selfTest(out io.Writer) error {
+	var opts *factory.FactoryOpts
+	if err := viper.UnmarshalKey("peer.BCCSP", &opts); err != nil {
+		return fmt.Errorf("Could not parse YAML config [%s]", err)
+	}
+	if opts == nil {
+		opts = &factory.DefaultOpts
+	}
+
+	if fipsMode {
+		if err := checkFIPS(opts); err != nil {
+			return err
+		}
+		fmt.Fprintln(out, "The BCCSP configuration satisfies the FIPS-mode constraints")
+	}
+
+	fmt.Fprintf(out, "Testing BCCSP provider [%s] with [%d] iterations\n", opts.ProviderName, iterations)
+	results, err := runSelfTest(factory.GetDefault(), iterations)
+	for _, r := range results {
+		fmt.Fprintln(out, r)
+	}
+	if err != nil {
+		return fmt.Errorf("Self-test failed [%s]", err)
+	}
+	return nil
+}
+
+// result is the outcome of benchmarking an operation
+type result struct {
+	name       string
+	iterations int
+	elapsed    time.Duration
+}
+
+func (r *result) String() string {
+	latency := r.elapsed / time.Duration(r.iterations)
+	throughput := float64(r.iterations) / r.elapsed.Seconds()
+	return fmt.Sprintf("%-14s %10.1f ops/s %12s/op", r.name, throughput, latency)
+}
+
+// runSelfTest checks that each operation of csp is correct, and then
+// benchmarks it. It returns the results of the operations benchmarked
+// before the first failure, if any.
+func runSelfTest(csp bccsp.BCCSP, iterations int) ([]*result, error) {
+	if csp == nil {
+		return nil, errors.New("Invalid BCCSP. It must be different from nil.")
+	}
+	if iterations <= 0 {
+		return nil, fmt.Errorf("Invalid number of iterations [%d]. It must be positive.", iterations)
+	}
+
+	msg := []byte("BCCSP self-test")
+	var (
+		digest, signature, ciphertext []byte
+		generated, signer, verifier   bccsp.Key
+		aesKey                        bccsp.Key
+	)
+
+	ops := []struct {
+		name  string
+		op    func() error
+		check func() error
+	}{
+		{"hash", func() (err error) {
+			digest, err = csp.Hash(msg, &bccsp.SHAOpts{})
+			return
+		}, func() error {
+			if len(digest) == 0 {
+				return errors.New("Empty digest")
+			}
+			return nil
+		}},
+		{"ecdsa keygen", func() (err error) {
+			generated, err = csp.KeyGen(&bccsp.ECDSAKeyGenOpts{Temporary: true})
+			return
+		}, func() (err error) {
+			// The keys generated while benchmarking are discarded
+			signer = generated
+			verifier, err = signer.PublicKey()
+			return
+		}},
+		{"ecdsa sign", func() (err error) {
+			signature, err = csp.Sign(signer, digest, nil)
+			return
+		}, nil},
+		{"ecdsa verify", func() error {
+			valid, err := csp.Verify(verifier, signature, digest, nil)
+			if err == nil && !valid {
+				err = errors.New("Invalid signature")
+			}
+			return err
+		}, nil},
+		{"aes keygen", func() (err error) {
+			generated, err = csp.KeyGen(&bccsp.AESKeyGenOpts{Temporary: true})
+			return
+		}, func() error {
+			aesKey = generated
+			return nil
+		}},
+		{"aes encrypt", func() (err error) {
+			ciphertext, err = csp.Encrypt(aesKey, msg, &bccsp.AESCBCPKCS7ModeOpts{})
+			return
+		}, nil},
+		{"aes decrypt", func() error {
+			// Some providers decrypt in place
+			src := append([]byte(nil), ciphertext...)
+			plaintext, err := csp.Decrypt(aesKey, src, &bccsp.AESCBCPKCS7ModeOpts{})
+			if err == nil && !bytes.Equal(plaintext, msg) {
+				err = errors.New("Decrypted plaintext does not match")
+			}
+			return err
+		}, nil},
+	}
+
+	var results []*result
+	for _, o := range ops {
+		// The first run checks the correctness of the operation
+		if err := o.op(); err != nil {
+			return results, fmt.Errorf("Failed %s [%s]", o.name, err)
+		}
+		if o.check != nil {
+			if err := o.check(); err != nil {
+				return results, fmt.Errorf("Failed %s [%s]", o.name, err)
+			}
+		}
+
+		start := time.Now()
+		for i := 0; i < iterations; i++ {
+			if err := o.op(); err != nil {
+				return results, fmt.Errorf("Failed %s [%s]", o.name, err)
+			}
+		}
+		results = append(results, &result{o.name, iterations, time.Since(start)})
+		logger.Debugf("Benchmarked %s", o.name)
+	}
+
+	return results, nil
+}
+
+// checkFIPS validates opts against the FIPS-mode constraints: keys
+// must be held by a PKCS#11 module, at a security level of at least
+// 256 bits with the SHA2 hash family
+func checkFIPS(opts *factory.FactoryOpts) error {
+	var violations []string
+	if opts.ProviderName != factory.PKCS11BasedFactoryName || opts.Pkcs11Opts == nil {
+		violations = append(violations, fmt.Sprintf("the default provider is [%s], not [%s]", opts.ProviderName, factory.PKCS11BasedFactoryName))
+	} else {
+		p11Opts := opts.Pkcs11Opts
+		if p11Opts.Library == "" {
+			violations = append(violations, "no PKCS#11 library is configured")
+		}
+		if p11Opts.SecLevel < 256 {
+			violations = append(violations, fmt.Sprintf("the security level [%d] is lower than 256", p11Opts.SecLevel))
+		}
+		if p11Opts.HashFamily != "SHA2" {
+			violations = append(violations, fmt.Sprintf("the hash family [%s] is not SHA2", p11Opts.HashFamily))
+		}
+	}
+
+	if len(violations) != 0 {
+		return fmt.Errorf("The BCCSP configuration violates the FIPS-mode constraints: %s", strings.Join(violations, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crypto
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/hyperledger/fabric/bccsp/factory"
+	"github.com/hyperledger/fabric/bccsp/sw"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunSelfTest(t *testing.T) {
+	csp, err := sw.New(256, "SHA2", sw.NewDummyKeyStore())
+	assert.NoError(t, err)
+
+	results, err := runSelfTest(csp, 2)
+	assert.NoError(t, err)
+	assert.Len(t, results, 7)
+	for _, r := range results {
+		assert.Equal(t, 2, r.iterations)
+		assert.Contains(t, r.String(), "ops/s")
+	}
+
+	_, err = runSelfTest(nil, 2)
+	assert.Error(t, err)
+	_, err = runSelfTest(csp, 0)
+	assert.Error(t, err)
+}
+
+func TestSelfTest(t *testing.T) {
+	assert.NoError(t, factory.InitFactories(nil))
+	defer func() { fipsMode = false }()
+
+	iterations = 1
+	out := &bytes.Buffer{}
+	assert.NoError(t, selfTest(out))
+	assert.Contains(t, out.String(), "ecdsa sign")
+
+	// The default configuration is not FIPS compliant
+	fipsMode = true
+	assert.Error(t, selfTest(out))
+}
+
+func TestCheckFIPS(t *testing.T) {
+	assert.Error(t, checkFIPS(&factory.DefaultOpts))
+
+	opts := &factory.FactoryOpts{
+		ProviderName: factory.PKCS11BasedFactoryName,
+		Pkcs11Opts: &factory.PKCS11Opts{
+			SecLevel:   256,
+			HashFamily: "SHA2",
+			Library:    "/usr/lib/softhsm/libsofthsm2.so",
+		},
+	}
+	assert.NoError(t, checkFIPS(opts))
+
+	opts.Pkcs11Opts.HashFamily = "SHA3"
+	opts.Pkcs11Opts.SecLevel = 128
+	opts.Pkcs11Opts.Library = ""
+	err := checkFIPS(opts)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "SHA3")
+	assert.Contains(t, err.Error(), "128")
+	assert.Contains(t, err.Error(), "library")
+}
@@ -0,0 +1,333 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcs
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric/bccsp/factory"
+	"github.com/hyperledger/fabric/gossip/common"
+	"github.com/hyperledger/fabric/msp"
+	protoscommon "github.com/hyperledger/fabric/protos/common"
+)
+
+// signAuditBufferSize is the number of sign records that can be
+// queued for the SignAuditSink before new records start being dropped.
+const signAuditBufferSize = 1024
+
+// evaluationAuditBufferSize is the number of evaluation records that can be
+// queued for the EvaluationAuditor before new records start being dropped.
+const evaluationAuditBufferSize = 1024
+
+// signAuditHashLabel prefixes what the hashes of sign records are computed over
+const signAuditHashLabel = "gossip-sign-audit"
+
+// SignAuditRecord describes a signing operation
+// performed by the MessageCryptoService.
+// The signed message itself is not part of the record.
+type SignAuditRecord struct {
+	// Digest is the SHA2-256 digest of the signed message
+	Digest []byte
+
+	// Timestamp is the time the signature was produced
+	Timestamp time.Time
+
+	// MSPID is the identifier of the MSP of the signing identity
+	MSPID string
+
+	// Dropped is the number of records dropped, as the sink
+	// could not keep up, since the previous record
+	Dropped uint64
+
+	// PrevHash is the Hash of the previous record delivered
+	// to the sink, nil for the first one
+	PrevHash []byte
+}
+
+// Hash returns the HMAC-SHA2-256 of the record under key, which the
+// next record delivered links to. Records thus form a chain, and dropped
+// records are accounted for by the record that follows them. A record
+// removed from or altered in the trail breaks the chain, unless the
+// chain is recomputed from that record on, which requires key. Without
+// a key, anyone can recompute the chain, which then only reveals
+// accidental loss or corruption of records.
+func (r *SignAuditRecord) Hash(key []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(signAuditHashLabel))
+	for _, field := range [][]byte{r.Digest, []byte(r.MSPID), r.PrevHash} {
+		binary.Write(mac, binary.BigEndian, uint32(len(field)))
+		mac.Write(field)
+	}
+	binary.Write(mac, binary.BigEndian, r.Timestamp.UnixNano())
+	binary.Write(mac, binary.BigEndian, r.Dropped)
+	return mac.Sum(nil)
+}
+
+// SignAuditSink receives a SignAuditRecord for
+// every signature produced by the MessageCryptoService.
+// Records are delivered sequentially by a dedicated goroutine,
+// therefore a slow sink never blocks signing. Records are dropped
+// if the sink cannot keep up, and counted in the next record.
+type SignAuditSink interface {
+	// AuditSign is invoked once per produced signature
+	AuditSign(record *SignAuditRecord)
+}
+
+// WithSignAuditSink makes the MessageCryptoService
+// report every signing operation to sink. The records
+// are chained with key, see SignAuditRecord.Hash, which
+// should be a secret kept apart from the trail.
+func WithSignAuditSink(sink SignAuditSink, key []byte) Option {
+	return func(s *mspMessageCryptoService) {
+		if sink == nil {
+			return
+		}
+		s.signAudit = newSignAuditor(sink, key)
+	}
+}
+
+// signAuditor decouples the signing path from the SignAuditSink
+type signAuditor struct {
+	sink    SignAuditSink
+	key     []byte
+	records chan *SignAuditRecord
+	done    chan struct{}
+
+	lock sync.Mutex
+	// dropped counts the records dropped since the last one queued
+	dropped uint64
+	stopped bool
+}
+
+func newSignAuditor(sink SignAuditSink, key []byte) *signAuditor {
+	a := &signAuditor{
+		sink:    sink,
+		key:     copyBytes(key),
+		records: make(chan *SignAuditRecord, signAuditBufferSize),
+		done:    make(chan struct{}),
+	}
+	go a.run()
+	return a
+}
+
+func (a *signAuditor) run() {
+	defer close(a.done)
+	var prevHash []byte
+	for record := range a.records {
+		record.PrevHash = prevHash
+		prevHash = record.Hash(a.key)
+		a.sink.AuditSign(record)
+	}
+}
+
+// stop stops the auditor once the queued records are
+// delivered. Records are no longer queued afterwards.
+func (a *signAuditor) stop() {
+	if a == nil {
+		return
+	}
+	a.lock.Lock()
+	if !a.stopped {
+		a.stopped = true
+		close(a.records)
+	}
+	a.lock.Unlock()
+	<-a.done
+}
+
+// record enqueues a SignAuditRecord for msg signed by identity.
+// It never blocks: if the queue is full, the record is dropped,
+// and counted in the next record queued.
+func (a *signAuditor) record(identity msp.Identity, msg []byte) {
+	if a == nil {
+		return
+	}
+
+	digest, err := factory.GetDefault().Hash(msg, &bccsp.SHA256Opts{})
+	if err != nil {
+		logger.Errorf("Failed computing digest of signed message for audit: [%s]", err)
+		return
+	}
+
+	record := &SignAuditRecord{
+		Digest:    digest,
+		Timestamp: time.Now(),
+		MSPID:     identity.GetMSPIdentifier(),
+	}
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if a.stopped {
+		return
+	}
+	record.Dropped = a.dropped
+	select {
+	case a.records <- record:
+		a.dropped = 0
+	default:
+		a.dropped++
+		logger.Warningf("Sign audit queue is full, dropping record for digest [% x]", digest)
+	}
+}
+
+// EvaluationAuditRecord describes the input of a policy
+// evaluation performed by the MessageCryptoService
+type EvaluationAuditRecord struct {
+	// ChainID is the channel the policy belongs to
+	ChainID common.ChainID
+
+	// Policy is the name of the evaluated policy
+	Policy string
+
+	// SignedData is the signature set the policy is evaluated
+	// over. It is a copy of the data the policy sees.
+	SignedData []*protoscommon.SignedData
+
+	// Timestamp is the time the evaluation started
+	Timestamp time.Time
+
+	// Dropped is the number of records dropped, as the auditor
+	// could not keep up, since the previous record
+	Dropped uint64
+}
+
+// EvaluationAuditor receives an EvaluationAuditRecord for every
+// policy evaluation performed by VerifyByChannel, VerifyByChannelAll,
+// VerifyByChannelAdmins and VerifyBlock, allowing the signatures the
+// peer accepted to be independently re-verified later.
+// Records are delivered sequentially by a dedicated goroutine,
+// therefore a slow auditor never blocks verification. Records are
+// dropped if the auditor cannot keep up, and counted in the next record.
+type EvaluationAuditor interface {
+	// AuditEvaluation is invoked once per policy evaluation
+	AuditEvaluation(record *EvaluationAuditRecord)
+}
+
+// WithEvaluationAuditor makes the MessageCryptoService report
+// the input of every policy evaluation to auditor.
+func WithEvaluationAuditor(auditor EvaluationAuditor) Option {
+	return func(s *mspMessageCryptoService) {
+		if auditor == nil {
+			return
+		}
+		s.evaluationAudit = newEvaluationAuditor(auditor)
+	}
+}
+
+// evaluationAuditor decouples the verification
+// paths from the EvaluationAuditor
+type evaluationAuditor struct {
+	auditor EvaluationAuditor
+	records chan *EvaluationAuditRecord
+	done    chan struct{}
+
+	lock sync.Mutex
+	// dropped counts the records dropped since the last one queued
+	dropped uint64
+	stopped bool
+}
+
+func newEvaluationAuditor(auditor EvaluationAuditor) *evaluationAuditor {
+	a := &evaluationAuditor{
+		auditor: auditor,
+		records: make(chan *EvaluationAuditRecord, evaluationAuditBufferSize),
+		done:    make(chan struct{}),
+	}
+	go a.run()
+	return a
+}
+
+func (a *evaluationAuditor) run() {
+	defer close(a.done)
+	for record := range a.records {
+		a.auditor.AuditEvaluation(record)
+	}
+}
+
+// stop stops the auditor once the queued records are
+// delivered. Records are no longer queued afterwards.
+func (a *evaluationAuditor) stop() {
+	if a == nil {
+		return
+	}
+	a.lock.Lock()
+	if !a.stopped {
+		a.stopped = true
+		close(a.records)
+	}
+	a.lock.Unlock()
+	<-a.done
+}
+
+// record enqueues an EvaluationAuditRecord for the evaluation of policy
+// policyName of channel chainID over signatureSet. signatureSet is copied,
+// so that the auditor cannot alter what the policy evaluates.
+// It never blocks: if the queue is full, the record is dropped,
+// and counted in the next record queued.
+func (a *evaluationAuditor) record(chainID common.ChainID, policyName string, signatureSet []*protoscommon.SignedData) {
+	if a == nil {
+		return
+	}
+
+	record := &EvaluationAuditRecord{
+		ChainID:    chainID,
+		Policy:     policyName,
+		SignedData: make([]*protoscommon.SignedData, len(signatureSet)),
+		Timestamp:  time.Now(),
+	}
+	for i, sd := range signatureSet {
+		record.SignedData[i] = &protoscommon.SignedData{
+			Data:      copyBytes(sd.Data),
+			Identity:  copyBytes(sd.Identity),
+			Signature: copyBytes(sd.Signature),
+		}
+	}
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if a.stopped {
+		return
+	}
+	record.Dropped = a.dropped
+	select {
+	case a.records <- record:
+		a.dropped = 0
+	default:
+		a.dropped++
+		logger.Warningf("Evaluation audit queue is full, dropping record for policy [%s] of channel [%s]", policyName, string(chainID))
+	}
+}
+
+// Stop stops the auditors of the service, once the records queued so
+// far are delivered. Signatures and evaluations are no longer audited
+// afterwards.
+func (s *mspMessageCryptoService) Stop() {
+	s.signAudit.stop()
+	s.evaluationAudit.stop()
+}
+
+func copyBytes(b []byte) []byte {
+	if b == nil {
+		return nil
+	}
+	return append([]byte(nil), b...)
+}
@@ -0,0 +1,254 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric/bccsp/factory"
+	mockpolicies "github.com/hyperledger/fabric/common/mocks/policies"
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/common/util"
+	"github.com/hyperledger/fabric/gossip/common"
+	"github.com/hyperledger/fabric/msp/mgmt"
+	protoscommon "github.com/hyperledger/fabric/protos/common"
+	"github.com/stretchr/testify/assert"
+)
+
+type chanSignAuditSink chan *SignAuditRecord
+
+func (c chanSignAuditSink) AuditSign(record *SignAuditRecord) {
+	c <- record
+}
+
+type blockingSignAuditSink chan struct{}
+
+func (b blockingSignAuditSink) AuditSign(record *SignAuditRecord) {
+	<-b
+}
+
+func TestSignAuditSink(t *testing.T) {
+	sink := make(chanSignAuditSink, 2)
+	mcs := New(&mockpolicies.PolicyManagerMgmt{}, WithSignAuditSink(sink, nil)).(*mspMessageCryptoService)
+
+	msg := []byte("Hello World!!!")
+	digest, err := factory.GetDefault().Hash(msg, &bccsp.SHA256Opts{})
+	assert.NoError(t, err)
+	localMSPID, err := mgmt.GetLocalMSP().GetIdentifier()
+	assert.NoError(t, err)
+
+	_, err = mcs.Sign(msg)
+	assert.NoError(t, err)
+	_, err = mcs.SignForChannel(common.ChainID(util.GetTestChainID()), msg)
+	assert.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		select {
+		case record := <-sink:
+			assert.Equal(t, digest, record.Digest, "Audit record must carry the digest of the message")
+			assert.Equal(t, localMSPID, record.MSPID)
+			assert.False(t, record.Timestamp.IsZero())
+		case <-time.After(time.Second):
+			t.Fatal("Audit record was not delivered")
+		}
+	}
+}
+
+func TestSignAuditSinkNeverBlocks(t *testing.T) {
+	sink := make(blockingSignAuditSink)
+	defer close(sink)
+	mcs := New(&mockpolicies.PolicyManagerMgmt{}, WithSignAuditSink(sink, nil))
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < signAuditBufferSize+10; i++ {
+			mcs.Sign([]byte("Hello World!!!"))
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("Signing blocked on a slow audit sink")
+	}
+}
+
+func TestSignAuditChain(t *testing.T) {
+	sink := make(chanSignAuditSink, 10)
+	// Not running yet, so that the queue fills up
+	key := []byte("audit key")
+	auditor := &signAuditor{sink: sink, key: key, records: make(chan *SignAuditRecord, 2), done: make(chan struct{})}
+	identity := mgmt.GetLocalSigningIdentityOrPanic()
+	for i := 0; i < 5; i++ {
+		auditor.record(identity, []byte{byte(i)})
+	}
+	go auditor.run()
+	for len(auditor.records) > 0 {
+		time.Sleep(10 * time.Millisecond)
+	}
+	auditor.record(identity, []byte{5})
+	auditor.stop()
+
+	// Records are no longer queued once stopped
+	auditor.record(identity, []byte{6})
+	auditor.stop()
+
+	assert.Len(t, sink, 3)
+	var prevHash []byte
+	var dropped []uint64
+	for i := 0; i < 3; i++ {
+		record := <-sink
+		assert.Equal(t, prevHash, record.PrevHash, "Records must be chained")
+		prevHash = record.Hash(key)
+		dropped = append(dropped, record.Dropped)
+	}
+	assert.Equal(t, []uint64{0, 0, 3}, dropped, "Dropped records must be counted in the next record")
+
+	// Altering a record breaks the chain
+	record := &SignAuditRecord{Digest: []byte{1}, MSPID: "Org1", Timestamp: time.Now()}
+	hash := record.Hash(key)
+	record.Dropped = 1
+	assert.NotEqual(t, hash, record.Hash(key))
+
+	// Recomputing the chain requires the key
+	record.Dropped = 0
+	assert.NotEqual(t, hash, record.Hash(nil))
+	assert.NotEqual(t, hash, record.Hash([]byte("other key")))
+}
+
+func TestAuditorsStop(t *testing.T) {
+	chainID := "evalauditstopchannel"
+	setupMockChannel(chainID, "AuditOrg")
+	sink := make(chanSignAuditSink, 1)
+	auditor := make(chanEvaluationAuditor, 1)
+	pm := newMockPolicyManager()
+	pm.setPolicy(chainID, policies.ChannelApplicationReaders, &mockChannelPolicy{chainID: chainID})
+	mcs := New(pm, WithSignAuditSink(sink, nil), WithEvaluationAuditor(auditor))
+
+	peer := newMockPeerIdentity("AuditOrg", "peer0")
+	msg := []byte("Hello World!!!")
+	_, err := mcs.Sign(msg)
+	assert.NoError(t, err)
+	assert.NoError(t, mcs.VerifyByChannel(common.ChainID(chainID), peer, mockSign(msg), msg))
+
+	// Queued records are delivered before Stop returns
+	mcs.Stop()
+	assert.Len(t, sink, 1)
+	assert.Len(t, auditor, 1)
+
+	// and nothing is audited afterwards
+	_, err = mcs.Sign(msg)
+	assert.NoError(t, err)
+	assert.NoError(t, mcs.VerifyByChannel(common.ChainID(chainID), peer, mockSign(msg), msg))
+	assert.Len(t, sink, 1)
+	assert.Len(t, auditor, 1)
+	mcs.Stop()
+
+	// Services without auditors can be stopped as well
+	New(pm).Stop()
+}
+
+type chanEvaluationAuditor chan *EvaluationAuditRecord
+
+func (c chanEvaluationAuditor) AuditEvaluation(record *EvaluationAuditRecord) {
+	c <- record
+}
+
+type blockingEvaluationAuditor chan struct{}
+
+func (b blockingEvaluationAuditor) AuditEvaluation(record *EvaluationAuditRecord) {
+	<-b
+}
+
+func TestEvaluationAuditor(t *testing.T) {
+	chainID := "evalauditchannel"
+	setupMockChannel(chainID, "AuditOrg")
+	auditor := make(chanEvaluationAuditor, 2)
+	pm := newMockPolicyManager()
+	pm.setPolicy(chainID, policies.ChannelApplicationReaders, &mockChannelPolicy{chainID: chainID})
+	pm.setPolicy(chainID, policies.BlockValidation, &mockChannelPolicy{chainID: chainID})
+	mcs := New(pm, WithEvaluationAuditor(auditor))
+
+	peer := newMockPeerIdentity("AuditOrg", "peer0")
+	msg := []byte("Hello World!!!")
+	sig := mockSign(msg)
+	assert.NoError(t, mcs.VerifyByChannel(common.ChainID(chainID), peer, sig, msg))
+	assert.NoError(t, mcs.VerifyBlock(common.ChainID(chainID), mockBlock(chainID, peer)))
+
+	for _, policyName := range []string{policies.ChannelApplicationReaders, policies.BlockValidation} {
+		select {
+		case record := <-auditor:
+			assert.Equal(t, common.ChainID(chainID), record.ChainID)
+			assert.Equal(t, policyName, record.Policy)
+			assert.False(t, record.Timestamp.IsZero())
+			assert.Len(t, record.SignedData, 1)
+			assert.Equal(t, []byte(peer), record.SignedData[0].Identity)
+			if policyName == policies.ChannelApplicationReaders {
+				assert.Equal(t, msg, record.SignedData[0].Data)
+				assert.Equal(t, sig, record.SignedData[0].Signature)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("Audit record was not delivered")
+		}
+	}
+}
+
+func TestEvaluationAuditorCannotAlterData(t *testing.T) {
+	// Not running, so that the record can be inspected here
+	auditor := &evaluationAuditor{records: make(chan *EvaluationAuditRecord, 1)}
+	signatureSet := []*protoscommon.SignedData{{
+		Data:      []byte("data"),
+		Identity:  []byte("identity"),
+		Signature: []byte("signature"),
+	}}
+	auditor.record(common.ChainID("channel"), policies.BlockValidation, signatureSet)
+
+	record := <-auditor.records
+	record.SignedData[0].Data[0] = 'D'
+	record.SignedData[0].Identity = []byte("other identity")
+	assert.Equal(t, []byte("data"), signatureSet[0].Data)
+	assert.Equal(t, []byte("identity"), signatureSet[0].Identity)
+}
+
+func TestEvaluationAuditorNeverBlocks(t *testing.T) {
+	chainID := "evalauditblockchannel"
+	setupMockChannel(chainID, "AuditOrg")
+	auditor := make(blockingEvaluationAuditor)
+	defer close(auditor)
+	pm := newMockPolicyManager()
+	pm.setPolicy(chainID, policies.ChannelApplicationReaders, &mockChannelPolicy{chainID: chainID})
+	mcs := New(pm, WithEvaluationAuditor(auditor))
+
+	peer := newMockPeerIdentity("AuditOrg", "peer0")
+	msg := []byte("Hello World!!!")
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < evaluationAuditBufferSize+10; i++ {
+			mcs.VerifyByChannel(common.ChainID(chainID), peer, mockSign(msg), msg)
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("Verification blocked on a slow auditor")
+	}
+}
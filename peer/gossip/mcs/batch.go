@@ -0,0 +1,239 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcs
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+
+	"github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/gossip/api"
+	"github.com/hyperledger/fabric/gossip/common"
+	"github.com/hyperledger/fabric/msp"
+)
+
+// SignedMessage is a message along with its signature
+// and the identity of its signer
+type SignedMessage struct {
+	PeerIdentity api.PeerIdentityType
+	Signature    []byte
+	Message      []byte
+}
+
+// BatchVerifyItem is an ECDSA signature to be verified by a BatchVerifier
+type BatchVerifyItem struct {
+	PublicKey *ecdsa.PublicKey
+	Message   []byte
+	Signature []byte
+}
+
+// BatchVerifier verifies several ECDSA signatures in a single call,
+// e.g. by means of a hardware accelerator or a library batching
+// the verifications.
+// Message is the message as it was signed: the verifier is expected to
+// hash it with SHA2-256, as the MSPs do, and to check that Signature is
+// an ASN.1 encoded ECDSA signature in low-S form.
+type BatchVerifier interface {
+	// VerifyBatch returns, for each of items in the same position,
+	// nil if its signature is valid, or an error otherwise
+	VerifyBatch(items []*BatchVerifyItem) []error
+}
+
+// WithBatchVerifier makes VerifyBatch hand the signature
+// verifications it can batch over to verifier
+func WithBatchVerifier(verifier BatchVerifier) Option {
+	return func(s *mspMessageCryptoService) {
+		s.batchVerifier = verifier
+	}
+}
+
+// bccspBatchVerifier is a BatchVerifier that verifies
+// signatures via a BCCSP, spreading them over several goroutines
+type bccspBatchVerifier struct {
+	csp         bccsp.BCCSP
+	concurrency int
+}
+
+// NewBCCSPBatchVerifier returns a BatchVerifier that verifies
+// signatures via csp, concurrency at a time.
+// If concurrency is not positive, the number of CPUs is used.
+func NewBCCSPBatchVerifier(csp bccsp.BCCSP, concurrency int) BatchVerifier {
+	if concurrency <= 0 {
+		concurrency = runtime.NumCPU()
+	}
+	return &bccspBatchVerifier{csp: csp, concurrency: concurrency}
+}
+
+// VerifyBatch returns, for each of items in the same position,
+// nil if its signature is valid, or an error otherwise
+func (v *bccspBatchVerifier) VerifyBatch(items []*BatchVerifyItem) []error {
+	errs := make([]error, len(items))
+
+	workers := v.concurrency
+	if workers > len(items) {
+		workers = len(items)
+	}
+
+	indexes := make(chan int, len(items))
+	for i := range items {
+		indexes <- i
+	}
+	close(indexes)
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				errs[i] = v.verify(items[i])
+			}
+		}()
+	}
+	wg.Wait()
+
+	return errs
+}
+
+// verify returns nil if the signature of item is valid
+func (v *bccspBatchVerifier) verify(item *BatchVerifyItem) error {
+	if item == nil || item.PublicKey == nil {
+		return errors.New("Invalid item. It must carry a public key.")
+	}
+
+	key, err := v.csp.KeyImport(item.PublicKey, &bccsp.ECDSAGoPublicKeyImportOpts{Temporary: true})
+	if err != nil {
+		return fmt.Errorf("Failed importing public key [%s]", err)
+	}
+
+	digest, err := v.csp.Hash(item.Message, &bccsp.SHA256Opts{})
+	if err != nil {
+		return fmt.Errorf("Failed computing digest [%s]", err)
+	}
+
+	valid, err := v.csp.Verify(key, item.Signature, digest, nil)
+	if err != nil {
+		return fmt.Errorf("Could not determine if signature is valid [%s]", err)
+	}
+	if !valid {
+		return errors.New("The signature is invalid")
+	}
+
+	return nil
+}
+
+// VerifyBatch checks each of msgs as Verify does, and returns, for each
+// of msgs in the same position, nil if the verification succeeded or
+// an error otherwise.
+// If a BatchVerifier is configured, the ECDSA signatures whose check
+// reduces to a plain signature verification (because the signer belongs
+// to the local MSP, or because the channel policy gossip is verified
+// against requires the signature of a single principal) are verified in
+// a single call to the BatchVerifier. All the others are verified one by one.
+func (s *mspMessageCryptoService) VerifyBatch(msgs []*SignedMessage) []error {
+	errs := make([]error, len(msgs))
+
+	var items []*BatchVerifyItem
+	var positions []int
+	for i, m := range msgs {
+		if m == nil {
+			errs[i] = errors.New("Invalid signed message. It must be different from nil.")
+			continue
+		}
+
+		identity, chainID, err := s.getValidatedIdentity(logger, m.PeerIdentity)
+		if err != nil {
+			logger.Errorf("Failed getting validated identity from peer identity [%s]", err)
+			errs[i] = err
+			continue
+		}
+
+		if s.batchVerifier != nil {
+			publicKey, batchable, err := s.getBatchPublicKey(identity, chainID, m.PeerIdentity)
+			if err != nil {
+				errs[i] = err
+				continue
+			}
+			if batchable {
+				items = append(items, &BatchVerifyItem{PublicKey: publicKey, Message: m.Message, Signature: m.Signature})
+				positions = append(positions, i)
+				continue
+			}
+		}
+
+		errs[i] = s.verifyValidated(logger, identity, chainID, m.PeerIdentity, m.Signature, m.Message)
+	}
+
+	if len(items) == 0 {
+		return errs
+	}
+
+	results := s.batchVerifier.VerifyBatch(items)
+	for j, i := range positions {
+		if len(results) != len(items) {
+			errs[i] = fmt.Errorf("Batch verifier returned [%d] results for [%d] signatures", len(results), len(items))
+			continue
+		}
+		errs[i] = results[j]
+	}
+
+	return errs
+}
+
+// getBatchPublicKey returns the ECDSA public key of peerIdentity, whose
+// validated identity is identity, if verifying a signature of
+// peerIdentity amounts to a plain signature verification.
+// Otherwise, it returns false and the signature must be verified as usual.
+// An error is returned if peerIdentity is known not to satisfy the
+// policy gossip is verified against on channel chainID.
+func (s *mspMessageCryptoService) getBatchPublicKey(identity msp.Identity, chainID common.ChainID, peerIdentity api.PeerIdentityType) (*ecdsa.PublicKey, bool, error) {
+	cert, err := getCertificate(peerIdentity)
+	if err != nil {
+		return nil, false, nil
+	}
+	publicKey, ok := cert.PublicKey.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, false, nil
+	}
+
+	if len(chainID) == 0 {
+		return publicKey, true, nil
+	}
+
+	_, policy, err := s.getGossipPolicy(logger, chainID)
+	if err != nil {
+		return nil, false, err
+	}
+	spp, ok := policy.(policies.SinglePrincipalPolicy)
+	if !ok {
+		return nil, false, nil
+	}
+	principal, ok := spp.SinglePrincipal()
+	if !ok {
+		return nil, false, nil
+	}
+
+	if err := identity.SatisfiesPrincipal(principal); err != nil {
+		return nil, false, err
+	}
+
+	return publicKey, true, nil
+}
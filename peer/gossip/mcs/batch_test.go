@@ -0,0 +1,136 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcs
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"testing"
+
+	"github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric/bccsp/factory"
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockBatchVerifier accepts the signatures produced by mockSign
+type mockBatchVerifier struct {
+	calls int
+	items []*BatchVerifyItem
+}
+
+func (v *mockBatchVerifier) VerifyBatch(items []*BatchVerifyItem) []error {
+	v.calls++
+	v.items = append(v.items, items...)
+	errs := make([]error, len(items))
+	for i, item := range items {
+		if !bytes.Equal(item.Signature, mockSign(item.Message)) {
+			errs[i] = errors.New("Invalid signature")
+		}
+	}
+	return errs
+}
+
+func TestVerifyBatch(t *testing.T) {
+	chainID := "batchchannel"
+	setupMockChannel(chainID, "BatchOrg")
+	policy := newMemberPolicy(t, chainID, "BatchOrg")
+	fast := newMockPolicyManager()
+	fast.setPolicy(chainID, policies.ChannelApplicationReaders, policy)
+	slow := newMockPolicyManager()
+	slow.setPolicy(chainID, policies.ChannelApplicationReaders, &opaquePolicy{policy})
+
+	msg := []byte("Hello World!!!")
+	peer0 := newCertPeerIdentity(t, "BatchOrg", &x509.Certificate{Subject: pkix.Name{CommonName: "peer0"}})
+	peer1 := newCertPeerIdentity(t, "BatchOrg", &x509.Certificate{Subject: pkix.Name{CommonName: "peer1"}})
+	msgs := []*SignedMessage{
+		{PeerIdentity: peer0, Signature: mockSign(msg), Message: msg},
+		{PeerIdentity: peer1, Signature: []byte("bad"), Message: msg},
+		{PeerIdentity: newMockPeerIdentity("BatchOrg", "peer2"), Signature: mockSign(msg), Message: msg},
+		{PeerIdentity: newMockPeerIdentity("UnknownOrg", "peer3"), Signature: mockSign(msg), Message: msg},
+		nil,
+	}
+	assertResults := func(errs []error) {
+		assert.Len(t, errs, len(msgs))
+		assert.NoError(t, errs[0])
+		assert.Error(t, errs[1])
+		assert.NoError(t, errs[2])
+		assert.Error(t, errs[3])
+		assert.Error(t, errs[4])
+	}
+
+	// No batch verifier
+	assertResults(New(fast).(*mspMessageCryptoService).VerifyBatch(msgs))
+
+	// The x.509 ECDSA identities are verified in one batch
+	verifier := &mockBatchVerifier{}
+	assertResults(New(fast, WithBatchVerifier(verifier)).(*mspMessageCryptoService).VerifyBatch(msgs))
+	assert.Equal(t, 1, verifier.calls)
+	assert.Len(t, verifier.items, 2)
+
+	// Policies not reducing to a single principal cannot be batched
+	verifier = &mockBatchVerifier{}
+	assertResults(New(slow, WithBatchVerifier(verifier)).(*mspMessageCryptoService).VerifyBatch(msgs))
+	assert.Equal(t, 0, verifier.calls)
+}
+
+func TestBCCSPBatchVerifier(t *testing.T) {
+	csp := factory.GetDefault()
+	msg := []byte("Hello World!!!")
+
+	var items []*BatchVerifyItem
+	for i := 0; i < 10; i++ {
+		key, err := csp.KeyGen(&bccsp.ECDSAP256KeyGenOpts{Temporary: true})
+		assert.NoError(t, err)
+		digest, err := csp.Hash(msg, &bccsp.SHA256Opts{})
+		assert.NoError(t, err)
+		signature, err := csp.Sign(key, digest, nil)
+		assert.NoError(t, err)
+
+		pk, err := key.PublicKey()
+		assert.NoError(t, err)
+		raw, err := pk.Bytes()
+		assert.NoError(t, err)
+		publicKey, err := x509.ParsePKIXPublicKey(raw)
+		assert.NoError(t, err)
+
+		items = append(items, &BatchVerifyItem{PublicKey: publicKey.(*ecdsa.PublicKey), Message: msg, Signature: signature})
+	}
+	// Invalid signature
+	items[3] = &BatchVerifyItem{PublicKey: items[3].PublicKey, Message: []byte("Other message"), Signature: items[3].Signature}
+	// Signature of another key
+	items[7] = &BatchVerifyItem{PublicKey: items[6].PublicKey, Message: msg, Signature: items[7].Signature}
+	// Missing public key
+	items = append(items, &BatchVerifyItem{Message: msg, Signature: items[0].Signature})
+
+	for _, concurrency := range []int{0, 1, 4, 100} {
+		errs := NewBCCSPBatchVerifier(csp, concurrency).VerifyBatch(items)
+		assert.Len(t, errs, len(items))
+		for i, err := range errs {
+			if i == 3 || i == 7 || i == 10 {
+				assert.Error(t, err, "Item [%d] must not verify", i)
+			} else {
+				assert.NoError(t, err, "Item [%d] must verify", i)
+			}
+		}
+	}
+
+	assert.Empty(t, NewBCCSPBatchVerifier(csp, 0).VerifyBatch(nil))
+}
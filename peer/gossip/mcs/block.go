@@ -0,0 +1,475 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcs
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"runtime"
+	"sync"
+
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/common/util"
+	"github.com/hyperledger/fabric/gossip/api"
+	"github.com/hyperledger/fabric/gossip/common"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/msp/mgmt"
+	protoscommon "github.com/hyperledger/fabric/protos/common"
+	protosgossip "github.com/hyperledger/fabric/protos/gossip"
+	"github.com/hyperledger/fabric/protos/utils"
+)
+
+// defaultMaxBlockSignatures is the maximum number of signatures
+// VerifyBlock processes on a block, unless configured otherwise
+const defaultMaxBlockSignatures = 1024
+
+// WithMaxBlockSignatures makes VerifyBlock reject blocks carrying more
+// than n signatures, before validating any of them, with a
+// *TooManySignaturesError. This bounds the work a peer can be forced
+// to do per block. If n is not positive, defaultMaxBlockSignatures
+// applies.
+func WithMaxBlockSignatures(n int) Option {
+	return func(s *mspMessageCryptoService) {
+		s.maxBlockSignatures = n
+	}
+}
+
+// WithMinBlockSignatures makes VerifyBlock reject blocks carrying
+// less than n valid signatures from distinct signers, even if the
+// block validation policy of the channel is satisfied.
+func WithMinBlockSignatures(n int) Option {
+	return func(s *mspMessageCryptoService) {
+		s.minBlockSignatures = n
+	}
+}
+
+// WithBlockDataHashCheck sets whether VerifyBlock recomputes the hash of
+// the data of every block and compares it with the data hash in the
+// block's header, which is what the signatures of the block cover. This
+// detects blocks whose data has been swapped under a validly signed
+// header, at the cost of hashing all the transactions of the block.
+//
+// The check is enabled by default. It recomputes the data hash as
+// Block.Data.Hash does, which matches the default block data hashing
+// structure of channels only. Peers of channels configured with another
+// BlockDataHashingStructure width would reject all of their blocks, and
+// peers that can't afford to hash large blocks may rely on the block
+// signatures alone. Both can opt out with WithBlockDataHashCheck(false).
+func WithBlockDataHashCheck(enabled bool) Option {
+	return func(s *mspMessageCryptoService) {
+		s.skipBlockDataHash = !enabled
+	}
+}
+
+// WithBlockVerificationConcurrency sets the maximum number of blocks
+// VerifyBlocks verifies at the same time. By default, it is the number
+// of CPUs.
+func WithBlockVerificationConcurrency(n int) Option {
+	return func(s *mspMessageCryptoService) {
+		s.blockVerificationConcurrency = n
+	}
+}
+
+// VerifyBlock returns nil if the block is properly signed,
+// else returns error
+func (s *mspMessageCryptoService) VerifyBlock(chainID common.ChainID, signedBlock api.SignedBlock) (err error) {
+	defer func() {
+		s.metrics.countBlockVerification(err)
+		s.publishFailure(OperationVerifyBlock, nil, chainID, err)
+	}()
+
+	ctx, err := s.getBlockVerificationContext(chainID)
+	if err != nil {
+		return err
+	}
+
+	return s.verifyBlock(ctx, signedBlock)
+}
+
+// VerifyBlocks verifies blocks concurrently, as VerifyBlock does, against
+// a single snapshot of the configuration of channel chainID. It returns
+// a slice whose i-th element is the result of the verification of
+// blocks[i]. If the configuration of the channel is updated while the
+// batch is being verified (i.e. the MSP manager of the channel is
+// replaced, or ChannelConfigUpdated is invoked for the channel), some
+// blocks would be verified against the old configuration and some against
+// the new one, hence the verification of the whole batch fails with
+// ChannelConfigChangedError and the caller is expected to retry.
+func (s *mspMessageCryptoService) VerifyBlocks(chainID common.ChainID, blocks []api.SignedBlock) []error {
+	errs := make([]error, len(blocks))
+	if len(blocks) == 0 {
+		return errs
+	}
+
+	ctx, err := s.getBlockVerificationContext(chainID)
+	if err != nil {
+		for i := range errs {
+			errs[i] = err
+			s.metrics.countBlockVerification(err)
+			s.publishFailure(OperationVerifyBlock, nil, chainID, err)
+		}
+		return errs
+	}
+
+	workers := s.blockVerificationConcurrency
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > len(blocks) {
+		workers = len(blocks)
+	}
+
+	indexes := make(chan int, len(blocks))
+	for i := range blocks {
+		indexes <- i
+	}
+	close(indexes)
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if s.configChanged(ctx) {
+					// The results would be discarded anyway
+					return
+				}
+				errs[i] = s.verifyBlock(ctx, blocks[i])
+			}
+		}()
+	}
+	wg.Wait()
+
+	if s.configChanged(ctx) {
+		logger.Warningf("Configuration of channel [%s] changed while verifying [%d] blocks", string(ctx.chainID), len(blocks))
+		for i := range errs {
+			errs[i] = &ChannelConfigChangedError{ChainID: ctx.chainID}
+		}
+	}
+
+	for _, err := range errs {
+		s.metrics.countBlockVerification(err)
+		s.publishFailure(OperationVerifyBlock, nil, chainID, err)
+	}
+
+	return errs
+}
+
+// blockVerificationContext is the configuration
+// of a channel blocks are verified against
+type blockVerificationContext struct {
+	// chainID is the normalized channel identifier
+	chainID common.ChainID
+	// mspManager is the MSP manager of the channel, if any
+	mspManager msp.MSPManager
+	// policy is the block validation policy of the channel
+	policy policies.Policy
+	// configUpdates is the number of configuration
+	// updates of the channel notified so far
+	configUpdates uint64
+}
+
+// getBlockVerificationContext returns the current
+// configuration of channel chainID for verifying blocks
+func (s *mspMessageCryptoService) getBlockVerificationContext(chainID common.ChainID) (*blockVerificationContext, error) {
+	chainID = s.normalizeChannel(chainID)
+
+	cpm, err := s.getChannelPolicyManager(logger, chainID)
+	if err != nil {
+		return nil, err
+	}
+
+	policy, flag := cpm.GetPolicy(policies.BlockValidation)
+	logger.Debugf("Got block validation policy for channel [%s] with flag [%s]", string(chainID), flag)
+	if !flag {
+		err := s.policyNotFound(chainID, policies.BlockValidation)
+		if s.strictPolicyLookup {
+			return nil, err
+		}
+	}
+
+	return &blockVerificationContext{
+		chainID:       chainID,
+		mspManager:    mgmt.GetManagerForChainIfExists(string(chainID)),
+		policy:        policy,
+		configUpdates: s.configUpdates.get(chainID),
+	}, nil
+}
+
+// configChanged returns true if the configuration
+// of the channel changed since ctx was taken
+func (s *mspMessageCryptoService) configChanged(ctx *blockVerificationContext) bool {
+	return mgmt.GetManagerForChainIfExists(string(ctx.chainID)) != ctx.mspManager ||
+		s.configUpdates.get(ctx.chainID) != ctx.configUpdates
+}
+
+// verifyBlock verifies signedBlock against ctx
+func (s *mspMessageCryptoService) verifyBlock(ctx *blockVerificationContext, signedBlock api.SignedBlock) error {
+	block, err := getBlock(signedBlock)
+	if err != nil {
+		return err
+	}
+
+	// 1. Check that the block is related to chainID
+	blockChainID, err := getBlockChainID(block)
+	if err != nil {
+		return fmt.Errorf("Failed getting channel id from block: [%s]", err)
+	}
+
+	if string(s.normalizeChannel(common.ChainID(blockChainID))) != string(ctx.chainID) {
+		return fmt.Errorf("Invalid block's channel id. Expected [%s]. Given [%s]", string(ctx.chainID), blockChainID)
+	}
+
+	// 2. Check that the block's data matches its header
+	if !s.skipBlockDataHash {
+		dataHash := block.Data.Hash()
+		if !bytes.Equal(dataHash, block.Header.DataHash) {
+			return &DataHashMismatchError{BlockNumber: block.Header.Number, Expected: block.Header.DataHash, Computed: dataHash}
+		}
+	}
+
+	// 3. Verify the signatures of the block
+	return s.verifyBlockSignatures(ctx, block)
+}
+
+// VerifyBlockAttestation checks that the header of a block is properly
+// signed, given the metadata of the block, without requiring the data of
+// the block. This allows checking the attestations of blocks before pulling
+// their data.
+// As the channel of a block is carried by its data, the attestation is
+// only checked against the block validation policy of channel chainID.
+// Once the data of the block is available, the whole block must still
+// be verified via VerifyBlock, which checks the data against the attested
+// data hash.
+func (s *mspMessageCryptoService) VerifyBlockAttestation(chainID common.ChainID, header *protoscommon.BlockHeader, metadata *protoscommon.BlockMetadata) (err error) {
+	defer func() {
+		s.publishFailure(OperationVerifyBlock, nil, chainID, err)
+	}()
+
+	if header == nil {
+		return errors.New("Invalid block attestation. It must carry a header.")
+	}
+	if metadata == nil {
+		return errors.New("Invalid block attestation. It must carry metadata.")
+	}
+
+	ctx, err := s.getBlockVerificationContext(chainID)
+	if err != nil {
+		return err
+	}
+
+	return s.verifyBlockSignatures(ctx, &protoscommon.Block{Header: header, Metadata: metadata})
+}
+
+// verifyBlockSignatures verifies the signatures of block against
+// ctx. The data of block is not accessed.
+func (s *mspMessageCryptoService) verifyBlockSignatures(ctx *blockVerificationContext, block *protoscommon.Block) error {
+	// 1. Extract the signatures of the block
+	maxSignatures := s.maxBlockSignatures
+	if maxSignatures <= 0 {
+		maxSignatures = defaultMaxBlockSignatures
+	}
+	signatureSet, err := getBlockSignatureSet(block, maxSignatures)
+	if err != nil {
+		return err
+	}
+
+	// 2. Enforce the minimum number of signatures, if requested
+	if s.minBlockSignatures > 0 {
+		if valid := countValidBlockSignatures(ctx, signatureSet); valid < s.minBlockSignatures {
+			return fmt.Errorf("Block [%d] carries [%d] valid signatures, at least [%d] are required", block.Header.Number, valid, s.minBlockSignatures)
+		}
+	}
+
+	// 3. Verify that the block is properly signed
+	//    using the policy associated to chainID
+	s.evaluationAudit.record(ctx.chainID, policies.BlockValidation, signatureSet)
+
+	return s.evaluatePolicy(ctx.chainID, policies.BlockValidation, func() error {
+		return ctx.policy.Evaluate(signatureSet)
+	})
+}
+
+// getBlock returns the block carried by signedBlock.
+// Blocks are received either as gossip data messages or
+// payloads, marshalled blocks or blocks.
+func getBlock(signedBlock api.SignedBlock) (*protoscommon.Block, error) {
+	var block *protoscommon.Block
+	var err error
+
+	switch b := signedBlock.(type) {
+	case *protoscommon.Block:
+		block = b
+	case []byte:
+		block, err = utils.GetBlockFromBlockBytes(b)
+	case *protosgossip.Payload:
+		block, err = utils.GetBlockFromBlockBytes(b.Data)
+	case *protosgossip.DataMessage:
+		if b.Payload == nil {
+			return nil, errors.New("Invalid block. The data message carries no payload.")
+		}
+		block, err = utils.GetBlockFromBlockBytes(b.Payload.Data)
+	default:
+		return nil, fmt.Errorf("Invalid block. Unsupported type [%T]", signedBlock)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Failed unmarshalling block: [%s]", err)
+	}
+
+	if block == nil || block.Header == nil || block.Metadata == nil {
+		return nil, errors.New("Invalid block. Header and metadata must be different from nil.")
+	}
+
+	return block, nil
+}
+
+// getBlockChainID returns the channel of block, as found in the channel
+// header of its first envelope. Unlike utils.GetChainIDFromBlock, it
+// checks every level of the envelope, as blocks come from remote peers.
+func getBlockChainID(block *protoscommon.Block) (string, error) {
+	if block.Data == nil || len(block.Data.Data) == 0 {
+		return "", errors.New("Invalid block. It carries no envelope.")
+	}
+	envelope, err := utils.UnmarshalEnvelope(block.Data.Data[0])
+	if err != nil {
+		return "", err
+	}
+	payload, err := utils.UnmarshalPayload(envelope.Payload)
+	if err != nil {
+		return "", err
+	}
+	if payload.Header == nil {
+		return "", errors.New("Invalid block. The payload of its first envelope has no header.")
+	}
+	channelHeader, err := utils.UnmarshalChannelHeader(payload.Header.ChannelHeader)
+	if err != nil {
+		return "", err
+	}
+	return channelHeader.ChannelId, nil
+}
+
+// getBlockSignatureSet returns the signatures of block
+// as SignedData to be evaluated against a policy
+func getBlockSignatureSet(block *protoscommon.Block, maxSignatures int) ([]*protoscommon.SignedData, error) {
+	if len(block.Metadata.Metadata) <= int(protoscommon.BlockMetadataIndex_SIGNATURES) {
+		return nil, errors.New("Invalid block. It carries no signatures metadata.")
+	}
+
+	// All the orderer types (solo, kafka, sbft and raft-based ones alike)
+	// store the block signatures as a Metadata message under the
+	// SIGNATURES index. Metadata.Value is opaque and orderer-specific
+	// (e.g. empty for solo and kafka, the consenter metadata for raft),
+	// but every signature covers it, followed by the signature header and
+	// the block header.
+	if len(block.Metadata.Metadata[protoscommon.BlockMetadataIndex_SIGNATURES]) == 0 {
+		return nil, errors.New("Invalid block. Its signatures metadata is empty.")
+	}
+
+	metadata, err := utils.GetMetadataFromBlock(block, protoscommon.BlockMetadataIndex_SIGNATURES)
+	if err != nil {
+		return nil, fmt.Errorf("Unrecognized signatures metadata layout: [%s]", err)
+	}
+	if len(metadata.Signatures) == 0 {
+		return nil, errors.New("Unrecognized signatures metadata layout: no signatures found")
+	}
+	if len(metadata.Signatures) > maxSignatures {
+		return nil, &TooManySignaturesError{BlockNumber: block.Header.Number, Signatures: len(metadata.Signatures), Max: maxSignatures}
+	}
+
+	signatureSet := make([]*protoscommon.SignedData, 0, len(metadata.Signatures))
+	for i, metadataSignature := range metadata.Signatures {
+		signatureHeader, err := utils.GetSignatureHeader(metadataSignature.SignatureHeader)
+		if err != nil {
+			return nil, fmt.Errorf("Unrecognized signatures metadata layout: failed unmarshalling signature header [%d]: [%s]", i, err)
+		}
+		if len(signatureHeader.Creator) == 0 {
+			return nil, fmt.Errorf("Unrecognized signatures metadata layout: signature header [%d] has no creator", i)
+		}
+
+		signedBytes, err := BlockSignedBytes(block, metadata.Value, metadataSignature.SignatureHeader)
+		if err != nil {
+			return nil, err
+		}
+
+		signatureSet = append(signatureSet, &protoscommon.SignedData{
+			Identity:  signatureHeader.Creator,
+			Data:      signedBytes,
+			Signature: metadataSignature.Signature,
+		})
+	}
+
+	return signatureSet, nil
+}
+
+// BlockSignedBytes returns the bytes covered by a signature of block,
+// whose signature header is signatureHeader, when the signatures
+// metadata of block carries metadataValue.
+// These bytes are the concatenation of, in order, metadataValue, the
+// marshalled signature header, and the ASN.1 DER encoding of the block
+// header as returned by BlockHeader.Bytes (i.e. the SEQUENCE of number,
+// previous hash and data hash). The block data and the other metadata
+// are covered only indirectly, via the data hash.
+// Signers and verifiers of blocks must agree on this construction.
+func BlockSignedBytes(block *protoscommon.Block, metadataValue, signatureHeader []byte) ([]byte, error) {
+	if block == nil || block.Header == nil {
+		return nil, errors.New("Invalid block. It must carry a header.")
+	}
+	if block.Header.Number > uint64(math.MaxInt64) {
+		return nil, fmt.Errorf("Invalid block number [%d]. It cannot be ASN.1 encoded.", block.Header.Number)
+	}
+
+	return util.ConcatenateBytes(metadataValue, signatureHeader, block.Header.Bytes()), nil
+}
+
+// countValidBlockSignatures returns the number of distinct signers
+// in signatureSet that are valid on channel chainID and whose
+// signature verifies
+func countValidBlockSignatures(ctx *blockVerificationContext, signatureSet []*protoscommon.SignedData) int {
+	chainID, mspManager := ctx.chainID, ctx.mspManager
+	if mspManager == nil {
+		logger.Warningf("No MSP manager found for channel [%s]", string(chainID))
+		return 0
+	}
+
+	signers := make(map[string]struct{})
+	for _, sd := range signatureSet {
+		if _, counted := signers[string(sd.Identity)]; counted {
+			continue
+		}
+
+		identity, err := mspManager.DeserializeIdentity(sd.Identity)
+		if err != nil {
+			logger.Debugf("Failed deserializing block signer [% x] on [%s]: [%s]", sd.Identity, string(chainID), err)
+			continue
+		}
+		if err := identity.Validate(); err != nil {
+			logger.Debugf("Failed validating block signer [% x] on [%s]: [%s]", sd.Identity, string(chainID), err)
+			continue
+		}
+		if err := identity.Verify(sd.Data, sd.Signature); err != nil {
+			logger.Debugf("Failed verifying block signature of [% x] on [%s]: [%s]", sd.Identity, string(chainID), err)
+			continue
+		}
+
+		signers[string(sd.Identity)] = struct{}{}
+	}
+
+	return len(signers)
+}
@@ -0,0 +1,431 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcs
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math"
+	"sync/atomic"
+	"testing"
+
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/gossip/api"
+	"github.com/hyperledger/fabric/gossip/common"
+	protoscommon "github.com/hyperledger/fabric/protos/common"
+	protosgossip "github.com/hyperledger/fabric/protos/gossip"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockBlock returns a block of channel chainID
+// signed by each of signers
+func mockBlock(chainID string, signers ...api.PeerIdentityType) *protoscommon.Block {
+	return mockBlockWithMetadataValue(chainID, nil, signers...)
+}
+
+// mockBlockWithMetadataValue returns a block of channel chainID
+// signed by each of signers, whose signatures metadata carries value
+func mockBlockWithMetadataValue(chainID string, value []byte, signers ...api.PeerIdentityType) *protoscommon.Block {
+	block := protoscommon.NewBlock(1, []byte("previous"))
+	block.Data.Data = [][]byte{utils.MarshalOrPanic(&protoscommon.Envelope{
+		Payload: utils.MarshalOrPanic(&protoscommon.Payload{
+			Header: &protoscommon.Header{
+				ChannelHeader: utils.MarshalOrPanic(utils.MakeChannelHeader(protoscommon.HeaderType_ENDORSER_TRANSACTION, 0, chainID, 0)),
+			},
+		}),
+	})}
+	block.Header.DataHash = block.Data.Hash()
+
+	metadata := &protoscommon.Metadata{Value: value}
+	for _, signer := range signers {
+		signatureHeader := utils.MarshalOrPanic(&protoscommon.SignatureHeader{Creator: signer})
+		signedBytes, err := BlockSignedBytes(block, metadata.Value, signatureHeader)
+		if err != nil {
+			panic(err)
+		}
+		metadata.Signatures = append(metadata.Signatures, &protoscommon.MetadataSignature{
+			SignatureHeader: signatureHeader,
+			Signature:       mockSign(signedBytes),
+		})
+	}
+	block.Metadata.Metadata[protoscommon.BlockMetadataIndex_SIGNATURES] = utils.MarshalOrPanic(metadata)
+
+	return block
+}
+
+func newBlockTestService(chainID string, opts ...Option) *mspMessageCryptoService {
+	pm := newMockPolicyManager()
+	pm.setPolicy(chainID, policies.BlockValidation, &mockChannelPolicy{chainID: chainID})
+	return New(pm, opts...).(*mspMessageCryptoService)
+}
+
+func TestVerifyBlock(t *testing.T) {
+	chainID := "blockchannel"
+	setupMockChannel(chainID, "OrdererOrg")
+	mcs := newBlockTestService(chainID)
+	orderer := newMockPeerIdentity("OrdererOrg", "orderer0")
+
+	block := mockBlock(chainID, orderer)
+	assert.NoError(t, mcs.VerifyBlock(common.ChainID(chainID), block))
+
+	// The different forms a block is gossiped in
+	blockBytes := utils.MarshalOrPanic(block)
+	assert.NoError(t, mcs.VerifyBlock(common.ChainID(chainID), blockBytes))
+	assert.NoError(t, mcs.VerifyBlock(common.ChainID(chainID), &protosgossip.Payload{Data: blockBytes}))
+	assert.NoError(t, mcs.VerifyBlock(common.ChainID(chainID), &protosgossip.DataMessage{Payload: &protosgossip.Payload{Data: blockBytes}}))
+
+	// Wrong channel
+	assert.Error(t, mcs.VerifyBlock(common.ChainID("otherchannel"), block))
+	// Unsigned block
+	assert.Error(t, mcs.VerifyBlock(common.ChainID(chainID), mockBlock(chainID)))
+	// Signed by an unknown identity
+	assert.Error(t, mcs.VerifyBlock(common.ChainID(chainID), mockBlock(chainID, newMockPeerIdentity("UnknownOrg", "orderer0"))))
+	// Unsupported types
+	assert.Error(t, mcs.VerifyBlock(common.ChainID(chainID), nil))
+	assert.Error(t, mcs.VerifyBlock(common.ChainID(chainID), &protosgossip.DataMessage{}))
+	assert.Error(t, mcs.VerifyBlock(common.ChainID(chainID), "block"))
+}
+
+func TestVerifyBlockMalformedEnvelope(t *testing.T) {
+	chainID := "malformedblockchannel"
+	setupMockChannel(chainID, "OrdererOrg")
+	mcs := newBlockTestService(chainID)
+	orderer := newMockPeerIdentity("OrdererOrg", "orderer0")
+
+	for name, data := range map[string][][]byte{
+		"no envelope":      nil,
+		"garbage envelope": {[]byte("garbage")},
+		"garbage payload":  {utils.MarshalOrPanic(&protoscommon.Envelope{Payload: []byte("garbage")})},
+		"no payload":       {utils.MarshalOrPanic(&protoscommon.Envelope{})},
+		"no header":        {utils.MarshalOrPanic(&protoscommon.Envelope{Payload: utils.MarshalOrPanic(&protoscommon.Payload{Data: []byte("data")})})},
+		"garbage channel header": {utils.MarshalOrPanic(&protoscommon.Envelope{Payload: utils.MarshalOrPanic(&protoscommon.Payload{
+			Header: &protoscommon.Header{ChannelHeader: []byte("garbage")},
+		})})},
+	} {
+		block := mockBlock(chainID, orderer)
+		block.Data.Data = data
+		block.Header.DataHash = block.Data.Hash()
+
+		// Blocks are gossiped as bytes, and must not make the peer panic
+		assert.Error(t, mcs.VerifyBlock(common.ChainID(chainID), block), name)
+		assert.Error(t, mcs.VerifyBlock(common.ChainID(chainID), utils.MarshalOrPanic(block)), name)
+	}
+}
+
+func TestVerifyBlockAttestation(t *testing.T) {
+	chainID := "attestationchannel"
+	setupMockChannel(chainID, "OrdererOrg")
+	mcs := newBlockTestService(chainID)
+	orderer := newMockPeerIdentity("OrdererOrg", "orderer0")
+
+	block := mockBlock(chainID, orderer)
+	assert.NoError(t, mcs.VerifyBlockAttestation(common.ChainID(chainID), block.Header, block.Metadata))
+
+	// Tampered header
+	header := *block.Header
+	header.DataHash = []byte("tampered")
+	assert.Error(t, mcs.VerifyBlockAttestation(common.ChainID(chainID), &header, block.Metadata))
+	// Unsigned block
+	unsigned := mockBlock(chainID)
+	assert.Error(t, mcs.VerifyBlockAttestation(common.ChainID(chainID), unsigned.Header, unsigned.Metadata))
+	// Signed by an unknown identity
+	unknown := mockBlock(chainID, newMockPeerIdentity("UnknownOrg", "orderer0"))
+	assert.Error(t, mcs.VerifyBlockAttestation(common.ChainID(chainID), unknown.Header, unknown.Metadata))
+	// Unknown channel
+	assert.Error(t, mcs.VerifyBlockAttestation(common.ChainID("otherchannel"), block.Header, block.Metadata))
+	// Missing header or metadata
+	assert.Error(t, mcs.VerifyBlockAttestation(common.ChainID(chainID), nil, block.Metadata))
+	assert.Error(t, mcs.VerifyBlockAttestation(common.ChainID(chainID), block.Header, nil))
+}
+
+func TestVerifyBlockMinSignatures(t *testing.T) {
+	chainID := "minsigchannel"
+	setupMockChannel(chainID, "OrdererOrg")
+	mcs := newBlockTestService(chainID, WithMinBlockSignatures(2))
+	orderer0 := newMockPeerIdentity("OrdererOrg", "orderer0")
+	orderer1 := newMockPeerIdentity("OrdererOrg", "orderer1")
+	orderer2 := newMockPeerIdentity("OrdererOrg", "orderer2")
+
+	// Below the threshold, although the policy is satisfied
+	assert.Error(t, mcs.VerifyBlock(common.ChainID(chainID), mockBlock(chainID, orderer0)))
+	// Below the threshold, the same signer twice counts once
+	assert.Error(t, mcs.VerifyBlock(common.ChainID(chainID), mockBlock(chainID, orderer0, orderer0)))
+	// Below the threshold, invalid signers do not count
+	assert.Error(t, mcs.VerifyBlock(common.ChainID(chainID), mockBlock(chainID, orderer0, newMockPeerIdentity("UnknownOrg", "orderer1"))))
+	// At the threshold
+	assert.NoError(t, mcs.VerifyBlock(common.ChainID(chainID), mockBlock(chainID, orderer0, orderer1)))
+	// Above the threshold
+	assert.NoError(t, mcs.VerifyBlock(common.ChainID(chainID), mockBlock(chainID, orderer0, orderer1, orderer2)))
+}
+
+func TestVerifyBlockMaxSignatures(t *testing.T) {
+	chainID := "maxsigchannel"
+	setupMockChannel(chainID, "OrdererOrg")
+	orderers := make([]api.PeerIdentityType, 4)
+	for i := range orderers {
+		orderers[i] = newMockPeerIdentity("OrdererOrg", fmt.Sprintf("orderer%d", i))
+	}
+
+	mcs := newBlockTestService(chainID, WithMaxBlockSignatures(3))
+	assert.NoError(t, mcs.VerifyBlock(common.ChainID(chainID), mockBlock(chainID, orderers[:3]...)))
+	err := mcs.VerifyBlock(common.ChainID(chainID), mockBlock(chainID, orderers...))
+	assert.IsType(t, &TooManySignaturesError{}, err)
+	assert.Equal(t, 4, err.(*TooManySignaturesError).Signatures)
+	assert.Equal(t, 3, err.(*TooManySignaturesError).Max)
+
+	// The limit applies before any signature is validated
+	block := mockBlock(chainID, orderers...)
+	metadata := utils.GetMetadataFromBlockOrPanic(block, protoscommon.BlockMetadataIndex_SIGNATURES)
+	metadata.Signatures[0].Signature = []byte("bad signature")
+	block.Metadata.Metadata[protoscommon.BlockMetadataIndex_SIGNATURES] = utils.MarshalOrPanic(metadata)
+	assert.IsType(t, &TooManySignaturesError{}, mcs.VerifyBlock(common.ChainID(chainID), block))
+
+	// A generous limit applies by default
+	mcs = newBlockTestService(chainID)
+	assert.NoError(t, mcs.VerifyBlock(common.ChainID(chainID), mockBlock(chainID, orderers...)))
+	signers := make([]api.PeerIdentityType, defaultMaxBlockSignatures+1)
+	for i := range signers {
+		signers[i] = orderers[0]
+	}
+	assert.IsType(t, &TooManySignaturesError{}, mcs.VerifyBlock(common.ChainID(chainID), mockBlock(chainID, signers...)))
+}
+
+func TestVerifyBlockRaftLayout(t *testing.T) {
+	chainID := "raftchannel"
+	setupMockChannel(chainID, "OrdererOrg")
+	mcs := newBlockTestService(chainID, WithMinBlockSignatures(3))
+	consenters := []api.PeerIdentityType{
+		newMockPeerIdentity("OrdererOrg", "consenter1"),
+		newMockPeerIdentity("OrdererOrg", "consenter2"),
+		newMockPeerIdentity("OrdererOrg", "consenter3"),
+	}
+
+	// A raft-based orderer stores its consenter metadata (last config
+	// index, raft index, term) in the value of the signatures metadata,
+	// and every consenter signs over it
+	consenterMetadata := utils.MarshalOrPanic(&protoscommon.LastConfig{Index: 42})
+	block := mockBlockWithMetadataValue(chainID, consenterMetadata, consenters...)
+	assert.NoError(t, mcs.VerifyBlock(common.ChainID(chainID), block))
+
+	// Tampering with the consenter metadata invalidates the signatures
+	metadata := utils.GetMetadataFromBlockOrPanic(block, protoscommon.BlockMetadataIndex_SIGNATURES)
+	metadata.Value = utils.MarshalOrPanic(&protoscommon.LastConfig{Index: 43})
+	block.Metadata.Metadata[protoscommon.BlockMetadataIndex_SIGNATURES] = utils.MarshalOrPanic(metadata)
+	assert.Error(t, mcs.VerifyBlock(common.ChainID(chainID), block))
+}
+
+func TestVerifyBlockUnrecognizedLayout(t *testing.T) {
+	chainID := "layoutchannel"
+	setupMockChannel(chainID, "OrdererOrg")
+	mcs := newBlockTestService(chainID)
+	orderer := newMockPeerIdentity("OrdererOrg", "orderer0")
+
+	for name, tamper := range map[string]func(block *protoscommon.Block){
+		"no signatures metadata": func(block *protoscommon.Block) {
+			block.Metadata.Metadata = nil
+		},
+		"empty signatures metadata": func(block *protoscommon.Block) {
+			block.Metadata.Metadata[protoscommon.BlockMetadataIndex_SIGNATURES] = nil
+		},
+		"garbage signatures metadata": func(block *protoscommon.Block) {
+			block.Metadata.Metadata[protoscommon.BlockMetadataIndex_SIGNATURES] = []byte("garbage")
+		},
+		"no signatures": func(block *protoscommon.Block) {
+			block.Metadata.Metadata[protoscommon.BlockMetadataIndex_SIGNATURES] = utils.MarshalOrPanic(&protoscommon.Metadata{Value: []byte("value")})
+		},
+		"garbage signature header": func(block *protoscommon.Block) {
+			block.Metadata.Metadata[protoscommon.BlockMetadataIndex_SIGNATURES] = utils.MarshalOrPanic(&protoscommon.Metadata{
+				Signatures: []*protoscommon.MetadataSignature{{SignatureHeader: []byte("garbage")}},
+			})
+		},
+		"no creator": func(block *protoscommon.Block) {
+			block.Metadata.Metadata[protoscommon.BlockMetadataIndex_SIGNATURES] = utils.MarshalOrPanic(&protoscommon.Metadata{
+				Signatures: []*protoscommon.MetadataSignature{{SignatureHeader: utils.MarshalOrPanic(&protoscommon.SignatureHeader{})}},
+			})
+		},
+	} {
+		block := mockBlock(chainID, orderer)
+		tamper(block)
+		assert.Error(t, mcs.VerifyBlock(common.ChainID(chainID), block), name)
+	}
+}
+
+func TestVerifyBlockDataHash(t *testing.T) {
+	chainID := "datahashchannel"
+	setupMockChannel(chainID, "OrdererOrg")
+	orderer := newMockPeerIdentity("OrdererOrg", "orderer0")
+
+	// Swap the data of a validly signed block
+	block := mockBlock(chainID, orderer)
+	block.Data.Data = append(block.Data.Data, []byte("injected transaction"))
+
+	// Not detected if opted out
+	assert.NoError(t, newBlockTestService(chainID, WithBlockDataHashCheck(false)).VerifyBlock(common.ChainID(chainID), block))
+
+	for _, mcs := range []*mspMessageCryptoService{newBlockTestService(chainID), newBlockTestService(chainID, WithBlockDataHashCheck(true))} {
+		err := mcs.VerifyBlock(common.ChainID(chainID), block)
+		assert.IsType(t, &DataHashMismatchError{}, err)
+		assert.Equal(t, block.Header.DataHash, err.(*DataHashMismatchError).Expected)
+		assert.Equal(t, block.Data.Hash(), err.(*DataHashMismatchError).Computed)
+
+		assert.NoError(t, mcs.VerifyBlock(common.ChainID(chainID), mockBlock(chainID, orderer)))
+	}
+}
+
+func TestVerifyBlocks(t *testing.T) {
+	chainID := "blockschannel"
+	setupMockChannel(chainID, "OrdererOrg")
+	orderer := newMockPeerIdentity("OrdererOrg", "orderer0")
+	unknown := newMockPeerIdentity("UnknownOrg", "orderer0")
+
+	blocks := []api.SignedBlock{
+		mockBlock(chainID, orderer),
+		mockBlock(chainID, unknown),
+		utils.MarshalOrPanic(mockBlock(chainID, orderer)),
+		mockBlock("otherchannel", orderer),
+		mockBlock(chainID),
+	}
+
+	for _, concurrency := range []int{0, 1, 2, 10} {
+		mcs := newBlockTestService(chainID, WithBlockVerificationConcurrency(concurrency))
+		errs := mcs.VerifyBlocks(common.ChainID(chainID), blocks)
+		assert.Len(t, errs, len(blocks))
+		assert.NoError(t, errs[0])
+		assert.Error(t, errs[1])
+		assert.NoError(t, errs[2])
+		assert.Error(t, errs[3])
+		assert.Error(t, errs[4])
+	}
+
+	mcs := newBlockTestService(chainID)
+	assert.Empty(t, mcs.VerifyBlocks(common.ChainID(chainID), nil))
+
+	// Unknown channel
+	errs := mcs.VerifyBlocks(common.ChainID("unknownchannel"), blocks[:2])
+	assert.Len(t, errs, 2)
+	assert.Error(t, errs[0])
+	assert.Error(t, errs[1])
+}
+
+// reconfiguringPolicy is a policy that replaces the MSP
+// manager of its channel the first time it is evaluated
+type reconfiguringPolicy struct {
+	mockChannelPolicy
+	evaluated int32
+}
+
+func (p *reconfiguringPolicy) Evaluate(signatureSet []*protoscommon.SignedData) error {
+	if atomic.AddInt32(&p.evaluated, 1) == 1 {
+		setupMockChannel(p.chainID, "OrdererOrg")
+	}
+	return p.mockChannelPolicy.Evaluate(signatureSet)
+}
+
+func TestVerifyBlocksConfigChange(t *testing.T) {
+	chainID := "reconfigchannel"
+	setupMockChannel(chainID, "OrdererOrg")
+	orderer := newMockPeerIdentity("OrdererOrg", "orderer0")
+
+	policy := &reconfiguringPolicy{mockChannelPolicy: mockChannelPolicy{chainID: chainID}}
+	pm := newMockPolicyManager()
+	pm.setPolicy(chainID, policies.BlockValidation, policy)
+	mcs := New(pm, WithBlockVerificationConcurrency(1)).(*mspMessageCryptoService)
+
+	blocks := []api.SignedBlock{mockBlock(chainID, orderer), mockBlock(chainID, orderer), mockBlock(chainID, orderer)}
+	errs := mcs.VerifyBlocks(common.ChainID(chainID), blocks)
+	assert.Len(t, errs, len(blocks))
+	for _, err := range errs {
+		assert.IsType(t, &ChannelConfigChangedError{}, err)
+	}
+	// The verification stopped as soon as the change was detected
+	assert.Equal(t, int32(1), atomic.LoadInt32(&policy.evaluated))
+
+	// Retrying against the new configuration succeeds
+	for _, err := range mcs.VerifyBlocks(common.ChainID(chainID), blocks) {
+		assert.NoError(t, err)
+	}
+}
+
+func TestBlockSignedBytes(t *testing.T) {
+	block := protoscommon.NewBlock(7, []byte("previous hash"))
+	block.Header.DataHash = []byte("data hash")
+
+	signedBytes, err := BlockSignedBytes(block, []byte("value"), []byte("signature header"))
+	assert.NoError(t, err)
+
+	// Changing this breaks the verification of blocks between
+	// peers (and orderers) running different versions
+	golden := "76616c7565" + // metadata value
+		"7369676e617475726520686561646572" + // signature header
+		"301d" + // block header: SEQUENCE
+		"020107" + // number
+		"040d70726576696f75732068617368" + // previous hash
+		"0409646174612068617368" // data hash
+	assert.Equal(t, golden, hex.EncodeToString(signedBytes))
+
+	// Nothing but the header is covered
+	block.Data.Data = [][]byte{[]byte("transaction")}
+	block.Metadata.Metadata[protoscommon.BlockMetadataIndex_TRANSACTIONS_FILTER] = []byte("filter")
+	signedBytes2, err := BlockSignedBytes(block, []byte("value"), []byte("signature header"))
+	assert.NoError(t, err)
+	assert.Equal(t, signedBytes, signedBytes2)
+
+	// Invalid blocks
+	_, err = BlockSignedBytes(nil, nil, nil)
+	assert.Error(t, err)
+	_, err = BlockSignedBytes(&protoscommon.Block{}, nil, nil)
+	assert.Error(t, err)
+	block.Header.Number = math.MaxUint64
+	_, err = BlockSignedBytes(block, nil, nil)
+	assert.Error(t, err)
+}
+
+func TestVerifyBlocksConfigUpdated(t *testing.T) {
+	chainID := "blocksconfigupdatechannel"
+	setupMockChannel(chainID, "OrdererOrg")
+	orderer := newMockPeerIdentity("OrdererOrg", "orderer0")
+
+	policy := &notifyingPolicy{mockChannelPolicy: mockChannelPolicy{chainID: chainID}}
+	pm := newMockPolicyManager()
+	pm.setPolicy(chainID, policies.BlockValidation, policy)
+	mcs := New(pm, WithBlockVerificationConcurrency(1)).(*mspMessageCryptoService)
+	policy.notify = func() {
+		// A config update applied in place
+		mcs.ChannelConfigUpdated(common.ChainID(chainID))
+	}
+
+	errs := mcs.VerifyBlocks(common.ChainID(chainID), []api.SignedBlock{mockBlock(chainID, orderer), mockBlock(chainID, orderer)})
+	for _, err := range errs {
+		assert.IsType(t, &ChannelConfigChangedError{}, err)
+	}
+}
+
+// notifyingPolicy is a policy that invokes
+// notify the first time it is evaluated
+type notifyingPolicy struct {
+	mockChannelPolicy
+	notify    func()
+	evaluated int32
+}
+
+func (p *notifyingPolicy) Evaluate(signatureSet []*protoscommon.SignedData) error {
+	if atomic.AddInt32(&p.evaluated, 1) == 1 {
+		p.notify()
+	}
+	return p.mockChannelPolicy.Evaluate(signatureSet)
+}
@@ -0,0 +1,123 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcs
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/hyperledger/fabric/bccsp"
+)
+
+// WithBootstrapKeys enables VerifyBootstrap, trusting keys to sign the
+// bootstrap messages exchanged during the startup of the peer, before
+// any MSP is loaded. Private keys are trusted through their public part.
+// Once the MSPs are loaded, DisableBootstrap should be invoked.
+func WithBootstrapKeys(keys ...bccsp.Key) Option {
+	return func(s *mspMessageCryptoService) {
+		s.bootstrap = newBootstrapKeys(keys)
+	}
+}
+
+// VerifyBootstrap checks that signature is a valid signature of message
+// under one of the trusted bootstrap keys. Bootstrap messages are not
+// bound to any identity nor channel, hence this is meant only for the
+// messages exchanged before the MSPs are loaded.
+// It fails if no bootstrap key is configured, or if DisableBootstrap
+// has been invoked.
+func (s *mspMessageCryptoService) VerifyBootstrap(signature, message []byte) error {
+	keys, err := s.bootstrap.get()
+	if err != nil {
+		return err
+	}
+
+	for i, key := range keys {
+		err := verifyKeySignature(key, signature, message)
+		if err == nil {
+			return nil
+		}
+		logger.Debugf("Bootstrap key [%d] did not verify the signature: [%s]", i, err)
+	}
+
+	return errors.New("Signature not valid under any of the bootstrap keys")
+}
+
+// DisableBootstrap disables VerifyBootstrap and drops the bootstrap keys.
+// It is meant to be invoked once the MSPs are loaded, after which
+// messages are verified against the MSPs only. It cannot be undone.
+func (s *mspMessageCryptoService) DisableBootstrap() {
+	s.bootstrap.disable()
+}
+
+// bootstrapKeys holds the trusted bootstrap
+// keys until they are disabled
+type bootstrapKeys struct {
+	lock     sync.RWMutex
+	keys     []bccsp.Key
+	disabled bool
+}
+
+func newBootstrapKeys(keys []bccsp.Key) *bootstrapKeys {
+	b := &bootstrapKeys{}
+	for _, key := range keys {
+		if key == nil {
+			continue
+		}
+		if key.Private() {
+			pk, err := key.PublicKey()
+			if err != nil {
+				logger.Errorf("Failed getting the public part of bootstrap key [% x]: [%s]", key.SKI(), err)
+				continue
+			}
+			key = pk
+		}
+		b.keys = append(b.keys, key)
+	}
+	return b
+}
+
+// get returns the bootstrap keys, or an error
+// if bootstrap verification is not enabled
+func (b *bootstrapKeys) get() ([]bccsp.Key, error) {
+	if b == nil {
+		return nil, errors.New("No bootstrap keys configured")
+	}
+
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	if b.disabled {
+		return nil, errors.New("Bootstrap verification has been disabled")
+	}
+	if len(b.keys) == 0 {
+		return nil, errors.New("No bootstrap keys configured")
+	}
+
+	return b.keys, nil
+}
+
+func (b *bootstrapKeys) disable() {
+	if b == nil {
+		return
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.disabled = true
+	b.keys = nil
+}
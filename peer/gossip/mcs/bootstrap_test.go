@@ -0,0 +1,66 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcs
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric/bccsp/factory"
+	mockpolicies "github.com/hyperledger/fabric/common/mocks/policies"
+	"github.com/stretchr/testify/assert"
+)
+
+func bootstrapSign(t *testing.T, key bccsp.Key, msg []byte) []byte {
+	csp := factory.GetDefault()
+	digest, err := csp.Hash(msg, &bccsp.SHAOpts{})
+	assert.NoError(t, err)
+	sig, err := csp.Sign(key, digest, nil)
+	assert.NoError(t, err)
+	return sig
+}
+
+func TestVerifyBootstrap(t *testing.T) {
+	csp := factory.GetDefault()
+	key1, err := csp.KeyGen(&bccsp.ECDSAP256KeyGenOpts{Temporary: true})
+	assert.NoError(t, err)
+	key2, err := csp.KeyGen(&bccsp.ECDSAP256KeyGenOpts{Temporary: true})
+	assert.NoError(t, err)
+	untrusted, err := csp.KeyGen(&bccsp.ECDSAP256KeyGenOpts{Temporary: true})
+	assert.NoError(t, err)
+	pk2, err := key2.PublicKey()
+	assert.NoError(t, err)
+
+	msg := []byte("bootstrap")
+
+	// Not configured
+	mcs := New(&mockpolicies.PolicyManagerMgmt{}).(*mspMessageCryptoService)
+	assert.Error(t, mcs.VerifyBootstrap(bootstrapSign(t, key1, msg), msg))
+	mcs.DisableBootstrap()
+
+	// Private and public keys are both trusted
+	mcs = New(&mockpolicies.PolicyManagerMgmt{}, WithBootstrapKeys(key1, pk2, nil)).(*mspMessageCryptoService)
+	assert.NoError(t, mcs.VerifyBootstrap(bootstrapSign(t, key1, msg), msg))
+	assert.NoError(t, mcs.VerifyBootstrap(bootstrapSign(t, key2, msg), msg))
+	assert.Error(t, mcs.VerifyBootstrap(bootstrapSign(t, untrusted, msg), msg))
+	assert.Error(t, mcs.VerifyBootstrap(bootstrapSign(t, key1, msg), []byte("tampered")))
+	assert.Error(t, mcs.VerifyBootstrap(nil, msg))
+
+	// Once the MSPs are loaded
+	mcs.DisableBootstrap()
+	assert.Error(t, mcs.VerifyBootstrap(bootstrapSign(t, key1, msg), msg))
+}
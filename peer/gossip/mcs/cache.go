@@ -0,0 +1,487 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcs
+
+import (
+	"container/list"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric/gossip/api"
+	"github.com/hyperledger/fabric/gossip/common"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/msp/mgmt"
+	"github.com/op/go-logging"
+)
+
+// maxTrackedIdentities bounds the number of identities an
+// identity cache keeps track of for evictions and revalidations
+const maxTrackedIdentities = 10000
+
+// IdentityCache is a key-value store backing the cache of
+// validated identities. Implementations may keep entries in
+// memory or in an external storage.
+//
+// A cache hit skips the validation of an identity, hence the
+// service does not trust the backend: entries are authenticated
+// with a key only the service knows, and bound to the number of
+// configuration updates the MSP that validated the identity went
+// through. Entries stored by other processes, altered in the
+// backend or stored before a configuration update are discarded,
+// and the identity is validated in full.
+type IdentityCache interface {
+	// Get returns the value stored under key.
+	// It returns false if there is no such value.
+	Get(key string) ([]byte, bool, error)
+
+	// Set stores value under key
+	Set(key string, value []byte) error
+
+	// Evict removes the value stored under key, if any
+	Evict(key string) error
+}
+
+// WithIdentityCache makes the MessageCryptoService remember, in cache and
+// for ttl, the identities it has validated, so that a subsequent
+// validation of the same identity only requires its deserialization.
+// Use NewLRUIdentityCache for an in-memory cache, or provide an
+// IdentityCache backed by an external storage.
+//
+// A cached identity is still rejected once its certificate expires,
+// or once the MSP that validated it is updated, see ChannelConfigUpdated
+// and LocalMSPUpdated. Other changes that would invalidate it are only
+// picked up when its entry expires. Errors of cache are logged and
+// otherwise ignored: the service falls back to the full validation
+// of identities.
+func WithIdentityCache(cache IdentityCache, ttl time.Duration) Option {
+	return func(s *mspMessageCryptoService) {
+		if cache == nil || ttl <= 0 {
+			return
+		}
+		secret := make([]byte, sha256.Size)
+		if _, err := rand.Read(secret); err != nil {
+			logger.Errorf("Failed generating the key of the identity cache, identities are not cached: [%s]", err)
+			return
+		}
+		s.identityCache = &identityCache{
+			backend:    cache,
+			ttl:        ttl,
+			secret:     secret,
+			identities: make(map[string]api.PeerIdentityType),
+		}
+	}
+}
+
+type identityCache struct {
+	backend IdentityCache
+	ttl     time.Duration
+	// secret authenticates the entries of the backend
+	secret []byte
+
+	// identities maps the keys of the entries this service stored
+	// or found in the backend to their peer identities, as keys
+	// cannot be mapped back to peer identities
+	lock       sync.Mutex
+	identities map[string]api.PeerIdentityType
+}
+
+func (c *identityCache) track(key string, peerIdentity api.PeerIdentityType) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if _, tracked := c.identities[key]; !tracked && len(c.identities) >= maxTrackedIdentities {
+		// Entries no longer tracked are still discarded
+		// on configuration updates, as they are bound to
+		// the configuration they were validated under
+		for k := range c.identities {
+			delete(c.identities, k)
+			break
+		}
+	}
+	c.identities[key] = peerIdentity
+}
+
+func (c *identityCache) untrack(key string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	delete(c.identities, key)
+}
+
+// tracked returns a copy of the tracked identities
+func (c *identityCache) tracked() map[string]api.PeerIdentityType {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	identities := make(map[string]api.PeerIdentityType, len(c.identities))
+	for key, peerIdentity := range c.identities {
+		identities[key] = peerIdentity
+	}
+	return identities
+}
+
+// ChannelConfigUpdated must be invoked whenever the configuration of
+// channel chainID is updated. On the peer, the MSP manager of a channel
+// is updated in place, hence this is the only way for the service to
+// learn that the validations performed by the MSPs of the channel may
+// no longer hold. It evicts from the cache of validated identities the
+// identities validated by the MSPs of the channel, and discards the
+// negative results of membership checks against the channel.
+func (s *mspMessageCryptoService) ChannelConfigUpdated(chainID common.ChainID) {
+	chainID = s.normalizeChannel(chainID)
+	logger.Debugf("Configuration of channel [%s] updated", string(chainID))
+
+	s.configUpdates.increment(chainID)
+	s.nonMembers.invalidateChannel(chainID)
+	s.evictValidatedBy(chainID)
+}
+
+// LocalMSPUpdated must be invoked whenever the local MSP is reloaded,
+// see mgmt.SubscribeLocalMSPUpdates. It evicts from the cache of
+// validated identities the identities validated by the local MSP.
+// The signatures of the service are produced, from then on, by the
+// signing identity of the new local MSP.
+func (s *mspMessageCryptoService) LocalMSPUpdated() {
+	logger.Debug("Local MSP updated")
+
+	s.configUpdates.increment(nil)
+	s.evictValidatedBy(nil)
+}
+
+// evictValidatedBy evicts from the cache of validated identities the
+// identities validated by the MSPs of channel chainID, or by the local
+// MSP if chainID is empty
+func (s *mspMessageCryptoService) evictValidatedBy(chainID common.ChainID) {
+	if s.identityCache == nil {
+		return
+	}
+	for key := range s.identityCache.tracked() {
+		value, ok, err := s.identityCache.backend.Get(key)
+		if err != nil {
+			logger.Warningf("Failed looking up identity cache: [%s]", err)
+			continue
+		}
+		if !ok {
+			s.identityCache.untrack(key)
+			continue
+		}
+		entry, err := s.identityCache.open(key, value)
+		if err == nil && string(entry.chainID) != string(chainID) {
+			continue
+		}
+
+		if err := s.identityCache.backend.Evict(key); err != nil {
+			logger.Warningf("Failed evicting from identity cache: [%s]", err)
+			continue
+		}
+		s.identityCache.untrack(key)
+	}
+}
+
+// configUpdateCounter counts the configuration
+// updates of each channel. The zero value is usable.
+type configUpdateCounter struct {
+	lock    sync.Mutex
+	updates map[string]uint64
+}
+
+func (c *configUpdateCounter) increment(chainID common.ChainID) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.updates == nil {
+		c.updates = make(map[string]uint64)
+	}
+	c.updates[string(chainID)]++
+}
+
+// get returns the number of configuration updates of channel chainID
+func (c *configUpdateCounter) get(chainID common.ChainID) uint64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.updates[string(chainID)]
+}
+
+// RevalidateAll validates again, against the current MSPs, the identities
+// in the cache of validated identities, and evicts those no longer valid.
+// It returns the number of evicted identities. This allows flushing, for
+// instance, the identities revoked by a configuration update without
+// waiting for their entries to expire.
+// Only the identities this service stored or found in the cache are
+// revalidated. Verifications are not blocked meanwhile: an identity
+// being revalidated may still be accepted by a concurrent verification.
+// Errors of the cache do not stop the revalidation, the first one
+// is returned.
+func (s *mspMessageCryptoService) RevalidateAll() (invalidated int, err error) {
+	if s.identityCache == nil {
+		return 0, errors.New("No identity cache configured")
+	}
+
+	for key, peerIdentity := range s.identityCache.tracked() {
+		_, ok, getErr := s.identityCache.backend.Get(key)
+		if getErr != nil {
+			if err == nil {
+				err = getErr
+			}
+			continue
+		}
+		if !ok {
+			// Evicted or expired meanwhile
+			s.identityCache.untrack(key)
+			continue
+		}
+
+		_, _, validationErr := s.validateIdentity(logger, peerIdentity)
+		if validationErr == nil {
+			validationErr = s.checkNotBefore(peerIdentity)
+		}
+		if validationErr == nil {
+			continue
+		}
+
+		logger.Infof("Evicting peer identity [% x] from identity cache: [%s]", []byte(peerIdentity), validationErr)
+		if evictErr := s.identityCache.backend.Evict(key); evictErr != nil {
+			if err == nil {
+				err = evictErr
+			}
+			continue
+		}
+		s.identityCache.untrack(key)
+		invalidated++
+	}
+
+	return invalidated, err
+}
+
+// getCachedIdentity returns the identity of peerIdentity and the
+// channel whose MSP validated it, if a valid cache entry exists
+func (s *mspMessageCryptoService) getCachedIdentity(log *logging.Logger, peerIdentity api.PeerIdentityType) (msp.Identity, common.ChainID, bool) {
+	if s.identityCache == nil || len(peerIdentity) == 0 {
+		return nil, nil, false
+	}
+
+	key := hex.EncodeToString(s.GetPKIidOfCert(peerIdentity))
+	value, ok, err := s.identityCache.backend.Get(key)
+	if err != nil {
+		log.Warningf("Failed looking up identity cache: [%s]", err)
+		return nil, nil, false
+	}
+	if !ok {
+		s.identityCache.untrack(key)
+		return nil, nil, false
+	}
+
+	identity, chainID, err := s.decodeCachedIdentity(key, peerIdentity, value)
+	if err != nil {
+		log.Debugf("Discarding cache entry of peer identity [% x]: [%s]", []byte(peerIdentity), err)
+		if err := s.identityCache.backend.Evict(key); err != nil {
+			log.Warningf("Failed evicting from identity cache: [%s]", err)
+		}
+		s.identityCache.untrack(key)
+		return nil, nil, false
+	}
+	s.identityCache.track(key, peerIdentity)
+
+	return identity, chainID, true
+}
+
+// decodeCachedIdentity deserializes peerIdentity as described
+// by value, the content of its cache entry stored under key
+func (s *mspMessageCryptoService) decodeCachedIdentity(key string, peerIdentity api.PeerIdentityType, value []byte) (msp.Identity, common.ChainID, error) {
+	entry, err := s.identityCache.open(key, value)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !time.Now().Before(entry.expiresAt) {
+		return nil, nil, errors.New("Expired entry")
+	}
+	chainID := entry.chainID
+	if s.configUpdates.get(chainID) != entry.configUpdates {
+		return nil, nil, errors.New("Entry of a previous configuration")
+	}
+
+	var identity msp.Identity
+	if len(chainID) == 0 {
+		var ok bool
+		if identity, ok = s.deserializeLocally(logger, peerIdentity); !ok {
+			return nil, nil, errors.New("Not a local identity")
+		}
+	} else {
+		mspManager := mgmt.GetManagerForChainIfExists(string(chainID))
+		if mspManager == nil {
+			return nil, nil, errors.New("Unknown channel")
+		}
+		var err error
+		if identity, err = mspManager.DeserializeIdentity(peerIdentity); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if cert, err := getCertificate(peerIdentity); err == nil && !time.Now().Before(cert.NotAfter) {
+		return nil, nil, errors.New("Expired certificate")
+	}
+
+	return identity, chainID, nil
+}
+
+// cacheIdentity records that peerIdentity has
+// been validated by the MSP of channel chainID
+func (s *mspMessageCryptoService) cacheIdentity(log *logging.Logger, peerIdentity api.PeerIdentityType, chainID common.ChainID) {
+	if s.identityCache == nil {
+		return
+	}
+
+	key := hex.EncodeToString(s.GetPKIidOfCert(peerIdentity))
+	value := s.identityCache.seal(key, identityCacheEntry{
+		expiresAt:     time.Now().Add(s.identityCache.ttl),
+		configUpdates: s.configUpdates.get(chainID),
+		chainID:       chainID,
+	})
+	if err := s.identityCache.backend.Set(key, value); err != nil {
+		log.Warningf("Failed storing into identity cache: [%s]", err)
+		return
+	}
+	s.identityCache.track(key, peerIdentity)
+}
+
+// identityCacheEntry is the content of an entry of the identity cache
+type identityCacheEntry struct {
+	expiresAt time.Time
+	// configUpdates is the number of configuration updates
+	// of the MSP that validated the identity, when it did
+	configUpdates uint64
+	// chainID is the channel whose MSP validated
+	// the identity, or nil for the local MSP
+	chainID common.ChainID
+}
+
+// seal returns the value of entry, stored under key. A value is the
+// expiration time of the entry, the number of configuration updates
+// and the channel, followed by an HMAC binding them to key.
+func (c *identityCache) seal(key string, entry identityCacheEntry) []byte {
+	value := make([]byte, 16, 16+len(entry.chainID)+sha256.Size)
+	binary.BigEndian.PutUint64(value, uint64(entry.expiresAt.UnixNano()))
+	binary.BigEndian.PutUint64(value[8:], entry.configUpdates)
+	value = append(value, entry.chainID...)
+	return append(value, c.mac(key, value)...)
+}
+
+// open returns the entry of value, stored under key,
+// if value has been sealed by this cache
+func (c *identityCache) open(key string, value []byte) (identityCacheEntry, error) {
+	if len(value) < 16+sha256.Size {
+		return identityCacheEntry{}, errors.New("Malformed entry")
+	}
+	content, tag := value[:len(value)-sha256.Size], value[len(value)-sha256.Size:]
+	if !hmac.Equal(tag, c.mac(key, content)) {
+		return identityCacheEntry{}, errors.New("Unauthenticated entry")
+	}
+	return identityCacheEntry{
+		expiresAt:     time.Unix(0, int64(binary.BigEndian.Uint64(content[:8]))),
+		configUpdates: binary.BigEndian.Uint64(content[8:16]),
+		chainID:       common.ChainID(content[16:]),
+	}, nil
+}
+
+func (c *identityCache) mac(key string, content []byte) []byte {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write([]byte(key))
+	mac.Write([]byte{0})
+	mac.Write(content)
+	return mac.Sum(nil)
+}
+
+// lruIdentityCache is an in-memory IdentityCache
+// evicting the least recently used entries
+type lruIdentityCache struct {
+	size int
+
+	lock    sync.Mutex
+	entries *list.List
+	index   map[string]*list.Element
+}
+
+type lruEntry struct {
+	key   string
+	value []byte
+}
+
+// NewLRUIdentityCache returns an in-memory IdentityCache holding
+// at most size entries. When full, the least recently used
+// entry is evicted to make room for a new one.
+func NewLRUIdentityCache(size int) IdentityCache {
+	if size < 1 {
+		size = 1
+	}
+	return &lruIdentityCache{
+		size:    size,
+		entries: list.New(),
+		index:   make(map[string]*list.Element),
+	}
+}
+
+func (c *lruIdentityCache) Get(key string) ([]byte, bool, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	e, ok := c.index[key]
+	if !ok {
+		return nil, false, nil
+	}
+	c.entries.MoveToFront(e)
+
+	return e.Value.(*lruEntry).value, true, nil
+}
+
+func (c *lruIdentityCache) Set(key string, value []byte) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if e, ok := c.index[key]; ok {
+		e.Value.(*lruEntry).value = value
+		c.entries.MoveToFront(e)
+		return nil
+	}
+
+	c.index[key] = c.entries.PushFront(&lruEntry{key: key, value: value})
+	if c.entries.Len() > c.size {
+		oldest := c.entries.Back()
+		c.entries.Remove(oldest)
+		delete(c.index, oldest.Value.(*lruEntry).key)
+	}
+
+	return nil
+}
+
+func (c *lruIdentityCache) Evict(key string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if e, ok := c.index[key]; ok {
+		c.entries.Remove(e)
+		delete(c.index, key)
+	}
+
+	return nil
+}
@@ -0,0 +1,303 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcs
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	mockpolicies "github.com/hyperledger/fabric/common/mocks/policies"
+	"github.com/hyperledger/fabric/gossip/api"
+	"github.com/hyperledger/fabric/gossip/common"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/msp/mgmt"
+	"github.com/stretchr/testify/assert"
+)
+
+// failingIdentityCache is an IdentityCache whose operations all fail
+type failingIdentityCache struct{}
+
+func (c *failingIdentityCache) Get(key string) ([]byte, bool, error) {
+	return nil, false, errors.New("unavailable")
+}
+
+func (c *failingIdentityCache) Set(key string, value []byte) error {
+	return errors.New("unavailable")
+}
+
+func (c *failingIdentityCache) Evict(key string) error {
+	return errors.New("unavailable")
+}
+
+func TestLRUIdentityCache(t *testing.T) {
+	cache := NewLRUIdentityCache(2)
+
+	_, ok, err := cache.Get("a")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	assert.NoError(t, cache.Set("a", []byte("1")))
+	assert.NoError(t, cache.Set("b", []byte("2")))
+	value, ok, err := cache.Get("a")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("1"), value)
+
+	// b is the least recently used
+	assert.NoError(t, cache.Set("c", []byte("3")))
+	_, ok, _ = cache.Get("b")
+	assert.False(t, ok)
+	_, ok, _ = cache.Get("a")
+	assert.True(t, ok)
+	_, ok, _ = cache.Get("c")
+	assert.True(t, ok)
+
+	assert.NoError(t, cache.Set("a", []byte("4")))
+	value, _, _ = cache.Get("a")
+	assert.Equal(t, []byte("4"), value)
+
+	assert.NoError(t, cache.Evict("a"))
+	assert.NoError(t, cache.Evict("a"))
+	_, ok, _ = cache.Get("a")
+	assert.False(t, ok)
+}
+
+func TestIdentityCache(t *testing.T) {
+	m := setupMockChannel("cachechannel", "CacheOrg")
+	peerIdentity := newMockPeerIdentity("CacheOrg", "peer0")
+
+	// Cached identities are not validated again until their entry expires
+	cache := NewLRUIdentityCache(10)
+	mcs := New(&mockpolicies.PolicyManagerMgmt{}, WithIdentityCache(cache, 100*time.Millisecond))
+	assert.NoError(t, mcs.ValidateIdentity(peerIdentity))
+	m.validateErrs["peer0"] = errors.New("revoked")
+	assert.NoError(t, mcs.ValidateIdentity(peerIdentity))
+	time.Sleep(150 * time.Millisecond)
+	assert.Error(t, mcs.ValidateIdentity(peerIdentity))
+
+	// Invalid identities are not cached
+	delete(m.validateErrs, "peer0")
+	assert.NoError(t, mcs.ValidateIdentity(peerIdentity))
+
+	// Without cache
+	mcs = New(&mockpolicies.PolicyManagerMgmt{})
+	assert.NoError(t, mcs.ValidateIdentity(peerIdentity))
+	m.validateErrs["peer0"] = errors.New("revoked")
+	assert.Error(t, mcs.ValidateIdentity(peerIdentity))
+	delete(m.validateErrs, "peer0")
+
+	// Corrupted entries are discarded
+	mcs = New(&mockpolicies.PolicyManagerMgmt{}, WithIdentityCache(cache, time.Hour))
+	key := "garbage"
+	for k := range cache.(*lruIdentityCache).index {
+		key = k
+	}
+	assert.NoError(t, cache.Set(key, []byte("garbage")))
+	m.validateErrs["peer0"] = errors.New("revoked")
+	assert.Error(t, mcs.ValidateIdentity(peerIdentity))
+	delete(m.validateErrs, "peer0")
+
+	// Entries altered in the backend, or stored by another service, are discarded
+	mcs = New(&mockpolicies.PolicyManagerMgmt{}, WithIdentityCache(cache, time.Hour))
+	assert.NoError(t, mcs.ValidateIdentity(peerIdentity))
+	value, ok, _ := cache.Get(key)
+	assert.True(t, ok)
+	tampered := append([]byte{}, value...)
+	tampered[0] ^= 0xff
+	assert.NoError(t, cache.Set(key, tampered))
+	m.validateErrs["peer0"] = errors.New("revoked")
+	assert.Error(t, mcs.ValidateIdentity(peerIdentity))
+	delete(m.validateErrs, "peer0")
+	other := New(&mockpolicies.PolicyManagerMgmt{}, WithIdentityCache(cache, time.Hour))
+	assert.NoError(t, other.ValidateIdentity(peerIdentity))
+	m.validateErrs["peer0"] = errors.New("revoked")
+	assert.Error(t, mcs.ValidateIdentity(peerIdentity))
+	delete(m.validateErrs, "peer0")
+
+	// A failing backend degrades to full validation
+	mcs = New(&mockpolicies.PolicyManagerMgmt{}, WithIdentityCache(&failingIdentityCache{}, time.Hour))
+	assert.NoError(t, mcs.ValidateIdentity(peerIdentity))
+	m.validateErrs["peer0"] = errors.New("revoked")
+	assert.Error(t, mcs.ValidateIdentity(peerIdentity))
+	delete(m.validateErrs, "peer0")
+}
+
+func TestRevalidateAll(t *testing.T) {
+	m := setupMockChannel("revalidatechannel", "RevalidateOrg")
+	peer0 := newMockPeerIdentity("RevalidateOrg", "peer0")
+	peer1 := newMockPeerIdentity("RevalidateOrg", "peer1")
+	peer2 := newMockPeerIdentity("RevalidateOrg", "peer2")
+
+	// Without cache
+	_, err := New(&mockpolicies.PolicyManagerMgmt{}).(*mspMessageCryptoService).RevalidateAll()
+	assert.Error(t, err)
+
+	cache := NewLRUIdentityCache(10)
+	mcs := New(&mockpolicies.PolicyManagerMgmt{}, WithIdentityCache(cache, time.Hour)).(*mspMessageCryptoService)
+	for _, peerIdentity := range []api.PeerIdentityType{peer0, peer1, peer2} {
+		assert.NoError(t, mcs.ValidateIdentity(peerIdentity))
+	}
+
+	invalidated, err := mcs.RevalidateAll()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, invalidated)
+
+	// peer0 and peer1 are revoked by a config update
+	m.validateErrs["peer0"] = errors.New("revoked")
+	m.validateErrs["peer1"] = errors.New("revoked")
+	assert.NoError(t, mcs.ValidateIdentity(peer0), "Still cached")
+
+	invalidated, err = mcs.RevalidateAll()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, invalidated)
+	assert.Error(t, mcs.ValidateIdentity(peer0))
+	assert.Error(t, mcs.ValidateIdentity(peer1))
+	assert.NoError(t, mcs.ValidateIdentity(peer2))
+
+	// Evicted entries are not counted again
+	invalidated, err = mcs.RevalidateAll()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, invalidated)
+
+	// Entries that left the backend meanwhile are skipped
+	delete(m.validateErrs, "peer0")
+	assert.NoError(t, mcs.ValidateIdentity(peer0))
+	for key := range cache.(*lruIdentityCache).index {
+		assert.NoError(t, cache.Evict(key))
+	}
+	m.validateErrs["peer0"] = errors.New("revoked")
+	invalidated, err = mcs.RevalidateAll()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, invalidated)
+	assert.Empty(t, mcs.identityCache.tracked())
+	delete(m.validateErrs, "peer0")
+	delete(m.validateErrs, "peer1")
+
+	// A failing backend
+	mcs = New(&mockpolicies.PolicyManagerMgmt{}, WithIdentityCache(&failingIdentityCache{}, time.Hour)).(*mspMessageCryptoService)
+	mcs.identityCache.track("key", peer0)
+	_, err = mcs.RevalidateAll()
+	assert.Error(t, err)
+}
+
+func TestRevalidateAllConcurrently(t *testing.T) {
+	setupMockChannel("revalidateconcurrentchannel", "RevalidateConcurrentOrg")
+	mcs := New(&mockpolicies.PolicyManagerMgmt{}, WithIdentityCache(NewLRUIdentityCache(5), time.Hour)).(*mspMessageCryptoService)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 200; i++ {
+			assert.NoError(t, mcs.ValidateIdentity(newMockPeerIdentity("RevalidateConcurrentOrg", fmt.Sprintf("peer%d", i%10))))
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			invalidated, err := mcs.RevalidateAll()
+			assert.NoError(t, err)
+			assert.Equal(t, 0, invalidated)
+		}
+	}()
+	wg.Wait()
+}
+
+// delegatingMSPManager is an MSP manager delegating to another one
+type delegatingMSPManager struct {
+	msp.MSPManager
+}
+
+func TestChannelConfigUpdated(t *testing.T) {
+	m1 := setupMockChannel("configupdate1", "ConfigUpdateOrg1")
+	m2 := setupMockChannel("configupdate2", "ConfigUpdateOrg2")
+	// Config updates replace the manager the
+	// registered MSP manager delegates to
+	channel1 := &delegatingMSPManager{MSPManager: mgmt.GetManagerForChain("configupdate1")}
+	mgmt.XXXSetMSPManager("configupdate1", channel1)
+	peer1 := newMockPeerIdentity("ConfigUpdateOrg1", "peer0")
+	peer2 := newMockPeerIdentity("ConfigUpdateOrg2", "peer0")
+
+	mcs := New(&mockpolicies.PolicyManagerMgmt{},
+		WithIdentityCache(NewLRUIdentityCache(10), time.Hour),
+		WithNegativeMembershipCache(time.Hour)).(*mspMessageCryptoService)
+	assert.NoError(t, mcs.ValidateIdentity(peer1))
+	assert.NoError(t, mcs.ValidateIdentity(peer2))
+	assert.Error(t, mcs.ValidateIdentityForChannel(common.ChainID("configupdate1"), peer2))
+
+	// A config update of the first channel revokes
+	// peer1, and adds the organization of peer2
+	m1.validateErrs["peer0"] = errors.New("revoked")
+	m2.validateErrs["peer0"] = errors.New("revoked")
+	mspManager := msp.NewMSPManager()
+	assert.NoError(t, mspManager.Setup([]msp.MSP{m1, &mockMSP{id: "ConfigUpdateOrg2", validateErrs: map[string]error{}}}))
+	channel1.MSPManager = mspManager
+	assert.NoError(t, mcs.ValidateIdentity(peer1), "Still cached")
+	assert.Error(t, mcs.ValidateIdentityForChannel(common.ChainID("configupdate1"), peer2), "Still cached")
+
+	// Entries are discarded on configuration updates even if not
+	// tracked anymore, as they are bound to the configuration
+	mcs.identityCache.untrack(hex.EncodeToString(mcs.GetPKIidOfCert(peer1)))
+	mcs.ChannelConfigUpdated(common.ChainID("configupdate1"))
+	assert.Error(t, mcs.ValidateIdentity(peer1))
+	assert.NoError(t, mcs.ValidateIdentityForChannel(common.ChainID("configupdate1"), peer2))
+	// The identities validated by other channels are not affected
+	assert.NoError(t, mcs.ValidateIdentity(peer2))
+
+	// Without caches
+	New(&mockpolicies.PolicyManagerMgmt{}).(*mspMessageCryptoService).ChannelConfigUpdated(common.ChainID("configupdate1"))
+}
+
+func TestLocalMSPUpdated(t *testing.T) {
+	setupMockChannel("localmspupdate", "LocalMSPUpdateOrg")
+	cache := NewLRUIdentityCache(10)
+	mcs := New(&mockpolicies.PolicyManagerMgmt{}, WithIdentityCache(cache, time.Hour)).(*mspMessageCryptoService)
+
+	// An identity validated by the local MSP
+	local := newMockPeerIdentity("LocalOrg", "peer0")
+	mcs.cacheIdentity(logger, local, nil)
+	// An identity validated by the MSP of a channel
+	peer := newMockPeerIdentity("LocalMSPUpdateOrg", "peer0")
+	assert.NoError(t, mcs.ValidateIdentity(peer))
+
+	mcs.LocalMSPUpdated()
+	_, ok, err := cache.Get(hex.EncodeToString(mcs.GetPKIidOfCert(local)))
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	_, ok, err = cache.Get(hex.EncodeToString(mcs.GetPKIidOfCert(peer)))
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// Without caches
+	New(&mockpolicies.PolicyManagerMgmt{}).(*mspMessageCryptoService).LocalMSPUpdated()
+}
+
+func TestIdentityCacheTrackingBound(t *testing.T) {
+	mcs := New(&mockpolicies.PolicyManagerMgmt{}, WithIdentityCache(NewLRUIdentityCache(10), time.Hour)).(*mspMessageCryptoService)
+	for i := 0; i < maxTrackedIdentities+10; i++ {
+		mcs.identityCache.track(fmt.Sprintf("key%d", i), nil)
+	}
+	assert.Len(t, mcs.identityCache.tracked(), maxTrackedIdentities)
+
+	// Tracking a tracked identity again does not evict others
+	mcs.identityCache.track(fmt.Sprintf("key%d", maxTrackedIdentities+9), nil)
+	assert.Len(t, mcs.identityCache.tracked(), maxTrackedIdentities)
+}
@@ -0,0 +1,98 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcs
+
+import (
+	"github.com/hyperledger/fabric/gossip/api"
+	"github.com/hyperledger/fabric/gossip/common"
+	"github.com/op/go-logging"
+)
+
+// ChannelState is the state of the membership
+// of this peer in a channel
+type ChannelState int
+
+const (
+	// ChannelStable is the state of a channel this peer
+	// has joined, or has not joined, and is not leaving
+	ChannelStable ChannelState = iota
+	// ChannelJoining is the state of a channel this peer is
+	// joining: its configuration may not be complete yet
+	ChannelJoining
+	// ChannelLeaving is the state of a channel this peer is
+	// leaving: its configuration may be torn down already
+	ChannelLeaving
+)
+
+func (st ChannelState) String() string {
+	switch st {
+	case ChannelStable:
+		return "stable"
+	case ChannelJoining:
+		return "joining"
+	case ChannelLeaving:
+		return "leaving"
+	default:
+		return "unknown"
+	}
+}
+
+// ChannelStateProvider returns the state of channel chainID
+type ChannelStateProvider func(chainID common.ChainID) ChannelState
+
+// WithChannelStateGrace makes VerifyByChannel accept, with a warning, the
+// signatures that fail the policies of a channel that provider reports as
+// joining or leaving, provided that the signer belongs to the organization
+// of a local MSP, which validates it, and the signature is valid under its
+// key. This avoids rejecting the gossip of the peers of this organization
+// spuriously while the configuration of a channel is in flux. The signers
+// of other organizations, which only the MSPs of the channels can vouch
+// for, remain subject to the channel's policies.
+// Without this option, channel policies are always enforced.
+func WithChannelStateGrace(provider ChannelStateProvider) Option {
+	return func(s *mspMessageCryptoService) {
+		s.channelState = provider
+	}
+}
+
+// applyTransitionGrace returns nil if the failure cause of the
+// verification of signature against the policies of channel chainID
+// is to be overlooked because the channel is in a transitional state,
+// else cause is returned
+func (s *mspMessageCryptoService) applyTransitionGrace(log *logging.Logger, chainID common.ChainID, peerIdentity api.PeerIdentityType, signature, message []byte, cause error) error {
+	if s.channelState == nil {
+		return cause
+	}
+
+	state := s.channelState(s.normalizeChannel(chainID))
+	if state != ChannelJoining && state != ChannelLeaving {
+		return cause
+	}
+
+	// The signer must be validated by a local MSP, rather than by
+	// the MSPs of a channel, and the signature itself must be valid
+	identity, validatedBy, err := s.getValidatedIdentity(log, peerIdentity)
+	if err != nil || len(validatedBy) != 0 {
+		return cause
+	}
+	if err := identity.Verify(message, signature); err != nil {
+		return cause
+	}
+
+	log.Warningf("Accepting signature of peer identity [% x] on channel [%s] in state [%s] despite: [%s]", []byte(peerIdentity), string(chainID), state, cause)
+	return nil
+}
@@ -0,0 +1,76 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcs
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/gossip/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChannelStateGrace(t *testing.T) {
+	setupMockChannel("statechannel", "StateOrg")
+	states := map[string]ChannelState{
+		"joiningchannel": ChannelJoining,
+		"leavingchannel": ChannelLeaving,
+		"stablechannel":  ChannelStable,
+	}
+	provider := func(chainID common.ChainID) ChannelState {
+		return states[string(chainID)]
+	}
+
+	// The policies of all the channels reject everything
+	pm := newMockPolicyManager()
+	for chainID := range states {
+		pm.setPolicy(chainID, policies.ChannelApplicationReaders, &mockRejectPolicy{})
+	}
+	peer := newMockPeerIdentity("StateOrg", "peer0")
+	msg := []byte("msg")
+
+	// Enforced by default
+	mcs := New(pm)
+	for chainID := range states {
+		assert.Error(t, mcs.VerifyByChannel(common.ChainID(chainID), peer, mockSign(msg), msg))
+	}
+
+	local := &mockMSP{id: "LocalStateOrg", validateErrs: make(map[string]error)}
+	localPeer := newMockPeerIdentity("LocalStateOrg", "peer0")
+	mcs = New(pm, WithChannelStateGrace(provider), WithLocalMSPs(local))
+	assert.NoError(t, mcs.VerifyByChannel(common.ChainID("joiningchannel"), localPeer, mockSign(msg), msg))
+	assert.NoError(t, mcs.VerifyByChannel(common.ChainID("leavingchannel"), localPeer, mockSign(msg), msg))
+	assert.Error(t, mcs.VerifyByChannel(common.ChainID("stablechannel"), localPeer, mockSign(msg), msg))
+
+	// Only the signers of local organizations are granted the grace
+	assert.Error(t, mcs.VerifyByChannel(common.ChainID("joiningchannel"), peer, mockSign(msg), msg))
+	assert.Error(t, mcs.VerifyByChannel(common.ChainID("leavingchannel"), peer, mockSign(msg), msg))
+
+	// A channel whose configuration is not there yet
+	states["newchannel"] = ChannelJoining
+	assert.NoError(t, mcs.VerifyByChannel(common.ChainID("newchannel"), localPeer, mockSign(msg), msg))
+
+	// Invalid signatures and identities are rejected regardless
+	assert.Error(t, mcs.VerifyByChannel(common.ChainID("joiningchannel"), localPeer, []byte("bad signature"), msg))
+	assert.Error(t, mcs.VerifyByChannel(common.ChainID("joiningchannel"), newMockPeerIdentity("UnknownOrg", "peer0"), mockSign(msg), msg))
+	assert.Error(t, mcs.VerifyByChannel(common.ChainID("joiningchannel"), nil, mockSign(msg), msg))
+
+	assert.Equal(t, "joining", ChannelJoining.String())
+	assert.Equal(t, "leaving", ChannelLeaving.String())
+	assert.Equal(t, "stable", ChannelStable.String())
+	assert.Equal(t, "unknown", ChannelState(42).String())
+}
@@ -0,0 +1,87 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcs
+
+import (
+	"errors"
+
+	"github.com/hyperledger/fabric/gossip/api"
+	"github.com/hyperledger/fabric/gossip/common"
+)
+
+// ConfigSequenceProvider returns the sequence number of the
+// current configuration of channel chainID.
+// See fabric/common/configtx/api/api.go#Manager
+type ConfigSequenceProvider func(chainID common.ChainID) (uint64, error)
+
+// WithConfigSequence enables VerifyAtConfigSeq, reading the current
+// configuration sequence of channels from provider. If rejectMoved is
+// true, verifications performed under a configuration other than the
+// expected one fail with a *ConfigMovedError, otherwise a warning is
+// logged and the outcome of the verification is returned.
+func WithConfigSequence(provider ConfigSequenceProvider, rejectMoved bool) Option {
+	return func(s *mspMessageCryptoService) {
+		s.configSequence = provider
+		s.rejectConfigMoved = rejectMoved
+	}
+}
+
+// VerifyAtConfigSeq verifies, as VerifyByChannel does, that signature is
+// a valid signature of message by peerIdentity under the read policy of
+// channel chainID, and that the configuration of the channel was the one
+// with sequence number expectedSeq throughout the verification.
+// This lets callers ensure a set of related verifications all happened
+// under the same configuration.
+// It requires the WithConfigSequence option.
+func (s *mspMessageCryptoService) VerifyAtConfigSeq(chainID common.ChainID, expectedSeq uint64, peerIdentity api.PeerIdentityType, signature, message []byte) error {
+	if s.configSequence == nil {
+		return errors.New("No configuration sequence provider configured")
+	}
+
+	if err := s.checkConfigSeq(chainID, expectedSeq); err != nil {
+		return err
+	}
+
+	if err := s.verifyByChannel(logger, chainID, peerIdentity, signature, message); err != nil {
+		return err
+	}
+
+	// The configuration may have been updated during the verification
+	return s.checkConfigSeq(chainID, expectedSeq)
+}
+
+// checkConfigSeq checks that the configuration of channel chainID
+// has sequence number expectedSeq. A mismatch is an error only if
+// the option to reject it is set.
+func (s *mspMessageCryptoService) checkConfigSeq(chainID common.ChainID, expectedSeq uint64) error {
+	currentSeq, err := s.configSequence(s.normalizeChannel(chainID))
+	if err != nil {
+		return err
+	}
+
+	if currentSeq == expectedSeq {
+		return nil
+	}
+
+	moved := &ConfigMovedError{ChainID: chainID, Expected: expectedSeq, Current: currentSeq}
+	if s.rejectConfigMoved {
+		return moved
+	}
+	logger.Warningf("%s", moved)
+
+	return nil
+}
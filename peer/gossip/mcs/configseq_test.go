@@ -0,0 +1,94 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcs
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/gossip/common"
+	protoscommon "github.com/hyperledger/fabric/protos/common"
+	"github.com/stretchr/testify/assert"
+)
+
+// bumpingPolicy is a policy that increments
+// a configuration sequence when evaluated
+type bumpingPolicy struct {
+	mockChannelPolicy
+	seq *uint64
+}
+
+func (p *bumpingPolicy) Evaluate(signatureSet []*protoscommon.SignedData) error {
+	atomic.AddUint64(p.seq, 1)
+	return p.mockChannelPolicy.Evaluate(signatureSet)
+}
+
+func TestVerifyAtConfigSeq(t *testing.T) {
+	chainID := "configseqchannel"
+	setupMockChannel(chainID, "SeqOrg")
+	var seq uint64 = 3
+	provider := func(c common.ChainID) (uint64, error) {
+		if string(c) != chainID {
+			return 0, errors.New("unknown channel")
+		}
+		return atomic.LoadUint64(&seq), nil
+	}
+	pm := newMockPolicyManager()
+	pm.setPolicy(chainID, policies.ChannelApplicationReaders, &mockChannelPolicy{chainID: chainID})
+
+	peer := newMockPeerIdentity("SeqOrg", "peer0")
+	msg := []byte("msg")
+
+	// Not configured
+	mcs := New(pm).(*mspMessageCryptoService)
+	assert.Error(t, mcs.VerifyAtConfigSeq(common.ChainID(chainID), 3, peer, mockSign(msg), msg))
+
+	// Rejecting
+	mcs = New(pm, WithConfigSequence(provider, true)).(*mspMessageCryptoService)
+	assert.NoError(t, mcs.VerifyAtConfigSeq(common.ChainID(chainID), 3, peer, mockSign(msg), msg))
+	assert.Error(t, mcs.VerifyAtConfigSeq(common.ChainID(chainID), 3, peer, []byte("bad signature"), msg))
+	err := mcs.VerifyAtConfigSeq(common.ChainID(chainID), 2, peer, mockSign(msg), msg)
+	assert.IsType(t, &ConfigMovedError{}, err)
+	assert.Equal(t, uint64(2), err.(*ConfigMovedError).Expected)
+	assert.Equal(t, uint64(3), err.(*ConfigMovedError).Current)
+	assert.Error(t, mcs.VerifyAtConfigSeq(common.ChainID("otherchannel"), 3, peer, mockSign(msg), msg))
+
+	// Warning only
+	mcs = New(pm, WithConfigSequence(provider, false)).(*mspMessageCryptoService)
+	assert.NoError(t, mcs.VerifyAtConfigSeq(common.ChainID(chainID), 2, peer, mockSign(msg), msg))
+	assert.Error(t, mcs.VerifyAtConfigSeq(common.ChainID(chainID), 2, peer, []byte("bad signature"), msg))
+}
+
+func TestVerifyAtConfigSeqMovedDuringVerification(t *testing.T) {
+	chainID := "configseqmovechannel"
+	setupMockChannel(chainID, "SeqOrg")
+	var seq uint64 = 7
+	pm := newMockPolicyManager()
+	pm.setPolicy(chainID, policies.ChannelApplicationReaders, &bumpingPolicy{mockChannelPolicy{chainID: chainID}, &seq})
+	provider := func(common.ChainID) (uint64, error) {
+		return atomic.LoadUint64(&seq), nil
+	}
+	mcs := New(pm, WithConfigSequence(provider, true)).(*mspMessageCryptoService)
+
+	msg := []byte("msg")
+	err := mcs.VerifyAtConfigSeq(common.ChainID(chainID), 7, newMockPeerIdentity("SeqOrg", "peer0"), mockSign(msg), msg)
+	assert.IsType(t, &ConfigMovedError{}, err)
+	assert.Equal(t, uint64(8), err.(*ConfigMovedError).Current)
+
+}
@@ -0,0 +1,168 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcs
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric/msp"
+)
+
+const (
+	// crlFetchTimeout is the maximum time taken to fetch a CRL
+	crlFetchTimeout = 10 * time.Second
+
+	// maxCRLSize is the maximum size of a fetched CRL
+	maxCRLSize = 16 * 1024 * 1024
+)
+
+// cachedCRL is a CRL along with the time it was fetched at
+type cachedCRL struct {
+	crl       *pkix.CertificateList
+	fetchedAt time.Time
+}
+
+// crlDistributionPointChecker is an OnlineRevocationChecker that checks
+// x.509 identities against the CRLs published at the distribution points
+// listed in their certificates
+type crlDistributionPointChecker struct {
+	issuers []*x509.Certificate
+	ttl     time.Duration
+	client  *http.Client
+
+	lock sync.Mutex
+	crls map[string]*cachedCRL
+}
+
+// NewCRLDistributionPointChecker returns an OnlineRevocationChecker that
+// fetches the CRLs listed in the CRL distribution points extension of the
+// certificates of identities, and checks the identities against them.
+// A fetched CRL is accepted only if it is signed by the issuer of the
+// certificate, which must be one of issuers, and it is cached for ttl.
+// Identities that are not x.509 identities, or whose certificates list no
+// distribution point, are not checked.
+func NewCRLDistributionPointChecker(issuers []*x509.Certificate, ttl time.Duration) OnlineRevocationChecker {
+	return &crlDistributionPointChecker{
+		issuers: issuers,
+		ttl:     ttl,
+		client:  &http.Client{Timeout: crlFetchTimeout},
+		crls:    make(map[string]*cachedCRL),
+	}
+}
+
+// CheckRevocation returns nil if identity is not revoked.
+// It returns an error if identity is revoked or if its
+// revocation status cannot be determined.
+func (c *crlDistributionPointChecker) CheckRevocation(identity msp.Identity) error {
+	raw, err := identity.Serialize()
+	if err != nil {
+		return fmt.Errorf("Failed serializing identity [%s]", err)
+	}
+	cert, err := getCertificate(raw)
+	if err != nil || len(cert.CRLDistributionPoints) == 0 {
+		return nil
+	}
+
+	issuer := c.getIssuer(cert)
+	if issuer == nil {
+		return errors.New("Revocation status cannot be determined: the issuer of the certificate is not trusted")
+	}
+
+	for _, url := range cert.CRLDistributionPoints {
+		crl, err := c.getCRL(url)
+		if err != nil {
+			return fmt.Errorf("Revocation status cannot be determined: failed getting CRL from [%s]: [%s]", url, err)
+		}
+		if err := issuer.CheckCRLSignature(crl); err != nil {
+			return fmt.Errorf("Revocation status cannot be determined: CRL from [%s] is not signed by the issuer of the certificate: [%s]", url, err)
+		}
+		if crl.HasExpired(time.Now()) {
+			return fmt.Errorf("Revocation status cannot be determined: CRL from [%s] has expired", url)
+		}
+
+		for _, revoked := range crl.TBSCertList.RevokedCertificates {
+			if revoked.SerialNumber.Cmp(cert.SerialNumber) == 0 {
+				return fmt.Errorf("Certificate with serial number [%s] has been revoked", cert.SerialNumber)
+			}
+		}
+	}
+
+	return nil
+}
+
+// getIssuer returns the one of the trusted issuers that issued cert, if any
+func (c *crlDistributionPointChecker) getIssuer(cert *x509.Certificate) *x509.Certificate {
+	for _, issuer := range c.issuers {
+		if cert.CheckSignatureFrom(issuer) == nil {
+			return issuer
+		}
+	}
+	return nil
+}
+
+// getCRL returns the CRL published at url,
+// fetching it if it is not cached
+func (c *crlDistributionPointChecker) getCRL(url string) (*pkix.CertificateList, error) {
+	c.lock.Lock()
+	cached, exists := c.crls[url]
+	c.lock.Unlock()
+	if exists && time.Since(cached.fetchedAt) < c.ttl {
+		return cached.crl, nil
+	}
+
+	crl, err := c.fetchCRL(url)
+	if err != nil {
+		return nil, err
+	}
+
+	c.lock.Lock()
+	c.crls[url] = &cachedCRL{crl: crl, fetchedAt: time.Now()}
+	c.lock.Unlock()
+
+	return crl, nil
+}
+
+// fetchCRL fetches the CRL published at url
+func (c *crlDistributionPointChecker) fetchCRL(url string) (*pkix.CertificateList, error) {
+	resp, err := c.client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Unexpected status [%s]", resp.Status)
+	}
+
+	raw, err := ioutil.ReadAll(&io.LimitedReader{R: resp.Body, N: maxCRLSize + 1})
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) > maxCRLSize {
+		return nil, fmt.Errorf("CRL exceeds [%d] bytes", maxCRLSize)
+	}
+
+	return x509.ParseCRL(raw)
+}
@@ -0,0 +1,147 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/stretchr/testify/assert"
+)
+
+// crlTestIdentity is an identity serialized as peerIdentity
+type crlTestIdentity struct {
+	mockIdentity
+	peerIdentity []byte
+}
+
+func (id *crlTestIdentity) Serialize() ([]byte, error) {
+	return id.peerIdentity, nil
+}
+
+// newCRLTestCA returns a self-signed CA certificate and its key
+func newCRLTestCA(t *testing.T, name string) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+	return cert, key
+}
+
+// newCRLTestIdentity returns an identity whose certificate,
+// issued by ca, lists crlURL as CRL distribution point
+func newCRLTestIdentity(t *testing.T, ca *x509.Certificate, caKey *ecdsa.PrivateKey, serial int64, crlURL string) *crlTestIdentity {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "peer"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	if crlURL != "" {
+		template.CRLDistributionPoints = []string{crlURL}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+	assert.NoError(t, err)
+	raw, err := proto.Marshal(&msp.SerializedIdentity{
+		Mspid:   "CRLOrg",
+		IdBytes: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	})
+	assert.NoError(t, err)
+	return &crlTestIdentity{peerIdentity: raw}
+}
+
+func TestCRLDistributionPointChecker(t *testing.T) {
+	ca, caKey := newCRLTestCA(t, "ca")
+	otherCA, otherCAKey := newCRLTestCA(t, "otherca")
+
+	crl, err := ca.CreateCRL(rand.Reader, caKey, []pkix.RevokedCertificate{
+		{SerialNumber: big.NewInt(2), RevocationTime: time.Now()},
+	}, time.Now(), time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+	forgedCRL, err := otherCA.CreateCRL(rand.Reader, otherCAKey, nil, time.Now(), time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+	expiredCRL, err := ca.CreateCRL(rand.Reader, caKey, nil, time.Now().Add(-2*time.Hour), time.Now().Add(-time.Hour))
+	assert.NoError(t, err)
+
+	var fetches int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fetches, 1)
+		switch r.URL.Path {
+		case "/ca.crl":
+			w.Write(crl)
+		case "/forged.crl":
+			w.Write(forgedCRL)
+		case "/expired.crl":
+			w.Write(expiredCRL)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	checker := NewCRLDistributionPointChecker([]*x509.Certificate{ca}, time.Hour)
+
+	// Not revoked
+	assert.NoError(t, checker.CheckRevocation(newCRLTestIdentity(t, ca, caKey, 1, server.URL+"/ca.crl")))
+	// Revoked
+	assert.Error(t, checker.CheckRevocation(newCRLTestIdentity(t, ca, caKey, 2, server.URL+"/ca.crl")))
+	// The CRL is cached
+	assert.Equal(t, int32(1), atomic.LoadInt32(&fetches))
+
+	// No distribution point
+	assert.NoError(t, checker.CheckRevocation(newCRLTestIdentity(t, ca, caKey, 2, "")))
+	// Not an x.509 identity
+	assert.NoError(t, checker.CheckRevocation(&crlTestIdentity{peerIdentity: newMockPeerIdentity("CRLOrg", "peer0")}))
+
+	// The revocation status cannot be determined
+	assert.Error(t, checker.CheckRevocation(newCRLTestIdentity(t, ca, caKey, 1, server.URL+"/missing.crl")))
+	assert.Error(t, checker.CheckRevocation(newCRLTestIdentity(t, ca, caKey, 1, server.URL+"/forged.crl")))
+	assert.Error(t, checker.CheckRevocation(newCRLTestIdentity(t, ca, caKey, 1, server.URL+"/expired.crl")))
+	assert.Error(t, checker.CheckRevocation(newCRLTestIdentity(t, otherCA, otherCAKey, 1, server.URL+"/ca.crl")))
+
+	// CRLs are fetched again once their cache entry expires
+	checker = NewCRLDistributionPointChecker([]*x509.Certificate{ca}, 0)
+	atomic.StoreInt32(&fetches, 0)
+	assert.NoError(t, checker.CheckRevocation(newCRLTestIdentity(t, ca, caKey, 1, server.URL+"/ca.crl")))
+	assert.NoError(t, checker.CheckRevocation(newCRLTestIdentity(t, ca, caKey, 1, server.URL+"/ca.crl")))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&fetches))
+}
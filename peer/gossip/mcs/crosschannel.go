@@ -0,0 +1,87 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcs
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/hyperledger/fabric/gossip/api"
+	"github.com/hyperledger/fabric/gossip/common"
+	"github.com/hyperledger/fabric/msp/mgmt"
+)
+
+// WithCrossChannelVerification enables VerifyCrossChannel.
+// It is disabled by default, see VerifyCrossChannel for
+// the implications of enabling it.
+func WithCrossChannelVerification() Option {
+	return func(s *mspMessageCryptoService) {
+		s.allowCrossChannel = true
+	}
+}
+
+// VerifyCrossChannel checks that peerIdentity is valid according to the
+// MSPs of channel identityChannel, that signature is a valid signature of
+// message under its verification key, and that the signature satisfies
+// the read policy of channel policyChannel.
+//
+// The policy of policyChannel deserializes and validates peerIdentity again,
+// with the MSPs of policyChannel, so the identity must belong to an
+// organization of policyChannel as well, and be valid on both channels:
+// validation on identityChannel narrows VerifyByChannel on policyChannel
+// by the configuration of identityChannel, e.g. by its revocation lists,
+// whose administrators may differ from those of policyChannel. Unlike
+// VerifyByChannel, the policy is enforced even if policyChannel is
+// joining or leaving, see WithChannelStateGrace.
+// It requires the WithCrossChannelVerification option.
+func (s *mspMessageCryptoService) VerifyCrossChannel(identityChannel, policyChannel common.ChainID, peerIdentity api.PeerIdentityType, signature, message []byte) error {
+	if !s.allowCrossChannel {
+		return errors.New("Cross-channel verification is not enabled")
+	}
+
+	// Validate arguments
+	if len(peerIdentity) == 0 {
+		return errors.New("Invalid Peer Identity. It must be different from nil.")
+	}
+
+	// 1. Validate the identity under the MSPs of identityChannel
+	mspManager := mgmt.GetManagerForChainIfExists(string(s.normalizeChannel(identityChannel)))
+	if mspManager == nil {
+		return fmt.Errorf("No MSP manager found for channel [%s]", string(identityChannel))
+	}
+
+	identity, err := mspManager.DeserializeIdentity(peerIdentity)
+	if err != nil {
+		return fmt.Errorf("Failed deserializing peer identity on channel [%s]: [%s]", string(identityChannel), err)
+	}
+	if err := s.validate(identity); err != nil {
+		return fmt.Errorf("Peer identity is not valid on channel [%s]: [%s]", string(identityChannel), err)
+	}
+	if err := s.checkNotBefore(peerIdentity); err != nil {
+		return err
+	}
+
+	if err := identity.Verify(message, signature); err != nil {
+		return &InvalidSignatureError{Err: err}
+	}
+
+	// 2. Evaluate the signature against the policy of policyChannel
+	logger.Debugf("Peer identity [% x] validated on channel [%s], evaluating policy of channel [%s]", []byte(peerIdentity), string(identityChannel), string(policyChannel))
+	err = s.evaluateGossipPolicy(logger, policyChannel, peerIdentity, signature, message)
+	s.publishFailure(OperationVerifyByChannel, peerIdentity, policyChannel, err)
+	return err
+}
@@ -0,0 +1,81 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcs
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/gossip/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyCrossChannel(t *testing.T) {
+	identityChannel := common.ChainID("crossidentitychannel")
+	policyChannel := common.ChainID("crosspolicychannel")
+	identityMSP := setupMockChannel(string(identityChannel), "CrossOrg")
+	policyMSP := setupMockChannel(string(policyChannel), "CrossOrg")
+	setupMockChannel("crossotherchannel", "OtherOrg")
+
+	pm := newMockPolicyManager()
+	pm.setPolicy(string(policyChannel), policies.ChannelApplicationReaders, &mockChannelPolicy{chainID: string(policyChannel)})
+	pm.setPolicy("crossotherchannel", policies.ChannelApplicationReaders, &mockChannelPolicy{chainID: "crossotherchannel"})
+
+	peer := newMockPeerIdentity("CrossOrg", "peer0")
+	msg := []byte("msg")
+	sig := mockSign(msg)
+
+	// Disabled by default
+	mcs := New(pm).(*mspMessageCryptoService)
+	assert.Error(t, mcs.VerifyCrossChannel(identityChannel, policyChannel, peer, sig, msg))
+
+	mcs = New(pm, WithCrossChannelVerification()).(*mspMessageCryptoService)
+	assert.NoError(t, mcs.VerifyCrossChannel(identityChannel, policyChannel, peer, sig, msg))
+
+	// Invalid signature
+	err := mcs.VerifyCrossChannel(identityChannel, policyChannel, peer, []byte("bad signature"), msg)
+	assert.IsType(t, &InvalidSignatureError{}, err)
+
+	// Not valid on the identity channel
+	identityMSP.validateErrs["peer0"] = errors.New("revoked")
+	assert.Error(t, mcs.VerifyCrossChannel(identityChannel, policyChannel, peer, sig, msg))
+	delete(identityMSP.validateErrs, "peer0")
+
+	// Not satisfying the policy of the policy channel
+	policyMSP.validateErrs["peer0"] = errors.New("revoked")
+	assert.Error(t, mcs.VerifyCrossChannel(identityChannel, policyChannel, peer, sig, msg))
+	delete(policyMSP.validateErrs, "peer0")
+	assert.Error(t, mcs.VerifyCrossChannel(identityChannel, common.ChainID("crossotherchannel"), peer, sig, msg))
+
+	// The policy is enforced even while the policy channel is in transition
+	joining := func(chainID common.ChainID) ChannelState {
+		return ChannelJoining
+	}
+	mcs = New(pm, WithCrossChannelVerification(), WithChannelStateGrace(joining), WithLocalMSPs(&mockMSP{id: "CrossOrg", validateErrs: make(map[string]error)})).(*mspMessageCryptoService)
+	assert.NoError(t, mcs.VerifyByChannel(common.ChainID("crossotherchannel"), peer, sig, msg))
+	assert.Error(t, mcs.VerifyCrossChannel(identityChannel, common.ChainID("crossotherchannel"), peer, sig, msg))
+	mcs = New(pm, WithCrossChannelVerification()).(*mspMessageCryptoService)
+
+	// Unknown channels
+	assert.Error(t, mcs.VerifyCrossChannel(common.ChainID("unknownchannel"), policyChannel, peer, sig, msg))
+	assert.Error(t, mcs.VerifyCrossChannel(identityChannel, common.ChainID("unknownchannel"), peer, sig, msg))
+
+	// Invalid identity
+	assert.Error(t, mcs.VerifyCrossChannel(identityChannel, policyChannel, nil, sig, msg))
+	assert.Error(t, mcs.VerifyCrossChannel(identityChannel, policyChannel, newMockPeerIdentity("OtherOrg", "peer0"), sig, msg))
+}
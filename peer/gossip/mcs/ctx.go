@@ -0,0 +1,82 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcs
+
+import (
+	"context"
+
+	"github.com/hyperledger/fabric/gossip/api"
+	"github.com/hyperledger/fabric/gossip/common"
+)
+
+// ValidateIdentityCtx validates the identity of a remote peer, as
+// ValidateIdentity does, unless ctx is done first, in which case
+// the error of ctx is returned.
+func (s *mspMessageCryptoService) ValidateIdentityCtx(ctx context.Context, peerIdentity api.PeerIdentityType) error {
+	return runCtx(ctx, func() error {
+		return s.ValidateIdentity(peerIdentity)
+	})
+}
+
+// VerifyCtx checks that signature is a valid signature of message under
+// a peer's verification key, as Verify does, unless ctx is done first, in
+// which case the error of ctx is returned.
+func (s *mspMessageCryptoService) VerifyCtx(ctx context.Context, peerIdentity api.PeerIdentityType, signature, message []byte) error {
+	return runCtx(ctx, func() error {
+		return s.Verify(peerIdentity, signature, message)
+	})
+}
+
+// VerifyByChannelCtx checks that signature is a valid signature of message
+// under a peer's verification key in the context of channel chainID, as
+// VerifyByChannel does, unless ctx is done first, in which case the error
+// of ctx is returned.
+func (s *mspMessageCryptoService) VerifyByChannelCtx(ctx context.Context, chainID common.ChainID, peerIdentity api.PeerIdentityType, signature, message []byte) error {
+	return runCtx(ctx, func() error {
+		return s.VerifyByChannel(chainID, peerIdentity, signature, message)
+	})
+}
+
+// VerifyBlockCtx checks that a block is properly signed, as VerifyBlock
+// does, unless ctx is done first, in which case the error of ctx is returned.
+func (s *mspMessageCryptoService) VerifyBlockCtx(ctx context.Context, chainID common.ChainID, signedBlock api.SignedBlock) error {
+	return runCtx(ctx, func() error {
+		return s.VerifyBlock(chainID, signedBlock)
+	})
+}
+
+// runCtx runs f and returns its result, unless ctx is done first.
+// The underlying cryptographic operations (e.g. on an HSM) cannot be
+// interrupted: if ctx is done first, f keeps running in the background
+// and its result is discarded, but the caller is released.
+func runCtx(ctx context.Context, f func() error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	result := make(chan error, 1)
+	go func() {
+		result <- f()
+	}()
+
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,71 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/gossip/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextVariants(t *testing.T) {
+	chainID := "ctxchannel"
+	setupMockChannel(chainID, "CtxOrg")
+	pm := newMockPolicyManager()
+	pm.setPolicy(chainID, policies.ChannelApplicationReaders, &mockChannelPolicy{chainID: chainID})
+	pm.setPolicy(chainID, policies.BlockValidation, &mockChannelPolicy{chainID: chainID})
+	mcs := New(pm).(*mspMessageCryptoService)
+	msg := []byte("Hello World!!!")
+	peerIdentity := newMockPeerIdentity("CtxOrg", "peer0")
+	block := mockBlock(chainID, newMockPeerIdentity("CtxOrg", "orderer0"))
+
+	ctx := context.Background()
+	assert.NoError(t, mcs.ValidateIdentityCtx(ctx, peerIdentity))
+	assert.NoError(t, mcs.VerifyCtx(ctx, peerIdentity, mockSign(msg), msg))
+	assert.NoError(t, mcs.VerifyByChannelCtx(ctx, common.ChainID(chainID), peerIdentity, mockSign(msg), msg))
+	assert.NoError(t, mcs.VerifyBlockCtx(ctx, common.ChainID(chainID), block))
+	assert.Error(t, mcs.VerifyCtx(ctx, peerIdentity, []byte("bad signature"), msg))
+	assert.Error(t, mcs.ValidateIdentityCtx(ctx, newMockPeerIdentity("UnknownOrg", "peer0")))
+
+	// Cancelled context
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.Equal(t, context.Canceled, mcs.ValidateIdentityCtx(cancelled, peerIdentity))
+	assert.Equal(t, context.Canceled, mcs.VerifyCtx(cancelled, peerIdentity, mockSign(msg), msg))
+	assert.Equal(t, context.Canceled, mcs.VerifyByChannelCtx(cancelled, common.ChainID(chainID), peerIdentity, mockSign(msg), msg))
+	assert.Equal(t, context.Canceled, mcs.VerifyBlockCtx(cancelled, common.ChainID(chainID), block))
+}
+
+func TestContextDeadline(t *testing.T) {
+	// An operation stuck, e.g. on a slow HSM
+	release := make(chan struct{})
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := runCtx(ctx, func() error {
+		<-release
+		return nil
+	})
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.True(t, time.Since(start) < 5*time.Second)
+}
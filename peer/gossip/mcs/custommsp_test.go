@@ -0,0 +1,187 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcs
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/gossip/api"
+	"github.com/hyperledger/fabric/gossip/common"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/msp/mgmt"
+	protoscommon "github.com/hyperledger/fabric/protos/common"
+	protosmsp "github.com/hyperledger/fabric/protos/msp"
+	"github.com/stretchr/testify/assert"
+)
+
+// tokenMSP is a trivial MSP plugin, unrelated to x.509, whose
+// identities are tokens and whose signatures are keyed digests
+type tokenMSP struct {
+	id      string
+	revoked map[string]bool
+
+	deserialized int32
+	validated    int32
+	verified     int32
+}
+
+// tokenSign returns the signature of msg by the identity holding token
+func tokenSign(token string, msg []byte) []byte {
+	digest := sha256.Sum256(append([]byte(token), msg...))
+	return digest[:]
+}
+
+func (m *tokenMSP) serialize(token string) api.PeerIdentityType {
+	raw, err := proto.Marshal(&msp.SerializedIdentity{Mspid: m.id, IdBytes: []byte("token:" + token)})
+	if err != nil {
+		panic(err)
+	}
+	return raw
+}
+
+func (m *tokenMSP) DeserializeIdentity(serializedID []byte) (msp.Identity, error) {
+	atomic.AddInt32(&m.deserialized, 1)
+	sID := &msp.SerializedIdentity{}
+	if err := proto.Unmarshal(serializedID, sID); err != nil {
+		return nil, err
+	}
+	if sID.Mspid != m.id || !bytes.HasPrefix(sID.IdBytes, []byte("token:")) {
+		return nil, errors.New("Not a token identity")
+	}
+	return &tokenIdentity{msp: m, token: string(sID.IdBytes[len("token:"):])}, nil
+}
+
+func (m *tokenMSP) Setup(config *protosmsp.MSPConfig) error {
+	return nil
+}
+
+func (m *tokenMSP) GetType() msp.ProviderType {
+	return msp.OTHER
+}
+
+func (m *tokenMSP) GetIdentifier() (string, error) {
+	return m.id, nil
+}
+
+func (m *tokenMSP) GetSigningIdentity(identifier *msp.IdentityIdentifier) (msp.SigningIdentity, error) {
+	return nil, errors.New("No signing identity")
+}
+
+func (m *tokenMSP) GetDefaultSigningIdentity() (msp.SigningIdentity, error) {
+	return nil, errors.New("No signing identity")
+}
+
+func (m *tokenMSP) Validate(id msp.Identity) error {
+	atomic.AddInt32(&m.validated, 1)
+	if m.revoked[id.(*tokenIdentity).token] {
+		return errors.New("Revoked token")
+	}
+	return nil
+}
+
+func (m *tokenMSP) SatisfiesPrincipal(id msp.Identity, principal *protoscommon.MSPPrincipal) error {
+	return errors.New("Unsupported principal")
+}
+
+type tokenIdentity struct {
+	msp   *tokenMSP
+	token string
+}
+
+func (id *tokenIdentity) GetIdentifier() *msp.IdentityIdentifier {
+	return &msp.IdentityIdentifier{Mspid: id.msp.id, Id: id.token}
+}
+
+func (id *tokenIdentity) GetMSPIdentifier() string {
+	return id.msp.id
+}
+
+func (id *tokenIdentity) Validate() error {
+	return id.msp.Validate(id)
+}
+
+func (id *tokenIdentity) GetOrganizationalUnits() []*msp.OUIdentifier {
+	return nil
+}
+
+func (id *tokenIdentity) Anonymous() bool {
+	return false
+}
+
+func (id *tokenIdentity) Verify(msg []byte, sig []byte) error {
+	atomic.AddInt32(&id.msp.verified, 1)
+	if !bytes.Equal(sig, tokenSign(id.token, msg)) {
+		return errors.New("Invalid signature")
+	}
+	return nil
+}
+
+func (id *tokenIdentity) VerifyOpts(msg []byte, sig []byte, opts msp.SignatureOpts) error {
+	return id.Verify(msg, sig)
+}
+
+func (id *tokenIdentity) VerifyAttributes(proof []byte, spec *msp.AttributeProofSpec) error {
+	return errors.New("Unsupported")
+}
+
+func (id *tokenIdentity) Serialize() ([]byte, error) {
+	return id.msp.serialize(id.token), nil
+}
+
+func (id *tokenIdentity) SatisfiesPrincipal(principal *protoscommon.MSPPrincipal) error {
+	return id.msp.SatisfiesPrincipal(id, principal)
+}
+
+func TestCustomMSPType(t *testing.T) {
+	chainID := "tokenchannel"
+	tokens := &tokenMSP{id: "TokenOrg", revoked: map[string]bool{"revoked": true}}
+	mspManager := msp.NewMSPManager()
+	assert.NoError(t, mspManager.Setup([]msp.MSP{tokens}))
+	mgmt.XXXSetMSPManager(chainID, mspManager)
+
+	pm := newMockPolicyManager()
+	pm.setPolicy(chainID, policies.ChannelApplicationReaders, &mockChannelPolicy{chainID: chainID})
+	mcs := New(pm)
+
+	peer := tokens.serialize("peer0")
+	msg := []byte("msg")
+
+	// ValidateIdentity routes through the plugin
+	assert.NoError(t, mcs.ValidateIdentity(peer))
+	assert.True(t, atomic.LoadInt32(&tokens.deserialized) > 0)
+	assert.True(t, atomic.LoadInt32(&tokens.validated) > 0)
+	assert.Error(t, mcs.ValidateIdentity(tokens.serialize("revoked")))
+
+	// So does Verify, the x.509-specific checks being skipped
+	assert.NoError(t, mcs.Verify(peer, tokenSign("peer0", msg), msg))
+	assert.True(t, atomic.LoadInt32(&tokens.verified) > 0)
+	assert.Error(t, mcs.Verify(peer, tokenSign("peer1", msg), msg))
+	assert.Error(t, mcs.Verify(peer, mockSign(msg), msg))
+	assert.NoError(t, mcs.VerifyByChannel(common.ChainID(chainID), peer, tokenSign("peer0", msg), msg))
+
+	// As well as the validation of identities cached along the way
+	mcs = New(pm, WithIdentityCache(NewLRUIdentityCache(10), time.Hour))
+	assert.NoError(t, mcs.ValidateIdentity(peer))
+	assert.NoError(t, mcs.ValidateIdentity(peer))
+}
@@ -0,0 +1,128 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcs
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric/bccsp/factory"
+	"github.com/hyperledger/fabric/gossip/api"
+)
+
+// VerifyDelegated checks that signature is a valid signature of message
+// under the key of delegateCert, a short-lived x.509 certificate that the
+// peer identified by delegatorIdentity issued to delegate its signing
+// capabilities.
+// delegatorIdentity must be valid according to the MSPs and carry an
+// x.509 certificate whose key signed delegateCert, and delegateCert must
+// be within its validity period. delegateCert can be either PEM or DER
+// encoded.
+// If the delegation does not hold, an *InvalidDelegationError is returned.
+// If the delegation holds but signature is not valid, an
+// *InvalidSignatureError is returned.
+func (s *mspMessageCryptoService) VerifyDelegated(delegatorIdentity api.PeerIdentityType, delegateCert []byte, signature, message []byte) error {
+	delegate, err := s.verifyDelegation(delegatorIdentity, delegateCert)
+	if err != nil {
+		logger.Warningf("Invalid delegation from peer identity [% x]: [%s]", []byte(delegatorIdentity), err)
+		return &InvalidDelegationError{Err: err}
+	}
+
+	if err := verifyCertificateSignature(delegate, signature, message); err != nil {
+		return &InvalidSignatureError{Err: err}
+	}
+
+	return nil
+}
+
+// verifyDelegation checks that the peer identified by delegatorIdentity
+// issued delegateCert, and returns the parsed delegate certificate
+func (s *mspMessageCryptoService) verifyDelegation(delegatorIdentity api.PeerIdentityType, delegateCert []byte) (*x509.Certificate, error) {
+	// 1. Validate the delegator
+	if _, _, err := s.getValidatedIdentity(logger, delegatorIdentity); err != nil {
+		return nil, fmt.Errorf("Invalid delegator identity: [%s]", err)
+	}
+
+	delegatorCert, err := getCertificate(delegatorIdentity)
+	if err != nil {
+		return nil, fmt.Errorf("Delegator identity carries no x.509 certificate: [%s]", err)
+	}
+
+	// 2. Check that the delegator signed the delegate certificate
+	if len(delegateCert) == 0 {
+		return nil, errors.New("Invalid delegate certificate. It must be different from nil.")
+	}
+	if bl, _ := pem.Decode(delegateCert); bl != nil {
+		delegateCert = bl.Bytes
+	}
+	delegate, err := x509.ParseCertificate(delegateCert)
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing delegate certificate: [%s]", err)
+	}
+
+	if delegate.IsCA {
+		return nil, errors.New("Delegate certificate must not be a CA certificate")
+	}
+
+	if err := delegatorCert.CheckSignature(delegate.SignatureAlgorithm, delegate.RawTBSCertificate, delegate.Signature); err != nil {
+		return nil, fmt.Errorf("Delegate certificate not signed by the delegator: [%s]", err)
+	}
+
+	// 3. Check the validity period of the delegate certificate
+	now := time.Now()
+	if now.Add(s.clockSkew).Before(delegate.NotBefore) || now.Add(-s.clockSkew).After(delegate.NotAfter) {
+		return nil, fmt.Errorf("Delegate certificate is valid between [%s] and [%s], current time is [%s]", delegate.NotBefore, delegate.NotAfter, now)
+	}
+
+	return delegate, nil
+}
+
+// verifyCertificateSignature checks that signature is a valid
+// signature of message under the public key of cert
+func verifyCertificateSignature(cert *x509.Certificate, signature, message []byte) error {
+	pk, err := factory.GetDefault().KeyImport(cert, &bccsp.X509PublicKeyImportOpts{Temporary: true})
+	if err != nil {
+		return fmt.Errorf("Failed importing public key: [%s]", err)
+	}
+
+	return verifyKeySignature(pk, signature, message)
+}
+
+// verifyKeySignature checks that signature is
+// a valid signature of message under pk
+func verifyKeySignature(pk bccsp.Key, signature, message []byte) error {
+	csp := factory.GetDefault()
+
+	digest, err := csp.Hash(message, &bccsp.SHAOpts{})
+	if err != nil {
+		return fmt.Errorf("Failed computing digest: [%s]", err)
+	}
+
+	valid, err := csp.Verify(pk, signature, digest, nil)
+	if err != nil {
+		return fmt.Errorf("Could not determine the validity of the signature: [%s]", err)
+	}
+	if !valid {
+		return errors.New("The signature is invalid")
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcs
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric/bccsp/factory"
+	"github.com/hyperledger/fabric/bccsp/signer"
+	mockpolicies "github.com/hyperledger/fabric/common/mocks/policies"
+	"github.com/stretchr/testify/assert"
+)
+
+// newDelegate returns a delegate certificate issued by delegator,
+// according to template, and a signer for the delegate key
+func newDelegate(t *testing.T, delegator *x509.Certificate, delegatorKey *ecdsa.PrivateKey, template *x509.Certificate) ([]byte, *signer.CryptoSigner) {
+	csp := factory.GetDefault()
+	key, err := csp.KeyGen(&bccsp.ECDSAP256KeyGenOpts{Temporary: true})
+	assert.NoError(t, err)
+	delegateSigner := &signer.CryptoSigner{}
+	assert.NoError(t, delegateSigner.Init(csp, key))
+
+	template.SerialNumber = big.NewInt(2)
+	if template.NotBefore.IsZero() {
+		template.NotBefore = time.Now().Add(-time.Minute)
+	}
+	if template.NotAfter.IsZero() {
+		template.NotAfter = time.Now().Add(time.Minute)
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, delegator, delegateSigner.Public(), delegatorKey)
+	assert.NoError(t, err)
+
+	return der, delegateSigner
+}
+
+func signWithDelegate(t *testing.T, delegateSigner *signer.CryptoSigner, msg []byte) []byte {
+	digest, err := factory.GetDefault().Hash(msg, &bccsp.SHAOpts{})
+	assert.NoError(t, err)
+	signature, err := delegateSigner.Sign(rand.Reader, digest, nil)
+	assert.NoError(t, err)
+	return signature
+}
+
+func TestVerifyDelegated(t *testing.T) {
+	m := setupMockChannel("delegationchannel", "DelegationOrg")
+	mcs := New(&mockpolicies.PolicyManagerMgmt{}).(*mspMessageCryptoService)
+
+	delegator, delegatorCert, delegatorKey := newCertPeerIdentityWithKey(t, "DelegationOrg", &x509.Certificate{Subject: pkix.Name{CommonName: "peer0"}})
+	delegateCert, delegateSigner := newDelegate(t, delegatorCert, delegatorKey, &x509.Certificate{Subject: pkix.Name{CommonName: "peer0-delegate"}})
+	msg := []byte("Hello World!!!")
+	signature := signWithDelegate(t, delegateSigner, msg)
+
+	// DER and PEM encoded delegate certificates
+	assert.NoError(t, mcs.VerifyDelegated(delegator, delegateCert, signature, msg))
+	assert.NoError(t, mcs.VerifyDelegated(delegator, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: delegateCert}), signature, msg))
+
+	// Bad message signature
+	err := mcs.VerifyDelegated(delegator, delegateCert, signature, []byte("Another message"))
+	assert.IsType(t, &InvalidSignatureError{}, err)
+	err = mcs.VerifyDelegated(delegator, delegateCert, []byte("bad"), msg)
+	assert.IsType(t, &InvalidSignatureError{}, err)
+
+	// Delegate certificate issued by somebody else
+	other, otherCert, otherKey := newCertPeerIdentityWithKey(t, "DelegationOrg", &x509.Certificate{Subject: pkix.Name{CommonName: "peer1"}})
+	err = mcs.VerifyDelegated(other, delegateCert, signature, msg)
+	assert.IsType(t, &InvalidDelegationError{}, err)
+	forgedCert, forgedSigner := newDelegate(t, otherCert, otherKey, &x509.Certificate{Subject: pkix.Name{CommonName: "peer0-delegate"}})
+	err = mcs.VerifyDelegated(delegator, forgedCert, signWithDelegate(t, forgedSigner, msg), msg)
+	assert.IsType(t, &InvalidDelegationError{}, err)
+
+	// Expired delegate certificate
+	expiredCert, expiredSigner := newDelegate(t, delegatorCert, delegatorKey, &x509.Certificate{
+		Subject:   pkix.Name{CommonName: "peer0-delegate"},
+		NotBefore: time.Now().Add(-time.Hour),
+		NotAfter:  time.Now().Add(-time.Minute),
+	})
+	err = mcs.VerifyDelegated(delegator, expiredCert, signWithDelegate(t, expiredSigner, msg), msg)
+	assert.IsType(t, &InvalidDelegationError{}, err)
+
+	// Malformed delegate certificate
+	err = mcs.VerifyDelegated(delegator, []byte("garbage"), signature, msg)
+	assert.IsType(t, &InvalidDelegationError{}, err)
+	err = mcs.VerifyDelegated(delegator, nil, signature, msg)
+	assert.IsType(t, &InvalidDelegationError{}, err)
+
+	// Invalid delegator
+	m.validateErrs[string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: delegatorCert.Raw}))] = assert.AnError
+	err = mcs.VerifyDelegated(delegator, delegateCert, signature, msg)
+	assert.IsType(t, &InvalidDelegationError{}, err)
+}
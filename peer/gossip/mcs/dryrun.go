@@ -0,0 +1,92 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcs
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric/gossip/api"
+)
+
+// dryRunExpiryWarning is how close to the expiration of its
+// certificate an identity must be for VerifyDryRun to warn about it
+const dryRunExpiryWarning = 7 * 24 * time.Hour
+
+// VerifyDryRun performs the same verification Verify does, but instead
+// of enforcing its outcome it reports whether the verification would
+// succeed, together with diagnostic notes on how the outcome was reached:
+// the MSP that validated peerIdentity, the policy evaluated, the reason of
+// a failure, and warnings such as a certificate close to expiration.
+// VerifyDryRun does not alter any state of the MessageCryptoService, so
+// it does not affect the outcome of subsequent verifications.
+// An error is returned only if the dry-run could not be performed at all.
+func (s *mspMessageCryptoService) VerifyDryRun(peerIdentity api.PeerIdentityType, signature, message []byte) (bool, []string, error) {
+	// Validate arguments
+	if len(peerIdentity) == 0 {
+		return false, nil, errors.New("Invalid Peer Identity. It must be different from nil.")
+	}
+
+	var notes []string
+	notef := func(format string, args ...interface{}) {
+		notes = append(notes, fmt.Sprintf(format, args...))
+	}
+
+	// Go through validateIdentity rather than getValidatedIdentity
+	// in order not to feed the state kept on validated identities
+	identity, chainID, err := s.validateIdentity(logger, peerIdentity)
+	if err == nil {
+		err = s.checkNotBefore(peerIdentity)
+	}
+	if err != nil {
+		notef("Identity validation failed: [%s]", err)
+		return false, notes, nil
+	}
+
+	if len(chainID) == 0 {
+		notef("Identity validated by the local MSP [%s]", identity.GetMSPIdentifier())
+	} else {
+		notef("Identity validated by MSP [%s] of channel [%s]", identity.GetMSPIdentifier(), string(chainID))
+	}
+
+	if expiresAt, ok := getExpiration(peerIdentity, identity); ok && expiresAt.Sub(time.Now()) < dryRunExpiryWarning {
+		notef("Warning: identity expires at [%s], in less than [%s]", expiresAt, dryRunExpiryWarning)
+	}
+
+	if len(chainID) == 0 {
+		notef("Signature verified directly against the identity, no policy evaluated")
+		if err := identity.Verify(message, signature); err != nil {
+			notef("Signature verification failed: [%s]", err)
+			return false, notes, nil
+		}
+		return true, notes, nil
+	}
+
+	policyName, _, err := s.getGossipPolicy(logger, chainID)
+	if err != nil {
+		notef("Policy lookup failed: [%s]", err)
+		return false, notes, nil
+	}
+	notef("Evaluating policy [%s] of channel [%s]", policyName, string(chainID))
+	if err := s.verifyByChannel(logger, chainID, peerIdentity, signature, message); err != nil {
+		notef("Policy evaluation failed: [%s]", err)
+		return false, notes, nil
+	}
+
+	return true, notes, nil
+}
@@ -0,0 +1,84 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcs
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/gossip/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyDryRun(t *testing.T) {
+	chainID := "dryrunchannel"
+	setupMockChannel(chainID, "DryRunOrg")
+	pm := newMockPolicyManager()
+	pm.setPolicy(chainID, policies.ChannelApplicationReaders, &mockChannelPolicy{chainID: chainID})
+
+	var reports [][]string
+	handler := func(pkiID common.PKIidType, mspIDs []string) {
+		reports = append(reports, mspIDs)
+	}
+	mcs := New(pm, WithDuplicateIdentityMonitor(handler)).(*mspMessageCryptoService)
+
+	peerIdentity := newMockPeerIdentity("DryRunOrg", "peer0")
+	msg := []byte("Hello World!!!")
+
+	ok, notes, err := mcs.VerifyDryRun(peerIdentity, mockSign(msg), msg)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Contains(t, strings.Join(notes, "\n"), "Identity validated by MSP [DryRunOrg] of channel [dryrunchannel]")
+	assert.Contains(t, strings.Join(notes, "\n"), policies.ChannelApplicationReaders)
+
+	// Invalid signature
+	ok, notes, err = mcs.VerifyDryRun(peerIdentity, []byte("bad"), msg)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.Contains(t, strings.Join(notes, "\n"), "Policy evaluation failed")
+
+	// Invalid identity
+	ok, notes, err = mcs.VerifyDryRun(newMockPeerIdentity("UnknownOrg", "peer0"), mockSign(msg), msg)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.Contains(t, strings.Join(notes, "\n"), "Identity validation failed")
+
+	// Certificate close to expiration
+	expiring := newCertPeerIdentity(t, "DryRunOrg", &x509.Certificate{
+		Subject:  pkix.Name{CommonName: "peer1"},
+		NotAfter: time.Now().Add(24 * time.Hour),
+	})
+	ok, notes, err = mcs.VerifyDryRun(expiring, mockSign(msg), msg)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Contains(t, strings.Join(notes, "\n"), "Warning: identity expires")
+
+	// No identity
+	_, _, err = mcs.VerifyDryRun(nil, mockSign(msg), msg)
+	assert.Error(t, err)
+
+	// Dry-runs leave no trace on the state kept on identities
+	setupMockChannel("dryrunchannel2", "DryRunOrg2")
+	_, _, err = mcs.VerifyDryRun(newMockPeerIdentity("DryRunOrg2", "peer0"), mockSign(msg), msg)
+	assert.NoError(t, err)
+	assert.NoError(t, mcs.Verify(peerIdentity, mockSign(msg), msg))
+	assert.Empty(t, reports)
+}
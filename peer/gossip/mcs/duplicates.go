@@ -0,0 +1,129 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcs
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric/bccsp/factory"
+	"github.com/hyperledger/fabric/gossip/api"
+	"github.com/hyperledger/fabric/gossip/common"
+	"github.com/hyperledger/fabric/msp"
+)
+
+// DuplicateIdentityHandler is notified when the same credential
+// is observed under more than one MSP-ID. pkiID identifies the
+// credential and mspIDs lists, sorted, all the MSP-IDs it has
+// been observed under so far.
+type DuplicateIdentityHandler func(pkiID common.PKIidType, mspIDs []string)
+
+// WithDuplicateIdentityMonitor makes the MessageCryptoService track,
+// for every identity it validates, the MSP-ID the identity has been
+// validated under, and invoke handler whenever a credential shows up
+// under a new MSP-ID after having been seen under a different one.
+// This usually means that the same certificate has been configured
+// for different organizations.
+//
+// Notice that the PKI-ID returned by GetPKIidOfCert covers the MSP-ID
+// as well, therefore the monitor identifies credentials by the PKI-ID
+// of the identity bytes alone (e.g. the certificate), when the peer
+// identity is a SerializedIdentity, and by the PKI-ID of the whole
+// peer identity otherwise.
+// handler is invoked synchronously on the validation path and
+// must return quickly.
+func WithDuplicateIdentityMonitor(handler DuplicateIdentityHandler) Option {
+	return func(s *mspMessageCryptoService) {
+		if handler == nil {
+			return
+		}
+		s.duplicates = &duplicateMonitor{
+			handler:  handler,
+			observed: make(map[string]map[string]struct{}),
+		}
+	}
+}
+
+type duplicateMonitor struct {
+	handler DuplicateIdentityHandler
+
+	lock sync.Mutex
+	// observed maps the PKI-ID of a credential
+	// to the set of MSP-IDs it has been seen under
+	observed map[string]map[string]struct{}
+}
+
+// observe records that peerIdentity has been
+// validated as identity and notifies the handler
+// if this reveals a duplicate
+func (m *duplicateMonitor) observe(peerIdentity api.PeerIdentityType, identity msp.Identity) {
+	if m == nil {
+		return
+	}
+
+	pkiID := credentialPKIid(peerIdentity)
+	if pkiID == nil {
+		return
+	}
+	mspID := identity.GetMSPIdentifier()
+
+	m.lock.Lock()
+	mspIDs, ok := m.observed[string(pkiID)]
+	if !ok {
+		mspIDs = make(map[string]struct{})
+		m.observed[string(pkiID)] = mspIDs
+	}
+	_, seen := mspIDs[mspID]
+	mspIDs[mspID] = struct{}{}
+
+	var duplicates []string
+	if !seen && len(mspIDs) > 1 {
+		for id := range mspIDs {
+			duplicates = append(duplicates, id)
+		}
+	}
+	m.lock.Unlock()
+
+	if duplicates == nil {
+		return
+	}
+
+	sort.Strings(duplicates)
+	logger.Warningf("Credential with PKI-ID [% x] has been observed under multiple MSPs %v", []byte(pkiID), duplicates)
+	m.handler(pkiID, duplicates)
+}
+
+// credentialPKIid returns the PKI-ID of the credential of
+// peerIdentity, regardless of the MSP-ID it claims
+func credentialPKIid(peerIdentity api.PeerIdentityType) common.PKIidType {
+	credential := []byte(peerIdentity)
+
+	sID := &msp.SerializedIdentity{}
+	if err := proto.Unmarshal(peerIdentity, sID); err == nil && len(sID.IdBytes) != 0 {
+		credential = sID.IdBytes
+	}
+
+	digest, err := factory.GetDefault().Hash(credential, &bccsp.SHA256Opts{})
+	if err != nil {
+		logger.Errorf("Failed computing digest of credential [% x]: [%s]", credential, err)
+		return nil
+	}
+
+	return digest
+}
@@ -0,0 +1,52 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcs
+
+import (
+	"testing"
+
+	mockpolicies "github.com/hyperledger/fabric/common/mocks/policies"
+	"github.com/hyperledger/fabric/gossip/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDuplicateIdentityMonitor(t *testing.T) {
+	setupMockChannel("dupchannel1", "DupOrg1")
+	setupMockChannel("dupchannel2", "DupOrg2")
+
+	var reports [][]string
+	handler := func(pkiID common.PKIidType, mspIDs []string) {
+		assert.NotNil(t, pkiID)
+		reports = append(reports, mspIDs)
+	}
+	mcs := New(&mockpolicies.PolicyManagerMgmt{}, WithDuplicateIdentityMonitor(handler))
+
+	// Distinct credentials in distinct MSPs
+	assert.NoError(t, mcs.ValidateIdentity(newMockPeerIdentity("DupOrg1", "peer0")))
+	assert.NoError(t, mcs.ValidateIdentity(newMockPeerIdentity("DupOrg2", "peer1")))
+	// The same credential, validated repeatedly under the same MSP
+	assert.NoError(t, mcs.ValidateIdentity(newMockPeerIdentity("DupOrg1", "peer0")))
+	assert.Empty(t, reports)
+
+	// The same credential under a different MSP
+	assert.NoError(t, mcs.ValidateIdentity(newMockPeerIdentity("DupOrg2", "peer0")))
+	assert.Equal(t, [][]string{{"DupOrg1", "DupOrg2"}}, reports)
+
+	// Already reported
+	assert.NoError(t, mcs.ValidateIdentity(newMockPeerIdentity("DupOrg1", "peer0")))
+	assert.Len(t, reports, 1)
+}
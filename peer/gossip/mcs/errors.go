@@ -0,0 +1,281 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcs
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric/gossip/common"
+)
+
+// The reasons an identity fails validation, see IdentityValidationError
+var (
+	// ErrIdentityExpired means that the certificate of the identity expired
+	ErrIdentityExpired = errors.New("Identity expired")
+	// ErrIdentityRevoked means that the identity has been revoked
+	ErrIdentityRevoked = errors.New("Identity revoked")
+	// ErrUnknownMSP means that the identity belongs to an MSP
+	// neither this peer nor any of its channels knows of
+	ErrUnknownMSP = errors.New("Unknown MSP")
+	// ErrMalformedIdentity means that the identity cannot be deserialized
+	ErrMalformedIdentity = errors.New("Malformed identity")
+	// ErrInvalidIdentity means that the identity
+	// is not valid for any other reason
+	ErrInvalidIdentity = errors.New("Invalid identity")
+)
+
+// IdentityValidationError is returned when
+// the identity of a peer cannot be validated
+type IdentityValidationError struct {
+	// PeerIdentity is the identity of the peer
+	PeerIdentity []byte
+	// Reason is one of ErrIdentityExpired, ErrIdentityRevoked,
+	// ErrUnknownMSP, ErrMalformedIdentity and ErrInvalidIdentity
+	Reason error
+	// Err is the error the validation failed with
+	Err error
+}
+
+func (e *IdentityValidationError) Error() string {
+	return fmt.Sprintf("Peer Identity [% x] cannot be validated: %s: [%s]", e.PeerIdentity, e.Reason, e.Err)
+}
+
+// IdentityValidationFailure returns the reason of err, if err is an
+// *IdentityValidationError, and nil otherwise. This allows handling
+// each class of validation failures differently:
+//
+//	switch mcs.IdentityValidationFailure(err) {
+//	case mcs.ErrIdentityExpired, mcs.ErrIdentityRevoked:
+//		...
+//	}
+func IdentityValidationFailure(err error) error {
+	switch e := err.(type) {
+	case *IdentityValidationError:
+		return e.Reason
+	case *IdentityTooLargeError:
+		return ErrMalformedIdentity
+	}
+	return nil
+}
+
+// IdentityTooLargeError is returned when an identity exceeds
+// the maximum size set by WithMaxIdentitySize. As honest peers
+// do not send such identities, gossip black-lists the peers
+// that do.
+type IdentityTooLargeError struct {
+	// Size is the size of the identity, in bytes
+	Size int
+	// Max is the maximum size of identities, in bytes
+	Max int
+}
+
+func (e *IdentityTooLargeError) Error() string {
+	return fmt.Sprintf("Peer Identity of [%d] bytes exceeds the maximum size of [%d] bytes", e.Size, e.Max)
+}
+
+// Blacklist implements api.BlacklistError
+func (e *IdentityTooLargeError) Blacklist() bool {
+	return true
+}
+
+// StaleMessageError is returned when a message claims
+// a signing time outside of the accepted freshness window
+type StaleMessageError struct {
+	// SignedAt is the signing time claimed by the message
+	SignedAt time.Time
+	// Now is the local time the check was performed at
+	Now time.Time
+	// Window is the maximum accepted age of the message
+	Window time.Duration
+	// ClockSkew is the tolerance for messages signed in the future
+	ClockSkew time.Duration
+}
+
+func (e *StaleMessageError) Error() string {
+	return fmt.Sprintf("Message signed at [%s] is outside the freshness window [%s, %s]",
+		e.SignedAt, e.Now.Add(-e.Window), e.Now.Add(e.ClockSkew))
+}
+
+// NotYetValidError is returned when the certificate
+// of an identity is not valid yet
+type NotYetValidError struct {
+	// NotBefore is the start of the validity of the certificate
+	NotBefore time.Time
+	// Now is the local time the check was performed at
+	Now time.Time
+	// ClockSkew is the tolerance for certificates valid in the future
+	ClockSkew time.Duration
+}
+
+func (e *NotYetValidError) Error() string {
+	return fmt.Sprintf("Certificate is not valid before [%s], current time is [%s] with clock skew [%s]",
+		e.NotBefore, e.Now, e.ClockSkew)
+}
+
+// DataHashMismatchError is returned when the data of a
+// block does not match the data hash in its header
+type DataHashMismatchError struct {
+	// BlockNumber is the number of the block
+	BlockNumber uint64
+	// Expected is the data hash in the block's header
+	Expected []byte
+	// Computed is the hash of the block's data
+	Computed []byte
+}
+
+func (e *DataHashMismatchError) Error() string {
+	return fmt.Sprintf("Data hash of block [%d] does not match its header. Expected [% x]. Computed [% x]",
+		e.BlockNumber, e.Expected, e.Computed)
+}
+
+// TooManySignaturesError is returned when a block carries
+// more signatures than VerifyBlock processes
+type TooManySignaturesError struct {
+	// BlockNumber is the number of the block
+	BlockNumber uint64
+	// Signatures is the number of signatures of the block
+	Signatures int
+	// Max is the maximum number of signatures processed
+	Max int
+}
+
+func (e *TooManySignaturesError) Error() string {
+	return fmt.Sprintf("Block [%d] carries [%d] signatures, at most [%d] are processed", e.BlockNumber, e.Signatures, e.Max)
+}
+
+// InvalidDelegationError is returned when a delegate
+// certificate has not been validly issued by its delegator
+type InvalidDelegationError struct {
+	// Err is the reason the delegation does not hold
+	Err error
+}
+
+func (e *InvalidDelegationError) Error() string {
+	return fmt.Sprintf("Invalid delegation: [%s]", e.Err)
+}
+
+// InvalidSignatureError is returned when a signature
+// is not valid under a key otherwise trusted
+type InvalidSignatureError struct {
+	// Err is the reason the signature is not valid
+	Err error
+}
+
+func (e *InvalidSignatureError) Error() string {
+	return fmt.Sprintf("Invalid signature: [%s]", e.Err)
+}
+
+// NoSharedChannelError is returned when a peer is not
+// a member of any of the channels this peer has joined
+type NoSharedChannelError struct {
+	// PeerIdentity is the identity of the peer
+	PeerIdentity []byte
+}
+
+func (e *NoSharedChannelError) Error() string {
+	return fmt.Sprintf("Peer identity [% x] shares no channel with this peer", e.PeerIdentity)
+}
+
+// PolicyEvaluationError is returned when a signature
+// does not satisfy one or more of the required policies
+type PolicyEvaluationError struct {
+	// ChainID is the channel the policies belong to
+	ChainID common.ChainID
+	// Policies lists the names of the policies that were
+	// not satisfied, in the order they were evaluated
+	Policies []string
+	// Errors maps the name of each policy that was not
+	// satisfied to the reason of the failure
+	Errors map[string]error
+}
+
+func (e *PolicyEvaluationError) add(policyName string, err error) {
+	if e.Errors == nil {
+		e.Errors = make(map[string]error)
+	}
+	e.Policies = append(e.Policies, policyName)
+	e.Errors[policyName] = err
+}
+
+func (e *PolicyEvaluationError) Error() string {
+	failures := make([]string, len(e.Policies))
+	for i, policyName := range e.Policies {
+		failures[i] = fmt.Sprintf("%s: %s", policyName, e.Errors[policyName])
+	}
+	return fmt.Sprintf("Signature does not satisfy policies on channel [%s]: [%s]", string(e.ChainID), strings.Join(failures, "; "))
+}
+
+// PolicyPanickedError is returned when
+// the evaluation of a policy panics
+type PolicyPanickedError struct {
+	// ChainID is the channel the policy belongs to
+	ChainID common.ChainID
+	// Policy is the name of the policy
+	Policy string
+	// Value is the value the evaluation panicked with
+	Value interface{}
+}
+
+func (e *PolicyPanickedError) Error() string {
+	return fmt.Sprintf("Evaluation of policy [%s] on channel [%s] panicked: [%v]", e.Policy, string(e.ChainID), e.Value)
+}
+
+// ChannelConfigChangedError is returned when the configuration
+// of a channel changes while a batch of blocks is being verified
+type ChannelConfigChangedError struct {
+	// ChainID is the channel whose configuration changed
+	ChainID common.ChainID
+}
+
+func (e *ChannelConfigChangedError) Error() string {
+	return fmt.Sprintf("Configuration of channel [%s] changed during verification", string(e.ChainID))
+}
+
+// ConfigMovedError is returned when the configuration of a channel
+// is not at the sequence number a verification expects
+type ConfigMovedError struct {
+	// ChainID is the channel whose configuration moved
+	ChainID common.ChainID
+	// Expected is the expected configuration sequence
+	Expected uint64
+	// Current is the current configuration sequence
+	Current uint64
+}
+
+func (e *ConfigMovedError) Error() string {
+	return fmt.Sprintf("Configuration of channel [%s] is at sequence [%d], expected [%d]", string(e.ChainID), e.Current, e.Expected)
+}
+
+// PolicyNotFoundError is returned when the policy manager
+// of a channel, or one of its policies, cannot be found
+type PolicyNotFoundError struct {
+	// ChainID is the channel the policy belongs to
+	ChainID common.ChainID
+	// Policy is the name of the policy, or empty if
+	// the policy manager of the channel is missing
+	Policy string
+}
+
+func (e *PolicyNotFoundError) Error() string {
+	if e.Policy == "" {
+		return fmt.Sprintf("No policy manager found for channel [%s]", string(e.ChainID))
+	}
+	return fmt.Sprintf("No policy [%s] found for channel [%s]", e.Policy, string(e.ChainID))
+}
@@ -0,0 +1,84 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcs
+
+import (
+	"math"
+	"time"
+
+	"github.com/hyperledger/fabric/gossip/api"
+	"github.com/hyperledger/fabric/msp"
+)
+
+// ExpiringIdentity is implemented by msp.Identity
+// implementations that know when they expire
+type ExpiringIdentity interface {
+	msp.Identity
+
+	// ExpiresAt returns the time the identity expires at
+	ExpiresAt() time.Time
+}
+
+// VerifyWithExpiry checks that signature is a valid signature of message
+// under a peer's verification key, as Verify does, and returns how long
+// the signer's identity is still valid for.
+// The expiration is taken from the validated identity, if it implements
+// ExpiringIdentity, or from its x.509 certificate otherwise. If the
+// expiration of the identity is unknown, the maximum duration is returned.
+// If the identity has already expired, a negative duration is returned,
+// along with the verification error.
+func (s *mspMessageCryptoService) VerifyWithExpiry(peerIdentity api.PeerIdentityType, signature, message []byte) (time.Duration, error) {
+	identity, err := s.verify(logger, peerIdentity, signature, message)
+
+	timeToExpiry := time.Duration(math.MaxInt64)
+	if expiresAt, ok := getExpiration(peerIdentity, identity); ok {
+		timeToExpiry = expiresAt.Sub(time.Now())
+	}
+
+	return timeToExpiry, err
+}
+
+// Expiration returns the time the identity of a remote peer expires at,
+// so that the identities of expired peers can be purged.
+// The expiration is taken as in VerifyWithExpiry. If the expiration of
+// the identity is unknown, the zero time is returned.
+// If the identity is invalid, it returns an error.
+func (s *mspMessageCryptoService) Expiration(peerIdentity api.PeerIdentityType) (time.Time, error) {
+	identity, _, err := s.getValidatedIdentity(logger, peerIdentity)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	expiresAt, _ := getExpiration(peerIdentity, identity)
+	return expiresAt, nil
+}
+
+// getExpiration returns the expiration time of identity, the
+// validated identity of peerIdentity, if any. identity may be nil
+// if peerIdentity could not be validated.
+func getExpiration(peerIdentity api.PeerIdentityType, identity msp.Identity) (time.Time, bool) {
+	if expiringIdentity, ok := identity.(ExpiringIdentity); ok {
+		return expiringIdentity.ExpiresAt(), true
+	}
+
+	cert, err := getCertificate(peerIdentity)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return cert.NotAfter, true
+}
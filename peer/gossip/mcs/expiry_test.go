@@ -0,0 +1,108 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcs
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math"
+	"testing"
+	"time"
+
+	mockpolicies "github.com/hyperledger/fabric/common/mocks/policies"
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/gossip/api"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockExpiringIdentity struct {
+	mockIdentity
+	expiresAt time.Time
+}
+
+func (id *mockExpiringIdentity) ExpiresAt() time.Time {
+	return id.expiresAt
+}
+
+func TestVerifyWithExpiry(t *testing.T) {
+	chainID := "expirychannel"
+	setupMockChannel(chainID, "ExpiryOrg")
+	pm := newMockPolicyManager()
+	pm.setPolicy(chainID, policies.ChannelApplicationReaders, &mockChannelPolicy{chainID: chainID})
+	mcs := New(pm).(*mspMessageCryptoService)
+	msg := []byte("Hello World!!!")
+
+	// x.509 identity
+	expiresAt := time.Now().Add(48 * time.Hour)
+	peerIdentity := newCertPeerIdentity(t, "ExpiryOrg", &x509.Certificate{
+		Subject:  pkix.Name{CommonName: "peer0"},
+		NotAfter: expiresAt,
+	})
+	timeToExpiry, err := mcs.VerifyWithExpiry(peerIdentity, mockSign(msg), msg)
+	assert.NoError(t, err)
+	assert.InDelta(t, (48 * time.Hour).Seconds(), timeToExpiry.Seconds(), 5)
+
+	// Invalid signature
+	timeToExpiry, err = mcs.VerifyWithExpiry(peerIdentity, []byte("bad"), msg)
+	assert.Error(t, err)
+	assert.True(t, timeToExpiry > 0)
+
+	// Expired identity
+	expired := newCertPeerIdentity(t, "ExpiryOrg", &x509.Certificate{
+		Subject:   pkix.Name{CommonName: "peer1"},
+		NotBefore: time.Now().Add(-2 * time.Hour),
+		NotAfter:  time.Now().Add(-time.Hour),
+	})
+	timeToExpiry, _ = mcs.VerifyWithExpiry(expired, mockSign(msg), msg)
+	assert.True(t, timeToExpiry < 0)
+
+	// Unknown expiration
+	timeToExpiry, err = mcs.VerifyWithExpiry(newMockPeerIdentity("ExpiryOrg", "peer2"), mockSign(msg), msg)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(math.MaxInt64), timeToExpiry)
+
+	// The expiration of the validated identity is preferred
+	identity := &mockExpiringIdentity{expiresAt: expiresAt.Add(time.Hour)}
+	at, ok := getExpiration(peerIdentity, identity)
+	assert.True(t, ok)
+	assert.Equal(t, identity.expiresAt, at)
+}
+
+func TestExpiration(t *testing.T) {
+	setupMockChannel("expirationchannel", "ExpirationOrg")
+	mcs := New(&mockpolicies.PolicyManagerMgmt{})
+	assert.Implements(t, (*api.ExpirationAwareMessageCryptoService)(nil), mcs)
+	expirationAware := mcs.(api.ExpirationAwareMessageCryptoService)
+
+	// x.509 identity
+	notAfter := time.Now().Add(48 * time.Hour)
+	expiresAt, err := expirationAware.Expiration(newCertPeerIdentity(t, "ExpirationOrg", &x509.Certificate{
+		Subject:  pkix.Name{CommonName: "peer0"},
+		NotAfter: notAfter,
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, notAfter.Unix(), expiresAt.Unix())
+
+	// Unknown expiration
+	expiresAt, err = expirationAware.Expiration(newMockPeerIdentity("ExpirationOrg", "peer1"))
+	assert.NoError(t, err)
+	assert.True(t, expiresAt.IsZero())
+
+	// Invalid identity
+	_, err = expirationAware.Expiration(newMockPeerIdentity("UnknownOrg", "peer0"))
+	assert.Error(t, err)
+}
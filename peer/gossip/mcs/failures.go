@@ -0,0 +1,97 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcs
+
+import (
+	"time"
+
+	"github.com/hyperledger/fabric/gossip/api"
+	"github.com/hyperledger/fabric/gossip/common"
+)
+
+// The operations whose failures are published
+// by the MessageCryptoService
+const (
+	// OperationValidateIdentity is the validation
+	// of an identity against the MSPs
+	OperationValidateIdentity = "ValidateIdentity"
+	// OperationVerify is the verification of a signature
+	// of a member of this peer's organization
+	OperationVerify = "Verify"
+	// OperationVerifyByChannel is the verification of
+	// a signature against the policies of a channel
+	OperationVerifyByChannel = "VerifyByChannel"
+	// OperationVerifyBlock is the verification of a block
+	OperationVerifyBlock = "VerifyBlock"
+)
+
+// VerificationFailure describes a failed verification
+type VerificationFailure struct {
+	// Operation is the failed operation
+	Operation string
+
+	// PKIID is the PKI-ID of the identity being verified,
+	// nil if the operation is not about an identity
+	PKIID common.PKIidType
+
+	// ChainID is the channel the operation refers to, if any
+	ChainID common.ChainID
+
+	// Reason is the error the operation failed with
+	Reason error
+
+	// Timestamp is the time the operation failed
+	Timestamp time.Time
+}
+
+// WithVerificationFailures makes the MessageCryptoService publish to
+// failures every failed identity validation, signature verification,
+// policy evaluation and block verification, so that a single consumer
+// can monitor them all.
+// Publishing never blocks: failures should be a buffered channel, and
+// the failures that do not fit in it are dropped and counted in the
+// VerificationFailuresDropped metric.
+func WithVerificationFailures(failures chan<- *VerificationFailure) Option {
+	return func(s *mspMessageCryptoService) {
+		s.failures = failures
+	}
+}
+
+// publishFailure publishes the failure of operation on peerIdentity
+// and chainID, both optional, with reason, if it is not nil
+func (s *mspMessageCryptoService) publishFailure(operation string, peerIdentity api.PeerIdentityType, chainID common.ChainID, reason error) {
+	if s.failures == nil || reason == nil {
+		return
+	}
+
+	failure := &VerificationFailure{
+		Operation: operation,
+		ChainID:   chainID,
+		Reason:    reason,
+		Timestamp: time.Now(),
+	}
+	if len(peerIdentity) != 0 {
+		failure.PKIID = s.GetPKIidOfCert(peerIdentity)
+	}
+
+	select {
+	case s.failures <- failure:
+	default:
+		s.metrics.countDroppedFailure()
+		logger.Debugf("Verification failures channel is full, dropping failure of [%s]: [%s]", operation, reason)
+	}
+}
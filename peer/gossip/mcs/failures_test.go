@@ -0,0 +1,84 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcs
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/gossip/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerificationFailures(t *testing.T) {
+	chainID := "failureschannel"
+	setupMockChannel(chainID, "FailuresOrg")
+	failures := make(chan *VerificationFailure, 10)
+	pm := newMockPolicyManager()
+	pm.setPolicy(chainID, policies.ChannelApplicationReaders, &mockChannelPolicy{chainID: chainID})
+	pm.setPolicy(chainID, policies.BlockValidation, &mockChannelPolicy{chainID: chainID})
+	mcs := New(pm, WithVerificationFailures(failures)).(*mspMessageCryptoService)
+
+	peer := newMockPeerIdentity("FailuresOrg", "peer0")
+	unknown := newMockPeerIdentity("UnknownOrg", "peer0")
+	msg := []byte("msg")
+
+	// Successful operations are not published
+	assert.NoError(t, mcs.ValidateIdentity(peer))
+	assert.NoError(t, mcs.VerifyByChannel(common.ChainID(chainID), peer, mockSign(msg), msg))
+	assert.NoError(t, mcs.VerifyBlock(common.ChainID(chainID), mockBlock(chainID, peer)))
+	assert.Empty(t, failures)
+
+	assert.Error(t, mcs.ValidateIdentity(unknown))
+	assert.Error(t, mcs.VerifyByChannel(common.ChainID(chainID), peer, []byte("bad signature"), msg))
+	assert.Error(t, mcs.VerifyBlock(common.ChainID(chainID), mockBlock(chainID)))
+
+	for _, expected := range []struct {
+		operation string
+		pkiID     common.PKIidType
+		chainID   common.ChainID
+	}{
+		{OperationValidateIdentity, mcs.GetPKIidOfCert(unknown), nil},
+		{OperationVerifyByChannel, mcs.GetPKIidOfCert(peer), common.ChainID(chainID)},
+		{OperationVerifyBlock, nil, common.ChainID(chainID)},
+	} {
+		failure := <-failures
+		assert.Equal(t, expected.operation, failure.Operation)
+		assert.Equal(t, expected.pkiID, failure.PKIID)
+		assert.Equal(t, expected.chainID, failure.ChainID)
+		assert.Error(t, failure.Reason)
+		assert.False(t, failure.Timestamp.IsZero())
+	}
+	assert.Empty(t, failures)
+}
+
+func TestVerificationFailuresNeverBlock(t *testing.T) {
+	provider := newMockMetricsProvider()
+	failures := make(chan *VerificationFailure, 2)
+	mcs := New(newMockPolicyManager(), WithVerificationFailures(failures), WithMetricsProvider(provider))
+
+	unknown := newMockPeerIdentity("UnknownOrg", "peer0")
+	for i := 0; i < 5; i++ {
+		assert.Error(t, mcs.ValidateIdentity(unknown))
+	}
+	assert.Len(t, failures, 2)
+	assert.Equal(t, []float64{1, 1, 1}, provider.get(VerificationFailuresDropped))
+
+	// Unbuffered channel without consumer
+	mcs = New(newMockPolicyManager(), WithVerificationFailures(make(chan *VerificationFailure)))
+	assert.Error(t, mcs.ValidateIdentity(unknown))
+}
@@ -0,0 +1,107 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcs
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/cauthdsl"
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/gossip/common"
+	"github.com/hyperledger/fabric/msp/mgmt"
+	"github.com/stretchr/testify/assert"
+)
+
+// opaquePolicy hides whether the policy it wraps
+// reduces to a single principal
+type opaquePolicy struct {
+	policies.Policy
+}
+
+func newMemberPolicy(t testing.TB, chainID, mspID string) policies.Policy {
+	data, err := proto.Marshal(cauthdsl.SignedByMspMember(mspID))
+	assert.NoError(t, err)
+	policy, err := cauthdsl.NewPolicyProvider(mgmt.GetManagerForChain(chainID)).NewPolicy(data)
+	assert.NoError(t, err)
+	return policy
+}
+
+func TestVerifyByChannelSinglePrincipal(t *testing.T) {
+	chainID := "fastpathchannel"
+	setupMockChannel(chainID, "FastOrg").validateErrs["revoked"] = assert.AnError
+	policy := newMemberPolicy(t, chainID, "FastOrg")
+	_, ok := policy.(policies.SinglePrincipalPolicy).SinglePrincipal()
+	assert.True(t, ok)
+
+	fast := newMockPolicyManager()
+	fast.setPolicy(chainID, policies.ChannelApplicationReaders, policy)
+	slow := newMockPolicyManager()
+	slow.setPolicy(chainID, policies.ChannelApplicationReaders, &opaquePolicy{policy})
+
+	msg := []byte("Hello World!!!")
+	for _, test := range []struct {
+		peerIdentity []byte
+		signature    []byte
+		valid        bool
+	}{
+		{newMockPeerIdentity("FastOrg", "peer0"), mockSign(msg), true},
+		{newMockPeerIdentity("FastOrg", "peer0"), []byte("bad"), false},
+		{newMockPeerIdentity("FastOrg", "revoked"), mockSign(msg), false},
+		{newMockPeerIdentity("OtherOrg", "peer0"), mockSign(msg), false},
+	} {
+		for _, pm := range []policies.Manager{fast, slow} {
+			err := New(pm).VerifyByChannel(common.ChainID(chainID), test.peerIdentity, test.signature, msg)
+			if test.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		}
+	}
+}
+
+func benchmarkVerifyByChannel(b *testing.B, opaque bool) {
+	chainID := "fastpathbenchchannel"
+	setupMockChannel(chainID, "FastOrg")
+	policy := newMemberPolicy(b, chainID, "FastOrg")
+	if opaque {
+		policy = &opaquePolicy{policy}
+	}
+	pm := newMockPolicyManager()
+	pm.setPolicy(chainID, policies.ChannelApplicationReaders, policy)
+	mcs := New(pm)
+
+	peerIdentity := newMockPeerIdentity("FastOrg", "peer0")
+	msg := []byte("Hello World!!!")
+	signature := mockSign(msg)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := mcs.VerifyByChannel(common.ChainID(chainID), peerIdentity, signature, msg); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkVerifyByChannelSinglePrincipal(b *testing.B) {
+	benchmarkVerifyByChannel(b, false)
+}
+
+func BenchmarkVerifyByChannelPolicyEvaluation(b *testing.B) {
+	benchmarkVerifyByChannel(b, true)
+}
@@ -0,0 +1,85 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcs
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/hyperledger/fabric/gossip/api"
+)
+
+// FramingMode tells which bytes of a length-prefixed frame are
+// covered by the signature of the frame.
+// A frame is a payload preceded by its length, encoded as an unsigned
+// varint, as protobuf does for length-delimited messages.
+type FramingMode int
+
+const (
+	// FrameUnsignedPrefix means that the signature covers the
+	// payload only, the length prefix is not signed
+	FrameUnsignedPrefix FramingMode = iota
+	// FrameSignedPrefix means that the signature covers the
+	// whole frame, length prefix included
+	FrameSignedPrefix
+)
+
+func (m FramingMode) String() string {
+	switch m {
+	case FrameUnsignedPrefix:
+		return "FrameUnsignedPrefix"
+	case FrameSignedPrefix:
+		return "FrameSignedPrefix"
+	default:
+		return fmt.Sprintf("FramingMode(%d)", int(m))
+	}
+}
+
+// VerifyFramed checks that signature is a valid signature, under a peer's
+// verification key, of the bytes of frame selected by mode, as Verify does.
+// frame must be a single, complete length-prefixed frame: callers do not
+// need to strip the framing beforehand.
+func (s *mspMessageCryptoService) VerifyFramed(peerIdentity api.PeerIdentityType, signature, frame []byte, mode FramingMode) error {
+	signedBytes, err := getFrameSignedBytes(frame, mode)
+	if err != nil {
+		return err
+	}
+
+	return s.Verify(peerIdentity, signature, signedBytes)
+}
+
+// getFrameSignedBytes returns the bytes of frame covered
+// by its signature, according to mode
+func getFrameSignedBytes(frame []byte, mode FramingMode) ([]byte, error) {
+	length, n := binary.Uvarint(frame)
+	if n <= 0 {
+		return nil, errors.New("Invalid frame. Failed decoding length prefix.")
+	}
+	if uint64(len(frame)-n) != length {
+		return nil, fmt.Errorf("Invalid frame. Length prefix is [%d], but payload is [%d] bytes long.", length, len(frame)-n)
+	}
+
+	switch mode {
+	case FrameUnsignedPrefix:
+		return frame[n:], nil
+	case FrameSignedPrefix:
+		return frame, nil
+	default:
+		return nil, fmt.Errorf("Invalid framing mode [%s]", mode)
+	}
+}
@@ -0,0 +1,51 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcs
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyFramed(t *testing.T) {
+	chainID := "framingchannel"
+	setupMockChannel(chainID, "FramingOrg")
+	pm := newMockPolicyManager()
+	pm.setPolicy(chainID, policies.ChannelApplicationReaders, &mockChannelPolicy{chainID: chainID})
+	mcs := New(pm).(*mspMessageCryptoService)
+	peerIdentity := newMockPeerIdentity("FramingOrg", "peer0")
+
+	payload := []byte("Hello World!!!")
+	frame := append(proto.EncodeVarint(uint64(len(payload))), payload...)
+
+	// The length prefix is not signed
+	assert.NoError(t, mcs.VerifyFramed(peerIdentity, mockSign(payload), frame, FrameUnsignedPrefix))
+	assert.Error(t, mcs.VerifyFramed(peerIdentity, mockSign(frame), frame, FrameUnsignedPrefix))
+
+	// The length prefix is signed
+	assert.NoError(t, mcs.VerifyFramed(peerIdentity, mockSign(frame), frame, FrameSignedPrefix))
+	assert.Error(t, mcs.VerifyFramed(peerIdentity, mockSign(payload), frame, FrameSignedPrefix))
+
+	// Malformed frames
+	assert.Error(t, mcs.VerifyFramed(peerIdentity, mockSign(payload), nil, FrameUnsignedPrefix))
+	assert.Error(t, mcs.VerifyFramed(peerIdentity, mockSign(payload), frame[:len(frame)-1], FrameUnsignedPrefix))
+	assert.Error(t, mcs.VerifyFramed(peerIdentity, mockSign(payload), append(frame, 0), FrameUnsignedPrefix))
+	assert.Error(t, mcs.VerifyFramed(peerIdentity, mockSign(payload), frame, FramingMode(42)))
+}
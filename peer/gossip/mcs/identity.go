@@ -0,0 +1,231 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcs
+
+import (
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/gossip/api"
+	"github.com/hyperledger/fabric/msp"
+)
+
+// attributesOID is the ASN.1 object identifier of the
+// x.509 extension Fabric CA stores identity attributes in
+var attributesOID = asn1.ObjectIdentifier{1, 2, 3, 4, 5, 6, 7, 8, 1}
+
+// AttributesIdentity is implemented by msp.Identity
+// implementations that expose identity attributes
+type AttributesIdentity interface {
+	msp.Identity
+
+	// GetAttributes returns the attributes of this identity
+	GetAttributes() map[string]string
+}
+
+// EnrollmentIDIdentity is implemented by msp.Identity
+// implementations that expose an enrollment ID
+type EnrollmentIDIdentity interface {
+	msp.Identity
+
+	// GetEnrollmentID returns the enrollment ID of this identity
+	GetEnrollmentID() string
+}
+
+// WithAllowedCriticalExtensions makes identity validation ignore the unknown
+// critical x.509 extensions whose object identifiers are in oids, for
+// interoperability with certificates issued by non-Fabric CAs. This applies
+// to identities whose MSP supports validation options (see
+// msp.OptsValidator), the others are validated as usual.
+// By default, identities carrying unknown critical extensions are rejected.
+// Ignoring a critical extension means ignoring the constraints its issuer
+// meant to impose on the use of the certificate, hence only extensions
+// known to be irrelevant to the peer should be allowed.
+func WithAllowedCriticalExtensions(oids ...asn1.ObjectIdentifier) Option {
+	return func(s *mspMessageCryptoService) {
+		s.validationOpts = &msp.ValidationOpts{AllowedCriticalExtensions: oids}
+	}
+}
+
+// validate validates identity, applying
+// the validation options, if any
+func (s *mspMessageCryptoService) validate(identity msp.Identity) error {
+	if s.validationOpts != nil {
+		if v, ok := identity.(msp.OptsValidator); ok {
+			return v.ValidateOpts(s.validationOpts)
+		}
+	}
+
+	return identity.Validate()
+}
+
+// ValidateIdentityWithAttributes validates the identity of a remote peer,
+// as ValidateIdentity does, and returns the attributes of the identity.
+// Attributes are populated by MSPs whose identities implement
+// AttributesIdentity and, for x.509 MSPs, from the attributes
+// extension (OID 1.2.3.4.5.6.7.8.1) of certificates issued by
+// Fabric CA. For any other identity, an empty map is returned.
+func (s *mspMessageCryptoService) ValidateIdentityWithAttributes(peerIdentity api.PeerIdentityType) (map[string]string, error) {
+	identity, _, err := s.getValidatedIdentity(logger, peerIdentity)
+	if err != nil {
+		return nil, err
+	}
+
+	return getIdentityAttributes(peerIdentity, identity)
+}
+
+// VerifyAndGetEnrollmentID checks that signature is a valid signature of
+// message under a peer's verification key, as Verify does, and returns
+// the enrollment ID of the signer. The enrollment ID is provided by MSPs
+// whose identities implement EnrollmentIDIdentity and, for x.509 MSPs,
+// it is the common name of the subject of the certificate. For any other
+// identity, an empty enrollment ID is returned.
+func (s *mspMessageCryptoService) VerifyAndGetEnrollmentID(peerIdentity api.PeerIdentityType, signature, message []byte) (enrollmentID string, err error) {
+	identity, err := s.verify(logger, peerIdentity, signature, message)
+	if err != nil {
+		return "", err
+	}
+
+	return getEnrollmentID(peerIdentity, identity), nil
+}
+
+func getEnrollmentID(peerIdentity api.PeerIdentityType, identity msp.Identity) string {
+	if enrollmentIdentity, ok := identity.(EnrollmentIDIdentity); ok {
+		return enrollmentIdentity.GetEnrollmentID()
+	}
+
+	cert, err := getCertificate(peerIdentity)
+	if err != nil {
+		// Not an x.509 identity
+		return ""
+	}
+
+	return cert.Subject.CommonName
+}
+
+func getIdentityAttributes(peerIdentity api.PeerIdentityType, identity msp.Identity) (map[string]string, error) {
+	attrs := make(map[string]string)
+
+	if attrsIdentity, ok := identity.(AttributesIdentity); ok {
+		for name, value := range attrsIdentity.GetAttributes() {
+			attrs[name] = value
+		}
+		return attrs, nil
+	}
+
+	cert, err := getCertificate(peerIdentity)
+	if err != nil {
+		// Not an x.509 identity
+		logger.Debugf("Identity [% x] carries no x.509 certificate: [%s]", []byte(peerIdentity), err)
+		return attrs, nil
+	}
+
+	for _, ext := range cert.Extensions {
+		if !ext.Id.Equal(attributesOID) {
+			continue
+		}
+
+		caAttrs := &struct {
+			Attrs map[string]string `json:"attrs"`
+		}{}
+		if err := json.Unmarshal(ext.Value, caAttrs); err != nil {
+			return nil, fmt.Errorf("Failed unmarshalling attributes of identity [% x]: [%s]", []byte(peerIdentity), err)
+		}
+		for name, value := range caAttrs.Attrs {
+			attrs[name] = value
+		}
+	}
+
+	return attrs, nil
+}
+
+// newIdentityValidationError returns an *IdentityValidationError telling
+// why peerIdentity cannot be validated. validationErr is the error the
+// last validation of peerIdentity failed with, or nil if no MSP could
+// deserialize peerIdentity.
+func (s *mspMessageCryptoService) newIdentityValidationError(managers *mspManagers, peerIdentity api.PeerIdentityType, validationErr error) error {
+	e := &IdentityValidationError{PeerIdentity: peerIdentity, Reason: ErrInvalidIdentity, Err: validationErr}
+
+	if validationErr == nil {
+		e.Err = errors.New("No MSP found able to do that.")
+
+		sID := &msp.SerializedIdentity{}
+		if err := proto.Unmarshal(peerIdentity, sID); err != nil {
+			e.Reason, e.Err = ErrMalformedIdentity, err
+		} else if s.isKnownMSP(managers, sID.Mspid) {
+			e.Reason = ErrMalformedIdentity
+		} else {
+			e.Reason = ErrUnknownMSP
+		}
+		return e
+	}
+
+	if cert, err := getCertificate(peerIdentity); err == nil && !time.Now().Before(cert.NotAfter) {
+		e.Reason = ErrIdentityExpired
+	} else if strings.Contains(validationErr.Error(), "revoked") {
+		// Not a typed error of the MSPs
+		e.Reason = ErrIdentityRevoked
+	}
+	return e
+}
+
+// isKnownMSP returns whether mspID is the identifier of a
+// local MSP or of an MSP of one of the channels in managers
+func (s *mspMessageCryptoService) isKnownMSP(managers *mspManagers, mspID string) bool {
+	for _, localMSP := range s.getLocalMSPs() {
+		if localMSPID, err := localMSP.GetIdentifier(); err == nil && localMSPID == mspID {
+			return true
+		}
+	}
+	for _, chainID := range managers.chainIDs {
+		msps, err := managers.managers[chainID].GetMSPs()
+		if err != nil {
+			continue
+		}
+		if _, ok := msps[mspID]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// getCertificate returns the x.509 certificate carried by
+// peerIdentity, if peerIdentity is a SerializedIdentity
+// whose identity bytes are a PEM-encoded certificate.
+// It is the capability check guarding every x.509-specific
+// check: identities of MSP types that carry no certificate
+// make it fail, and such checks are then skipped.
+func getCertificate(peerIdentity api.PeerIdentityType) (*x509.Certificate, error) {
+	sID := &msp.SerializedIdentity{}
+	if err := proto.Unmarshal(peerIdentity, sID); err != nil {
+		return nil, fmt.Errorf("Failed unmarshalling serialized identity: [%s]", err)
+	}
+
+	bl, _ := pem.Decode(sID.IdBytes)
+	if bl == nil {
+		return nil, errors.New("Failed decoding PEM structure")
+	}
+
+	return x509.ParseCertificate(bl.Bytes)
+}
@@ -0,0 +1,275 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	mockpolicies "github.com/hyperledger/fabric/common/mocks/policies"
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/gossip/api"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/stretchr/testify/assert"
+)
+
+// newCertPeerIdentity returns a peer identity of MSP mspID carrying
+// a self-signed certificate, customized by template
+func newCertPeerIdentity(t *testing.T, mspID string, template *x509.Certificate) api.PeerIdentityType {
+	peerIdentity, _, _ := newCertPeerIdentityWithKey(t, mspID, template)
+	return peerIdentity
+}
+
+// newCertPeerIdentityWithKey returns, along with the peer identity
+// returned by newCertPeerIdentity, its certificate and key
+func newCertPeerIdentityWithKey(t *testing.T, mspID string, template *x509.Certificate) (api.PeerIdentityType, *x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	if template.SerialNumber == nil {
+		template.SerialNumber = big.NewInt(1)
+	}
+	if template.NotBefore.IsZero() {
+		template.NotBefore = time.Now().Add(-time.Hour)
+	}
+	if template.NotAfter.IsZero() {
+		template.NotAfter = time.Now().Add(time.Hour)
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+
+	raw, err := proto.Marshal(&msp.SerializedIdentity{
+		Mspid:   mspID,
+		IdBytes: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	})
+	assert.NoError(t, err)
+
+	return raw, cert, key
+}
+
+type mockAttributesIdentity struct {
+	mockIdentity
+	attrs map[string]string
+}
+
+func (id *mockAttributesIdentity) GetAttributes() map[string]string {
+	return id.attrs
+}
+
+func TestValidateIdentityWithAttributes(t *testing.T) {
+	setupMockChannel("attrschannel", "AttrsOrg")
+	mcs := New(&mockpolicies.PolicyManagerMgmt{}).(*mspMessageCryptoService)
+
+	// An x.509 identity issued by Fabric CA with attributes
+	withAttrs := newCertPeerIdentity(t, "AttrsOrg", &x509.Certificate{
+		Subject: pkix.Name{CommonName: "peer0"},
+		ExtraExtensions: []pkix.Extension{{
+			Id:    attributesOID,
+			Value: []byte(`{"attrs":{"department":"treasury","hf.Type":"peer"}}`),
+		}},
+	})
+	attrs, err := mcs.ValidateIdentityWithAttributes(withAttrs)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"department": "treasury", "hf.Type": "peer"}, attrs)
+
+	// An x.509 identity without attributes
+	withoutAttrs := newCertPeerIdentity(t, "AttrsOrg", &x509.Certificate{Subject: pkix.Name{CommonName: "peer1"}})
+	attrs, err = mcs.ValidateIdentityWithAttributes(withoutAttrs)
+	assert.NoError(t, err)
+	assert.Empty(t, attrs)
+	assert.NotNil(t, attrs)
+
+	// A non x.509 identity
+	attrs, err = mcs.ValidateIdentityWithAttributes(newMockPeerIdentity("AttrsOrg", "peer2"))
+	assert.NoError(t, err)
+	assert.Empty(t, attrs)
+
+	// Malformed attributes
+	malformed := newCertPeerIdentity(t, "AttrsOrg", &x509.Certificate{
+		Subject:         pkix.Name{CommonName: "peer3"},
+		ExtraExtensions: []pkix.Extension{{Id: attributesOID, Value: []byte("attrs")}},
+	})
+	_, err = mcs.ValidateIdentityWithAttributes(malformed)
+	assert.Error(t, err)
+
+	// An invalid identity
+	_, err = mcs.ValidateIdentityWithAttributes(newMockPeerIdentity("UnknownOrg", "peer0"))
+	assert.Error(t, err)
+
+	// An identity exposing its attributes directly
+	identity := &mockAttributesIdentity{attrs: map[string]string{"role": "auditor"}}
+	attrs, err = getIdentityAttributes(nil, identity)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"role": "auditor"}, attrs)
+}
+
+type mockOptsIdentity struct {
+	mockIdentity
+	opts *msp.ValidationOpts
+}
+
+func (id *mockOptsIdentity) ValidateOpts(opts *msp.ValidationOpts) error {
+	id.opts = opts
+	return nil
+}
+
+func TestWithAllowedCriticalExtensions(t *testing.T) {
+	oid := asn1.ObjectIdentifier{1, 2, 3, 4, 5, 99}
+
+	// Strict by default
+	mcs := New(&mockpolicies.PolicyManagerMgmt{}).(*mspMessageCryptoService)
+	identity := &mockOptsIdentity{mockIdentity: mockIdentity{msp: &mockMSP{}}}
+	assert.NoError(t, mcs.validate(identity))
+	assert.Nil(t, identity.opts)
+
+	// Allowlisted extensions are passed down to the identity
+	mcs = New(&mockpolicies.PolicyManagerMgmt{}, WithAllowedCriticalExtensions(oid)).(*mspMessageCryptoService)
+	assert.NoError(t, mcs.validate(identity))
+	assert.Equal(t, []asn1.ObjectIdentifier{oid}, identity.opts.AllowedCriticalExtensions)
+
+	// Identities not supporting validation options are validated as usual
+	setupMockChannel("critextchannel", "CritExtOrg").validateErrs["peer1"] = errors.New("invalid")
+	assert.NoError(t, mcs.ValidateIdentity(newMockPeerIdentity("CritExtOrg", "peer0")))
+	assert.Error(t, mcs.ValidateIdentity(newMockPeerIdentity("CritExtOrg", "peer1")))
+}
+
+func TestNotYetValidIdentity(t *testing.T) {
+	setupMockChannel("notbeforechannel", "NotBeforeOrg")
+	mcs := New(&mockpolicies.PolicyManagerMgmt{})
+
+	// Validity started in the past
+	assert.NoError(t, mcs.ValidateIdentity(newCertPeerIdentity(t, "NotBeforeOrg", &x509.Certificate{
+		Subject: pkix.Name{CommonName: "peer0"},
+	})))
+
+	// Validity starts in the future
+	notBefore := time.Now().Add(time.Hour)
+	predated := newCertPeerIdentity(t, "NotBeforeOrg", &x509.Certificate{
+		Subject:   pkix.Name{CommonName: "peer1"},
+		NotBefore: notBefore,
+		NotAfter:  notBefore.Add(time.Hour),
+	})
+	err := mcs.ValidateIdentity(predated)
+	assert.IsType(t, &NotYetValidError{}, err)
+	assert.Equal(t, notBefore.Unix(), err.(*NotYetValidError).NotBefore.Unix())
+	assert.Error(t, mcs.Verify(predated, mockSign([]byte("msg")), []byte("msg")))
+
+	// Within the clock skew tolerance
+	mcs = New(&mockpolicies.PolicyManagerMgmt{}, WithClockSkew(2*time.Hour))
+	assert.NoError(t, mcs.ValidateIdentity(predated))
+}
+
+type mockEnrollmentIdentity struct {
+	mockIdentity
+	enrollmentID string
+}
+
+func (id *mockEnrollmentIdentity) GetEnrollmentID() string {
+	return id.enrollmentID
+}
+
+func TestVerifyAndGetEnrollmentID(t *testing.T) {
+	chainID := "enrollmentchannel"
+	setupMockChannel(chainID, "EnrollmentOrg")
+	pm := newMockPolicyManager()
+	pm.setPolicy(chainID, policies.ChannelApplicationReaders, &mockChannelPolicy{chainID: chainID})
+	mcs := New(pm).(*mspMessageCryptoService)
+	msg := []byte("msg")
+
+	// A non x.509 identity
+	peer := newMockPeerIdentity("EnrollmentOrg", "peer0")
+	enrollmentID, err := mcs.VerifyAndGetEnrollmentID(peer, mockSign(msg), msg)
+	assert.NoError(t, err)
+	assert.Empty(t, enrollmentID)
+
+	// Invalid signature
+	_, err = mcs.VerifyAndGetEnrollmentID(peer, []byte("bad signature"), msg)
+	assert.Error(t, err)
+
+	// Invalid identity
+	_, err = mcs.VerifyAndGetEnrollmentID(newMockPeerIdentity("UnknownOrg", "peer0"), mockSign(msg), msg)
+	assert.Error(t, err)
+
+	// An x.509 identity
+	certIdentity := newCertPeerIdentity(t, "EnrollmentOrg", &x509.Certificate{Subject: pkix.Name{CommonName: "user1"}})
+	enrollmentID, err = mcs.VerifyAndGetEnrollmentID(certIdentity, mockSign(msg), msg)
+	assert.NoError(t, err)
+	assert.Equal(t, "user1", enrollmentID)
+
+	// An identity exposing its enrollment ID directly
+	assert.Equal(t, "user2", getEnrollmentID(certIdentity, &mockEnrollmentIdentity{enrollmentID: "user2"}))
+}
+
+func TestIdentityValidationErrors(t *testing.T) {
+	m := setupMockChannel("idvalidationchannel", "IDValidationOrg")
+	mcs := New(&mockpolicies.PolicyManagerMgmt{})
+
+	reason := func(peerIdentity api.PeerIdentityType) error {
+		err := mcs.ValidateIdentity(peerIdentity)
+		assert.IsType(t, &IdentityValidationError{}, err)
+		return IdentityValidationFailure(err)
+	}
+
+	// Malformed identities
+	assert.Equal(t, ErrMalformedIdentity, reason(nil))
+	assert.Equal(t, ErrMalformedIdentity, reason([]byte{0xff, 0xff}))
+
+	// An identity of an unknown MSP
+	assert.Equal(t, ErrUnknownMSP, reason(newMockPeerIdentity("UnknownOrg", "peer0")))
+
+	// A revoked identity
+	m.validateErrs["peer1"] = errors.New("The certificate has been revoked")
+	assert.Equal(t, ErrIdentityRevoked, reason(newMockPeerIdentity("IDValidationOrg", "peer1")))
+
+	// An expired identity
+	expired := newCertPeerIdentity(t, "IDValidationOrg", &x509.Certificate{
+		Subject:   pkix.Name{CommonName: "peer2"},
+		NotBefore: time.Now().Add(-2 * time.Hour),
+		NotAfter:  time.Now().Add(-time.Hour),
+	})
+	sID := &msp.SerializedIdentity{}
+	assert.NoError(t, proto.Unmarshal(expired, sID))
+	m.validateErrs[string(sID.IdBytes)] = errors.New("x509: certificate has expired or is not yet valid")
+	assert.Equal(t, ErrIdentityExpired, reason(expired))
+
+	// An identity invalid for any other reason
+	m.validateErrs["peer3"] = errors.New("untrusted")
+	assert.Equal(t, ErrInvalidIdentity, reason(newMockPeerIdentity("IDValidationOrg", "peer3")))
+
+	// An identity revoked by the online revocation checker
+	mcs = New(&mockpolicies.PolicyManagerMgmt{},
+		WithOnlineRevocationChecker(revokedNames{"peer4": true}),
+		WithOnlineRevocationEnforcement())
+	assert.Equal(t, ErrIdentityRevoked, reason(newMockPeerIdentity("IDValidationOrg", "peer4")))
+
+	// Other errors
+	assert.Nil(t, IdentityValidationFailure(nil))
+	assert.Nil(t, IdentityValidationFailure(errors.New("error")))
+}
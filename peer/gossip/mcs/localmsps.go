@@ -0,0 +1,82 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcs
+
+import (
+	"github.com/hyperledger/fabric/gossip/api"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/msp/mgmt"
+	"github.com/op/go-logging"
+)
+
+// WithLocalMSPs makes the MessageCryptoService treat msps as local MSPs,
+// next to the local MSP of the peer. This allows a peer hosting the
+// identities of several organizations to validate the identities of all
+// of them locally. As for the local MSP of the peer, the identities of
+// the organization of a local MSP are validated by that MSP only, and
+// not by the MSPs of the channels. Local MSPs are checked in order,
+// starting with the local MSP of the peer.
+func WithLocalMSPs(msps ...msp.MSP) Option {
+	return func(s *mspMessageCryptoService) {
+		for _, localMSP := range msps {
+			if localMSP != nil {
+				s.additionalLocalMSPs = append(s.additionalLocalMSPs, localMSP)
+			}
+		}
+	}
+}
+
+// getLocalMSPs returns the local MSP of the peer
+// followed by the additional local MSPs
+func (s *mspMessageCryptoService) getLocalMSPs() []msp.MSP {
+	return append([]msp.MSP{mgmt.GetLocalMSP()}, s.additionalLocalMSPs...)
+}
+
+// deserializeLocally deserializes peerIdentity with the local MSP of its
+// organization. It returns false if peerIdentity is not an identity
+// of the organization of any of the local MSPs.
+func (s *mspMessageCryptoService) deserializeLocally(log *logging.Logger, peerIdentity api.PeerIdentityType) (msp.Identity, bool) {
+	for _, localMSP := range s.getLocalMSPs() {
+		identity, err := localMSP.DeserializeIdentity([]byte(peerIdentity))
+		if err != nil {
+			// peerIdentity is NOT in the organization of this MSP
+			log.Debugf("Local MSP failed deserializing peer identity [% x]: [%s]", []byte(peerIdentity), err)
+			continue
+		}
+
+		// The following check is consistent with the SecurityAdvisor#OrgByPeerIdentity
+		// implementation. Scoping messages to organizational units (MSP subdivisions)
+		// is left to OUAwareSecurityAdvisor, as the local MSP validates the
+		// identities of all its organizational units.
+		// TODO: Notice that the following check saves us from the fact
+		// that DeserializeIdentity does not yet enforce MSP-IDs consistency.
+		// This check can be removed once DeserializeIdentity will be fixed.
+		// The identifier of the local MSP is used rather than the MSP
+		// of the local signing identity, as MSP implementations
+		// are not required to provide a signing identity.
+		localMSPID, err := localMSP.GetIdentifier()
+		if err != nil {
+			log.Warningf("Failed getting identifier of a local MSP: [%s]", err)
+			continue
+		}
+		if identity.GetMSPIdentifier() == localMSPID {
+			return identity, true
+		}
+	}
+
+	return nil, false
+}
@@ -0,0 +1,60 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcs
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	mockpolicies "github.com/hyperledger/fabric/common/mocks/policies"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithLocalMSPs(t *testing.T) {
+	sibling := &mockMSP{id: "SiblingOrg", validateErrs: map[string]error{"peer1": errors.New("revoked locally")}}
+	// The channel does not know of the revocation
+	setupMockChannel("localmspschannel", "SiblingOrg")
+	peer0 := newMockPeerIdentity("SiblingOrg", "peer0")
+	peer1 := newMockPeerIdentity("SiblingOrg", "peer1")
+
+	// Without additional local MSPs, the channel validates the identities
+	mcs := New(&mockpolicies.PolicyManagerMgmt{}).(*mspMessageCryptoService)
+	_, chainID, err := mcs.getValidatedIdentity(logger, peer1)
+	assert.NoError(t, err)
+	assert.Equal(t, "localmspschannel", string(chainID))
+
+	// Local MSPs take the final decision on the identities of their organizations
+	mcs = New(&mockpolicies.PolicyManagerMgmt{}, WithLocalMSPs(nil, sibling)).(*mspMessageCryptoService)
+	_, chainID, err = mcs.getValidatedIdentity(logger, peer0)
+	assert.NoError(t, err)
+	assert.Nil(t, chainID)
+	assert.Error(t, mcs.ValidateIdentity(peer1))
+
+	// Identities of other organizations are still validated by the channels
+	setupMockChannel("localmspschannel2", "OtherOrg")
+	assert.NoError(t, mcs.ValidateIdentity(newMockPeerIdentity("OtherOrg", "peer0")))
+
+	// Identities validated by additional local MSPs are cached as local identities
+	mcs = New(&mockpolicies.PolicyManagerMgmt{},
+		WithLocalMSPs(sibling),
+		WithIdentityCache(NewLRUIdentityCache(10), time.Hour)).(*mspMessageCryptoService)
+	assert.NoError(t, mcs.ValidateIdentity(peer0))
+	_, chainID, cached := mcs.getCachedIdentity(logger, peer0)
+	assert.True(t, cached)
+	assert.Empty(t, chainID)
+}
@@ -0,0 +1,236 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcs
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric/gossip/api"
+	"github.com/hyperledger/fabric/gossip/common"
+	"github.com/hyperledger/fabric/msp/mgmt"
+)
+
+const (
+	// macHandshakeLabel prefixes what peers sign in handshakes
+	macHandshakeLabel = "gossip-mac-handshake"
+
+	// macKeyLabel is mixed into the derivation of session keys
+	macKeyLabel = "gossip-mac-key"
+
+	// macNonceSize is the size of the nonces of handshakes
+	macNonceSize = 32
+)
+
+// WithMACSessions enables the authentication of the messages exchanged
+// with the peers of the organizations of the local MSPs via HMAC-SHA256,
+// rather than signatures. The MAC session of a connection is established
+// by the handshake NewMACHandshake starts: both peers exchange ephemeral
+// ECDH keys and nonces, and sign the PKI-IDs, ephemeral keys and nonces of
+// both peers, so that a signature can neither be replayed in another
+// handshake nor relayed to another peer. Sessions expire after lifetime,
+// and their connection must then be established again, with a fresh key.
+func WithMACSessions(lifetime time.Duration) Option {
+	return func(s *mspMessageCryptoService) {
+		s.macSessionLifetime = lifetime
+	}
+}
+
+// NewMACHandshake starts the handshake establishing the MAC session of a
+// new connection, or returns nil if MAC sessions are disabled.
+func (s *mspMessageCryptoService) NewMACHandshake() (api.MACHandshake, error) {
+	if s.macSessionLifetime <= 0 {
+		return nil, nil
+	}
+
+	self, err := mgmt.GetLocalSigningIdentityOrPanic().Serialize()
+	if err != nil {
+		return nil, fmt.Errorf("Failed serializing the local identity: [%s]", err)
+	}
+	priv, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("Failed generating ephemeral key: [%s]", err)
+	}
+	nonce := make([]byte, macNonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("Failed generating nonce: [%s]", err)
+	}
+
+	return &macHandshake{
+		s:    s,
+		priv: priv,
+		self: macParty{
+			pkiID: s.GetPKIidOfCert(self),
+			key:   priv.PublicKey().Bytes(),
+			nonce: nonce,
+		},
+	}, nil
+}
+
+// macParty is what a peer contributes to a handshake
+type macParty struct {
+	pkiID common.PKIidType
+	key   []byte
+	nonce []byte
+}
+
+type macHandshake struct {
+	s    *mspMessageCryptoService
+	priv *ecdh.PrivateKey
+	self macParty
+
+	// set by Sign
+	remoteIdentity api.PeerIdentityType
+	remote         macParty
+	secret         []byte
+}
+
+// Hello returns the ephemeral public key and the nonce to send to the remote peer
+func (h *macHandshake) Hello() ([]byte, []byte) {
+	return h.self.key, h.self.nonce
+}
+
+// Sign returns the signature to send to the remote peer of remoteIdentity,
+// which must belong to the organization of a local MSP, given the
+// ephemeral key and the nonce of its hello.
+func (h *macHandshake) Sign(remoteIdentity api.PeerIdentityType, ephemeralKey, nonce []byte) ([]byte, error) {
+	if err := h.s.checkSameOrg(remoteIdentity); err != nil {
+		return nil, err
+	}
+	if len(nonce) != macNonceSize {
+		return nil, errors.New("Invalid nonce")
+	}
+	remoteKey, err := ecdh.P256().NewPublicKey(ephemeralKey)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid ephemeral key: [%s]", err)
+	}
+	secret, err := h.priv.ECDH(remoteKey)
+	if err != nil {
+		return nil, fmt.Errorf("Failed deriving shared secret: [%s]", err)
+	}
+
+	h.remoteIdentity = remoteIdentity
+	h.remote = macParty{pkiID: h.s.GetPKIidOfCert(remoteIdentity), key: ephemeralKey, nonce: nonce}
+	h.secret = secret
+
+	signature, err := h.s.Sign(macTranscript(h.self, h.remote))
+	if err != nil {
+		return nil, fmt.Errorf("Failed signing handshake: [%s]", err)
+	}
+	return signature, nil
+}
+
+// Establish verifies the signature sent by the remote peer, as Verify
+// does, and returns the MAC session of the connection. Each direction
+// of the connection is authenticated with its own key.
+func (h *macHandshake) Establish(signature []byte) (api.MACSession, error) {
+	if h.secret == nil {
+		return nil, errors.New("Handshake not signed")
+	}
+	if err := h.s.Verify(h.remoteIdentity, signature, macTranscript(h.remote, h.self)); err != nil {
+		return nil, fmt.Errorf("Failed verifying handshake: [%s]", err)
+	}
+
+	return &macSession{
+		sendKey:   macSessionKey(h.secret, h.self, h.remote),
+		recvKey:   macSessionKey(h.secret, h.remote, h.self),
+		expiresAt: time.Now().Add(h.s.macSessionLifetime),
+	}, nil
+}
+
+type macSession struct {
+	sendKey   []byte
+	recvKey   []byte
+	expiresAt time.Time
+
+	lock sync.Mutex
+	// sent and received count the messages authenticated in
+	// each direction, so that messages can't be replayed
+	sent     uint64
+	received uint64
+}
+
+// MAC returns the MAC of the next message sent
+func (m *macSession) MAC(message []byte) []byte {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	tag := computeMAC(m.sendKey, m.sent, message)
+	m.sent++
+	return tag
+}
+
+// VerifyMAC checks that tag is the MAC of the next message received
+func (m *macSession) VerifyMAC(tag, message []byte) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if !hmac.Equal(tag, computeMAC(m.recvKey, m.received, message)) {
+		return errors.New("Invalid MAC")
+	}
+	m.received++
+	return nil
+}
+
+// Expired returns whether the session expired
+func (m *macSession) Expired() bool {
+	return !time.Now().Before(m.expiresAt)
+}
+
+// macTranscript returns what signer signs in a handshake
+// with recipient: the PKI-IDs, ephemeral keys and nonces of both
+func macTranscript(signer, recipient macParty) []byte {
+	buf := bytes.NewBufferString(macHandshakeLabel)
+	for _, field := range [][]byte{signer.pkiID, signer.key, signer.nonce, recipient.pkiID, recipient.key, recipient.nonce} {
+		binary.Write(buf, binary.BigEndian, uint32(len(field)))
+		buf.Write(field)
+	}
+	return buf.Bytes()
+}
+
+// macSessionKey derives, from the shared secret of a
+// handshake, the key of the messages sender sends
+func macSessionKey(secret []byte, sender, recipient macParty) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(macKeyLabel))
+	mac.Write(macTranscript(sender, recipient))
+	return mac.Sum(nil)
+}
+
+// checkSameOrg returns an error if peerIdentity is not an
+// identity of the organization of one of the local MSPs
+func (s *mspMessageCryptoService) checkSameOrg(peerIdentity api.PeerIdentityType) error {
+	if _, local := s.deserializeLocally(logger, peerIdentity); !local {
+		return fmt.Errorf("Peer identity [% x] is not of a local organization", []byte(peerIdentity))
+	}
+	return nil
+}
+
+func computeMAC(key []byte, seq uint64, message []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	binary.Write(mac, binary.BigEndian, seq)
+	mac.Write(message)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,116 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcs
+
+import (
+	"crypto/ecdh"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	mockpolicies "github.com/hyperledger/fabric/common/mocks/policies"
+	"github.com/hyperledger/fabric/gossip/api"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/msp/mgmt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMACSessions(t *testing.T) {
+	self := mgmt.GetLocalSigningIdentityOrPanic()
+	macOrg := &mockMSP{id: "MACOrg", validateErrs: make(map[string]error)}
+	remoteIdentity := newMockPeerIdentity("MACOrg", "peer0")
+	msg := []byte("msg")
+
+	// Disabled by default
+	mcs := New(&mockpolicies.PolicyManagerMgmt{}, WithLocalMSPs(macOrg)).(*mspMessageCryptoService)
+	hs, err := mcs.NewMACHandshake()
+	assert.NoError(t, err)
+	assert.Nil(t, hs)
+
+	mcs = New(&mockpolicies.PolicyManagerMgmt{}, WithLocalMSPs(macOrg), WithMACSessions(time.Hour)).(*mspMessageCryptoService)
+	hs, err = mcs.NewMACHandshake()
+	assert.NoError(t, err)
+	localKey, localNonce := hs.Hello()
+
+	// The remote peer, of the organization of a local MSP
+	remotePriv, err := ecdh.P256().GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	remoteNonce := make([]byte, macNonceSize)
+	_, err = rand.Read(remoteNonce)
+	assert.NoError(t, err)
+	remote := macParty{
+		pkiID: mcs.GetPKIidOfCert(remoteIdentity),
+		key:   remotePriv.PublicKey().Bytes(),
+		nonce: remoteNonce,
+	}
+
+	// Only with peers of the organizations of the local MSPs
+	setupMockChannel("macchannel", "OtherMACOrg")
+	_, err = hs.Sign(newMockPeerIdentity("OtherMACOrg", "peer0"), remote.key, remote.nonce)
+	assert.Error(t, err)
+	_, err = hs.Establish(mockSign([]byte("msg")))
+	assert.Error(t, err)
+
+	// With valid ephemeral keys and nonces
+	_, err = hs.Sign(remoteIdentity, []byte("invalid key"), remote.nonce)
+	assert.Error(t, err)
+	_, err = hs.Sign(remoteIdentity, remote.key, remote.nonce[1:])
+	assert.Error(t, err)
+
+	// The signature covers both PKI-IDs, ephemeral keys and nonces
+	local := macParty{pkiID: mcs.GetPKIidOfCert(api.PeerIdentityType(mustSerialize(t, self))), key: localKey, nonce: localNonce}
+	signature, err := hs.Sign(remoteIdentity, remote.key, remote.nonce)
+	assert.NoError(t, err)
+	assert.NoError(t, self.Verify(macTranscript(local, remote), signature))
+
+	// The signature of the remote peer must cover the hello of this handshake
+	otherNonce := make([]byte, macNonceSize)
+	_, err = hs.Establish(mockSign(macTranscript(remote, macParty{pkiID: local.pkiID, key: local.key, nonce: otherNonce})))
+	assert.Error(t, err)
+	_, err = hs.Establish(mockSign(macTranscript(local, remote)))
+	assert.Error(t, err)
+	session, err := hs.Establish(mockSign(macTranscript(remote, local)))
+	assert.NoError(t, err)
+
+	// Each direction has its own key, and MACs are bound to the order of messages
+	localPub, err := ecdh.P256().NewPublicKey(localKey)
+	assert.NoError(t, err)
+	secret, err := remotePriv.ECDH(localPub)
+	assert.NoError(t, err)
+	remoteSession := &macSession{
+		sendKey:   macSessionKey(secret, remote, local),
+		recvKey:   macSessionKey(secret, local, remote),
+		expiresAt: time.Now().Add(time.Hour),
+	}
+	tag := session.MAC(msg)
+	assert.Error(t, session.VerifyMAC(tag, msg))
+	assert.Error(t, remoteSession.VerifyMAC(tag, []byte("other msg")))
+	assert.NoError(t, remoteSession.VerifyMAC(tag, msg))
+	assert.Error(t, remoteSession.VerifyMAC(tag, msg))
+	assert.NoError(t, session.VerifyMAC(remoteSession.MAC(msg), msg))
+
+	// Sessions expire
+	assert.False(t, session.Expired())
+	session.(*macSession).expiresAt = time.Now()
+	assert.True(t, session.Expired())
+}
+
+func mustSerialize(t *testing.T, identity msp.Identity) []byte {
+	raw, err := identity.Serialize()
+	assert.NoError(t, err)
+	return raw
+}
@@ -17,8 +17,12 @@ limitations under the License.
 package mcs
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/hyperledger/fabric/bccsp"
 	"github.com/hyperledger/fabric/bccsp/factory"
@@ -44,6 +48,205 @@ var logger = logging.MustGetLogger("peer/gossip/mcs")
 // This implementation assumes that these mechanisms are all in place and working.
 type mspMessageCryptoService struct {
 	manager policies.Manager
+
+	// signAudit, if not nil, records every signing operation
+	signAudit *signAuditor
+
+	// evaluationAudit, if not nil, records the
+	// input of every policy evaluation
+	evaluationAudit *evaluationAuditor
+
+	// clockSkew is the tolerance applied to time-based checks
+	clockSkew time.Duration
+
+	// normalizer maps channel identifiers to the form
+	// used by the policy and MSP manager registries
+	normalizer ChannelNormalizer
+
+	// minBlockSignatures is the minimum number of valid
+	// signatures VerifyBlock requires on a block
+	minBlockSignatures int
+
+	// maxBlockSignatures is the maximum number of
+	// signatures VerifyBlock processes on a block
+	maxBlockSignatures int
+
+	// skipBlockDataHash makes VerifyBlock skip
+	// the check of the data hash of blocks
+	skipBlockDataHash bool
+
+	// revocationChecker, if not nil, is consulted
+	// by VerifyWithRevocationCheck
+	revocationChecker OnlineRevocationChecker
+
+	// strictPolicyLookup makes verifications fail when
+	// a channel policy manager or policy is missing
+	strictPolicyLookup bool
+
+	// policyNotFoundHandler, if not nil, is notified when a
+	// channel policy manager or policy is missing
+	policyNotFoundHandler PolicyNotFoundHandler
+
+	// enforceOnlineRevocation makes the validation of identities
+	// consult revocationChecker as well
+	enforceOnlineRevocation bool
+
+	// duplicates, if not nil, tracks the MSPs
+	// validated identities are observed under
+	duplicates *duplicateMonitor
+
+	// validationOpts, if not nil, are applied when validating
+	// identities that support validation options
+	validationOpts *msp.ValidationOpts
+
+	// batchVerifier, if not nil, verifies
+	// the signatures checked by VerifyBatch
+	batchVerifier BatchVerifier
+
+	// nonMembers, if not nil, caches the identities
+	// found not to be members of a channel
+	nonMembers *membershipCache
+
+	// propagatePolicyPanics disables the recovery
+	// from panics raised by policy evaluations
+	propagatePolicyPanics bool
+
+	// identityCache, if not nil, caches
+	// the identities validated so far
+	identityCache *identityCache
+
+	// metrics, if not nil, are the metrics
+	// this service reports
+	metrics *serviceMetrics
+
+	// requireSharedChannel makes Verify require that the signer
+	// is a member of a channel this peer has joined
+	requireSharedChannel bool
+
+	// blockVerificationConcurrency is the maximum number
+	// of blocks VerifyBlocks verifies at the same time
+	blockVerificationConcurrency int
+
+	// configSequence, if not nil, provides the configuration
+	// sequence of channels to VerifyAtConfigSeq
+	configSequence ConfigSequenceProvider
+
+	// rejectConfigMoved makes VerifyAtConfigSeq fail if the
+	// configuration is not at the expected sequence
+	rejectConfigMoved bool
+
+	// bootstrap, if not nil, holds the keys
+	// VerifyBootstrap verifies against
+	bootstrap *bootstrapKeys
+
+	// allowCrossChannel enables VerifyCrossChannel
+	allowCrossChannel bool
+
+	// channelState, if not nil, provides the membership state
+	// of channels, for VerifyByChannel to apply grace to
+	// channels in a transitional state
+	channelState ChannelStateProvider
+
+	// configUpdates counts the configuration updates
+	// of channels notified via ChannelConfigUpdated
+	configUpdates configUpdateCounter
+
+	// failures, if not nil, receives the failed verifications
+	failures chan<- *VerificationFailure
+
+	// pkiIDHashOpts, if not nil, selects the hash
+	// function GetPKIidOfCert computes PKI-IDs with
+	pkiIDHashOpts bccsp.HashOpts
+
+	// additionalLocalMSPs are checked, after the local MSP,
+	// before the MSPs of the channels
+	additionalLocalMSPs []msp.MSP
+
+	// maxIdentitySize, if positive, is the maximum
+	// size of the identities the service processes
+	maxIdentitySize int
+
+	// macSessionLifetime, if positive, is the lifetime of the MAC
+	// sessions established with peers of the local organization
+	macSessionLifetime time.Duration
+}
+
+// Option configures an optional behaviour of the
+// MessageCryptoService returned by New.
+type Option func(*mspMessageCryptoService)
+
+// MessageCryptoService is the api.MessageCryptoService New returns,
+// extended with the verifications and the notifications the MSP-based
+// implementation offers on top of the gossip contract.
+type MessageCryptoService interface {
+	api.ExpirationAwareMessageCryptoService
+	api.MACSessionService
+
+	// SignForChannel signs msg with the signing identity of this peer on a channel
+	SignForChannel(chainID common.ChainID, msg []byte) ([]byte, error)
+	// GetChannelIdentity returns the identity of this peer on a channel
+	GetChannelIdentity(chainID common.ChainID) (api.PeerIdentityType, error)
+	// Fingerprint returns a short, human-readable fingerprint of a peer identity
+	Fingerprint(peerIdentity api.PeerIdentityType) (string, error)
+	// ValidateIdentityWithAttributes validates a peer identity and returns its attributes
+	ValidateIdentityWithAttributes(peerIdentity api.PeerIdentityType) (map[string]string, error)
+	// ValidateIdentityForChannel validates a peer identity as a member of a channel
+	ValidateIdentityForChannel(chainID common.ChainID, peerIdentity api.PeerIdentityType) error
+
+	// VerifyWithLogger verifies a signature as Verify does, logging to log
+	VerifyWithLogger(log *logging.Logger, peerIdentity api.PeerIdentityType, signature, message []byte) error
+	// VerifyFresh verifies a signature made at signedAt, within window of now
+	VerifyFresh(peerIdentity api.PeerIdentityType, signature, message []byte, signedAt time.Time, window time.Duration) error
+	// VerifyWithExpiry verifies a signature and returns how long the identity remains valid
+	VerifyWithExpiry(peerIdentity api.PeerIdentityType, signature, message []byte) (time.Duration, error)
+	// VerifyWithRevocationCheck verifies a signature and checks the revocation of the identity online
+	VerifyWithRevocationCheck(peerIdentity api.PeerIdentityType, signature, message []byte) error
+	// VerifyAndGetEnrollmentID verifies a signature and returns the enrollment ID of the signer
+	VerifyAndGetEnrollmentID(peerIdentity api.PeerIdentityType, signature, message []byte) (string, error)
+	// VerifyDryRun reports whether a signature would verify, with diagnostic notes
+	VerifyDryRun(peerIdentity api.PeerIdentityType, signature, message []byte) (bool, []string, error)
+	// VerifyFramed verifies a signature over a frame encoded according to mode
+	VerifyFramed(peerIdentity api.PeerIdentityType, signature, frame []byte, mode FramingMode) error
+	// VerifyDelegated verifies a signature of a delegate certified by a delegator
+	VerifyDelegated(delegatorIdentity api.PeerIdentityType, delegateCert []byte, signature, message []byte) error
+	// VerifyBootstrap verifies a signature under the bootstrap keys
+	VerifyBootstrap(signature, message []byte) error
+	// DisableBootstrap stops accepting signatures under the bootstrap keys
+	DisableBootstrap()
+	// VerifyBatch verifies several signatures at once
+	VerifyBatch(msgs []*SignedMessage) []error
+
+	// VerifyByChannelAll verifies a signature against several policies of a channel
+	VerifyByChannelAll(chainID common.ChainID, peerIdentity api.PeerIdentityType, signature, message []byte, policyNames []string) error
+	// VerifyByChannelAdmins verifies a signature against the admins policy of a channel
+	VerifyByChannelAdmins(chainID common.ChainID, peerIdentity api.PeerIdentityType, signature, message []byte) error
+	// VerifyAtConfigSeq verifies a signature against a channel at a configuration sequence
+	VerifyAtConfigSeq(chainID common.ChainID, expectedSeq uint64, peerIdentity api.PeerIdentityType, signature, message []byte) error
+	// VerifyCrossChannel verifies a signature against the policy of another channel
+	VerifyCrossChannel(identityChannel, policyChannel common.ChainID, peerIdentity api.PeerIdentityType, signature, message []byte) error
+
+	// VerifyBlocks verifies several blocks of a channel
+	VerifyBlocks(chainID common.ChainID, blocks []api.SignedBlock) []error
+	// VerifyBlockAttestation verifies the signatures of a block without its data
+	VerifyBlockAttestation(chainID common.ChainID, header *protoscommon.BlockHeader, metadata *protoscommon.BlockMetadata) error
+
+	// ValidateIdentityCtx validates an identity as ValidateIdentity does, unless ctx is done first
+	ValidateIdentityCtx(ctx context.Context, peerIdentity api.PeerIdentityType) error
+	// VerifyCtx verifies a signature as Verify does, unless ctx is done first
+	VerifyCtx(ctx context.Context, peerIdentity api.PeerIdentityType, signature, message []byte) error
+	// VerifyByChannelCtx verifies a signature as VerifyByChannel does, unless ctx is done first
+	VerifyByChannelCtx(ctx context.Context, chainID common.ChainID, peerIdentity api.PeerIdentityType, signature, message []byte) error
+	// VerifyBlockCtx verifies a block as VerifyBlock does, unless ctx is done first
+	VerifyBlockCtx(ctx context.Context, chainID common.ChainID, signedBlock api.SignedBlock) error
+
+	// ChannelConfigUpdated notifies the service of a configuration update of a channel
+	ChannelConfigUpdated(chainID common.ChainID)
+	// LocalMSPUpdated notifies the service of an update of the local MSP
+	LocalMSPUpdated()
+	// RevalidateAll evicts the cached identities that are no longer valid
+	RevalidateAll() (invalidated int, err error)
+	// Stop stops the auditors, once the records queued so far are delivered
+	Stop()
 }
 
 // New creates a new instance of mspMessageCryptoService
@@ -52,8 +255,36 @@ type mspMessageCryptoService struct {
 // access to the policy manager of a given channel via the Manager method.
 // See fabric/core/peer/peer.go#NewPolicyManagerMgmt and
 // fabric/common/mocks/policies/policies.go#PolicyManagerMgmt
-func New(manager policies.Manager) api.MessageCryptoService {
-	return &mspMessageCryptoService{manager: manager}
+// Additional behaviour can be enabled by passing options.
+func New(manager policies.Manager, opts ...Option) MessageCryptoService {
+	s := &mspMessageCryptoService{manager: manager}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// ChannelNormalizer maps a channel identifier to the canonical
+// form used by the policy and MSP manager registries.
+// It must be deterministic and idempotent.
+type ChannelNormalizer func(chainID common.ChainID) common.ChainID
+
+// WithChannelNormalizer makes the MessageCryptoService normalize channel
+// identifiers before looking up any per-channel manager.
+// All the lookups by channel identifier go through normalizer.
+// By default, channel identifiers are used as they are.
+func WithChannelNormalizer(normalizer ChannelNormalizer) Option {
+	return func(s *mspMessageCryptoService) {
+		s.normalizer = normalizer
+	}
+}
+
+// WithClockSkew sets the tolerance applied to time-based checks
+// to absorb clock differences between this peer and remote peers.
+func WithClockSkew(skew time.Duration) Option {
+	return func(s *mspMessageCryptoService) {
+		s.clockSkew = skew
+	}
 }
 
 // ValidateIdentity validates the identity of a remote peer.
@@ -64,7 +295,7 @@ func (s *mspMessageCryptoService) ValidateIdentity(peerIdentity api.PeerIdentity
 	// here we check only that peerIdentity is not
 	// invalid, revoked or expired.
 
-	_, _, err := s.getValidatedIdentity(peerIdentity)
+	_, _, err := s.getValidatedIdentity(logger, peerIdentity)
 	return err
 }
 
@@ -72,6 +303,7 @@ func (s *mspMessageCryptoService) ValidateIdentity(peerIdentity api.PeerIdentity
 // If any error occurs, the method return nil
 // The PKid of a peer is computed as the SHA2-256 of peerIdentity which
 // is supposed to be the serialized version of MSP identity.
+// A different hash function can be selected via WithPKIidHash.
 // This method does not validate peerIdentity.
 // This validation is supposed to be done appropriately during the execution flow.
 func (s *mspMessageCryptoService) GetPKIidOfCert(peerIdentity api.PeerIdentityType) common.PKIidType {
@@ -81,9 +313,14 @@ func (s *mspMessageCryptoService) GetPKIidOfCert(peerIdentity api.PeerIdentityTy
 
 		return nil
 	}
+	if err := s.checkIdentitySize(peerIdentity); err != nil {
+		logger.Errorf("Refusing to compute the PKI-ID of peer identity: [%s]", err)
+
+		return nil
+	}
 
 	// Hash
-	digest, err := factory.GetDefault().Hash(peerIdentity, &bccsp.SHA256Opts{})
+	digest, err := factory.GetDefault().Hash(peerIdentity, s.getPKIidHashOpts())
 	if err != nil {
 		logger.Errorf("Failed computing digest of serialized identity [% x]: [%s]", peerIdentity, err)
 
@@ -93,118 +330,608 @@ func (s *mspMessageCryptoService) GetPKIidOfCert(peerIdentity api.PeerIdentityTy
 	return digest
 }
 
-// VerifyBlock returns nil if the block is properly signed,
-// else returns error
-func (s *mspMessageCryptoService) VerifyBlock(chainID common.ChainID, signedBlock api.SignedBlock) error {
-	// TODO: to be implemented
-	// Steps:
-	// 1. Check that the block is related to chainID
-	// 2. Verify that the block is properly signed
-	//    using the policy associated to chainID
+// fingerprintLength is the number of bytes
+// of the PKI-ID shown in a fingerprint
+const fingerprintLength = 8
 
-	return nil
+// Fingerprint returns a short, human-readable fingerprint of a peer's
+// identity, meant for display purposes only.
+// The fingerprint is the first bytes of the PKI-ID of peerIdentity,
+// as returned by GetPKIidOfCert, formatted as colon-separated
+// hexadecimal (e.g. 1a:2b:3c:4d:5e:6f:70:81).
+// This method does not validate peerIdentity.
+func (s *mspMessageCryptoService) Fingerprint(peerIdentity api.PeerIdentityType) (string, error) {
+	pkiID := s.GetPKIidOfCert(peerIdentity)
+	if len(pkiID) == 0 {
+		return "", errors.New("Failed computing PKI-ID of peer identity")
+	}
+
+	n := fingerprintLength
+	if len(pkiID) < n {
+		n = len(pkiID)
+	}
+
+	parts := make([]string, n)
+	for i, b := range pkiID[:n] {
+		parts[i] = fmt.Sprintf("%02x", b)
+	}
+
+	return strings.Join(parts, ":"), nil
 }
 
 // Sign signs msg with this peer's signing key and outputs
 // the signature if no error occurred.
 func (s *mspMessageCryptoService) Sign(msg []byte) ([]byte, error) {
-	return mgmt.GetLocalSigningIdentityOrPanic().Sign(msg)
+	identity := mgmt.GetLocalSigningIdentityOrPanic()
+
+	signature, err := identity.Sign(msg)
+	if err == nil {
+		s.signAudit.record(identity, msg)
+	}
+
+	return signature, err
+}
+
+// SignForChannel signs msg with the signing identity this peer holds
+// on the channel identified by chainID, and outputs the signature
+// if no error occurred.
+// The signing identity is resolved via the MSP manager of the channel,
+// rather than being the local default signing identity.
+// If no signing identity is available for the channel, an error is returned.
+func (s *mspMessageCryptoService) SignForChannel(chainID common.ChainID, msg []byte) ([]byte, error) {
+	identity, err := s.getChannelSigningIdentity(chainID)
+	if err != nil {
+		logger.Errorf("Failed getting signing identity for channel [%s]: [%s]", string(chainID), err)
+
+		return nil, err
+	}
+
+	signature, err := identity.Sign(msg)
+	if err == nil {
+		s.signAudit.record(identity, msg)
+	}
+
+	return signature, err
+}
+
+// GetChannelIdentity returns the serialized signing identity this peer
+// presents on the channel identified by chainID, which is the identity
+// signatures output by SignForChannel verify against.
+// If no signing identity is available for the channel, an error is returned.
+func (s *mspMessageCryptoService) GetChannelIdentity(chainID common.ChainID) (api.PeerIdentityType, error) {
+	identity, err := s.getChannelSigningIdentity(chainID)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := identity.Serialize()
+	if err != nil {
+		return nil, fmt.Errorf("Failed serializing signing identity for channel [%s]: [%s]", string(chainID), err)
+	}
+
+	return raw, nil
 }
 
 // Verify checks that signature is a valid signature of message under a peer's verification key.
 // If the verification succeeded, Verify returns nil meaning no error occurred.
 // If peerIdentity is nil, then the verification fails.
 func (s *mspMessageCryptoService) Verify(peerIdentity api.PeerIdentityType, signature, message []byte) error {
-	identity, chainID, err := s.getValidatedIdentity(peerIdentity)
+	_, err := s.verify(logger, peerIdentity, signature, message)
+	return err
+}
+
+// VerifyWithLogger checks that signature is a valid signature of message
+// under a peer's verification key, as Verify does, but all the log output
+// related to this verification goes to log instead of the package logger.
+// This allows to correlate the log lines of a single verification, e.g. by
+// means of a logger whose module carries a request identifier.
+// If log is nil, the package logger is used.
+func (s *mspMessageCryptoService) VerifyWithLogger(log *logging.Logger, peerIdentity api.PeerIdentityType, signature, message []byte) error {
+	if log == nil {
+		log = logger
+	}
+
+	_, err := s.verify(log, peerIdentity, signature, message)
+	return err
+}
+
+// verify checks that signature is a valid signature of message
+// under a peer's verification key, as Verify does, and returns
+// the validated identity of the signer.
+func (s *mspMessageCryptoService) verify(log *logging.Logger, peerIdentity api.PeerIdentityType, signature, message []byte) (identity msp.Identity, err error) {
+	start := time.Now()
+	defer func() {
+		s.metrics.observeVerify(OperationVerify, start, err)
+	}()
+
+	identity, chainID, err := s.getValidatedIdentity(log, peerIdentity)
 	if err != nil {
-		logger.Errorf("Failed getting validated identity from peer identity [%s]", err)
+		log.Errorf("Failed getting validated identity from peer identity [%s]", err)
 
-		return err
+		return nil, err
 	}
 
+	if s.requireSharedChannel {
+		if err := s.checkSharedChannel(log, chainID, peerIdentity); err != nil {
+			log.Warningf("Rejecting signature of peer identity [% x]: [%s]", []byte(peerIdentity), err)
+			return nil, err
+		}
+	}
+
+	return identity, s.verifyValidated(log, identity, chainID, peerIdentity, signature, message)
+}
+
+// verifyValidated checks signature against identity, the validated
+// identity of peerIdentity, and chainID, the channel whose MSP
+// validated it (nil if the local MSP did).
+func (s *mspMessageCryptoService) verifyValidated(log *logging.Logger, identity msp.Identity, chainID common.ChainID, peerIdentity api.PeerIdentityType, signature, message []byte) error {
 	if len(chainID) == 0 {
 		// At this stage, this means that peerIdentity
 		// belongs to this peer's LocalMSP.
 		// The signature is validated directly
-		return identity.Verify(message, signature)
+		err := identity.Verify(message, signature)
+		s.publishFailure(OperationVerify, peerIdentity, nil, err)
+		return err
 	}
 
 	// At this stage, the signature must be validated
-	// against the reader policy of the channel
+	// against the gossip policy of the channel
 	// identified by chainID
 
-	return s.VerifyByChannel(chainID, peerIdentity, signature, message)
+	return s.verifyByChannel(log, chainID, peerIdentity, signature, message)
+}
+
+// VerifyFresh checks that signature is a valid signature of message under a peer's
+// verification key, and that the message has been signed recently.
+// signedAt is the signing time claimed by the signer (typically taken from
+// the message header) and it must fall within [now-window, now+clockSkew].
+// If signedAt is outside that interval, a *StaleMessageError is returned
+// and the signature is not checked at all.
+func (s *mspMessageCryptoService) VerifyFresh(peerIdentity api.PeerIdentityType, signature, message []byte, signedAt time.Time, window time.Duration) error {
+	// Validate arguments
+	if window < 0 {
+		return fmt.Errorf("Invalid freshness window [%s]. It must be non-negative.", window)
+	}
+
+	now := time.Now()
+	if signedAt.Before(now.Add(-window)) || signedAt.After(now.Add(s.clockSkew)) {
+		return &StaleMessageError{SignedAt: signedAt, Now: now, Window: window, ClockSkew: s.clockSkew}
+	}
+
+	return s.Verify(peerIdentity, signature, message)
 }
 
 // VerifyByChannel checks that signature is a valid signature of message
 // under a peer's verification key, but also in the context of a specific channel.
 // If the verification succeeded, Verify returns nil meaning no error occurred.
 // If peerIdentity is nil, then the verification fails.
-func (s *mspMessageCryptoService) VerifyByChannel(chainID common.ChainID, peerIdentity api.PeerIdentityType, signature, message []byte) error {
+func (s *mspMessageCryptoService) VerifyByChannel(chainID common.ChainID, peerIdentity api.PeerIdentityType, signature, message []byte) (err error) {
+	start := time.Now()
+	defer func() {
+		s.metrics.observeVerify(OperationVerifyByChannel, start, err)
+	}()
+
+	return s.verifyByChannel(logger, chainID, peerIdentity, signature, message)
+}
+
+func (s *mspMessageCryptoService) verifyByChannel(log *logging.Logger, chainID common.ChainID, peerIdentity api.PeerIdentityType, signature, message []byte) error {
+	err := s.evaluateGossipPolicy(log, chainID, peerIdentity, signature, message)
+	if err != nil {
+		err = s.applyTransitionGrace(log, chainID, peerIdentity, signature, message, err)
+	}
+
+	s.publishFailure(OperationVerifyByChannel, peerIdentity, chainID, err)
+	return err
+}
+
+// evaluateGossipPolicy checks that signature satisfies the policy
+// gossip messages are verified against on channel chainID, which
+// is the reader policy of the channel unless the channel configures
+// a dedicated one (see getGossipPolicy)
+func (s *mspMessageCryptoService) evaluateGossipPolicy(log *logging.Logger, chainID common.ChainID, peerIdentity api.PeerIdentityType, signature, message []byte) error {
 	// Validate arguments
 	if len(peerIdentity) == 0 {
 		return errors.New("Invalid Peer Identity. It must be different from nil.")
 	}
 
-	// Get the policy manager for channel chainID
+	// Get the policy gossip messages are verified against on channel chainID
+	policyName, policy, err := s.getGossipPolicy(log, chainID)
+	if err != nil {
+		return err
+	}
+
+	signedData := &protoscommon.SignedData{
+		Data:      message,
+		Identity:  []byte(peerIdentity),
+		Signature: signature,
+	}
+
+	s.evaluationAudit.record(chainID, policyName, []*protoscommon.SignedData{signedData})
+
+	return s.evaluatePolicy(chainID, policyName, func() error {
+		// Fast path for policies requiring a signature of a single principal
+		if evaluated, err := s.evaluateSinglePrincipal(chainID, policy, signedData); evaluated {
+			return err
+		}
+
+		return policy.Evaluate([]*protoscommon.SignedData{signedData})
+	})
+}
+
+// evaluateSinglePrincipal evaluates policy over signedData by checking the
+// signer directly against the only principal of policy, thus bypassing the
+// generic policy evaluation. This produces the same outcome as Evaluate,
+// given that the channel's policies deserialize identities through the
+// channel's MSP manager.
+// It returns false if policy does not reduce to a single principal, in
+// which case the policy must be evaluated as usual.
+func (s *mspMessageCryptoService) evaluateSinglePrincipal(chainID common.ChainID, policy policies.Policy, signedData *protoscommon.SignedData) (bool, error) {
+	spp, ok := policy.(policies.SinglePrincipalPolicy)
+	if !ok {
+		return false, nil
+	}
+	principal, ok := spp.SinglePrincipal()
+	if !ok {
+		return false, nil
+	}
+
+	mspManager := mgmt.GetManagerForChainIfExists(string(s.normalizeChannel(chainID)))
+	if mspManager == nil {
+		return false, nil
+	}
+
+	identity, err := mspManager.DeserializeIdentity(signedData.Identity)
+	if err != nil {
+		return true, fmt.Errorf("Failed deserializing identity [% x] on [%s]: [%s]", signedData.Identity, string(chainID), err)
+	}
+
+	if err := identity.SatisfiesPrincipal(principal); err != nil {
+		return true, err
+	}
+
+	return true, identity.Verify(signedData.Data, signedData.Signature)
+}
+
+// VerifyByChannelAll checks that signature is a valid signature of message
+// under a peer's verification key, in the context of a specific channel,
+// and that it satisfies every one of the channel's policies named policyNames.
+// The identity is validated once, and then all the policies are evaluated
+// against the same signed data. If any policy is not satisfied, or does
+// not exist on the channel, a *PolicyEvaluationError listing all the
+// failing policies is returned.
+func (s *mspMessageCryptoService) VerifyByChannelAll(chainID common.ChainID, peerIdentity api.PeerIdentityType, signature, message []byte, policyNames []string) error {
+	// Validate arguments
+	if len(policyNames) == 0 {
+		return errors.New("Invalid policy names. At least one policy must be specified.")
+	}
+
+	if _, _, err := s.getValidatedIdentity(logger, peerIdentity); err != nil {
+		logger.Errorf("Failed getting validated identity from peer identity [%s]", err)
+
+		return err
+	}
+
+	cpm, err := s.getChannelPolicyManager(logger, chainID)
+	if err != nil {
+		return err
+	}
+
+	signedData := []*protoscommon.SignedData{{
+		Data:      message,
+		Identity:  []byte(peerIdentity),
+		Signature: signature,
+	}}
+
+	failures := &PolicyEvaluationError{ChainID: chainID}
+	for _, policyName := range policyNames {
+		policy, ok := cpm.GetPolicy(policyName)
+		if !ok {
+			failures.add(policyName, s.policyNotFound(chainID, policyName))
+			continue
+		}
+
+		s.evaluationAudit.record(chainID, policyName, signedData)
+		err := s.evaluatePolicy(chainID, policyName, func() error {
+			return policy.Evaluate(signedData)
+		})
+		if err != nil {
+			failures.add(policyName, err)
+		}
+	}
+
+	if len(failures.Policies) != 0 {
+		s.publishFailure(OperationVerifyByChannel, peerIdentity, chainID, failures)
+		return failures
+	}
+
+	return nil
+}
+
+// VerifyByChannelAdmins checks that signature is a valid signature of
+// message under a peer's verification key, and that it satisfies the
+// admins policy of the channel identified by chainID.
+// This is meant for administrative messages that only the channel's
+// admins are allowed to issue. If the channel has no admins policy,
+// an error is returned.
+func (s *mspMessageCryptoService) VerifyByChannelAdmins(chainID common.ChainID, peerIdentity api.PeerIdentityType, signature, message []byte) error {
+	if _, _, err := s.getValidatedIdentity(logger, peerIdentity); err != nil {
+		logger.Errorf("Failed getting validated identity from peer identity [%s]", err)
+
+		return err
+	}
+
+	cpm, err := s.getChannelPolicyManager(logger, chainID)
+	if err != nil {
+		return err
+	}
+
+	policy, ok := cpm.GetPolicy(policies.ChannelApplicationAdmins)
+	if !ok {
+		s.policyNotFound(chainID, policies.ChannelApplicationAdmins)
+		return fmt.Errorf("No admins policy [%s] configured for channel [%s]", policies.ChannelApplicationAdmins, string(chainID))
+	}
+
+	signedData := []*protoscommon.SignedData{{
+		Data:      message,
+		Identity:  []byte(peerIdentity),
+		Signature: signature,
+	}}
+
+	s.evaluationAudit.record(chainID, policies.ChannelApplicationAdmins, signedData)
+
+	err = s.evaluatePolicy(chainID, policies.ChannelApplicationAdmins, func() error {
+		return policy.Evaluate(signedData)
+	})
+	s.publishFailure(OperationVerifyByChannel, peerIdentity, chainID, err)
+	return err
+}
+
+// getChannelPolicyManager returns the policy manager of channel chainID
+func (s *mspMessageCryptoService) getChannelPolicyManager(log *logging.Logger, chainID common.ChainID) (policies.Manager, error) {
+	chainID = s.normalizeChannel(chainID)
+
 	cpm, flag := s.manager.Manager([]string{string(chainID)})
-	logger.Debugf("Got policy manager for channel [%s] with flag [%s]", string(chainID), flag)
+	log.Debugf("Got policy manager for channel [%s] with flag [%s]", string(chainID), flag)
+	if cpm == nil || !flag {
+		err := s.policyNotFound(chainID, "")
+		if cpm == nil || s.strictPolicyLookup {
+			return nil, err
+		}
+	}
+
+	return cpm, nil
+}
+
+// getGossipPolicy returns the name of the policy gossip messages are
+// verified against on channel chainID, along with the policy.
+// This is policies.ChannelApplicationGossipVerification if the
+// configuration of the channel defines it, so that channels can apply
+// stricter rules to gossip than to ordinary reads, and
+// policies.ChannelApplicationReaders otherwise.
+func (s *mspMessageCryptoService) getGossipPolicy(log *logging.Logger, chainID common.ChainID) (string, policies.Policy, error) {
+	cpm, err := s.getChannelPolicyManager(log, chainID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if policy, ok := cpm.GetPolicy(policies.ChannelApplicationGossipVerification); ok {
+		log.Debugf("Got gossip verification policy for channel [%s]", string(chainID))
+		return policies.ChannelApplicationGossipVerification, policy, nil
+	}
 
-	// Get channel reader policy
 	policy, flag := cpm.GetPolicy(policies.ChannelApplicationReaders)
-	logger.Debugf("Got reader policy for channel [%s] with flag [%s]", string(chainID), flag)
+	log.Debugf("Got reader policy for channel [%s] with flag [%s]", string(chainID), flag)
+	if !flag {
+		err := s.policyNotFound(chainID, policies.ChannelApplicationReaders)
+		if s.strictPolicyLookup {
+			return "", nil, err
+		}
+	}
+
+	return policies.ChannelApplicationReaders, policy, nil
+}
+
+// normalizeChannel returns the form of chainID to be used
+// for looking up per-channel managers
+func (s *mspMessageCryptoService) normalizeChannel(chainID common.ChainID) common.ChainID {
+	if s.normalizer == nil {
+		return chainID
+	}
+	return s.normalizer(chainID)
+}
 
-	return policy.Evaluate(
-		[]*protoscommon.SignedData{{
-			Data:      message,
-			Identity:  []byte(peerIdentity),
-			Signature: signature,
-		}},
-	)
+// getChannelSigningIdentity returns the signing identity this peer
+// holds on channel chainID.
+// The MSP of the local signing identity is preferred, if the channel
+// knows about it. Otherwise, the MSPs of the channel are inspected in
+// lexicographic order of their identifiers and the first default signing
+// identity found is returned.
+func (s *mspMessageCryptoService) getChannelSigningIdentity(chainID common.ChainID) (msp.SigningIdentity, error) {
+	// Validate arguments
+	if len(chainID) == 0 {
+		return nil, errors.New("Invalid channel. It must be different from nil.")
+	}
+
+	chainID = s.normalizeChannel(chainID)
+	mspManager := mgmt.GetManagerForChainIfExists(string(chainID))
+	if mspManager == nil {
+		return nil, fmt.Errorf("No MSP manager found for channel [%s]", string(chainID))
+	}
+
+	msps, err := mspManager.GetMSPs()
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting MSPs of channel [%s]: [%s]", string(chainID), err)
+	}
+
+	// First check the MSP of the local signing identity, if any
+	localMSPID, err := mgmt.GetLocalMSP().GetIdentifier()
+	if err == nil {
+		if localMSP, ok := msps[localMSPID]; ok {
+			identity, err := localMSP.GetDefaultSigningIdentity()
+			if err == nil && identity != nil {
+				return identity, nil
+			}
+			logger.Debugf("Local MSP [%s] has no signing identity on channel [%s]: [%s]", localMSPID, string(chainID), err)
+		}
+	}
+
+	// Then, check the remaining MSPs in a deterministic order
+	mspIDs := make([]string, 0, len(msps))
+	for mspID := range msps {
+		mspIDs = append(mspIDs, mspID)
+	}
+	sort.Strings(mspIDs)
+
+	for _, mspID := range mspIDs {
+		if mspID == localMSPID {
+			continue
+		}
+
+		identity, err := msps[mspID].GetDefaultSigningIdentity()
+		if err != nil || identity == nil {
+			logger.Debugf("MSP [%s] has no signing identity on channel [%s]: [%s]", mspID, string(chainID), err)
+			continue
+		}
+
+		return identity, nil
+	}
+
+	return nil, fmt.Errorf("No signing identity available for channel [%s]", string(chainID))
 }
 
-func (s *mspMessageCryptoService) getValidatedIdentity(peerIdentity api.PeerIdentityType) (msp.Identity, common.ChainID, error) {
+// getValidatedIdentity deserializes and validates peerIdentity.
+// It returns the validated identity and the channel whose MSP
+// validated it, or a nil channel if the local MSP did.
+func (s *mspMessageCryptoService) getValidatedIdentity(log *logging.Logger, peerIdentity api.PeerIdentityType) (msp.Identity, common.ChainID, error) {
+	if err := s.checkIdentitySize(peerIdentity); err != nil {
+		log.Warningf("Rejecting peer identity: [%s]", err)
+		s.metrics.countIdentityValidationFailure()
+		s.publishFailure(OperationValidateIdentity, nil, nil, err)
+		return nil, nil, err
+	}
+
+	start := time.Now()
+	identity, chainID, cached := s.getCachedIdentity(log, peerIdentity)
+	defer s.metrics.observeIdentityValidation(start, cached)
+
+	if !cached {
+		var err error
+		identity, chainID, err = s.validateIdentity(log, peerIdentity)
+		if err != nil {
+			s.metrics.countIdentityValidationFailure()
+			s.publishFailure(OperationValidateIdentity, peerIdentity, nil, err)
+			return nil, nil, err
+		}
+
+		if err := s.checkNotBefore(peerIdentity); err != nil {
+			log.Warningf("Peer identity [% x] is not valid yet: [%s]", []byte(peerIdentity), err)
+			s.metrics.countIdentityValidationFailure()
+			s.publishFailure(OperationValidateIdentity, peerIdentity, nil, err)
+			return nil, nil, err
+		}
+
+		if err := s.checkOnlineRevocation(identity); err != nil {
+			log.Warningf("Online revocation check of peer identity [% x] failed: [%s]", []byte(peerIdentity), err)
+			err = &IdentityValidationError{PeerIdentity: peerIdentity, Reason: ErrIdentityRevoked, Err: err}
+			s.metrics.countIdentityValidationFailure()
+			s.publishFailure(OperationValidateIdentity, peerIdentity, nil, err)
+			return nil, nil, err
+		}
+
+		s.cacheIdentity(log, peerIdentity, chainID)
+	}
+
+	s.duplicates.observe(peerIdentity, identity)
+
+	return identity, chainID, nil
+}
+
+// checkNotBefore returns a *NotYetValidError if peerIdentity carries an
+// x.509 certificate whose validity has not started yet, allowing for the
+// configured clock skew. This check does not rely on the MSP enforcing
+// the validity period of certificates.
+func (s *mspMessageCryptoService) checkNotBefore(peerIdentity api.PeerIdentityType) error {
+	cert, err := getCertificate(peerIdentity)
+	if err != nil {
+		// Not an x.509 identity
+		return nil
+	}
+
+	now := time.Now()
+	if now.Add(s.clockSkew).Before(cert.NotBefore) {
+		return &NotYetValidError{NotBefore: cert.NotBefore, Now: now, ClockSkew: s.clockSkew}
+	}
+
+	return nil
+}
+
+// mspManagers is a snapshot of the MSP managers
+// of the channels this peer has joined
+type mspManagers struct {
+	// chainIDs lists the channels, in the order they are walked in
+	chainIDs []string
+	managers map[string]msp.MSPManager
+}
+
+// getMSPManagers returns a snapshot of the MSP managers of the channels
+// this peer has joined. Walking a snapshot is not affected by the
+// configuration transactions committed meanwhile, and walks channels
+// in a deterministic order.
+func getMSPManagers() *mspManagers {
+	managers := mgmt.GetManagers()
+	chainIDs := make([]string, 0, len(managers))
+	for chainID := range managers {
+		chainIDs = append(chainIDs, chainID)
+	}
+	sort.Strings(chainIDs)
+
+	return &mspManagers{chainIDs: chainIDs, managers: managers}
+}
+
+// validateIdentity validates peerIdentity against the local MSP and then
+// against the MSPs of the channels, in lexicographic order of channel
+// identifiers. The channels' MSPs are those at the time validateIdentity
+// is invoked: a configuration update landing during the validation
+// is reflected only by later validations.
+func (s *mspMessageCryptoService) validateIdentity(log *logging.Logger, peerIdentity api.PeerIdentityType) (msp.Identity, common.ChainID, error) {
 	// Validate arguments
 	if len(peerIdentity) == 0 {
-		return nil, nil, errors.New("Invalid Peer Identity. It must be different from nil.")
+		return nil, nil, &IdentityValidationError{
+			Reason: ErrMalformedIdentity,
+			Err:    errors.New("Invalid Peer Identity. It must be different from nil."),
+		}
 	}
 
+	managers := getMSPManagers()
+
 	// Notice that peerIdentity is assumed to be the serialization of an identity.
 	// So, first step is the identity deserialization and then verify it.
 
-	// First check against the local MSP.
+	// First check against the local MSPs.
 	// If the peerIdentity is in the same organization of this node then
 	// the local MSP is required to take the final decision on the validity
 	// of the signature.
-	identity, err := mgmt.GetLocalMSP().DeserializeIdentity([]byte(peerIdentity))
-	if err != nil {
-		// peerIdentity is NOT in the same organization of this node
-		logger.Debugf("LocalMSP failed deserializing peer identity [% x]: [%s]", []byte(peerIdentity), err)
-	} else {
-		// TODO: The following check will be replaced by a check on the organizational units
-		// when we allow the gossip network to have organization unit (MSP subdivisions)
-		// scoped messages.
-		// The following check is consistent with the SecurityAdvisor#OrgByPeerIdentity
-		// implementation.
-		// TODO: Notice that the following check saves us from the fact
-		// that DeserializeIdentity does not yet enforce MSP-IDs consistency.
-		// This check can be removed once DeserializeIdentity will be fixed.
-		if identity.GetMSPIdentifier() == mgmt.GetLocalSigningIdentityOrPanic().GetMSPIdentifier() {
-			// Check identity validity
-
-			// Notice that at this stage we don't have to check the identity
-			// against any channel's policies.
-			// This will be done by the caller function, if needed.
-			return identity, nil, identity.Validate()
+	if identity, ok := s.deserializeLocally(log, peerIdentity); ok {
+		// Check identity validity
+
+		// Notice that at this stage we don't have to check the identity
+		// against any channel's policies.
+		// This will be done by the caller function, if needed.
+		if err := s.validate(identity); err != nil {
+			return nil, nil, s.newIdentityValidationError(managers, peerIdentity, err)
 		}
+		return identity, nil, nil
 	}
 
 	// Check against managers
-	for chainID, mspManager := range mgmt.GetManagers() {
+	var validationErr error
+	for _, chainID := range managers.chainIDs {
+		mspManager := managers.managers[chainID]
+
 		// Deserialize identity
 		identity, err := mspManager.DeserializeIdentity([]byte(peerIdentity))
 		if err != nil {
-			logger.Debugf("Failed deserialization identity [% x] on [%s]: [%s]", peerIdentity, chainID, err)
+			log.Debugf("Failed deserialization identity [% x] on [%s]: [%s]", peerIdentity, chainID, err)
 			continue
 		}
 
@@ -213,15 +940,16 @@ func (s *mspMessageCryptoService) getValidatedIdentity(peerIdentity api.PeerIden
 		// against any channel's policies.
 		// This will be done by the caller function, if needed.
 
-		if err := identity.Validate(); err != nil {
-			logger.Debugf("Failed validating identity [% x] on [%s]: [%s]", peerIdentity, chainID, err)
+		if err := s.validate(identity); err != nil {
+			log.Debugf("Failed validating identity [% x] on [%s]: [%s]", peerIdentity, chainID, err)
+			validationErr = err
 			continue
 		}
 
-		logger.Debugf("Validation succesed  [% x] on [%s]", peerIdentity, chainID)
+		log.Debugf("Validation succesed  [% x] on [%s]", peerIdentity, chainID)
 
 		return identity, common.ChainID(chainID), nil
 	}
 
-	return nil, nil, fmt.Errorf("Peer Identity [% x] cannot be validated. No MSP found able to do that.", peerIdentity)
+	return nil, nil, s.newIdentityValidationError(managers, peerIdentity, validationErr)
 }
@@ -17,17 +17,26 @@ limitations under the License.
 package mcs
 
 import (
+	"bytes"
+	"errors"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"fmt"
 
 	"github.com/hyperledger/fabric/bccsp"
 	"github.com/hyperledger/fabric/bccsp/factory"
 	mockpolicies "github.com/hyperledger/fabric/common/mocks/policies"
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/common/util"
 	"github.com/hyperledger/fabric/gossip/api"
+	"github.com/hyperledger/fabric/gossip/common"
+	"github.com/hyperledger/fabric/msp"
 	"github.com/hyperledger/fabric/msp/mgmt"
 	"github.com/hyperledger/fabric/msp/mgmt/testtools"
+	"github.com/op/go-logging"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -71,6 +80,36 @@ func TestPKIidOfCert(t *testing.T) {
 	assert.Equal(t, digest, []byte(pkid), "PKID must be the SHA2-256 of peerIdentity")
 }
 
+func TestPKIidHash(t *testing.T) {
+	peerIdentity := newMockPeerIdentity("PKIidOrg", "peer0")
+
+	for _, name := range []string{PKIidHashSHA2_256, PKIidHashSHA2_384, PKIidHashSHA3_256, PKIidHashSHA3_384} {
+		opts, err := GetPKIidHashOpts(name)
+		assert.NoError(t, err)
+		digest, err := factory.GetDefault().Hash(peerIdentity, opts)
+		assert.NoError(t, err)
+		mcs := New(&mockpolicies.PolicyManagerMgmt{}, WithPKIidHash(opts))
+		assert.Equal(t, digest, []byte(mcs.GetPKIidOfCert(peerIdentity)), "PKID must be the %s of peerIdentity", name)
+	}
+
+	// SHA2-256 is the default
+	opts, err := GetPKIidHashOpts("")
+	assert.NoError(t, err)
+	assert.IsType(t, &bccsp.SHA256Opts{}, opts)
+
+	// SHA3-256 PKI-IDs differ from the default ones
+	opts, err = GetPKIidHashOpts(PKIidHashSHA3_256)
+	assert.NoError(t, err)
+	mcs := New(&mockpolicies.PolicyManagerMgmt{}, WithPKIidHash(opts))
+	assert.NotEqual(t, msgCryptoService.GetPKIidOfCert(peerIdentity), mcs.GetPKIidOfCert(peerIdentity))
+
+	// Unsupported hash functions
+	_, err = GetPKIidHashOpts("SM3")
+	assert.Error(t, err)
+	_, err = GetPKIidHashOpts("BLAKE2b")
+	assert.Error(t, err)
+}
+
 func TestPKIidOfNil(t *testing.T) {
 	pkid := msgCryptoService.GetPKIidOfCert(nil)
 	// Check pkid is not nil
@@ -97,3 +136,314 @@ func TestVerify(t *testing.T) {
 	err = msgCryptoService.Verify(peerIdentity, sigma, msg)
 	assert.NoError(t, err, "Failed verifying signature")
 }
+
+func TestSignForChannel(t *testing.T) {
+	msg := []byte("Hello World!!!")
+	sigma, err := msgCryptoService.(*mspMessageCryptoService).SignForChannel(common.ChainID(util.GetTestChainID()), msg)
+	assert.NoError(t, err, "Failed generating signature")
+	assert.NotNil(t, sigma, "Signature must be different from nil")
+
+	// The signature must have been produced by the local MSP identity,
+	// which is the one the test channel knows about
+	id, err := mgmt.GetLocalMSP().GetDefaultSigningIdentity()
+	assert.NoError(t, err, "Failed getting local default signing identity")
+	assert.NoError(t, id.Verify(msg, sigma), "Failed verifying signature")
+}
+
+func TestGetChannelIdentity(t *testing.T) {
+	mcs := msgCryptoService.(*mspMessageCryptoService)
+	chainID := common.ChainID(util.GetTestChainID())
+
+	peerIdentity, err := mcs.GetChannelIdentity(chainID)
+	assert.NoError(t, err)
+	id, err := mgmt.GetLocalMSP().GetDefaultSigningIdentity()
+	assert.NoError(t, err)
+	expected, err := id.Serialize()
+	assert.NoError(t, err)
+	assert.Equal(t, api.PeerIdentityType(expected), peerIdentity)
+
+	// Unknown channel
+	_, err = mcs.GetChannelIdentity(common.ChainID("unknown"))
+	assert.Error(t, err)
+}
+
+func TestSignForChannelNoSigningIdentity(t *testing.T) {
+	msg := []byte("Hello World!!!")
+	mcs := msgCryptoService.(*mspMessageCryptoService)
+
+	// Nil channel
+	_, err := mcs.SignForChannel(nil, msg)
+	assert.Error(t, err)
+
+	// Unknown channel
+	_, err = mcs.SignForChannel(common.ChainID("unknown"), msg)
+	assert.Error(t, err)
+
+	// Channel whose MSPs have no signing identity
+	bareMSP, err := msp.NewBccspMsp()
+	assert.NoError(t, err)
+	mspManager := msp.NewMSPManager()
+	assert.NoError(t, mspManager.Setup([]msp.MSP{bareMSP}))
+	mgmt.XXXSetMSPManager("nosigner", mspManager)
+
+	_, err = mcs.SignForChannel(common.ChainID("nosigner"), msg)
+	assert.Error(t, err)
+}
+
+func TestVerifyFresh(t *testing.T) {
+	chainID := "freshchannel"
+	setupMockChannel(chainID, "FreshOrg")
+	pm := newMockPolicyManager()
+	pm.setPolicy(chainID, policies.ChannelApplicationReaders, &mockChannelPolicy{chainID: chainID})
+	mcs := New(pm, WithClockSkew(time.Minute)).(*mspMessageCryptoService)
+
+	peerIdentity := newMockPeerIdentity("FreshOrg", "peer0")
+	msg := []byte("Hello World!!!")
+	window := 10 * time.Minute
+
+	// Within the window
+	assert.NoError(t, mcs.VerifyFresh(peerIdentity, mockSign(msg), msg, time.Now().Add(-time.Minute), window))
+	// Slightly in the future, within the clock skew
+	assert.NoError(t, mcs.VerifyFresh(peerIdentity, mockSign(msg), msg, time.Now().Add(30*time.Second), window))
+	// Fresh, but with an invalid signature
+	assert.Error(t, mcs.VerifyFresh(peerIdentity, []byte("bad"), msg, time.Now(), window))
+
+	// Too old
+	err := mcs.VerifyFresh(peerIdentity, mockSign(msg), msg, time.Now().Add(-time.Hour), window)
+	assert.IsType(t, &StaleMessageError{}, err)
+	// Too far in the future
+	err = mcs.VerifyFresh(peerIdentity, mockSign(msg), msg, time.Now().Add(time.Hour), window)
+	assert.IsType(t, &StaleMessageError{}, err)
+
+	// Invalid window
+	assert.Error(t, mcs.VerifyFresh(peerIdentity, mockSign(msg), msg, time.Now(), -window))
+}
+
+func TestChannelNormalizer(t *testing.T) {
+	chainID := "normchannel"
+	setupMockChannel(chainID, "NormOrg")
+	pm := newMockPolicyManager()
+	pm.setPolicy(chainID, policies.ChannelApplicationReaders, &mockChannelPolicy{chainID: chainID})
+
+	peerIdentity := newMockPeerIdentity("NormOrg", "peer0")
+	msg := []byte("Hello World!!!")
+
+	// Without normalization, the channel is not found
+	mcs := New(pm).(*mspMessageCryptoService)
+	assert.Error(t, mcs.VerifyByChannel(common.ChainID("NormChannel"), peerIdentity, mockSign(msg), msg))
+	assert.NoError(t, mcs.VerifyByChannel(common.ChainID(chainID), peerIdentity, mockSign(msg), msg))
+
+	// With normalization, both forms are accepted
+	lower := func(chainID common.ChainID) common.ChainID {
+		return common.ChainID(strings.ToLower(string(chainID)))
+	}
+	mcs = New(pm, WithChannelNormalizer(lower)).(*mspMessageCryptoService)
+	assert.NoError(t, mcs.VerifyByChannel(common.ChainID("NormChannel"), peerIdentity, mockSign(msg), msg))
+	assert.NoError(t, mcs.VerifyByChannel(common.ChainID(chainID), peerIdentity, mockSign(msg), msg))
+
+	// Lookups of MSP managers go through the normalizer as well
+	_, err := mcs.SignForChannel(common.ChainID(strings.ToUpper(util.GetTestChainID())), msg)
+	assert.NoError(t, err)
+}
+
+func TestFingerprint(t *testing.T) {
+	mcs := msgCryptoService.(*mspMessageCryptoService)
+	peerIdentity := newMockPeerIdentity("FingerprintOrg", "peer0")
+
+	fingerprint, err := mcs.Fingerprint(peerIdentity)
+	assert.NoError(t, err)
+	assert.Len(t, fingerprint, 3*fingerprintLength-1)
+
+	// The fingerprint is a prefix of the PKI-ID
+	pkiID := mcs.GetPKIidOfCert(peerIdentity)
+	assert.Equal(t, strings.Replace(fingerprint, ":", "", -1), fmt.Sprintf("%x", pkiID[:fingerprintLength]))
+
+	// It is stable
+	again, err := mcs.Fingerprint(peerIdentity)
+	assert.NoError(t, err)
+	assert.Equal(t, fingerprint, again)
+
+	// And different identities have different fingerprints
+	other, err := mcs.Fingerprint(newMockPeerIdentity("FingerprintOrg", "peer1"))
+	assert.NoError(t, err)
+	assert.NotEqual(t, fingerprint, other)
+
+	_, err = mcs.Fingerprint(nil)
+	assert.Error(t, err)
+}
+
+func TestVerifyByChannelGossipPolicy(t *testing.T) {
+	chainID := "gossippolicychannel"
+	setupMockChannel(chainID, "GossipPolicyOrg")
+	pm := newMockPolicyManager()
+	pm.setPolicy(chainID, policies.ChannelApplicationReaders, &mockChannelPolicy{chainID: chainID})
+	mcs := New(pm).(*mspMessageCryptoService)
+	msg := []byte("Hello World!!!")
+	peerIdentity := newMockPeerIdentity("GossipPolicyOrg", "peer0")
+
+	// The reader policy applies by default
+	assert.NoError(t, mcs.VerifyByChannel(common.ChainID(chainID), peerIdentity, mockSign(msg), msg))
+
+	// A dedicated gossip verification policy takes precedence
+	pm.setPolicy(chainID, policies.ChannelApplicationGossipVerification, &mockRejectPolicy{})
+	assert.Error(t, mcs.VerifyByChannel(common.ChainID(chainID), peerIdentity, mockSign(msg), msg))
+	policyName, _, err := mcs.getGossipPolicy(logger, common.ChainID(chainID))
+	assert.NoError(t, err)
+	assert.Equal(t, policies.ChannelApplicationGossipVerification, policyName)
+}
+
+func TestVerifyByChannelAll(t *testing.T) {
+	chainID := "allchannel"
+	setupMockChannel(chainID, "AllOrg")
+	pm := newMockPolicyManager()
+	pm.setPolicy(chainID, "Role", &mockChannelPolicy{chainID: chainID})
+	pm.setPolicy(chainID, "OU", &mockChannelPolicy{chainID: chainID})
+	pm.setPolicy(chainID, "Deny", &mockRejectPolicy{})
+	mcs := New(pm).(*mspMessageCryptoService)
+
+	peerIdentity := newMockPeerIdentity("AllOrg", "peer0")
+	msg := []byte("Hello World!!!")
+
+	assert.NoError(t, mcs.VerifyByChannelAll(common.ChainID(chainID), peerIdentity, mockSign(msg), msg, []string{"Role", "OU"}))
+
+	// One policy fails, one does not exist
+	err := mcs.VerifyByChannelAll(common.ChainID(chainID), peerIdentity, mockSign(msg), msg, []string{"Role", "Deny", "Missing"})
+	assert.IsType(t, &PolicyEvaluationError{}, err)
+	assert.Equal(t, []string{"Deny", "Missing"}, err.(*PolicyEvaluationError).Policies)
+
+	// Invalid signature fails every policy
+	err = mcs.VerifyByChannelAll(common.ChainID(chainID), peerIdentity, []byte("bad"), msg, []string{"Role", "OU"})
+	assert.IsType(t, &PolicyEvaluationError{}, err)
+	assert.Equal(t, []string{"Role", "OU"}, err.(*PolicyEvaluationError).Policies)
+
+	// Invalid identity
+	assert.Error(t, mcs.VerifyByChannelAll(common.ChainID(chainID), newMockPeerIdentity("UnknownOrg", "peer0"), mockSign(msg), msg, []string{"Role"}))
+	// No policies
+	assert.Error(t, mcs.VerifyByChannelAll(common.ChainID(chainID), peerIdentity, mockSign(msg), msg, nil))
+}
+
+func TestVerifyByChannelAdmins(t *testing.T) {
+	chainID := "adminschannel"
+	m := setupMockChannel(chainID, "AdminsOrg")
+	m.validateErrs["revoked"] = errors.New("revoked")
+	pm := newMockPolicyManager()
+	pm.setPolicy(chainID, policies.ChannelApplicationAdmins, &mockChannelPolicy{chainID: chainID})
+	mcs := New(pm).(*mspMessageCryptoService)
+
+	admin := newMockPeerIdentity("AdminsOrg", "admin0")
+	msg := []byte("Hello World!!!")
+
+	assert.NoError(t, mcs.VerifyByChannelAdmins(common.ChainID(chainID), admin, mockSign(msg), msg))
+	assert.Error(t, mcs.VerifyByChannelAdmins(common.ChainID(chainID), admin, []byte("bad"), msg))
+	assert.Error(t, mcs.VerifyByChannelAdmins(common.ChainID(chainID), newMockPeerIdentity("AdminsOrg", "revoked"), mockSign(msg), msg))
+	assert.Error(t, mcs.VerifyByChannelAdmins(common.ChainID("otherchannel"), admin, mockSign(msg), msg))
+
+	// Not an admin
+	pm.setPolicy(chainID, policies.ChannelApplicationAdmins, &mockRejectPolicy{})
+	assert.Error(t, mcs.VerifyByChannelAdmins(common.ChainID(chainID), admin, mockSign(msg), msg))
+
+	// No admins policy
+	setupMockChannel("noadminschannel", "AdminsOrg")
+	pm.setPolicy("noadminschannel", policies.ChannelApplicationReaders, &mockChannelPolicy{chainID: "noadminschannel"})
+	err := mcs.VerifyByChannelAdmins(common.ChainID("noadminschannel"), admin, mockSign(msg), msg)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "No admins policy")
+}
+
+func TestVerifyWithLogger(t *testing.T) {
+	chainID := "loggerchannel"
+	setupMockChannel(chainID, "LoggerOrg")
+	pm := newMockPolicyManager()
+	pm.setPolicy(chainID, policies.ChannelApplicationReaders, &mockChannelPolicy{chainID: chainID})
+	mcs := New(pm).(*mspMessageCryptoService)
+
+	// Capture the output of a dedicated logger
+	buf := &bytes.Buffer{}
+	backend := logging.AddModuleLevel(logging.NewLogBackend(buf, "", 0))
+	backend.SetLevel(logging.DEBUG, "")
+	log := logging.MustGetLogger("peer/gossip/mcs/request-42")
+	log.SetBackend(backend)
+
+	peerIdentity := newMockPeerIdentity("LoggerOrg", "peer0")
+	msg := []byte("Hello World!!!")
+
+	assert.NoError(t, mcs.VerifyWithLogger(log, peerIdentity, mockSign(msg), msg))
+	assert.Contains(t, buf.String(), "Got reader policy for channel [loggerchannel]")
+
+	buf.Reset()
+	assert.Error(t, mcs.VerifyWithLogger(log, newMockPeerIdentity("UnknownOrg", "peer0"), mockSign(msg), msg))
+	assert.Contains(t, buf.String(), "Failed getting validated identity")
+
+	// A nil logger falls back to the package logger
+	assert.NoError(t, mcs.VerifyWithLogger(nil, peerIdentity, mockSign(msg), msg))
+}
+
+func TestValidateIdentityManagersSnapshot(t *testing.T) {
+	mcs := New(&mockpolicies.PolicyManagerMgmt{}).(*mspMessageCryptoService)
+	peer := newMockPeerIdentity("SnapshotOrg", "peer0")
+
+	// The first channel rejects the identity and, while being walked,
+	// sees a config update making the second channel reject it too
+	first := setupMockChannel("snapshot1", "SnapshotOrg")
+	first.validateErrs["peer0"] = errors.New("revoked")
+	setupMockChannel("snapshot2", "SnapshotOrg")
+	updated := false
+	first.onDeserialize = func() {
+		if !updated {
+			updated = true
+			setupMockChannel("snapshot2", "SnapshotOrg").validateErrs["peer0"] = errors.New("revoked")
+		}
+	}
+
+	// The validation reflects the config as of its start
+	_, chainID, err := mcs.validateIdentity(logger, peer)
+	assert.NoError(t, err)
+	assert.Equal(t, common.ChainID("snapshot2"), chainID)
+	assert.True(t, updated)
+
+	// Later validations reflect the update
+	_, _, err = mcs.validateIdentity(logger, peer)
+	assert.Error(t, err)
+}
+
+func TestValidateIdentityDeterministicOrder(t *testing.T) {
+	mcs := New(&mockpolicies.PolicyManagerMgmt{}).(*mspMessageCryptoService)
+	peer := newMockPeerIdentity("OrderOrg", "peer0")
+	for _, chainID := range []string{"order-c", "order-a", "order-b"} {
+		setupMockChannel(chainID, "OrderOrg")
+	}
+
+	for i := 0; i < 20; i++ {
+		_, chainID, err := mcs.validateIdentity(logger, peer)
+		assert.NoError(t, err)
+		assert.Equal(t, common.ChainID("order-a"), chainID)
+	}
+}
+
+func TestValidateIdentityConcurrentConfigUpdates(t *testing.T) {
+	mcs := New(&mockpolicies.PolicyManagerMgmt{})
+	peer := newMockPeerIdentity("ConcurrentOrg", "peer0")
+	setupMockChannel("concurrent0", "ConcurrentOrg")
+
+	stop := make(chan struct{})
+	updated := make(chan struct{})
+	go func() {
+		defer close(updated)
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			// Config updates, and channels being joined
+			setupMockChannel("concurrent0", "ConcurrentOrg")
+			setupMockChannel(fmt.Sprintf("concurrent%d", i%10+1), "ConcurrentOrg")
+		}
+	}()
+
+	for i := 0; i < 1000; i++ {
+		assert.NoError(t, mcs.ValidateIdentity(peer))
+	}
+	close(stop)
+	<-updated
+}
@@ -0,0 +1,177 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcs
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric/gossip/api"
+	"github.com/hyperledger/fabric/gossip/common"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/msp/mgmt"
+)
+
+// WithNegativeMembershipCache makes ValidateIdentityForChannel remember,
+// for ttl, that an identity is not a member of a channel, so that repeated
+// checks of the same identity against the same channel, as happens during
+// membership churn, do not go through the channel's MSPs every time.
+//
+// A cached negative result is discarded as soon as the configuration of
+// the channel changes (i.e. the channel's MSP manager is replaced, or
+// ChannelConfigUpdated is invoked for the channel), hence
+// members added by a configuration update are recognized promptly.
+// Changes that do not go through a configuration update, such as the
+// removal of a revocation from an MSP, are only picked up once the entry
+// expires: the longer ttl, the less work during churn, but the longer
+// such an identity may be wrongly rejected. Keep ttl short.
+// Positive results are never cached.
+func WithNegativeMembershipCache(ttl time.Duration) Option {
+	return func(s *mspMessageCryptoService) {
+		if ttl <= 0 {
+			return
+		}
+		s.nonMembers = &membershipCache{
+			ttl:     ttl,
+			entries: make(map[membershipKey]*membershipEntry),
+		}
+	}
+}
+
+// ValidateIdentityForChannel validates the identity of a remote peer
+// with the MSPs of the channel identified by chainID.
+// If the identity is not a valid member of the channel it returns an
+// error. Else, returns nil.
+func (s *mspMessageCryptoService) ValidateIdentityForChannel(chainID common.ChainID, peerIdentity api.PeerIdentityType) error {
+	// Validate arguments
+	if len(peerIdentity) == 0 {
+		return errors.New("Invalid Peer Identity. It must be different from nil.")
+	}
+
+	chainID = s.normalizeChannel(chainID)
+	mspManager := mgmt.GetManagerForChainIfExists(string(chainID))
+	if mspManager == nil {
+		return fmt.Errorf("No MSP manager found for channel [%s]", string(chainID))
+	}
+
+	key := membershipKey{pkiID: string(s.GetPKIidOfCert(peerIdentity)), chainID: string(chainID)}
+	if err := s.nonMembers.get(key, mspManager); err != nil {
+		logger.Debugf("Peer identity [% x] is not a member of channel [%s] (cached): [%s]", []byte(peerIdentity), string(chainID), err)
+		return err
+	}
+
+	identity, err := mspManager.DeserializeIdentity(peerIdentity)
+	if err == nil {
+		err = s.validate(identity)
+	}
+	if err == nil {
+		err = s.checkNotBefore(peerIdentity)
+	}
+	if err != nil {
+		err = fmt.Errorf("Peer identity [% x] is not a member of channel [%s]: [%s]", []byte(peerIdentity), string(chainID), err)
+		s.nonMembers.put(key, mspManager, err)
+		return err
+	}
+
+	return nil
+}
+
+type membershipKey struct {
+	pkiID   string
+	chainID string
+}
+
+type membershipEntry struct {
+	// mspManager is the channel's MSP manager
+	// at the time the entry was created
+	mspManager msp.MSPManager
+	expiration time.Time
+	err        error
+}
+
+// membershipCache keeps the negative
+// results of channel membership checks
+type membershipCache struct {
+	ttl time.Duration
+
+	lock    sync.Mutex
+	entries map[membershipKey]*membershipEntry
+	// nextPurge is when expired entries are purged next
+	nextPurge time.Time
+}
+
+// get returns the cached error for key, if any, provided that
+// it has not expired and the channel's MSP manager is still mspManager
+func (c *membershipCache) get(key membershipKey, mspManager msp.MSPManager) error {
+	if c == nil {
+		return nil
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+	if entry.mspManager != mspManager || !time.Now().Before(entry.expiration) {
+		delete(c.entries, key)
+		return nil
+	}
+
+	return entry.err
+}
+
+func (c *membershipCache) put(key membershipKey, mspManager msp.MSPManager, err error) {
+	if c == nil {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	// Purge the expired entries at most once per ttl
+	now := time.Now()
+	if !now.Before(c.nextPurge) {
+		for k, entry := range c.entries {
+			if !now.Before(entry.expiration) {
+				delete(c.entries, k)
+			}
+		}
+		c.nextPurge = now.Add(c.ttl)
+	}
+
+	c.entries[key] = &membershipEntry{mspManager: mspManager, expiration: now.Add(c.ttl), err: err}
+}
+
+// invalidateChannel discards the entries of channel chainID
+func (c *membershipCache) invalidateChannel(chainID common.ChainID) {
+	if c == nil {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for key := range c.entries {
+		if key.chainID == string(chainID) {
+			delete(c.entries, key)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcs
+
+import (
+	"testing"
+	"time"
+
+	mockpolicies "github.com/hyperledger/fabric/common/mocks/policies"
+	"github.com/hyperledger/fabric/gossip/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateIdentityForChannel(t *testing.T) {
+	chainID := common.ChainID("memberchannel")
+	setupMockChannel(string(chainID), "MemberOrg").validateErrs["revoked"] = assert.AnError
+	mcs := New(&mockpolicies.PolicyManagerMgmt{}).(*mspMessageCryptoService)
+
+	assert.NoError(t, mcs.ValidateIdentityForChannel(chainID, newMockPeerIdentity("MemberOrg", "peer0")))
+	assert.Error(t, mcs.ValidateIdentityForChannel(chainID, newMockPeerIdentity("MemberOrg", "revoked")))
+	assert.Error(t, mcs.ValidateIdentityForChannel(chainID, newMockPeerIdentity("OtherOrg", "peer0")))
+	assert.Error(t, mcs.ValidateIdentityForChannel(common.ChainID("nosuchchannel"), newMockPeerIdentity("MemberOrg", "peer0")))
+	assert.Error(t, mcs.ValidateIdentityForChannel(chainID, nil))
+}
+
+func TestNegativeMembershipCache(t *testing.T) {
+	chainID := common.ChainID("nonmemberchannel")
+	m := setupMockChannel(string(chainID), "MemberOrg")
+	m.validateErrs["peer0"] = assert.AnError
+	peerIdentity := newMockPeerIdentity("MemberOrg", "peer0")
+
+	// Without cache, a change is observed immediately
+	mcs := New(&mockpolicies.PolicyManagerMgmt{}).(*mspMessageCryptoService)
+	assert.Error(t, mcs.ValidateIdentityForChannel(chainID, peerIdentity))
+	delete(m.validateErrs, "peer0")
+	assert.NoError(t, mcs.ValidateIdentityForChannel(chainID, peerIdentity))
+
+	// With cache, the negative result is served until it expires
+	mcs = New(&mockpolicies.PolicyManagerMgmt{}, WithNegativeMembershipCache(100*time.Millisecond)).(*mspMessageCryptoService)
+	m.validateErrs["peer0"] = assert.AnError
+	assert.Error(t, mcs.ValidateIdentityForChannel(chainID, peerIdentity))
+	delete(m.validateErrs, "peer0")
+	assert.Error(t, mcs.ValidateIdentityForChannel(chainID, peerIdentity))
+	time.Sleep(150 * time.Millisecond)
+	assert.NoError(t, mcs.ValidateIdentityForChannel(chainID, peerIdentity))
+
+	// Positive results are not cached
+	m.validateErrs["peer0"] = assert.AnError
+	assert.Error(t, mcs.ValidateIdentityForChannel(chainID, peerIdentity))
+
+	// A channel configuration change invalidates the cache
+	mcs = New(&mockpolicies.PolicyManagerMgmt{}, WithNegativeMembershipCache(time.Hour)).(*mspMessageCryptoService)
+	newcomer := newMockPeerIdentity("NewOrg", "peer1")
+	assert.Error(t, mcs.ValidateIdentityForChannel(chainID, newcomer))
+	assert.Error(t, mcs.ValidateIdentityForChannel(chainID, newcomer))
+	setupMockChannel(string(chainID), "NewOrg")
+	assert.NoError(t, mcs.ValidateIdentityForChannel(chainID, newcomer))
+}
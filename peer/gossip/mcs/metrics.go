@@ -0,0 +1,178 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcs
+
+import (
+	"time"
+)
+
+// Histogram records the distribution of observed values
+type Histogram interface {
+	// With returns a Histogram whose observations carry the labels
+	// given as alternating label names and values
+	With(labelValues ...string) Histogram
+
+	// Observe records value
+	Observe(value float64)
+}
+
+// Counter records a monotonically increasing value
+type Counter interface {
+	// With returns a Counter whose increments carry the labels
+	// given as alternating label names and values
+	With(labelValues ...string) Counter
+
+	// Add increases the counter by delta
+	Add(delta float64)
+}
+
+// MetricsProvider creates the metrics the MessageCryptoService reports
+type MetricsProvider interface {
+	// NewHistogram returns the histogram named name
+	NewHistogram(name string) Histogram
+
+	// NewCounter returns the counter named name
+	NewCounter(name string) Counter
+}
+
+const (
+	// IdentityValidationDuration is the name of the histogram of the
+	// time, in seconds, taken to validate identities, labeled with
+	// "cache" being "hit" or "miss"
+	IdentityValidationDuration = "identity_validation_duration"
+
+	// VerificationFailuresDropped is the name of the counter of the
+	// verification failures that could not be published because
+	// the consumer was not keeping up
+	VerificationFailuresDropped = "verification_failures_dropped"
+
+	// VerifyDuration is the name of the histogram of the time, in
+	// seconds, taken to verify signatures, labeled with "operation"
+	// being "Verify" or "VerifyByChannel", and "result" being
+	// "success" or "failure"
+	VerifyDuration = "verify_duration"
+
+	// ValidateIdentityFailures is the name of the counter
+	// of the identities that failed validation
+	ValidateIdentityFailures = "validate_identity_failures"
+
+	// BlockVerificationTotal is the name of the counter of the
+	// blocks verified, labeled with "result" being "success"
+	// or "failure"
+	BlockVerificationTotal = "block_verification_total"
+
+	// OversizedIdentities is the name of the counter of the
+	// identities rejected for exceeding the maximum size
+	OversizedIdentities = "oversized_identities"
+)
+
+// WithMetricsProvider makes the MessageCryptoService
+// report its metrics through provider
+func WithMetricsProvider(provider MetricsProvider) Option {
+	return func(s *mspMessageCryptoService) {
+		if provider == nil {
+			return
+		}
+		s.metrics = &serviceMetrics{
+			identityValidationDuration:  provider.NewHistogram(IdentityValidationDuration),
+			verificationFailuresDropped: provider.NewCounter(VerificationFailuresDropped),
+			verifyDuration:              provider.NewHistogram(VerifyDuration),
+			validateIdentityFailures:    provider.NewCounter(ValidateIdentityFailures),
+			blockVerificationTotal:      provider.NewCounter(BlockVerificationTotal),
+			oversizedIdentities:         provider.NewCounter(OversizedIdentities),
+		}
+	}
+}
+
+type serviceMetrics struct {
+	identityValidationDuration  Histogram
+	verificationFailuresDropped Counter
+	verifyDuration              Histogram
+	validateIdentityFailures    Counter
+	blockVerificationTotal      Counter
+	oversizedIdentities         Counter
+}
+
+// result returns the label value of the result of an operation failed with err
+func result(err error) string {
+	if err != nil {
+		return "failure"
+	}
+	return "success"
+}
+
+// observeIdentityValidation records the duration of an identity
+// validation started at start, served from the cache if cached
+func (m *serviceMetrics) observeIdentityValidation(start time.Time, cached bool) {
+	if m == nil {
+		return
+	}
+
+	cache := "miss"
+	if cached {
+		cache = "hit"
+	}
+	m.identityValidationDuration.With("cache", cache).Observe(time.Since(start).Seconds())
+}
+
+// countDroppedFailure records that a verification failure was dropped
+func (m *serviceMetrics) countDroppedFailure() {
+	if m == nil {
+		return
+	}
+
+	m.verificationFailuresDropped.Add(1)
+}
+
+// observeVerify records the duration of the verification
+// operation started at start, which failed with err, if any
+func (m *serviceMetrics) observeVerify(operation string, start time.Time, err error) {
+	if m == nil {
+		return
+	}
+
+	m.verifyDuration.With("operation", operation, "result", result(err)).Observe(time.Since(start).Seconds())
+}
+
+// countIdentityValidationFailure records that an identity failed validation
+func (m *serviceMetrics) countIdentityValidationFailure() {
+	if m == nil {
+		return
+	}
+
+	m.validateIdentityFailures.Add(1)
+}
+
+// countBlockVerification records the verification
+// of a block, which failed with err, if any
+func (m *serviceMetrics) countBlockVerification(err error) {
+	if m == nil {
+		return
+	}
+
+	m.blockVerificationTotal.With("result", result(err)).Add(1)
+}
+
+// countOversizedIdentity records that an
+// identity exceeding the maximum size was rejected
+func (m *serviceMetrics) countOversizedIdentity() {
+	if m == nil {
+		return
+	}
+
+	m.oversizedIdentities.Add(1)
+}
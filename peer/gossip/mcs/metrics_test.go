@@ -0,0 +1,148 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcs
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	mockpolicies "github.com/hyperledger/fabric/common/mocks/policies"
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/gossip/api"
+	"github.com/hyperledger/fabric/gossip/common"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockMetricsProvider records the observations of its histograms
+// and counters, by metric name and labels
+type mockMetricsProvider struct {
+	lock         sync.Mutex
+	observations map[string][]float64
+}
+
+func newMockMetricsProvider() *mockMetricsProvider {
+	return &mockMetricsProvider{observations: make(map[string][]float64)}
+}
+
+func (p *mockMetricsProvider) NewHistogram(name string) Histogram {
+	return &mockHistogram{provider: p, name: name}
+}
+
+func (p *mockMetricsProvider) NewCounter(name string) Counter {
+	return &mockCounter{provider: p, name: name}
+}
+
+func (p *mockMetricsProvider) get(name string) []float64 {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.observations[name]
+}
+
+type mockHistogram struct {
+	provider *mockMetricsProvider
+	name     string
+}
+
+func (h *mockHistogram) With(labelValues ...string) Histogram {
+	return &mockHistogram{provider: h.provider, name: h.name + "{" + strings.Join(labelValues, ",") + "}"}
+}
+
+func (h *mockHistogram) Observe(value float64) {
+	h.provider.lock.Lock()
+	defer h.provider.lock.Unlock()
+	h.provider.observations[h.name] = append(h.provider.observations[h.name], value)
+}
+
+type mockCounter struct {
+	provider *mockMetricsProvider
+	name     string
+}
+
+func (c *mockCounter) With(labelValues ...string) Counter {
+	return &mockCounter{provider: c.provider, name: c.name + "{" + strings.Join(labelValues, ",") + "}"}
+}
+
+func (c *mockCounter) Add(delta float64) {
+	c.provider.lock.Lock()
+	defer c.provider.lock.Unlock()
+	c.provider.observations[c.name] = append(c.provider.observations[c.name], delta)
+}
+
+func TestIdentityValidationMetrics(t *testing.T) {
+	setupMockChannel("metricschannel", "MetricsOrg")
+	provider := newMockMetricsProvider()
+	mcs := New(&mockpolicies.PolicyManagerMgmt{},
+		WithMetricsProvider(provider),
+		WithIdentityCache(NewLRUIdentityCache(10), time.Hour))
+
+	peerIdentity := newMockPeerIdentity("MetricsOrg", "peer0")
+	assert.NoError(t, mcs.ValidateIdentity(peerIdentity))
+	assert.NoError(t, mcs.ValidateIdentity(peerIdentity))
+	assert.NoError(t, mcs.ValidateIdentity(peerIdentity))
+	assert.Error(t, mcs.ValidateIdentity(newMockPeerIdentity("UnknownOrg", "peer0")))
+
+	assert.Len(t, provider.get(IdentityValidationDuration+"{cache,miss}"), 2)
+	assert.Len(t, provider.get(IdentityValidationDuration+"{cache,hit}"), 2)
+	for _, d := range provider.get(IdentityValidationDuration + "{cache,miss}") {
+		assert.True(t, d >= 0)
+	}
+
+	// No provider
+	assert.NoError(t, New(&mockpolicies.PolicyManagerMgmt{}).ValidateIdentity(peerIdentity))
+}
+
+func TestVerificationMetrics(t *testing.T) {
+	chainID := "verifymetricschannel"
+	setupMockChannel(chainID, "VerifyMetricsOrg")
+	setupMockChannel("blockmetricschannel", "OrdererOrg")
+	pm := newMockPolicyManager()
+	pm.setPolicy(chainID, policies.ChannelApplicationReaders, &mockChannelPolicy{chainID: chainID})
+	pm.setPolicy("blockmetricschannel", policies.BlockValidation, &mockChannelPolicy{chainID: "blockmetricschannel"})
+	provider := newMockMetricsProvider()
+	mcs := New(pm, WithMetricsProvider(provider))
+	msg := []byte("Hello World!!!")
+	peerIdentity := newMockPeerIdentity("VerifyMetricsOrg", "peer0")
+
+	assert.NoError(t, mcs.Verify(peerIdentity, mockSign(msg), msg))
+	assert.Error(t, mcs.Verify(peerIdentity, []byte("bad signature"), msg))
+	assert.Error(t, mcs.Verify(newMockPeerIdentity("UnknownOrg", "peer0"), mockSign(msg), msg))
+	assert.Len(t, provider.get(VerifyDuration+"{operation,Verify,result,success}"), 1)
+	assert.Len(t, provider.get(VerifyDuration+"{operation,Verify,result,failure}"), 2)
+
+	assert.NoError(t, mcs.VerifyByChannel(common.ChainID(chainID), peerIdentity, mockSign(msg), msg))
+	assert.Error(t, mcs.VerifyByChannel(common.ChainID(chainID), peerIdentity, []byte("bad signature"), msg))
+	assert.Len(t, provider.get(VerifyDuration+"{operation,VerifyByChannel,result,success}"), 1)
+	assert.Len(t, provider.get(VerifyDuration+"{operation,VerifyByChannel,result,failure}"), 1)
+
+	assert.Error(t, mcs.ValidateIdentity(newMockPeerIdentity("UnknownOrg", "peer1")))
+	// One failure by Verify, one by ValidateIdentity
+	assert.Equal(t, []float64{1, 1}, provider.get(ValidateIdentityFailures))
+
+	orderer := newMockPeerIdentity("OrdererOrg", "orderer0")
+	assert.NoError(t, mcs.VerifyBlock(common.ChainID("blockmetricschannel"), mockBlock("blockmetricschannel", orderer)))
+	assert.Error(t, mcs.VerifyBlock(common.ChainID("blockmetricschannel"), mockBlock("blockmetricschannel")))
+	errs := mcs.(*mspMessageCryptoService).VerifyBlocks(common.ChainID("blockmetricschannel"), []api.SignedBlock{
+		mockBlock("blockmetricschannel", orderer),
+		mockBlock("blockmetricschannel", orderer),
+	})
+	assert.NoError(t, errs[0])
+	assert.NoError(t, errs[1])
+	assert.Len(t, provider.get(BlockVerificationTotal+"{result,success}"), 3)
+	assert.Len(t, provider.get(BlockVerificationTotal+"{result,failure}"), 1)
+}
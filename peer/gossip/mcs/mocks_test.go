@@ -0,0 +1,295 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcs
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/gossip/api"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/msp/mgmt"
+	protoscommon "github.com/hyperledger/fabric/protos/common"
+	protosmsp "github.com/hyperledger/fabric/protos/msp"
+)
+
+// The mocks below allow to exercise the verification paths
+// without depending on x.509 material and its validity period.
+// Mock identities are serialized as SerializedIdentity with
+// IdBytes set to the name of the peer; a valid signature of
+// msg is mockSign(msg).
+
+const mockMSPID = "MockOrg"
+
+func mockSign(msg []byte) []byte {
+	return append([]byte("signed:"), msg...)
+}
+
+func newMockPeerIdentity(mspID, name string) api.PeerIdentityType {
+	raw, err := proto.Marshal(&msp.SerializedIdentity{Mspid: mspID, IdBytes: []byte(name)})
+	if err != nil {
+		panic(err)
+	}
+	return raw
+}
+
+// setupMockChannel registers on chainID a channel MSP manager
+// with a single mockMSP and returns that mockMSP
+func setupMockChannel(chainID string, mspID string) *mockMSP {
+	m := &mockMSP{id: mspID, validateErrs: make(map[string]error)}
+	mspManager := msp.NewMSPManager()
+	if err := mspManager.Setup([]msp.MSP{m}); err != nil {
+		panic(err)
+	}
+	mgmt.XXXSetMSPManager(chainID, mspManager)
+	return m
+}
+
+type mockIdentity struct {
+	msp  *mockMSP
+	name string
+}
+
+func (id *mockIdentity) GetIdentifier() *msp.IdentityIdentifier {
+	return &msp.IdentityIdentifier{Mspid: id.msp.id, Id: id.name}
+}
+
+func (id *mockIdentity) GetMSPIdentifier() string {
+	return id.msp.id
+}
+
+func (id *mockIdentity) Validate() error {
+	return id.msp.Validate(id)
+}
+
+func (id *mockIdentity) GetOrganizationalUnits() []*msp.OUIdentifier {
+	return nil
+}
+
+func (id *mockIdentity) Anonymous() bool {
+	return false
+}
+
+func (id *mockIdentity) Verify(msg []byte, sig []byte) error {
+	if !bytes.Equal(sig, mockSign(msg)) {
+		return errors.New("Invalid signature")
+	}
+	return nil
+}
+
+func (id *mockIdentity) VerifyOpts(msg []byte, sig []byte, opts msp.SignatureOpts) error {
+	return id.Verify(msg, sig)
+}
+
+func (id *mockIdentity) VerifyAttributes(proof []byte, spec *msp.AttributeProofSpec) error {
+	return nil
+}
+
+func (id *mockIdentity) Serialize() ([]byte, error) {
+	return newMockPeerIdentity(id.msp.id, id.name), nil
+}
+
+func (id *mockIdentity) SatisfiesPrincipal(principal *protoscommon.MSPPrincipal) error {
+	return id.msp.SatisfiesPrincipal(id, principal)
+}
+
+type mockSigningIdentity struct {
+	mockIdentity
+}
+
+func (id *mockSigningIdentity) Sign(msg []byte) ([]byte, error) {
+	return mockSign(msg), nil
+}
+
+func (id *mockSigningIdentity) SignOpts(msg []byte, opts msp.SignatureOpts) ([]byte, error) {
+	return id.Sign(msg)
+}
+
+func (id *mockSigningIdentity) GetAttributeProof(spec *msp.AttributeProofSpec) ([]byte, error) {
+	return nil, nil
+}
+
+func (id *mockSigningIdentity) GetPublicVersion() msp.Identity {
+	return &id.mockIdentity
+}
+
+func (id *mockSigningIdentity) Renew() error {
+	return nil
+}
+
+type mockMSP struct {
+	id string
+
+	// validateErrs maps the name of an identity
+	// to the error its validation returns
+	validateErrs map[string]error
+
+	// signer is the default signing identity, if any
+	signer msp.SigningIdentity
+
+	// onDeserialize, if not nil, is invoked
+	// on every deserialization
+	onDeserialize func()
+}
+
+func (m *mockMSP) DeserializeIdentity(serializedID []byte) (msp.Identity, error) {
+	if m.onDeserialize != nil {
+		m.onDeserialize()
+	}
+	sID := &msp.SerializedIdentity{}
+	if err := proto.Unmarshal(serializedID, sID); err != nil {
+		return nil, err
+	}
+	if sID.Mspid != m.id {
+		return nil, fmt.Errorf("Expected MSP ID %s, received %s", m.id, sID.Mspid)
+	}
+	return &mockIdentity{msp: m, name: string(sID.IdBytes)}, nil
+}
+
+func (m *mockMSP) Setup(config *protosmsp.MSPConfig) error {
+	return nil
+}
+
+func (m *mockMSP) GetType() msp.ProviderType {
+	return msp.OTHER
+}
+
+func (m *mockMSP) GetIdentifier() (string, error) {
+	return m.id, nil
+}
+
+func (m *mockMSP) GetSigningIdentity(identifier *msp.IdentityIdentifier) (msp.SigningIdentity, error) {
+	return m.GetDefaultSigningIdentity()
+}
+
+func (m *mockMSP) GetDefaultSigningIdentity() (msp.SigningIdentity, error) {
+	if m.signer == nil {
+		return nil, errors.New("No signing identity")
+	}
+	return m.signer, nil
+}
+
+func (m *mockMSP) Validate(id msp.Identity) error {
+	return m.validateErrs[id.(*mockIdentity).name]
+}
+
+func (m *mockMSP) SatisfiesPrincipal(id msp.Identity, principal *protoscommon.MSPPrincipal) error {
+	if err := m.Validate(id); err != nil {
+		return err
+	}
+	if principal.PrincipalClassification != protoscommon.MSPPrincipal_ROLE {
+		return errors.New("Unsupported principal")
+	}
+	role := &protoscommon.MSPRole{}
+	if err := proto.Unmarshal(principal.Principal, role); err != nil {
+		return err
+	}
+	if role.MspIdentifier != m.id {
+		return errors.New("The identity is a member of a different MSP")
+	}
+	return nil
+}
+
+// mockChannelPolicy is a policy that is satisfied by any
+// valid signature of a member of the MSPs of its channel
+type mockChannelPolicy struct {
+	chainID string
+}
+
+func (p *mockChannelPolicy) Evaluate(signatureSet []*protoscommon.SignedData) error {
+	for _, sd := range signatureSet {
+		identity, err := mgmt.GetManagerForChain(p.chainID).DeserializeIdentity(sd.Identity)
+		if err != nil {
+			continue
+		}
+		if identity.Validate() != nil {
+			continue
+		}
+		if identity.Verify(sd.Data, sd.Signature) == nil {
+			return nil
+		}
+	}
+	return errors.New("Policy not satisfied")
+}
+
+// mockPolicyManager is a policies.Manager serving, for each
+// channel, the policies registered for that channel
+type mockPolicyManager struct {
+	policies map[string]policies.Policy
+	channels map[string]*mockPolicyManager
+}
+
+func newMockPolicyManager() *mockPolicyManager {
+	return &mockPolicyManager{
+		policies: make(map[string]policies.Policy),
+		channels: make(map[string]*mockPolicyManager),
+	}
+}
+
+// setPolicy registers policy under name on channel chainID
+func (m *mockPolicyManager) setPolicy(chainID, name string, policy policies.Policy) {
+	cpm, ok := m.channels[chainID]
+	if !ok {
+		cpm = newMockPolicyManager()
+		m.channels[chainID] = cpm
+	}
+	cpm.policies[name] = policy
+}
+
+func (m *mockPolicyManager) GetPolicy(id string) (policies.Policy, bool) {
+	policy, ok := m.policies[id]
+	if !ok {
+		return &mockRejectPolicy{}, false
+	}
+	return policy, true
+}
+
+func (m *mockPolicyManager) Manager(path []string) (policies.Manager, bool) {
+	if len(path) == 0 {
+		return m, true
+	}
+	cpm, ok := m.channels[path[0]]
+	if !ok {
+		return nil, false
+	}
+	return cpm.Manager(path[1:])
+}
+
+func (m *mockPolicyManager) BasePath() string {
+	return ""
+}
+
+func (m *mockPolicyManager) PolicyNames() []string {
+	names := make([]string, 0, len(m.policies))
+	for name := range m.policies {
+		names = append(names, name)
+	}
+	return names
+}
+
+func (m *mockPolicyManager) Snapshot() policies.Snapshot {
+	panic("implement me")
+}
+
+type mockRejectPolicy struct{}
+
+func (p *mockRejectPolicy) Evaluate(signatureSet []*protoscommon.SignedData) error {
+	return errors.New("No such policy")
+}
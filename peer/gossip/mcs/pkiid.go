@@ -0,0 +1,68 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcs
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric/bccsp"
+)
+
+// Names of the hash functions the PKI-ID of
+// a peer's identity can be computed with
+const (
+	PKIidHashSHA2_256 = "SHA2-256"
+	PKIidHashSHA2_384 = "SHA2-384"
+	PKIidHashSHA3_256 = "SHA3-256"
+	PKIidHashSHA3_384 = "SHA3-384"
+)
+
+// GetPKIidHashOpts returns the hash options of the hash function
+// with the given name, to be passed to WithPKIidHash.
+// An empty name selects SHA2-256, the default.
+func GetPKIidHashOpts(name string) (bccsp.HashOpts, error) {
+	switch name {
+	case "", PKIidHashSHA2_256:
+		return &bccsp.SHA256Opts{}, nil
+	case PKIidHashSHA2_384:
+		return &bccsp.SHA384Opts{}, nil
+	case PKIidHashSHA3_256:
+		return &bccsp.SHA3_256Opts{}, nil
+	case PKIidHashSHA3_384:
+		return &bccsp.SHA3_384Opts{}, nil
+	default:
+		return nil, fmt.Errorf("Hash function [%s] not supported for PKI-IDs", name)
+	}
+}
+
+// WithPKIidHash makes GetPKIidOfCert compute PKI-IDs with the
+// hash function described by opts instead of SHA2-256.
+// All the peers of a network must use the same hash function,
+// or they will not recognize each other's PKI-IDs.
+func WithPKIidHash(opts bccsp.HashOpts) Option {
+	return func(s *mspMessageCryptoService) {
+		s.pkiIDHashOpts = opts
+	}
+}
+
+// getPKIidHashOpts returns the hash options PKI-IDs are computed with
+func (s *mspMessageCryptoService) getPKIidHashOpts() bccsp.HashOpts {
+	if s.pkiIDHashOpts == nil {
+		return &bccsp.SHA256Opts{}
+	}
+	return s.pkiIDHashOpts
+}
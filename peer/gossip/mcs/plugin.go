@@ -0,0 +1,78 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcs
+
+import (
+	"fmt"
+	"plugin"
+
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/gossip/api"
+)
+
+// PluginFactorySymbol is the name of the function a MessageCryptoService
+// plugin must export. Its signature must be that of PluginFactory:
+//
+//	func NewMessageCryptoService(manager policies.Manager) (api.MessageCryptoService, error)
+const PluginFactorySymbol = "NewMessageCryptoService"
+
+// PluginFactory creates a MessageCryptoService given the policy manager
+// giving access to the policy managers of channels, as New does
+type PluginFactory func(manager policies.Manager) (api.MessageCryptoService, error)
+
+// LoadPlugin loads the Go plugin at path and returns the MessageCryptoService
+// created by the function it exports as PluginFactorySymbol. This allows
+// replacing the MSP-based MessageCryptoService, for instance with a
+// hardware-backed one, without rebuilding the peer. The plugin must be
+// built against the same sources as the peer.
+func LoadPlugin(path string, manager policies.Manager) (api.MessageCryptoService, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("Failed opening MessageCryptoService plugin [%s]: [%s]", path, err)
+	}
+
+	sym, err := p.Lookup(PluginFactorySymbol)
+	if err != nil {
+		return nil, fmt.Errorf("Failed looking up [%s] in MessageCryptoService plugin [%s]: [%s]", PluginFactorySymbol, path, err)
+	}
+
+	factory, err := getPluginFactory(sym)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid MessageCryptoService plugin [%s]: [%s]", path, err)
+	}
+
+	mcs, err := factory(manager)
+	if err != nil {
+		return nil, fmt.Errorf("MessageCryptoService plugin [%s] failed creating the service: [%s]", path, err)
+	}
+	if mcs == nil {
+		return nil, fmt.Errorf("MessageCryptoService plugin [%s] returned no service", path)
+	}
+
+	return mcs, nil
+}
+
+// getPluginFactory returns the PluginFactory sym is
+func getPluginFactory(sym plugin.Symbol) (PluginFactory, error) {
+	switch factory := sym.(type) {
+	case func(policies.Manager) (api.MessageCryptoService, error):
+		return factory, nil
+	case *PluginFactory:
+		return *factory, nil
+	}
+	return nil, fmt.Errorf("[%s] is of type [%T], expected [%T]", PluginFactorySymbol, sym, PluginFactory(nil))
+}
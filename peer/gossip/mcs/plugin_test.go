@@ -0,0 +1,53 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcs
+
+import (
+	"testing"
+
+	mockpolicies "github.com/hyperledger/fabric/common/mocks/policies"
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/gossip/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func newPluginMCS(manager policies.Manager) (api.MessageCryptoService, error) {
+	return New(manager), nil
+}
+
+func TestLoadPlugin(t *testing.T) {
+	_, err := LoadPlugin("/nonexistent/mcs.so", &mockpolicies.PolicyManagerMgmt{})
+	assert.Error(t, err)
+
+	// Exported functions and function variables
+	factory, err := getPluginFactory(newPluginMCS)
+	assert.NoError(t, err)
+	mcs, err := factory(&mockpolicies.PolicyManagerMgmt{})
+	assert.NoError(t, err)
+	assert.NotNil(t, mcs)
+
+	variable := PluginFactory(newPluginMCS)
+	factory, err = getPluginFactory(&variable)
+	assert.NoError(t, err)
+	assert.NotNil(t, factory)
+
+	// Symbols of other types
+	_, err = getPluginFactory(func() api.MessageCryptoService { return nil })
+	assert.Error(t, err)
+	_, err = getPluginFactory(&mockpolicies.PolicyManagerMgmt{})
+	assert.Error(t, err)
+}
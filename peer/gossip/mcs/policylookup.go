@@ -0,0 +1,55 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcs
+
+import (
+	"github.com/hyperledger/fabric/gossip/common"
+)
+
+// PolicyNotFoundHandler is notified when the policy manager of
+// a channel, or one of its policies, cannot be found.
+// policyName is empty if the policy manager of the channel is missing.
+// It is invoked synchronously, hence it must not block.
+type PolicyNotFoundHandler func(chainID common.ChainID, policyName string)
+
+// WithStrictPolicyLookup makes the verifications against channel
+// policies fail with a *PolicyNotFoundError when the policy manager
+// of the channel is not the one requested, or when the policy is not
+// defined, instead of evaluating the default policy returned in its place.
+func WithStrictPolicyLookup() Option {
+	return func(s *mspMessageCryptoService) {
+		s.strictPolicyLookup = true
+	}
+}
+
+// WithPolicyNotFoundHandler sets the handler notified when the policy
+// manager of a channel, or one of its policies, cannot be found, e.g.
+// so that the peer can refresh the configuration of the channel
+func WithPolicyNotFoundHandler(handler PolicyNotFoundHandler) Option {
+	return func(s *mspMessageCryptoService) {
+		s.policyNotFoundHandler = handler
+	}
+}
+
+// policyNotFound notifies the handler that policyName of channel chainID
+// could not be found, and returns the corresponding *PolicyNotFoundError
+func (s *mspMessageCryptoService) policyNotFound(chainID common.ChainID, policyName string) *PolicyNotFoundError {
+	if s.policyNotFoundHandler != nil {
+		s.policyNotFoundHandler(chainID, policyName)
+	}
+	return &PolicyNotFoundError{ChainID: chainID, Policy: policyName}
+}
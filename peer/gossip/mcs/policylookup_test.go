@@ -0,0 +1,103 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcs
+
+import (
+	"sync"
+	"testing"
+
+	mockpolicies "github.com/hyperledger/fabric/common/mocks/policies"
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/gossip/common"
+	"github.com/stretchr/testify/assert"
+)
+
+// missingPolicies records the notifications of a PolicyNotFoundHandler
+type missingPolicies struct {
+	lock     sync.Mutex
+	policies []string
+}
+
+func (m *missingPolicies) handle(chainID common.ChainID, policyName string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.policies = append(m.policies, string(chainID)+":"+policyName)
+}
+
+func (m *missingPolicies) get() []string {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.policies
+}
+
+func TestStrictPolicyLookup(t *testing.T) {
+	chainID := "strictpolicychannel"
+	setupMockChannel(chainID, "StrictPolicyOrg")
+	setupMockChannel("nopolicychannel", "StrictPolicyOrg")
+	pm := newMockPolicyManager()
+	pm.setPolicy(chainID, policies.ChannelApplicationReaders, &mockChannelPolicy{chainID: chainID})
+	pm.setPolicy("nopolicychannel", policies.ChannelApplicationAdmins, &mockChannelPolicy{chainID: "nopolicychannel"})
+	msg := []byte("Hello World!!!")
+	peerIdentity := newMockPeerIdentity("StrictPolicyOrg", "peer0")
+
+	missing := &missingPolicies{}
+	mcs := New(pm, WithStrictPolicyLookup(), WithPolicyNotFoundHandler(missing.handle))
+
+	// The policy is found
+	assert.NoError(t, mcs.VerifyByChannel(common.ChainID(chainID), peerIdentity, mockSign(msg), msg))
+	assert.Empty(t, missing.get())
+
+	// The reader policy is missing
+	err := mcs.VerifyByChannel(common.ChainID("nopolicychannel"), peerIdentity, mockSign(msg), msg)
+	assert.Equal(t, &PolicyNotFoundError{ChainID: common.ChainID("nopolicychannel"), Policy: policies.ChannelApplicationReaders}, err)
+
+	// The block validation policy is missing
+	err = mcs.VerifyBlock(common.ChainID(chainID), mockBlock(chainID, newMockPeerIdentity("StrictPolicyOrg", "orderer0")))
+	assert.IsType(t, &PolicyNotFoundError{}, err)
+
+	// The policy manager is missing
+	err = mcs.VerifyByChannel(common.ChainID("unknownchannel"), peerIdentity, mockSign(msg), msg)
+	assert.Equal(t, &PolicyNotFoundError{ChainID: common.ChainID("unknownchannel")}, err)
+
+	assert.Equal(t, []string{
+		"nopolicychannel:" + policies.ChannelApplicationReaders,
+		chainID + ":" + policies.BlockValidation,
+		"unknownchannel:",
+	}, missing.get())
+}
+
+func TestLenientPolicyLookup(t *testing.T) {
+	setupMockChannel("lenientpolicychannel", "LenientPolicyOrg")
+	msg := []byte("Hello World!!!")
+	peerIdentity := newMockPeerIdentity("LenientPolicyOrg", "peer0")
+
+	// The default policy is evaluated, but the handler is notified
+	missing := &missingPolicies{}
+	mcs := New(&mockpolicies.PolicyManagerMgmt{}, WithPolicyNotFoundHandler(missing.handle))
+	assert.NoError(t, mcs.VerifyByChannel(common.ChainID("lenientpolicychannel"), peerIdentity, mockSign(msg), msg))
+	assert.Equal(t, []string{"lenientpolicychannel:"}, missing.get())
+
+	// In strict mode, the default policy is not evaluated
+	mcs = New(&mockpolicies.PolicyManagerMgmt{}, WithStrictPolicyLookup())
+	assert.IsType(t, &PolicyNotFoundError{}, mcs.VerifyByChannel(common.ChainID("lenientpolicychannel"), peerIdentity, mockSign(msg), msg))
+
+	// Without a handler
+	pm := newMockPolicyManager()
+	pm.setPolicy("lenientpolicychannel", policies.ChannelApplicationAdmins, &mockChannelPolicy{chainID: "lenientpolicychannel"})
+	mcs = New(pm)
+	assert.Error(t, mcs.VerifyByChannel(common.ChainID("lenientpolicychannel"), peerIdentity, mockSign(msg), msg))
+}
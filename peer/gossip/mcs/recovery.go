@@ -0,0 +1,52 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcs
+
+import (
+	"runtime/debug"
+
+	"github.com/hyperledger/fabric/gossip/common"
+)
+
+// WithPolicyPanicRecovery sets whether a panic raised while evaluating
+// a channel policy is recovered and turned into a *PolicyPanickedError.
+// Recovery is enabled by default, so that a faulty policy implementation
+// cannot take down the goroutine verifying gossip messages and blocks.
+// Disabling it lets such panics propagate, which can help debugging.
+func WithPolicyPanicRecovery(enabled bool) Option {
+	return func(s *mspMessageCryptoService) {
+		s.propagatePolicyPanics = !enabled
+	}
+}
+
+// evaluatePolicy runs evaluate, the evaluation of policy policyName
+// of channel chainID, recovering from any panic it raises,
+// unless policy panic recovery has been disabled
+func (s *mspMessageCryptoService) evaluatePolicy(chainID common.ChainID, policyName string, evaluate func() error) (err error) {
+	if s.propagatePolicyPanics {
+		return evaluate()
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Errorf("Evaluation of policy [%s] of channel [%s] panicked: [%v]\n%s", policyName, string(chainID), r, debug.Stack())
+			err = &PolicyPanickedError{ChainID: chainID, Policy: policyName, Value: r}
+		}
+	}()
+
+	return evaluate()
+}
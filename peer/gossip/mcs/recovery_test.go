@@ -0,0 +1,67 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcs
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/gossip/common"
+	protoscommon "github.com/hyperledger/fabric/protos/common"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockPanicPolicy struct{}
+
+func (p *mockPanicPolicy) Evaluate(signatureSet []*protoscommon.SignedData) error {
+	panic("policy bug")
+}
+
+func TestPolicyPanicRecovery(t *testing.T) {
+	chainID := "panicchannel"
+	setupMockChannel(chainID, "PanicOrg")
+	pm := newMockPolicyManager()
+	pm.setPolicy(chainID, policies.ChannelApplicationReaders, &mockPanicPolicy{})
+	pm.setPolicy(chainID, policies.BlockValidation, &mockPanicPolicy{})
+	pm.setPolicy(chainID, "Custom", &mockPanicPolicy{})
+	mcs := New(pm).(*mspMessageCryptoService)
+
+	peerIdentity := newMockPeerIdentity("PanicOrg", "peer0")
+	msg := []byte("Hello World!!!")
+
+	err := mcs.VerifyByChannel(common.ChainID(chainID), peerIdentity, mockSign(msg), msg)
+	assert.IsType(t, &PolicyPanickedError{}, err)
+	assert.Equal(t, policies.ChannelApplicationReaders, err.(*PolicyPanickedError).Policy)
+	assert.Equal(t, "policy bug", err.(*PolicyPanickedError).Value)
+
+	err = mcs.Verify(peerIdentity, mockSign(msg), msg)
+	assert.IsType(t, &PolicyPanickedError{}, err)
+
+	err = mcs.VerifyByChannelAll(common.ChainID(chainID), peerIdentity, mockSign(msg), msg, []string{"Custom"})
+	assert.IsType(t, &PolicyEvaluationError{}, err)
+	assert.IsType(t, &PolicyPanickedError{}, err.(*PolicyEvaluationError).Errors["Custom"])
+
+	err = mcs.VerifyBlock(common.ChainID(chainID), mockBlock(chainID, newMockPeerIdentity("PanicOrg", "orderer0")))
+	assert.IsType(t, &PolicyPanickedError{}, err)
+	assert.Equal(t, policies.BlockValidation, err.(*PolicyPanickedError).Policy)
+
+	// Recovery disabled
+	mcs = New(pm, WithPolicyPanicRecovery(false)).(*mspMessageCryptoService)
+	assert.Panics(t, func() {
+		mcs.VerifyByChannel(common.ChainID(chainID), peerIdentity, mockSign(msg), msg)
+	})
+}
@@ -0,0 +1,86 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcs
+
+import (
+	"github.com/hyperledger/fabric/gossip/api"
+	"github.com/hyperledger/fabric/msp"
+)
+
+// OnlineRevocationChecker checks the revocation status of an
+// identity against an online source, such as a CRL distribution
+// point or an OCSP responder, as opposed to the CRLs that are
+// part of the MSP configuration.
+type OnlineRevocationChecker interface {
+	// CheckRevocation returns nil if identity is not revoked.
+	// It returns an error if identity is revoked or if its
+	// revocation status cannot be determined.
+	CheckRevocation(identity msp.Identity) error
+}
+
+// WithOnlineRevocationChecker sets the OnlineRevocationChecker
+// consulted by VerifyWithRevocationCheck.
+func WithOnlineRevocationChecker(checker OnlineRevocationChecker) Option {
+	return func(s *mspMessageCryptoService) {
+		s.revocationChecker = checker
+	}
+}
+
+// WithOnlineRevocationEnforcement makes the validation of identities,
+// and hence all the verifications, consult the OnlineRevocationChecker
+// set via WithOnlineRevocationChecker, so that identities revoked after
+// the configuration of their channel was last updated are rejected.
+// If an identity cache is configured, identities are checked again
+// only once their cache entry expires.
+func WithOnlineRevocationEnforcement() Option {
+	return func(s *mspMessageCryptoService) {
+		s.enforceOnlineRevocation = true
+	}
+}
+
+// checkOnlineRevocation checks identity against the OnlineRevocationChecker
+// if the validation of identities is required to
+func (s *mspMessageCryptoService) checkOnlineRevocation(identity msp.Identity) error {
+	if !s.enforceOnlineRevocation || s.revocationChecker == nil {
+		return nil
+	}
+	return s.revocationChecker.CheckRevocation(identity)
+}
+
+// VerifyWithRevocationCheck checks that signature is a valid signature of
+// message under a peer's verification key, as Verify does. Then, it checks
+// that the signer's identity has not been revoked using the configured
+// OnlineRevocationChecker. If no OnlineRevocationChecker is configured,
+// VerifyWithRevocationCheck is equivalent to Verify.
+func (s *mspMessageCryptoService) VerifyWithRevocationCheck(peerIdentity api.PeerIdentityType, signature, message []byte) error {
+	identity, err := s.verify(logger, peerIdentity, signature, message)
+	if err != nil {
+		return err
+	}
+
+	if s.revocationChecker == nil {
+		logger.Debugf("No online revocation checker configured, skipping revocation check of [% x]", []byte(peerIdentity))
+		return nil
+	}
+
+	if err := s.revocationChecker.CheckRevocation(identity); err != nil {
+		logger.Warningf("Online revocation check of peer identity [% x] failed: [%s]", []byte(peerIdentity), err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcs
+
+import (
+	"errors"
+	"testing"
+
+	mockpolicies "github.com/hyperledger/fabric/common/mocks/policies"
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/stretchr/testify/assert"
+)
+
+// revokedNames is an OnlineRevocationChecker revoking
+// the mock identities whose name is in the set
+type revokedNames map[string]bool
+
+func (r revokedNames) CheckRevocation(identity msp.Identity) error {
+	if r[identity.GetIdentifier().Id] {
+		return errors.New("The certificate has been revoked")
+	}
+	return nil
+}
+
+func TestVerifyWithRevocationCheck(t *testing.T) {
+	chainID := "revocationchannel"
+	setupMockChannel(chainID, "RevocationOrg")
+	pm := newMockPolicyManager()
+	pm.setPolicy(chainID, policies.ChannelApplicationReaders, &mockChannelPolicy{chainID: chainID})
+
+	good := newMockPeerIdentity("RevocationOrg", "peer0")
+	revoked := newMockPeerIdentity("RevocationOrg", "peer1")
+	msg := []byte("Hello World!!!")
+
+	// No checker configured
+	mcs := New(pm).(*mspMessageCryptoService)
+	assert.NoError(t, mcs.VerifyWithRevocationCheck(good, mockSign(msg), msg))
+	assert.NoError(t, mcs.VerifyWithRevocationCheck(revoked, mockSign(msg), msg))
+
+	// Checker configured
+	mcs = New(pm, WithOnlineRevocationChecker(revokedNames{"peer1": true})).(*mspMessageCryptoService)
+	assert.NoError(t, mcs.VerifyWithRevocationCheck(good, mockSign(msg), msg))
+	assert.Error(t, mcs.VerifyWithRevocationCheck(revoked, mockSign(msg), msg))
+	assert.Error(t, mcs.VerifyWithRevocationCheck(good, []byte("bad"), msg))
+	// Verify does not consult the checker
+	assert.NoError(t, mcs.Verify(revoked, mockSign(msg), msg))
+}
+
+func TestOnlineRevocationEnforcement(t *testing.T) {
+	setupMockChannel("enforcerevocationchannel", "EnforceRevocationOrg")
+	good := newMockPeerIdentity("EnforceRevocationOrg", "peer0")
+	revoked := newMockPeerIdentity("EnforceRevocationOrg", "peer1")
+	msg := []byte("Hello World!!!")
+
+	// Enforcement without a checker
+	mcs := New(&mockpolicies.PolicyManagerMgmt{}, WithOnlineRevocationEnforcement())
+	assert.NoError(t, mcs.ValidateIdentity(revoked))
+
+	mcs = New(&mockpolicies.PolicyManagerMgmt{},
+		WithOnlineRevocationChecker(revokedNames{"peer1": true}),
+		WithOnlineRevocationEnforcement())
+	assert.NoError(t, mcs.ValidateIdentity(good))
+	assert.Error(t, mcs.ValidateIdentity(revoked))
+	assert.NoError(t, mcs.Verify(good, mockSign(msg), msg))
+	assert.Error(t, mcs.Verify(revoked, mockSign(msg), msg))
+}
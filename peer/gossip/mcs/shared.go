@@ -0,0 +1,59 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcs
+
+import (
+	"github.com/hyperledger/fabric/gossip/api"
+	"github.com/hyperledger/fabric/gossip/common"
+	"github.com/op/go-logging"
+)
+
+// WithSharedChannelRequired makes Verify accept signatures only from
+// peers that are members of at least one of the channels this peer
+// has joined. Without this option, the signatures of the members of
+// this peer's organization are accepted regardless of their channels.
+func WithSharedChannelRequired() Option {
+	return func(s *mspMessageCryptoService) {
+		s.requireSharedChannel = true
+	}
+}
+
+// checkSharedChannel returns a *NoSharedChannelError if peerIdentity,
+// validated by the MSP of channel chainID (nil for the local MSP),
+// is not a member of any of the channels this peer has joined
+func (s *mspMessageCryptoService) checkSharedChannel(log *logging.Logger, chainID common.ChainID, peerIdentity api.PeerIdentityType) error {
+	if len(chainID) != 0 {
+		// Validated by the MSP of a joined channel
+		return nil
+	}
+
+	managers := getMSPManagers()
+	for _, channel := range managers.chainIDs {
+		identity, err := managers.managers[channel].DeserializeIdentity(peerIdentity)
+		if err != nil {
+			continue
+		}
+		if err := s.validate(identity); err != nil {
+			continue
+		}
+
+		log.Debugf("Peer identity [% x] shares channel [%s]", []byte(peerIdentity), channel)
+		return nil
+	}
+
+	return &NoSharedChannelError{PeerIdentity: peerIdentity}
+}
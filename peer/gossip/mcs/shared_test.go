@@ -0,0 +1,49 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcs
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/gossip/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSharedChannelRequired(t *testing.T) {
+	chainID := "sharedchannel"
+	setupMockChannel(chainID, "SharedOrg")
+	pm := newMockPolicyManager()
+	pm.setPolicy(chainID, policies.ChannelApplicationReaders, &mockChannelPolicy{chainID: chainID})
+	msg := []byte("Hello World!!!")
+
+	// Signers validated by a channel's MSP share that channel
+	for _, mcs := range []*mspMessageCryptoService{
+		New(pm).(*mspMessageCryptoService),
+		New(pm, WithSharedChannelRequired()).(*mspMessageCryptoService),
+	} {
+		assert.NoError(t, mcs.Verify(newMockPeerIdentity("SharedOrg", "peer0"), mockSign(msg), msg))
+		assert.Error(t, mcs.Verify(newMockPeerIdentity("UnknownOrg", "peer0"), mockSign(msg), msg))
+	}
+
+	// Signers validated by the local MSP must be members of a joined channel
+	mcs := New(pm, WithSharedChannelRequired()).(*mspMessageCryptoService)
+	assert.NoError(t, mcs.checkSharedChannel(logger, nil, newMockPeerIdentity("SharedOrg", "peer0")))
+	err := mcs.checkSharedChannel(logger, nil, newMockPeerIdentity("LocalOnlyOrg", "peer0"))
+	assert.IsType(t, &NoSharedChannelError{}, err)
+	assert.NoError(t, mcs.checkSharedChannel(logger, common.ChainID(chainID), newMockPeerIdentity("LocalOnlyOrg", "peer0")))
+}
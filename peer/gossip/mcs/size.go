@@ -0,0 +1,42 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcs
+
+import "github.com/hyperledger/fabric/gossip/api"
+
+// WithMaxIdentitySize makes the MessageCryptoService reject the
+// identities larger than maxBytes before hashing or deserializing
+// them, so that a malicious peer cannot make this peer process
+// arbitrarily large identities. Rejected identities fail with
+// an *IdentityTooLargeError, and GetPKIidOfCert returns nil for
+// them. A non-positive maxBytes disables the limit.
+func WithMaxIdentitySize(maxBytes int) Option {
+	return func(s *mspMessageCryptoService) {
+		s.maxIdentitySize = maxBytes
+	}
+}
+
+// checkIdentitySize returns an *IdentityTooLargeError
+// if peerIdentity exceeds the maximum identity size
+func (s *mspMessageCryptoService) checkIdentitySize(peerIdentity api.PeerIdentityType) error {
+	if s.maxIdentitySize <= 0 || len(peerIdentity) <= s.maxIdentitySize {
+		return nil
+	}
+
+	s.metrics.countOversizedIdentity()
+	return &IdentityTooLargeError{Size: len(peerIdentity), Max: s.maxIdentitySize}
+}
@@ -0,0 +1,54 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcs
+
+import (
+	"bytes"
+	"testing"
+
+	mockpolicies "github.com/hyperledger/fabric/common/mocks/policies"
+	"github.com/hyperledger/fabric/gossip/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaxIdentitySize(t *testing.T) {
+	setupMockChannel("identitysizechannel", "IdentitySizeOrg")
+	small := newMockPeerIdentity("IdentitySizeOrg", "peer0")
+	large := newMockPeerIdentity("IdentitySizeOrg", string(bytes.Repeat([]byte("x"), 1024)))
+	msg := []byte("msg")
+
+	// No limit by default
+	mcs := New(&mockpolicies.PolicyManagerMgmt{})
+	assert.NoError(t, mcs.ValidateIdentity(large))
+	assert.NotNil(t, mcs.GetPKIidOfCert(large))
+
+	provider := newMockMetricsProvider()
+	mcs = New(&mockpolicies.PolicyManagerMgmt{}, WithMaxIdentitySize(512), WithMetricsProvider(provider))
+	assert.NoError(t, mcs.ValidateIdentity(small))
+	assert.NotNil(t, mcs.GetPKIidOfCert(small))
+
+	err := mcs.ValidateIdentity(large)
+	assert.IsType(t, &IdentityTooLargeError{}, err)
+	assert.Equal(t, len(large), err.(*IdentityTooLargeError).Size)
+	assert.Equal(t, 512, err.(*IdentityTooLargeError).Max)
+	assert.Equal(t, ErrMalformedIdentity, IdentityValidationFailure(err))
+	assert.True(t, err.(api.BlacklistError).Blacklist())
+
+	assert.Nil(t, mcs.GetPKIidOfCert(large))
+	assert.Error(t, mcs.Verify(large, mockSign(msg), msg))
+	assert.Len(t, provider.get(OversizedIdentities), 3)
+}
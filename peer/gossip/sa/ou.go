@@ -0,0 +1,91 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sa
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/hyperledger/fabric/gossip/api"
+)
+
+// mspOUSecurityAdvisor implements the OUAwareSecurityAdvisor
+// interface using peer's MSPs.
+// Organizations are mapped as by mspSecurityAdvisor.
+type mspOUSecurityAdvisor struct {
+	mspSecurityAdvisor
+}
+
+// NewOUSecurityAdvisor creates a new instance of mspOUSecurityAdvisor
+// that implements OUAwareSecurityAdvisor
+func NewOUSecurityAdvisor() api.OUAwareSecurityAdvisor {
+	return &mspOUSecurityAdvisor{}
+}
+
+// OrgUnitsByPeerIdentity returns the organizational units
+// of a given peer identity, sorted.
+// If any error occurs, nil is returned.
+// This method does not validate peerIdentity.
+// This validation is supposed to be done appropriately during the execution flow.
+func (advisor *mspOUSecurityAdvisor) OrgUnitsByPeerIdentity(peerIdentity api.PeerIdentityType) []string {
+	// Validate arguments
+	if len(peerIdentity) == 0 {
+		logger.Error("Invalid Peer Identity. It must be different from nil.")
+
+		return nil
+	}
+
+	identity := deserializeIdentity(peerIdentity)
+	if identity == nil {
+		return nil
+	}
+
+	ous := identity.GetOrganizationalUnits()
+	if len(ous) == 0 {
+		return nil
+	}
+
+	sorted := make([]string, len(ous))
+	for i, ou := range ous {
+		sorted[i] = ou.OrganizationalUnitIdentifier
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
+// SameOrgUnit returns whether two peer identities belong to the
+// same organization and hold the same organizational units, and
+// can be used to restrict the dissemination of messages to the
+// peers of the organizational units of this peer
+func SameOrgUnit(advisor api.OUAwareSecurityAdvisor, peerIdentity, otherPeerIdentity api.PeerIdentityType) bool {
+	org := advisor.OrgByPeerIdentity(peerIdentity)
+	if len(org) == 0 || !bytes.Equal(org, advisor.OrgByPeerIdentity(otherPeerIdentity)) {
+		return false
+	}
+
+	ous := advisor.OrgUnitsByPeerIdentity(peerIdentity)
+	otherOUs := advisor.OrgUnitsByPeerIdentity(otherPeerIdentity)
+	if len(ous) != len(otherOUs) {
+		return false
+	}
+	for i := range ous {
+		if ous[i] != otherOUs[i] {
+			return false
+		}
+	}
+	return true
+}
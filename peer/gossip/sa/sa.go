@@ -18,6 +18,7 @@ package sa
 
 import (
 	"github.com/hyperledger/fabric/gossip/api"
+	"github.com/hyperledger/fabric/msp"
 	"github.com/hyperledger/fabric/msp/mgmt"
 	"github.com/op/go-logging"
 )
@@ -54,19 +55,24 @@ func (advisor *mspSecurityAdvisor) OrgByPeerIdentity(peerIdentity api.PeerIdenti
 		return nil
 	}
 
+	identity := deserializeIdentity(peerIdentity)
+	if identity == nil {
+		return nil
+	}
+
+	return []byte(identity.GetMSPIdentifier())
+}
+
+// deserializeIdentity returns the identity peerIdentity is the
+// serialization of, or nil if no MSP is able to deserialize it
+func deserializeIdentity(peerIdentity api.PeerIdentityType) msp.Identity {
 	// Notice that peerIdentity is assumed to be the serialization of an identity.
 	// So, first step is the identity deserialization
 
-	// TODO: This method should return a structure consisting of two fields:
-	// one of the MSPidentifier of the MSP the identity belongs to,
-	// and then a list of organization units this identity is in possession of.
-	// For gossip use, it is the first part that we would need for now,
-	// namely the identity's MSP identifier be returned (Identity.GetMSPIdentifier())
-
 	// First check against the local MSP.
 	identity, err := mgmt.GetLocalMSP().DeserializeIdentity([]byte(peerIdentity))
 	if err == nil {
-		return []byte(identity.GetMSPIdentifier())
+		return identity
 	}
 
 	// Check against managers
@@ -78,7 +84,7 @@ func (advisor *mspSecurityAdvisor) OrgByPeerIdentity(peerIdentity api.PeerIdenti
 			continue
 		}
 
-		return []byte(identity.GetMSPIdentifier())
+		return identity
 	}
 
 	logger.Warning("Peer Identity [% x] cannot be desirialized. No MSP found able to do that.", peerIdentity)
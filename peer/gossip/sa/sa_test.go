@@ -21,6 +21,7 @@ import (
 
 	"fmt"
 	"os"
+	"sort"
 
 	"github.com/hyperledger/fabric/gossip/api"
 	"github.com/hyperledger/fabric/msp/mgmt"
@@ -49,3 +50,70 @@ func TestMspSecurityAdvisor_OrgByPeerIdentity(t *testing.T) {
 	orgIdentity := advisor.OrgByPeerIdentity(api.PeerIdentityType(identityRaw))
 	assert.NotNil(t, orgIdentity, "Organization for identity must be different from nil")
 }
+
+func TestMspOUSecurityAdvisor(t *testing.T) {
+	id, err := mgmt.GetLocalMSP().GetDefaultSigningIdentity()
+	assert.NoError(t, err, "Failed getting local default signing identity")
+	identityRaw, err := id.Serialize()
+	assert.NoError(t, err, "Failed serializing local default signing identity")
+	peerIdentity := api.PeerIdentityType(identityRaw)
+
+	advisor := NewOUSecurityAdvisor()
+	assert.Equal(t, NewSecurityAdvisor().OrgByPeerIdentity(peerIdentity), advisor.OrgByPeerIdentity(peerIdentity))
+
+	ous := make([]string, len(id.GetOrganizationalUnits()))
+	for i, ou := range id.GetOrganizationalUnits() {
+		ous[i] = ou.OrganizationalUnitIdentifier
+	}
+	sort.Strings(ous)
+	if len(ous) == 0 {
+		ous = nil
+	}
+	assert.Equal(t, ous, advisor.OrgUnitsByPeerIdentity(peerIdentity))
+	assert.True(t, SameOrgUnit(advisor, peerIdentity, peerIdentity))
+
+	// Invalid identities
+	assert.Nil(t, advisor.OrgUnitsByPeerIdentity(nil))
+	assert.Nil(t, advisor.OrgUnitsByPeerIdentity(api.PeerIdentityType("invalid")))
+	assert.False(t, SameOrgUnit(advisor, peerIdentity, api.PeerIdentityType("invalid")))
+	assert.False(t, SameOrgUnit(advisor, api.PeerIdentityType("invalid"), api.PeerIdentityType("invalid")))
+}
+
+type mockOUAdvisor struct {
+	orgs map[string]string
+	ous  map[string][]string
+}
+
+func (a *mockOUAdvisor) OrgByPeerIdentity(peerIdentity api.PeerIdentityType) api.OrgIdentityType {
+	if org, ok := a.orgs[string(peerIdentity)]; ok {
+		return api.OrgIdentityType(org)
+	}
+	return nil
+}
+
+func (a *mockOUAdvisor) OrgUnitsByPeerIdentity(peerIdentity api.PeerIdentityType) []string {
+	return a.ous[string(peerIdentity)]
+}
+
+func TestSameOrgUnit(t *testing.T) {
+	advisor := &mockOUAdvisor{
+		orgs: map[string]string{"p1": "Org1", "p2": "Org1", "p3": "Org1", "p4": "Org2", "p5": "Org1"},
+		ous: map[string][]string{
+			"p1": {"east", "sales"},
+			"p2": {"east", "sales"},
+			"p3": {"east"},
+			"p4": {"east", "sales"},
+			"p5": {"east", "support"},
+		},
+	}
+
+	assert.True(t, SameOrgUnit(advisor, api.PeerIdentityType("p1"), api.PeerIdentityType("p2")))
+	// Fewer organizational units
+	assert.False(t, SameOrgUnit(advisor, api.PeerIdentityType("p1"), api.PeerIdentityType("p3")))
+	// Different organization
+	assert.False(t, SameOrgUnit(advisor, api.PeerIdentityType("p1"), api.PeerIdentityType("p4")))
+	// Different organizational units
+	assert.False(t, SameOrgUnit(advisor, api.PeerIdentityType("p1"), api.PeerIdentityType("p5")))
+	// Unknown identity
+	assert.False(t, SameOrgUnit(advisor, api.PeerIdentityType("p1"), api.PeerIdentityType("p6")))
+}
@@ -0,0 +1,102 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/hyperledger/fabric/peer/common"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+)
+
+var (
+	leadershipChainID  string
+	leadershipOverride string
+)
+
+func leadershipCmd() *cobra.Command {
+	flags := nodeLeadershipCmd.Flags()
+	flags.StringVarP(&leadershipChainID, "chain", "c", common.UndefinedParamValue, "The chain whose leader election to show or override.")
+	flags.StringVar(&leadershipOverride, "override", "", "Override the leader election: relinquish, acquire, or none to end the override in effect.")
+
+	return nodeLeadershipCmd
+}
+
+var nodeLeadershipCmd = &cobra.Command{
+	Use:   "leadership",
+	Short: "Shows or overrides the leader election of a chain.",
+	Long: `Shows the state of the leader election of a chain on the running node. With --override, the node relinquishes the leadership of the chain and abstains from the election, e.g. during its maintenance, or acquires the leadership regardless of the election, until the override is ended with --override none.
+To move the leadership to another node, acquire it on that node and relinquish it on the current leader.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return leadership()
+	},
+}
+
+func leadership() error {
+	if leadershipChainID == common.UndefinedParamValue {
+		return fmt.Errorf("Must supply the chain ID")
+	}
+
+	adminClient, err := common.GetAdminClient()
+	if err != nil {
+		logger.Warningf("%s", err)
+		return err
+	}
+
+	var state *pb.LeadershipState
+	if leadershipOverride == "" {
+		state, err = adminClient.GetLeadershipState(context.Background(), &pb.LeadershipRequest{Channel: leadershipChainID})
+	} else {
+		var override pb.LeadershipState_Override
+		if override, err = parseLeadershipOverride(leadershipOverride); err != nil {
+			return err
+		}
+		state, err = adminClient.OverrideLeadership(context.Background(), &pb.LeadershipOverrideRequest{Channel: leadershipChainID, Override: override})
+	}
+	if err != nil {
+		logger.Warningf("Error getting the leader election of chain %s: %s", leadershipChainID, err)
+		return fmt.Errorf("Error getting the leader election of chain %s: %s", leadershipChainID, err)
+	}
+
+	fmt.Print(formatLeadershipState(state))
+	return nil
+}
+
+// parseLeadershipOverride returns the override named by the --override flag
+func parseLeadershipOverride(name string) (pb.LeadershipState_Override, error) {
+	switch name {
+	case "none":
+		return pb.LeadershipState_NONE, nil
+	case "relinquish":
+		return pb.LeadershipState_RELINQUISHED, nil
+	case "acquire":
+		return pb.LeadershipState_ACQUIRED, nil
+	}
+	return pb.LeadershipState_NONE, fmt.Errorf("Invalid override %s, must be one of relinquish, acquire and none", name)
+}
+
+func formatLeadershipState(state *pb.LeadershipState) string {
+	leader := "unknown"
+	if len(state.Leader) != 0 {
+		leader = hex.EncodeToString(state.Leader)
+	}
+	return fmt.Sprintf("Chain: %s\nIs leader: %t\nLeader: %s\nTerm: %d\nOverride: %s\n",
+		state.Channel, state.IsLeader, leader, state.Term, state.Override)
+}
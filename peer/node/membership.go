@@ -0,0 +1,122 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/hyperledger/fabric/peer/common"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+)
+
+func membershipCmd() *cobra.Command {
+	return nodeMembershipCmd
+}
+
+var nodeMembershipCmd = &cobra.Command{
+	Use:   "membership",
+	Short: "Dumps the gossip membership view of the node as JSON.",
+	Long:  `Dumps the alive and dead peers in the gossip membership view of the running node as JSON, with their endpoints, PKI-IDs, metadata and the times they were last seen, e.g. to diagnose partial network partitions by comparing the views of several nodes.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return membership()
+	},
+}
+
+// gossipMember is the JSON representation of a member of the view
+type gossipMember struct {
+	PKIID            string     `json:"pkiID"`
+	Endpoint         string     `json:"endpoint"`
+	InternalEndpoint string     `json:"internalEndpoint,omitempty"`
+	Metadata         []byte     `json:"metadata,omitempty"`
+	Alive            bool       `json:"alive"`
+	LastSeen         *time.Time `json:"lastSeen,omitempty"`
+}
+
+// gossipMembers orders alive members first, each by endpoint
+type gossipMembers []gossipMember
+
+func (m gossipMembers) Len() int      { return len(m) }
+func (m gossipMembers) Swap(i, j int) { m[i], m[j] = m[j], m[i] }
+func (m gossipMembers) Less(i, j int) bool {
+	if m[i].Alive != m[j].Alive {
+		return m[i].Alive
+	}
+	return m[i].Endpoint < m[j].Endpoint
+}
+
+func membership() error {
+	adminClient, err := common.GetAdminClient()
+	if err != nil {
+		logger.Warningf("%s", err)
+		return err
+	}
+
+	response, err := adminClient.GetGossipMembership(context.Background(), &empty.Empty{})
+	if err != nil {
+		logger.Warningf("Error getting the gossip membership: %s", err)
+		return fmt.Errorf("Error getting the gossip membership: %s", err)
+	}
+
+	out, err := json.MarshalIndent(membershipToJSON(response), "", "  ")
+	if err != nil {
+		return fmt.Errorf("Error marshalling the gossip membership: %s", err)
+	}
+	fmt.Println(string(out))
+	return nil
+}
+
+// membershipToJSON returns the JSON representation of the view
+func membershipToJSON(response *pb.GossipMembership) interface{} {
+	members := []gossipMember{}
+	for _, member := range response.Members {
+		members = append(members, gossipMemberToJSON(member))
+	}
+	sort.Sort(gossipMembers(members))
+
+	return struct {
+		Self    gossipMember   `json:"self"`
+		Members []gossipMember `json:"members"`
+	}{
+		Self:    gossipMemberToJSON(response.Self),
+		Members: members,
+	}
+}
+
+func gossipMemberToJSON(member *pb.GossipMember) gossipMember {
+	if member == nil {
+		return gossipMember{}
+	}
+	m := gossipMember{
+		PKIID:            hex.EncodeToString(member.PkiId),
+		Endpoint:         member.Endpoint,
+		InternalEndpoint: member.InternalEndpoint,
+		Metadata:         member.Metadata,
+		Alive:            member.Alive,
+	}
+	if ts := member.LastSeen; ts != nil {
+		lastSeen := time.Unix(ts.Seconds, int64(ts.Nanos)).UTC()
+		m.LastSeen = &lastSeen
+	}
+	return m
+}
@@ -35,6 +35,9 @@ func Cmd() *cobra.Command {
 	nodeCmd.AddCommand(startCmd())
 	nodeCmd.AddCommand(statusCmd())
 	nodeCmd.AddCommand(stopCmd())
+	nodeCmd.AddCommand(reloadMSPCmd())
+	nodeCmd.AddCommand(leadershipCmd())
+	nodeCmd.AddCommand(membershipCmd())
 
 	return nodeCmd
 }
@@ -0,0 +1,81 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/peer/common"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+)
+
+func reloadMSPCmd() *cobra.Command {
+	return nodeReloadMSPCmd
+}
+
+var nodeReloadMSPCmd = &cobra.Command{
+	Use:   "reloadmsp",
+	Short: "Reloads the local MSP of the node.",
+	Long:  `Reloads the local MSP of the running node from its directory, e.g. after admin certificates were rotated or CRLs added, without restarting the node.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		reloadMSP()
+	},
+}
+
+func reloadMSP() error {
+	adminClient, err := common.GetAdminClient()
+	if err != nil {
+		logger.Warningf("%s", err)
+		return err
+	}
+
+	response, err := adminClient.ReloadLocalMSP(context.Background(), &empty.Empty{})
+	if err != nil {
+		logger.Warningf("Error reloading the local MSP: %s", err)
+		return fmt.Errorf("Error reloading the local MSP: %s", err)
+	}
+
+	fmt.Printf("Reloaded local MSP [%s]\n", response.MspId)
+	if description := describeSigningIdentity(response.SigningIdentity); description != "" {
+		fmt.Printf("Signing identity: %s\n", description)
+	}
+	return nil
+}
+
+// describeSigningIdentity returns the subject and expiry of the
+// certificate of a serialized x.509 signing identity, if any
+func describeSigningIdentity(raw []byte) string {
+	sID := &msp.SerializedIdentity{}
+	if err := proto.Unmarshal(raw, sID); err != nil {
+		return ""
+	}
+	block, _ := pem.Decode(sID.IdBytes)
+	if block == nil {
+		return ""
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%s, expires at %s", cert.Subject.CommonName, cert.NotAfter)
+}
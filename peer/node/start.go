@@ -17,7 +17,10 @@ limitations under the License.
 package node
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
+	"io/ioutil"
 	"net"
 	"net/http"
 	"os"
@@ -27,6 +30,7 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/hyperledger/fabric/bccsp/factory"
 	"github.com/hyperledger/fabric/common/configtx"
 	"github.com/hyperledger/fabric/common/configtx/test"
 	"github.com/hyperledger/fabric/common/configvalues/channel/application"
@@ -42,6 +46,7 @@ import (
 	"github.com/hyperledger/fabric/core/peer"
 	"github.com/hyperledger/fabric/core/scc"
 	"github.com/hyperledger/fabric/events/producer"
+	"github.com/hyperledger/fabric/gossip/api"
 	"github.com/hyperledger/fabric/gossip/service"
 	"github.com/hyperledger/fabric/msp/mgmt"
 	"github.com/hyperledger/fabric/peer/common"
@@ -99,6 +104,26 @@ func serve(args []string) error {
 		return err
 	}
 
+	// Set before any channel is set up, so that
+	// they apply to the MSPs of all channels
+	mgmt.SetClockSkew(viper.GetDuration("peer.mspClockSkew"))
+	if viper.GetBool("peer.mspAudit.enabled") {
+		mgmt.EnableAuditLog(viper.GetInt("peer.mspAudit.size"))
+	}
+	var policyPlugins []struct {
+		Type int32
+		Path string
+	}
+	if err := viper.UnmarshalKey("peer.policyPlugins", &policyPlugins); err != nil {
+		return fmt.Errorf("Invalid peer.policyPlugins: [%s]", err)
+	}
+	for _, policyPlugin := range policyPlugins {
+		logger.Infof("Loading policy provider plugin [%s] for policy type [%d]", policyPlugin.Path, policyPlugin.Type)
+		if err := policies.LoadPlugin(policyPlugin.Type, policyPlugin.Path); err != nil {
+			return err
+		}
+	}
+
 	peerEndpoint, err := peer.GetPeerEndpoint()
 	if err != nil {
 		err = fmt.Errorf("Failed to get Peer Endpoint: %s", err)
@@ -154,7 +179,21 @@ func serve(args []string) error {
 		panic(fmt.Sprintf("Failed serializing self identity: %v", err))
 	}
 
-	messageCryptoService := mcs.New(peer.GetPolicyManagerMgmt())
+	var messageCryptoService api.MessageCryptoService
+	if mcsPlugin := viper.GetString("peer.gossip.mcsPlugin"); mcsPlugin != "" {
+		logger.Infof("Loading MessageCryptoService plugin [%s]", mcsPlugin)
+		if messageCryptoService, err = mcs.LoadPlugin(mcsPlugin, peer.GetPolicyManagerMgmt()); err != nil {
+			return err
+		}
+	} else {
+		mcsOpts, err := messageCryptoServiceOptions()
+		if err != nil {
+			return err
+		}
+		mspMessageCryptoService := mcs.New(peer.GetPolicyManagerMgmt(), mcsOpts...)
+		defer mspMessageCryptoService.Stop()
+		messageCryptoService = mspMessageCryptoService
+	}
 	service.InitGossipService(serializedIdentity, peerEndpoint.Address, grpcServer.Server(), messageCryptoService, bootstrap...)
 	defer service.GetGossipService().Stop()
 
@@ -250,10 +289,56 @@ func serve(args []string) error {
 	common.SetLogLevelFromViper("error")
 	common.SetLogLevelFromViper("msp")
 
+	// Renew the certificate of the local MSP before it expires
+	if viper.GetBool("peer.mspRenewal.enabled") {
+		renewer, err := newCertRenewer()
+		if err != nil {
+			return fmt.Errorf("Failed initializing the renewal of the local MSP certificate: %s", err)
+		}
+		renewer.Start()
+		defer renewer.Stop()
+	}
+
 	// Block until grpc server exits
 	return <-serve
 }
 
+// newCertRenewer returns the renewer of the certificate
+// of the local MSP configured by peer.mspRenewal
+func newCertRenewer() (*mgmt.CertRenewer, error) {
+	var bccspConfig *factory.FactoryOpts
+	if err := viper.UnmarshalKey("peer.BCCSP", &bccspConfig); err != nil {
+		return nil, fmt.Errorf("Could not parse YAML config [%s]", err)
+	}
+
+	var tlsConfig *tls.Config
+	if rootCertFile := viper.GetString("peer.mspRenewal.rootCertFile"); rootCertFile != "" {
+		rootCerts, err := ioutil.ReadFile(rootCertFile)
+		if err != nil {
+			return nil, fmt.Errorf("Failed reading root certificates [%s]", err)
+		}
+		tlsConfig = &tls.Config{RootCAs: x509.NewCertPool()}
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(rootCerts) {
+			return nil, fmt.Errorf("No root certificate found in %s", rootCertFile)
+		}
+	}
+
+	enroller, err := mgmt.NewFabricCAEnroller(viper.GetString("peer.mspRenewal.url"),
+		viper.GetString("peer.mspRenewal.caName"), tlsConfig, viper.GetDuration("peer.mspRenewal.timeout"))
+	if err != nil {
+		return nil, err
+	}
+
+	return mgmt.NewCertRenewer(mgmt.RenewalConfig{
+		Dir:           viper.GetString("peer.mspConfigPath"),
+		BCCSPConfig:   bccspConfig,
+		MSPID:         viper.GetString("peer.localMspId"),
+		Window:        viper.GetDuration("peer.mspRenewal.window"),
+		CheckInterval: viper.GetDuration("peer.mspRenewal.interval"),
+		Enroller:      enroller,
+	})
+}
+
 //NOTE - when we implment JOIN we will no longer pass the chainID as param
 //The chaincode support will come up without registering system chaincodes
 //which will be registered only during join phase.
@@ -334,3 +419,42 @@ func writePid(fileName string, pid int) error {
 	}
 	return nil
 }
+
+// messageCryptoServiceOptions returns the options of the MessageCryptoService
+// of gossip, as configured under peer.gossip
+func messageCryptoServiceOptions() ([]mcs.Option, error) {
+	pkiIDHashOpts, err := mcs.GetPKIidHashOpts(viper.GetString("peer.gossip.pkiidHash"))
+	if err != nil {
+		return nil, fmt.Errorf("Invalid peer.gossip.pkiidHash: [%s]", err)
+	}
+
+	opts := []mcs.Option{
+		mcs.WithPKIidHash(pkiIDHashOpts),
+		mcs.WithMaxIdentitySize(viper.GetInt("peer.gossip.maxIdentitySize")),
+		mcs.WithMACSessions(viper.GetDuration("peer.gossip.macSessionLifetime")),
+		mcs.WithClockSkew(viper.GetDuration("peer.gossip.clockSkew")),
+		mcs.WithNegativeMembershipCache(viper.GetDuration("peer.gossip.negativeMembershipCacheTTL")),
+		mcs.WithMaxBlockSignatures(viper.GetInt("peer.gossip.maxBlockSignatures")),
+		mcs.WithMinBlockSignatures(viper.GetInt("peer.gossip.minBlockSignatures")),
+		mcs.WithBlockVerificationConcurrency(viper.GetInt("peer.gossip.blockVerificationConcurrency")),
+	}
+	if size := viper.GetInt("peer.gossip.identityCacheSize"); size > 0 {
+		opts = append(opts, mcs.WithIdentityCache(mcs.NewLRUIdentityCache(size), viper.GetDuration("peer.gossip.identityCacheTTL")))
+	}
+	if viper.IsSet("peer.gossip.blockDataHashCheck") {
+		opts = append(opts, mcs.WithBlockDataHashCheck(viper.GetBool("peer.gossip.blockDataHashCheck")))
+	}
+	if viper.IsSet("peer.gossip.policyPanicRecovery") {
+		opts = append(opts, mcs.WithPolicyPanicRecovery(viper.GetBool("peer.gossip.policyPanicRecovery")))
+	}
+	if viper.GetBool("peer.gossip.sharedChannelRequired") {
+		opts = append(opts, mcs.WithSharedChannelRequired())
+	}
+	if viper.GetBool("peer.gossip.strictPolicyLookup") {
+		opts = append(opts, mcs.WithStrictPolicyLookup())
+	}
+	if viper.GetBool("peer.gossip.crossChannelVerification") {
+		opts = append(opts, mcs.WithCrossChannelVerification())
+	}
+	return opts, nil
+}
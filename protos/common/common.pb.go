@@ -44,10 +44,14 @@ It has these top-level messages:
 	MSPPrincipal
 	OrganizationUnit
 	MSPRole
+	MSPAttribute
+	MSPIdentityAnonymity
 	Policy
 	SignaturePolicyEnvelope
 	SignaturePolicy
 	ImplicitMetaPolicy
+	CompositePolicy
+	BLSAggregatePolicy
 */
 package common
 
@@ -24,17 +24,25 @@ const (
 	// E.g., this can well be represented by an MSP's
 	// Organization unit
 	MSPPrincipal_IDENTITY MSPPrincipal_Classification = 2
+	// identity
+	MSPPrincipal_ATTRIBUTE MSPPrincipal_Classification = 3
+	// an attribute or an x.509 extension
+	MSPPrincipal_ANONYMITY MSPPrincipal_Classification = 4
 )
 
 var MSPPrincipal_Classification_name = map[int32]string{
 	0: "ROLE",
 	1: "ORGANIZATION_UNIT",
 	2: "IDENTITY",
+	3: "ATTRIBUTE",
+	4: "ANONYMITY",
 }
 var MSPPrincipal_Classification_value = map[string]int32{
 	"ROLE":              0,
 	"ORGANIZATION_UNIT": 1,
 	"IDENTITY":          2,
+	"ATTRIBUTE":         3,
+	"ANONYMITY":         4,
 }
 
 func (x MSPPrincipal_Classification) String() string {
@@ -47,17 +55,26 @@ func (MSPPrincipal_Classification) EnumDescriptor() ([]byte, []int) {
 type MSPRole_MSPRoleType int32
 
 const (
-	MSPRole_MEMBER MSPRole_MSPRoleType = 0
-	MSPRole_ADMIN  MSPRole_MSPRoleType = 1
+	MSPRole_MEMBER  MSPRole_MSPRoleType = 0
+	MSPRole_ADMIN   MSPRole_MSPRoleType = 1
+	MSPRole_CLIENT  MSPRole_MSPRoleType = 2
+	MSPRole_PEER    MSPRole_MSPRoleType = 3
+	MSPRole_ORDERER MSPRole_MSPRoleType = 4
 )
 
 var MSPRole_MSPRoleType_name = map[int32]string{
 	0: "MEMBER",
 	1: "ADMIN",
+	2: "CLIENT",
+	3: "PEER",
+	4: "ORDERER",
 }
 var MSPRole_MSPRoleType_value = map[string]int32{
-	"MEMBER": 0,
-	"ADMIN":  1,
+	"MEMBER":  0,
+	"ADMIN":   1,
+	"CLIENT":  2,
+	"PEER":    3,
+	"ORDERER": 4,
 }
 
 func (x MSPRole_MSPRoleType) String() string {
@@ -65,6 +82,30 @@ func (x MSPRole_MSPRoleType) String() string {
 }
 func (MSPRole_MSPRoleType) EnumDescriptor() ([]byte, []int) { return fileDescriptor4, []int{2, 0} }
 
+type MSPIdentityAnonymity_MSPIdentityAnonymityType int32
+
+const (
+	MSPIdentityAnonymity_NOMINAL MSPIdentityAnonymity_MSPIdentityAnonymityType = 0
+	// e.g., x.509 certificates
+	MSPIdentityAnonymity_ANONYMOUS MSPIdentityAnonymity_MSPIdentityAnonymityType = 1
+)
+
+var MSPIdentityAnonymity_MSPIdentityAnonymityType_name = map[int32]string{
+	0: "NOMINAL",
+	1: "ANONYMOUS",
+}
+var MSPIdentityAnonymity_MSPIdentityAnonymityType_value = map[string]int32{
+	"NOMINAL":   0,
+	"ANONYMOUS": 1,
+}
+
+func (x MSPIdentityAnonymity_MSPIdentityAnonymityType) String() string {
+	return proto.EnumName(MSPIdentityAnonymity_MSPIdentityAnonymityType_name, int32(x))
+}
+func (MSPIdentityAnonymity_MSPIdentityAnonymityType) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor4, []int{4, 0}
+}
+
 // MSPPrincipal aims to represent an MSP-centric set of identities.
 // In particular, this structure allows for definition of
 //  - a group of identities that are member of the same MSP
@@ -127,7 +168,8 @@ func (*OrganizationUnit) Descriptor() ([]byte, []int) { return fileDescriptor4,
 
 // MSPRole governs the organization of the Principal
 // field of an MSPPrincipal when it aims to define one of the
-// two dedicated roles within an MSP: Admin and Members.
+// dedicated roles within an MSP: Admin and Members, or, with
+// NodeOUs enabled, Client, Peer and Orderer.
 type MSPRole struct {
 	// MSPIdentifier represents the identifier of the MSP this principal
 	// refers to
@@ -142,40 +184,93 @@ func (m *MSPRole) String() string            { return proto.CompactTextString(m)
 func (*MSPRole) ProtoMessage()               {}
 func (*MSPRole) Descriptor() ([]byte, []int) { return fileDescriptor4, []int{2} }
 
+// MSPAttribute governs the organization of the Principal
+// field of an MSPPrincipal when it aims to define the identities
+// of an MSP holding an attribute: either an attribute issued by
+// Fabric CA or an arbitrary x.509 extension.
+type MSPAttribute struct {
+	// MSPIdentifier represents the identifier of the MSP this principal
+	// refers to
+	MspIdentifier string `protobuf:"bytes,1,opt,name=msp_identifier,json=mspIdentifier" json:"msp_identifier,omitempty"`
+	// Name is the name of the Fabric CA attribute the identities
+	// must hold; it is ignored if ExtensionOid is set
+	Name string `protobuf:"bytes,2,opt,name=name" json:"name,omitempty"`
+	// Value is the value the Fabric CA attribute must have;
+	// any value is accepted if it is empty
+	Value string `protobuf:"bytes,3,opt,name=value" json:"value,omitempty"`
+	// ExtensionOid is the object identifier, in dotted form,
+	// of the x.509 extension the identities must carry
+	ExtensionOid string `protobuf:"bytes,4,opt,name=extension_oid,json=extensionOid" json:"extension_oid,omitempty"`
+	// ExtensionValue is the value the x.509 extension must
+	// have; any value is accepted if it is empty
+	ExtensionValue []byte `protobuf:"bytes,5,opt,name=extension_value,json=extensionValue,proto3" json:"extension_value,omitempty"`
+}
+
+func (m *MSPAttribute) Reset()                    { *m = MSPAttribute{} }
+func (m *MSPAttribute) String() string            { return proto.CompactTextString(m) }
+func (*MSPAttribute) ProtoMessage()               {}
+func (*MSPAttribute) Descriptor() ([]byte, []int) { return fileDescriptor4, []int{3} }
+
+// MSPIdentityAnonymity governs the organization of the Principal
+// field of an MSPPrincipal when it aims to define the identities
+// of an MSP that are either anonymous or nominal.
+type MSPIdentityAnonymity struct {
+	AnonymityType MSPIdentityAnonymity_MSPIdentityAnonymityType `protobuf:"varint,1,opt,name=anonymity_type,json=anonymityType,enum=common.MSPIdentityAnonymity_MSPIdentityAnonymityType" json:"anonymity_type,omitempty"`
+}
+
+func (m *MSPIdentityAnonymity) Reset()                    { *m = MSPIdentityAnonymity{} }
+func (m *MSPIdentityAnonymity) String() string            { return proto.CompactTextString(m) }
+func (*MSPIdentityAnonymity) ProtoMessage()               {}
+func (*MSPIdentityAnonymity) Descriptor() ([]byte, []int) { return fileDescriptor4, []int{4} }
+
 func init() {
 	proto.RegisterType((*MSPPrincipal)(nil), "common.MSPPrincipal")
 	proto.RegisterType((*OrganizationUnit)(nil), "common.OrganizationUnit")
 	proto.RegisterType((*MSPRole)(nil), "common.MSPRole")
+	proto.RegisterType((*MSPAttribute)(nil), "common.MSPAttribute")
+	proto.RegisterType((*MSPIdentityAnonymity)(nil), "common.MSPIdentityAnonymity")
 	proto.RegisterEnum("common.MSPPrincipal_Classification", MSPPrincipal_Classification_name, MSPPrincipal_Classification_value)
 	proto.RegisterEnum("common.MSPRole_MSPRoleType", MSPRole_MSPRoleType_name, MSPRole_MSPRoleType_value)
+	proto.RegisterEnum("common.MSPIdentityAnonymity_MSPIdentityAnonymityType", MSPIdentityAnonymity_MSPIdentityAnonymityType_name, MSPIdentityAnonymity_MSPIdentityAnonymityType_value)
 }
 
 func init() { proto.RegisterFile("common/msp_principal.proto", fileDescriptor4) }
 
 var fileDescriptor4 = []byte{
-	// 372 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x8c, 0x92, 0xdf, 0x6a, 0xe2, 0x40,
-	0x14, 0xc6, 0x1d, 0xd7, 0x75, 0xf5, 0xac, 0x1b, 0xb2, 0xc3, 0xca, 0xca, 0xae, 0x2c, 0x92, 0xdd,
-	0x05, 0xa1, 0x34, 0x01, 0x7d, 0x80, 0xa2, 0x35, 0x94, 0x40, 0x93, 0xc8, 0x18, 0x2f, 0xea, 0x45,
-	0x43, 0x8c, 0xa3, 0x0e, 0xe4, 0x1f, 0x93, 0x78, 0x61, 0x5f, 0xa0, 0x2f, 0xd4, 0xd7, 0xe8, 0x3b,
-	0x95, 0x4c, 0x30, 0xc6, 0x5e, 0xf5, 0x2a, 0x9c, 0xef, 0xfb, 0x7e, 0xe7, 0x4f, 0x12, 0xf8, 0xe5,
-	0xc7, 0x61, 0x18, 0x47, 0x5a, 0x98, 0x26, 0x6e, 0xc2, 0x59, 0xe4, 0xb3, 0xc4, 0x0b, 0xd4, 0x84,
-	0xc7, 0x59, 0x8c, 0x9b, 0x85, 0xa7, 0xbc, 0x22, 0xe8, 0x98, 0x8b, 0xf9, 0xfc, 0x64, 0xe3, 0x47,
-	0xe8, 0x95, 0x59, 0xd7, 0x0f, 0xbc, 0x34, 0x65, 0x5b, 0xe6, 0x7b, 0x19, 0x8b, 0xa3, 0x1e, 0x1a,
-	0xa0, 0xa1, 0x34, 0xfa, 0xab, 0x16, 0xac, 0x5a, 0xe5, 0xd4, 0xdb, 0x8b, 0x28, 0xf9, 0x59, 0x36,
-	0xb9, 0x34, 0x70, 0x1f, 0xda, 0xa5, 0xd5, 0xab, 0x0f, 0xd0, 0xb0, 0x43, 0xce, 0x82, 0x72, 0x03,
-	0xd2, 0xbb, 0x7c, 0x0b, 0x1a, 0xc4, 0xbe, 0xd7, 0xe5, 0x1a, 0xee, 0xc2, 0x77, 0x9b, 0xdc, 0x4d,
-	0x2c, 0x63, 0x35, 0x71, 0x0c, 0xdb, 0x72, 0x97, 0x96, 0xe1, 0xc8, 0x08, 0x77, 0xa0, 0x65, 0xcc,
-	0x74, 0xcb, 0x31, 0x9c, 0x07, 0xb9, 0xae, 0xbc, 0x20, 0x90, 0x6d, 0xbe, 0xf3, 0x22, 0xf6, 0x24,
-	0xf8, 0x65, 0xc4, 0x32, 0xfc, 0x1f, 0xa4, 0xfc, 0x1d, 0xb0, 0x0d, 0x8d, 0x32, 0xb6, 0x65, 0x94,
-	0x8b, 0x4b, 0xda, 0xe4, 0x5b, 0x98, 0x26, 0x46, 0x29, 0xe2, 0x19, 0xfc, 0x89, 0x2b, 0xa8, 0x17,
-	0xb8, 0x87, 0x88, 0x65, 0x55, 0xac, 0x2e, 0xb0, 0xfe, 0x65, 0x2a, 0x1f, 0x51, 0xe9, 0x32, 0x86,
-	0xae, 0x4f, 0x79, 0x51, 0xa4, 0x55, 0xf8, 0x93, 0x38, 0xf6, 0xc7, 0xd9, 0x3c, 0x43, 0xca, 0x33,
-	0x82, 0x2f, 0xe6, 0x62, 0x4e, 0xe2, 0x80, 0x7e, 0x74, 0x5b, 0x0d, 0x1a, 0x79, 0x5c, 0xec, 0x24,
-	0x8d, 0x7e, 0x57, 0x3e, 0x4a, 0x2e, 0x9f, 0x9e, 0xce, 0x31, 0xa1, 0x44, 0x04, 0x95, 0x7f, 0xf0,
-	0xb5, 0x22, 0x62, 0x80, 0xa6, 0xa9, 0x9b, 0x53, 0x9d, 0xc8, 0x35, 0xdc, 0x86, 0xcf, 0x93, 0x99,
-	0x69, 0x58, 0x32, 0x9a, 0x5e, 0xaf, 0xae, 0x76, 0x2c, 0xdb, 0x1f, 0xd6, 0x79, 0x43, 0x6d, 0x7f,
-	0x4c, 0x28, 0x0f, 0xe8, 0x66, 0x47, 0xb9, 0xb6, 0xf5, 0xd6, 0x9c, 0xf9, 0x9a, 0xf8, 0x7f, 0x52,
-	0xad, 0x18, 0xb7, 0x6e, 0x8a, 0x72, 0xfc, 0x16, 0x00, 0x00, 0xff, 0xff, 0xeb, 0xd1, 0x3b, 0xb8,
-	0x6c, 0x02, 0x00, 0x00,
+	// 549 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x53, 0x6d, 0x6b, 0x9b, 0x5c,
+	0x18, 0xae, 0xa9, 0x7d, 0xc9, 0xdd, 0xc4, 0xe7, 0x3c, 0x87, 0x96, 0x85, 0xad, 0x8c, 0x62, 0x19,
+	0x2b, 0x8c, 0x45, 0x68, 0xd9, 0xbe, 0xdb, 0x46, 0x8a, 0x50, 0x35, 0x9c, 0x98, 0x41, 0xcb, 0x36,
+	0x31, 0xe6, 0xb4, 0x3d, 0xa0, 0x47, 0xd1, 0x93, 0x31, 0xf7, 0x93, 0xc6, 0xf6, 0x6d, 0xff, 0x6a,
+	0x7f, 0x62, 0x78, 0x6c, 0x8c, 0xd9, 0x0b, 0xf4, 0x53, 0x72, 0x5f, 0x2f, 0xb7, 0x97, 0xd7, 0x39,
+	0xc2, 0xd3, 0x28, 0x4d, 0x92, 0x94, 0x1b, 0x49, 0x91, 0x05, 0x59, 0xce, 0x78, 0xc4, 0xb2, 0x30,
+	0x1e, 0x66, 0x79, 0x2a, 0x52, 0xbc, 0x5d, 0x73, 0xfa, 0x4f, 0x05, 0x7a, 0xce, 0x64, 0x3c, 0x5e,
+	0xd2, 0xf8, 0x23, 0x0c, 0x1a, 0x6d, 0x10, 0xc5, 0x61, 0x51, 0xb0, 0x5b, 0x16, 0x85, 0x82, 0xa5,
+	0x7c, 0xa0, 0x1c, 0x29, 0x27, 0xda, 0xe9, 0xf1, 0xb0, 0xf6, 0x0e, 0xdb, 0xbe, 0xe1, 0xc5, 0x9a,
+	0x94, 0x3c, 0x69, 0x96, 0xac, 0x13, 0xf8, 0x10, 0xba, 0x0d, 0x35, 0xe8, 0x1c, 0x29, 0x27, 0x3d,
+	0xb2, 0x02, 0xf4, 0x0f, 0xa0, 0xfd, 0xa6, 0xdf, 0x05, 0x95, 0x78, 0x57, 0x16, 0xda, 0xc0, 0x07,
+	0xf0, 0xbf, 0x47, 0x2e, 0x4d, 0xd7, 0xbe, 0x31, 0x7d, 0xdb, 0x73, 0x83, 0xa9, 0x6b, 0xfb, 0x48,
+	0xc1, 0x3d, 0xd8, 0xb5, 0x47, 0x96, 0xeb, 0xdb, 0xfe, 0x35, 0xea, 0xe0, 0x3e, 0x74, 0x4d, 0xdf,
+	0x27, 0xf6, 0xf9, 0xd4, 0xb7, 0xd0, 0xa6, 0x1c, 0x5d, 0xcf, 0xbd, 0x76, 0x2a, 0x56, 0xd5, 0x7f,
+	0x28, 0x80, 0xbc, 0xfc, 0x2e, 0xe4, 0xec, 0x8b, 0xdc, 0x3e, 0xe5, 0x4c, 0xe0, 0x17, 0xa0, 0x55,
+	0x0d, 0xb1, 0x39, 0xe5, 0x82, 0xdd, 0x32, 0x9a, 0xcb, 0xf7, 0xec, 0x92, 0x7e, 0x52, 0x64, 0x76,
+	0x03, 0xe2, 0x11, 0x3c, 0x4f, 0x5b, 0xd6, 0x30, 0x0e, 0x16, 0x9c, 0x89, 0xb6, 0xad, 0x23, 0x6d,
+	0x87, 0xeb, 0xaa, 0xea, 0x11, 0xad, 0x2d, 0x67, 0x70, 0x10, 0xd1, 0xbc, 0x1e, 0x8a, 0xb6, 0x79,
+	0x53, 0x56, 0xb1, 0xbf, 0x22, 0x57, 0x26, 0xfd, 0xab, 0x02, 0x3b, 0xce, 0x64, 0x4c, 0xd2, 0x98,
+	0x3e, 0x36, 0xad, 0x01, 0x6a, 0x25, 0x97, 0x99, 0xb4, 0xd3, 0x67, 0xad, 0x23, 0xab, 0xe0, 0xe5,
+	0xaf, 0x5f, 0x66, 0x94, 0x48, 0xa1, 0x7e, 0x09, 0x7b, 0x2d, 0x10, 0x03, 0x6c, 0x3b, 0x96, 0x73,
+	0x6e, 0x11, 0xb4, 0x81, 0xbb, 0xb0, 0x65, 0x8e, 0x1c, 0xdb, 0x45, 0x4a, 0x05, 0x5f, 0x5c, 0xd9,
+	0x96, 0xeb, 0xa3, 0x4e, 0x75, 0x32, 0x63, 0xcb, 0x22, 0x68, 0x13, 0xef, 0xc1, 0x8e, 0x47, 0x46,
+	0x16, 0xb1, 0x08, 0x52, 0xf5, 0xef, 0xf5, 0x8d, 0x32, 0x85, 0xc8, 0xd9, 0x6c, 0x21, 0x1e, 0x9d,
+	0x18, 0x83, 0xca, 0xc3, 0x84, 0x3e, 0xb4, 0x28, 0xff, 0xe3, 0x7d, 0xd8, 0xfa, 0x14, 0xc6, 0x0b,
+	0x2a, 0xdb, 0xe9, 0x92, 0x7a, 0xc0, 0xc7, 0xd0, 0xa7, 0x9f, 0x05, 0xe5, 0x05, 0x4b, 0x79, 0x90,
+	0xb2, 0xf9, 0x40, 0x95, 0x6c, 0xaf, 0x01, 0x3d, 0x36, 0xc7, 0x2f, 0xe1, 0xbf, 0x95, 0xa8, 0x5e,
+	0xb2, 0x25, 0x2b, 0xd6, 0x1a, 0xf8, 0x5d, 0x85, 0xea, 0xdf, 0x14, 0xd8, 0x77, 0x26, 0xe3, 0x3a,
+	0x89, 0x28, 0x4d, 0x9e, 0xf2, 0x32, 0x61, 0xa2, 0xc4, 0xef, 0x41, 0x0b, 0x97, 0x43, 0x20, 0xca,
+	0x8c, 0x3e, 0xdc, 0xff, 0x37, 0xad, 0x32, 0xff, 0x70, 0xfd, 0x15, 0x94, 0x35, 0xf7, 0xc3, 0xf6,
+	0xa8, 0xbf, 0x85, 0xc1, 0xbf, 0xa4, 0x55, 0x9f, 0xae, 0xe7, 0xd8, 0xae, 0x79, 0x85, 0x36, 0x56,
+	0x57, 0xd8, 0x9b, 0x4e, 0x90, 0x72, 0xfe, 0xfa, 0xe6, 0xd5, 0x1d, 0x13, 0xf7, 0x8b, 0x59, 0x95,
+	0xc2, 0xb8, 0x2f, 0x33, 0x9a, 0xc7, 0x74, 0x7e, 0x47, 0x73, 0xe3, 0x36, 0x9c, 0xe5, 0x2c, 0x32,
+	0xe4, 0xf7, 0x5d, 0x18, 0x75, 0xc6, 0xd9, 0xb6, 0x1c, 0xcf, 0x7e, 0x0d, 0x00, 0x06, 0xef, 0x0b,
+	0xb8, 0x0c, 0x04, 0x00, 0x00,
 }
@@ -20,6 +20,8 @@ const (
 	Policy_SIGNATURE     Policy_PolicyType = 1
 	Policy_MSP           Policy_PolicyType = 2
 	Policy_IMPLICIT_META Policy_PolicyType = 3
+	Policy_COMPOSITE     Policy_PolicyType = 4
+	Policy_BLS_AGGREGATE Policy_PolicyType = 5
 )
 
 var Policy_PolicyType_name = map[int32]string{
@@ -27,12 +29,16 @@ var Policy_PolicyType_name = map[int32]string{
 	1: "SIGNATURE",
 	2: "MSP",
 	3: "IMPLICIT_META",
+	4: "COMPOSITE",
+	5: "BLS_AGGREGATE",
 }
 var Policy_PolicyType_value = map[string]int32{
 	"UNKNOWN":       0,
 	"SIGNATURE":     1,
 	"MSP":           2,
 	"IMPLICIT_META": 3,
+	"COMPOSITE":     4,
+	"BLS_AGGREGATE": 5,
 }
 
 func (x Policy_PolicyType) String() string {
@@ -112,6 +118,7 @@ type SignaturePolicy struct {
 	// Types that are valid to be assigned to Type:
 	//	*SignaturePolicy_SignedBy
 	//	*SignaturePolicy_NOutOf_
+	//	*SignaturePolicy_SignedByExcept_
 	Type isSignaturePolicy_Type `protobuf_oneof:"Type"`
 }
 
@@ -130,9 +137,13 @@ type SignaturePolicy_SignedBy struct {
 type SignaturePolicy_NOutOf_ struct {
 	NOutOf *SignaturePolicy_NOutOf `protobuf:"bytes,2,opt,name=n_out_of,json=nOutOf,oneof"`
 }
+type SignaturePolicy_SignedByExcept_ struct {
+	SignedByExcept *SignaturePolicy_SignedByExcept `protobuf:"bytes,3,opt,name=signed_by_except,json=signedByExcept,oneof"`
+}
 
-func (*SignaturePolicy_SignedBy) isSignaturePolicy_Type() {}
-func (*SignaturePolicy_NOutOf_) isSignaturePolicy_Type()  {}
+func (*SignaturePolicy_SignedBy) isSignaturePolicy_Type()        {}
+func (*SignaturePolicy_NOutOf_) isSignaturePolicy_Type()         {}
+func (*SignaturePolicy_SignedByExcept_) isSignaturePolicy_Type() {}
 
 func (m *SignaturePolicy) GetType() isSignaturePolicy_Type {
 	if m != nil {
@@ -155,11 +166,19 @@ func (m *SignaturePolicy) GetNOutOf() *SignaturePolicy_NOutOf {
 	return nil
 }
 
+func (m *SignaturePolicy) GetSignedByExcept() *SignaturePolicy_SignedByExcept {
+	if x, ok := m.GetType().(*SignaturePolicy_SignedByExcept_); ok {
+		return x.SignedByExcept
+	}
+	return nil
+}
+
 // XXX_OneofFuncs is for the internal use of the proto package.
 func (*SignaturePolicy) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
 	return _SignaturePolicy_OneofMarshaler, _SignaturePolicy_OneofUnmarshaler, _SignaturePolicy_OneofSizer, []interface{}{
 		(*SignaturePolicy_SignedBy)(nil),
 		(*SignaturePolicy_NOutOf_)(nil),
+		(*SignaturePolicy_SignedByExcept_)(nil),
 	}
 }
 
@@ -175,6 +194,11 @@ func _SignaturePolicy_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
 		if err := b.EncodeMessage(x.NOutOf); err != nil {
 			return err
 		}
+	case *SignaturePolicy_SignedByExcept_:
+		b.EncodeVarint(3<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.SignedByExcept); err != nil {
+			return err
+		}
 	case nil:
 	default:
 		return fmt.Errorf("SignaturePolicy.Type has unexpected type %T", x)
@@ -200,6 +224,14 @@ func _SignaturePolicy_OneofUnmarshaler(msg proto.Message, tag, wire int, b *prot
 		err := b.DecodeMessage(msg)
 		m.Type = &SignaturePolicy_NOutOf_{msg}
 		return true, err
+	case 3: // Type.signed_by_except
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(SignaturePolicy_SignedByExcept)
+		err := b.DecodeMessage(msg)
+		m.Type = &SignaturePolicy_SignedByExcept_{msg}
+		return true, err
 	default:
 		return false, nil
 	}
@@ -217,6 +249,11 @@ func _SignaturePolicy_OneofSizer(msg proto.Message) (n int) {
 		n += proto.SizeVarint(2<<3 | proto.WireBytes)
 		n += proto.SizeVarint(uint64(s))
 		n += s
+	case *SignaturePolicy_SignedByExcept_:
+		s := proto.Size(x.SignedByExcept)
+		n += proto.SizeVarint(3<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
 	case nil:
 	default:
 		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
@@ -241,6 +278,21 @@ func (m *SignaturePolicy_NOutOf) GetPolicies() []*SignaturePolicy {
 	return nil
 }
 
+// SignedByExcept is satisfied by a signature from an identity which satisfies the
+// principal signed_by but none of the principals except, such as any member of an
+// organization except those of an organizational unit
+type SignaturePolicy_SignedByExcept struct {
+	SignedBy int32   `protobuf:"varint,1,opt,name=signed_by,json=signedBy" json:"signed_by,omitempty"`
+	Except   []int32 `protobuf:"varint,2,rep,packed,name=except" json:"except,omitempty"`
+}
+
+func (m *SignaturePolicy_SignedByExcept) Reset()         { *m = SignaturePolicy_SignedByExcept{} }
+func (m *SignaturePolicy_SignedByExcept) String() string { return proto.CompactTextString(m) }
+func (*SignaturePolicy_SignedByExcept) ProtoMessage()    {}
+func (*SignaturePolicy_SignedByExcept) Descriptor() ([]byte, []int) {
+	return fileDescriptor5, []int{2, 1}
+}
+
 // ImplicitMetaPolicy is a policy type which depends on the hierarchical nature of the configuration
 // It is implicit because the rule is generate implicitly based on the number of sub policies
 // It is meta because it depends only on the result of other policies
@@ -259,12 +311,167 @@ func (m *ImplicitMetaPolicy) String() string            { return proto.CompactTe
 func (*ImplicitMetaPolicy) ProtoMessage()               {}
 func (*ImplicitMetaPolicy) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{3} }
 
+// CompositePolicy is a policy type which, like ImplicitMetaPolicy, depends only on the result of other
+// policies, but which names these sub-policies explicitly and combines them with the NOutOf operator, which
+// may be nested.  A sub-policy is referenced by its path, either relative to the group of the composite policy,
+// such as "Org1/Admins", or absolute, such as "/Channel/Orderer/Admins".
+// For example, 2 out of the sub-policies "OrgA/Admins", "OrgB/Peers" and "/Channel/Orderer/Admins" requires
+// the signatures to satisfy at least two of these three policies.
+type CompositePolicy struct {
+	// Types that are valid to be assigned to Type:
+	//	*CompositePolicy_SubPolicy
+	//	*CompositePolicy_NOutOf_
+	Type isCompositePolicy_Type `protobuf_oneof:"Type"`
+}
+
+func (m *CompositePolicy) Reset()                    { *m = CompositePolicy{} }
+func (m *CompositePolicy) String() string            { return proto.CompactTextString(m) }
+func (*CompositePolicy) ProtoMessage()               {}
+func (*CompositePolicy) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{4} }
+
+type isCompositePolicy_Type interface{ isCompositePolicy_Type() }
+
+type CompositePolicy_SubPolicy struct {
+	SubPolicy string `protobuf:"bytes,1,opt,name=sub_policy,json=subPolicy,oneof"`
+}
+type CompositePolicy_NOutOf_ struct {
+	NOutOf *CompositePolicy_NOutOf `protobuf:"bytes,2,opt,name=n_out_of,json=nOutOf,oneof"`
+}
+
+func (*CompositePolicy_SubPolicy) isCompositePolicy_Type() {}
+func (*CompositePolicy_NOutOf_) isCompositePolicy_Type()   {}
+
+func (m *CompositePolicy) GetType() isCompositePolicy_Type {
+	if m != nil {
+		return m.Type
+	}
+	return nil
+}
+
+func (m *CompositePolicy) GetSubPolicy() string {
+	if x, ok := m.GetType().(*CompositePolicy_SubPolicy); ok {
+		return x.SubPolicy
+	}
+	return ""
+}
+
+func (m *CompositePolicy) GetNOutOf() *CompositePolicy_NOutOf {
+	if x, ok := m.GetType().(*CompositePolicy_NOutOf_); ok {
+		return x.NOutOf
+	}
+	return nil
+}
+
+// XXX_OneofFuncs is for the internal use of the proto package.
+func (*CompositePolicy) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
+	return _CompositePolicy_OneofMarshaler, _CompositePolicy_OneofUnmarshaler, _CompositePolicy_OneofSizer, []interface{}{
+		(*CompositePolicy_SubPolicy)(nil),
+		(*CompositePolicy_NOutOf_)(nil),
+	}
+}
+
+func _CompositePolicy_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
+	m := msg.(*CompositePolicy)
+	// Type
+	switch x := m.Type.(type) {
+	case *CompositePolicy_SubPolicy:
+		b.EncodeVarint(1<<3 | proto.WireBytes)
+		b.EncodeStringBytes(x.SubPolicy)
+	case *CompositePolicy_NOutOf_:
+		b.EncodeVarint(2<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.NOutOf); err != nil {
+			return err
+		}
+	case nil:
+	default:
+		return fmt.Errorf("CompositePolicy.Type has unexpected type %T", x)
+	}
+	return nil
+}
+
+func _CompositePolicy_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error) {
+	m := msg.(*CompositePolicy)
+	switch tag {
+	case 1: // Type.sub_policy
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		x, err := b.DecodeStringBytes()
+		m.Type = &CompositePolicy_SubPolicy{x}
+		return true, err
+	case 2: // Type.n_out_of
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(CompositePolicy_NOutOf)
+		err := b.DecodeMessage(msg)
+		m.Type = &CompositePolicy_NOutOf_{msg}
+		return true, err
+	default:
+		return false, nil
+	}
+}
+
+func _CompositePolicy_OneofSizer(msg proto.Message) (n int) {
+	m := msg.(*CompositePolicy)
+	// Type
+	switch x := m.Type.(type) {
+	case *CompositePolicy_SubPolicy:
+		n += proto.SizeVarint(1<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(len(x.SubPolicy)))
+		n += len(x.SubPolicy)
+	case *CompositePolicy_NOutOf_:
+		s := proto.Size(x.NOutOf)
+		n += proto.SizeVarint(2<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case nil:
+	default:
+		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
+	}
+	return n
+}
+
+type CompositePolicy_NOutOf struct {
+	N        int32              `protobuf:"varint,1,opt,name=N" json:"N,omitempty"`
+	Policies []*CompositePolicy `protobuf:"bytes,2,rep,name=policies" json:"policies,omitempty"`
+}
+
+func (m *CompositePolicy_NOutOf) Reset()                    { *m = CompositePolicy_NOutOf{} }
+func (m *CompositePolicy_NOutOf) String() string            { return proto.CompactTextString(m) }
+func (*CompositePolicy_NOutOf) ProtoMessage()               {}
+func (*CompositePolicy_NOutOf) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{4, 0} }
+
+func (m *CompositePolicy_NOutOf) GetPolicies() []*CompositePolicy {
+	if m != nil {
+		return m.Policies
+	}
+	return nil
+}
+
+// BLSAggregatePolicy is a policy type satisfied by a BLS12-381 signature aggregated from the signatures,
+// over the same data, of at least n of the principals identified by public_keys.  The public keys are
+// compressed G1 points, and must have been checked against proofs of possession of their private keys.
+type BLSAggregatePolicy struct {
+	N          int32    `protobuf:"varint,1,opt,name=n" json:"n,omitempty"`
+	PublicKeys [][]byte `protobuf:"bytes,2,rep,name=public_keys,json=publicKeys,proto3" json:"public_keys,omitempty"`
+}
+
+func (m *BLSAggregatePolicy) Reset()                    { *m = BLSAggregatePolicy{} }
+func (m *BLSAggregatePolicy) String() string            { return proto.CompactTextString(m) }
+func (*BLSAggregatePolicy) ProtoMessage()               {}
+func (*BLSAggregatePolicy) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{5} }
+
 func init() {
 	proto.RegisterType((*Policy)(nil), "common.Policy")
 	proto.RegisterType((*SignaturePolicyEnvelope)(nil), "common.SignaturePolicyEnvelope")
 	proto.RegisterType((*SignaturePolicy)(nil), "common.SignaturePolicy")
 	proto.RegisterType((*SignaturePolicy_NOutOf)(nil), "common.SignaturePolicy.NOutOf")
+	proto.RegisterType((*SignaturePolicy_SignedByExcept)(nil), "common.SignaturePolicy.SignedByExcept")
 	proto.RegisterType((*ImplicitMetaPolicy)(nil), "common.ImplicitMetaPolicy")
+	proto.RegisterType((*CompositePolicy)(nil), "common.CompositePolicy")
+	proto.RegisterType((*CompositePolicy_NOutOf)(nil), "common.CompositePolicy.NOutOf")
+	proto.RegisterType((*BLSAggregatePolicy)(nil), "common.BLSAggregatePolicy")
 	proto.RegisterEnum("common.Policy_PolicyType", Policy_PolicyType_name, Policy_PolicyType_value)
 	proto.RegisterEnum("common.ImplicitMetaPolicy_Rule", ImplicitMetaPolicy_Rule_name, ImplicitMetaPolicy_Rule_value)
 }
@@ -272,35 +479,44 @@ func init() {
 func init() { proto.RegisterFile("common/policies.proto", fileDescriptor5) }
 
 var fileDescriptor5 = []byte{
-	// 466 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x74, 0x52, 0x51, 0x8f, 0xd2, 0x40,
-	0x10, 0x66, 0x01, 0x0b, 0x0c, 0x9c, 0xd6, 0x8d, 0x7a, 0x84, 0x44, 0x25, 0x7d, 0x30, 0x24, 0xc6,
-	0x36, 0x01, 0x9f, 0x7c, 0x03, 0x25, 0x5e, 0x3d, 0x5a, 0x9a, 0xa5, 0x17, 0x73, 0xbe, 0x34, 0x14,
-	0x16, 0x6e, 0x93, 0xd2, 0x6e, 0xda, 0xed, 0x25, 0xf5, 0x57, 0xf8, 0xec, 0xbf, 0xf1, 0x9f, 0x99,
-	0xee, 0xb6, 0x17, 0xbd, 0xcb, 0xbd, 0xcd, 0xcc, 0x7e, 0xf3, 0xcd, 0xf7, 0xcd, 0x2c, 0xbc, 0xdc,
-	0x25, 0xa7, 0x53, 0x12, 0x5b, 0x3c, 0x89, 0xd8, 0x8e, 0xd1, 0xcc, 0xe4, 0x69, 0x22, 0x12, 0xac,
-	0xa9, 0xf2, 0x68, 0x54, 0x3d, 0x9f, 0x32, 0x1e, 0xf0, 0x94, 0xc5, 0x3b, 0xc6, 0xb7, 0x91, 0xc2,
-	0x18, 0x3f, 0x41, 0xf3, 0xca, 0xae, 0x02, 0x63, 0x68, 0x8b, 0x82, 0xd3, 0x21, 0x1a, 0xa3, 0xc9,
-	0x13, 0x22, 0x63, 0xfc, 0x0a, 0x34, 0xc9, 0x59, 0x0c, 0x9b, 0x63, 0x34, 0x19, 0x90, 0x2a, 0x33,
-	0xbe, 0x00, 0xa8, 0x2e, 0xbf, 0x44, 0xf5, 0xa1, 0x73, 0xe5, 0x5e, 0xba, 0xeb, 0xef, 0xae, 0xde,
-	0xc0, 0x67, 0xd0, 0xdb, 0xd8, 0x5f, 0xdd, 0xb9, 0x7f, 0x45, 0x96, 0x3a, 0xc2, 0x1d, 0x68, 0x39,
-	0x1b, 0x4f, 0x6f, 0xe2, 0xe7, 0x70, 0x66, 0x3b, 0xde, 0xca, 0xfe, 0x6c, 0xfb, 0x81, 0xb3, 0xf4,
-	0xe7, 0x7a, 0xcb, 0xf8, 0x8d, 0xe0, 0x7c, 0xc3, 0x8e, 0xf1, 0x56, 0xe4, 0x29, 0x55, 0x7c, 0xcb,
-	0xf8, 0x96, 0x46, 0x09, 0xa7, 0x78, 0x08, 0x9d, 0x5b, 0x9a, 0x66, 0x2c, 0x89, 0x2b, 0x41, 0x75,
-	0x8a, 0xad, 0xff, 0x34, 0xf5, 0xa7, 0xe7, 0xa6, 0xb2, 0x67, 0xde, 0xa3, 0xaa, 0xc5, 0xe2, 0x8f,
-	0x00, 0x6c, 0x4f, 0x63, 0xc1, 0x04, 0xa3, 0xd9, 0xb0, 0x35, 0x6e, 0x4d, 0xfa, 0xd3, 0x17, 0x75,
-	0x93, 0xb3, 0xf1, 0xbc, 0x7a, 0x25, 0xe4, 0x1f, 0x9c, 0xf1, 0x07, 0xc1, 0xb3, 0x7b, 0x8c, 0xf8,
-	0x35, 0xf4, 0x32, 0x76, 0x8c, 0xe9, 0x3e, 0x08, 0x0b, 0x25, 0xeb, 0xa2, 0x41, 0xba, 0xaa, 0xb4,
-	0x28, 0xf0, 0x27, 0xe8, 0xc6, 0x41, 0x92, 0x8b, 0x20, 0x39, 0x54, 0xda, 0xde, 0x3c, 0xa2, 0xcd,
-	0x74, 0xd7, 0xb9, 0x58, 0x1f, 0x2e, 0x1a, 0x44, 0x8b, 0x65, 0x34, 0xba, 0x04, 0x4d, 0xd5, 0xf0,
-	0x00, 0x90, 0x5b, 0x79, 0x46, 0x2e, 0x9e, 0x41, 0xb7, 0xbe, 0xea, 0xb0, 0x29, 0xa5, 0x3f, 0xea,
-	0xf7, 0x0e, 0xb8, 0xd0, 0xa0, 0x5d, 0x1e, 0xc6, 0xf8, 0x85, 0x00, 0xdb, 0x27, 0x5e, 0x56, 0x85,
-	0x43, 0xc5, 0xf6, 0xce, 0x06, 0x64, 0x79, 0x18, 0x54, 0x5b, 0x2c, 0x47, 0xf5, 0x48, 0x2f, 0xcb,
-	0xc3, 0xea, 0x79, 0x06, 0xed, 0x34, 0x8f, 0xa8, 0xb4, 0xf0, 0x74, 0xfa, 0xb6, 0x1e, 0xf7, 0x90,
-	0xc8, 0x24, 0x79, 0x44, 0x89, 0x04, 0x1b, 0xef, 0xa0, 0x5d, 0x66, 0xe5, 0xbd, 0xe7, 0xee, 0xb5,
-	0xde, 0x90, 0xc1, 0x6a, 0xa5, 0x23, 0x3c, 0x80, 0xae, 0x33, 0xff, 0xb6, 0x26, 0xb6, 0x7f, 0xad,
-	0x37, 0x17, 0x1f, 0x7e, 0xbc, 0x3f, 0x32, 0x71, 0x93, 0x87, 0x25, 0xad, 0x75, 0x53, 0x70, 0x9a,
-	0x46, 0x74, 0x7f, 0xa4, 0xa9, 0x75, 0xd8, 0x86, 0x29, 0xdb, 0x59, 0xf2, 0x5b, 0x66, 0x96, 0x1a,
-	0x1a, 0x6a, 0x32, 0x9d, 0xfd, 0x0d, 0x00, 0x00, 0xff, 0xff, 0x29, 0x52, 0xc3, 0xda, 0xe2, 0x02,
-	0x00, 0x00,
+	// 620 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x54, 0xcd, 0x6e, 0xda, 0x4c,
+	0x14, 0x65, 0x80, 0x38, 0x70, 0x21, 0x89, 0xbf, 0xd1, 0xd7, 0x04, 0x51, 0xb5, 0x41, 0x5e, 0x44,
+	0x91, 0xaa, 0x82, 0x94, 0x74, 0xd5, 0x9d, 0x41, 0x16, 0xb8, 0xc1, 0x06, 0x8d, 0x1d, 0x55, 0xe9,
+	0xc6, 0xc2, 0x64, 0x70, 0x46, 0x35, 0xb6, 0x65, 0x8f, 0xa3, 0xfa, 0x2d, 0xba, 0xae, 0xfa, 0x36,
+	0x7d, 0x8d, 0x3e, 0x4c, 0xe5, 0x3f, 0x1a, 0x40, 0xc9, 0xa2, 0xbb, 0xb9, 0x77, 0xce, 0x9c, 0x73,
+	0xe6, 0xdc, 0xb1, 0xe1, 0xd5, 0xd2, 0x5f, 0xaf, 0x7d, 0x6f, 0x10, 0xf8, 0x2e, 0x5b, 0x32, 0x1a,
+	0xf5, 0x83, 0xd0, 0xe7, 0x3e, 0x16, 0xf2, 0x76, 0xb7, 0x5b, 0x6c, 0xaf, 0xa3, 0xc0, 0x0a, 0x42,
+	0xe6, 0x2d, 0x59, 0xb0, 0x70, 0x73, 0x8c, 0xf4, 0x13, 0x81, 0x30, 0x4f, 0x8f, 0x25, 0x18, 0x43,
+	0x9d, 0x27, 0x01, 0xed, 0xa0, 0x1e, 0xba, 0x3c, 0x20, 0xd9, 0x1a, 0x9f, 0x82, 0x90, 0x91, 0x26,
+	0x9d, 0x6a, 0x0f, 0x5d, 0xb6, 0x49, 0x51, 0x49, 0x2b, 0x80, 0xfc, 0x94, 0x99, 0xa2, 0x5a, 0x70,
+	0x78, 0xab, 0xdf, 0xe8, 0xb3, 0xcf, 0xba, 0x58, 0xc1, 0x47, 0xd0, 0x34, 0xd4, 0xb1, 0x2e, 0x9b,
+	0xb7, 0x44, 0x11, 0x11, 0x3e, 0x84, 0x9a, 0x66, 0xcc, 0xc5, 0x2a, 0xfe, 0x0f, 0x8e, 0x54, 0x6d,
+	0x3e, 0x55, 0x47, 0xaa, 0x69, 0x69, 0x8a, 0x29, 0x8b, 0xb5, 0x14, 0x3a, 0x9a, 0x69, 0xf3, 0x99,
+	0xa1, 0x9a, 0x8a, 0x58, 0x4f, 0x11, 0xc3, 0xa9, 0x61, 0xc9, 0xe3, 0x31, 0x51, 0xc6, 0xb2, 0xa9,
+	0x88, 0x07, 0xd2, 0x0f, 0x04, 0x67, 0x06, 0x73, 0xbc, 0x05, 0x8f, 0x43, 0x9a, 0x2b, 0x2a, 0xde,
+	0x23, 0x75, 0xfd, 0x80, 0xe2, 0x0e, 0x1c, 0x3e, 0xd2, 0x30, 0x62, 0xbe, 0x57, 0x58, 0x2e, 0x4b,
+	0x3c, 0xd8, 0x72, 0xdd, 0xba, 0x3a, 0xeb, 0xe7, 0x09, 0xf4, 0x77, 0xa8, 0xca, 0xeb, 0xe0, 0x0f,
+	0x00, 0xec, 0x9e, 0x7a, 0x9c, 0x71, 0x46, 0xa3, 0x4e, 0xad, 0x57, 0xbb, 0x6c, 0x5d, 0xfd, 0x5f,
+	0x1e, 0xd2, 0x8c, 0xf9, 0xbc, 0x4c, 0x8d, 0x3c, 0xc1, 0x49, 0xbf, 0xab, 0x70, 0xb2, 0xc3, 0x88,
+	0xdf, 0x40, 0x33, 0x62, 0x8e, 0x47, 0xef, 0x2d, 0x3b, 0xc9, 0x6d, 0x4d, 0x2a, 0xa4, 0x91, 0xb7,
+	0x86, 0x09, 0xfe, 0x08, 0x0d, 0xcf, 0xf2, 0x63, 0x6e, 0xf9, 0xab, 0xc2, 0xdb, 0xdb, 0x67, 0xbc,
+	0xf5, 0xf5, 0x59, 0xcc, 0x67, 0xab, 0x49, 0x85, 0x08, 0x5e, 0xb6, 0xc2, 0x04, 0xc4, 0x0d, 0xb5,
+	0x45, 0xbf, 0x2d, 0x69, 0xc0, 0x3b, 0xb5, 0x8c, 0xe3, 0xe2, 0x39, 0x0e, 0xa3, 0xd0, 0x55, 0x32,
+	0xf4, 0xa4, 0x42, 0x8e, 0xa3, 0xad, 0x4e, 0xf7, 0x06, 0x84, 0x5c, 0x07, 0xb7, 0x01, 0xe9, 0x45,
+	0x8e, 0x48, 0xc7, 0xd7, 0xd0, 0x28, 0x1f, 0x53, 0xa7, 0xda, 0xab, 0xbd, 0x94, 0xe1, 0x06, 0xd8,
+	0x55, 0xe0, 0x78, 0x5b, 0x10, 0xbf, 0xde, 0x4b, 0xe3, 0x49, 0x16, 0xa7, 0x20, 0x14, 0xb7, 0x48,
+	0x15, 0x0e, 0x48, 0x51, 0x0d, 0x05, 0xa8, 0xa7, 0xaf, 0x4a, 0xfa, 0x8e, 0x00, 0xab, 0xeb, 0x20,
+	0x25, 0xe7, 0x1a, 0xe5, 0x8b, 0x4d, 0xc2, 0x10, 0xc5, 0xb6, 0x55, 0x0c, 0x38, 0x25, 0x6d, 0x92,
+	0x66, 0x14, 0xdb, 0xc5, 0xf6, 0x35, 0xd4, 0xc3, 0xd8, 0xa5, 0x59, 0xba, 0xc7, 0x57, 0xe7, 0xa5,
+	0xeb, 0x7d, 0xa2, 0x3e, 0x89, 0x5d, 0x4a, 0x32, 0xb0, 0x74, 0x01, 0xf5, 0xb4, 0x4a, 0x1f, 0xab,
+	0xac, 0xdf, 0x89, 0x95, 0x6c, 0x31, 0x9d, 0x8a, 0x08, 0xb7, 0xa1, 0xa1, 0xc9, 0x9f, 0x66, 0x44,
+	0x35, 0xef, 0xc4, 0xaa, 0xf4, 0x0b, 0xc1, 0xc9, 0xc8, 0x5f, 0x07, 0x7e, 0xc4, 0x78, 0x39, 0xf1,
+	0xf3, 0x7d, 0x3f, 0x93, 0xca, 0x53, 0x47, 0x2f, 0xcc, 0x7c, 0x87, 0x6b, 0x6f, 0xe6, 0xff, 0x32,
+	0x9f, 0x1d, 0xce, 0xbf, 0xf3, 0xd9, 0x04, 0x3b, 0x02, 0x3c, 0x9c, 0x1a, 0xb2, 0xe3, 0x84, 0xd4,
+	0x59, 0x6c, 0xee, 0xd1, 0x06, 0x54, 0x7e, 0x48, 0xc8, 0xc3, 0xe7, 0xd0, 0x0a, 0x62, 0xdb, 0x65,
+	0x4b, 0xeb, 0x2b, 0x4d, 0x72, 0x8d, 0x36, 0x81, 0xbc, 0x75, 0x43, 0x93, 0x68, 0xf8, 0xfe, 0xcb,
+	0x3b, 0x87, 0xf1, 0x87, 0xd8, 0x4e, 0x75, 0x07, 0x0f, 0x49, 0x40, 0x43, 0x97, 0xde, 0x3b, 0x34,
+	0x1c, 0xac, 0x16, 0x76, 0xc8, 0x96, 0x83, 0xec, 0xff, 0x12, 0x0d, 0x72, 0x57, 0xb6, 0x90, 0x95,
+	0xd7, 0x7f, 0x06, 0x00, 0x7b, 0x39, 0x7b, 0x10, 0xab, 0x04, 0x00, 0x00,
 }
@@ -6,9 +6,11 @@
 Package gossip is a generated protocol buffer package.
 
 It is generated from these files:
+
 	gossip/message.proto
 
 It has these top-level messages:
+
 	Envelope
 	SecretEnvelope
 	Secret
@@ -33,6 +35,8 @@ It has these top-level messages:
 	Empty
 	RemoteStateRequest
 	RemoteStateResponse
+	BloomFilter
+	MACHandshake
 */
 package gossip
 
@@ -121,6 +125,18 @@ type Envelope struct {
 	Payload        []byte          `protobuf:"bytes,1,opt,name=payload,proto3" json:"payload,omitempty"`
 	Signature      []byte          `protobuf:"bytes,2,opt,name=signature,proto3" json:"signature,omitempty"`
 	SecretEnvelope *SecretEnvelope `protobuf:"bytes,3,opt,name=secretEnvelope" json:"secretEnvelope,omitempty"`
+	// The number of hops the message traveled from the peer
+	// that created it. It is set by the peer that sends
+	// the Envelope and therefore isn't signed
+	Hops uint32 `protobuf:"varint,4,opt,name=hops" json:"hops,omitempty"`
+	// The compression algorithm the payload is compressed
+	// with, negotiated in the handshake. Empty if the
+	// payload is not compressed
+	Compression string `protobuf:"bytes,5,opt,name=compression" json:"compression,omitempty"`
+	// The MAC of the Envelope under the MAC session of the
+	// connection it is sent over, if any. It is set by the
+	// peer that sends the Envelope and therefore isn't signed
+	Mac []byte `protobuf:"bytes,6,opt,name=mac,proto3" json:"mac,omitempty"`
 }
 
 func (m *Envelope) Reset()                    { *m = Envelope{} }
@@ -890,6 +906,10 @@ type ConnEstablish struct {
 	PkiID []byte `protobuf:"bytes,1,opt,name=pkiID,proto3" json:"pkiID,omitempty"`
 	Cert  []byte `protobuf:"bytes,2,opt,name=cert,proto3" json:"cert,omitempty"`
 	Hash  []byte `protobuf:"bytes,3,opt,name=hash,proto3" json:"hash,omitempty"`
+	// The compression algorithms the peer accepts
+	Compression []string `protobuf:"bytes,4,rep,name=compression" json:"compression,omitempty"`
+	// The handshake of the MAC session of the connection, if any
+	Mac *MACHandshake `protobuf:"bytes,5,opt,name=mac" json:"mac,omitempty"`
 }
 
 func (m *ConnEstablish) Reset()                    { *m = ConnEstablish{} }
@@ -897,6 +917,13 @@ func (m *ConnEstablish) String() string            { return proto.CompactTextStr
 func (*ConnEstablish) ProtoMessage()               {}
 func (*ConnEstablish) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
 
+func (m *ConnEstablish) GetMac() *MACHandshake {
+	if m != nil {
+		return m.Mac
+	}
+	return nil
+}
+
 // PeerIdentity defines the identity of the peer
 // Used to make other peers learn of the identity
 // of a certain peer
@@ -930,6 +957,9 @@ type GossipHello struct {
 	Nonce    uint64      `protobuf:"varint,1,opt,name=nonce" json:"nonce,omitempty"`
 	Metadata []byte      `protobuf:"bytes,2,opt,name=metadata,proto3" json:"metadata,omitempty"`
 	MsgType  PullMsgType `protobuf:"varint,3,opt,name=msgType,enum=gossip.PullMsgType" json:"msgType,omitempty"`
+	// The items the initiator has, hashed with the nonce
+	// as a seed. If nil, the digest contains all items
+	Filter *BloomFilter `protobuf:"bytes,4,opt,name=filter" json:"filter,omitempty"`
 }
 
 func (m *GossipHello) Reset()                    { *m = GossipHello{} }
@@ -937,6 +967,13 @@ func (m *GossipHello) String() string            { return proto.CompactTextStrin
 func (*GossipHello) ProtoMessage()               {}
 func (*GossipHello) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
 
+func (m *GossipHello) GetFilter() *BloomFilter {
+	if m != nil {
+		return m.Filter
+	}
+	return nil
+}
+
 // DataUpdate is the the final message in the pull phase
 // sent from the receiver to the initiator
 type DataUpdate struct {
@@ -1152,6 +1189,35 @@ func (m *RemoteStateResponse) GetPayloads() []*Payload {
 	return nil
 }
 
+// BloomFilter is a probabilistic representation of a set
+// of items. The initiator of a pull sends a BloomFilter of
+// the items it has, so that the digest sent back only
+// contains the items it is missing
+type BloomFilter struct {
+	Bits   []byte `protobuf:"bytes,1,opt,name=bits,proto3" json:"bits,omitempty"`
+	Hashes uint32 `protobuf:"varint,2,opt,name=hashes" json:"hashes,omitempty"`
+}
+
+func (m *BloomFilter) Reset()                    { *m = BloomFilter{} }
+func (m *BloomFilter) String() string            { return proto.CompactTextString(m) }
+func (*BloomFilter) ProtoMessage()               {}
+func (*BloomFilter) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{24} }
+
+// MACHandshake establishes the MAC session of a connection
+// between two peers. Each peer first sends its ephemeral key
+// and nonce, then its signature over both PKI-IDs, ephemeral
+// keys and nonces
+type MACHandshake struct {
+	EphemeralKey []byte `protobuf:"bytes,1,opt,name=ephemeralKey,proto3" json:"ephemeralKey,omitempty"`
+	Nonce        []byte `protobuf:"bytes,2,opt,name=nonce,proto3" json:"nonce,omitempty"`
+	Signature    []byte `protobuf:"bytes,3,opt,name=signature,proto3" json:"signature,omitempty"`
+}
+
+func (m *MACHandshake) Reset()                    { *m = MACHandshake{} }
+func (m *MACHandshake) String() string            { return proto.CompactTextString(m) }
+func (*MACHandshake) ProtoMessage()               {}
+func (*MACHandshake) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{25} }
+
 func init() {
 	proto.RegisterType((*Envelope)(nil), "gossip.Envelope")
 	proto.RegisterType((*SecretEnvelope)(nil), "gossip.SecretEnvelope")
@@ -1177,6 +1243,8 @@ func init() {
 	proto.RegisterType((*Empty)(nil), "gossip.Empty")
 	proto.RegisterType((*RemoteStateRequest)(nil), "gossip.RemoteStateRequest")
 	proto.RegisterType((*RemoteStateResponse)(nil), "gossip.RemoteStateResponse")
+	proto.RegisterType((*BloomFilter)(nil), "gossip.BloomFilter")
+	proto.RegisterType((*MACHandshake)(nil), "gossip.MACHandshake")
 	proto.RegisterEnum("gossip.PullMsgType", PullMsgType_name, PullMsgType_value)
 	proto.RegisterEnum("gossip.GossipMessage_Tag", GossipMessage_Tag_name, GossipMessage_Tag_value)
 }
@@ -1326,84 +1394,94 @@ var _Gossip_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("gossip/message.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 1256 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xb4, 0x57, 0xdd, 0x6f, 0xdb, 0x36,
-	0x10, 0x97, 0xe3, 0x4f, 0x9d, 0xed, 0xc4, 0x61, 0xd3, 0x42, 0xcb, 0x3a, 0x20, 0x10, 0xba, 0x22,
-	0x5b, 0x5a, 0x67, 0x4b, 0xfb, 0x50, 0xf4, 0x61, 0x9b, 0x53, 0x7b, 0x75, 0x86, 0xda, 0x0d, 0x98,
-	0x74, 0x40, 0xf7, 0x12, 0x30, 0x36, 0x23, 0x6b, 0x91, 0x28, 0x55, 0x64, 0x5a, 0xe4, 0x69, 0xc0,
-	0x9e, 0x86, 0xfd, 0xd5, 0x03, 0x49, 0x51, 0x96, 0x22, 0xa7, 0x40, 0x0a, 0xec, 0x4d, 0x77, 0xfc,
-	0xdd, 0x07, 0x8f, 0x77, 0x3f, 0x52, 0xb0, 0xe5, 0x45, 0x9c, 0xfb, 0xf1, 0x7e, 0x48, 0x39, 0x27,
-	0x1e, 0xed, 0xc7, 0x49, 0x24, 0x22, 0xd4, 0xd0, 0x5a, 0xf7, 0xef, 0x0a, 0xb4, 0x46, 0xec, 0x23,
-	0x0d, 0xa2, 0x98, 0x22, 0x07, 0x9a, 0x31, 0xb9, 0x0e, 0x22, 0x32, 0x77, 0x2a, 0x3b, 0x95, 0xdd,
-	0x0e, 0x36, 0x22, 0x7a, 0x08, 0x36, 0xf7, 0x3d, 0x46, 0xc4, 0x55, 0x42, 0x9d, 0x35, 0xb5, 0xb6,
-	0x54, 0xa0, 0x9f, 0x60, 0x9d, 0xd3, 0x59, 0x42, 0x85, 0xf1, 0xe4, 0x54, 0x77, 0x2a, 0xbb, 0xed,
-	0x83, 0x07, 0x7d, 0x1d, 0xa5, 0x7f, 0x52, 0x58, 0xc5, 0x37, 0xd0, 0xee, 0x18, 0xd6, 0x8b, 0x88,
-	0x2f, 0xcd, 0xc4, 0x1d, 0x40, 0x43, 0x7b, 0x42, 0x4f, 0xa0, 0xe7, 0x33, 0x41, 0x13, 0x46, 0x82,
-	0x11, 0x9b, 0xc7, 0x91, 0xcf, 0x84, 0x72, 0x65, 0x8f, 0x2d, 0x5c, 0x5a, 0x39, 0xb4, 0xa1, 0x39,
-	0x8b, 0x98, 0xa0, 0x4c, 0xb8, 0xff, 0xd8, 0xd0, 0x7d, 0xad, 0xd2, 0x9e, 0xe8, 0x8a, 0xa1, 0x2d,
-	0xa8, 0xb3, 0x88, 0xcd, 0xa8, 0xb2, 0xaf, 0x61, 0x2d, 0xc8, 0x14, 0x67, 0x0b, 0xc2, 0x18, 0x0d,
-	0xd2, 0x34, 0x8c, 0x88, 0xf6, 0xa0, 0x2a, 0x88, 0xa7, 0x6a, 0xb0, 0x7e, 0xf0, 0x95, 0xa9, 0x41,
-	0xc1, 0x67, 0xff, 0x94, 0x78, 0x58, 0xa2, 0xd0, 0x01, 0xb4, 0x48, 0xe0, 0x7f, 0xa4, 0x13, 0xee,
-	0x39, 0x75, 0x55, 0xb5, 0x2d, 0x63, 0x31, 0x50, 0x7a, 0x6d, 0x30, 0xb6, 0x70, 0x86, 0x43, 0xcf,
-	0xa0, 0x11, 0xd2, 0x10, 0xd3, 0x0f, 0x4e, 0x43, 0x59, 0x64, 0x31, 0x26, 0x34, 0x3c, 0xa7, 0x09,
-	0x5f, 0xf8, 0x31, 0xa6, 0x1f, 0xae, 0x28, 0x17, 0x63, 0x0b, 0xa7, 0x50, 0xf4, 0x3c, 0x35, 0xe2,
-	0x4e, 0x53, 0x19, 0x6d, 0xaf, 0x32, 0xe2, 0x71, 0xc4, 0x38, 0xcd, 0xac, 0x38, 0xda, 0x87, 0xe6,
-	0x9c, 0x08, 0x22, 0xb3, 0x6b, 0x29, 0xb3, 0x7b, 0xc6, 0x6c, 0x28, 0xd5, 0x59, 0x72, 0x06, 0x85,
-	0xf6, 0xa0, 0xbe, 0xa0, 0x41, 0x10, 0x39, 0x76, 0x11, 0xae, 0xb7, 0x3f, 0x96, 0x4b, 0x63, 0x0b,
-	0x6b, 0x0c, 0xea, 0x6b, 0xef, 0x43, 0xdf, 0x73, 0x40, 0xc1, 0x51, 0xde, 0xfb, 0xd0, 0xf7, 0xf4,
-	0x16, 0x0c, 0xc8, 0x64, 0x23, 0x77, 0xde, 0x2e, 0x67, 0xb3, 0xdc, 0xb3, 0x41, 0xa1, 0xe7, 0x00,
-	0xf2, 0xf3, 0x5d, 0x3c, 0x27, 0x82, 0x3a, 0x9d, 0x72, 0x0c, 0xbd, 0x32, 0xb6, 0x70, 0x0e, 0x87,
-	0xbe, 0x85, 0x3a, 0x0d, 0x63, 0x71, 0xed, 0x74, 0x95, 0x41, 0xd7, 0x18, 0x8c, 0xa4, 0x52, 0x66,
-	0xaf, 0x56, 0xd1, 0x1e, 0xd4, 0x66, 0x11, 0x63, 0xce, 0xba, 0x42, 0xdd, 0x37, 0xa8, 0x57, 0x11,
-	0x63, 0x23, 0x2e, 0xc8, 0x79, 0xe0, 0xf3, 0xc5, 0xd8, 0xc2, 0x0a, 0x84, 0x7e, 0x04, 0x9b, 0x0b,
-	0x22, 0xe8, 0x11, 0xbb, 0x88, 0x9c, 0x0d, 0x65, 0xb1, 0x99, 0x8d, 0x87, 0x59, 0x18, 0x5b, 0x78,
-	0x89, 0x42, 0x03, 0xe8, 0x2a, 0xe1, 0x84, 0x91, 0x98, 0x2f, 0x22, 0xe1, 0xf4, 0x8a, 0xa7, 0x9d,
-	0x99, 0x19, 0xc0, 0xd8, 0xc2, 0x45, 0x0b, 0xf4, 0x1b, 0xf4, 0x32, 0x7f, 0xc7, 0x57, 0x41, 0x20,
-	0x2b, 0xb7, 0xa9, 0xbc, 0x3c, 0x2c, 0x79, 0x49, 0xd7, 0xd3, 0x12, 0x96, 0xec, 0xd0, 0x2f, 0xd0,
-	0x51, 0xba, 0x14, 0xe3, 0xa0, 0x62, 0x1b, 0x61, 0x1a, 0x46, 0x82, 0x9e, 0xe4, 0x10, 0x63, 0x0b,
-	0x17, 0x2c, 0xd0, 0xab, 0x74, 0x43, 0xa6, 0xcf, 0x9c, 0x7b, 0xca, 0xc5, 0xd7, 0x2b, 0x5d, 0x64,
-	0xad, 0x58, 0xb4, 0x91, 0x55, 0x09, 0x28, 0x99, 0xeb, 0x8e, 0x95, 0x7d, 0xb9, 0x55, 0xac, 0xca,
-	0x9b, 0xe5, 0x62, 0xd6, 0x9d, 0x45, 0x0b, 0xf4, 0x12, 0x3a, 0x31, 0xa5, 0xc9, 0xd1, 0x9c, 0x32,
-	0xe1, 0x8b, 0x6b, 0xe7, 0x7e, 0x71, 0xee, 0x8e, 0x73, 0x6b, 0x72, 0x0f, 0x79, 0xac, 0x7b, 0x06,
-	0xd5, 0x53, 0xe2, 0xa1, 0x2e, 0xd8, 0xef, 0xa6, 0xc3, 0xd1, 0xaf, 0x47, 0xd3, 0xd1, 0xb0, 0x67,
-	0x21, 0x1b, 0xea, 0xa3, 0xc9, 0xf1, 0xe9, 0xfb, 0x5e, 0x05, 0x75, 0xa0, 0xf5, 0x16, 0xbf, 0x3e,
-	0x7b, 0x3b, 0x7d, 0xf3, 0xbe, 0xb7, 0x26, 0x71, 0xaf, 0xc6, 0x83, 0xa9, 0x16, 0xab, 0xa8, 0x07,
-	0x1d, 0x25, 0x0e, 0xa6, 0xc3, 0xb3, 0xb7, 0xf8, 0x75, 0xaf, 0x86, 0x36, 0xa0, 0xad, 0x01, 0x58,
-	0x29, 0xea, 0x79, 0x2a, 0x0a, 0xc1, 0xce, 0x4e, 0x07, 0x6d, 0x43, 0x2b, 0xa4, 0x82, 0xc8, 0x36,
-	0x4d, 0x39, 0x31, 0x93, 0x51, 0x1f, 0x6c, 0xe1, 0x87, 0x94, 0x0b, 0x12, 0xc6, 0x8a, 0x8d, 0xda,
-	0x07, 0xbd, 0xfc, 0x6e, 0x4e, 0xfd, 0x90, 0xe2, 0x25, 0x44, 0x32, 0x5a, 0x7c, 0xe9, 0x1f, 0x0d,
-	0x15, 0x47, 0x75, 0xb0, 0x16, 0xdc, 0x01, 0x6c, 0x96, 0x5a, 0x0a, 0x3d, 0x81, 0x16, 0x0d, 0x68,
-	0x48, 0x99, 0xe0, 0x4e, 0x65, 0xa7, 0x9a, 0xf7, 0x9c, 0xf1, 0x79, 0x86, 0x70, 0x1f, 0xc0, 0xd6,
-	0xaa, 0x7e, 0x72, 0x27, 0xd0, 0x2d, 0x8c, 0xc5, 0x32, 0x83, 0x4a, 0x2e, 0x03, 0x84, 0xa0, 0x36,
-	0xa3, 0x89, 0x48, 0x09, 0x55, 0x7d, 0x4b, 0xdd, 0x82, 0xf0, 0x45, 0x9a, 0xaa, 0xfa, 0x76, 0x4f,
-	0xa1, 0x93, 0x3f, 0xa4, 0x3b, 0x78, 0xcb, 0x57, 0xb1, 0x5a, 0xac, 0xa2, 0x1b, 0x40, 0x3b, 0x47,
-	0x23, 0xb7, 0xd3, 0xfe, 0x5c, 0xf1, 0x12, 0x77, 0xd6, 0x76, 0xaa, 0xbb, 0x36, 0x36, 0x22, 0x7a,
-	0x0a, 0xcd, 0x90, 0x7b, 0xa7, 0xd7, 0xe9, 0xf5, 0xb7, 0xbe, 0x24, 0x27, 0x59, 0x89, 0x89, 0x5e,
-	0xc2, 0x06, 0xe3, 0x32, 0x68, 0xe7, 0x38, 0xf1, 0x96, 0x68, 0xf9, 0x74, 0xd7, 0x6e, 0x1c, 0xfa,
-	0x1d, 0xe3, 0x7d, 0x02, 0x58, 0x12, 0xde, 0x2d, 0xe1, 0x1e, 0x41, 0x2d, 0x0d, 0xb5, 0xfa, 0xa0,
-	0x6b, 0x5f, 0x12, 0xf8, 0x52, 0x07, 0xd6, 0x6c, 0xfe, 0x7f, 0x57, 0xf5, 0x85, 0x3e, 0x43, 0x73,
-	0x75, 0x7f, 0x57, 0x7c, 0x47, 0xb4, 0x0f, 0x36, 0x32, 0x6b, 0xad, 0xce, 0x1e, 0x16, 0xee, 0x11,
-	0x34, 0x53, 0x1d, 0x7a, 0x00, 0x0d, 0x4e, 0x3f, 0x4c, 0xaf, 0xc2, 0x34, 0xc9, 0x54, 0xca, 0x5a,
-	0x51, 0x9e, 0x84, 0xad, 0x5b, 0x51, 0xea, 0x72, 0xcd, 0xa4, 0xbe, 0xdd, 0x7f, 0x2b, 0xd0, 0xc9,
-	0x5f, 0xde, 0xa8, 0x0f, 0x10, 0x66, 0xb7, 0x6c, 0x9a, 0xc9, 0x7a, 0xf1, 0xfe, 0xc5, 0x39, 0xc4,
-	0x9d, 0xe7, 0x79, 0x1b, 0x5a, 0xbe, 0x21, 0xb3, 0x9a, 0x6e, 0x13, 0x23, 0xbb, 0x7f, 0xc1, 0x66,
-	0x89, 0x12, 0x6f, 0x19, 0x98, 0xbb, 0x86, 0x7d, 0x04, 0x5d, 0x9f, 0x0f, 0xe9, 0x2c, 0x20, 0x09,
-	0x11, 0x7e, 0xc4, 0x54, 0x11, 0x5a, 0xb8, 0xa8, 0x74, 0x07, 0xd0, 0x32, 0xc6, 0xe8, 0x1b, 0x00,
-	0x9f, 0xcd, 0xce, 0xd8, 0x95, 0xdc, 0x6a, 0x5a, 0x5d, 0xdb, 0x67, 0xb3, 0xa9, 0x52, 0xe4, 0x0a,
-	0xbf, 0x96, 0x2f, 0xbc, 0x4b, 0x61, 0xb3, 0xf4, 0xb4, 0x41, 0x2f, 0x61, 0x83, 0xd3, 0xe0, 0x42,
-	0x52, 0x4d, 0x12, 0xea, 0xf8, 0x95, 0x62, 0xce, 0x59, 0xdf, 0xde, 0x04, 0xca, 0xfd, 0x5f, 0xb2,
-	0xe8, 0x13, 0x53, 0xdd, 0xd6, 0xc1, 0x5a, 0x70, 0xcf, 0x01, 0x95, 0x1f, 0x43, 0xe8, 0x31, 0xd4,
-	0xd5, 0xcb, 0xeb, 0x56, 0xfa, 0xd3, 0xcb, 0x6a, 0x78, 0x28, 0x99, 0x7f, 0x66, 0x78, 0x28, 0x99,
-	0xbb, 0xbf, 0x43, 0x43, 0xc7, 0x90, 0x87, 0x46, 0x0b, 0x2f, 0x53, 0x9c, 0xc9, 0x9f, 0x9d, 0xfb,
-	0xd5, 0xe4, 0xdd, 0x84, 0xba, 0x7a, 0x9e, 0xb8, 0x7d, 0x40, 0xe5, 0xab, 0x58, 0x0e, 0x98, 0xae,
-	0xa5, 0x66, 0xf1, 0x1a, 0x36, 0xa2, 0x7b, 0x08, 0xf7, 0x56, 0xdc, 0xbb, 0x68, 0x0f, 0x5a, 0xe9,
-	0x64, 0x18, 0xde, 0x2f, 0x8d, 0x4e, 0x06, 0xf8, 0xfe, 0x67, 0x68, 0xe7, 0xa6, 0x51, 0x5d, 0x8e,
-	0x6c, 0x4e, 0x2f, 0x7c, 0x46, 0xe7, 0x3d, 0x4b, 0x5e, 0x7a, 0x87, 0x41, 0x34, 0xbb, 0x4c, 0x9b,
-	0xaf, 0x57, 0x91, 0x97, 0x9e, 0xe1, 0xee, 0x09, 0xf7, 0x7a, 0x6b, 0x07, 0x7f, 0x42, 0x43, 0x93,
-	0x21, 0x7a, 0x01, 0x1d, 0xfd, 0x75, 0x22, 0x12, 0x4a, 0x42, 0x54, 0xaa, 0xe3, 0x76, 0x49, 0xe3,
-	0x5a, 0xbb, 0x95, 0x1f, 0x2a, 0xe8, 0x31, 0xd4, 0x8e, 0x7d, 0xe6, 0xa1, 0xe2, 0x73, 0x6d, 0xbb,
-	0x28, 0xba, 0xd6, 0xe1, 0xd3, 0x3f, 0xf6, 0x3c, 0x5f, 0x2c, 0xae, 0xce, 0xfb, 0xb3, 0x28, 0xdc,
-	0x5f, 0x5c, 0xc7, 0x34, 0x09, 0xe8, 0xdc, 0xa3, 0xc9, 0xfe, 0x05, 0x39, 0x4f, 0xfc, 0xd9, 0xbe,
-	0xfa, 0x43, 0xe2, 0xfb, 0xda, 0xec, 0xbc, 0xa1, 0xc4, 0x67, 0xff, 0x05, 0x00, 0x00, 0xff, 0xff,
-	0x9b, 0xa7, 0x27, 0x70, 0x48, 0x0d, 0x00, 0x00,
+	// 1411 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x57, 0x4f, 0x6f, 0xdb, 0xc6,
+	0x12, 0x17, 0xad, 0x3f, 0x96, 0x46, 0x92, 0x2d, 0x6f, 0x9c, 0x80, 0xcf, 0x2f, 0x0f, 0x10, 0x88,
+	0xbc, 0xc0, 0xef, 0x39, 0x91, 0x5b, 0x27, 0x87, 0x34, 0x87, 0xb6, 0xb2, 0xe5, 0x44, 0x6e, 0x63,
+	0xc7, 0x58, 0x3b, 0x05, 0xd2, 0x8b, 0xb1, 0xa6, 0xc6, 0x14, 0x6b, 0x72, 0xc9, 0x70, 0xd7, 0x09,
+	0x7c, 0xea, 0xb5, 0xe8, 0x17, 0xe8, 0xa1, 0xdf, 0xa8, 0x9f, 0xaa, 0xd8, 0x5d, 0x92, 0x22, 0x2d,
+	0x39, 0x80, 0x03, 0xf4, 0xc6, 0x99, 0xfd, 0xcd, 0xec, 0xcc, 0xec, 0xfc, 0x23, 0xac, 0x7b, 0x91,
+	0x10, 0x7e, 0xbc, 0x1d, 0xa2, 0x10, 0xcc, 0xc3, 0x41, 0x9c, 0x44, 0x32, 0x22, 0x0d, 0xc3, 0x75,
+	0xfe, 0xb2, 0xa0, 0xb9, 0xcf, 0x3f, 0x62, 0x10, 0xc5, 0x48, 0x6c, 0x58, 0x8e, 0xd9, 0x75, 0x10,
+	0xb1, 0x89, 0x6d, 0xf5, 0xad, 0xcd, 0x0e, 0xcd, 0x48, 0xf2, 0x10, 0x5a, 0xc2, 0xf7, 0x38, 0x93,
+	0x57, 0x09, 0xda, 0x4b, 0xfa, 0x6c, 0xc6, 0x20, 0xdf, 0xc2, 0x8a, 0x40, 0x37, 0x41, 0x99, 0x69,
+	0xb2, 0xab, 0x7d, 0x6b, 0xb3, 0xbd, 0xf3, 0x60, 0x60, 0x6e, 0x19, 0x9c, 0x94, 0x4e, 0xe9, 0x0d,
+	0x34, 0x21, 0x50, 0x9b, 0x46, 0xb1, 0xb0, 0x6b, 0x7d, 0x6b, 0xb3, 0x4b, 0xf5, 0x37, 0xe9, 0x43,
+	0xdb, 0x8d, 0xc2, 0x38, 0x41, 0x21, 0xfc, 0x88, 0xdb, 0xf5, 0xbe, 0xb5, 0xd9, 0xa2, 0x45, 0x16,
+	0xe9, 0x41, 0x35, 0x64, 0xae, 0xdd, 0xd0, 0xd6, 0xa8, 0x4f, 0x67, 0x0c, 0x2b, 0xe5, 0x9b, 0xbe,
+	0xd4, 0x23, 0x67, 0x08, 0x0d, 0xa3, 0x89, 0x3c, 0x81, 0x9e, 0xcf, 0x25, 0x26, 0x9c, 0x05, 0xfb,
+	0x7c, 0x12, 0x47, 0x3e, 0x97, 0x5a, 0x55, 0x6b, 0x5c, 0xa1, 0x73, 0x27, 0xbb, 0x2d, 0x58, 0x76,
+	0x23, 0x2e, 0x91, 0x4b, 0xe7, 0xb7, 0x16, 0x74, 0x5f, 0x6b, 0xf7, 0x0f, 0x4d, 0xe4, 0xc9, 0x3a,
+	0xd4, 0x79, 0xc4, 0x5d, 0xd4, 0xf2, 0x35, 0x6a, 0x08, 0x65, 0xa2, 0x3b, 0x65, 0x9c, 0x63, 0x90,
+	0x9a, 0x91, 0x91, 0x64, 0x0b, 0xaa, 0x92, 0x79, 0x3a, 0x96, 0x2b, 0x3b, 0xff, 0xca, 0x62, 0x59,
+	0xd2, 0x39, 0x38, 0x65, 0x1e, 0x55, 0x28, 0xb2, 0x03, 0x4d, 0x16, 0xf8, 0x1f, 0xf1, 0x50, 0x78,
+	0x3a, 0x58, 0xed, 0x9d, 0xf5, 0x4c, 0x62, 0xa8, 0xf9, 0x46, 0x60, 0x5c, 0xa1, 0x39, 0x8e, 0x3c,
+	0x83, 0x46, 0x88, 0x21, 0xc5, 0x0f, 0x3a, 0x88, 0xed, 0xd9, 0x1d, 0x87, 0x18, 0x9e, 0x63, 0x22,
+	0xa6, 0x7e, 0x4c, 0xf1, 0xc3, 0x15, 0x0a, 0x39, 0xae, 0xd0, 0x14, 0x4a, 0x9e, 0xa7, 0x42, 0xc2,
+	0x5e, 0xd6, 0x42, 0x1b, 0x8b, 0x84, 0x44, 0x1c, 0x71, 0x81, 0xb9, 0x94, 0x20, 0xdb, 0xb0, 0x3c,
+	0x61, 0x92, 0x29, 0xeb, 0x9a, 0x5a, 0xec, 0x5e, 0x26, 0x36, 0x52, 0xec, 0xdc, 0xb8, 0x0c, 0x45,
+	0xb6, 0xa0, 0x3e, 0xc5, 0x20, 0x88, 0xec, 0x56, 0x19, 0x6e, 0xdc, 0x1f, 0xab, 0xa3, 0x71, 0x85,
+	0x1a, 0x0c, 0x19, 0x18, 0xed, 0x23, 0xdf, 0xb3, 0x41, 0xc3, 0x49, 0x51, 0xfb, 0xc8, 0xf7, 0x8c,
+	0x0b, 0x19, 0x28, 0xb3, 0x46, 0x79, 0xde, 0x9e, 0xb7, 0x66, 0xe6, 0x73, 0x86, 0x22, 0xcf, 0x01,
+	0xd4, 0xe7, 0xbb, 0x78, 0xc2, 0x24, 0xda, 0x9d, 0xf9, 0x3b, 0xcc, 0xc9, 0xb8, 0x42, 0x0b, 0x38,
+	0xf2, 0x5f, 0xa8, 0x63, 0x18, 0xcb, 0x6b, 0xbb, 0xab, 0x05, 0xba, 0x99, 0xc0, 0xbe, 0x62, 0x2a,
+	0xeb, 0xf5, 0x29, 0xd9, 0x82, 0x9a, 0x1b, 0x71, 0x6e, 0xaf, 0x68, 0xd4, 0xfd, 0x0c, 0xb5, 0x17,
+	0x71, 0xbe, 0x2f, 0x24, 0x3b, 0x0f, 0x7c, 0x31, 0x1d, 0x57, 0xa8, 0x06, 0x91, 0xaf, 0xa1, 0x25,
+	0x24, 0x93, 0x78, 0xc0, 0x2f, 0x22, 0x7b, 0x55, 0x4b, 0xac, 0xe5, 0x65, 0x96, 0x1d, 0x8c, 0x2b,
+	0x74, 0x86, 0x22, 0x43, 0xe8, 0x6a, 0xe2, 0x84, 0xb3, 0x58, 0x4c, 0x23, 0x69, 0xf7, 0xca, 0xaf,
+	0x9d, 0x8b, 0x65, 0x80, 0x71, 0x85, 0x96, 0x25, 0xc8, 0x0f, 0xd0, 0xcb, 0xf5, 0x1d, 0x5f, 0x05,
+	0x81, 0x8a, 0xdc, 0x9a, 0xd6, 0xf2, 0x70, 0x4e, 0x4b, 0x7a, 0x9e, 0x86, 0x70, 0x4e, 0x8e, 0x7c,
+	0x0f, 0x1d, 0xcd, 0x4b, 0x31, 0x36, 0x29, 0xa7, 0x11, 0xc5, 0x30, 0x92, 0x78, 0x52, 0x40, 0x8c,
+	0x2b, 0xb4, 0x24, 0x41, 0xf6, 0x52, 0x87, 0xb2, 0x3c, 0xb3, 0xef, 0x69, 0x15, 0xff, 0x5e, 0xa8,
+	0x22, 0x4f, 0xc5, 0xb2, 0x8c, 0x8a, 0x4a, 0x80, 0x6c, 0x62, 0x32, 0x56, 0xe5, 0xe5, 0x7a, 0x39,
+	0x2a, 0x6f, 0x66, 0x87, 0x79, 0x76, 0x96, 0x25, 0xc8, 0x4b, 0xe8, 0xc4, 0x88, 0xc9, 0xc1, 0x04,
+	0xb9, 0xf4, 0xe5, 0xb5, 0x7d, 0xbf, 0x5c, 0x77, 0xc7, 0x85, 0x33, 0xe5, 0x43, 0x11, 0xeb, 0x9c,
+	0x41, 0xf5, 0x94, 0x79, 0xa4, 0x0b, 0xad, 0x77, 0x47, 0xa3, 0xfd, 0x57, 0x07, 0x47, 0xfb, 0xa3,
+	0x5e, 0x85, 0xb4, 0xa0, 0xbe, 0x7f, 0x78, 0x7c, 0xfa, 0xbe, 0x67, 0x91, 0x0e, 0x34, 0xdf, 0xd2,
+	0xd7, 0x67, 0x6f, 0x8f, 0xde, 0xbc, 0xef, 0x2d, 0x29, 0xdc, 0xde, 0x78, 0x78, 0x64, 0xc8, 0x2a,
+	0xe9, 0x41, 0x47, 0x93, 0xc3, 0xa3, 0xd1, 0xd9, 0x5b, 0xfa, 0xba, 0x57, 0x23, 0xab, 0xd0, 0x36,
+	0x00, 0xaa, 0x19, 0xf5, 0x62, 0x2b, 0x0a, 0xa1, 0x95, 0xbf, 0x0e, 0xd9, 0x80, 0x66, 0x88, 0x92,
+	0xa9, 0x34, 0x4d, 0x7b, 0x62, 0x4e, 0x93, 0x01, 0xb4, 0xa4, 0x1f, 0xa2, 0x90, 0x2c, 0x8c, 0x75,
+	0x37, 0x6a, 0xef, 0xf4, 0x8a, 0xde, 0x9c, 0xfa, 0x21, 0xd2, 0x19, 0x44, 0x75, 0xb4, 0xf8, 0xd2,
+	0x3f, 0x18, 0xe9, 0x1e, 0xd5, 0xa1, 0x86, 0x70, 0x86, 0xb0, 0x36, 0x97, 0x52, 0xe4, 0x09, 0x34,
+	0x31, 0xc0, 0x10, 0xb9, 0x14, 0xb6, 0xd5, 0xaf, 0x16, 0x35, 0xe7, 0x73, 0x21, 0x47, 0x38, 0x0f,
+	0x60, 0x7d, 0x51, 0x3e, 0x39, 0x7f, 0x58, 0xd0, 0x2d, 0xd5, 0xc5, 0xcc, 0x04, 0xab, 0x60, 0x82,
+	0x9a, 0x28, 0x2e, 0x26, 0x32, 0xed, 0xa8, 0xfa, 0x5b, 0x4f, 0x19, 0x26, 0xa6, 0xa9, 0xad, 0xfa,
+	0xfb, 0xe6, 0x94, 0xa9, 0xf5, 0xab, 0x37, 0xa7, 0xcc, 0x63, 0x33, 0x65, 0x6e, 0xb4, 0xd4, 0xc3,
+	0xe1, 0xde, 0x98, 0xf1, 0x89, 0x98, 0xb2, 0x4b, 0x34, 0xb3, 0xe7, 0x14, 0x3a, 0xc5, 0xf7, 0xbe,
+	0x83, 0x5d, 0xc5, 0x07, 0xa9, 0x96, 0x1f, 0xc4, 0x09, 0xa0, 0x5d, 0xe8, 0x48, 0xb7, 0x4f, 0x90,
+	0x89, 0x6e, 0x71, 0xc2, 0x5e, 0xd2, 0x0e, 0x64, 0x24, 0x79, 0x0a, 0xcb, 0xa1, 0xf0, 0x4e, 0xaf,
+	0xd3, 0x89, 0xbc, 0x32, 0xeb, 0x73, 0x2a, 0xa8, 0x87, 0xe6, 0x88, 0x66, 0x18, 0xe7, 0x4f, 0x0b,
+	0xda, 0x85, 0xfe, 0x7a, 0xcb, 0x75, 0x45, 0x7b, 0x97, 0x6e, 0x24, 0xd0, 0xdd, 0x2e, 0x24, 0x5b,
+	0xd0, 0xb8, 0xf0, 0x03, 0x89, 0x89, 0x5d, 0x2b, 0xb7, 0xe1, 0xdd, 0x20, 0x8a, 0xc2, 0x57, 0xfa,
+	0x88, 0xa6, 0x10, 0xe7, 0x13, 0xc0, 0xac, 0xd3, 0xde, 0x62, 0xdb, 0x23, 0xa8, 0xa5, 0x76, 0x2d,
+	0xce, 0xb0, 0xda, 0x17, 0x58, 0xe9, 0x5c, 0x02, 0xcc, 0xc6, 0xc8, 0x3f, 0xfd, 0x06, 0x2f, 0xcc,
+	0x8b, 0x67, 0x3b, 0xc3, 0xff, 0xca, 0x0b, 0x4c, 0x7b, 0x67, 0x35, 0x97, 0x36, 0xec, 0x7c, 0xa3,
+	0x71, 0x0e, 0x60, 0x39, 0xe5, 0x91, 0x07, 0xd0, 0x10, 0xf8, 0xe1, 0xe8, 0x2a, 0x4c, 0x8d, 0x4c,
+	0xa9, 0xbc, 0x04, 0x96, 0xf4, 0x36, 0xa5, 0xbf, 0x15, 0xaf, 0x90, 0x7a, 0xfa, 0xdb, 0xf9, 0xdd,
+	0x82, 0x4e, 0x71, 0x6b, 0x20, 0x03, 0x80, 0x30, 0x1f, 0xef, 0xa9, 0x25, 0x2b, 0xe5, 0xc1, 0x4f,
+	0x0b, 0x88, 0x3b, 0x37, 0x92, 0x0d, 0x68, 0xfa, 0x59, 0x17, 0xad, 0x99, 0x9c, 0xca, 0x68, 0xe7,
+	0x57, 0x58, 0x9b, 0xeb, 0xc5, 0xb7, 0x94, 0xd7, 0x5d, 0xaf, 0x7d, 0x04, 0x5d, 0x5f, 0x8c, 0xd0,
+	0x0d, 0x58, 0xc2, 0xa4, 0x6a, 0x00, 0x2a, 0x08, 0x4d, 0x5a, 0x66, 0x3a, 0x43, 0x68, 0x66, 0xc2,
+	0xe4, 0x3f, 0x00, 0x3e, 0x77, 0xcf, 0xf8, 0x95, 0x72, 0x35, 0x8d, 0x6e, 0xcb, 0xe7, 0xee, 0x91,
+	0x66, 0x14, 0x02, 0xbf, 0x54, 0x0c, 0xbc, 0x83, 0xb0, 0x36, 0xb7, 0x53, 0x91, 0x97, 0xb0, 0x2a,
+	0x30, 0xb8, 0x50, 0x3d, 0x2e, 0x09, 0xcd, 0xfd, 0x56, 0xdf, 0x5a, 0x98, 0xb7, 0x37, 0x81, 0xca,
+	0xff, 0x4b, 0x1e, 0x7d, 0xe2, 0x3a, 0xdb, 0x3a, 0xd4, 0x10, 0xce, 0x39, 0x90, 0xf9, 0x2d, 0x8c,
+	0x3c, 0x86, 0xba, 0x5e, 0xf9, 0x6e, 0xed, 0xbb, 0xe6, 0x58, 0x17, 0x0f, 0xb2, 0xc9, 0x67, 0x8a,
+	0x07, 0xd9, 0xc4, 0xf9, 0x09, 0x1a, 0xe6, 0x0e, 0xf5, 0x68, 0x58, 0x5a, 0x89, 0x69, 0x4e, 0x7f,
+	0xb6, 0x49, 0x2c, 0x9e, 0x1a, 0xcb, 0x50, 0xd7, 0x7b, 0x91, 0x33, 0x00, 0x32, 0xbf, 0x03, 0xa8,
+	0x02, 0x33, 0xb1, 0x34, 0xe3, 0xa3, 0x46, 0x33, 0xd2, 0xd9, 0x85, 0x7b, 0x0b, 0x06, 0x3e, 0xd9,
+	0x82, 0x66, 0x5a, 0x19, 0xd9, 0xc0, 0x99, 0x2b, 0x9d, 0x1c, 0xe0, 0x7c, 0x03, 0xed, 0x42, 0xcb,
+	0x51, 0x35, 0x71, 0xee, 0xeb, 0x41, 0xa5, 0x6b, 0x42, 0x7d, 0xab, 0xa7, 0x55, 0xf5, 0x82, 0x42,
+	0xfb, 0xd3, 0xa5, 0x29, 0xe5, 0x5c, 0x40, 0xa7, 0x38, 0x0d, 0x88, 0x03, 0x1d, 0x8c, 0xa7, 0x18,
+	0x62, 0xc2, 0x82, 0x1f, 0xf1, 0x3a, 0xd5, 0x51, 0xe2, 0xcd, 0x7a, 0x88, 0x09, 0x8d, 0x21, 0xca,
+	0xbf, 0x24, 0xd5, 0x1b, 0xbf, 0x24, 0xff, 0xff, 0x0e, 0xda, 0x85, 0x86, 0xa1, 0x17, 0x07, 0x3e,
+	0xc1, 0x0b, 0x9f, 0xe3, 0xa4, 0x57, 0x51, 0x0b, 0xc1, 0x6e, 0x10, 0xb9, 0x97, 0x69, 0x7d, 0xf4,
+	0x2c, 0xb5, 0x10, 0x64, 0xc3, 0xe8, 0x50, 0x78, 0xbd, 0xa5, 0x9d, 0x5f, 0xa0, 0x61, 0x9a, 0x3b,
+	0x79, 0x01, 0x1d, 0xf3, 0x75, 0x22, 0x13, 0x64, 0x21, 0x99, 0x7b, 0xea, 0x8d, 0x39, 0x8e, 0x53,
+	0xd9, 0xb4, 0xbe, 0xb2, 0xc8, 0x63, 0xa8, 0x1d, 0xfb, 0xdc, 0x23, 0xe5, 0x55, 0x76, 0xa3, 0x4c,
+	0x3a, 0x95, 0xdd, 0xa7, 0x3f, 0x6f, 0x79, 0xbe, 0x9c, 0x5e, 0x9d, 0x0f, 0xdc, 0x28, 0xdc, 0x9e,
+	0x5e, 0xc7, 0x98, 0x04, 0x38, 0xf1, 0x30, 0xd9, 0xbe, 0x60, 0xe7, 0x89, 0xef, 0x6e, 0xeb, 0xbf,
+	0x50, 0xb1, 0x6d, 0xc4, 0xce, 0x1b, 0x9a, 0x7c, 0xf6, 0xf7, 0x00, 0x40, 0x06, 0x66, 0x99, 0xac,
+	0x0e, 0x00, 0x00,
 }
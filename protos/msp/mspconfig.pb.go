@@ -14,6 +14,8 @@ It has these top-level messages:
 	SigningIdentityInfo
 	KeyInfo
 	FabricOUIdentifier
+	FabricNodeOUs
+	MSPConfigBundle
 */
 package msp
 
@@ -88,6 +90,10 @@ type FabricMSPConfig struct {
 	// fabric organizational unit identifiers that belong to
 	// this MSP configuration
 	OrganizationalUnitIdentifiers []*FabricOUIdentifier `protobuf:"bytes,7,rep,name=organizational_unit_identifiers,json=organizationalUnitIdentifiers" json:"organizational_unit_identifiers,omitempty"`
+	// FabricNodeOUs configures how identities are classified,
+	// based on their organizational units, into clients, peers,
+	// admins and orderers
+	FabricNodeOus *FabricNodeOUs `protobuf:"bytes,8,opt,name=fabric_node_ous,json=fabricNodeOus" json:"fabric_node_ous,omitempty"`
 }
 
 func (m *FabricMSPConfig) Reset()                    { *m = FabricMSPConfig{} }
@@ -109,6 +115,13 @@ func (m *FabricMSPConfig) GetOrganizationalUnitIdentifiers() []*FabricOUIdentifi
 	return nil
 }
 
+func (m *FabricMSPConfig) GetFabricNodeOus() *FabricNodeOUs {
+	if m != nil {
+		return m.FabricNodeOus
+	}
+	return nil
+}
+
 // SigningIdentityInfo represents the configuration information
 // related to the signing identity the peer is to use for generating
 // endorsements
@@ -169,45 +182,122 @@ func (m *FabricOUIdentifier) String() string            { return proto.CompactTe
 func (*FabricOUIdentifier) ProtoMessage()               {}
 func (*FabricOUIdentifier) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
 
+// FabricNodeOUs defines the organizational units identifying
+// the clients, peers, admins and orderers of an MSP.
+type FabricNodeOUs struct {
+	// Enable enables the classification of identities by organizational unit
+	Enable bool `protobuf:"varint,1,opt,name=enable" json:"enable,omitempty"`
+	// ClientOuIdentifier represents the OU identifier of the clients
+	ClientOuIdentifier *FabricOUIdentifier `protobuf:"bytes,2,opt,name=client_ou_identifier,json=clientOuIdentifier" json:"client_ou_identifier,omitempty"`
+	// PeerOuIdentifier represents the OU identifier of the peers
+	PeerOuIdentifier *FabricOUIdentifier `protobuf:"bytes,3,opt,name=peer_ou_identifier,json=peerOuIdentifier" json:"peer_ou_identifier,omitempty"`
+	// AdminOuIdentifier represents the OU identifier of the admins
+	AdminOuIdentifier *FabricOUIdentifier `protobuf:"bytes,4,opt,name=admin_ou_identifier,json=adminOuIdentifier" json:"admin_ou_identifier,omitempty"`
+	// OrdererOuIdentifier represents the OU identifier of the orderers
+	OrdererOuIdentifier *FabricOUIdentifier `protobuf:"bytes,5,opt,name=orderer_ou_identifier,json=ordererOuIdentifier" json:"orderer_ou_identifier,omitempty"`
+}
+
+func (m *FabricNodeOUs) Reset()                    { *m = FabricNodeOUs{} }
+func (m *FabricNodeOUs) String() string            { return proto.CompactTextString(m) }
+func (*FabricNodeOUs) ProtoMessage()               {}
+func (*FabricNodeOUs) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+
+func (m *FabricNodeOUs) GetClientOuIdentifier() *FabricOUIdentifier {
+	if m != nil {
+		return m.ClientOuIdentifier
+	}
+	return nil
+}
+
+func (m *FabricNodeOUs) GetPeerOuIdentifier() *FabricOUIdentifier {
+	if m != nil {
+		return m.PeerOuIdentifier
+	}
+	return nil
+}
+
+func (m *FabricNodeOUs) GetAdminOuIdentifier() *FabricOUIdentifier {
+	if m != nil {
+		return m.AdminOuIdentifier
+	}
+	return nil
+}
+
+func (m *FabricNodeOUs) GetOrdererOuIdentifier() *FabricOUIdentifier {
+	if m != nil {
+		return m.OrdererOuIdentifier
+	}
+	return nil
+}
+
+// MSPConfigBundle is an MSP configuration signed by an identity,
+// so that it can be distributed out-of-band and checked before
+// being used in channel configuration updates
+type MSPConfigBundle struct {
+	// Config is the marshalled MSPConfig
+	Config []byte `protobuf:"bytes,1,opt,name=config,proto3" json:"config,omitempty"`
+	// Signer is the serialized identity that signed Config
+	Signer []byte `protobuf:"bytes,2,opt,name=signer,proto3" json:"signer,omitempty"`
+	// Signature is the signature of Signer over Config
+	Signature []byte `protobuf:"bytes,3,opt,name=signature,proto3" json:"signature,omitempty"`
+}
+
+func (m *MSPConfigBundle) Reset()                    { *m = MSPConfigBundle{} }
+func (m *MSPConfigBundle) String() string            { return proto.CompactTextString(m) }
+func (*MSPConfigBundle) ProtoMessage()               {}
+func (*MSPConfigBundle) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+
 func init() {
 	proto.RegisterType((*MSPConfig)(nil), "msp.MSPConfig")
 	proto.RegisterType((*FabricMSPConfig)(nil), "msp.FabricMSPConfig")
 	proto.RegisterType((*SigningIdentityInfo)(nil), "msp.SigningIdentityInfo")
 	proto.RegisterType((*KeyInfo)(nil), "msp.KeyInfo")
 	proto.RegisterType((*FabricOUIdentifier)(nil), "msp.FabricOUIdentifier")
+	proto.RegisterType((*FabricNodeOUs)(nil), "msp.FabricNodeOUs")
+	proto.RegisterType((*MSPConfigBundle)(nil), "msp.MSPConfigBundle")
 }
 
 func init() { proto.RegisterFile("msp/mspconfig.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 458 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x7c, 0x53, 0xdd, 0x6e, 0xd3, 0x30,
-	0x14, 0x56, 0xda, 0xad, 0x53, 0x4f, 0xd3, 0x16, 0x3c, 0x18, 0xb9, 0x60, 0x10, 0x82, 0x10, 0x11,
-	0x12, 0xad, 0xb4, 0x5e, 0x70, 0x4f, 0x11, 0x52, 0x05, 0x13, 0x28, 0xd5, 0x6e, 0xb8, 0x89, 0xd2,
-	0xd4, 0xcd, 0x8e, 0x1a, 0xff, 0xc8, 0x76, 0x27, 0x85, 0x97, 0xe0, 0xfd, 0x78, 0x1a, 0x14, 0xdb,
-	0xa2, 0x2d, 0x4c, 0xbb, 0x3b, 0xfe, 0x7e, 0x4e, 0xbe, 0x73, 0x1c, 0xc3, 0x39, 0xd3, 0x72, 0xca,
-	0xb4, 0x2c, 0x05, 0xdf, 0x60, 0x35, 0x91, 0x4a, 0x18, 0x41, 0xba, 0x4c, 0xcb, 0xe4, 0x03, 0xf4,
-	0xaf, 0x97, 0xdf, 0xe7, 0x16, 0x27, 0x04, 0x4e, 0x4c, 0x23, 0x69, 0x14, 0xc4, 0x41, 0x7a, 0x9a,
-	0xd9, 0x9a, 0x5c, 0x40, 0xcf, 0xb9, 0xa2, 0x4e, 0x1c, 0xa4, 0x61, 0xe6, 0x4f, 0xc9, 0xef, 0x0e,
-	0x8c, 0x3f, 0x17, 0x2b, 0x85, 0xe5, 0x91, 0x9f, 0x17, 0xcc, 0xf9, 0xfb, 0x99, 0xad, 0xc9, 0x25,
-	0x80, 0x12, 0xc2, 0xe4, 0x25, 0x55, 0x46, 0x47, 0x9d, 0xb8, 0x9b, 0x86, 0x59, 0xbf, 0x45, 0xe6,
-	0x2d, 0x40, 0xde, 0x03, 0x41, 0x6e, 0xa8, 0x62, 0x74, 0x8d, 0x85, 0xa1, 0x5e, 0xd6, 0xb5, 0xb2,
-	0xc7, 0x87, 0x8c, 0x93, 0x5f, 0x40, 0xaf, 0x58, 0x33, 0xe4, 0x3a, 0x3a, 0xb1, 0x12, 0x7f, 0x22,
-	0x6f, 0x61, 0xac, 0xe8, 0x9d, 0x28, 0x0b, 0x83, 0x82, 0xe7, 0x35, 0x6a, 0x13, 0x9d, 0x5a, 0xc1,
-	0x68, 0x0f, 0x7f, 0x45, 0x6d, 0xc8, 0x1c, 0x1e, 0x69, 0xac, 0x38, 0xf2, 0x2a, 0xc7, 0x35, 0xe5,
-	0x06, 0x4d, 0x13, 0xf5, 0xe2, 0x20, 0x1d, 0x5c, 0x45, 0x13, 0xa6, 0xe5, 0x64, 0xe9, 0xc8, 0x85,
-	0xe7, 0x16, 0x7c, 0x23, 0xb2, 0xb1, 0x3e, 0x06, 0x49, 0x0e, 0x2f, 0x85, 0xaa, 0x0a, 0x8e, 0x3f,
-	0x6d, 0xe3, 0xa2, 0xce, 0x77, 0x1c, 0x8d, 0x6f, 0xb8, 0x41, 0xaa, 0x74, 0x74, 0x16, 0x77, 0xd3,
-	0xc1, 0xd5, 0x33, 0xdb, 0xd3, 0xad, 0xe9, 0xdb, 0xcd, 0xe2, 0x2f, 0x9f, 0x5d, 0x1e, 0xfb, 0x6f,
-	0x38, 0x9a, 0x3d, 0xab, 0x13, 0x01, 0xe7, 0xf7, 0x04, 0x21, 0xaf, 0x61, 0x28, 0x77, 0xab, 0x1a,
-	0xcb, 0xbc, 0x4d, 0x44, 0x95, 0x5d, 0x74, 0x98, 0x85, 0x0e, 0x5c, 0x5a, 0x8c, 0xcc, 0x60, 0x24,
-	0x15, 0xde, 0xb5, 0xcb, 0xf4, 0xaa, 0x8e, 0x9d, 0x2f, 0xb4, 0x59, 0xbe, 0x50, 0x37, 0xd3, 0xd0,
-	0x6b, 0x9c, 0x29, 0x59, 0xc2, 0x99, 0x67, 0xc8, 0x1b, 0x18, 0x6d, 0x69, 0x73, 0x30, 0x8c, 0xbf,
-	0xce, 0xe1, 0x96, 0x36, 0xfb, 0x8c, 0xe4, 0x15, 0x84, 0xad, 0x8c, 0x15, 0x86, 0x2a, 0x2c, 0x6a,
-	0xff, 0x77, 0x0c, 0xb6, 0xb4, 0xb9, 0xf6, 0x50, 0xf2, 0x2b, 0x00, 0xf2, 0xff, 0xec, 0x64, 0x06,
-	0x4f, 0xdb, 0x5b, 0x76, 0xa3, 0xfe, 0xfb, 0x9d, 0x30, 0x7b, 0xb2, 0x27, 0x0f, 0x4c, 0x9f, 0xe0,
-	0xc5, 0xc3, 0x2b, 0xb7, 0x01, 0xfa, 0xd9, 0xf3, 0x87, 0x16, 0xfb, 0xf1, 0xdd, 0x8f, 0xb4, 0x42,
-	0x73, 0xbb, 0x5b, 0x4d, 0x4a, 0xc1, 0xa6, 0xb7, 0x8d, 0xa4, 0xaa, 0xa6, 0xeb, 0x8a, 0xaa, 0xe9,
-	0xc6, 0xe6, 0x9c, 0xda, 0x97, 0xa1, 0xdb, 0xa7, 0xb2, 0xea, 0xd9, 0x7a, 0xf6, 0x27, 0x00, 0x00,
-	0xff, 0xff, 0x35, 0x7e, 0x6d, 0x6e, 0x3c, 0x03, 0x00, 0x00,
+	// 611 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7c, 0x54, 0x5f, 0x4f, 0xdb, 0x3e,
+	0x14, 0x55, 0x5b, 0x28, 0xf4, 0x92, 0x52, 0x70, 0x81, 0x5f, 0x1e, 0xe0, 0xb7, 0x2e, 0xd3, 0xb4,
+	0x6a, 0xd2, 0x5a, 0x09, 0x1e, 0x26, 0xed, 0x11, 0xf6, 0x47, 0x15, 0x63, 0x4c, 0xae, 0x78, 0xd9,
+	0x4b, 0x94, 0x26, 0xb7, 0xc5, 0x22, 0xb1, 0x23, 0xdb, 0x41, 0xea, 0xbe, 0xc4, 0xde, 0xf6, 0xb5,
+	0xf6, 0x95, 0xa6, 0x38, 0x16, 0x49, 0xca, 0x94, 0x37, 0xdf, 0x73, 0xcf, 0x39, 0xb6, 0xaf, 0xaf,
+	0x2f, 0x0c, 0x13, 0x95, 0x4e, 0x13, 0x95, 0x86, 0x82, 0x2f, 0xd9, 0x6a, 0x92, 0x4a, 0xa1, 0x05,
+	0xe9, 0x24, 0x2a, 0xf5, 0xde, 0x43, 0xef, 0x66, 0xfe, 0xfd, 0xca, 0xe0, 0x84, 0xc0, 0x96, 0x5e,
+	0xa7, 0xe8, 0xb6, 0x46, 0xad, 0xf1, 0x36, 0x35, 0x6b, 0x72, 0x02, 0xdd, 0x42, 0xe5, 0xb6, 0x47,
+	0xad, 0xb1, 0x43, 0x6d, 0xe4, 0xfd, 0xee, 0xc0, 0xe0, 0x73, 0xb0, 0x90, 0x2c, 0xac, 0xe9, 0x79,
+	0x90, 0x14, 0xfa, 0x1e, 0x35, 0x6b, 0x72, 0x06, 0x20, 0x85, 0xd0, 0x7e, 0x88, 0x52, 0x2b, 0xb7,
+	0x3d, 0xea, 0x8c, 0x1d, 0xda, 0xcb, 0x91, 0xab, 0x1c, 0x20, 0xef, 0x80, 0x30, 0xae, 0x51, 0x26,
+	0x18, 0xb1, 0x40, 0xa3, 0xa5, 0x75, 0x0c, 0xed, 0xb0, 0x9a, 0x29, 0xe8, 0x27, 0xd0, 0x0d, 0xa2,
+	0x84, 0x71, 0xe5, 0x6e, 0x19, 0x8a, 0x8d, 0xc8, 0x1b, 0x18, 0x48, 0x7c, 0x14, 0x61, 0xa0, 0x99,
+	0xe0, 0x7e, 0xcc, 0x94, 0x76, 0xb7, 0x0d, 0x61, 0xbf, 0x84, 0xbf, 0x32, 0xa5, 0xc9, 0x15, 0x1c,
+	0x28, 0xb6, 0xe2, 0x8c, 0xaf, 0x7c, 0x16, 0x21, 0xd7, 0x4c, 0xaf, 0xdd, 0xee, 0xa8, 0x35, 0xde,
+	0x3b, 0x77, 0x27, 0x89, 0x4a, 0x27, 0xf3, 0x22, 0x39, 0xb3, 0xb9, 0x19, 0x5f, 0x0a, 0x3a, 0x50,
+	0x75, 0x90, 0xf8, 0xf0, 0x42, 0xc8, 0x55, 0xc0, 0xd9, 0x4f, 0x63, 0x1c, 0xc4, 0x7e, 0xc6, 0x99,
+	0xb6, 0x86, 0x4b, 0x86, 0x52, 0xb9, 0x3b, 0xa3, 0xce, 0x78, 0xef, 0xfc, 0x3f, 0xe3, 0x59, 0x94,
+	0xe9, 0xf6, 0x6e, 0xf6, 0x94, 0xa7, 0x67, 0x75, 0xfd, 0x1d, 0x67, 0xba, 0xcc, 0x2a, 0xf2, 0x01,
+	0x06, 0x4b, 0x23, 0xf2, 0xb9, 0x88, 0xd0, 0x17, 0x99, 0x72, 0x77, 0xcd, 0x21, 0x49, 0xc5, 0xf0,
+	0x9b, 0x88, 0xf0, 0xf6, 0x4e, 0xd1, 0xfe, 0xb2, 0x0c, 0x33, 0xe5, 0x09, 0x18, 0xfe, 0xe3, 0x12,
+	0xe4, 0x15, 0xf4, 0xd3, 0x6c, 0x11, 0xb3, 0xd0, 0xcf, 0x6f, 0x83, 0xd2, 0x3c, 0x92, 0x43, 0x9d,
+	0x02, 0x9c, 0x1b, 0x8c, 0x5c, 0xc0, 0x7e, 0x2a, 0xd9, 0x63, 0xfe, 0x10, 0x96, 0xd5, 0x36, 0xdb,
+	0x3a, 0x66, 0xdb, 0x6b, 0x2c, 0xea, 0xd1, 0xb7, 0x9c, 0x42, 0xe4, 0xcd, 0x61, 0xc7, 0x66, 0xc8,
+	0x6b, 0xd8, 0x7f, 0xc0, 0x75, 0xa5, 0x10, 0xb6, 0x15, 0xfa, 0x0f, 0xb8, 0x2e, 0xef, 0x47, 0x5e,
+	0x82, 0x93, 0xd3, 0x92, 0x40, 0xa3, 0x64, 0x41, 0x6c, 0x3b, 0x6b, 0xef, 0x01, 0xd7, 0x37, 0x16,
+	0xf2, 0x7e, 0xb5, 0x80, 0x3c, 0xaf, 0x1b, 0xb9, 0x80, 0xe3, 0xbc, 0x43, 0x4c, 0xa0, 0x36, 0xf7,
+	0x71, 0xe8, 0x51, 0x99, 0xac, 0x88, 0x3e, 0xc2, 0xff, 0xcd, 0xcf, 0x65, 0x0e, 0xd0, 0xa3, 0xa7,
+	0x4d, 0x8f, 0xe2, 0xfd, 0x69, 0x43, 0xbf, 0x56, 0xf8, 0xbc, 0x19, 0x91, 0x07, 0x8b, 0xb8, 0x68,
+	0xf8, 0x5d, 0x6a, 0x23, 0x32, 0x83, 0xa3, 0x30, 0x66, 0xc8, 0xb5, 0x2f, 0xb2, 0xcd, 0x5d, 0x1a,
+	0x7a, 0x82, 0x14, 0xa2, 0xdb, 0xac, 0x72, 0xf4, 0x4f, 0x40, 0x52, 0x44, 0xb9, 0x61, 0xd4, 0x69,
+	0x36, 0x3a, 0xc8, 0x25, 0x35, 0x9b, 0x2f, 0x30, 0x34, 0x1f, 0x65, 0xc3, 0x67, 0xab, 0xd9, 0xe7,
+	0xd0, 0x68, 0x6a, 0x46, 0xd7, 0x70, 0x2c, 0x64, 0x84, 0xf2, 0xd9, 0x91, 0xb6, 0x9b, 0xad, 0x86,
+	0x56, 0x55, 0x35, 0xf3, 0x7c, 0x18, 0x3c, 0xcd, 0x8e, 0xcb, 0x8c, 0x47, 0x71, 0x75, 0xda, 0xb4,
+	0xaa, 0xd3, 0x26, 0xc7, 0x2b, 0x0d, 0xe9, 0x50, 0x1b, 0x91, 0x53, 0xe8, 0xe5, 0xab, 0x40, 0x67,
+	0x12, 0x4d, 0x59, 0x1c, 0x5a, 0x02, 0x97, 0x6f, 0x7f, 0x8c, 0x57, 0x4c, 0xdf, 0x67, 0x8b, 0x49,
+	0x28, 0x92, 0xe9, 0xfd, 0x3a, 0x45, 0x19, 0x63, 0xb4, 0x42, 0x39, 0x2d, 0xbe, 0xcc, 0xd4, 0x0c,
+	0x42, 0x95, 0x4f, 0xc6, 0x45, 0xd7, 0xac, 0x2f, 0xfe, 0x0e, 0x00, 0x2c, 0x09, 0x11, 0x0b, 0x2b,
+	0x05, 0x00, 0x00,
 }
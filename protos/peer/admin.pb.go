@@ -6,6 +6,7 @@
 Package peer is a generated protocol buffer package.
 
 It is generated from these files:
+
 	peer/admin.proto
 	peer/chaincode.proto
 	peer/chaincodeevent.proto
@@ -19,9 +20,19 @@ It is generated from these files:
 	peer/transaction.proto
 
 It has these top-level messages:
+
 	ServerStatus
 	LogLevelRequest
 	LogLevelResponse
+	LocalMSPResponse
+	MSPAuditLogRequest
+	MSPAuditRecord
+	MSPAuditLogResponse
+	LeadershipRequest
+	LeadershipState
+	LeadershipOverrideRequest
+	GossipMember
+	GossipMembership
 	ChaincodeID
 	ChaincodeInput
 	ChaincodeSpec
@@ -74,6 +85,7 @@ import proto "github.com/golang/protobuf/proto"
 import fmt "fmt"
 import math "math"
 import google_protobuf "github.com/golang/protobuf/ptypes/empty"
+import google_protobuf1 "github.com/golang/protobuf/ptypes/timestamp"
 
 import (
 	context "golang.org/x/net/context"
@@ -124,6 +136,33 @@ func (x ServerStatus_StatusCode) String() string {
 }
 func (ServerStatus_StatusCode) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{0, 0} }
 
+// Override is a manual override of the leader election
+type LeadershipState_Override int32
+
+const (
+	LeadershipState_NONE LeadershipState_Override = 0
+	// The peer relinquishes the leadership and abstains from the election
+	LeadershipState_RELINQUISHED LeadershipState_Override = 1
+	// The peer holds the leadership regardless of the election
+	LeadershipState_ACQUIRED LeadershipState_Override = 2
+)
+
+var LeadershipState_Override_name = map[int32]string{
+	0: "NONE",
+	1: "RELINQUISHED",
+	2: "ACQUIRED",
+}
+var LeadershipState_Override_value = map[string]int32{
+	"NONE":         0,
+	"RELINQUISHED": 1,
+	"ACQUIRED":     2,
+}
+
+func (x LeadershipState_Override) String() string {
+	return proto.EnumName(LeadershipState_Override_name, int32(x))
+}
+func (LeadershipState_Override) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{8, 0} }
+
 type ServerStatus struct {
 	Status ServerStatus_StatusCode `protobuf:"varint,1,opt,name=status,enum=protos.ServerStatus_StatusCode" json:"status,omitempty"`
 }
@@ -153,11 +192,177 @@ func (m *LogLevelResponse) String() string            { return proto.CompactText
 func (*LogLevelResponse) ProtoMessage()               {}
 func (*LogLevelResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
 
+// LocalMSPResponse describes the local MSP of the peer
+type LocalMSPResponse struct {
+	// MspId is the identifier of the local MSP
+	MspId string `protobuf:"bytes,1,opt,name=msp_id,json=mspId" json:"msp_id,omitempty"`
+	// SigningIdentity is the serialized default signing identity of the local MSP
+	SigningIdentity []byte `protobuf:"bytes,2,opt,name=signing_identity,json=signingIdentity,proto3" json:"signing_identity,omitempty"`
+}
+
+func (m *LocalMSPResponse) Reset()                    { *m = LocalMSPResponse{} }
+func (m *LocalMSPResponse) String() string            { return proto.CompactTextString(m) }
+func (*LocalMSPResponse) ProtoMessage()               {}
+func (*LocalMSPResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+
+// MSPAuditLogRequest selects the decisions of the MSP audit log to return
+type MSPAuditLogRequest struct {
+	// Channel, if not empty, restricts the decisions to those of the
+	// MSPs of a channel. Decisions of the local MSP have no channel.
+	Channel string `protobuf:"bytes,1,opt,name=channel" json:"channel,omitempty"`
+}
+
+func (m *MSPAuditLogRequest) Reset()                    { *m = MSPAuditLogRequest{} }
+func (m *MSPAuditLogRequest) String() string            { return proto.CompactTextString(m) }
+func (*MSPAuditLogRequest) ProtoMessage()               {}
+func (*MSPAuditLogRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+
+// MSPAuditRecord is a decision taken by an MSP about an identity
+type MSPAuditRecord struct {
+	Timestamp *google_protobuf1.Timestamp `protobuf:"bytes,1,opt,name=timestamp" json:"timestamp,omitempty"`
+	MspId     string                      `protobuf:"bytes,2,opt,name=msp_id,json=mspId" json:"msp_id,omitempty"`
+	Channel   string                      `protobuf:"bytes,3,opt,name=channel" json:"channel,omitempty"`
+	// IdentityHash is the SHA-256 hash of the serialized identity
+	IdentityHash []byte `protobuf:"bytes,4,opt,name=identity_hash,json=identityHash,proto3" json:"identity_hash,omitempty"`
+	// Operation is either Validate or SatisfiesPrincipal
+	Operation string `protobuf:"bytes,5,opt,name=operation" json:"operation,omitempty"`
+	// Principal is the classification of the principal checked, if any
+	Principal string `protobuf:"bytes,6,opt,name=principal" json:"principal,omitempty"`
+	Allowed   bool   `protobuf:"varint,7,opt,name=allowed" json:"allowed,omitempty"`
+	// Reason is why the identity was not allowed, if it was not
+	Reason string `protobuf:"bytes,8,opt,name=reason" json:"reason,omitempty"`
+}
+
+func (m *MSPAuditRecord) Reset()                    { *m = MSPAuditRecord{} }
+func (m *MSPAuditRecord) String() string            { return proto.CompactTextString(m) }
+func (*MSPAuditRecord) ProtoMessage()               {}
+func (*MSPAuditRecord) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+
+func (m *MSPAuditRecord) GetTimestamp() *google_protobuf1.Timestamp {
+	if m != nil {
+		return m.Timestamp
+	}
+	return nil
+}
+
+// MSPAuditLogResponse holds the decisions of the MSP audit log, oldest first
+type MSPAuditLogResponse struct {
+	Records []*MSPAuditRecord `protobuf:"bytes,1,rep,name=records" json:"records,omitempty"`
+}
+
+func (m *MSPAuditLogResponse) Reset()                    { *m = MSPAuditLogResponse{} }
+func (m *MSPAuditLogResponse) String() string            { return proto.CompactTextString(m) }
+func (*MSPAuditLogResponse) ProtoMessage()               {}
+func (*MSPAuditLogResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+
+func (m *MSPAuditLogResponse) GetRecords() []*MSPAuditRecord {
+	if m != nil {
+		return m.Records
+	}
+	return nil
+}
+
+// LeadershipRequest selects the channel whose leader election to return
+type LeadershipRequest struct {
+	Channel string `protobuf:"bytes,1,opt,name=channel" json:"channel,omitempty"`
+}
+
+func (m *LeadershipRequest) Reset()                    { *m = LeadershipRequest{} }
+func (m *LeadershipRequest) String() string            { return proto.CompactTextString(m) }
+func (*LeadershipRequest) ProtoMessage()               {}
+func (*LeadershipRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
+
+// LeadershipState is the state of the leader election of a channel, as seen by the peer
+type LeadershipState struct {
+	Channel  string `protobuf:"bytes,1,opt,name=channel" json:"channel,omitempty"`
+	IsLeader bool   `protobuf:"varint,2,opt,name=is_leader,json=isLeader" json:"is_leader,omitempty"`
+	// Leader is the PKI-ID of the current leader, empty if no leader is known
+	Leader []byte `protobuf:"bytes,3,opt,name=leader,proto3" json:"leader,omitempty"`
+	// Term is incremented every time the leader known to the peer changes
+	Term     uint64                   `protobuf:"varint,4,opt,name=term" json:"term,omitempty"`
+	Override LeadershipState_Override `protobuf:"varint,5,opt,name=override,enum=protos.LeadershipState_Override" json:"override,omitempty"`
+}
+
+func (m *LeadershipState) Reset()                    { *m = LeadershipState{} }
+func (m *LeadershipState) String() string            { return proto.CompactTextString(m) }
+func (*LeadershipState) ProtoMessage()               {}
+func (*LeadershipState) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
+
+// LeadershipOverrideRequest overrides the leader election of a channel,
+// or ends the override in effect with NONE
+type LeadershipOverrideRequest struct {
+	Channel  string                   `protobuf:"bytes,1,opt,name=channel" json:"channel,omitempty"`
+	Override LeadershipState_Override `protobuf:"varint,2,opt,name=override,enum=protos.LeadershipState_Override" json:"override,omitempty"`
+}
+
+func (m *LeadershipOverrideRequest) Reset()                    { *m = LeadershipOverrideRequest{} }
+func (m *LeadershipOverrideRequest) String() string            { return proto.CompactTextString(m) }
+func (*LeadershipOverrideRequest) ProtoMessage()               {}
+func (*LeadershipOverrideRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
+
+// GossipMember is a peer in the gossip membership view
+type GossipMember struct {
+	PkiId            []byte `protobuf:"bytes,1,opt,name=pki_id,json=pkiId,proto3" json:"pki_id,omitempty"`
+	Endpoint         string `protobuf:"bytes,2,opt,name=endpoint" json:"endpoint,omitempty"`
+	InternalEndpoint string `protobuf:"bytes,3,opt,name=internal_endpoint,json=internalEndpoint" json:"internal_endpoint,omitempty"`
+	Metadata         []byte `protobuf:"bytes,4,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	Alive            bool   `protobuf:"varint,5,opt,name=alive" json:"alive,omitempty"`
+	// LastSeen is the last time the peer was known to be alive
+	LastSeen *google_protobuf1.Timestamp `protobuf:"bytes,6,opt,name=last_seen,json=lastSeen" json:"last_seen,omitempty"`
+}
+
+func (m *GossipMember) Reset()                    { *m = GossipMember{} }
+func (m *GossipMember) String() string            { return proto.CompactTextString(m) }
+func (*GossipMember) ProtoMessage()               {}
+func (*GossipMember) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
+
+func (m *GossipMember) GetLastSeen() *google_protobuf1.Timestamp {
+	if m != nil {
+		return m.LastSeen
+	}
+	return nil
+}
+
+// GossipMembership is the gossip membership view of the peer
+type GossipMembership struct {
+	Self    *GossipMember   `protobuf:"bytes,1,opt,name=self" json:"self,omitempty"`
+	Members []*GossipMember `protobuf:"bytes,2,rep,name=members" json:"members,omitempty"`
+}
+
+func (m *GossipMembership) Reset()                    { *m = GossipMembership{} }
+func (m *GossipMembership) String() string            { return proto.CompactTextString(m) }
+func (*GossipMembership) ProtoMessage()               {}
+func (*GossipMembership) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11} }
+
+func (m *GossipMembership) GetSelf() *GossipMember {
+	if m != nil {
+		return m.Self
+	}
+	return nil
+}
+
+func (m *GossipMembership) GetMembers() []*GossipMember {
+	if m != nil {
+		return m.Members
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*ServerStatus)(nil), "protos.ServerStatus")
 	proto.RegisterType((*LogLevelRequest)(nil), "protos.LogLevelRequest")
 	proto.RegisterType((*LogLevelResponse)(nil), "protos.LogLevelResponse")
+	proto.RegisterType((*LocalMSPResponse)(nil), "protos.LocalMSPResponse")
+	proto.RegisterType((*MSPAuditLogRequest)(nil), "protos.MSPAuditLogRequest")
+	proto.RegisterType((*MSPAuditRecord)(nil), "protos.MSPAuditRecord")
+	proto.RegisterType((*MSPAuditLogResponse)(nil), "protos.MSPAuditLogResponse")
+	proto.RegisterType((*LeadershipRequest)(nil), "protos.LeadershipRequest")
+	proto.RegisterType((*LeadershipState)(nil), "protos.LeadershipState")
+	proto.RegisterType((*LeadershipOverrideRequest)(nil), "protos.LeadershipOverrideRequest")
+	proto.RegisterType((*GossipMember)(nil), "protos.GossipMember")
+	proto.RegisterType((*GossipMembership)(nil), "protos.GossipMembership")
 	proto.RegisterEnum("protos.ServerStatus_StatusCode", ServerStatus_StatusCode_name, ServerStatus_StatusCode_value)
+	proto.RegisterEnum("protos.LeadershipState_Override", LeadershipState_Override_name, LeadershipState_Override_value)
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -177,6 +382,17 @@ type AdminClient interface {
 	StopServer(ctx context.Context, in *google_protobuf.Empty, opts ...grpc.CallOption) (*ServerStatus, error)
 	GetModuleLogLevel(ctx context.Context, in *LogLevelRequest, opts ...grpc.CallOption) (*LogLevelResponse, error)
 	SetModuleLogLevel(ctx context.Context, in *LogLevelRequest, opts ...grpc.CallOption) (*LogLevelResponse, error)
+	// Reload the local MSP from its directory.
+	ReloadLocalMSP(ctx context.Context, in *google_protobuf.Empty, opts ...grpc.CallOption) (*LocalMSPResponse, error)
+	// Return the validation decisions recorded in the MSP audit log.
+	GetMSPAuditLog(ctx context.Context, in *MSPAuditLogRequest, opts ...grpc.CallOption) (*MSPAuditLogResponse, error)
+	// Return the state of the leader election of a channel.
+	GetLeadershipState(ctx context.Context, in *LeadershipRequest, opts ...grpc.CallOption) (*LeadershipState, error)
+	// Override the leader election of a channel, e.g. to have the peer
+	// relinquish the leadership during maintenance.
+	OverrideLeadership(ctx context.Context, in *LeadershipOverrideRequest, opts ...grpc.CallOption) (*LeadershipState, error)
+	// Return the alive and dead peers in the gossip membership view.
+	GetGossipMembership(ctx context.Context, in *google_protobuf.Empty, opts ...grpc.CallOption) (*GossipMembership, error)
 }
 
 type adminClient struct {
@@ -232,6 +448,51 @@ func (c *adminClient) SetModuleLogLevel(ctx context.Context, in *LogLevelRequest
 	return out, nil
 }
 
+func (c *adminClient) ReloadLocalMSP(ctx context.Context, in *google_protobuf.Empty, opts ...grpc.CallOption) (*LocalMSPResponse, error) {
+	out := new(LocalMSPResponse)
+	err := grpc.Invoke(ctx, "/protos.Admin/ReloadLocalMSP", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) GetMSPAuditLog(ctx context.Context, in *MSPAuditLogRequest, opts ...grpc.CallOption) (*MSPAuditLogResponse, error) {
+	out := new(MSPAuditLogResponse)
+	err := grpc.Invoke(ctx, "/protos.Admin/GetMSPAuditLog", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) GetLeadershipState(ctx context.Context, in *LeadershipRequest, opts ...grpc.CallOption) (*LeadershipState, error) {
+	out := new(LeadershipState)
+	err := grpc.Invoke(ctx, "/protos.Admin/GetLeadershipState", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) OverrideLeadership(ctx context.Context, in *LeadershipOverrideRequest, opts ...grpc.CallOption) (*LeadershipState, error) {
+	out := new(LeadershipState)
+	err := grpc.Invoke(ctx, "/protos.Admin/OverrideLeadership", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) GetGossipMembership(ctx context.Context, in *google_protobuf.Empty, opts ...grpc.CallOption) (*GossipMembership, error) {
+	out := new(GossipMembership)
+	err := grpc.Invoke(ctx, "/protos.Admin/GetGossipMembership", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for Admin service
 
 type AdminServer interface {
@@ -241,6 +502,17 @@ type AdminServer interface {
 	StopServer(context.Context, *google_protobuf.Empty) (*ServerStatus, error)
 	GetModuleLogLevel(context.Context, *LogLevelRequest) (*LogLevelResponse, error)
 	SetModuleLogLevel(context.Context, *LogLevelRequest) (*LogLevelResponse, error)
+	// Reload the local MSP from its directory.
+	ReloadLocalMSP(context.Context, *google_protobuf.Empty) (*LocalMSPResponse, error)
+	// Return the validation decisions recorded in the MSP audit log.
+	GetMSPAuditLog(context.Context, *MSPAuditLogRequest) (*MSPAuditLogResponse, error)
+	// Return the state of the leader election of a channel.
+	GetLeadershipState(context.Context, *LeadershipRequest) (*LeadershipState, error)
+	// Override the leader election of a channel, e.g. to have the peer
+	// relinquish the leadership during maintenance.
+	OverrideLeadership(context.Context, *LeadershipOverrideRequest) (*LeadershipState, error)
+	// Return the alive and dead peers in the gossip membership view.
+	GetGossipMembership(context.Context, *google_protobuf.Empty) (*GossipMembership, error)
 }
 
 func RegisterAdminServer(s *grpc.Server, srv AdminServer) {
@@ -337,6 +609,96 @@ func _Admin_SetModuleLogLevel_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Admin_ReloadLocalMSP_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(google_protobuf.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).ReloadLocalMSP(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.Admin/ReloadLocalMSP",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).ReloadLocalMSP(ctx, req.(*google_protobuf.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_GetMSPAuditLog_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MSPAuditLogRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).GetMSPAuditLog(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.Admin/GetMSPAuditLog",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).GetMSPAuditLog(ctx, req.(*MSPAuditLogRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_GetLeadershipState_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LeadershipRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).GetLeadershipState(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.Admin/GetLeadershipState",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).GetLeadershipState(ctx, req.(*LeadershipRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_OverrideLeadership_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LeadershipOverrideRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).OverrideLeadership(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.Admin/OverrideLeadership",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).OverrideLeadership(ctx, req.(*LeadershipOverrideRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_GetGossipMembership_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(google_protobuf.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).GetGossipMembership(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.Admin/GetGossipMembership",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).GetGossipMembership(ctx, req.(*google_protobuf.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Admin_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "protos.Admin",
 	HandlerType: (*AdminServer)(nil),
@@ -361,6 +723,26 @@ var _Admin_serviceDesc = grpc.ServiceDesc{
 			MethodName: "SetModuleLogLevel",
 			Handler:    _Admin_SetModuleLogLevel_Handler,
 		},
+		{
+			MethodName: "ReloadLocalMSP",
+			Handler:    _Admin_ReloadLocalMSP_Handler,
+		},
+		{
+			MethodName: "GetMSPAuditLog",
+			Handler:    _Admin_GetMSPAuditLog_Handler,
+		},
+		{
+			MethodName: "GetLeadershipState",
+			Handler:    _Admin_GetLeadershipState_Handler,
+		},
+		{
+			MethodName: "OverrideLeadership",
+			Handler:    _Admin_OverrideLeadership_Handler,
+		},
+		{
+			MethodName: "GetGossipMembership",
+			Handler:    _Admin_GetGossipMembership_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: fileDescriptor0,
@@ -369,30 +751,67 @@ var _Admin_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("peer/admin.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 388 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xac, 0x92, 0xdf, 0x8e, 0xd2, 0x40,
-	0x14, 0xc6, 0x29, 0x0a, 0xda, 0x83, 0x7f, 0xc6, 0x89, 0x51, 0x02, 0x31, 0x9a, 0x5e, 0x69, 0x4c,
-	0xda, 0x04, 0x2f, 0xbc, 0x50, 0x2f, 0xd0, 0x56, 0x34, 0x42, 0x21, 0x2d, 0xc4, 0xe8, 0x0d, 0x69,
-	0xe9, 0xa1, 0x90, 0x4c, 0x99, 0xee, 0xcc, 0x94, 0x84, 0xd7, 0xd9, 0x77, 0xda, 0xf7, 0xd9, 0xb4,
-	0x03, 0x61, 0xb3, 0xbb, 0x37, 0xfb, 0xe7, 0xea, 0xf4, 0x9c, 0xf3, 0x9d, 0x2f, 0x9d, 0x5f, 0x3e,
-	0x20, 0x39, 0xa2, 0x70, 0xa2, 0x24, 0x5b, 0x6f, 0xec, 0x5c, 0x70, 0xc5, 0x69, 0xb3, 0x2a, 0xb2,
-	0xd3, 0x4d, 0x39, 0x4f, 0x19, 0x3a, 0x55, 0x1b, 0x17, 0x4b, 0x07, 0xb3, 0x5c, 0xed, 0xb4, 0xc8,
-	0x3a, 0x35, 0xe0, 0x49, 0x88, 0x62, 0x8b, 0x22, 0x54, 0x91, 0x2a, 0x24, 0xfd, 0x0c, 0x4d, 0x59,
-	0x7d, 0xb5, 0x8d, 0x77, 0xc6, 0xfb, 0x67, 0xbd, 0xb7, 0x5a, 0x28, 0xed, 0x8b, 0x2a, 0x5b, 0x97,
-	0x1f, 0x3c, 0xc1, 0x60, 0x2f, 0xb7, 0xfe, 0x01, 0x1c, 0xa7, 0xf4, 0x29, 0x98, 0x33, 0xdf, 0xf5,
-	0x7e, 0xfe, 0xf6, 0x3d, 0x97, 0xd4, 0x68, 0x0b, 0x1e, 0x85, 0xd3, 0x7e, 0x30, 0xf5, 0x5c, 0x62,
-	0xe8, 0x66, 0x3c, 0x99, 0x78, 0x2e, 0xa9, 0x53, 0x80, 0xe6, 0xa4, 0x3f, 0x0b, 0x3d, 0x97, 0x3c,
-	0xa0, 0x26, 0x34, 0xbc, 0x20, 0x18, 0x07, 0xe4, 0x61, 0xa9, 0x99, 0xf9, 0x7f, 0xfc, 0xf1, 0x5f,
-	0x9f, 0x34, 0xac, 0x11, 0x3c, 0x1f, 0xf2, 0x74, 0x88, 0x5b, 0x64, 0x01, 0x9e, 0x14, 0x28, 0x15,
-	0x7d, 0x03, 0xc0, 0x78, 0x3a, 0xcf, 0x78, 0x52, 0x30, 0xac, 0x7e, 0xd5, 0x0c, 0x4c, 0xc6, 0xd3,
-	0x51, 0x35, 0xa0, 0x5d, 0x28, 0x9b, 0x39, 0x2b, 0x4f, 0xda, 0xf5, 0x6a, 0xfb, 0x98, 0xed, 0x2d,
-	0x2c, 0x1f, 0xc8, 0xd1, 0x4e, 0xe6, 0x7c, 0x23, 0xf1, 0x2e, 0x7e, 0xbd, 0xb3, 0x3a, 0x34, 0xfa,
-	0x25, 0x78, 0xfa, 0x05, 0xcc, 0x01, 0xaa, 0x3d, 0xc9, 0x57, 0xb6, 0x06, 0x6f, 0x1f, 0xc0, 0xdb,
-	0x5e, 0x09, 0xbe, 0xf3, 0xf2, 0x3a, 0xa2, 0x56, 0x8d, 0x7e, 0x83, 0x56, 0xa8, 0x22, 0xa1, 0xf4,
-	0xf8, 0xc6, 0xe7, 0x5f, 0x4b, 0xfe, 0x3c, 0xbf, 0xe5, 0xf5, 0x2f, 0x78, 0x31, 0x40, 0xa5, 0x5f,
-	0x7b, 0x80, 0x43, 0x5f, 0x1f, 0xc4, 0x97, 0xe8, 0x77, 0xda, 0x57, 0x17, 0x9a, 0xa3, 0x76, 0x0a,
-	0xef, 0xc5, 0xe9, 0xfb, 0xc7, 0xff, 0x1f, 0xd2, 0xb5, 0x5a, 0x15, 0xb1, 0xbd, 0xe0, 0x99, 0xb3,
-	0xda, 0xe5, 0x28, 0x18, 0x26, 0x29, 0x0a, 0x67, 0x19, 0xc5, 0x62, 0xbd, 0xd0, 0x89, 0x96, 0x4e,
-	0x99, 0xfc, 0x58, 0xa7, 0xfd, 0xd3, 0x79, 0x00, 0x00, 0x00, 0xff, 0xff, 0xaa, 0x24, 0x41, 0xca,
-	0x08, 0x03, 0x00, 0x00,
+	// 979 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x56, 0xef, 0x6e, 0x1b, 0x45,
+	0x10, 0xb7, 0x1d, 0xdb, 0x39, 0x4f, 0xdc, 0xe4, 0xb2, 0x0d, 0xe9, 0xd5, 0x01, 0x35, 0x1c, 0x5f,
+	0x52, 0x55, 0xd8, 0x28, 0x20, 0x15, 0x89, 0xf2, 0x21, 0xd4, 0xc6, 0x31, 0x4d, 0x9c, 0x74, 0x9d,
+	0x08, 0xc1, 0x17, 0x6b, 0xe3, 0x9b, 0xd8, 0xab, 0xee, 0xdd, 0x1e, 0xbb, 0xeb, 0xa0, 0xbc, 0x04,
+	0x0f, 0xc1, 0x2b, 0xf1, 0x89, 0x37, 0xe0, 0x31, 0xd0, 0xfd, 0x59, 0xff, 0x4b, 0x43, 0x54, 0xe0,
+	0x93, 0x3d, 0x33, 0xbf, 0xf9, 0xdd, 0xcc, 0xdc, 0xfc, 0x39, 0x70, 0x63, 0x44, 0xd5, 0x62, 0x41,
+	0xc8, 0xa3, 0x66, 0xac, 0xa4, 0x91, 0xa4, 0x9a, 0xfe, 0xe8, 0xc6, 0xde, 0x58, 0xca, 0xb1, 0xc0,
+	0x56, 0x2a, 0x5e, 0x4d, 0xaf, 0x5b, 0x18, 0xc6, 0xe6, 0x36, 0x03, 0x35, 0x9e, 0xad, 0x1a, 0x0d,
+	0x0f, 0x51, 0x1b, 0x16, 0xc6, 0x19, 0xc0, 0xff, 0xbd, 0x08, 0xf5, 0x01, 0xaa, 0x1b, 0x54, 0x03,
+	0xc3, 0xcc, 0x54, 0x93, 0x97, 0x50, 0xd5, 0xe9, 0x3f, 0xaf, 0xb8, 0x5f, 0x3c, 0xd8, 0x3c, 0x7c,
+	0x96, 0x01, 0x75, 0x73, 0x11, 0xd5, 0xcc, 0x7e, 0x5e, 0xcb, 0x00, 0x69, 0x0e, 0xf7, 0x7f, 0x02,
+	0x98, 0x6b, 0xc9, 0x23, 0xa8, 0x5d, 0xf6, 0xdb, 0x9d, 0xef, 0x7b, 0xfd, 0x4e, 0xdb, 0x2d, 0x90,
+	0x0d, 0x58, 0x1f, 0x5c, 0x1c, 0xd1, 0x8b, 0x4e, 0xdb, 0x2d, 0x66, 0xc2, 0xd9, 0xf9, 0x79, 0xa7,
+	0xed, 0x96, 0x08, 0x40, 0xf5, 0xfc, 0xe8, 0x72, 0xd0, 0x69, 0xbb, 0x6b, 0xa4, 0x06, 0x95, 0x0e,
+	0xa5, 0x67, 0xd4, 0x2d, 0x27, 0x98, 0xcb, 0xfe, 0x9b, 0xfe, 0xd9, 0x8f, 0x7d, 0xb7, 0xe2, 0x9f,
+	0xc2, 0xd6, 0x89, 0x1c, 0x9f, 0xe0, 0x0d, 0x0a, 0x8a, 0xbf, 0x4c, 0x51, 0x1b, 0xf2, 0x09, 0x80,
+	0x90, 0xe3, 0x61, 0x28, 0x83, 0xa9, 0xc0, 0x34, 0xd4, 0x1a, 0xad, 0x09, 0x39, 0x3e, 0x4d, 0x15,
+	0x64, 0x0f, 0x12, 0x61, 0x28, 0x12, 0x17, 0xaf, 0x94, 0x5a, 0x1d, 0x91, 0x53, 0xf8, 0x7d, 0x70,
+	0xe7, 0x74, 0x3a, 0x96, 0x91, 0xc6, 0xff, 0xc4, 0x77, 0x91, 0xf0, 0x8d, 0x98, 0x38, 0x1d, 0x9c,
+	0xcf, 0xf8, 0x3e, 0x82, 0x6a, 0xa8, 0xe3, 0x21, 0x0f, 0x72, 0xae, 0x4a, 0xa8, 0xe3, 0x5e, 0x40,
+	0x9e, 0x83, 0xab, 0xf9, 0x38, 0xe2, 0xd1, 0x78, 0xc8, 0x03, 0x8c, 0x0c, 0x37, 0xb7, 0x29, 0x5d,
+	0x9d, 0x6e, 0xe5, 0xfa, 0x5e, 0xae, 0xf6, 0x9b, 0x40, 0x4e, 0x07, 0xe7, 0x47, 0xd3, 0x80, 0x9b,
+	0x13, 0x39, 0xb6, 0x79, 0x7b, 0xb0, 0x3e, 0x9a, 0xb0, 0x28, 0x42, 0x91, 0x13, 0x5b, 0xd1, 0xff,
+	0xad, 0x04, 0x9b, 0xd6, 0x81, 0xe2, 0x48, 0xaa, 0x80, 0x7c, 0x0d, 0xb5, 0xd9, 0xfb, 0x4e, 0xe1,
+	0x1b, 0x87, 0x8d, 0x66, 0xd6, 0x11, 0x4d, 0xdb, 0x11, 0xcd, 0x0b, 0x8b, 0xa0, 0x73, 0xf0, 0x42,
+	0xf8, 0xa5, 0xc5, 0xf0, 0x17, 0x9e, 0xbe, 0xb6, 0xf4, 0x74, 0xf2, 0x19, 0x3c, 0xb2, 0x09, 0x0d,
+	0x27, 0x4c, 0x4f, 0xbc, 0x72, 0x9a, 0x55, 0xdd, 0x2a, 0x8f, 0x99, 0x9e, 0x90, 0x8f, 0xa1, 0x26,
+	0x63, 0x54, 0xcc, 0x70, 0x19, 0x79, 0x95, 0xac, 0xc6, 0x33, 0x45, 0x62, 0x8d, 0x15, 0x8f, 0x46,
+	0x3c, 0x66, 0xc2, 0xab, 0x66, 0xd6, 0x99, 0x22, 0x79, 0x34, 0x13, 0x42, 0xfe, 0x8a, 0x81, 0xb7,
+	0xbe, 0x5f, 0x3c, 0x70, 0xa8, 0x15, 0xc9, 0x2e, 0x54, 0x15, 0x32, 0x2d, 0x23, 0xcf, 0x49, 0x9d,
+	0x72, 0xc9, 0xef, 0xc2, 0xe3, 0xa5, 0x02, 0xe6, 0x6f, 0xe6, 0x0b, 0x58, 0x57, 0x69, 0x79, 0x92,
+	0x0e, 0x5f, 0x3b, 0xd8, 0x38, 0xdc, 0xb5, 0x1d, 0xbe, 0x5c, 0x3d, 0x6a, 0x61, 0xfe, 0xe7, 0xb0,
+	0x7d, 0x82, 0x2c, 0x40, 0xa5, 0x27, 0x3c, 0x7e, 0xf8, 0x45, 0xfc, 0x55, 0x84, 0xad, 0x39, 0x3e,
+	0x99, 0x09, 0xbc, 0x1f, 0x9d, 0x74, 0x16, 0xd7, 0x43, 0x91, 0xe2, 0xd3, 0x62, 0x3b, 0xd4, 0xe1,
+	0x3a, 0xf3, 0x4f, 0x52, 0xcb, 0x2d, 0x6b, 0x69, 0x39, 0x73, 0x89, 0x10, 0x28, 0x1b, 0x54, 0x61,
+	0x5a, 0xe4, 0x32, 0x4d, 0xff, 0x93, 0x57, 0xe0, 0xc8, 0x1b, 0x54, 0x8a, 0x07, 0x98, 0xd6, 0x76,
+	0xf3, 0x70, 0xdf, 0x26, 0xb6, 0x12, 0x4d, 0xf3, 0x2c, 0xc7, 0xd1, 0x99, 0x87, 0xff, 0x15, 0x38,
+	0x56, 0x4b, 0x1c, 0x28, 0xf7, 0xcf, 0xfa, 0x1d, 0xb7, 0x40, 0x5c, 0xa8, 0xd3, 0xce, 0x49, 0xaf,
+	0xff, 0xf6, 0xb2, 0x37, 0x38, 0x4e, 0x67, 0xb7, 0x0e, 0xce, 0xd1, 0xeb, 0xb7, 0x97, 0x3d, 0x9a,
+	0x0c, 0xaf, 0xaf, 0xe1, 0xe9, 0x9c, 0x7b, 0xc6, 0xfa, 0x50, 0x85, 0x96, 0x42, 0x2d, 0x7d, 0x70,
+	0xa8, 0x7f, 0x16, 0xa1, 0xde, 0x95, 0x5a, 0xf3, 0xf8, 0x14, 0xc3, 0x2b, 0x54, 0x49, 0xb3, 0xc6,
+	0xef, 0xb8, 0x9d, 0xb5, 0x3a, 0xad, 0xc4, 0xef, 0x78, 0x2f, 0x20, 0x0d, 0x70, 0x30, 0x0a, 0x62,
+	0xc9, 0x23, 0x63, 0x47, 0xd6, 0xca, 0xe4, 0x05, 0x6c, 0xf3, 0xc8, 0xa0, 0x8a, 0x98, 0x18, 0xce,
+	0x40, 0x59, 0x4b, 0xbb, 0xd6, 0xd0, 0xb1, 0xe0, 0x06, 0x38, 0x21, 0x1a, 0x16, 0x30, 0xc3, 0xf2,
+	0xb6, 0x9e, 0xc9, 0x64, 0x07, 0x2a, 0x4c, 0xf0, 0x9b, 0xac, 0xe4, 0x0e, 0xcd, 0x04, 0xf2, 0x12,
+	0x6a, 0x82, 0x69, 0x33, 0xd4, 0x88, 0x91, 0x57, 0x7d, 0x70, 0xf0, 0x9c, 0x04, 0x3c, 0x40, 0x8c,
+	0x7c, 0x01, 0xee, 0x62, 0x6a, 0x49, 0x1d, 0xc8, 0x01, 0x94, 0x35, 0x8a, 0xeb, 0x7c, 0x80, 0x77,
+	0x6c, 0xa5, 0x16, 0x71, 0x34, 0x45, 0x90, 0x26, 0xac, 0x87, 0x99, 0x9f, 0x57, 0xda, 0x5f, 0xbb,
+	0x17, 0x6c, 0x41, 0x87, 0x7f, 0x54, 0xa0, 0x72, 0x94, 0x9c, 0x14, 0xf2, 0x0d, 0xd4, 0xba, 0x68,
+	0xf2, 0x13, 0xb0, 0x7b, 0x27, 0xd4, 0x4e, 0x72, 0x52, 0x1a, 0x3b, 0xef, 0x3b, 0x05, 0x7e, 0x81,
+	0x7c, 0x0b, 0x1b, 0x03, 0xc3, 0x94, 0xc9, 0xd4, 0x1f, 0xec, 0xfe, 0x2a, 0x39, 0x1c, 0x32, 0xfe,
+	0x97, 0xde, 0xc7, 0xb0, 0xdd, 0x45, 0x93, 0xad, 0x69, 0xbb, 0xd5, 0xc9, 0x93, 0x59, 0x3b, 0x2d,
+	0x9f, 0x8d, 0x86, 0x77, 0xd7, 0x90, 0xad, 0x85, 0x8c, 0x69, 0xf0, 0xff, 0x30, 0xb5, 0x61, 0x93,
+	0xa2, 0x90, 0x2c, 0xb0, 0x67, 0xe1, 0xde, 0xac, 0x16, 0x58, 0x96, 0x0f, 0x88, 0x5f, 0x20, 0x6f,
+	0x60, 0x33, 0xc9, 0x6c, 0xbe, 0xc2, 0x48, 0x63, 0x75, 0x53, 0xcd, 0x0f, 0x43, 0x63, 0xef, 0xbd,
+	0xb6, 0x19, 0xd9, 0x0f, 0x40, 0xba, 0x68, 0x56, 0xd7, 0xd2, 0xd3, 0xbb, 0x63, 0x67, 0xf9, 0x9e,
+	0xdc, 0x33, 0x91, 0x7e, 0x81, 0x50, 0x20, 0x76, 0x2c, 0xe7, 0x46, 0xf2, 0xe9, 0x5d, 0x87, 0x95,
+	0x8d, 0xf0, 0x4f, 0x9c, 0x3d, 0x78, 0xdc, 0x45, 0x73, 0xa7, 0xf7, 0x1f, 0xac, 0xdb, 0xaa, 0x87,
+	0x5f, 0xf8, 0xee, 0xc5, 0xcf, 0xcf, 0xc7, 0xdc, 0x4c, 0xa6, 0x57, 0xcd, 0x91, 0x0c, 0x5b, 0x93,
+	0xdb, 0x18, 0x95, 0xc0, 0x60, 0x8c, 0xaa, 0x75, 0xcd, 0xae, 0x14, 0x1f, 0x65, 0x1f, 0x43, 0xba,
+	0x15, 0x23, 0xaa, 0xab, 0xec, 0x2b, 0xea, 0xcb, 0xbf, 0x07, 0x00, 0x27, 0xf1, 0x09, 0xef, 0x60,
+	0x09, 0x00, 0x00,
 }